@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -10,14 +12,44 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/slavakukuyev/elevator-go/internal/analytics"
+	"github.com/slavakukuyev/elevator-go/internal/cluster"
+	"github.com/slavakukuyev/elevator-go/internal/constants"
 	"github.com/slavakukuyev/elevator-go/internal/factory"
+	"github.com/slavakukuyev/elevator-go/internal/forecast"
 	httpPkg "github.com/slavakukuyev/elevator-go/internal/http"
 	"github.com/slavakukuyev/elevator-go/internal/infra/config"
 	"github.com/slavakukuyev/elevator-go/internal/infra/logging"
+	"github.com/slavakukuyev/elevator-go/internal/infra/observability"
 	"github.com/slavakukuyev/elevator-go/internal/manager"
+	"github.com/slavakukuyev/elevator-go/internal/mqtt"
+	"github.com/slavakukuyev/elevator-go/internal/sla"
+	"github.com/slavakukuyev/elevator-go/internal/webhook"
 )
 
 func main() {
+	// Parse command-line flags, which override every other configuration source
+	flags, err := config.ParseFlags(os.Args[0], os.Args[1:], os.Stderr)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(0)
+		}
+		os.Exit(2)
+	}
+	config.UseFlags(flags)
+
+	if flags.PrintConfig {
+		_, settings, err := config.Effective()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid configuration: %v\n", err)
+			os.Exit(1)
+		}
+		if err := config.PrintSettings(os.Stdout, settings); err != nil {
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Initialize configuration
 	cfg, err := config.InitConfig()
 	if err != nil {
@@ -43,29 +75,35 @@ func main() {
 		slog.Bool("circuit_breaker_enabled", cfg.CircuitBreakerEnabled),
 		slog.Any("config_summary", envInfo))
 
-	// Initialize factory and manager
+	// Initialize factory and the manager of the default building
 	elevatorFactory := &factory.StandardElevatorFactory{}
 	elevatorManager := manager.New(cfg, elevatorFactory)
+	if !cfg.ClusterEnabled {
+		// A cluster replicates its fleet; the leader creates the default one
+		elevatorManager.AddDefaultElevators(ctx, cfg)
+	}
 
-	// Create default elevators if configured
-	if cfg.DefaultElevatorCount > 0 {
-		slog.InfoContext(ctx, "creating default elevators",
-			slog.Int("count", cfg.DefaultElevatorCount),
-			slog.String("prefix", cfg.NamePrefix))
-
-		for i := 0; i < cfg.DefaultElevatorCount; i++ {
-			elevatorName := fmt.Sprintf("%s-%d", cfg.NamePrefix, i+1)
-			err := elevatorManager.AddElevator(ctx, cfg, elevatorName,
-				cfg.MinFloor, cfg.MaxFloor,
-				cfg.EachFloorDuration, cfg.OpenDoorDuration, cfg.DefaultOverloadThreshold)
-			if err != nil {
-				slog.ErrorContext(ctx, "failed to create default elevator",
-					slog.String("name", elevatorName),
-					slog.String("error", err.Error()))
-			} else {
-				slog.InfoContext(ctx, "default elevator created",
-					slog.String("name", elevatorName))
-			}
+	// Register the default building and start the configured ones, each
+	// with its own manager and fleet
+	buildings := manager.NewRegistry(elevatorFactory)
+	if _, err := buildings.Register(cfg.BuildingID, elevatorManager); err != nil {
+		slog.ErrorContext(ctx, "failed to register default building",
+			slog.String("building", cfg.BuildingID),
+			slog.String("error", err.Error()))
+		elevatorManager.Shutdown()
+		os.Exit(1)
+	}
+	for _, id := range cfg.BuildingIDs() {
+		buildingCfg, err := cfg.BuildingConfig(id)
+		if err == nil {
+			_, err = buildings.AddBuilding(ctx, id, buildingCfg)
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to start building",
+				slog.String("building", id),
+				slog.String("error", err.Error()))
+			buildings.Shutdown()
+			os.Exit(1)
 		}
 	}
 
@@ -78,13 +116,127 @@ func main() {
 		port = 6660
 	}
 
+	// Trace requests from the HTTP server through dispatch to the pickup and
+	// delivery; spans go to the OTLP collector when one is configured
+	telemetry, err := startTelemetry()
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to start telemetry", slog.String("error", err.Error()))
+		buildings.Shutdown()
+		os.Exit(1)
+	}
+
 	// Create servers
 	server := httpPkg.NewServer(cfg, port, elevatorManager)
+	server.SetBuildings(buildings)
+	server.SetTelemetry(telemetry)
+
+	// Join the cluster, whose leader runs the fleet of the default building
+	var clusterNode *cluster.Node
+	if cfg.ClusterEnabled {
+		clusterNode, err = startClusterNode(cfg, elevatorManager)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to join cluster",
+				slog.String("node", cfg.ClusterNodeID),
+				slog.String("error", err.Error()))
+			buildings.Shutdown()
+			stopTelemetry(telemetry, cfg.ShutdownTimeout)
+			os.Exit(1)
+		}
+		server.SetCluster(clusterNode)
+	}
+
+	// Notify webhook subscribers of the events of every building
+	var webhooks *webhook.Dispatcher
+	if cfg.WebhookEnabled {
+		webhooks = webhook.NewDispatcher(webhook.OptionsFromConfig(cfg))
+		for _, building := range buildings.Buildings() {
+			webhooks.Watch(building.ID, building.Manager.Events())
+		}
+		server.SetWebhooks(webhooks)
+	}
+
+	// Evaluate the SLA rules against every building; breaches reach the
+	// logs, the event bus and readiness
+	var alerts *sla.Engine
+	if cfg.SLARules != "" {
+		rules, err := config.ParseSLARules(cfg.SLARules)
+		if err != nil {
+			slog.ErrorContext(ctx, "invalid SLA rules", slog.String("error", err.Error()))
+			stopWebhooks(webhooks)
+			stopClusterNode(clusterNode)
+			buildings.Shutdown()
+			stopTelemetry(telemetry, cfg.ShutdownTimeout)
+			os.Exit(1)
+		}
+		alerts = sla.NewEngine(rules, cfg.SLAEvaluationInterval)
+		for _, building := range buildings.Buildings() {
+			alerts.Monitor(building.ID, building.Manager)
+		}
+		server.SetAlerts(alerts)
+		alerts.Start()
+	}
+
+	// Record the traffic of every building for /v1/analytics reports
+	var analyticsStore *analytics.Store
+	var recorder *analytics.Recorder
+	if cfg.AnalyticsEnabled {
+		analyticsStore, err = analytics.OpenStore(cfg.AnalyticsDir, cfg.AnalyticsRetention)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to open analytics store",
+				slog.String("dir", cfg.AnalyticsDir),
+				slog.String("error", err.Error()))
+			stopAlerts(alerts)
+			stopWebhooks(webhooks)
+			stopClusterNode(clusterNode)
+			buildings.Shutdown()
+			stopTelemetry(telemetry, cfg.ShutdownTimeout)
+			os.Exit(1)
+		}
+		recorder = analytics.NewRecorder(analyticsStore)
+		for _, building := range buildings.Buildings() {
+			recorder.Watch(building.ID, building.Manager)
+		}
+		server.SetAnalytics(analyticsStore)
+	}
+
+	// Learn when and where calls are made, from the analytics history when
+	// available, and place idle cars ahead of the demand
+	var planner *forecast.Planner
+	if cfg.PrepositionEnabled {
+		planner = forecast.NewPlanner(forecast.OptionsFromConfig(cfg))
+		for _, building := range buildings.Buildings() {
+			planner.Watch(building.ID, building.Manager, callHistory(ctx, analyticsStore, building.ID, cfg.AnalyticsRetention))
+		}
+	}
 	wsServer := httpPkg.NewWebSocketServer(6661, elevatorManager, slog.With(slog.String("component", "websocket-server")))
+	if clusterNode != nil {
+		wsServer.SetCluster(clusterNode)
+	}
+
+	// Start MQTT bridge for field hardware if configured
+	var mqttBridge *mqtt.Bridge
+	if cfg.MQTTEnabled {
+		mqttBridge = mqtt.New(cfg, elevatorManager)
+		if err := mqttBridge.Start(); err != nil {
+			slog.ErrorContext(ctx, "MQTT bridge failed to start",
+				slog.String("broker", cfg.MQTTBrokerURL),
+				slog.String("error", err.Error()))
+			stopAlerts(alerts)
+			stopPlanner(planner)
+			stopAnalytics(recorder, analyticsStore)
+			stopWebhooks(webhooks)
+			stopClusterNode(clusterNode)
+			buildings.Shutdown()
+			stopTelemetry(telemetry, cfg.ShutdownTimeout)
+			os.Exit(1)
+		}
+	}
 
-	// Setup graceful shutdown
+	// Setup graceful shutdown; SIGHUP reloads the configuration instead
 	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
 
 	// Start servers with proper error handling
 	var httpStarted, wsStarted bool
@@ -133,7 +285,14 @@ func main() {
 
 		// Try to gracefully shutdown any servers that might have started
 		shutdownServers(server, wsServer, cfg, httpStarted, wsStarted)
-		elevatorManager.Shutdown()
+		stopMQTTBridge(mqttBridge)
+		stopAlerts(alerts)
+		stopPlanner(planner)
+		stopAnalytics(recorder, analyticsStore)
+		stopWebhooks(webhooks)
+		stopClusterNode(clusterNode)
+		buildings.Shutdown()
+		stopTelemetry(telemetry, cfg.ShutdownTimeout)
 		os.Exit(1)
 
 	case <-startupTimer.C:
@@ -146,12 +305,19 @@ func main() {
 		slog.InfoContext(ctx, "received shutdown signal during startup",
 			slog.String("signal", sig.String()))
 		shutdownServers(server, wsServer, cfg, httpStarted, wsStarted)
-		elevatorManager.Shutdown()
+		stopMQTTBridge(mqttBridge)
+		stopAlerts(alerts)
+		stopPlanner(planner)
+		stopAnalytics(recorder, analyticsStore)
+		stopWebhooks(webhooks)
+		stopClusterNode(clusterNode)
+		buildings.Shutdown()
+		stopTelemetry(telemetry, cfg.ShutdownTimeout)
 		return
 	}
 
-	// Wait for shutdown signal
-	sig := <-quit
+	// Reload the configuration on SIGHUP until a shutdown signal arrives
+	sig := waitForShutdown(ctx, server, quit, reload)
 	slog.InfoContext(ctx, "received shutdown signal",
 		slog.String("signal", sig.String()),
 		slog.Duration("shutdown_timeout", cfg.ShutdownTimeout))
@@ -161,11 +327,18 @@ func main() {
 
 	// Shutdown servers gracefully
 	shutdownServers(server, wsServer, cfg, httpStarted, wsStarted)
+	stopMQTTBridge(mqttBridge)
+	stopAlerts(alerts)
+	stopPlanner(planner)
+	stopAnalytics(recorder, analyticsStore)
+	stopWebhooks(webhooks)
+	stopClusterNode(clusterNode)
 
-	// Shutdown the manager
-	slog.InfoContext(ctx, "shutting down elevator manager")
-	elevatorManager.Shutdown()
-	slog.InfoContext(ctx, "elevator manager shutdown completed")
+	// Shutdown the manager of every building
+	slog.InfoContext(ctx, "shutting down elevator managers")
+	buildings.Shutdown()
+	slog.InfoContext(ctx, "elevator managers shutdown completed")
+	stopTelemetry(telemetry, cfg.ShutdownTimeout)
 
 	// Wait for a short grace period before final exit
 	<-time.After(cfg.ShutdownGrace)
@@ -173,6 +346,24 @@ func main() {
 		slog.Duration("grace_period", cfg.ShutdownGrace))
 }
 
+// waitForShutdown reloads the configuration for every signal on reload and
+// returns the first signal received on quit
+func waitForShutdown(ctx context.Context, server *httpPkg.Server, quit, reload <-chan os.Signal) os.Signal {
+	for {
+		select {
+		case sig := <-quit:
+			return sig
+		case sig := <-reload:
+			slog.InfoContext(ctx, "received configuration reload signal",
+				slog.String("signal", sig.String()))
+			if _, err := server.ReloadConfig(ctx); err != nil {
+				slog.ErrorContext(ctx, "configuration reload failed, keeping current configuration",
+					slog.String("error", err.Error()))
+			}
+		}
+	}
+}
+
 // shutdownServers gracefully shuts down both HTTP and WebSocket servers
 func shutdownServers(server *httpPkg.Server, wsServer *httpPkg.WebSocketServer, cfg *config.Config, httpStarted, wsStarted bool) {
 	slog.Info("shutting down servers gracefully")
@@ -197,3 +388,108 @@ func shutdownServers(server *httpPkg.Server, wsServer *httpPkg.WebSocketServer,
 		}
 	}
 }
+
+// stopMQTTBridge disconnects the MQTT bridge if it was started
+func stopMQTTBridge(bridge *mqtt.Bridge) {
+	if bridge == nil {
+		return
+	}
+	bridge.Stop()
+}
+
+// stopAlerts stops evaluating SLA rules if any were configured
+func stopAlerts(engine *sla.Engine) {
+	if engine == nil {
+		return
+	}
+	engine.Stop()
+}
+
+// callHistory returns the requests recorded for a building within the
+// retention, or none when analytics are disabled or cannot be read
+func callHistory(ctx context.Context, store *analytics.Store, building string, retention time.Duration) []analytics.RequestRecord {
+	if store == nil {
+		return nil
+	}
+	now := time.Now()
+	history, err := store.Requests(building, now.Add(-retention), now)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to read call history, forecasting from scratch",
+			slog.String("building", building),
+			slog.String("error", err.Error()))
+		return nil
+	}
+	return history
+}
+
+// stopPlanner stops placing idle cars if pre-positioning was enabled
+func stopPlanner(planner *forecast.Planner) {
+	if planner == nil {
+		return
+	}
+	planner.Stop()
+}
+
+// stopAnalytics stops recording and closes the store if analytics were
+// enabled
+func stopAnalytics(recorder *analytics.Recorder, store *analytics.Store) {
+	if recorder != nil {
+		recorder.Stop()
+	}
+	if store == nil {
+		return
+	}
+	if err := store.Close(); err != nil {
+		slog.Error("analytics store close failed", slog.String("error", err.Error()))
+	}
+}
+
+// stopWebhooks stops delivering webhooks if they were enabled
+func stopWebhooks(dispatcher *webhook.Dispatcher) {
+	if dispatcher == nil {
+		return
+	}
+	dispatcher.Stop()
+}
+
+// startClusterNode joins the cluster the configuration describes
+func startClusterNode(cfg *config.Config, m *manager.Manager) (*cluster.Node, error) {
+	opts, err := cluster.OptionsFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return cluster.NewNode(opts, m)
+}
+
+// startTelemetry creates the telemetry provider the observability
+// configuration describes
+func startTelemetry() (*observability.TelemetryProvider, error) {
+	obsCfg, err := observability.LoadObservabilityConfig()
+	if err != nil {
+		return nil, err
+	}
+	if err := obsCfg.Validate(); err != nil {
+		return nil, err
+	}
+	return observability.NewTelemetryProvider(obsCfg, slog.With(slog.String("component", constants.ComponentTelemetry)))
+}
+
+// stopTelemetry exports the spans still batched and shuts the telemetry
+// provider down
+func stopTelemetry(provider *observability.TelemetryProvider, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := provider.Shutdown(ctx); err != nil {
+		slog.Error("telemetry shutdown failed", slog.String("error", err.Error()))
+	}
+}
+
+// stopClusterNode leaves the cluster if the node was started
+func stopClusterNode(node *cluster.Node) {
+	if node == nil {
+		return
+	}
+	if err := node.Shutdown(); err != nil {
+		slog.Error("cluster node shutdown failed", slog.String("error", err.Error()))
+	}
+}
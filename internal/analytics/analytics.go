@@ -0,0 +1,115 @@
+// Package analytics keeps a history of request outcomes and car state changes
+// and answers questions about traffic over arbitrary time ranges.
+//
+// A Recorder follows the event bus and status broadcaster of every watched
+// building and appends records to a Store: one per finished request
+// (completed, cancelled or rejected) and one whenever a car changes between
+// idle, moving up, moving down, faulted, being deleted and offline. The store
+// is a directory of append-only JSON-lines files, one per UTC day, so it
+// needs no database and can be inspected or shipped with standard tools.
+// Files older than the retention are deleted.
+//
+// Query turns the records of a range into a Report: request counts, wait and
+// ride time percentiles, an hour-of-day by floor heatmap of calls, the
+// busiest hours and the utilization of every car.
+package analytics
+
+import (
+	"time"
+)
+
+// Outcome is how a floor request ended
+type Outcome string
+
+const (
+	// OutcomeCompleted requests reached their destination floor
+	OutcomeCompleted Outcome = "completed"
+	// OutcomeCancelled requests were withdrawn or lost their elevator
+	OutcomeCancelled Outcome = "cancelled"
+	// OutcomeRejected requests were not taken by any elevator
+	OutcomeRejected Outcome = "rejected"
+)
+
+// CarState is what a car is doing
+type CarState string
+
+const (
+	// CarIdle cars wait for requests
+	CarIdle CarState = "idle"
+	// CarUp cars serve requests going up
+	CarUp CarState = "up"
+	// CarDown cars serve requests going down
+	CarDown CarState = "down"
+	// CarFaulted cars are out of service after repeated failures
+	CarFaulted CarState = "faulted"
+	// CarDeleting cars finish their requests before leaving the pool
+	CarDeleting CarState = "deleting"
+	// CarOffline cars left the pool or are not observed
+	CarOffline CarState = "offline"
+)
+
+// IsBusy reports whether a car in the state is serving requests
+func (s CarState) IsBusy() bool {
+	return s == CarUp || s == CarDown
+}
+
+// RequestRecord is a floor request that reached its final state
+type RequestRecord struct {
+	Building    string     `json:"building"`
+	TripID      string     `json:"trip_id,omitempty"`  // Rejected requests have no trip
+	Elevator    string     `json:"elevator,omitempty"` // Rejected requests have no elevator
+	Outcome     Outcome    `json:"outcome"`
+	FromFloor   int        `json:"from_floor"`
+	ToFloor     int        `json:"to_floor"`
+	Direction   string     `json:"direction"`
+	Priority    string     `json:"priority,omitempty"`
+	RequestedAt time.Time  `json:"requested_at"`
+	PickedUpAt  *time.Time `json:"picked_up_at,omitempty"`
+	FinishedAt  time.Time  `json:"finished_at"`
+}
+
+// Wait returns how long riders waited for pickup, if they were picked up
+func (r RequestRecord) Wait() (time.Duration, bool) {
+	if r.PickedUpAt == nil {
+		return 0, false
+	}
+	return r.PickedUpAt.Sub(r.RequestedAt), true
+}
+
+// Ride returns how long a completed request took from pickup to drop-off
+func (r RequestRecord) Ride() (time.Duration, bool) {
+	if r.PickedUpAt == nil || r.Outcome != OutcomeCompleted {
+		return 0, false
+	}
+	return r.FinishedAt.Sub(*r.PickedUpAt), true
+}
+
+// within reports whether the request was made in a building, or any when
+// empty, within a range
+func (r RequestRecord) within(building string, from, to time.Time) bool {
+	return (building == "" || r.Building == building) &&
+		!r.RequestedAt.Before(from) && r.RequestedAt.Before(to)
+}
+
+// CarRecord is a car entering a state
+type CarRecord struct {
+	Building string    `json:"building"`
+	Elevator string    `json:"elevator"`
+	State    CarState  `json:"state"`
+	Floor    int       `json:"floor"`
+	At       time.Time `json:"at"`
+}
+
+// entry is one line of a store file; exactly one field is set
+type entry struct {
+	Request *RequestRecord `json:"request,omitempty"`
+	Car     *CarRecord     `json:"car,omitempty"`
+}
+
+// time returns when the entry was recorded, which decides its file
+func (e entry) time() time.Time {
+	if e.Request != nil {
+		return e.Request.FinishedAt
+	}
+	return e.Car.At
+}
@@ -0,0 +1,263 @@
+package analytics
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/slavakukuyev/elevator-go/internal/broadcast"
+	"github.com/slavakukuyev/elevator-go/internal/constants"
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+	"github.com/slavakukuyev/elevator-go/internal/events"
+	"github.com/slavakukuyev/elevator-go/metrics"
+)
+
+const (
+	// eventBuffer bounds the events queued for the recorder on each bus
+	eventBuffer = 256
+	// statusBuffer bounds the status updates queued for the recorder; only
+	// the newest one matters
+	statusBuffer = 16
+)
+
+// Source publishes the events and status of one building;
+// *manager.Manager is a Source
+type Source interface {
+	Events() *events.Bus
+	Broadcaster() *broadcast.Broadcaster
+}
+
+// Recorder appends the request outcomes and car state changes of watched
+// buildings to a store
+type Recorder struct {
+	store  *Store
+	logger *slog.Logger
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewRecorder creates a recorder writing to a store
+func NewRecorder(store *Store) *Recorder {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Recorder{
+		store:  store,
+		logger: slog.With(slog.String("component", constants.ComponentAnalytics)),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Watch records the requests a building finishes and the state changes of
+// its cars from now on
+func (r *Recorder) Watch(building string, source Source) {
+	w := &buildingWatch{
+		building: building,
+		status:   make(map[string]domain.ElevatorStatus),
+		faulted:  make(map[string]bool),
+		cars:     make(map[string]CarRecord),
+	}
+	bus := source.Events()
+	lastID := bus.LastID()
+	status := source.Broadcaster().Subscribe(statusBuffer)
+
+	r.wg.Add(1)
+	go r.watch(w, bus, status, lastID)
+}
+
+// Stop stops watching and records every observed car as offline, so the
+// time the system is down does not count towards any state
+func (r *Recorder) Stop() {
+	r.cancel()
+	r.wg.Wait()
+}
+
+// buildingWatch is what the recorder knows about one building; it is only
+// used by the building's watch goroutine
+type buildingWatch struct {
+	building string
+	status   map[string]domain.ElevatorStatus // Latest status of every car
+	faulted  map[string]bool                  // Cars whose circuit breaker is open
+	cars     map[string]CarRecord             // Last recorded state of the cars that are not offline
+}
+
+func (r *Recorder) watch(w *buildingWatch, bus *events.Bus, status *broadcast.Subscriber, lastID uint64) {
+	defer r.wg.Done()
+	defer func() {
+		status.Close()
+		now := time.Now()
+		for name := range w.cars {
+			r.recordCar(w, name, CarOffline, now)
+		}
+	}()
+
+	statusC := status.C
+	for {
+		sub, backlog, complete := bus.SubscribeSince(lastID, eventBuffer)
+		if !complete {
+			r.logger.Warn("analytics recorder fell behind, events were not recorded",
+				slog.String("building", w.building),
+				slog.Uint64("last_event_id", lastID))
+			metrics.IncError("events_missed", constants.ComponentAnalytics)
+		}
+		for _, event := range backlog {
+			r.recordEvent(w, event)
+			lastID = event.ID
+		}
+
+		// Follow the subscription until the bus drops it
+		for open := true; open; {
+			select {
+			case <-r.ctx.Done():
+				sub.Close()
+				return
+			case event, ok := <-sub.C:
+				if !ok {
+					open = false
+					continue
+				}
+				r.recordEvent(w, event)
+				lastID = event.ID
+			case update, ok := <-statusC:
+				if !ok {
+					// The building shut down; its events end as well
+					statusC = nil
+					continue
+				}
+				r.recordStatus(w, update)
+			}
+		}
+	}
+}
+
+// recordEvent records finished requests and tracks faulted cars
+func (r *Recorder) recordEvent(w *buildingWatch, event events.Event) {
+	switch event.Type {
+	case events.TypeRequestCompleted, events.TypeRequestCancelled, events.TypeRequestRejected:
+		r.recordRequest(w, event)
+	case events.TypeCircuitBreakerOpened:
+		w.faulted[event.Elevator] = true
+		r.refreshCar(w, event.Elevator, event.Timestamp)
+	case events.TypeElevatorRecovered:
+		delete(w.faulted, event.Elevator)
+		r.refreshCar(w, event.Elevator, event.Timestamp)
+	case events.TypeElevatorRemoved:
+		// The status update without the car records it offline
+		delete(w.faulted, event.Elevator)
+	}
+}
+
+func (r *Recorder) recordRequest(w *buildingWatch, event events.Event) {
+	record := RequestRecord{
+		Building:    w.building,
+		TripID:      stringData(event, "trip_id"),
+		Elevator:    event.Elevator,
+		Outcome:     OutcomeCompleted,
+		FromFloor:   intData(event, "from_floor"),
+		ToFloor:     intData(event, "to_floor"),
+		Direction:   stringData(event, "direction"),
+		Priority:    stringData(event, "priority"),
+		RequestedAt: event.Timestamp,
+		FinishedAt:  event.Timestamp,
+	}
+	switch event.Type {
+	case events.TypeRequestCancelled:
+		record.Outcome = OutcomeCancelled
+	case events.TypeRequestRejected:
+		record.Outcome = OutcomeRejected
+	}
+	if requestedAt, ok := event.Data["requested_at"].(time.Time); ok {
+		record.RequestedAt = requestedAt
+	}
+	if pickedUpAt, ok := event.Data["picked_up_at"].(time.Time); ok {
+		record.PickedUpAt = &pickedUpAt
+	}
+	if droppedOffAt, ok := event.Data["dropped_off_at"].(time.Time); ok {
+		record.FinishedAt = droppedOffAt
+	}
+
+	if err := r.store.AppendRequest(record); err != nil {
+		r.writeFailed(w, err)
+	}
+}
+
+// recordStatus records the cars whose state changed and the cars that left
+// the pool
+func (r *Recorder) recordStatus(w *buildingWatch, update *broadcast.Update) {
+	w.status = update.Status
+	for name := range update.Status {
+		r.refreshCar(w, name, update.Timestamp)
+	}
+	for name := range w.cars {
+		if _, ok := update.Status[name]; !ok {
+			r.refreshCar(w, name, update.Timestamp)
+		}
+	}
+}
+
+// refreshCar records a car's state if it changed
+func (r *Recorder) refreshCar(w *buildingWatch, name string, at time.Time) {
+	state := CarOffline
+	if status, ok := w.status[name]; ok {
+		switch {
+		case status.IsDeleting:
+			state = CarDeleting
+		case w.faulted[name]:
+			state = CarFaulted
+		case status.Direction == domain.DirectionUp:
+			state = CarUp
+		case status.Direction == domain.DirectionDown:
+			state = CarDown
+		default:
+			state = CarIdle
+		}
+	}
+
+	last, known := w.cars[name]
+	if (known && last.State == state) || (!known && state == CarOffline) {
+		return
+	}
+	r.recordCar(w, name, state, at)
+}
+
+func (r *Recorder) recordCar(w *buildingWatch, name string, state CarState, at time.Time) {
+	floor := w.cars[name].Floor
+	if status, ok := w.status[name]; ok {
+		floor = status.CurrentFloor.Value()
+	}
+	record := CarRecord{
+		Building: w.building,
+		Elevator: name,
+		State:    state,
+		Floor:    floor,
+		At:       at,
+	}
+	if state == CarOffline {
+		delete(w.cars, name)
+	} else {
+		w.cars[name] = record
+	}
+
+	if err := r.store.AppendCar(record); err != nil {
+		r.writeFailed(w, err)
+	}
+}
+
+func (r *Recorder) writeFailed(w *buildingWatch, err error) {
+	r.logger.Error("failed to record analytics",
+		slog.String("building", w.building),
+		slog.String("error", err.Error()))
+	metrics.IncError("analytics_write_failed", constants.ComponentAnalytics)
+}
+
+func stringData(event events.Event, key string) string {
+	value, _ := event.Data[key].(string)
+	return value
+}
+
+func intData(event events.Event, key string) int {
+	value, _ := event.Data[key].(int)
+	return value
+}
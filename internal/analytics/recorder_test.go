@@ -0,0 +1,159 @@
+package analytics
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slavakukuyev/elevator-go/internal/broadcast"
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+	"github.com/slavakukuyev/elevator-go/internal/events"
+)
+
+// fakeBuilding publishes events and the status set on it
+type fakeBuilding struct {
+	bus    *events.Bus
+	status *broadcast.Broadcaster
+
+	mu   sync.Mutex
+	cars map[string]domain.ElevatorStatus
+}
+
+func newFakeBuilding(t *testing.T) *fakeBuilding {
+	t.Helper()
+	b := &fakeBuilding{bus: events.NewBus(100), cars: make(map[string]domain.ElevatorStatus)}
+	b.status = broadcast.New(b, time.Millisecond, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go b.status.Run(ctx)
+	return b
+}
+
+func (b *fakeBuilding) StatusSnapshot() map[string]domain.ElevatorStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	status := make(map[string]domain.ElevatorStatus, len(b.cars))
+	for name, car := range b.cars {
+		status[name] = car
+	}
+	return status
+}
+
+func (b *fakeBuilding) set(name string, floor int, direction domain.Direction) {
+	b.mu.Lock()
+	b.cars[name] = domain.NewElevatorStatus(name, domain.NewFloor(floor), direction, 0, domain.NewFloor(0), domain.NewFloor(9))
+	b.mu.Unlock()
+	b.status.Notify()
+}
+
+func (b *fakeBuilding) remove(name string) {
+	b.mu.Lock()
+	delete(b.cars, name)
+	b.mu.Unlock()
+	b.status.Notify()
+}
+
+func (b *fakeBuilding) Events() *events.Bus                 { return b.bus }
+func (b *fakeBuilding) Broadcaster() *broadcast.Broadcaster { return b.status }
+
+// carStates returns the states recorded for a car in order
+func carStates(t *testing.T, store *Store, elevator string) []CarState {
+	t.Helper()
+	var states []CarState
+	now := time.Now()
+	for _, e := range scanAll(t, store, now, now) {
+		if e.Car != nil && e.Car.Elevator == elevator {
+			states = append(states, e.Car.State)
+		}
+	}
+	return states
+}
+
+func TestRecorder_RecordsCarStates(t *testing.T) {
+	store, _ := openTestStore(t)
+	building := newFakeBuilding(t)
+	recorder := NewRecorder(store)
+	recorder.Watch("main", building)
+
+	building.set("A", 0, domain.DirectionIdle)
+	require.Eventually(t, func() bool { return len(carStates(t, store, "A")) == 1 }, time.Second, time.Millisecond)
+
+	// A move between floors in the same direction is not a state change
+	building.set("A", 1, domain.DirectionUp)
+	require.Eventually(t, func() bool { return len(carStates(t, store, "A")) == 2 }, time.Second, time.Millisecond)
+	building.set("A", 2, domain.DirectionUp)
+
+	building.bus.Publish(events.TypeCircuitBreakerOpened, "A", nil)
+	require.Eventually(t, func() bool { return len(carStates(t, store, "A")) == 3 }, time.Second, time.Millisecond)
+	building.bus.Publish(events.TypeElevatorRecovered, "A", nil)
+	require.Eventually(t, func() bool { return len(carStates(t, store, "A")) == 4 }, time.Second, time.Millisecond)
+
+	building.set("B", 5, domain.DirectionDeleting)
+	require.Eventually(t, func() bool { return len(carStates(t, store, "B")) == 1 }, time.Second, time.Millisecond)
+	building.remove("B")
+	require.Eventually(t, func() bool { return len(carStates(t, store, "B")) == 2 }, time.Second, time.Millisecond)
+
+	// Stopping takes the cars still observed offline
+	recorder.Stop()
+	assert.Equal(t, []CarState{CarIdle, CarUp, CarFaulted, CarUp, CarOffline}, carStates(t, store, "A"))
+	assert.Equal(t, []CarState{CarDeleting, CarOffline}, carStates(t, store, "B"))
+}
+
+func TestRecorder_RecordsRequests(t *testing.T) {
+	store, _ := openTestStore(t)
+	building := newFakeBuilding(t)
+	recorder := NewRecorder(store)
+	recorder.Watch("main", building)
+	defer recorder.Stop()
+
+	requestedAt := time.Now().Add(-time.Minute)
+	pickedUpAt := requestedAt.Add(20 * time.Second)
+	building.bus.Publish(events.TypeRequestAssigned, "A", map[string]any{"trip_id": "trip-1"})
+	completed := building.bus.Publish(events.TypeRequestCompleted, "A", map[string]any{
+		"trip_id": "trip-1", "from_floor": 1, "to_floor": 4, "direction": "up", "priority": "normal",
+		"requested_at": requestedAt, "picked_up_at": pickedUpAt,
+	})
+	building.bus.Publish(events.TypeRequestCancelled, "B", map[string]any{
+		"trip_id": "trip-2", "from_floor": 3, "to_floor": 0, "direction": "down", "requested_at": requestedAt,
+	})
+	rejected := building.bus.Publish(events.TypeRequestRejected, "", map[string]any{
+		"from_floor": 2, "to_floor": 7, "direction": "up", "reason": "no suitable elevator found",
+	})
+
+	var requests []RequestRecord
+	require.Eventually(t, func() bool {
+		requests = requests[:0]
+		now := time.Now()
+		for _, e := range scanAll(t, store, now, now) {
+			if e.Request != nil {
+				requests = append(requests, *e.Request)
+			}
+		}
+		return len(requests) == 3
+	}, time.Second, time.Millisecond)
+
+	assert.Equal(t, OutcomeCompleted, requests[0].Outcome)
+	assert.Equal(t, "main", requests[0].Building)
+	assert.Equal(t, "trip-1", requests[0].TripID)
+	assert.Equal(t, 1, requests[0].FromFloor)
+	assert.Equal(t, 4, requests[0].ToFloor)
+	assert.True(t, requests[0].RequestedAt.Equal(requestedAt))
+	assert.True(t, requests[0].FinishedAt.Equal(completed.Timestamp))
+	wait, ok := requests[0].Wait()
+	require.True(t, ok)
+	assert.Equal(t, 20*time.Second, wait)
+	_, ok = requests[0].Ride()
+	assert.True(t, ok)
+
+	assert.Equal(t, OutcomeCancelled, requests[1].Outcome)
+	_, ok = requests[1].Wait()
+	assert.False(t, ok)
+
+	assert.Equal(t, OutcomeRejected, requests[2].Outcome)
+	assert.Empty(t, requests[2].Elevator)
+	assert.True(t, requests[2].RequestedAt.Equal(rejected.Timestamp))
+}
@@ -0,0 +1,354 @@
+package analytics
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+)
+
+// MaxPeaks bounds the busiest hours a report lists
+const MaxPeaks = 100
+
+// Query selects the records a report covers
+type Query struct {
+	Building string    // Every building when empty
+	From     time.Time // Inclusive
+	To       time.Time // Exclusive
+	Peaks    int       // Busiest hours to list
+}
+
+// Report describes the traffic of a time range. Requests belong to the range
+// they were made in; times are in seconds and hours in UTC.
+type Report struct {
+	Building    string           `json:"building,omitempty"`
+	From        time.Time        `json:"from"`
+	To          time.Time        `json:"to"`
+	Requests    RequestSummary   `json:"requests"`
+	WaitTime    Distribution     `json:"wait_time"` // Request to pickup
+	RideTime    Distribution     `json:"ride_time"` // Pickup to drop-off
+	Heatmap     Heatmap          `json:"heatmap"`
+	Peaks       []HourStats      `json:"peaks"`
+	Utilization []CarUtilization `json:"utilization"`
+}
+
+// RequestSummary counts requests by outcome
+type RequestSummary struct {
+	Total         int     `json:"total"`
+	Completed     int     `json:"completed"`
+	Cancelled     int     `json:"cancelled"`
+	Rejected      int     `json:"rejected"`
+	RejectedRatio float64 `json:"rejected_ratio"`
+}
+
+// Distribution summarises durations in seconds
+type Distribution struct {
+	Count int     `json:"count"`
+	Mean  float64 `json:"mean"`
+	P50   float64 `json:"p50"`
+	P90   float64 `json:"p90"`
+	P95   float64 `json:"p95"`
+	P99   float64 `json:"p99"`
+	Max   float64 `json:"max"`
+}
+
+// Heatmap counts requests by the hour of day they were made and the floor
+// they were made from: Hours[h][i] is the number of requests from Floors[i]
+// during hour h
+type Heatmap struct {
+	Floors []int   `json:"floors"`
+	Hours  [][]int `json:"hours"`
+}
+
+// HourStats is the traffic of one hour of the range
+type HourStats struct {
+	Start    time.Time `json:"start"`
+	Requests int       `json:"requests"`
+	Rejected int       `json:"rejected"`
+	WaitP95  float64   `json:"wait_p95"`
+}
+
+// CarUtilization is how a car spent the part of the range it was observed
+type CarUtilization struct {
+	Building           string  `json:"building"`
+	Elevator           string  `json:"elevator"`
+	BusySeconds        float64 `json:"busy_seconds"`        // Moving up or down
+	IdleSeconds        float64 `json:"idle_seconds"`        // Waiting for requests
+	UnavailableSeconds float64 `json:"unavailable_seconds"` // Faulted or being deleted
+	ObservedSeconds    float64 `json:"observed_seconds"`
+	Utilization        float64 `json:"utilization"` // Busy share of the observed time
+}
+
+// Requests returns the stored requests of a building, or of every building
+// when empty, made within a range, in the order they were made
+func (s *Store) Requests(building string, from, to time.Time) ([]RequestRecord, error) {
+	var requests []RequestRecord
+	err := s.scan(from, to, func(e entry) {
+		if e.Request != nil && e.Request.within(building, from, to) {
+			requests = append(requests, *e.Request)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(requests, func(i, j int) bool {
+		return requests[i].RequestedAt.Before(requests[j].RequestedAt)
+	})
+	return requests, nil
+}
+
+// Report builds the report of a query from the stored records
+func (s *Store) Report(q Query) (Report, error) {
+	return s.report(q, time.Now())
+}
+
+func (s *Store) report(q Query, now time.Time) (Report, error) {
+	if !q.To.After(q.From) {
+		return Report{}, domain.NewValidationError("analytics range must end after it starts", nil).
+			WithContext("from", q.From).
+			WithContext("to", q.To)
+	}
+	if q.Peaks < 0 || q.Peaks > MaxPeaks {
+		return Report{}, domain.NewValidationError("peaks must be between 0 and 100", nil).
+			WithContext("peaks", q.Peaks)
+	}
+
+	// Cars are only observed until now
+	end := q.To
+	if now.Before(end) {
+		end = now
+	}
+
+	var requests []RequestRecord
+	cars := newUtilizationTracker(q.From, end)
+	err := s.scan(q.From, q.To, func(e entry) {
+		switch {
+		case e.Request != nil:
+			if e.Request.within(q.Building, q.From, q.To) {
+				requests = append(requests, *e.Request)
+			}
+		case e.Car != nil:
+			if q.Building == "" || e.Car.Building == q.Building {
+				cars.add(*e.Car)
+			}
+		}
+	})
+	if err != nil {
+		return Report{}, err
+	}
+
+	report := Report{
+		Building:    q.Building,
+		From:        q.From,
+		To:          q.To,
+		Requests:    summarise(requests),
+		Heatmap:     heatmap(requests),
+		Peaks:       peaks(requests, q.Peaks),
+		Utilization: cars.finish(),
+	}
+
+	var waits, rides []float64
+	for _, record := range requests {
+		if wait, ok := record.Wait(); ok {
+			waits = append(waits, wait.Seconds())
+		}
+		if ride, ok := record.Ride(); ok {
+			rides = append(rides, ride.Seconds())
+		}
+	}
+	report.WaitTime = distribution(waits)
+	report.RideTime = distribution(rides)
+	return report, nil
+}
+
+func summarise(requests []RequestRecord) RequestSummary {
+	summary := RequestSummary{Total: len(requests)}
+	for _, record := range requests {
+		switch record.Outcome {
+		case OutcomeCompleted:
+			summary.Completed++
+		case OutcomeCancelled:
+			summary.Cancelled++
+		case OutcomeRejected:
+			summary.Rejected++
+		}
+	}
+	if summary.Total > 0 {
+		summary.RejectedRatio = float64(summary.Rejected) / float64(summary.Total)
+	}
+	return summary
+}
+
+func heatmap(requests []RequestRecord) Heatmap {
+	floors := make(map[int]bool)
+	for _, record := range requests {
+		floors[record.FromFloor] = true
+	}
+
+	h := Heatmap{Floors: make([]int, 0, len(floors)), Hours: make([][]int, 24)}
+	for floor := range floors {
+		h.Floors = append(h.Floors, floor)
+	}
+	sort.Ints(h.Floors)
+
+	column := make(map[int]int, len(h.Floors))
+	for i, floor := range h.Floors {
+		column[floor] = i
+	}
+	for hour := range h.Hours {
+		h.Hours[hour] = make([]int, len(h.Floors))
+	}
+	for _, record := range requests {
+		h.Hours[record.RequestedAt.UTC().Hour()][column[record.FromFloor]]++
+	}
+	return h
+}
+
+// peaks returns the n hours with the most requests, busiest first
+func peaks(requests []RequestRecord, n int) []HourStats {
+	type bucket struct {
+		stats HourStats
+		waits []float64
+	}
+	buckets := make(map[time.Time]*bucket)
+	for _, record := range requests {
+		start := record.RequestedAt.UTC().Truncate(time.Hour)
+		b, ok := buckets[start]
+		if !ok {
+			b = &bucket{stats: HourStats{Start: start}}
+			buckets[start] = b
+		}
+		b.stats.Requests++
+		if record.Outcome == OutcomeRejected {
+			b.stats.Rejected++
+		}
+		if wait, ok := record.Wait(); ok {
+			b.waits = append(b.waits, wait.Seconds())
+		}
+	}
+
+	hours := make([]HourStats, 0, len(buckets))
+	for _, b := range buckets {
+		if len(b.waits) > 0 {
+			b.stats.WaitP95 = nearestRank(b.waits, 95)
+		}
+		hours = append(hours, b.stats)
+	}
+	sort.Slice(hours, func(i, j int) bool {
+		if hours[i].Requests != hours[j].Requests {
+			return hours[i].Requests > hours[j].Requests
+		}
+		return hours[i].Start.Before(hours[j].Start)
+	})
+	return hours[:min(n, len(hours))]
+}
+
+func distribution(values []float64) Distribution {
+	if len(values) == 0 {
+		return Distribution{}
+	}
+
+	sum := 0.0
+	for _, value := range values {
+		sum += value
+	}
+	return Distribution{
+		Count: len(values),
+		Mean:  sum / float64(len(values)),
+		P50:   nearestRank(values, 50),
+		P90:   nearestRank(values, 90),
+		P95:   nearestRank(values, 95),
+		P99:   nearestRank(values, 99),
+		Max:   nearestRank(values, 100),
+	}
+}
+
+// nearestRank returns the nearest-rank percentile of values, which it sorts
+func nearestRank(values []float64, percentile float64) float64 {
+	sort.Float64s(values)
+	rank := int(math.Ceil(percentile / 100 * float64(len(values))))
+	rank = min(max(rank, 1), len(values))
+	return values[rank-1]
+}
+
+// carSpan is the state a car has been in since a point in time
+type carSpan struct {
+	state CarState
+	since time.Time
+	usage CarUtilization
+}
+
+// utilizationTracker adds up the time every car spends in each state from
+// one point in time to another from its state changes in the order they were
+// recorded
+type utilizationTracker struct {
+	from, to time.Time
+	cars     map[carKey]*carSpan
+}
+
+func newUtilizationTracker(from, to time.Time) *utilizationTracker {
+	return &utilizationTracker{from: from, to: to, cars: make(map[carKey]*carSpan)}
+}
+
+func (t *utilizationTracker) add(record CarRecord) {
+	if !record.At.Before(t.to) {
+		return
+	}
+
+	key := carKey{building: record.Building, elevator: record.Elevator}
+	span, ok := t.cars[key]
+	if !ok {
+		span = &carSpan{usage: CarUtilization{Building: record.Building, Elevator: record.Elevator}}
+		t.cars[key] = span
+	} else {
+		span.close(t.from, record.At)
+	}
+	span.state = record.State
+	span.since = record.At
+}
+
+// close counts the time from the span's start to end, clipped to the range
+func (s *carSpan) close(from, end time.Time) {
+	start := s.since
+	if start.Before(from) {
+		start = from
+	}
+	if !end.After(start) {
+		return
+	}
+
+	seconds := end.Sub(start).Seconds()
+	switch {
+	case s.state == CarOffline:
+		return
+	case s.state.IsBusy():
+		s.usage.BusySeconds += seconds
+	case s.state == CarIdle:
+		s.usage.IdleSeconds += seconds
+	default:
+		s.usage.UnavailableSeconds += seconds
+	}
+	s.usage.ObservedSeconds += seconds
+}
+
+// finish closes every span at the end and returns the cars that were
+// observed
+func (t *utilizationTracker) finish() []CarUtilization {
+	usage := make([]CarUtilization, 0, len(t.cars))
+	for _, span := range t.cars {
+		span.close(t.from, t.to)
+		if span.usage.ObservedSeconds == 0 {
+			continue
+		}
+		span.usage.Utilization = span.usage.BusySeconds / span.usage.ObservedSeconds
+		usage = append(usage, span.usage)
+	}
+	sort.Slice(usage, func(i, j int) bool {
+		if usage[i].Building != usage[j].Building {
+			return usage[i].Building < usage[j].Building
+		}
+		return usage[i].Elevator < usage[j].Elevator
+	})
+	return usage
+}
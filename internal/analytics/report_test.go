@@ -0,0 +1,138 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+)
+
+func completed(building string, from int, requested time.Time, wait, ride time.Duration) RequestRecord {
+	pickedUp := requested.Add(wait)
+	return RequestRecord{
+		Building: building, Elevator: "A", Outcome: OutcomeCompleted, FromFloor: from, ToFloor: from + 1,
+		RequestedAt: requested, PickedUpAt: &pickedUp, FinishedAt: pickedUp.Add(ride),
+	}
+}
+
+func TestStore_Report(t *testing.T) {
+	store, _ := openTestStore(t)
+	start := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -1).Add(8 * time.Hour)
+
+	for _, record := range []RequestRecord{
+		completed("main", 0, start.Add(time.Minute), 10*time.Second, 20*time.Second),
+		completed("main", 0, start.Add(2*time.Minute), 30*time.Second, 40*time.Second),
+		completed("main", 5, start.Add(time.Hour+time.Minute), 20*time.Second, 10*time.Second),
+		{Building: "main", Outcome: OutcomeRejected, FromFloor: 5, ToFloor: 0,
+			RequestedAt: start.Add(time.Hour + 2*time.Minute), FinishedAt: start.Add(time.Hour + 2*time.Minute)},
+		{Building: "main", Elevator: "A", Outcome: OutcomeCancelled, FromFloor: 0, ToFloor: 3,
+			RequestedAt: start.Add(3 * time.Minute), FinishedAt: start.Add(4 * time.Minute)},
+		// Another building and a request before the range
+		completed("annex", 0, start.Add(time.Minute), time.Second, time.Second),
+		completed("main", 2, start.Add(-time.Hour), time.Second, time.Second),
+	} {
+		require.NoError(t, store.AppendRequest(record))
+	}
+
+	// A is idle for 30m, busy for 30m, faulted for an hour, then idle until
+	// the range ends
+	for _, record := range []CarRecord{
+		{Building: "main", Elevator: "A", State: CarIdle, At: start.Add(-time.Hour)},
+		{Building: "main", Elevator: "A", State: CarUp, At: start.Add(30 * time.Minute)},
+		{Building: "main", Elevator: "A", State: CarFaulted, At: start.Add(time.Hour)},
+		{Building: "main", Elevator: "A", State: CarIdle, At: start.Add(2 * time.Hour)},
+		{Building: "main", Elevator: "B", State: CarDown, At: start.Add(150 * time.Minute)},
+		{Building: "main", Elevator: "B", State: CarOffline, At: start.Add(165 * time.Minute)},
+		{Building: "main", Elevator: "C", State: CarIdle, At: start.Add(4 * time.Hour)},
+	} {
+		require.NoError(t, store.AppendCar(record))
+	}
+
+	report, err := store.report(Query{Building: "main", From: start, To: start.Add(3 * time.Hour), Peaks: 1}, time.Now())
+	require.NoError(t, err)
+
+	assert.Equal(t, RequestSummary{Total: 5, Completed: 3, Cancelled: 1, Rejected: 1, RejectedRatio: 0.2}, report.Requests)
+	assert.Equal(t, Distribution{Count: 3, Mean: 20, P50: 20, P90: 30, P95: 30, P99: 30, Max: 30}, report.WaitTime)
+	assert.Equal(t, 3, report.RideTime.Count)
+	assert.Equal(t, 40.0, report.RideTime.Max)
+
+	assert.Equal(t, []int{0, 5}, report.Heatmap.Floors)
+	require.Len(t, report.Heatmap.Hours, 24)
+	assert.Equal(t, []int{3, 0}, report.Heatmap.Hours[8])
+	assert.Equal(t, []int{0, 2}, report.Heatmap.Hours[9])
+	assert.Equal(t, []int{0, 0}, report.Heatmap.Hours[10])
+
+	require.Len(t, report.Peaks, 1)
+	assert.Equal(t, HourStats{Start: start, Requests: 3, WaitP95: 30}, report.Peaks[0])
+
+	require.Len(t, report.Utilization, 2, "C was only seen after the range")
+	a := report.Utilization[0]
+	assert.Equal(t, "A", a.Elevator)
+	assert.Equal(t, 1800.0, a.BusySeconds)
+	assert.Equal(t, 5400.0, a.IdleSeconds)
+	assert.Equal(t, 3600.0, a.UnavailableSeconds)
+	assert.Equal(t, 10800.0, a.ObservedSeconds)
+	assert.InDelta(t, 1.0/6, a.Utilization, 1e-9)
+	b := report.Utilization[1]
+	assert.Equal(t, 900.0, b.ObservedSeconds)
+	assert.Equal(t, 1.0, b.Utilization)
+
+	// Every building, and a range still running
+	report, err = store.report(Query{From: start, To: start.Add(3 * time.Hour)}, start.Add(2*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 6, report.Requests.Total)
+	assert.Empty(t, report.Peaks)
+	require.Len(t, report.Utilization, 1)
+	assert.Equal(t, 7200.0, report.Utilization[0].ObservedSeconds)
+}
+
+func TestStore_ReportValidation(t *testing.T) {
+	store, _ := openTestStore(t)
+	now := time.Now()
+
+	_, err := store.Report(Query{From: now, To: now})
+	require.Error(t, err)
+	assert.Equal(t, domain.ErrTypeValidation, err.(*domain.DomainError).Type)
+
+	_, err = store.Report(Query{From: now.Add(-time.Hour), To: now, Peaks: MaxPeaks + 1})
+	require.Error(t, err)
+	assert.Equal(t, domain.ErrTypeValidation, err.(*domain.DomainError).Type)
+
+	report, err := store.Report(Query{From: now.Add(-time.Hour), To: now})
+	require.NoError(t, err)
+	assert.Zero(t, report.Requests.Total)
+	assert.Empty(t, report.Heatmap.Floors)
+	assert.Len(t, report.Heatmap.Hours, 24)
+	assert.NotNil(t, report.Peaks)
+	assert.NotNil(t, report.Utilization)
+}
+
+func TestStore_Requests(t *testing.T) {
+	store, _ := openTestStore(t)
+	start := time.Now().UTC().Add(-time.Hour)
+
+	// Requests are written when they finish, so a slow one comes first
+	slow := completed("main", 3, start.Add(time.Minute), time.Minute, time.Minute)
+	fast := completed("main", 1, start.Add(2*time.Minute), time.Second, time.Second)
+	fast.FinishedAt = slow.FinishedAt.Add(-time.Minute)
+	for _, record := range []RequestRecord{
+		fast, slow,
+		completed("annex", 0, start.Add(time.Minute), time.Second, time.Second),
+		completed("main", 2, start.Add(-time.Minute), time.Second, time.Second),
+	} {
+		require.NoError(t, store.AppendRequest(record))
+	}
+
+	requests, err := store.Requests("main", start, start.Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, requests, 2)
+	assert.Equal(t, 3, requests[0].FromFloor)
+	assert.Equal(t, 1, requests[1].FromFloor)
+
+	requests, err = store.Requests("", start, start.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Len(t, requests, 3)
+}
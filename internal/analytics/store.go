@@ -0,0 +1,273 @@
+package analytics
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/slavakukuyev/elevator-go/internal/constants"
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+)
+
+const (
+	// fileLayout names the file of a UTC day
+	fileLayout = "2006-01-02"
+	// fileSuffix is the extension of store files
+	fileSuffix = ".jsonl"
+)
+
+// carKey identifies a car across buildings
+type carKey struct {
+	building string
+	elevator string
+}
+
+// Store appends records to one JSON-lines file per UTC day. The first
+// records of a day, when the store was already writing the day before, are
+// the states of the cars that were not offline, so the state of every car is
+// known from the start of any day's file.
+type Store struct {
+	dir       string
+	retention time.Duration
+	logger    *slog.Logger
+
+	mu     sync.Mutex
+	file   *os.File
+	day    time.Time            // UTC midnight of the open file
+	cars   map[carKey]CarRecord // Last state of the cars that are not offline
+	closed bool
+}
+
+// OpenStore opens the store in a directory, creating it if needed, and
+// deletes the files older than the retention
+func OpenStore(dir string, retention time.Duration) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, domain.NewInternalError("failed to create analytics directory", err).
+			WithContext("dir", dir)
+	}
+
+	s := &Store{
+		dir:       dir,
+		retention: retention,
+		logger:    slog.With(slog.String("component", constants.ComponentAnalytics)),
+		cars:      make(map[carKey]CarRecord),
+	}
+	s.prune(time.Now())
+	return s, nil
+}
+
+// AppendRequest records a finished request
+func (s *Store) AppendRequest(record RequestRecord) error {
+	return s.append(entry{Request: &record})
+}
+
+// AppendCar records a car entering a state
+func (s *Store) AppendCar(record CarRecord) error {
+	return s.append(entry{Car: &record})
+}
+
+func (s *Store) append(e entry) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return domain.NewInternalError("failed to encode analytics record", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return domain.NewConflictError("analytics store is closed", nil)
+	}
+
+	// Late records go to the open file rather than reopening an older day
+	if day := dayOf(e.time()); s.file == nil || day.After(s.day) {
+		if err := s.rotate(day); err != nil {
+			return err
+		}
+	}
+
+	if err := s.write(line); err != nil {
+		return err
+	}
+
+	if e.Car != nil {
+		key := carKey{building: e.Car.Building, elevator: e.Car.Elevator}
+		if e.Car.State == CarOffline {
+			delete(s.cars, key)
+		} else {
+			s.cars[key] = *e.Car
+		}
+	}
+	return nil
+}
+
+// rotate switches to the file of a day, carrying the car states over when
+// the store was writing the day before; callers must hold s.mu
+func (s *Store) rotate(day time.Time) error {
+	carryOver := s.file != nil
+	if carryOver {
+		if err := s.file.Close(); err != nil {
+			s.logger.Warn("failed to close analytics file", slog.String("error", err.Error()))
+		}
+		s.file = nil
+	}
+
+	path := filepath.Join(s.dir, day.Format(fileLayout)+fileSuffix)
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return domain.NewInternalError("failed to open analytics file", err).
+			WithContext("path", path)
+	}
+	s.file = file
+	s.day = day
+
+	if carryOver {
+		keys := make([]carKey, 0, len(s.cars))
+		for key := range s.cars {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			if keys[i].building != keys[j].building {
+				return keys[i].building < keys[j].building
+			}
+			return keys[i].elevator < keys[j].elevator
+		})
+		for _, key := range keys {
+			record := s.cars[key]
+			record.At = day
+			line, err := json.Marshal(entry{Car: &record})
+			if err != nil {
+				return domain.NewInternalError("failed to encode analytics record", err)
+			}
+			if err := s.write(line); err != nil {
+				return err
+			}
+		}
+	}
+
+	s.prune(day)
+	return nil
+}
+
+// write appends a line to the open file in a single write, so readers never
+// see half of one unless the process dies mid-write; callers must hold s.mu
+func (s *Store) write(line []byte) error {
+	if _, err := s.file.Write(append(line, '\n')); err != nil {
+		return domain.NewInternalError("failed to write analytics record", err).
+			WithContext("path", s.file.Name())
+	}
+	return nil
+}
+
+// prune deletes the files of the days that ended before the retention
+func (s *Store) prune(now time.Time) {
+	cutoff := dayOf(now.Add(-s.retention))
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		s.logger.Warn("failed to list analytics files", slog.String("error", err.Error()))
+		return
+	}
+
+	for _, dirEntry := range entries {
+		day, ok := fileDay(dirEntry.Name())
+		if !ok || !day.Before(cutoff) {
+			continue
+		}
+		path := filepath.Join(s.dir, dirEntry.Name())
+		if err := os.Remove(path); err != nil {
+			s.logger.Warn("failed to delete expired analytics file",
+				slog.String("path", path),
+				slog.String("error", err.Error()))
+			continue
+		}
+		s.logger.Info("deleted expired analytics file", slog.String("path", path))
+	}
+}
+
+// Close closes the open file. Appending to a closed store fails.
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	if err != nil {
+		return fmt.Errorf("failed to close analytics file: %w", err)
+	}
+	return nil
+}
+
+// scan calls fn for the entries of every day from the one holding from
+// through the day after the one holding to, in the order they were written.
+// Requests finish after they are made, so the extra day holds the requests
+// made late on the last day. Lines that cannot be decoded, such as one cut
+// short by a crash, are skipped.
+func (s *Store) scan(from, to time.Time, fn func(entry)) error {
+	last := dayOf(to).AddDate(0, 0, 1)
+	for day := dayOf(from); !day.After(last); day = day.AddDate(0, 0, 1) {
+		if err := s.scanFile(filepath.Join(s.dir, day.Format(fileLayout)+fileSuffix), fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Store) scanFile(path string, fn func(entry)) error {
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return domain.NewInternalError("failed to open analytics file", err).
+			WithContext("path", path)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			// A line without a newline is still being written
+			return nil
+		}
+		if err != nil {
+			return domain.NewInternalError("failed to read analytics file", err).
+				WithContext("path", path)
+		}
+
+		var e entry
+		if json.Unmarshal(line, &e) != nil || (e.Request == nil) == (e.Car == nil) {
+			continue
+		}
+		fn(e)
+	}
+}
+
+// dayOf returns the UTC midnight starting the day of t
+func dayOf(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
+
+// fileDay returns the day a store file holds
+func fileDay(name string) (time.Time, bool) {
+	date, ok := strings.CutSuffix(name, fileSuffix)
+	if !ok {
+		return time.Time{}, false
+	}
+	day, err := time.Parse(fileLayout, date)
+	return day, err == nil
+}
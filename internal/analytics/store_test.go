@@ -0,0 +1,99 @@
+package analytics
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+)
+
+func openTestStore(t *testing.T) (*Store, string) {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "analytics")
+	store, err := OpenStore(dir, 7*24*time.Hour)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	return store, dir
+}
+
+func scanAll(t *testing.T, store *Store, from, to time.Time) []entry {
+	t.Helper()
+	var entries []entry
+	require.NoError(t, store.scan(from, to, func(e entry) { entries = append(entries, e) }))
+	return entries
+}
+
+func TestStore_AppendsOneFilePerDay(t *testing.T) {
+	store, dir := openTestStore(t)
+	day := time.Now().UTC().Truncate(24 * time.Hour)
+
+	require.NoError(t, store.AppendCar(CarRecord{Building: "main", Elevator: "A", State: CarIdle, At: day.Add(-time.Hour)}))
+	require.NoError(t, store.AppendCar(CarRecord{Building: "main", Elevator: "B", State: CarUp, Floor: 3, At: day.Add(-time.Minute)}))
+	require.NoError(t, store.AppendCar(CarRecord{Building: "main", Elevator: "A", State: CarOffline, At: day.Add(-time.Second)}))
+	require.NoError(t, store.AppendRequest(RequestRecord{
+		Building: "main", Outcome: OutcomeRejected, FromFloor: 1, ToFloor: 4,
+		RequestedAt: day.Add(time.Minute), FinishedAt: day.Add(time.Minute),
+	}))
+
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	assert.Equal(t, day.AddDate(0, 0, -1).Format(fileLayout)+fileSuffix, files[0].Name())
+	assert.Equal(t, day.Format(fileLayout)+fileSuffix, files[1].Name())
+
+	// The new day starts with the state of every car that is not offline
+	entries := scanAll(t, store, day, day)
+	require.Len(t, entries, 2)
+	require.NotNil(t, entries[0].Car)
+	assert.Equal(t, CarRecord{Building: "main", Elevator: "B", State: CarUp, Floor: 3, At: day}, *entries[0].Car)
+	require.NotNil(t, entries[1].Request)
+	assert.Equal(t, OutcomeRejected, entries[1].Request.Outcome)
+
+	assert.Len(t, scanAll(t, store, day.AddDate(0, 0, -1), day), 5)
+
+	require.NoError(t, store.Close())
+	err = store.AppendCar(CarRecord{Building: "main", Elevator: "B", State: CarIdle, At: day})
+	require.Error(t, err)
+	assert.Equal(t, domain.ErrTypeConflict, err.(*domain.DomainError).Type)
+}
+
+func TestStore_SkipsDamagedLines(t *testing.T) {
+	store, dir := openTestStore(t)
+	now := time.Now().UTC()
+	require.NoError(t, store.AppendCar(CarRecord{Building: "main", Elevator: "A", State: CarIdle, At: now}))
+
+	path := filepath.Join(dir, now.Format(fileLayout)+fileSuffix)
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644)
+	require.NoError(t, err)
+	_, err = file.WriteString("not json\n{}\n{\"car\":{\"building\":\"main\"")
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	entries := scanAll(t, store, now, now)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "A", entries[0].Car.Elevator)
+}
+
+func TestOpenStore_DeletesExpiredFiles(t *testing.T) {
+	dir := t.TempDir()
+	today := time.Now().UTC()
+	expired := filepath.Join(dir, today.AddDate(0, 0, -10).Format(fileLayout)+fileSuffix)
+	kept := filepath.Join(dir, today.AddDate(0, 0, -2).Format(fileLayout)+fileSuffix)
+	unrelated := filepath.Join(dir, "notes.txt")
+	for _, path := range []string{expired, kept, unrelated} {
+		require.NoError(t, os.WriteFile(path, nil, 0o644))
+	}
+
+	store, err := OpenStore(dir, 7*24*time.Hour)
+	require.NoError(t, err)
+	defer store.Close()
+
+	assert.NoFileExists(t, expired)
+	assert.FileExists(t, kept)
+	assert.FileExists(t, unrelated)
+}
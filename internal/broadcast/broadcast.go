@@ -0,0 +1,299 @@
+// Package broadcast computes elevator status once per change and fans it out
+// to any number of subscribers.
+//
+// Elevators notify the broadcaster when their floor, direction, doors or
+// pending requests change. Notifications are coalesced, so a burst of changes
+// results in a single snapshot. Each snapshot is diffed against the previous
+// one and published only when something changed, together with the names of
+// the changed and removed elevators and a pre-encoded JSON payload that
+// connection handlers can write as-is.
+//
+// Every subscriber has a bounded queue. A subscriber that cannot keep up loses
+// its oldest queued updates instead of blocking the broadcaster or other
+// subscribers; since each update carries the full status and a sequence
+// number, a consumer only ever needs the newest one to be current.
+package broadcast
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/slavakukuyev/elevator-go/internal/constants"
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+	"github.com/slavakukuyev/elevator-go/metrics"
+)
+
+// DefaultSubscriberBuffer is used when a subscriber asks for a non-positive
+// queue size
+const DefaultSubscriberBuffer = 8
+
+// Source provides the current status of every elevator keyed by name
+type Source interface {
+	StatusSnapshot() map[string]domain.ElevatorStatus
+}
+
+// Update is a status snapshot that differs from the one before it. Updates are
+// shared between subscribers and must be treated as read-only.
+type Update struct {
+	Seq       uint64
+	Timestamp time.Time
+	// Status holds the full status of every elevator
+	Status map[string]domain.ElevatorStatus
+	// Changed lists elevators that were added or changed, sorted by name
+	Changed []string
+	// Removed lists elevators that left the pool, sorted by name
+	Removed []string
+	// JSON is Status encoded as a JSON object keyed by elevator name
+	JSON []byte
+}
+
+// Subscriber receives status updates on C until it is closed
+type Subscriber struct {
+	C <-chan *Update
+
+	ch          chan *Update
+	broadcaster *Broadcaster
+	id          uint64
+	dropped     uint64 // guarded by broadcaster.mu
+}
+
+// Close stops delivery and closes C. It is safe to call more than once.
+func (s *Subscriber) Close() {
+	s.broadcaster.unsubscribe(s.id)
+}
+
+// Dropped returns how many updates were discarded because the subscriber's
+// queue was full
+func (s *Subscriber) Dropped() uint64 {
+	s.broadcaster.mu.Lock()
+	defer s.broadcaster.mu.Unlock()
+	return s.dropped
+}
+
+// Broadcaster turns change notifications into status updates for subscribers
+type Broadcaster struct {
+	source   Source
+	interval atomic.Int64
+	logger   *slog.Logger
+	notify   chan struct{}
+	retick   chan struct{}
+
+	mu          sync.Mutex
+	latest      *Update
+	subscribers map[uint64]*Subscriber
+	nextID      uint64
+}
+
+// New creates a broadcaster reading from source. The interval is a safety net
+// that recomputes the status periodically even without notifications; a
+// non-positive interval falls back to constants.StatusUpdateInterval.
+func New(source Source, interval time.Duration, logger *slog.Logger) *Broadcaster {
+	if interval <= 0 {
+		interval = constants.StatusUpdateInterval
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	b := &Broadcaster{
+		source:      source,
+		logger:      logger.With(slog.String("component", constants.ComponentBroadcaster)),
+		notify:      make(chan struct{}, 1),
+		retick:      make(chan struct{}, 1),
+		subscribers: make(map[uint64]*Subscriber),
+	}
+	b.interval.Store(int64(interval))
+	return b
+}
+
+// SetInterval changes the safety-net refresh interval of a running
+// broadcaster; non-positive intervals are ignored
+func (b *Broadcaster) SetInterval(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	b.interval.Store(int64(interval))
+	select {
+	case b.retick <- struct{}{}:
+	default:
+	}
+}
+
+// Interval returns the safety-net refresh interval
+func (b *Broadcaster) Interval() time.Duration {
+	return time.Duration(b.interval.Load())
+}
+
+// Run computes and publishes updates until ctx is cancelled, then closes all
+// subscribers
+func (b *Broadcaster) Run(ctx context.Context) {
+	ticker := time.NewTicker(b.Interval())
+	defer ticker.Stop()
+	defer b.closeAll()
+
+	b.refresh()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-b.notify:
+			b.refresh()
+		case <-ticker.C:
+			b.refresh()
+		case <-b.retick:
+			ticker.Reset(b.Interval())
+		}
+	}
+}
+
+// Notify signals that the status may have changed. It never blocks; pending
+// notifications are coalesced into one refresh.
+func (b *Broadcaster) Notify() {
+	select {
+	case b.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Subscribe registers a subscriber with a queue of the given size. The latest
+// update, if any, is queued immediately so the subscriber starts current.
+func (b *Broadcaster) Subscribe(buffer int) *Subscriber {
+	if buffer <= 0 {
+		buffer = DefaultSubscriberBuffer
+	}
+
+	b.mu.Lock()
+	b.nextID++
+	ch := make(chan *Update, buffer)
+	sub := &Subscriber{C: ch, ch: ch, broadcaster: b, id: b.nextID}
+	b.subscribers[sub.id] = sub
+	latest := b.latest
+	if latest != nil {
+		ch <- latest
+	}
+	count := len(b.subscribers)
+	b.mu.Unlock()
+
+	metrics.SetStatusSubscribers(float64(count))
+	if latest == nil {
+		b.Notify()
+	}
+	return sub
+}
+
+// Latest returns the most recent update, or nil before the first refresh
+func (b *Broadcaster) Latest() *Update {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.latest
+}
+
+// refresh takes a snapshot and publishes it if it differs from the last one
+func (b *Broadcaster) refresh() {
+	status := b.source.StatusSnapshot()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var previous map[string]domain.ElevatorStatus
+	if b.latest != nil {
+		previous = b.latest.Status
+	}
+	changed, removed := diff(previous, status)
+	if b.latest != nil && len(changed) == 0 && len(removed) == 0 {
+		return
+	}
+
+	payload, err := json.Marshal(status)
+	if err != nil {
+		b.logger.Error("failed to encode status update", slog.String("error", err.Error()))
+		metrics.IncError("status_encode_failed", constants.ComponentBroadcaster)
+		return
+	}
+
+	var seq uint64 = 1
+	if b.latest != nil {
+		seq = b.latest.Seq + 1
+	}
+	update := &Update{
+		Seq:       seq,
+		Timestamp: time.Now(),
+		Status:    status,
+		Changed:   changed,
+		Removed:   removed,
+		JSON:      payload,
+	}
+	b.latest = update
+
+	for _, sub := range b.subscribers {
+		b.deliver(sub, update)
+	}
+}
+
+// deliver queues an update, discarding the oldest queued update when the
+// subscriber is full; callers must hold b.mu
+func (b *Broadcaster) deliver(sub *Subscriber, update *Update) {
+	for {
+		select {
+		case sub.ch <- update:
+			return
+		default:
+		}
+
+		select {
+		case <-sub.ch:
+			sub.dropped++
+			metrics.IncStatusUpdatesDropped()
+		default:
+			// The consumer drained the queue in the meantime; retry the send
+		}
+	}
+}
+
+func (b *Broadcaster) unsubscribe(id uint64) {
+	b.mu.Lock()
+	sub, ok := b.subscribers[id]
+	if ok {
+		delete(b.subscribers, id)
+		close(sub.ch)
+	}
+	count := len(b.subscribers)
+	b.mu.Unlock()
+
+	if ok {
+		metrics.SetStatusSubscribers(float64(count))
+	}
+}
+
+func (b *Broadcaster) closeAll() {
+	b.mu.Lock()
+	for id, sub := range b.subscribers {
+		delete(b.subscribers, id)
+		close(sub.ch)
+	}
+	b.mu.Unlock()
+
+	metrics.SetStatusSubscribers(0)
+}
+
+// diff returns the sorted names of elevators added or changed in current and
+// removed since previous
+func diff(previous, current map[string]domain.ElevatorStatus) (changed, removed []string) {
+	for name, status := range current {
+		if old, ok := previous[name]; !ok || old != status {
+			changed = append(changed, name)
+		}
+	}
+	for name := range previous {
+		if _, ok := current[name]; !ok {
+			removed = append(removed, name)
+		}
+	}
+	sort.Strings(changed)
+	sort.Strings(removed)
+	return changed, removed
+}
@@ -0,0 +1,199 @@
+package broadcast
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+)
+
+// fakeSource returns a settable status and counts snapshots taken
+type fakeSource struct {
+	mu        sync.Mutex
+	status    map[string]domain.ElevatorStatus
+	snapshots int
+}
+
+func (f *fakeSource) StatusSnapshot() map[string]domain.ElevatorStatus {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.snapshots++
+	out := make(map[string]domain.ElevatorStatus, len(f.status))
+	for name, status := range f.status {
+		out[name] = status
+	}
+	return out
+}
+
+func (f *fakeSource) set(name string, floor int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.status == nil {
+		f.status = make(map[string]domain.ElevatorStatus)
+	}
+	f.status[name] = domain.ElevatorStatus{Name: name, CurrentFloor: domain.NewFloor(floor), Position: float64(floor)}
+}
+
+func (f *fakeSource) remove(name string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.status, name)
+}
+
+func TestBroadcaster_PublishesDiffOnlyOnChange(t *testing.T) {
+	source := &fakeSource{}
+	source.set("A", 0)
+	source.set("B", 3)
+	b := New(source, time.Hour, nil)
+
+	b.refresh()
+	first := b.Latest()
+	require.NotNil(t, first)
+	assert.Equal(t, uint64(1), first.Seq)
+	assert.Equal(t, []string{"A", "B"}, first.Changed)
+	assert.Empty(t, first.Removed)
+	assert.JSONEq(t, `{
+		"A": {"name":"A","current_floor":0,"direction":"","requests":0,"min_floor":0,"max_floor":0,"is_deleting":false,"door_open":false,"position":0,"velocity":0},
+		"B": {"name":"B","current_floor":3,"direction":"","requests":0,"min_floor":0,"max_floor":0,"is_deleting":false,"door_open":false,"position":3,"velocity":0}
+	}`, string(first.JSON))
+
+	// Nothing changed, nothing published
+	b.refresh()
+	assert.Same(t, first, b.Latest())
+
+	source.set("A", 1)
+	source.remove("B")
+	b.refresh()
+	second := b.Latest()
+	assert.Equal(t, uint64(2), second.Seq)
+	assert.Equal(t, []string{"A"}, second.Changed)
+	assert.Equal(t, []string{"B"}, second.Removed)
+	assert.Len(t, second.Status, 1)
+}
+
+func TestBroadcaster_FirstSnapshotIsPublishedWhenEmpty(t *testing.T) {
+	b := New(&fakeSource{}, time.Hour, nil)
+	sub := b.Subscribe(1)
+	defer sub.Close()
+
+	b.refresh()
+
+	update := <-sub.C
+	assert.Equal(t, uint64(1), update.Seq)
+	assert.JSONEq(t, `{}`, string(update.JSON))
+}
+
+func TestBroadcaster_SubscribeStartsWithLatest(t *testing.T) {
+	source := &fakeSource{}
+	source.set("A", 4)
+	b := New(source, time.Hour, nil)
+	b.refresh()
+
+	sub := b.Subscribe(2)
+	defer sub.Close()
+
+	select {
+	case update := <-sub.C:
+		assert.Equal(t, 4, update.Status["A"].CurrentFloor.Value())
+	default:
+		t.Fatal("subscriber did not receive the latest update")
+	}
+}
+
+func TestBroadcaster_SlowSubscriberDropsOldest(t *testing.T) {
+	source := &fakeSource{}
+	b := New(source, time.Hour, nil)
+
+	slow := b.Subscribe(2)
+	defer slow.Close()
+	fast := b.Subscribe(8)
+	defer fast.Close()
+
+	for floor := 0; floor < 5; floor++ {
+		source.set("A", floor)
+		b.refresh()
+	}
+
+	// The slow subscriber keeps only the two newest updates
+	assert.Equal(t, uint64(3), slow.Dropped())
+	assert.Equal(t, uint64(4), (<-slow.C).Seq)
+	assert.Equal(t, uint64(5), (<-slow.C).Seq)
+
+	// Other subscribers are unaffected
+	assert.Zero(t, fast.Dropped())
+	assert.Len(t, fast.C, 5)
+}
+
+func TestBroadcaster_RunCoalescesNotificationsAndClosesOnCancel(t *testing.T) {
+	source := &fakeSource{}
+	b := New(source, time.Hour, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		b.Run(ctx)
+	}()
+
+	sub := b.Subscribe(4)
+	initial := <-sub.C
+	assert.Equal(t, uint64(1), initial.Seq)
+
+	source.set("A", 2)
+	for i := 0; i < 10; i++ {
+		b.Notify()
+	}
+
+	select {
+	case update := <-sub.C:
+		assert.Equal(t, []string{"A"}, update.Changed)
+	case <-time.After(2 * time.Second):
+		t.Fatal("no update after notify")
+	}
+
+	source.mu.Lock()
+	snapshots := source.snapshots
+	source.mu.Unlock()
+	assert.LessOrEqual(t, snapshots, 4, "notifications should be coalesced")
+
+	cancel()
+	<-done
+
+	_, ok := <-sub.C
+	assert.False(t, ok, "subscribers are closed when the broadcaster stops")
+
+	// Closing after shutdown is a no-op
+	sub.Close()
+}
+
+func TestBroadcaster_SetIntervalRetimesRunningTicker(t *testing.T) {
+	source := &fakeSource{}
+	source.set("A", 0)
+	b := New(source, time.Hour, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go b.Run(ctx)
+
+	sub := b.Subscribe(4)
+	<-sub.C
+
+	b.SetInterval(10 * time.Millisecond)
+	assert.Equal(t, 10*time.Millisecond, b.Interval())
+	b.SetInterval(0)
+	assert.Equal(t, 10*time.Millisecond, b.Interval(), "non-positive intervals are ignored")
+
+	// Only the ticker picks up changes made without a notification
+	source.set("A", 4)
+	select {
+	case update := <-sub.C:
+		assert.Equal(t, []string{"A"}, update.Changed)
+	case <-time.After(2 * time.Second):
+		t.Fatal("no update after the interval was shortened")
+	}
+}
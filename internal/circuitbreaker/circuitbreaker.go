@@ -0,0 +1,352 @@
+// Package circuitbreaker implements the Circuit Breaker pattern for elevator
+// system operations.
+//
+// The breaker protects the system from cascading failures by monitoring the
+// outcome of operations and temporarily rejecting them once they fail too
+// often. This stops load on a failing subsystem and gives it time to recover.
+//
+// The breaker operates in three states:
+//
+//  1. CLOSED (Normal Operation): operations are allowed through and their
+//     outcomes recorded. The breaker opens after MaxFailures consecutive
+//     failures or, in rolling-window mode, once the failure rate within
+//     Window reaches FailureThreshold over at least MinRequests operations.
+//
+//  2. OPEN (Failure Protection): operations are rejected with ErrOpen without
+//     being executed. After ResetTimeout the breaker moves to HALF-OPEN.
+//
+//  3. HALF-OPEN (Recovery Testing): up to HalfOpenLimit operations are let
+//     through. If they all succeed the breaker closes; any failure opens it
+//     again.
+//
+// Listeners registered with OnStateChange are told about every transition,
+// which lets owners drive metrics, logs and events from a single place.
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrOpen is returned by Execute while the breaker rejects operations
+var ErrOpen = errors.New("circuit breaker is open - request rejected")
+
+// State represents the state of a circuit breaker
+type State int
+
+const (
+	// StateClosed means the breaker is closed and allowing operations
+	StateClosed State = iota
+	// StateOpen means the breaker is open and rejecting operations
+	StateOpen
+	// StateHalfOpen means the breaker allows limited operations to test recovery
+	StateHalfOpen
+)
+
+// String returns the name of the state
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// Value encodes the state for the circuit breaker state gauge
+// (0 = closed, 1 = half-open, 2 = open)
+func (s State) Value() float64 {
+	switch s {
+	case StateHalfOpen:
+		return 1
+	case StateOpen:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// Settings configures a circuit breaker
+type Settings struct {
+	// MaxFailures is the number of consecutive failures that opens the breaker
+	MaxFailures int
+	// ResetTimeout is how long the breaker stays open before testing recovery
+	ResetTimeout time.Duration
+	// HalfOpenLimit is the number of successful operations needed to close
+	// the breaker again
+	HalfOpenLimit int
+	// FailureThreshold is the failure rate (0-1) within Window that opens the
+	// breaker; rolling-window mode is off when it or Window is zero
+	FailureThreshold float64
+	// Window is the rolling period over which the failure rate is measured
+	Window time.Duration
+	// MinRequests is the number of operations within Window needed before the
+	// failure rate is considered
+	MinRequests int
+	// IsFailure decides whether an operation error counts against the
+	// breaker; by default every error does
+	IsFailure func(error) bool `json:"-"`
+}
+
+// Equal reports whether both settings configure the breaker the same way;
+// IsFailure is not compared
+func (s Settings) Equal(other Settings) bool {
+	return s.MaxFailures == other.MaxFailures &&
+		s.ResetTimeout == other.ResetTimeout &&
+		s.HalfOpenLimit == other.HalfOpenLimit &&
+		s.FailureThreshold == other.FailureThreshold &&
+		s.Window == other.Window &&
+		s.MinRequests == other.MinRequests
+}
+
+// StateChangeListener is called after a breaker changes state
+type StateChangeListener func(name string, from, to State)
+
+// Breaker is a circuit breaker that can be shared by any number of callers
+type Breaker struct {
+	name     string
+	settings Settings
+
+	mu           sync.RWMutex
+	state        State
+	failureCount int
+	successCount int
+	lastFailTime time.Time
+	nextRetry    time.Time
+	window       *rollingWindow
+	listeners    []StateChangeListener
+}
+
+// New creates a circuit breaker. A non-positive MaxFailures or HalfOpenLimit
+// is treated as 1.
+func New(name string, settings Settings) *Breaker {
+	settings.MaxFailures = max(settings.MaxFailures, 1)
+	settings.HalfOpenLimit = max(settings.HalfOpenLimit, 1)
+
+	b := &Breaker{
+		name:     name,
+		settings: settings,
+		state:    StateClosed,
+	}
+	if settings.FailureThreshold > 0 && settings.Window > 0 {
+		b.window = newRollingWindow(settings.Window)
+	}
+	return b
+}
+
+// Configure replaces the settings of a running breaker. The current state is
+// kept; the rolling window starts afresh when rolling-window mode stays on.
+func (b *Breaker) Configure(settings Settings) {
+	settings.MaxFailures = max(settings.MaxFailures, 1)
+	settings.HalfOpenLimit = max(settings.HalfOpenLimit, 1)
+	if settings.IsFailure == nil {
+		settings.IsFailure = b.settings.IsFailure
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.settings = settings
+	b.window = nil
+	if settings.FailureThreshold > 0 && settings.Window > 0 {
+		b.window = newRollingWindow(settings.Window)
+	}
+}
+
+// Settings returns the current settings of the breaker
+func (b *Breaker) Settings() Settings {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.settings
+}
+
+// Name returns the name the breaker reports to listeners
+func (b *Breaker) Name() string {
+	return b.name
+}
+
+// OnStateChange registers a listener for state transitions. Listeners run
+// synchronously on the goroutine that caused the transition and must not
+// block.
+func (b *Breaker) OnStateChange(listener StateChangeListener) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.listeners = append(b.listeners, listener)
+}
+
+// Execute runs an operation with circuit breaker protection
+func (b *Breaker) Execute(ctx context.Context, operation func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if !b.allowRequest() {
+		return ErrOpen
+	}
+
+	err := operation()
+	if err != nil && b.isFailure(err) {
+		b.recordFailure()
+		return err
+	}
+
+	b.recordSuccess()
+	return err
+}
+
+func (b *Breaker) isFailure(err error) bool {
+	b.mu.RLock()
+	isFailure := b.settings.IsFailure
+	b.mu.RUnlock()
+	if isFailure == nil {
+		return true
+	}
+	return isFailure(err)
+}
+
+// allowRequest determines if an operation should be allowed based on the state
+func (b *Breaker) allowRequest() bool {
+	b.mu.Lock()
+	switch b.state {
+	case StateClosed:
+		b.mu.Unlock()
+		return true
+	case StateOpen:
+		if time.Now().After(b.nextRetry) {
+			from, listeners := b.transition(StateHalfOpen)
+			b.mu.Unlock()
+			b.notify(listeners, from, StateHalfOpen)
+			return true
+		}
+		b.mu.Unlock()
+		return false
+	case StateHalfOpen:
+		allowed := b.successCount < b.settings.HalfOpenLimit
+		b.mu.Unlock()
+		return allowed
+	default:
+		b.mu.Unlock()
+		return false
+	}
+}
+
+// recordSuccess records a successful operation
+func (b *Breaker) recordSuccess() {
+	b.mu.Lock()
+	b.failureCount = 0
+	if b.window != nil {
+		b.window.record(time.Now(), false)
+	}
+
+	if b.state == StateHalfOpen {
+		b.successCount++
+		if b.successCount >= b.settings.HalfOpenLimit {
+			// Enough successful operations, close the circuit
+			from, listeners := b.transition(StateClosed)
+			b.mu.Unlock()
+			b.notify(listeners, from, StateClosed)
+			return
+		}
+	}
+	b.mu.Unlock()
+}
+
+// recordFailure records a failed operation
+func (b *Breaker) recordFailure() {
+	b.mu.Lock()
+	now := time.Now()
+	b.failureCount++
+	b.lastFailTime = now
+	if b.window != nil {
+		b.window.record(now, true)
+	}
+
+	// A failure in half-open state or too many failures opens the circuit
+	if b.state == StateHalfOpen || (b.state == StateClosed && b.tripped(now)) {
+		from, listeners := b.transition(StateOpen)
+		b.nextRetry = now.Add(b.settings.ResetTimeout)
+		b.mu.Unlock()
+		b.notify(listeners, from, StateOpen)
+		return
+	}
+	b.mu.Unlock()
+}
+
+// tripped reports whether the closed breaker should open; callers must hold
+// b.mu
+func (b *Breaker) tripped(now time.Time) bool {
+	if b.failureCount >= b.settings.MaxFailures {
+		return true
+	}
+	if b.window == nil {
+		return false
+	}
+	requests, failures := b.window.counts(now)
+	return requests >= b.settings.MinRequests && requests > 0 &&
+		float64(failures)/float64(requests) >= b.settings.FailureThreshold
+}
+
+// transition moves the breaker to a new state and starts a fresh rolling
+// window; callers must hold b.mu and pass the result to notify after
+// unlocking
+func (b *Breaker) transition(to State) (State, []StateChangeListener) {
+	from := b.state
+	b.state = to
+	b.successCount = 0
+	if b.window != nil {
+		b.window.reset()
+	}
+	return from, b.listeners
+}
+
+func (b *Breaker) notify(listeners []StateChangeListener, from, to State) {
+	for _, listener := range listeners {
+		listener(b.name, from, to)
+	}
+}
+
+// State returns the current state of the breaker
+func (b *Breaker) State() State {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.state
+}
+
+// Metrics returns the state, the consecutive failures and the successes
+// counted while half-open
+func (b *Breaker) Metrics() (state State, failures int, successes int) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.state, b.failureCount, b.successCount
+}
+
+// FailureRate returns the failure rate and number of operations in the
+// rolling window; both are zero when rolling-window mode is off
+func (b *Breaker) FailureRate() (rate float64, requests int) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.window == nil {
+		return 0, 0
+	}
+	requests, failures := b.window.counts(time.Now())
+	if requests == 0 {
+		return 0, 0
+	}
+	return float64(failures) / float64(requests), requests
+}
+
+// RetryAfter returns how long an open breaker keeps rejecting operations; it
+// is zero in the other states
+func (b *Breaker) RetryAfter() time.Duration {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.state != StateOpen {
+		return 0
+	}
+	return max(time.Until(b.nextRetry), 0)
+}
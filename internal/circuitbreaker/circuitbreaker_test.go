@@ -0,0 +1,211 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errTest = errors.New("operation failed")
+
+func fail() error    { return errTest }
+func succeed() error { return nil }
+
+type transition struct {
+	name     string
+	from, to State
+}
+
+type recorder struct {
+	mu          sync.Mutex
+	transitions []transition
+}
+
+func (r *recorder) listen(name string, from, to State) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.transitions = append(r.transitions, transition{name: name, from: from, to: to})
+}
+
+func (r *recorder) all() []transition {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]transition(nil), r.transitions...)
+}
+
+func TestBreaker_OpensAfterConsecutiveFailures(t *testing.T) {
+	b := New("test", Settings{MaxFailures: 3, ResetTimeout: time.Minute, HalfOpenLimit: 1})
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		assert.ErrorIs(t, b.Execute(ctx, fail), errTest)
+	}
+	assert.Equal(t, StateClosed, b.State())
+
+	// A success resets the consecutive failure count
+	require.NoError(t, b.Execute(ctx, succeed))
+	for i := 0; i < 2; i++ {
+		assert.ErrorIs(t, b.Execute(ctx, fail), errTest)
+	}
+	assert.Equal(t, StateClosed, b.State())
+
+	assert.ErrorIs(t, b.Execute(ctx, fail), errTest)
+	assert.Equal(t, StateOpen, b.State())
+
+	called := false
+	err := b.Execute(ctx, func() error { called = true; return nil })
+	assert.ErrorIs(t, err, ErrOpen)
+	assert.False(t, called)
+	assert.Greater(t, b.RetryAfter(), 59*time.Second)
+}
+
+func TestBreaker_OpensOnFailureRate(t *testing.T) {
+	b := New("test", Settings{
+		MaxFailures:      100,
+		ResetTimeout:     time.Minute,
+		HalfOpenLimit:    1,
+		FailureThreshold: 0.5,
+		Window:           time.Minute,
+		MinRequests:      4,
+	})
+	ctx := context.Background()
+
+	// Alternating outcomes never reach MaxFailures consecutive failures
+	require.NoError(t, b.Execute(ctx, succeed))
+	assert.Error(t, b.Execute(ctx, fail))
+	require.NoError(t, b.Execute(ctx, succeed))
+	assert.Equal(t, StateClosed, b.State(), "below MinRequests the rate is ignored")
+
+	rate, requests := b.FailureRate()
+	assert.InDelta(t, 1.0/3.0, rate, 0.001)
+	assert.Equal(t, 3, requests)
+
+	assert.Error(t, b.Execute(ctx, fail))
+	assert.Equal(t, StateOpen, b.State())
+
+	// Opening starts a fresh window
+	rate, requests = b.FailureRate()
+	assert.Zero(t, rate)
+	assert.Zero(t, requests)
+}
+
+func TestBreaker_FailureRateExpiresWithWindow(t *testing.T) {
+	b := New("test", Settings{
+		MaxFailures:      100,
+		FailureThreshold: 0.5,
+		Window:           50 * time.Millisecond,
+		MinRequests:      2,
+	})
+	ctx := context.Background()
+
+	assert.Error(t, b.Execute(ctx, fail))
+	time.Sleep(80 * time.Millisecond)
+	require.NoError(t, b.Execute(ctx, succeed))
+	require.NoError(t, b.Execute(ctx, succeed))
+	assert.Error(t, b.Execute(ctx, fail))
+
+	// The first failure has left the window, leaving 1 of 3
+	assert.Equal(t, StateClosed, b.State())
+	rate, requests := b.FailureRate()
+	assert.Equal(t, 3, requests)
+	assert.InDelta(t, 1.0/3.0, rate, 0.001)
+}
+
+func TestBreaker_HalfOpenRecovery(t *testing.T) {
+	rec := &recorder{}
+	b := New("car", Settings{MaxFailures: 1, ResetTimeout: 20 * time.Millisecond, HalfOpenLimit: 2})
+	b.OnStateChange(rec.listen)
+	ctx := context.Background()
+
+	assert.Error(t, b.Execute(ctx, fail))
+	assert.Equal(t, StateOpen, b.State())
+
+	time.Sleep(30 * time.Millisecond)
+	require.NoError(t, b.Execute(ctx, succeed))
+	assert.Equal(t, StateHalfOpen, b.State())
+	require.NoError(t, b.Execute(ctx, succeed))
+	assert.Equal(t, StateClosed, b.State())
+
+	assert.Equal(t, []transition{
+		{name: "car", from: StateClosed, to: StateOpen},
+		{name: "car", from: StateOpen, to: StateHalfOpen},
+		{name: "car", from: StateHalfOpen, to: StateClosed},
+	}, rec.all())
+}
+
+func TestBreaker_HalfOpenFailureReopens(t *testing.T) {
+	rec := &recorder{}
+	b := New("car", Settings{MaxFailures: 1, ResetTimeout: 20 * time.Millisecond, HalfOpenLimit: 2})
+	b.OnStateChange(rec.listen)
+	ctx := context.Background()
+
+	assert.Error(t, b.Execute(ctx, fail))
+	time.Sleep(30 * time.Millisecond)
+	assert.ErrorIs(t, b.Execute(ctx, fail), errTest)
+	assert.Equal(t, StateOpen, b.State())
+
+	transitions := rec.all()
+	require.Len(t, transitions, 3)
+	assert.Equal(t, transition{name: "car", from: StateHalfOpen, to: StateOpen}, transitions[2])
+}
+
+func TestBreaker_IsFailureFiltersErrors(t *testing.T) {
+	errIgnored := errors.New("ignored")
+	b := New("test", Settings{
+		MaxFailures: 1,
+		IsFailure:   func(err error) bool { return !errors.Is(err, errIgnored) },
+	})
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		assert.ErrorIs(t, b.Execute(ctx, func() error { return errIgnored }), errIgnored)
+	}
+	assert.Equal(t, StateClosed, b.State())
+
+	assert.ErrorIs(t, b.Execute(ctx, fail), errTest)
+	assert.Equal(t, StateOpen, b.State())
+}
+
+func TestBreaker_CancelledContext(t *testing.T) {
+	b := New("test", Settings{MaxFailures: 1})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	called := false
+	err := b.Execute(ctx, func() error { called = true; return nil })
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.False(t, called)
+	assert.Equal(t, StateClosed, b.State())
+}
+
+func TestState_StringAndValue(t *testing.T) {
+	assert.Equal(t, "closed", StateClosed.String())
+	assert.Equal(t, "open", StateOpen.String())
+	assert.Equal(t, "half-open", StateHalfOpen.String())
+	assert.Equal(t, 0.0, StateClosed.Value())
+	assert.Equal(t, 1.0, StateHalfOpen.Value())
+	assert.Equal(t, 2.0, StateOpen.Value())
+}
+
+func TestBreaker_Configure(t *testing.T) {
+	b := New("test", Settings{MaxFailures: 1, ResetTimeout: time.Minute})
+	ctx := context.Background()
+
+	b.Configure(Settings{MaxFailures: 3, ResetTimeout: time.Minute})
+	for i := 0; i < 2; i++ {
+		assert.Error(t, b.Execute(ctx, fail))
+	}
+	assert.Equal(t, StateClosed, b.State())
+
+	// Switching on rolling-window mode keeps the state and starts counting
+	b.Configure(Settings{MaxFailures: 100, FailureThreshold: 0.5, Window: time.Minute, MinRequests: 2})
+	assert.Equal(t, StateClosed, b.State())
+	require.NoError(t, b.Execute(ctx, succeed))
+	assert.Error(t, b.Execute(ctx, fail))
+	assert.Equal(t, StateOpen, b.State())
+}
@@ -0,0 +1,53 @@
+package circuitbreaker
+
+import "time"
+
+// windowBuckets is the number of buckets a rolling window is divided into
+const windowBuckets = 10
+
+type bucket struct {
+	start     time.Time
+	successes int
+	failures  int
+}
+
+// rollingWindow counts operation outcomes over a sliding period using
+// fixed-width buckets, so old outcomes expire one bucket at a time
+type rollingWindow struct {
+	width   time.Duration
+	buckets [windowBuckets]bucket
+}
+
+func newRollingWindow(period time.Duration) *rollingWindow {
+	return &rollingWindow{width: max(period/windowBuckets, time.Millisecond)}
+}
+
+func (w *rollingWindow) record(now time.Time, failed bool) {
+	start := now.Truncate(w.width)
+	b := &w.buckets[(start.UnixNano()/int64(w.width))%windowBuckets]
+	if !b.start.Equal(start) {
+		*b = bucket{start: start}
+	}
+	if failed {
+		b.failures++
+	} else {
+		b.successes++
+	}
+}
+
+// counts returns the operations and failures recorded within the window
+func (w *rollingWindow) counts(now time.Time) (requests, failures int) {
+	oldest := now.Truncate(w.width).Add(-w.width * (windowBuckets - 1))
+	for _, b := range w.buckets {
+		if b.start.Before(oldest) {
+			continue
+		}
+		requests += b.successes + b.failures
+		failures += b.failures
+	}
+	return requests, failures
+}
+
+func (w *rollingWindow) reset() {
+	w.buckets = [windowBuckets]bucket{}
+}
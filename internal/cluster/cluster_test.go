@@ -0,0 +1,353 @@
+package cluster
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/raft"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+	"github.com/slavakukuyev/elevator-go/internal/elevator"
+	"github.com/slavakukuyev/elevator-go/internal/factory"
+	"github.com/slavakukuyev/elevator-go/internal/infra/config"
+	"github.com/slavakukuyev/elevator-go/internal/manager"
+)
+
+// testNode is a cluster node running in-process, reached through an
+// in-memory Raft transport and a local HTTP server
+type testNode struct {
+	node      atomic.Pointer[Node]
+	manager   *manager.Manager
+	server    *httptest.Server
+	transport *raft.InmemTransport
+}
+
+func (tn *testNode) Node() *Node {
+	return tn.node.Load()
+}
+
+func (tn *testNode) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	node := tn.node.Load()
+	if node == nil {
+		http.Error(w, "node not started", http.StatusServiceUnavailable)
+		return
+	}
+	node.Handler().ServeHTTP(w, r)
+}
+
+func buildClusterTestConfig(t *testing.T) *config.Config {
+	t.Setenv("ENV", "testing")
+	t.Setenv("LOG_LEVEL", "ERROR")
+	cfg, err := config.InitConfig()
+	require.NoError(t, err)
+	return cfg
+}
+
+// startTestCluster starts a cluster of three nodes running the default fleet
+// of cfg
+func startTestCluster(t *testing.T, cfg *config.Config) []*testNode {
+	t.Helper()
+
+	ids := []string{"node1", "node2", "node3"}
+	nodes := make([]*testNode, len(ids))
+	peers := make([]config.ClusterPeer, len(ids))
+	for i, id := range ids {
+		tn := &testNode{manager: manager.New(cfg, &factory.StandardElevatorFactory{})}
+		var address raft.ServerAddress
+		address, tn.transport = raft.NewInmemTransport(raft.ServerAddress(id))
+		tn.server = httptest.NewServer(tn)
+		t.Cleanup(tn.server.Close)
+		t.Cleanup(tn.manager.Shutdown)
+
+		nodes[i] = tn
+		peers[i] = config.ClusterPeer{ID: id, RaftAddr: string(address), APIURL: tn.server.URL}
+	}
+	for _, tn := range nodes {
+		for i, peer := range nodes {
+			tn.transport.Connect(raft.ServerAddress(peers[i].RaftAddr), peer.transport)
+		}
+	}
+
+	for i, tn := range nodes {
+		node, err := NewNode(Options{
+			NodeID:          ids[i],
+			Peers:           peers,
+			Token:           "secret",
+			ElectionTimeout: 100 * time.Millisecond,
+			Transport:       tn.transport,
+			DefaultFleet:    DefaultFleet(cfg),
+		}, tn.manager)
+		require.NoError(t, err)
+		tn.node.Store(node)
+		t.Cleanup(func() { _ = node.Shutdown() })
+	}
+	return nodes
+}
+
+// waitForLeader waits until exactly one of the nodes runs the fleet
+func waitForLeader(t *testing.T, nodes []*testNode) *testNode {
+	t.Helper()
+
+	var leader *testNode
+	require.Eventually(t, func() bool {
+		leader = nil
+		for _, tn := range nodes {
+			if tn.Node().IsLeader() {
+				if leader != nil {
+					return false
+				}
+				leader = tn
+			}
+		}
+		return leader != nil
+	}, 10*time.Second, 20*time.Millisecond, "cluster did not elect a leader")
+	return leader
+}
+
+func followerOf(nodes []*testNode, leader *testNode) *testNode {
+	for _, tn := range nodes {
+		if tn != leader {
+			return tn
+		}
+	}
+	return nil
+}
+
+func elevatorNames(m *manager.Manager) []string {
+	names := []string{}
+	for _, e := range m.GetElevators() {
+		names = append(names, e.Name())
+	}
+	return names
+}
+
+func TestCluster_LeaderRunsReplicatedFleet(t *testing.T) {
+	cfg := buildClusterTestConfig(t)
+	cfg.DefaultElevatorCount = 2
+	nodes := startTestCluster(t, cfg)
+
+	leader := waitForLeader(t, nodes)
+	follower := followerOf(nodes, leader)
+	ctx := context.Background()
+
+	// Only the leader runs the default fleet, which every node knows about
+	assert.ElementsMatch(t, []string{"Elevator-1", "Elevator-2"}, elevatorNames(leader.manager))
+	for _, tn := range nodes {
+		if tn != leader {
+			assert.Empty(t, tn.manager.GetElevators())
+		}
+		require.Eventually(t, func() bool {
+			return len(tn.Node().State().Elevators) == 2
+		}, 5*time.Second, 10*time.Millisecond)
+	}
+
+	// Followers forward fleet changes and floor requests to the leader
+	require.NoError(t, follower.Node().AddElevator(ctx, ElevatorSpec{
+		Name: "Express", Type: domain.ElevatorTypePassenger, MinFloor: 0, MaxFloor: 9,
+		EachFloorDuration: 10 * time.Millisecond, OpenDoorDuration: 10 * time.Millisecond, OverloadThreshold: 12,
+	}))
+	assert.NotNil(t, leader.manager.GetElevator("Express"))
+	assert.Nil(t, follower.manager.GetElevator("Express"))
+
+	name, err := follower.Node().RequestElevator(ctx, 0, 1, domain.CallOptions{})
+	require.NoError(t, err)
+	assert.NotEmpty(t, name)
+
+	// Errors from the leader keep their type
+	err = follower.Node().AddElevator(ctx, ElevatorSpec{
+		Name: "Express", Type: domain.ElevatorTypePassenger, MinFloor: 0, MaxFloor: 9,
+		EachFloorDuration: 10 * time.Millisecond, OpenDoorDuration: 10 * time.Millisecond, OverloadThreshold: 12,
+	})
+	require.Error(t, err)
+	assert.Equal(t, domain.ErrTypeConflict, err.(*domain.DomainError).Type)
+
+	// Completed requests leave the replicated state
+	for _, tn := range nodes {
+		require.Eventually(t, func() bool {
+			state := tn.Node().State()
+			return len(state.Elevators) == 3 && len(state.Requests) == 0
+		}, 5*time.Second, 10*time.Millisecond)
+	}
+
+	require.NoError(t, follower.Node().DeleteElevator(ctx, "Express"))
+	assert.Nil(t, leader.manager.GetElevator("Express"))
+	require.Eventually(t, func() bool {
+		return len(follower.Node().State().Elevators) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestCluster_FollowerTakesOverAfterLeaderFailure(t *testing.T) {
+	cfg := buildClusterTestConfig(t)
+	cfg.DefaultElevatorCount = 1
+	// Slow elevators keep the request pending while the leader fails
+	cfg.EachFloorDuration = time.Second
+	nodes := startTestCluster(t, cfg)
+
+	leader := waitForLeader(t, nodes)
+	ctx := context.Background()
+	_, err := leader.Node().RequestElevator(ctx, 5, 9, domain.CallOptions{})
+	require.NoError(t, err)
+	for _, tn := range nodes {
+		require.Eventually(t, func() bool {
+			return len(tn.Node().State().Requests) == 1
+		}, 5*time.Second, 10*time.Millisecond)
+	}
+
+	// Cut the leader off from the cluster
+	leader.transport.DisconnectAll()
+	for _, tn := range nodes {
+		tn.transport.Disconnect(leader.transport.LocalAddr())
+	}
+	survivors := []*testNode{}
+	for _, tn := range nodes {
+		if tn != leader {
+			survivors = append(survivors, tn)
+		}
+	}
+
+	// A survivor starts the replicated fleet and serves the pending request
+	// again, while the old leader hands its fleet over
+	newLeader := waitForLeader(t, survivors)
+	assert.Equal(t, []string{"Elevator-1"}, elevatorNames(newLeader.manager))
+	assert.True(t, newLeader.manager.GetElevator("Elevator-1").HasPendingRequests())
+	require.Eventually(t, func() bool {
+		return !leader.Node().IsLeader() && len(leader.manager.GetElevators()) == 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// The other survivor forwards to the new leader
+	follower := followerOf(survivors, newLeader)
+	name, err := follower.Node().RequestElevator(ctx, 0, 1, domain.CallOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "Elevator-1", name)
+}
+
+func TestCluster_ForwardedCallsNeedToken(t *testing.T) {
+	cfg := buildClusterTestConfig(t)
+	cfg.DefaultElevatorCount = 1
+	nodes := startTestCluster(t, cfg)
+	leader := waitForLeader(t, nodes)
+
+	resp, err := http.Post(leader.server.URL+requestsPath, "application/json", nil)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	status, err := http.Get(leader.server.URL + statusPath)
+	require.NoError(t, err)
+	defer status.Body.Close()
+	assert.Equal(t, http.StatusOK, status.StatusCode)
+}
+
+func TestCluster_FollowerForwardsCallsTuningAndFaults(t *testing.T) {
+	cfg := buildClusterTestConfig(t)
+	cfg.DefaultElevatorCount = 1
+	nodes := startTestCluster(t, cfg)
+
+	leader := waitForLeader(t, nodes)
+	follower := followerOf(nodes, leader)
+	ctx := context.Background()
+
+	// Calls go to the leader's fleet and leave the replicated state once
+	// they are served
+	name, err := follower.Node().HallCall(ctx, 3, domain.DirectionUp, domain.CallOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "Elevator-1", name)
+	direction, err := follower.Node().CarCall(ctx, "Elevator-1", 5)
+	require.NoError(t, err)
+	assert.NotEmpty(t, direction)
+	for _, tn := range nodes {
+		require.Eventually(t, func() bool {
+			return len(tn.Node().State().Calls) == 0
+		}, 5*time.Second, 10*time.Millisecond)
+	}
+	assert.Empty(t, follower.manager.GetElevators())
+
+	_, err = follower.Node().CarCall(ctx, "Missing", 5)
+	require.Error(t, err)
+	assert.Equal(t, domain.ErrTypeNotFound, err.(*domain.DomainError).Type)
+
+	// Tuning and faults are carried out by the leader and replicated
+	threshold := 7
+	tuned, err := follower.Node().TuneElevator(ctx, "Elevator-1", elevator.TuningPatch{OverloadThreshold: &threshold})
+	require.NoError(t, err)
+	assert.Equal(t, 7, tuned.OverloadThreshold)
+	tuning, err := leader.manager.ElevatorTuning("Elevator-1")
+	require.NoError(t, err)
+	assert.Equal(t, 7, tuning.OverloadThreshold)
+
+	_, err = follower.Node().InjectFault(ctx, "Elevator-1", domain.Fault{Type: domain.FaultSlowTravel})
+	require.NoError(t, err)
+	faults, err := leader.manager.ElevatorFaults("Elevator-1")
+	require.NoError(t, err)
+	require.Len(t, faults, 1)
+	assert.Equal(t, domain.FaultSlowTravel, faults[0].Type)
+
+	require.Eventually(t, func() bool {
+		tuning, err := follower.Node().ElevatorTuning("Elevator-1")
+		faults, faultsErr := follower.Node().ElevatorFaults("Elevator-1")
+		return err == nil && faultsErr == nil && tuning.OverloadThreshold == 7 && len(faults) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	err = follower.Node().ClearFault(ctx, "Elevator-1", domain.FaultDoorFailure)
+	require.Error(t, err)
+	assert.Equal(t, domain.ErrTypeNotFound, err.(*domain.DomainError).Type)
+	require.NoError(t, follower.Node().ClearFault(ctx, "Elevator-1", domain.FaultSlowTravel))
+	faults, err = leader.manager.ElevatorFaults("Elevator-1")
+	require.NoError(t, err)
+	assert.Empty(t, faults)
+
+	// Trips are tracked by the leader only, so followers name it instead
+	_, err = follower.Node().RequestTrip(ctx, 0, 1, domain.CallOptions{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), leader.server.URL)
+	trip, err := leader.Node().RequestTrip(ctx, 0, 1, domain.CallOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "Elevator-1", trip.Elevator)
+}
+
+func TestCluster_NewLeaderKeepsTuningAndFaults(t *testing.T) {
+	cfg := buildClusterTestConfig(t)
+	cfg.DefaultElevatorCount = 1
+	nodes := startTestCluster(t, cfg)
+
+	leader := waitForLeader(t, nodes)
+	ctx := context.Background()
+	threshold := 9
+	_, err := leader.Node().TuneElevator(ctx, "Elevator-1", elevator.TuningPatch{OverloadThreshold: &threshold})
+	require.NoError(t, err)
+	_, err = leader.Node().InjectFault(ctx, "Elevator-1", domain.Fault{Type: domain.FaultDoorFailure})
+	require.NoError(t, err)
+	for _, tn := range nodes {
+		require.Eventually(t, func() bool {
+			spec, ok := tn.Node().fsm.elevator("Elevator-1")
+			return ok && spec.OverloadThreshold == 9 && len(spec.Faults) == 1
+		}, 5*time.Second, 10*time.Millisecond)
+	}
+
+	// Cut the leader off from the cluster
+	leader.transport.DisconnectAll()
+	for _, tn := range nodes {
+		tn.transport.Disconnect(leader.transport.LocalAddr())
+	}
+	survivors := []*testNode{}
+	for _, tn := range nodes {
+		if tn != leader {
+			survivors = append(survivors, tn)
+		}
+	}
+
+	newLeader := waitForLeader(t, survivors)
+	tuning, err := newLeader.manager.ElevatorTuning("Elevator-1")
+	require.NoError(t, err)
+	assert.Equal(t, 9, tuning.OverloadThreshold)
+	faults, err := newLeader.manager.ElevatorFaults("Elevator-1")
+	require.NoError(t, err)
+	require.Len(t, faults, 1)
+	assert.Equal(t, domain.FaultDoorFailure, faults[0].Type)
+}
@@ -0,0 +1,322 @@
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+
+	"github.com/slavakukuyev/elevator-go/internal/constants"
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+	"github.com/slavakukuyev/elevator-go/internal/elevator"
+	"github.com/slavakukuyev/elevator-go/metrics"
+)
+
+// TokenHeader carries the token that authenticates calls forwarded between
+// nodes
+const TokenHeader = "X-Cluster-Token"
+
+const (
+	statusPath    = "/v1/cluster/status"
+	requestsPath  = "/v1/cluster/requests"
+	hallCallsPath = "/v1/cluster/hall-calls"
+	elevatorsPath = "/v1/cluster/elevators"
+)
+
+// StatusResponse describes a node and the replicated state it holds
+type StatusResponse struct {
+	NodeID   string `json:"node_id"`
+	LeaderID string `json:"leader_id"`
+	Leader   bool   `json:"leader"`
+	State    State  `json:"state"`
+}
+
+// requestBody is a floor request forwarded to the leader
+type requestBody struct {
+	From int                `json:"from"`
+	To   int                `json:"to"`
+	Call domain.CallOptions `json:"call"`
+}
+
+// requestResponse names the elevator the leader assigned to a forwarded
+// floor request or hall call
+type requestResponse struct {
+	ElevatorName string `json:"elevator_name"`
+}
+
+// hallCallBody is a hall call forwarded to the leader
+type hallCallBody struct {
+	Floor     int                `json:"floor"`
+	Direction domain.Direction   `json:"direction"`
+	Call      domain.CallOptions `json:"call"`
+}
+
+// carCallBody is a car call forwarded to the leader
+type carCallBody struct {
+	Floor int `json:"floor"`
+}
+
+// carCallResponse names the direction of the stop a forwarded car call added
+type carCallResponse struct {
+	Direction domain.Direction `json:"direction"`
+}
+
+// errorResponse carries a domain error back to the forwarding node
+type errorResponse struct {
+	Type    domain.ErrType `json:"type"`
+	Message string         `json:"message"`
+}
+
+// Handler serves the API nodes use to reach each other under /v1/cluster/:
+// the status of the node and the calls followers forward to the leader.
+// Forwarded calls must carry the cluster token when one is configured.
+func (n *Node) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+statusPath, n.statusHandler)
+	mux.HandleFunc("POST "+requestsPath, n.forwarded(n.requestHandler))
+	mux.HandleFunc("POST "+hallCallsPath, n.forwarded(n.hallCallHandler))
+	mux.HandleFunc("POST "+elevatorsPath, n.forwarded(n.addElevatorHandler))
+	mux.HandleFunc("DELETE "+elevatorsPath+"/{name}", n.forwarded(n.deleteElevatorHandler))
+	mux.HandleFunc("PATCH "+elevatorsPath+"/{name}", n.forwarded(n.tuneElevatorHandler))
+	mux.HandleFunc("POST "+elevatorsPath+"/{name}/car-calls", n.forwarded(n.carCallHandler))
+	mux.HandleFunc("POST "+elevatorsPath+"/{name}/faults", n.forwarded(n.injectFaultHandler))
+	mux.HandleFunc("DELETE "+elevatorsPath+"/{name}/faults", n.forwarded(n.clearFaultHandler))
+	return mux
+}
+
+// forwarded accepts a forwarded call when it carries the cluster token and
+// this node leads the cluster. A node that is not the leader refuses the
+// call instead of forwarding it again.
+func (n *Node) forwarded(handle http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if n.token != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get(TokenHeader)), []byte(n.token)) != 1 {
+			n.logger.WarnContext(r.Context(), "rejected forwarded call with invalid cluster token",
+				slog.String("path", r.URL.Path))
+			writeJSON(w, http.StatusUnauthorized, errorResponse{
+				Type:    domain.ErrTypeValidation,
+				Message: "invalid cluster token",
+			})
+			return
+		}
+		if !n.leading.Load() {
+			writeError(w, notLeaderError(n.id))
+			return
+		}
+		handle(w, r)
+	}
+}
+
+func (n *Node) statusHandler(w http.ResponseWriter, r *http.Request) {
+	leader, _ := n.Leader()
+	writeJSON(w, http.StatusOK, StatusResponse{
+		NodeID:   n.id,
+		LeaderID: leader.ID,
+		Leader:   n.IsLeader(),
+		State:    n.State(),
+	})
+}
+
+func (n *Node) requestHandler(w http.ResponseWriter, r *http.Request) {
+	var body requestBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, domain.NewValidationError("invalid forwarded request", err))
+		return
+	}
+
+	name, err := n.RequestElevator(r.Context(), body.From, body.To, body.Call)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, requestResponse{ElevatorName: name})
+}
+
+func (n *Node) hallCallHandler(w http.ResponseWriter, r *http.Request) {
+	var body hallCallBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, domain.NewValidationError("invalid forwarded hall call", err))
+		return
+	}
+
+	name, err := n.HallCall(r.Context(), body.Floor, body.Direction, body.Call)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, requestResponse{ElevatorName: name})
+}
+
+func (n *Node) carCallHandler(w http.ResponseWriter, r *http.Request) {
+	var body carCallBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, domain.NewValidationError("invalid forwarded car call", err))
+		return
+	}
+
+	direction, err := n.CarCall(r.Context(), r.PathValue("name"), body.Floor)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, carCallResponse{Direction: direction})
+}
+
+func (n *Node) addElevatorHandler(w http.ResponseWriter, r *http.Request) {
+	var spec ElevatorSpec
+	if err := json.NewDecoder(r.Body).Decode(&spec); err != nil {
+		writeError(w, domain.NewValidationError("invalid forwarded elevator", err))
+		return
+	}
+
+	if err := n.AddElevator(r.Context(), spec); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (n *Node) deleteElevatorHandler(w http.ResponseWriter, r *http.Request) {
+	if err := n.DeleteElevator(r.Context(), r.PathValue("name")); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (n *Node) tuneElevatorHandler(w http.ResponseWriter, r *http.Request) {
+	var patch elevator.TuningPatch
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		writeError(w, domain.NewValidationError("invalid forwarded tuning", err))
+		return
+	}
+
+	tuning, err := n.TuneElevator(r.Context(), r.PathValue("name"), patch)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, tuning)
+}
+
+func (n *Node) injectFaultHandler(w http.ResponseWriter, r *http.Request) {
+	var fault domain.Fault
+	if err := json.NewDecoder(r.Body).Decode(&fault); err != nil {
+		writeError(w, domain.NewValidationError("invalid forwarded fault", err))
+		return
+	}
+
+	injected, err := n.InjectFault(r.Context(), r.PathValue("name"), fault)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, injected)
+}
+
+func (n *Node) clearFaultHandler(w http.ResponseWriter, r *http.Request) {
+	faultType := domain.FaultType(r.URL.Query().Get("type"))
+	if err := n.ClearFault(r.Context(), r.PathValue("name"), faultType); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// forward sends a call to the leader and decodes its response into out. A
+// domain error returned by the leader is returned as is.
+func (n *Node) forward(ctx context.Context, operation, method, path string, body, out any) error {
+	leader, ok := n.Leader()
+	if !ok || leader.ID == n.id {
+		// Without a leader, or while this node is still taking the fleet
+		// over, there is nobody to forward to
+		metrics.IncClusterForwardedCalls(n.id, operation, "no_leader")
+		return domain.NewExternalError("cluster has no leader", nil).
+			WithContext("node", n.id)
+	}
+
+	var payload bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&payload).Encode(body); err != nil {
+			return domain.NewInternalError("failed to encode forwarded call", err).
+				WithContext("operation", operation)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, leader.APIURL+path, &payload)
+	if err != nil {
+		return domain.NewInternalError("failed to build forwarded call", err).
+			WithContext("operation", operation)
+	}
+	req.Header.Set("Content-Type", constants.ContentTypeJSON)
+	if n.token != "" {
+		req.Header.Set(TokenHeader, n.token)
+	}
+	// The leader continues the caller's trace
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		metrics.IncClusterForwardedCalls(n.id, operation, "failed")
+		return domain.NewExternalError("failed to reach the cluster leader", err).
+			WithContext("leader", leader.ID)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		metrics.IncClusterForwardedCalls(n.id, operation, "rejected")
+		failure := errorResponse{Type: domain.ErrTypeExternal, Message: resp.Status}
+		_ = json.NewDecoder(resp.Body).Decode(&failure)
+		return &domain.DomainError{
+			Type:    failure.Type,
+			Message: failure.Message,
+			Context: map[string]interface{}{"leader": leader.ID},
+		}
+	}
+
+	metrics.IncClusterForwardedCalls(n.id, operation, "success")
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return domain.NewExternalError("invalid response from the cluster leader", err).
+				WithContext("leader", leader.ID)
+		}
+	}
+
+	n.logger.DebugContext(ctx, "call forwarded to the cluster leader",
+		slog.String("operation", operation),
+		slog.String("leader", leader.ID))
+	return nil
+}
+
+// writeError writes an error with the status code matching its domain type
+func writeError(w http.ResponseWriter, err error) {
+	failure := errorResponse{Type: domain.ErrTypeInternal, Message: err.Error()}
+	var domainErr *domain.DomainError
+	if errors.As(err, &domainErr) {
+		failure = errorResponse{Type: domainErr.Type, Message: domainErr.Message}
+	}
+
+	status := http.StatusInternalServerError
+	switch failure.Type {
+	case domain.ErrTypeValidation:
+		status = http.StatusBadRequest
+	case domain.ErrTypeNotFound:
+		status = http.StatusNotFound
+	case domain.ErrTypeConflict:
+		status = http.StatusConflict
+	case domain.ErrTypeExternal:
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, failure)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", constants.ContentTypeJSON)
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,335 @@
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/raft"
+
+	"github.com/slavakukuyev/elevator-go/internal/circuitbreaker"
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+	"github.com/slavakukuyev/elevator-go/internal/elevator"
+)
+
+// ElevatorSpec describes an elevator of the replicated fleet, enough for a
+// new leader to create it again with its tuning and active faults
+type ElevatorSpec struct {
+	Name              string                   `json:"name"`
+	Type              domain.ElevatorType      `json:"type"`
+	MinFloor          int                      `json:"min_floor"`
+	MaxFloor          int                      `json:"max_floor"`
+	EachFloorDuration time.Duration            `json:"each_floor_duration"`
+	OpenDoorDuration  time.Duration            `json:"open_door_duration"`
+	OverloadThreshold int                      `json:"overload_threshold"`
+	CircuitBreaker    *circuitbreaker.Settings `json:"circuit_breaker,omitempty"`
+	Faults            []domain.Fault           `json:"faults,omitempty"`
+}
+
+// FloorRequest is a floor request that has not been completed yet. Its ID
+// is given by the leader that accepted it.
+type FloorRequest struct {
+	ID        string             `json:"id"`
+	FromFloor int                `json:"from_floor"`
+	ToFloor   int                `json:"to_floor"`
+	Call      domain.CallOptions `json:"call"`
+}
+
+// Call is a hall call or car call that has not been served yet. Hall calls
+// have a direction; car calls name the elevator the button was pressed in.
+type Call struct {
+	ID        string             `json:"id"`
+	Floor     int                `json:"floor"`
+	Direction domain.Direction   `json:"direction,omitempty"`
+	Elevator  string             `json:"elevator,omitempty"`
+	Call      domain.CallOptions `json:"call"`
+}
+
+// IsCarCall reports whether the call was pressed inside an elevator
+func (c Call) IsCarCall() bool {
+	return c.Elevator != ""
+}
+
+// State is the replicated state of the cluster: the fleet and the floor
+// requests and calls still pending, all in the order they were added.
+// Seeded is set once the first leader has created the default fleet.
+type State struct {
+	Elevators []ElevatorSpec `json:"elevators"`
+	Requests  []FloorRequest `json:"requests"`
+	Calls     []Call         `json:"calls"`
+	Seeded    bool           `json:"seeded"`
+}
+
+// commandType names a change to the replicated state
+type commandType string
+
+const (
+	commandSeedFleet      commandType = "seed_fleet"
+	commandAddElevator    commandType = "add_elevator"
+	commandRemoveElevator commandType = "remove_elevator"
+	commandTuneElevator   commandType = "tune_elevator"
+	commandInjectFault    commandType = "inject_fault"
+	commandClearFault     commandType = "clear_fault"
+	commandRequest        commandType = "request"
+	commandFinishRequest  commandType = "finish_request"
+	commandCall           commandType = "call"
+	commandFinishCall     commandType = "finish_call"
+)
+
+// command is a Raft log entry. Origin identifies the call on the leader
+// that appended it.
+type command struct {
+	Type      commandType      `json:"type"`
+	Origin    string           `json:"origin,omitempty"`
+	Elevators []ElevatorSpec   `json:"elevators,omitempty"`
+	Elevator  *ElevatorSpec    `json:"elevator,omitempty"`
+	Name      string           `json:"name,omitempty"`
+	Tuning    *elevator.Tuning `json:"tuning,omitempty"`
+	Fault     *domain.Fault    `json:"fault,omitempty"`
+	FaultType domain.FaultType `json:"fault_type,omitempty"`
+	Request   *FloorRequest    `json:"request,omitempty"`
+	RequestID string           `json:"request_id,omitempty"`
+	Call      *Call            `json:"call,omitempty"`
+	CallID    string           `json:"call_id,omitempty"`
+}
+
+// fsm applies committed commands to the replicated state. Every node keeps
+// the same state; only the leader runs elevators from it, which it does
+// through execute once a command is applied.
+type fsm struct {
+	mu        sync.RWMutex
+	elevators map[string]ElevatorSpec
+	requests  map[string]FloorRequest
+	calls     map[string]Call
+	seeded    bool
+
+	// execute carries a committed command out on the fleet; its result is
+	// the response of the command
+	execute func(cmd command) any
+
+	// order records when each elevator and request was added, so every
+	// node lists them the same way
+	order map[string]uint64
+	added uint64
+}
+
+func newFSM() *fsm {
+	f := &fsm{}
+	f.reset()
+	return f
+}
+
+func (f *fsm) reset() {
+	f.elevators = make(map[string]ElevatorSpec)
+	f.requests = make(map[string]FloorRequest)
+	f.calls = make(map[string]Call)
+	f.order = make(map[string]uint64)
+	f.seeded = false
+	f.added = 0
+}
+
+// add records an elevator or request as the latest one added
+func (f *fsm) add(key string) {
+	f.added++
+	f.order[key] = f.added
+}
+
+// Apply implements raft.FSM
+func (f *fsm) Apply(entry *raft.Log) interface{} {
+	var cmd command
+	if err := json.Unmarshal(entry.Data, &cmd); err != nil {
+		return fmt.Errorf("failed to decode cluster command: %w", err)
+	}
+
+	if err := f.update(cmd); err != nil {
+		return err
+	}
+	if f.execute == nil {
+		return nil
+	}
+	return f.execute(cmd)
+}
+
+// update applies a command to the replicated state
+func (f *fsm) update(cmd command) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch cmd.Type {
+	case commandSeedFleet:
+		for _, spec := range cmd.Elevators {
+			f.elevators[spec.Name] = spec
+			f.add("elevator/" + spec.Name)
+		}
+		f.seeded = true
+	case commandAddElevator:
+		if cmd.Elevator == nil {
+			return fmt.Errorf("%s command without an elevator", cmd.Type)
+		}
+		f.elevators[cmd.Elevator.Name] = *cmd.Elevator
+		f.add("elevator/" + cmd.Elevator.Name)
+	case commandRemoveElevator:
+		delete(f.elevators, cmd.Name)
+		delete(f.order, "elevator/"+cmd.Name)
+	case commandTuneElevator:
+		if cmd.Tuning == nil {
+			return fmt.Errorf("%s command without a tuning", cmd.Type)
+		}
+		if spec, exists := f.elevators[cmd.Name]; exists {
+			cb := cmd.Tuning.CircuitBreaker
+			spec.EachFloorDuration = cmd.Tuning.EachFloorDuration
+			spec.OpenDoorDuration = cmd.Tuning.OpenDoorDuration
+			spec.OverloadThreshold = cmd.Tuning.OverloadThreshold
+			spec.CircuitBreaker = &cb
+			f.elevators[cmd.Name] = spec
+		}
+	case commandInjectFault:
+		if cmd.Fault == nil {
+			return fmt.Errorf("%s command without a fault", cmd.Type)
+		}
+		if spec, exists := f.elevators[cmd.Name]; exists {
+			spec.Faults = append(withoutFault(spec.Faults, cmd.Fault.Type), *cmd.Fault)
+			f.elevators[cmd.Name] = spec
+		}
+	case commandClearFault:
+		if spec, exists := f.elevators[cmd.Name]; exists {
+			spec.Faults = withoutFault(spec.Faults, cmd.FaultType)
+			f.elevators[cmd.Name] = spec
+		}
+	case commandRequest:
+		if cmd.Request == nil {
+			return fmt.Errorf("%s command without a request", cmd.Type)
+		}
+		f.requests[cmd.Request.ID] = *cmd.Request
+		f.add("request/" + cmd.Request.ID)
+	case commandFinishRequest:
+		delete(f.requests, cmd.RequestID)
+		delete(f.order, "request/"+cmd.RequestID)
+	case commandCall:
+		if cmd.Call == nil {
+			return fmt.Errorf("%s command without a call", cmd.Type)
+		}
+		f.calls[cmd.Call.ID] = *cmd.Call
+		f.add("call/" + cmd.Call.ID)
+	case commandFinishCall:
+		delete(f.calls, cmd.CallID)
+		delete(f.order, "call/"+cmd.CallID)
+	default:
+		return fmt.Errorf("unknown cluster command %q", cmd.Type)
+	}
+	return nil
+}
+
+// withoutFault returns faults without the ones of a type; an empty type
+// removes all of them
+func withoutFault(faults []domain.Fault, faultType domain.FaultType) []domain.Fault {
+	kept := []domain.Fault{}
+	for _, fault := range faults {
+		if faultType != "" && fault.Type != faultType {
+			kept = append(kept, fault)
+		}
+	}
+	if len(kept) == 0 {
+		return nil
+	}
+	return kept
+}
+
+// state returns a copy of the replicated state
+func (f *fsm) state() State {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	state := State{
+		Elevators: make([]ElevatorSpec, 0, len(f.elevators)),
+		Requests:  make([]FloorRequest, 0, len(f.requests)),
+		Calls:     make([]Call, 0, len(f.calls)),
+		Seeded:    f.seeded,
+	}
+	for _, spec := range f.elevators {
+		state.Elevators = append(state.Elevators, spec)
+	}
+	for _, request := range f.requests {
+		state.Requests = append(state.Requests, request)
+	}
+	for _, call := range f.calls {
+		state.Calls = append(state.Calls, call)
+	}
+	sort.Slice(state.Elevators, func(i, j int) bool {
+		return f.order["elevator/"+state.Elevators[i].Name] < f.order["elevator/"+state.Elevators[j].Name]
+	})
+	sort.Slice(state.Requests, func(i, j int) bool {
+		return f.order["request/"+state.Requests[i].ID] < f.order["request/"+state.Requests[j].ID]
+	})
+	sort.Slice(state.Calls, func(i, j int) bool {
+		return f.order["call/"+state.Calls[i].ID] < f.order["call/"+state.Calls[j].ID]
+	})
+	return state
+}
+
+// hasElevator reports whether an elevator is part of the replicated fleet
+func (f *fsm) hasElevator(name string) bool {
+	_, exists := f.elevator(name)
+	return exists
+}
+
+// elevator returns the spec of an elevator of the replicated fleet
+func (f *fsm) elevator(name string) (ElevatorSpec, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	spec, exists := f.elevators[name]
+	return spec, exists
+}
+
+// Snapshot implements raft.FSM
+func (f *fsm) Snapshot() (raft.FSMSnapshot, error) {
+	return &snapshot{state: f.state()}, nil
+}
+
+// Restore implements raft.FSM
+func (f *fsm) Restore(reader io.ReadCloser) error {
+	defer reader.Close()
+
+	var state State
+	if err := json.NewDecoder(reader).Decode(&state); err != nil {
+		return fmt.Errorf("failed to decode cluster snapshot: %w", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reset()
+	for _, spec := range state.Elevators {
+		f.elevators[spec.Name] = spec
+		f.add("elevator/" + spec.Name)
+	}
+	for _, request := range state.Requests {
+		f.requests[request.ID] = request
+		f.add("request/" + request.ID)
+	}
+	for _, call := range state.Calls {
+		f.calls[call.ID] = call
+		f.add("call/" + call.ID)
+	}
+	f.seeded = state.Seeded
+	return nil
+}
+
+// snapshot is a point-in-time copy of the replicated state
+type snapshot struct {
+	state State
+}
+
+// Persist implements raft.FSMSnapshot
+func (s *snapshot) Persist(sink raft.SnapshotSink) error {
+	if err := json.NewEncoder(sink).Encode(s.state); err != nil {
+		_ = sink.Cancel()
+		return err
+	}
+	return sink.Close()
+}
+
+// Release implements raft.FSMSnapshot
+func (s *snapshot) Release() {}
@@ -0,0 +1,80 @@
+package cluster
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/raft"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slavakukuyev/elevator-go/internal/circuitbreaker"
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+	"github.com/slavakukuyev/elevator-go/internal/elevator"
+)
+
+func applyCommand(t *testing.T, f *fsm, cmd command) {
+	t.Helper()
+	data, err := json.Marshal(cmd)
+	require.NoError(t, err)
+	assert.Nil(t, f.Apply(&raft.Log{Data: data}))
+}
+
+// snapshotSink collects a persisted snapshot
+type snapshotSink struct {
+	bytes.Buffer
+}
+
+func (s *snapshotSink) ID() string    { return "test" }
+func (s *snapshotSink) Cancel() error { return nil }
+func (s *snapshotSink) Close() error  { return nil }
+
+func TestFSM_ApplyAndRestore(t *testing.T) {
+	f := newFSM()
+	applyCommand(t, f, command{Type: commandSeedFleet, Elevators: []ElevatorSpec{{Name: "B"}, {Name: "A"}}})
+	applyCommand(t, f, command{Type: commandAddElevator, Elevator: &ElevatorSpec{Name: "C"}})
+	applyCommand(t, f, command{Type: commandRemoveElevator, Name: "A"})
+	applyCommand(t, f, command{Type: commandRequest, Request: &FloorRequest{ID: "2", FromFloor: 1, ToFloor: 5}})
+	applyCommand(t, f, command{Type: commandRequest, Request: &FloorRequest{ID: "1", FromFloor: 3, ToFloor: 0}})
+	applyCommand(t, f, command{Type: commandRequest, Request: &FloorRequest{ID: "3", FromFloor: 2, ToFloor: 4}})
+	applyCommand(t, f, command{Type: commandFinishRequest, RequestID: "3"})
+	applyCommand(t, f, command{Type: commandCall, Call: &Call{ID: "4", Floor: 6, Elevator: "B"}})
+	applyCommand(t, f, command{Type: commandCall, Call: &Call{ID: "5", Floor: 2, Direction: domain.DirectionUp}})
+	applyCommand(t, f, command{Type: commandFinishCall, CallID: "4"})
+	applyCommand(t, f, command{Type: commandTuneElevator, Name: "C", Tuning: &elevator.Tuning{
+		EachFloorDuration: time.Second, OpenDoorDuration: 2 * time.Second, OverloadThreshold: 8,
+		CircuitBreaker: circuitbreaker.Settings{MaxFailures: 3},
+	}})
+	applyCommand(t, f, command{Type: commandInjectFault, Name: "C", Fault: &domain.Fault{Type: domain.FaultSlowTravel, SlowdownFactor: 2}})
+	applyCommand(t, f, command{Type: commandInjectFault, Name: "C", Fault: &domain.Fault{Type: domain.FaultDoorFailure}})
+	applyCommand(t, f, command{Type: commandInjectFault, Name: "C", Fault: &domain.Fault{Type: domain.FaultSlowTravel, SlowdownFactor: 4}})
+	applyCommand(t, f, command{Type: commandClearFault, Name: "C", FaultType: domain.FaultDoorFailure})
+
+	// Entries keep the order they were added in
+	state := f.state()
+	assert.True(t, state.Seeded)
+	assert.Equal(t, []ElevatorSpec{{Name: "B"}, {
+		Name: "C", EachFloorDuration: time.Second, OpenDoorDuration: 2 * time.Second, OverloadThreshold: 8,
+		CircuitBreaker: &circuitbreaker.Settings{MaxFailures: 3},
+		Faults:         []domain.Fault{{Type: domain.FaultSlowTravel, SlowdownFactor: 4}},
+	}}, state.Elevators)
+	assert.Equal(t, []FloorRequest{{ID: "2", FromFloor: 1, ToFloor: 5}, {ID: "1", FromFloor: 3, ToFloor: 0}}, state.Requests)
+	assert.Equal(t, []Call{{ID: "5", Floor: 2, Direction: domain.DirectionUp}}, state.Calls)
+
+	snapshot, err := f.Snapshot()
+	require.NoError(t, err)
+	sink := &snapshotSink{}
+	require.NoError(t, snapshot.Persist(sink))
+
+	restored := newFSM()
+	require.NoError(t, restored.Restore(io.NopCloser(&sink.Buffer)))
+	assert.Equal(t, state, restored.state())
+
+	// Unknown commands are rejected
+	data, err := json.Marshal(command{Type: "unknown"})
+	require.NoError(t, err)
+	assert.Error(t, f.Apply(&raft.Log{Data: data}).(error))
+}
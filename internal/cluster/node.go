@@ -0,0 +1,1021 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hashicorp/raft"
+
+	"github.com/slavakukuyev/elevator-go/internal/constants"
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+	"github.com/slavakukuyev/elevator-go/internal/elevator"
+	"github.com/slavakukuyev/elevator-go/internal/events"
+	"github.com/slavakukuyev/elevator-go/internal/infra/config"
+	"github.com/slavakukuyev/elevator-go/internal/manager"
+	"github.com/slavakukuyev/elevator-go/metrics"
+)
+
+const (
+	// applyTimeout bounds how long the leader waits for a change to be
+	// replicated to a majority of the nodes
+	applyTimeout = 5 * time.Second
+	// forwardTimeout bounds a call forwarded to the leader
+	forwardTimeout = 10 * time.Second
+	// requestEventBuffer bounds the events queued for request tracking
+	requestEventBuffer = 1024
+	// notifyBuffer bounds the leadership changes queued for the node; Raft
+	// blocks while it is full
+	notifyBuffer = 16
+)
+
+// Options configures a cluster node
+type Options struct {
+	// NodeID names this node among Peers
+	NodeID string
+	// Peers lists every node of the cluster, this one included
+	Peers []config.ClusterPeer
+	// Token authenticates calls forwarded between nodes; empty disables the
+	// check
+	Token string
+	// ElectionTimeout is how long followers go without hearing from the
+	// leader before electing a new one; zero keeps the Raft default
+	ElectionTimeout time.Duration
+	// Transport carries Raft traffic; nil listens on the node's Raft address
+	// over TCP
+	Transport raft.Transport
+	// Client forwards calls to the leader; nil uses a client with a default
+	// timeout
+	Client *http.Client
+	// DefaultFleet is the fleet the first leader of the cluster creates
+	DefaultFleet []ElevatorSpec
+}
+
+// OptionsFromConfig returns the options of the node a configuration
+// describes
+func OptionsFromConfig(cfg *config.Config) (Options, error) {
+	peers, err := config.ParseClusterPeers(cfg.ClusterPeers)
+	if err != nil {
+		return Options{}, domain.NewValidationError("invalid cluster peers", err).
+			WithContext("cluster_peers", cfg.ClusterPeers)
+	}
+	return Options{
+		NodeID:          cfg.ClusterNodeID,
+		Peers:           peers,
+		Token:           cfg.ClusterToken,
+		ElectionTimeout: cfg.ClusterElectionTimeout,
+		DefaultFleet:    DefaultFleet(cfg),
+	}, nil
+}
+
+// DefaultFleet returns the passenger elevators a configuration asks for,
+// named after its prefix like the fleet of a standalone manager
+func DefaultFleet(cfg *config.Config) []ElevatorSpec {
+	fleet := make([]ElevatorSpec, 0, max(cfg.DefaultElevatorCount, 0))
+	for i := 0; i < cfg.DefaultElevatorCount; i++ {
+		name := fmt.Sprintf("%s-%d", cfg.NamePrefix, i+1)
+		settings := cfg.ElevatorSettings(name)
+		fleet = append(fleet, ElevatorSpec{
+			Name:              name,
+			Type:              domain.ElevatorTypePassenger,
+			MinFloor:          cfg.MinFloor,
+			MaxFloor:          cfg.MaxFloor,
+			EachFloorDuration: settings.EachFloorDuration,
+			OpenDoorDuration:  settings.OpenDoorDuration,
+			OverloadThreshold: settings.OverloadThreshold,
+		})
+	}
+	return fleet
+}
+
+// Node is one node of a cluster of elevator services. The nodes elect a
+// leader through Raft. Only the leader runs the fleet on its Manager. Every
+// change to the fleet, its tuning and faults, and every floor request, hall
+// call and car call is appended to the replicated log first and carried out
+// by the leader once it is committed. Followers forward calls to the leader,
+// and the node that takes over after the leader fails starts the replicated
+// fleet again and serves the requests and calls still pending.
+//
+// The Raft log and snapshots are kept in memory, so a node that restarts
+// catches up from its peers.
+type Node struct {
+	id      string
+	peers   map[string]config.ClusterPeer
+	token   string
+	raft    *raft.Raft
+	fsm     *fsm
+	manager *manager.Manager
+	client  *http.Client
+	closer  io.Closer
+	logger  *slog.Logger
+
+	defaultFleet []ElevatorSpec
+
+	// leading is set once the node runs the fleet and cleared before it
+	// hands the fleet over; fleetMu serialises fleet changes with
+	// leadership changes
+	leading atomic.Bool
+	fleetMu sync.Mutex
+
+	// trips maps the trips the leader's manager tracks to the replicated
+	// requests they serve, and calls the stops of the fleet to the
+	// replicated calls they serve
+	tripsMu sync.Mutex
+	trips   map[string]string
+	calls   map[callStop][]string
+
+	// inflight holds the contexts of the calls waiting for their commands
+	// to be committed, by command origin
+	inflight sync.Map
+	sequence atomic.Uint64
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewNode starts a cluster node that runs the fleet on m whenever it leads
+// the cluster. m should start without elevators: the fleet is the
+// replicated one.
+func NewNode(opts Options, m *manager.Manager) (*Node, error) {
+	peers := make(map[string]config.ClusterPeer, len(opts.Peers))
+	servers := make([]raft.Server, 0, len(opts.Peers))
+	for _, peer := range opts.Peers {
+		peers[peer.ID] = peer
+		servers = append(servers, raft.Server{
+			ID:      raft.ServerID(peer.ID),
+			Address: raft.ServerAddress(peer.RaftAddr),
+		})
+	}
+	self, ok := peers[opts.NodeID]
+	if !ok {
+		return nil, domain.NewValidationError("cluster peers must include this node", nil).
+			WithContext("cluster_node_id", opts.NodeID)
+	}
+
+	notify := make(chan bool, notifyBuffer)
+	raftConfig := raft.DefaultConfig()
+	raftConfig.LocalID = raft.ServerID(opts.NodeID)
+	raftConfig.NotifyCh = notify
+	raftConfig.LogLevel = "ERROR"
+	if opts.ElectionTimeout > 0 {
+		raftConfig.HeartbeatTimeout = opts.ElectionTimeout
+		raftConfig.ElectionTimeout = opts.ElectionTimeout
+		raftConfig.LeaderLeaseTimeout = opts.ElectionTimeout / 2
+	}
+
+	transport := opts.Transport
+	var closer io.Closer
+	if transport == nil {
+		advertise, err := net.ResolveTCPAddr("tcp", self.RaftAddr)
+		if err != nil {
+			return nil, domain.NewValidationError("invalid cluster raft address", err).
+				WithContext("raft_addr", self.RaftAddr)
+		}
+		tcp, err := raft.NewTCPTransport(self.RaftAddr, advertise, 3, forwardTimeout, io.Discard)
+		if err != nil {
+			return nil, domain.NewInternalError("failed to listen for cluster traffic", err).
+				WithContext("raft_addr", self.RaftAddr)
+		}
+		transport, closer = tcp, tcp
+	}
+
+	client := opts.Client
+	if client == nil {
+		client = &http.Client{Timeout: forwardTimeout}
+	}
+
+	n := &Node{
+		id:           opts.NodeID,
+		peers:        peers,
+		token:        opts.Token,
+		fsm:          newFSM(),
+		manager:      m,
+		client:       client,
+		closer:       closer,
+		defaultFleet: opts.DefaultFleet,
+		logger: slog.With(
+			slog.String("component", constants.ComponentCluster),
+			slog.String("node", opts.NodeID)),
+		trips: make(map[string]string),
+		calls: make(map[callStop][]string),
+	}
+	n.ctx, n.cancel = context.WithCancel(context.Background())
+	n.fsm.execute = n.execute
+	// IDs stay unique across restarts of the node
+	n.sequence.Store(uint64(time.Now().UnixNano()))
+
+	store := raft.NewInmemStore()
+	r, err := raft.NewRaft(raftConfig, n.fsm, store, store, raft.NewInmemSnapshotStore(), transport)
+	if err != nil {
+		n.cancel()
+		if closer != nil {
+			_ = closer.Close()
+		}
+		return nil, domain.NewInternalError("failed to start raft", err)
+	}
+	n.raft = r
+
+	// Every node bootstraps the same static configuration; a node that
+	// already has one keeps it
+	err = r.BootstrapCluster(raft.Configuration{Servers: servers}).Error()
+	if err != nil && !errors.Is(err, raft.ErrCantBootstrap) {
+		_ = n.Shutdown()
+		return nil, domain.NewInternalError("failed to bootstrap cluster", err)
+	}
+
+	metrics.SetClusterLeader(n.id, false)
+	n.wg.Add(2)
+	go n.watchLeadership(notify)
+	go n.trackRequests(m.Events().Subscribe(requestEventBuffer))
+
+	n.logger.Info("cluster node started",
+		slog.String("raft_addr", self.RaftAddr),
+		slog.Int("peers", len(peers)))
+	return n, nil
+}
+
+// ID returns the ID of the node
+func (n *Node) ID() string {
+	return n.id
+}
+
+// IsLeader reports whether the node leads the cluster and runs the fleet
+func (n *Node) IsLeader() bool {
+	return n.leading.Load()
+}
+
+// Leader returns the peer the node knows as the leader; ok is false while
+// the cluster has none
+func (n *Node) Leader() (config.ClusterPeer, bool) {
+	_, id := n.raft.LeaderWithID()
+	peer, ok := n.peers[string(id)]
+	return peer, ok
+}
+
+// State returns the replicated fleet and pending requests as this node
+// knows them
+func (n *Node) State() State {
+	return n.fsm.state()
+}
+
+// RequestElevator requests an elevator from the leader's fleet and returns
+// the name of the elevator assigned
+func (n *Node) RequestElevator(ctx context.Context, fromFloor, toFloor int, call domain.CallOptions) (string, error) {
+	if !n.leading.Load() {
+		var response requestResponse
+		err := n.forward(ctx, "request", http.MethodPost, requestsPath,
+			requestBody{From: fromFloor, To: toFloor, Call: call}, &response)
+		return response.ElevatorName, err
+	}
+
+	trip, err := n.RequestTrip(ctx, fromFloor, toFloor, call)
+	return trip.Elevator, err
+}
+
+// RequestTrip requests an elevator from the fleet of this node, which must
+// lead the cluster, and returns the trip that serves the request. Trips are
+// tracked by the leader's manager only, so followers reject the call and
+// name the leader.
+func (n *Node) RequestTrip(ctx context.Context, fromFloor, toFloor int, call domain.CallOptions) (manager.Trip, error) {
+	if !n.leading.Load() {
+		return manager.Trip{}, n.LeaderRequiredError()
+	}
+
+	request := &FloorRequest{ID: n.newID(), FromFloor: fromFloor, ToFloor: toFloor, Call: call}
+	res, err := n.apply(ctx, command{Type: commandRequest, Request: request})
+	if err != nil {
+		return manager.Trip{}, err
+	}
+	if res.err != nil {
+		n.finishRequest(request.ID)
+		return manager.Trip{}, res.err
+	}
+	assigned, ok := res.value.(assignment)
+	if !ok {
+		// Leadership moved on before the request was assigned; the new
+		// leader serves it
+		return manager.Trip{}, notLeaderError(n.id)
+	}
+	if assigned.merged {
+		// The request joined a pending trip that serves another one
+		n.finishRequest(request.ID)
+	}
+	return assigned.trip, nil
+}
+
+// LeaderRequiredError reports a call that only the leader serves and
+// names the leader's API, if the cluster has one
+func (n *Node) LeaderRequiredError() error {
+	leader, ok := n.Leader()
+	if !ok || leader.ID == n.id {
+		return domain.NewExternalError("cluster has no leader", nil).
+			WithContext("node", n.id)
+	}
+	return domain.NewExternalError("node does not lead the cluster, send the call to the leader at "+leader.APIURL, nil).
+		WithContext("node", n.id).
+		WithContext("leader", leader.ID).
+		WithContext("leader_url", leader.APIURL)
+}
+
+// HallCall assigns an up or down button pressed at a floor to an elevator
+// of the leader's fleet and returns the name of the elevator
+func (n *Node) HallCall(ctx context.Context, floor int, direction domain.Direction, options domain.CallOptions) (string, error) {
+	if !n.leading.Load() {
+		var response requestResponse
+		err := n.forward(ctx, "hall_call", http.MethodPost, hallCallsPath,
+			hallCallBody{Floor: floor, Direction: direction, Call: options}, &response)
+		return response.ElevatorName, err
+	}
+
+	call := &Call{ID: n.newID(), Floor: floor, Direction: direction, Call: options}
+	res, err := n.apply(ctx, command{Type: commandCall, Call: call})
+	if err != nil {
+		return "", err
+	}
+	if res.err != nil {
+		n.finishCall(call.ID)
+		return "", res.err
+	}
+	name, ok := res.value.(string)
+	if !ok {
+		return "", notLeaderError(n.id)
+	}
+	n.settleCalls(callStop{elevator: name, direction: direction, floor: floor})
+	return name, nil
+}
+
+// CarCall adds a destination pressed inside an elevator of the leader's
+// fleet and returns the direction of the stop
+func (n *Node) CarCall(ctx context.Context, name string, floor int) (domain.Direction, error) {
+	if !n.leading.Load() {
+		var response carCallResponse
+		err := n.forward(ctx, "car_call", http.MethodPost, elevatorsPath+"/"+name+"/car-calls",
+			carCallBody{Floor: floor}, &response)
+		return response.Direction, err
+	}
+
+	call := &Call{ID: n.newID(), Floor: floor, Elevator: name}
+	res, err := n.apply(ctx, command{Type: commandCall, Call: call})
+	if err != nil {
+		return "", err
+	}
+	if res.err != nil {
+		n.finishCall(call.ID)
+		return "", res.err
+	}
+	direction, ok := res.value.(domain.Direction)
+	if !ok {
+		return "", notLeaderError(n.id)
+	}
+	n.settleCalls(callStop{elevator: name, direction: direction, floor: floor})
+	return direction, nil
+}
+
+// AddElevator adds an elevator to the leader's fleet
+func (n *Node) AddElevator(ctx context.Context, spec ElevatorSpec) error {
+	if !n.leading.Load() {
+		return n.forward(ctx, "add_elevator", http.MethodPost, elevatorsPath, spec, nil)
+	}
+
+	n.fleetMu.Lock()
+	defer n.fleetMu.Unlock()
+	if !n.leading.Load() {
+		return notLeaderError(n.id)
+	}
+
+	// Creating the elevator validates the spec before it is replicated; a
+	// spec the cluster does not take is not kept running
+	if err := n.startElevator(ctx, spec); err != nil {
+		return err
+	}
+	if _, err := n.apply(ctx, command{Type: commandAddElevator, Elevator: &spec}); err != nil {
+		if deleteErr := n.manager.DeleteElevator(ctx, spec.Name); deleteErr != nil {
+			n.logger.Error("failed to remove elevator the cluster did not take",
+				slog.String("elevator", spec.Name),
+				slog.String("error", deleteErr.Error()))
+		}
+		return err
+	}
+	return nil
+}
+
+// DeleteElevator removes an elevator from the leader's fleet once it has
+// served its pending requests
+func (n *Node) DeleteElevator(ctx context.Context, name string) error {
+	if !n.leading.Load() {
+		return n.forward(ctx, "delete_elevator", http.MethodDelete, elevatorsPath+"/"+name, nil, nil)
+	}
+
+	n.fleetMu.Lock()
+	defer n.fleetMu.Unlock()
+	if !n.leading.Load() {
+		return notLeaderError(n.id)
+	}
+
+	err := n.manager.DeleteElevator(ctx, name)
+	if n.manager.GetElevator(name) != nil || !n.fsm.hasElevator(name) {
+		return err
+	}
+	if _, applyErr := n.apply(ctx, command{Type: commandRemoveElevator, Name: name}); applyErr != nil {
+		return applyErr
+	}
+
+	// An elevator that failed to start when this node took over is only
+	// part of the replicated fleet
+	var domainErr *domain.DomainError
+	if errors.As(err, &domainErr) && domainErr.Type == domain.ErrTypeNotFound {
+		return nil
+	}
+	return err
+}
+
+// TuneElevator changes the settings of an elevator of the leader's fleet
+func (n *Node) TuneElevator(ctx context.Context, name string, patch elevator.TuningPatch) (elevator.Tuning, error) {
+	if !n.leading.Load() {
+		var tuning elevator.Tuning
+		err := n.forward(ctx, "tune_elevator", http.MethodPatch, elevatorsPath+"/"+name, patch, &tuning)
+		return tuning, err
+	}
+
+	n.fleetMu.Lock()
+	defer n.fleetMu.Unlock()
+	if !n.leading.Load() {
+		return elevator.Tuning{}, notLeaderError(n.id)
+	}
+
+	current, err := n.manager.ElevatorTuning(name)
+	if err != nil {
+		return current, err
+	}
+	tuned := patch.Apply(current)
+	if err := tuned.Validate(); err != nil {
+		return current, err
+	}
+
+	res, err := n.apply(ctx, command{Type: commandTuneElevator, Name: name, Tuning: &tuned})
+	if err != nil {
+		return current, err
+	}
+	if res.err != nil {
+		return current, res.err
+	}
+	return tuned, nil
+}
+
+// ElevatorTuning returns the settings of an elevator of the replicated fleet
+func (n *Node) ElevatorTuning(name string) (elevator.Tuning, error) {
+	if n.leading.Load() {
+		return n.manager.ElevatorTuning(name)
+	}
+
+	spec, exists := n.fsm.elevator(name)
+	if !exists {
+		return elevator.Tuning{}, elevatorNotFoundError(name)
+	}
+	tuning := elevator.Tuning{
+		EachFloorDuration: spec.EachFloorDuration,
+		OpenDoorDuration:  spec.OpenDoorDuration,
+		OverloadThreshold: spec.OverloadThreshold,
+		CircuitBreaker:    n.manager.Config().CircuitBreakerSettings(),
+	}
+	if spec.CircuitBreaker != nil {
+		tuning.CircuitBreaker = *spec.CircuitBreaker
+	}
+	return tuning, nil
+}
+
+// InjectFault activates a simulated hardware failure on an elevator of the
+// leader's fleet. The fault stays part of the replicated fleet until it is
+// cleared, so a new leader starts the elevator with it again.
+func (n *Node) InjectFault(ctx context.Context, name string, fault domain.Fault) (domain.Fault, error) {
+	if !n.leading.Load() {
+		var injected domain.Fault
+		err := n.forward(ctx, "inject_fault", http.MethodPost, elevatorsPath+"/"+name+"/faults", fault, &injected)
+		return injected, err
+	}
+
+	fault, err := fault.Normalize()
+	if err != nil {
+		return fault, err
+	}
+	if n.manager.GetElevator(name) == nil {
+		return fault, elevatorNotFoundError(name)
+	}
+
+	res, err := n.apply(ctx, command{Type: commandInjectFault, Name: name, Fault: &fault})
+	if err != nil {
+		return fault, err
+	}
+	return fault, res.err
+}
+
+// ClearFault deactivates a fault of an elevator of the leader's fleet; an
+// empty fault type clears all of them
+func (n *Node) ClearFault(ctx context.Context, name string, faultType domain.FaultType) error {
+	if !n.leading.Load() {
+		path := elevatorsPath + "/" + name + "/faults"
+		if faultType != "" {
+			path += "?type=" + string(faultType)
+		}
+		return n.forward(ctx, "clear_fault", http.MethodDelete, path, nil, nil)
+	}
+
+	faults, err := n.manager.ElevatorFaults(name)
+	if err != nil {
+		return err
+	}
+	if faultType != "" && len(withoutFault(faults, faultType)) == len(faults) {
+		return domain.NewNotFoundError("fault is not active", nil).
+			WithContext("name", name).
+			WithContext("fault", string(faultType))
+	}
+
+	res, err := n.apply(ctx, command{Type: commandClearFault, Name: name, FaultType: faultType})
+	if err != nil {
+		return err
+	}
+	return res.err
+}
+
+// ElevatorFaults returns the active faults of an elevator of the replicated
+// fleet
+func (n *Node) ElevatorFaults(name string) ([]domain.Fault, error) {
+	if n.leading.Load() {
+		return n.manager.ElevatorFaults(name)
+	}
+
+	spec, exists := n.fsm.elevator(name)
+	if !exists {
+		return nil, elevatorNotFoundError(name)
+	}
+	return append([]domain.Fault{}, spec.Faults...), nil
+}
+
+// Shutdown hands leadership over to another node when this one leads, then
+// stops the node. The fleet on the manager is dropped.
+func (n *Node) Shutdown() error {
+	if n.raft.State() == raft.Leader {
+		if err := n.raft.LeadershipTransfer().Error(); err != nil {
+			n.logger.Warn("failed to hand leadership over before shutdown",
+				slog.String("error", err.Error()))
+		}
+	}
+
+	err := n.raft.Shutdown().Error()
+	n.cancel()
+	n.wg.Wait()
+	n.follow()
+	if n.closer != nil {
+		if closeErr := n.closer.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+
+	n.logger.Info("cluster node stopped")
+	return err
+}
+
+// result is the outcome of a command the leader carried out once it was
+// committed
+type result struct {
+	value any
+	err   error
+}
+
+// assignment is the outcome of a floor request: the trip that serves it and
+// whether the request joined a trip that serves an earlier one
+type assignment struct {
+	trip   manager.Trip
+	merged bool
+}
+
+// apply replicates a change, waits until it is committed and returns the
+// outcome of carrying it out on the fleet. The leader carries the change
+// out with ctx, when given.
+func (n *Node) apply(ctx context.Context, cmd command) (result, error) {
+	if ctx != nil {
+		cmd.Origin = n.newID()
+		n.inflight.Store(cmd.Origin, ctx)
+		defer n.inflight.Delete(cmd.Origin)
+	}
+
+	data, err := json.Marshal(cmd)
+	if err != nil {
+		return result{}, domain.NewInternalError("failed to encode cluster command", err).
+			WithContext("command", string(cmd.Type))
+	}
+
+	future := n.raft.Apply(data, applyTimeout)
+	if err := future.Error(); err != nil {
+		return result{}, domain.NewExternalError("failed to replicate change to the cluster", err).
+			WithContext("command", string(cmd.Type))
+	}
+	switch response := future.Response().(type) {
+	case error:
+		return result{}, domain.NewInternalError("failed to apply cluster command", response).
+			WithContext("command", string(cmd.Type))
+	case result:
+		return response, nil
+	}
+	return result{}, nil
+}
+
+// execute carries a committed command out on the fleet when the node leads
+// the cluster. It runs on the Raft goroutine, so it must not wait for other
+// commands to be committed.
+func (n *Node) execute(cmd command) any {
+	if !n.leading.Load() {
+		return nil
+	}
+
+	ctx := n.ctx
+	if origin, ok := n.inflight.Load(cmd.Origin); ok {
+		ctx = origin.(context.Context)
+	}
+
+	switch cmd.Type {
+	case commandRequest:
+		request := cmd.Request
+		trip, err := n.manager.RequestTripWithOptions(ctx, request.FromFloor, request.ToFloor, request.Call)
+		if err != nil {
+			return result{err: err}
+		}
+		_, merged := n.trackTrip(trip.ID, request.ID)
+		return result{value: assignment{trip: trip, merged: merged}}
+	case commandCall:
+		stop, err := n.placeCall(ctx, *cmd.Call)
+		if err != nil {
+			return result{err: err}
+		}
+		if cmd.Call.IsCarCall() {
+			return result{value: stop.direction}
+		}
+		return result{value: stop.elevator}
+	case commandTuneElevator:
+		tuning, err := n.manager.TuneElevator(ctx, cmd.Name, fullPatch(*cmd.Tuning))
+		return result{value: tuning, err: err}
+	case commandInjectFault:
+		fault, err := n.manager.InjectFault(ctx, cmd.Name, *cmd.Fault)
+		return result{value: fault, err: err}
+	case commandClearFault:
+		return result{err: n.manager.ClearFault(ctx, cmd.Name, cmd.FaultType)}
+	}
+	return nil
+}
+
+// placeCall places a replicated hall call or car call on the fleet and
+// tracks the stop that serves it
+func (n *Node) placeCall(ctx context.Context, call Call) (callStop, error) {
+	stop := callStop{elevator: call.Elevator, direction: call.Direction, floor: call.Floor}
+	if call.IsCarCall() {
+		direction, err := n.manager.CarCall(ctx, call.Elevator, call.Floor)
+		if err != nil {
+			return stop, err
+		}
+		stop.direction = direction
+	} else {
+		el, err := n.manager.RequestHallCall(ctx, call.Floor, call.Direction, call.Call)
+		if err != nil {
+			return stop, err
+		}
+		stop.elevator = el.Name()
+	}
+
+	n.tripsMu.Lock()
+	n.calls[stop] = append(n.calls[stop], call.ID)
+	n.tripsMu.Unlock()
+	return stop, nil
+}
+
+// fullPatch returns a patch that sets every setting of a tuning
+func fullPatch(t elevator.Tuning) elevator.TuningPatch {
+	cb := t.CircuitBreaker
+	return elevator.TuningPatch{
+		EachFloorDuration: &t.EachFloorDuration,
+		OpenDoorDuration:  &t.OpenDoorDuration,
+		OverloadThreshold: &t.OverloadThreshold,
+		CircuitBreaker: elevator.CircuitBreakerPatch{
+			MaxFailures:      &cb.MaxFailures,
+			ResetTimeout:     &cb.ResetTimeout,
+			HalfOpenLimit:    &cb.HalfOpenLimit,
+			FailureThreshold: &cb.FailureThreshold,
+			Window:           &cb.Window,
+			MinRequests:      &cb.MinRequests,
+		},
+	}
+}
+
+// newID returns an ID for a request, call or command that is unique in the
+// cluster
+func (n *Node) newID() string {
+	return n.id + "-" + strconv.FormatUint(n.sequence.Add(1), 36)
+}
+
+// startElevator creates an elevator of the replicated fleet on the manager
+func (n *Node) startElevator(ctx context.Context, spec ElevatorSpec) error {
+	err := n.manager.AddTypedElevator(ctx, n.manager.Config(), spec.Type, spec.Name,
+		spec.MinFloor, spec.MaxFloor,
+		spec.EachFloorDuration, spec.OpenDoorDuration, spec.OverloadThreshold)
+	if err != nil {
+		return err
+	}
+
+	if spec.CircuitBreaker != nil {
+		cb := *spec.CircuitBreaker
+		_, err = n.manager.TuneElevator(ctx, spec.Name, elevator.TuningPatch{
+			CircuitBreaker: elevator.CircuitBreakerPatch{
+				MaxFailures:      &cb.MaxFailures,
+				ResetTimeout:     &cb.ResetTimeout,
+				HalfOpenLimit:    &cb.HalfOpenLimit,
+				FailureThreshold: &cb.FailureThreshold,
+				Window:           &cb.Window,
+				MinRequests:      &cb.MinRequests,
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, fault := range spec.Faults {
+		if _, err := n.manager.InjectFault(ctx, spec.Name, fault); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// trackTrip maps a trip of the manager to the replicated request it serves.
+// It returns the request the trip already serves, if any.
+func (n *Node) trackTrip(tripID, requestID string) (string, bool) {
+	n.tripsMu.Lock()
+	defer n.tripsMu.Unlock()
+
+	if existing, tracked := n.trips[tripID]; tracked {
+		return existing, true
+	}
+	n.trips[tripID] = requestID
+	return requestID, false
+}
+
+// watchLeadership takes the fleet over when the node becomes leader and
+// hands it back when it steps down
+func (n *Node) watchLeadership(notify <-chan bool) {
+	defer n.wg.Done()
+	for {
+		select {
+		case <-n.ctx.Done():
+			return
+		case leader := <-notify:
+			if leader {
+				n.lead()
+			} else {
+				n.follow()
+			}
+		}
+	}
+}
+
+// lead starts the replicated fleet on the manager and serves the pending
+// requests again
+func (n *Node) lead() {
+	// Apply every committed change first, so the fleet is started from the
+	// latest state
+	for {
+		err := n.raft.Barrier(applyTimeout).Error()
+		if err == nil {
+			break
+		}
+		if n.ctx.Err() != nil || n.raft.State() != raft.Leader {
+			return
+		}
+		n.logger.Warn("failed to catch up with the cluster log, retrying",
+			slog.String("error", err.Error()))
+	}
+
+	n.fleetMu.Lock()
+	defer n.fleetMu.Unlock()
+
+	state := n.fsm.state()
+	if state.Seeded {
+		n.startFleet(state.Elevators)
+	} else {
+		// The first leader creates the default fleet
+		seed := command{Type: commandSeedFleet, Elevators: n.startFleet(n.defaultFleet)}
+		if _, err := n.apply(nil, seed); err != nil {
+			n.logger.Error("failed to replicate the default fleet",
+				slog.String("error", err.Error()))
+		}
+		state.Elevators = seed.Elevators
+	}
+	n.leading.Store(true)
+
+	for _, request := range state.Requests {
+		trip, err := n.manager.RequestTripWithOptions(n.ctx, request.FromFloor, request.ToFloor, request.Call)
+		if err != nil {
+			n.logger.Warn("pending request cannot be served after takeover, dropping it",
+				slog.String("request_id", request.ID),
+				slog.Int("from_floor", request.FromFloor),
+				slog.Int("to_floor", request.ToFloor),
+				slog.String("error", err.Error()))
+			n.finishRequest(request.ID)
+			continue
+		}
+		if _, merged := n.trackTrip(trip.ID, request.ID); merged {
+			n.finishRequest(request.ID)
+		}
+	}
+
+	for _, call := range state.Calls {
+		stop, err := n.placeCall(n.ctx, call)
+		if err != nil {
+			n.logger.Warn("pending call cannot be served after takeover, dropping it",
+				slog.String("call_id", call.ID),
+				slog.Int("floor", call.Floor),
+				slog.String("elevator", call.Elevator),
+				slog.String("error", err.Error()))
+			n.finishCall(call.ID)
+			continue
+		}
+		n.settleCalls(stop)
+	}
+
+	metrics.SetClusterLeader(n.id, true)
+	n.logger.Info("node leads the cluster and runs the fleet",
+		slog.Int("elevators", len(state.Elevators)),
+		slog.Int("pending_requests", len(state.Requests)),
+		slog.Int("pending_calls", len(state.Calls)))
+}
+
+// startFleet starts elevators of the replicated fleet on the manager and
+// returns the ones that started
+func (n *Node) startFleet(fleet []ElevatorSpec) []ElevatorSpec {
+	started := make([]ElevatorSpec, 0, len(fleet))
+	for _, spec := range fleet {
+		if err := n.startElevator(n.ctx, spec); err != nil {
+			n.logger.Error("failed to start elevator of the replicated fleet",
+				slog.String("elevator", spec.Name),
+				slog.String("error", err.Error()))
+			continue
+		}
+		started = append(started, spec)
+	}
+	return started
+}
+
+// follow drops the fleet the node ran as leader
+func (n *Node) follow() {
+	n.fleetMu.Lock()
+	defer n.fleetMu.Unlock()
+
+	if !n.leading.Swap(false) {
+		return
+	}
+	n.tripsMu.Lock()
+	n.trips = make(map[string]string)
+	n.calls = make(map[callStop][]string)
+	n.tripsMu.Unlock()
+
+	n.manager.DropElevators(n.ctx)
+	metrics.SetClusterLeader(n.id, false)
+	n.logger.Info("node no longer leads the cluster, fleet handed over")
+}
+
+// trackRequests removes requests and calls from the replicated state once
+// the leader's manager completes or cancels their trips or serves their
+// stops. Events missed while the subscription fell behind are made up for
+// from the state of the trips and the fleet.
+func (n *Node) trackRequests(sub *events.Subscription) {
+	defer n.wg.Done()
+	for {
+		select {
+		case <-n.ctx.Done():
+			sub.Close()
+			return
+		case event, ok := <-sub.C:
+			if !ok {
+				n.logger.Warn("request tracking fell behind the event bus, resubscribing")
+				metrics.IncError("cluster_request_tracking_overflow", constants.ComponentCluster)
+				sub = n.manager.Events().Subscribe(requestEventBuffer)
+				n.reconcile()
+				continue
+			}
+
+			switch event.Type {
+			case events.TypeRequestCompleted, events.TypeRequestCancelled:
+				tripID, _ := event.Data["trip_id"].(string)
+				n.tripsMu.Lock()
+				requestID, tracked := n.trips[tripID]
+				delete(n.trips, tripID)
+				n.tripsMu.Unlock()
+				if tracked && n.leading.Load() {
+					n.finishRequest(requestID)
+				}
+			case events.TypeStopServed:
+				floor, _ := event.Data["floor"].(int)
+				direction, _ := event.Data["direction"].(string)
+				n.finishCalls(callStop{elevator: event.Elevator, direction: domain.Direction(direction), floor: floor})
+			}
+		}
+	}
+}
+
+// reconcile finishes the requests whose trips are over and the calls whose
+// stops have been served
+func (n *Node) reconcile() {
+	n.tripsMu.Lock()
+	var finished []string
+	for tripID, requestID := range n.trips {
+		if trip, ok := n.manager.GetTrip(tripID); !ok || trip.IsFinished() {
+			delete(n.trips, tripID)
+			finished = append(finished, requestID)
+		}
+	}
+	stops := make([]callStop, 0, len(n.calls))
+	for stop := range n.calls {
+		stops = append(stops, stop)
+	}
+	n.tripsMu.Unlock()
+
+	if n.leading.Load() {
+		for _, requestID := range finished {
+			n.finishRequest(requestID)
+		}
+	}
+	for _, stop := range stops {
+		n.settleCalls(stop)
+	}
+}
+
+// finishRequest removes a request from the replicated state
+func (n *Node) finishRequest(requestID string) {
+	if _, err := n.apply(nil, command{Type: commandFinishRequest, RequestID: requestID}); err != nil {
+		n.logger.Warn("failed to replicate finished request",
+			slog.String("request_id", requestID),
+			slog.String("error", err.Error()))
+	}
+}
+
+// callStop is the stop of an elevator that serves hall calls and car calls
+type callStop struct {
+	elevator  string
+	direction domain.Direction
+	floor     int
+}
+
+// settleCalls finishes the calls of a stop the elevator no longer has to
+// make, which happens when it served the stop before the calls were
+// tracked
+func (n *Node) settleCalls(stop callStop) {
+	el := n.manager.GetElevator(stop.elevator)
+	if el != nil && el.HasStop(stop.direction, domain.NewFloor(stop.floor)) {
+		return
+	}
+	n.finishCalls(stop)
+}
+
+// finishCalls removes the calls a stop served from the replicated state
+func (n *Node) finishCalls(stop callStop) {
+	n.tripsMu.Lock()
+	callIDs := n.calls[stop]
+	delete(n.calls, stop)
+	n.tripsMu.Unlock()
+
+	if !n.leading.Load() {
+		return
+	}
+	for _, callID := range callIDs {
+		n.finishCall(callID)
+	}
+}
+
+// finishCall removes a call from the replicated state
+func (n *Node) finishCall(callID string) {
+	if _, err := n.apply(nil, command{Type: commandFinishCall, CallID: callID}); err != nil {
+		n.logger.Warn("failed to replicate finished call",
+			slog.String("call_id", callID),
+			slog.String("error", err.Error()))
+	}
+}
+
+// elevatorNotFoundError reports an elevator that is not part of the fleet
+func elevatorNotFoundError(name string) error {
+	return domain.NewNotFoundError("elevator not found", nil).
+		WithContext("name", name)
+}
+
+// notLeaderError reports a call that only the leader can serve
+func notLeaderError(node string) error {
+	return domain.NewExternalError("node does not lead the cluster", nil).
+		WithContext("node", node)
+}
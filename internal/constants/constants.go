@@ -17,6 +17,18 @@ const (
 	DefaultEachFloorDuration = 500 * time.Millisecond
 	DefaultOpenDoorDuration  = 2 * time.Second
 
+	// AccessibleDwellFactor extends the door time at the pickup and
+	// destination floors of riders with mobility needs
+	AccessibleDwellFactor = 3.0
+
+	// NuisanceDestinationsPerRider is the most pending destinations per
+	// rider on board before the car calls are treated as a nuisance
+	NuisanceDestinationsPerRider = 2
+
+	// DefaultBuildingMaxConcurrentRequests bounds the requests a building
+	// serves at once when its configuration does not
+	DefaultBuildingMaxConcurrentRequests = 50
+
 	// WebSocket update interval
 	StatusUpdateInterval = 1 * time.Second
 )
@@ -40,6 +52,16 @@ const (
 	ComponentElevator    = "elevator"
 	ComponentManager     = "manager"
 	ComponentDirections  = "directions"
+	ComponentMQTTBridge  = "mqtt-bridge"
+	ComponentBroadcaster = "status-broadcaster"
+	ComponentRegistry    = "building-registry"
+	ComponentCluster     = "cluster"
+	ComponentDrive       = "drive"
+	ComponentWebhook     = "webhook"
+	ComponentSLA         = "sla"
+	ComponentAnalytics   = "analytics"
+	ComponentForecast    = "forecast"
+	ComponentTelemetry   = "telemetry"
 )
 
 // Floor Validation Limits
@@ -52,6 +74,8 @@ const (
 const (
 	MetricsNamespace  = "elevator"
 	ElevatorNameLabel = "elevator"
+	BuildingLabel     = "building"
+	ClusterNodeLabel  = "node"
 )
 
 // Default Elevator Names
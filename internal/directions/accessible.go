@@ -0,0 +1,101 @@
+package directions
+
+import (
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+)
+
+// AppendCall adds a request with its call options: priority calls are
+// queued (see AppendPriority) and the stops of accessible calls are tracked
+// so the elevator can give those riders extra door time.
+//
+// ASSISTED STOPS:
+// ===============
+// Accessible requests are mirrored in separate up/down maps that follow the
+// same pickup → destination marker life cycle as the main maps:
+//
+//	AppendCall(UP, Floor(1), Floor(5), accessible) → assisted[1] = [5]
+//	Flush(UP, Floor(1))                            → assisted[5] = []
+//	Flush(UP, Floor(5))                            → assisted is empty
+//
+// NeedsAssistance reports whether the stop at a floor serves such a rider.
+func (d *Manager) AppendCall(direction domain.Direction, fromFloor, toFloor domain.Floor, call domain.CallOptions) {
+	d.AppendPriority(direction, fromFloor, toFloor, call.Priority)
+	if !call.Accessible {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if assisted := d.assisted(direction); assisted != nil {
+		assisted[fromFloor.Value()] = append(assisted[fromFloor.Value()], toFloor.Value())
+	}
+}
+
+// NeedsAssistance reports whether a rider with mobility needs boards or
+// leaves the car at the floor when it stops there in the given direction
+func (d *Manager) NeedsAssistance(direction domain.Direction, floor int) bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	_, exists := d.assisted(direction)[floor]
+	return exists
+}
+
+// assisted returns the assisted stops of a direction; callers must hold d.mu
+func (d *Manager) assisted(direction domain.Direction) map[int][]int {
+	switch direction {
+	case domain.DirectionUp:
+		return d.assistedUp
+	case domain.DirectionDown:
+		return d.assistedDown
+	default:
+		return nil
+	}
+}
+
+// flushAssisted turns the assisted pickups at a flushed floor into
+// destination markers; callers must hold d.mu
+func (d *Manager) flushAssisted(direction domain.Direction, floor int) {
+	assisted := d.assisted(direction)
+	if assisted == nil {
+		return
+	}
+	for _, to := range assisted[floor] {
+		if _, exists := assisted[to]; !exists {
+			assisted[to] = make([]int, 0)
+		}
+	}
+	delete(assisted, floor)
+}
+
+// removeAssisted drops an assisted pickup withdrawn by Remove once fewer
+// pickup entries than assisted ones remain, like removePriority. keepStop
+// keeps the floor as a stop as Remove does. Callers must hold d.mu.
+func (d *Manager) removeAssisted(direction domain.Direction, from, to int, pending []int, keepStop bool) {
+	assisted := d.assisted(direction)
+	entries := 0
+	for _, floor := range pending {
+		if floor == to {
+			entries++
+		}
+	}
+
+	requests := assisted[from]
+	last := -1
+	for i, floor := range requests {
+		if floor == to {
+			last = i
+			entries--
+		}
+	}
+	if last < 0 || entries >= 0 {
+		return
+	}
+
+	remaining := append(append(make([]int, 0, len(requests)-1), requests[:last]...), requests[last+1:]...)
+	if len(remaining) == 0 && !keepStop {
+		delete(assisted, from)
+	} else {
+		assisted[from] = remaining
+	}
+}
@@ -0,0 +1,45 @@
+package directions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+)
+
+func TestDirections_AppendCallTracksAssistedStops(t *testing.T) {
+	directions := New()
+	directions.AppendCall(domain.DirectionUp, domain.NewFloor(1), domain.NewFloor(5), domain.CallOptions{Accessible: true})
+	directions.AppendCall(domain.DirectionUp, domain.NewFloor(2), domain.NewFloor(4), domain.CallOptions{})
+
+	assert.Equal(t, []int{5}, directions.up[1])
+	assert.True(t, directions.NeedsAssistance(domain.DirectionUp, 1))
+	assert.False(t, directions.NeedsAssistance(domain.DirectionDown, 1))
+	assert.False(t, directions.NeedsAssistance(domain.DirectionUp, 2))
+
+	// Boarding moves the assistance to the destination
+	directions.Flush(domain.DirectionUp, domain.NewFloor(1))
+	assert.False(t, directions.NeedsAssistance(domain.DirectionUp, 1))
+	assert.True(t, directions.NeedsAssistance(domain.DirectionUp, 5))
+
+	directions.Flush(domain.DirectionUp, domain.NewFloor(2))
+	assert.False(t, directions.NeedsAssistance(domain.DirectionUp, 4))
+
+	directions.Flush(domain.DirectionUp, domain.NewFloor(5))
+	assert.False(t, directions.NeedsAssistance(domain.DirectionUp, 5))
+}
+
+func TestDirections_RemoveWithdrawsAssistedStop(t *testing.T) {
+	directions := New()
+	directions.AppendCall(domain.DirectionDown, domain.NewFloor(8), domain.NewFloor(2), domain.CallOptions{Accessible: true})
+	directions.AppendCall(domain.DirectionDown, domain.NewFloor(8), domain.NewFloor(2), domain.CallOptions{Accessible: true})
+
+	require.True(t, directions.Remove(domain.DirectionDown, domain.NewFloor(8), domain.NewFloor(2), false))
+	assert.True(t, directions.NeedsAssistance(domain.DirectionDown, 8))
+
+	require.True(t, directions.Remove(domain.DirectionDown, domain.NewFloor(8), domain.NewFloor(2), false))
+	assert.False(t, directions.NeedsAssistance(domain.DirectionDown, 8))
+	assert.True(t, directions.IsIdle())
+}
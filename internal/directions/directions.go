@@ -53,17 +53,26 @@ import (
 // This design ensures no request loss during concurrent operations and provides
 // accurate state management for real-world elevator systems where requests
 // continuously arrive while the elevator is in motion.
+//
+// Requests that have to be served ahead of ordinary traffic are also queued
+// by priority, see PriorityCall, and the stops of riders with mobility needs
+// are tracked separately, see AppendCall.
 type Manager struct {
-	up   map[int][]int
-	down map[int][]int
-	mu   sync.RWMutex
+	up           map[int][]int
+	down         map[int][]int
+	priority     []PriorityCall
+	assistedUp   map[int][]int
+	assistedDown map[int][]int
+	mu           sync.RWMutex
 }
 
 // New creates a new directions manager
 func New() *Manager {
 	return &Manager{
-		up:   make(map[int][]int),
-		down: make(map[int][]int),
+		up:           make(map[int][]int),
+		down:         make(map[int][]int),
+		assistedUp:   make(map[int][]int),
+		assistedDown: make(map[int][]int),
 	}
 }
 
@@ -131,15 +140,24 @@ func (d *Manager) Append(direction domain.Direction, fromFloor domain.Floor, toF
 // If new requests arrive while elevator is moving (e.g., map[1] = [8] added after flush),
 // they are preserved and won't be lost when destination floors are reached.
 // This ensures robust handling of real-world concurrent elevator requests.
-
-func (d *Manager) Flush(direction domain.Direction, currentFloor domain.Floor) {
+//
+// RETURN VALUE:
+// =============
+// Flush returns the destinations of the pickups it served, one entry per
+// request, so the caller knows how many riders boarded and where they are
+// headed. A stop that was only a destination marker returns nothing.
+func (d *Manager) Flush(direction domain.Direction, currentFloor domain.Floor) []int {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
 	current := currentFloor.Value()
+	d.flushPriority(direction, current)
+	d.flushAssisted(direction, current)
 
+	var boarded []int
 	if direction == domain.DirectionUp {
 		if len(d.up[current]) > 0 {
+			boarded = append(boarded, d.up[current]...)
 			for _, floor := range d.up[current] {
 				if _, exists := d.up[floor]; !exists {
 					d.up[floor] = make([]int, 0)
@@ -147,11 +165,12 @@ func (d *Manager) Flush(direction domain.Direction, currentFloor domain.Floor) {
 			}
 		}
 		delete(d.up, current)
-		return
+		return boarded
 	}
 
 	if direction == domain.DirectionDown {
 		if len(d.down[current]) > 0 {
+			boarded = append(boarded, d.down[current]...)
 			for _, floor := range d.down[current] {
 				if _, exists := d.down[floor]; !exists {
 					d.down[floor] = make([]int, 0)
@@ -161,6 +180,45 @@ func (d *Manager) Flush(direction domain.Direction, currentFloor domain.Floor) {
 
 		delete(d.down, current)
 	}
+	return boarded
+}
+
+// Remove withdraws one pending pickup request before it has been flushed.
+// It returns false if no such request is waiting at fromFloor.
+//
+// When the pickup floor has no requests left its key is deleted, unless
+// keepStop is set. Flush merges destination markers into existing pickup
+// keys, so callers that know a boarded passenger is headed to fromFloor must
+// keep the stop.
+func (d *Manager) Remove(direction domain.Direction, fromFloor, toFloor domain.Floor, keepStop bool) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	requests := d.up
+	if direction == domain.DirectionDown {
+		requests = d.down
+	} else if direction != domain.DirectionUp {
+		return false
+	}
+
+	from := fromFloor.Value()
+	to := toFloor.Value()
+	pending := requests[from]
+	for i, floor := range pending {
+		if floor != to {
+			continue
+		}
+		remaining := append(append(make([]int, 0, len(pending)-1), pending[:i]...), pending[i+1:]...)
+		if len(remaining) == 0 && !keepStop {
+			delete(requests, from)
+		} else {
+			requests[from] = remaining
+		}
+		d.removePriority(direction, from, to, remaining)
+		d.removeAssisted(direction, from, to, remaining, keepStop)
+		return true
+	}
+	return false
 }
 
 // UpDirectionLength returns the count of floors with active upward requests.
@@ -147,3 +147,27 @@ func TestValidateIntInMapSlice(t *testing.T) {
 	assert.False(t, isValueInMapSlice(m, 1, 4))
 	assert.False(t, isValueInMapSlice(m, 2, 3))
 }
+
+func TestDirections_Remove(t *testing.T) {
+	directions := New()
+	directions.Append(domain.DirectionUp, domain.NewFloor(1), domain.NewFloor(3))
+	directions.Append(domain.DirectionUp, domain.NewFloor(1), domain.NewFloor(5))
+	directions.Append(domain.DirectionDown, domain.NewFloor(6), domain.NewFloor(2))
+
+	// Removing one of several destinations keeps the pickup
+	assert.True(t, directions.Remove(domain.DirectionUp, domain.NewFloor(1), domain.NewFloor(3), false))
+	assert.Equal(t, []int{5}, directions.up[1])
+
+	// Removing the last destination drops the pickup floor
+	assert.True(t, directions.Remove(domain.DirectionUp, domain.NewFloor(1), domain.NewFloor(5), false))
+	assert.NotContains(t, directions.up, 1)
+
+	// Unknown or already flushed requests are not removed
+	assert.False(t, directions.Remove(domain.DirectionUp, domain.NewFloor(1), domain.NewFloor(5), false))
+	assert.False(t, directions.Remove(domain.DirectionUp, domain.NewFloor(6), domain.NewFloor(2), false))
+
+	// keepStop leaves an empty marker for passengers already on board
+	assert.True(t, directions.Remove(domain.DirectionDown, domain.NewFloor(6), domain.NewFloor(2), true))
+	assert.Contains(t, directions.down, 6)
+	assert.Empty(t, directions.down[6])
+}
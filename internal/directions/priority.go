@@ -0,0 +1,142 @@
+package directions
+
+import (
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+)
+
+// PriorityCall is a request that has to be served ahead of ordinary traffic.
+//
+// PRIORITY QUEUE:
+// ===============
+// Priority calls are kept in the up/down maps like every other request, so
+// the SCAN/LOOK bookkeeping and idle detection stay unchanged. The queue
+// only records which of those requests are urgent and in which order they
+// are served: higher priorities first, and calls of the same priority in
+// arrival order.
+//
+// Flush advances the queue together with the maps: a call whose pickup
+// floor is flushed is marked as boarded and its destination becomes the
+// target; a boarded call leaves the queue once its destination is flushed.
+type PriorityCall struct {
+	Direction domain.Direction
+	FromFloor domain.Floor
+	ToFloor   domain.Floor
+	Priority  domain.Priority
+	Boarded   bool
+}
+
+// Target returns the floor the elevator has to reach next for the call: the
+// pickup floor until the passengers boarded, then the destination
+func (c PriorityCall) Target() domain.Floor {
+	if c.Boarded {
+		return c.ToFloor
+	}
+	return c.FromFloor
+}
+
+// IsNonStop reports whether the elevator skips every other stop while it
+// serves the call
+func (c PriorityCall) IsNonStop() bool {
+	return c.Priority == domain.PriorityEmergency
+}
+
+// AppendPriority adds a request like Append and queues it by priority.
+// Requests of normal priority are not queued.
+//
+// EXAMPLE:
+// - AppendPriority(UP, Floor(2), Floor(8), high)      → queue: [2→8 high]
+// - AppendPriority(DOWN, Floor(9), Floor(0), emergency) → queue: [9→0 emergency, 2→8 high]
+func (d *Manager) AppendPriority(direction domain.Direction, fromFloor, toFloor domain.Floor, priority domain.Priority) {
+	d.Append(direction, fromFloor, toFloor)
+	if priority.Rank() == 0 {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	call := PriorityCall{Direction: direction, FromFloor: fromFloor, ToFloor: toFloor, Priority: priority}
+	i := len(d.priority)
+	for i > 0 && d.priority[i-1].Priority.Rank() < priority.Rank() {
+		i--
+	}
+	d.priority = append(d.priority, PriorityCall{})
+	copy(d.priority[i+1:], d.priority[i:])
+	d.priority[i] = call
+}
+
+// NextPriority returns the most urgent queued call
+func (d *Manager) NextPriority() (PriorityCall, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if len(d.priority) == 0 {
+		return PriorityCall{}, false
+	}
+	return d.priority[0], true
+}
+
+// PriorityCalls returns a copy of the queue, most urgent first
+func (d *Manager) PriorityCalls() []PriorityCall {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return append([]PriorityCall(nil), d.priority...)
+}
+
+// HasPriority reports whether any call of the given priority is queued
+func (d *Manager) HasPriority(priority domain.Priority) bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	for _, call := range d.priority {
+		if call.Priority == priority {
+			return true
+		}
+	}
+	return false
+}
+
+// flushPriority advances the queue for a flushed floor: boarded calls
+// headed to the floor are done, then calls waiting there board. Callers must
+// hold d.mu.
+func (d *Manager) flushPriority(direction domain.Direction, floor int) {
+	remaining := d.priority[:0]
+	for _, call := range d.priority {
+		if call.Direction == direction && call.Boarded && call.ToFloor.Value() == floor {
+			continue
+		}
+		remaining = append(remaining, call)
+	}
+	clear(d.priority[len(remaining):])
+	d.priority = remaining
+
+	for i := range d.priority {
+		call := &d.priority[i]
+		if call.Direction == direction && !call.Boarded && call.FromFloor.Value() == floor {
+			call.Boarded = true
+		}
+	}
+}
+
+// removePriority drops a waiting call withdrawn by Remove. Identical
+// requests share a pickup entry, so the least urgent matching call is only
+// dropped once fewer entries than queued calls remain. Callers must hold
+// d.mu.
+func (d *Manager) removePriority(direction domain.Direction, from, to int, pending []int) {
+	entries := 0
+	for _, floor := range pending {
+		if floor == to {
+			entries++
+		}
+	}
+
+	last := -1
+	queued := 0
+	for i, call := range d.priority {
+		if call.Direction == direction && !call.Boarded && call.FromFloor.Value() == from && call.ToFloor.Value() == to {
+			last = i
+			queued++
+		}
+	}
+	if queued > entries {
+		d.priority = append(d.priority[:last], d.priority[last+1:]...)
+	}
+}
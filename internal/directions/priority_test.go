@@ -0,0 +1,77 @@
+package directions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+)
+
+func TestDirections_AppendPriority(t *testing.T) {
+	directions := New()
+
+	directions.AppendPriority(domain.DirectionUp, domain.NewFloor(1), domain.NewFloor(4), domain.PriorityNormal)
+	directions.AppendPriority(domain.DirectionUp, domain.NewFloor(2), domain.NewFloor(8), domain.PriorityHigh)
+	directions.AppendPriority(domain.DirectionDown, domain.NewFloor(9), domain.NewFloor(0), domain.PriorityEmergency)
+	directions.AppendPriority(domain.DirectionUp, domain.NewFloor(3), domain.NewFloor(5), domain.PriorityHigh)
+
+	// Every request is kept in the direction maps
+	assert.Equal(t, []int{4}, directions.up[1])
+	assert.Equal(t, []int{8}, directions.up[2])
+	assert.Equal(t, []int{0}, directions.down[9])
+
+	// Normal requests are not queued; the rest are ordered by priority, then
+	// arrival
+	calls := directions.PriorityCalls()
+	require.Len(t, calls, 3)
+	assert.Equal(t, domain.PriorityEmergency, calls[0].Priority)
+	assert.Equal(t, 2, calls[1].FromFloor.Value())
+	assert.Equal(t, 3, calls[2].FromFloor.Value())
+	assert.True(t, directions.HasPriority(domain.PriorityEmergency))
+
+	next, ok := directions.NextPriority()
+	require.True(t, ok)
+	assert.Equal(t, 9, next.Target().Value())
+	assert.True(t, next.IsNonStop())
+}
+
+func TestDirections_FlushAdvancesPriorityCalls(t *testing.T) {
+	directions := New()
+	directions.AppendPriority(domain.DirectionUp, domain.NewFloor(2), domain.NewFloor(6), domain.PriorityHigh)
+
+	// A flush in the other direction does not board the call
+	directions.Flush(domain.DirectionDown, domain.NewFloor(2))
+	next, _ := directions.NextPriority()
+	assert.False(t, next.Boarded)
+
+	// Boarding makes the destination the target
+	directions.Flush(domain.DirectionUp, domain.NewFloor(2))
+	next, ok := directions.NextPriority()
+	require.True(t, ok)
+	assert.True(t, next.Boarded)
+	assert.Equal(t, 6, next.Target().Value())
+	assert.False(t, next.IsNonStop())
+
+	// Reaching the destination serves the call
+	directions.Flush(domain.DirectionUp, domain.NewFloor(6))
+	_, ok = directions.NextPriority()
+	assert.False(t, ok)
+	assert.True(t, directions.IsIdle())
+}
+
+func TestDirections_RemoveWithdrawsPriorityCall(t *testing.T) {
+	directions := New()
+	directions.AppendPriority(domain.DirectionUp, domain.NewFloor(2), domain.NewFloor(6), domain.PriorityNormal)
+	directions.AppendPriority(domain.DirectionUp, domain.NewFloor(2), domain.NewFloor(6), domain.PriorityEmergency)
+
+	// The identical ordinary request still needs the shared entry, so the
+	// first removal keeps the queued call
+	require.True(t, directions.Remove(domain.DirectionUp, domain.NewFloor(2), domain.NewFloor(6), false))
+	assert.True(t, directions.HasPriority(domain.PriorityEmergency))
+
+	require.True(t, directions.Remove(domain.DirectionUp, domain.NewFloor(2), domain.NewFloor(6), false))
+	assert.Empty(t, directions.PriorityCalls())
+	assert.True(t, directions.IsIdle())
+}
@@ -0,0 +1,83 @@
+package directions
+
+import (
+	"sort"
+
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+)
+
+// AppendStop adds a stop at a floor without a pickup/destination pair.
+//
+// A stop is a key with an empty slice, the same shape Flush leaves behind
+// as a destination marker, so the elevator serves it like any other stop in
+// that direction and Flush removes it. It covers the two calls whose
+// destination is not part of the request:
+//
+// - Car call: a rider already in the car presses a floor button; the stop
+// is a destination marker in the direction of that floor.
+// - Hall call with a direction only: a rider presses the up or down button;
+// the stop is a pickup whose destination arrives later as a car call.
+//
+// EXAMPLE:
+// - AppendStop(UP, Floor(7)) → map[7] = []
+// - AppendStop(UP, Floor(3)) while map[3] = [9] → map[3] = [9] (unchanged)
+func (d *Manager) AppendStop(direction domain.Direction, floor domain.Floor) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	requests := d.up
+	if direction == domain.DirectionDown {
+		requests = d.down
+	} else if direction != domain.DirectionUp {
+		return
+	}
+
+	if _, exists := requests[floor.Value()]; !exists {
+		requests[floor.Value()] = make([]int, 0)
+	}
+}
+
+// Markers returns the floors that are stops in a direction without any
+// pickup waiting there: destination markers, car calls and direction-only
+// hall calls
+func (d *Manager) Markers(direction domain.Direction) []int {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	requests := d.up
+	if direction == domain.DirectionDown {
+		requests = d.down
+	} else if direction != domain.DirectionUp {
+		return nil
+	}
+
+	var floors []int
+	for floor, pending := range requests {
+		if len(pending) == 0 {
+			floors = append(floors, floor)
+		}
+	}
+	sort.Ints(floors)
+	return floors
+}
+
+// RemoveStop withdraws a stop added by AppendStop or left by Flush. A floor
+// where pickups are waiting is kept, and false is returned.
+func (d *Manager) RemoveStop(direction domain.Direction, floor domain.Floor) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	requests := d.up
+	if direction == domain.DirectionDown {
+		requests = d.down
+	} else if direction != domain.DirectionUp {
+		return false
+	}
+
+	pending, exists := requests[floor.Value()]
+	if !exists || len(pending) > 0 {
+		return false
+	}
+	delete(requests, floor.Value())
+	return true
+}
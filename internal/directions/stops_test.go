@@ -0,0 +1,53 @@
+package directions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+)
+
+func TestDirections_AppendStop(t *testing.T) {
+	directions := New()
+
+	directions.AppendStop(domain.DirectionUp, domain.NewFloor(7))
+	assert.Equal(t, []int{}, directions.up[7])
+	assert.True(t, directions.HasUpFloor(7))
+
+	// An existing pickup keeps its destinations
+	directions.Append(domain.DirectionDown, domain.NewFloor(3), domain.NewFloor(0))
+	directions.AppendStop(domain.DirectionDown, domain.NewFloor(3))
+	assert.Equal(t, []int{0}, directions.down[3])
+
+	directions.AppendStop(domain.DirectionIdle, domain.NewFloor(5))
+	assert.False(t, directions.HasUpFloor(5))
+	assert.False(t, directions.HasDownFloor(5))
+
+	// Flushing a stop serves it without adding destinations
+	directions.Flush(domain.DirectionUp, domain.NewFloor(7))
+	assert.False(t, directions.HasUpRequests())
+}
+
+func TestDirections_MarkersAndRemoveStop(t *testing.T) {
+	directions := New()
+
+	directions.Append(domain.DirectionUp, domain.NewFloor(1), domain.NewFloor(4))
+	directions.AppendStop(domain.DirectionUp, domain.NewFloor(6))
+	assert.Equal(t, []int{6}, directions.Markers(domain.DirectionUp))
+
+	// Boarding returns the destinations and leaves their markers
+	assert.Equal(t, []int{4}, directions.Flush(domain.DirectionUp, domain.NewFloor(1)))
+	assert.Equal(t, []int{4, 6}, directions.Markers(domain.DirectionUp))
+	assert.Empty(t, directions.Flush(domain.DirectionUp, domain.NewFloor(4)))
+
+	// A floor with waiting pickups is not a marker and is kept
+	directions.Append(domain.DirectionDown, domain.NewFloor(8), domain.NewFloor(2))
+	assert.Empty(t, directions.Markers(domain.DirectionDown))
+	assert.False(t, directions.RemoveStop(domain.DirectionDown, domain.NewFloor(8)))
+	assert.False(t, directions.RemoveStop(domain.DirectionDown, domain.NewFloor(5)))
+
+	assert.True(t, directions.RemoveStop(domain.DirectionUp, domain.NewFloor(6)))
+	assert.False(t, directions.HasUpRequests())
+	assert.True(t, directions.HasDownFloor(8))
+}
@@ -2,13 +2,20 @@ package domain
 
 // ElevatorStatus represents the current status of an elevator
 type ElevatorStatus struct {
-	Name         string    `json:"name"`
-	CurrentFloor Floor     `json:"current_floor"`
-	Direction    Direction `json:"direction"`
-	Requests     int       `json:"requests"`
-	MinFloor     Floor     `json:"min_floor"`
-	MaxFloor     Floor     `json:"max_floor"`
-	IsDeleting   bool      `json:"is_deleting"`
+	Name         string       `json:"name"`
+	Type         ElevatorType `json:"type,omitempty"`
+	CurrentFloor Floor        `json:"current_floor"`
+	Direction    Direction    `json:"direction"`
+	Requests     int          `json:"requests"`
+	MinFloor     Floor        `json:"min_floor"`
+	MaxFloor     Floor        `json:"max_floor"`
+	IsDeleting   bool         `json:"is_deleting"`
+	DoorOpen     bool         `json:"door_open"`
+	// Position is the car's position in floors, between floors while it
+	// travels; Velocity is in floors per second, negative going down. Both
+	// stay at the current floor and zero for cars without a motion profile.
+	Position float64 `json:"position"`
+	Velocity float64 `json:"velocity"`
 }
 
 // NewElevatorStatus creates a new elevator status
@@ -21,6 +28,7 @@ func NewElevatorStatus(name string, currentFloor Floor, direction Direction, req
 		MinFloor:     minFloor,
 		MaxFloor:     maxFloor,
 		IsDeleting:   direction == DirectionDeleting,
+		Position:     float64(currentFloor.Value()),
 	}
 }
 
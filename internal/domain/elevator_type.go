@@ -0,0 +1,73 @@
+package domain
+
+// ElevatorType is the kind of car, which decides the calls it may serve
+type ElevatorType string
+
+const (
+	// ElevatorTypePassenger serves ordinary calls
+	ElevatorTypePassenger ElevatorType = "passenger"
+	// ElevatorTypeFreight is reserved for cargo calls
+	ElevatorTypeFreight ElevatorType = "freight"
+	// ElevatorTypeService is reserved for staff-authenticated calls
+	ElevatorTypeService ElevatorType = "service"
+)
+
+// ElevatorTypes lists every elevator type
+var ElevatorTypes = []ElevatorType{ElevatorTypePassenger, ElevatorTypeFreight, ElevatorTypeService}
+
+// IsValid checks if the elevator type is known
+func (t ElevatorType) IsValid() bool {
+	switch t {
+	case ElevatorTypePassenger, ElevatorTypeFreight, ElevatorTypeService:
+		return true
+	default:
+		return false
+	}
+}
+
+// ParseElevatorType parses an elevator type; an empty value is a passenger
+// car
+func ParseElevatorType(value string) (ElevatorType, error) {
+	if value == "" {
+		return ElevatorTypePassenger, nil
+	}
+	t := ElevatorType(value)
+	if !t.IsValid() {
+		return t, NewValidationError("unknown elevator type", nil).
+			WithContext("type", value)
+	}
+	return t, nil
+}
+
+// CallOptions describe what a floor request needs from the car serving it
+type CallOptions struct {
+	// Cargo calls are served by freight cars, or by service cars for staff
+	Cargo bool `json:"cargo,omitempty"`
+	// Staff calls were authenticated as coming from building staff and may
+	// use service cars
+	Staff bool `json:"staff,omitempty"`
+	// Priority calls are served ahead of ordinary traffic
+	Priority Priority `json:"priority,omitempty"`
+	// Accessible calls are for riders with mobility needs: they prefer
+	// lightly loaded cars, get extra door time and are announced
+	Accessible bool `json:"accessible,omitempty"`
+}
+
+// IsPriority reports whether the call is more urgent than ordinary traffic
+func (c CallOptions) IsPriority() bool {
+	return c.Priority.Rank() > 0
+}
+
+// Serves reports whether a car of this type may serve a call. Freight cars
+// only take cargo calls and service cars only staff calls; passenger cars
+// take every call that is not cargo.
+func (t ElevatorType) Serves(call CallOptions) bool {
+	switch t {
+	case ElevatorTypeFreight:
+		return call.Cargo
+	case ElevatorTypeService:
+		return call.Staff
+	default:
+		return !call.Cargo
+	}
+}
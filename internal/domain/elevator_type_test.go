@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseElevatorType(t *testing.T) {
+	elevatorType, err := ParseElevatorType("")
+	require.NoError(t, err)
+	assert.Equal(t, ElevatorTypePassenger, elevatorType)
+
+	elevatorType, err = ParseElevatorType("freight")
+	require.NoError(t, err)
+	assert.Equal(t, ElevatorTypeFreight, elevatorType)
+
+	_, err = ParseElevatorType("dumbwaiter")
+	require.Error(t, err)
+	assert.Equal(t, ErrTypeValidation, err.(*DomainError).Type)
+}
+
+func TestElevatorType_Serves(t *testing.T) {
+	tests := []struct {
+		call      CallOptions
+		passenger bool
+		freight   bool
+		service   bool
+	}{
+		{CallOptions{}, true, false, false},
+		{CallOptions{Cargo: true}, false, true, false},
+		{CallOptions{Staff: true}, true, false, true},
+		{CallOptions{Cargo: true, Staff: true}, false, true, true},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.passenger, ElevatorTypePassenger.Serves(tt.call), "passenger %+v", tt.call)
+		assert.Equal(t, tt.freight, ElevatorTypeFreight.Serves(tt.call), "freight %+v", tt.call)
+		assert.Equal(t, tt.service, ElevatorTypeService.Serves(tt.call), "service %+v", tt.call)
+	}
+}
@@ -0,0 +1,117 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FaultType identifies a simulated hardware failure
+type FaultType string
+
+const (
+	// FaultStuckBetweenFloors stops the car from reaching the next floor
+	FaultStuckBetweenFloors FaultType = "stuck_between_floors"
+	// FaultDoorFailure makes the door cycle at a stop fail
+	FaultDoorFailure FaultType = "door_failure"
+	// FaultPositionLoss makes the car lose track of its floor
+	FaultPositionLoss FaultType = "position_loss"
+	// FaultSlowTravel stretches the travel time between floors
+	FaultSlowTravel FaultType = "slow_travel"
+)
+
+// DefaultSlowdownFactor is used for slow travel faults without an explicit factor
+const DefaultSlowdownFactor = 3.0
+
+// IsValid checks if the fault type is known
+func (t FaultType) IsValid() bool {
+	switch t {
+	case FaultStuckBetweenFloors, FaultDoorFailure, FaultPositionLoss, FaultSlowTravel:
+		return true
+	default:
+		return false
+	}
+}
+
+// Fault describes a failure injected into an elevator
+type Fault struct {
+	Type FaultType `json:"type"`
+	// SlowdownFactor multiplies the floor travel time of slow travel faults
+	SlowdownFactor float64 `json:"slowdown_factor,omitempty"`
+	// Occurrences limits how many operations the fault affects; zero keeps
+	// it active until cleared
+	Occurrences int `json:"occurrences,omitempty"`
+}
+
+// Normalize applies defaults and validates the fault
+func (f Fault) Normalize() (Fault, error) {
+	if !f.Type.IsValid() {
+		return f, NewValidationError("unknown fault type", nil).
+			WithContext("type", string(f.Type))
+	}
+
+	if f.Occurrences < 0 {
+		return f, NewValidationError("fault occurrences cannot be negative", nil).
+			WithContext("occurrences", f.Occurrences)
+	}
+
+	if f.Type != FaultSlowTravel {
+		if f.SlowdownFactor != 0 {
+			return f, NewValidationError("slowdown factor only applies to slow travel faults", nil).
+				WithContext("type", string(f.Type))
+		}
+		return f, nil
+	}
+
+	if f.SlowdownFactor == 0 {
+		f.SlowdownFactor = DefaultSlowdownFactor
+	}
+	if f.SlowdownFactor <= 1 {
+		return f, NewValidationError("slowdown factor must be greater than 1", nil).
+			WithContext("slowdown_factor", f.SlowdownFactor)
+	}
+	return f, nil
+}
+
+// ParseFaultInjections parses a fault injection spec of the form
+// "A=stuck_between_floors;B=slow_travel:2.5,door_failure" into the faults
+// of each elevator. A number after a colon is the slowdown factor.
+func ParseFaultInjections(spec string) (map[string][]Fault, error) {
+	faults := make(map[string][]Fault)
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, list, ok := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, NewValidationError("fault injection must have the form name=fault", nil).
+				WithContext("entry", entry)
+		}
+
+		for _, item := range strings.Split(list, ",") {
+			fault, err := parseFault(strings.TrimSpace(item))
+			if err != nil {
+				return nil, err
+			}
+			faults[name] = append(faults[name], fault)
+		}
+	}
+	return faults, nil
+}
+
+func parseFault(item string) (Fault, error) {
+	faultType, factor, hasFactor := strings.Cut(item, ":")
+	fault := Fault{Type: FaultType(strings.TrimSpace(faultType))}
+	if hasFactor {
+		value, err := strconv.ParseFloat(strings.TrimSpace(factor), 64)
+		if err != nil {
+			return fault, NewValidationError(fmt.Sprintf("invalid slowdown factor %q", factor), err).
+				WithContext("fault", item)
+		}
+		fault.SlowdownFactor = value
+	}
+	return fault.Normalize()
+}
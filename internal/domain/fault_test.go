@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFaultInjections(t *testing.T) {
+	faults, err := ParseFaultInjections(" A=stuck_between_floors ; B=slow_travel:2.5, door_failure;C=slow_travel;")
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string][]Fault{
+		"A": {{Type: FaultStuckBetweenFloors}},
+		"B": {{Type: FaultSlowTravel, SlowdownFactor: 2.5}, {Type: FaultDoorFailure}},
+		"C": {{Type: FaultSlowTravel, SlowdownFactor: DefaultSlowdownFactor}},
+	}, faults)
+}
+
+func TestParseFaultInjections_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+		want string
+	}{
+		{name: "missing elevator", spec: "=door_failure", want: "fault injection must have the form name=fault"},
+		{name: "unknown fault", spec: "A=meteor_strike", want: "unknown fault type"},
+		{name: "bad factor", spec: "A=slow_travel:fast", want: "invalid slowdown factor"},
+		{name: "factor too small", spec: "A=slow_travel:0.5", want: "slowdown factor must be greater than 1"},
+		{name: "factor on other fault", spec: "A=position_loss:2", want: "slowdown factor only applies to slow travel faults"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseFaultInjections(tt.spec)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
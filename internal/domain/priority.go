@@ -0,0 +1,60 @@
+package domain
+
+// Priority is the urgency of a floor request
+type Priority string
+
+const (
+	// PriorityNormal is ordinary traffic; the zero value means the same
+	PriorityNormal Priority = "normal"
+	// PriorityHigh calls (VIP guests) are served ahead of ordinary traffic
+	PriorityHigh Priority = "high"
+	// PriorityEmergency calls (stretchers, emergency staff) reserve a car
+	// that runs non-stop to the caller and the destination
+	PriorityEmergency Priority = "emergency"
+)
+
+// Priorities lists every priority from lowest to highest
+var Priorities = []Priority{PriorityNormal, PriorityHigh, PriorityEmergency}
+
+// IsValid checks if the priority is known; the zero value is normal
+func (p Priority) IsValid() bool {
+	switch p {
+	case "", PriorityNormal, PriorityHigh, PriorityEmergency:
+		return true
+	default:
+		return false
+	}
+}
+
+// Rank orders priorities: higher ranks are served first
+func (p Priority) Rank() int {
+	switch p {
+	case PriorityHigh:
+		return 1
+	case PriorityEmergency:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// String returns the priority name, reporting the zero value as normal
+func (p Priority) String() string {
+	if p == "" {
+		return string(PriorityNormal)
+	}
+	return string(p)
+}
+
+// ParsePriority parses a floor request priority; an empty value is normal
+func ParsePriority(value string) (Priority, error) {
+	if value == "" {
+		return PriorityNormal, nil
+	}
+	p := Priority(value)
+	if !p.IsValid() {
+		return p, NewValidationError("unknown priority", nil).
+			WithContext("priority", value)
+	}
+	return p, nil
+}
@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePriority(t *testing.T) {
+	priority, err := ParsePriority("")
+	require.NoError(t, err)
+	assert.Equal(t, PriorityNormal, priority)
+
+	priority, err = ParsePriority("emergency")
+	require.NoError(t, err)
+	assert.Equal(t, PriorityEmergency, priority)
+
+	_, err = ParsePriority("urgent")
+	require.Error(t, err)
+	assert.Equal(t, ErrTypeValidation, err.(*DomainError).Type)
+}
+
+func TestPriority_Rank(t *testing.T) {
+	assert.Equal(t, 0, Priority("").Rank())
+	assert.Less(t, PriorityNormal.Rank(), PriorityHigh.Rank())
+	assert.Less(t, PriorityHigh.Rank(), PriorityEmergency.Rank())
+	assert.Equal(t, "normal", Priority("").String())
+
+	assert.False(t, CallOptions{}.IsPriority())
+	assert.False(t, CallOptions{Priority: PriorityNormal}.IsPriority())
+	assert.True(t, CallOptions{Priority: PriorityHigh}.IsPriority())
+}
@@ -0,0 +1,246 @@
+// Package drive connects elevator cars to hardware.
+//
+// Line drives a car through a PLC, or an emulator of one, speaking a plain
+// text protocol over TCP or a serial port. Every command is a single line
+// naming the car and every reply is a single line:
+//
+//	MOVE <car> <floor>  -> OK once the car has arrived at the adjacent floor
+//	OPEN <car>          -> OK once the doors are open
+//	CLOSE <car>         -> OK once the doors are closed
+//	POS <car>           -> OK <floor>
+//
+// A device that cannot carry a command out replies ERR followed by the
+// reason. Each car uses its own connection, and commands on a connection are
+// sent one at a time.
+package drive
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/slavakukuyev/elevator-go/internal/constants"
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+	"github.com/slavakukuyev/elevator-go/metrics"
+)
+
+const (
+	commandMove     = "MOVE"
+	commandOpen     = "OPEN"
+	commandClose    = "CLOSE"
+	commandPosition = "POS"
+
+	replyOK    = "OK"
+	replyError = "ERR"
+)
+
+// Line drives a car over the line protocol. A connection that fails or
+// times out is dropped, since a late reply would be taken for the reply to
+// the next command, and dialed again on the next command.
+type Line struct {
+	address *url.URL
+	car     string
+	timeout time.Duration
+	logger  *slog.Logger
+
+	mu     sync.Mutex
+	conn   io.ReadWriteCloser
+	reader *bufio.Reader
+	closed bool
+}
+
+// Dial connects to the device at address, tcp://host:port or
+// serial:///dev/ttyUSB0, to drive the named car. timeout bounds the wait for
+// each reply; a serial port has to be set up (baud rate, framing) beforehand.
+func Dial(ctx context.Context, address, car string, timeout time.Duration) (*Line, error) {
+	if car == "" || strings.ContainsAny(car, " \t\r\n") {
+		return nil, domain.NewValidationError("elevator name cannot be sent over the line protocol", nil).
+			WithContext("elevator", car)
+	}
+	parsed, err := url.Parse(address)
+	if err != nil {
+		return nil, domain.NewValidationError("invalid drive address", err).
+			WithContext("address", address)
+	}
+	if parsed.Scheme != "tcp" && parsed.Scheme != "serial" {
+		return nil, domain.NewValidationError("drive address must use the tcp or serial scheme", nil).
+			WithContext("address", address)
+	}
+
+	l := &Line{
+		address: parsed,
+		car:     car,
+		timeout: timeout,
+		logger: slog.With(
+			slog.String("component", constants.ComponentDrive),
+			slog.String("elevator", car)),
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.connect(ctx); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// MoveTo moves the car to an adjacent floor
+func (l *Line) MoveTo(ctx context.Context, floor domain.Floor) error {
+	_, err := l.command(ctx, commandMove, strconv.Itoa(floor.Value()))
+	return err
+}
+
+// OpenDoor opens the car's doors
+func (l *Line) OpenDoor(ctx context.Context) error {
+	_, err := l.command(ctx, commandOpen)
+	return err
+}
+
+// CloseDoor closes the car's doors
+func (l *Line) CloseDoor(ctx context.Context) error {
+	_, err := l.command(ctx, commandClose)
+	return err
+}
+
+// Position reports the floor the car stands at
+func (l *Line) Position(ctx context.Context) (domain.Floor, error) {
+	value, err := l.command(ctx, commandPosition)
+	if err != nil {
+		return 0, err
+	}
+	floor, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, domain.NewExternalError("drive reported an invalid position", err).
+			WithContext("elevator", l.car).
+			WithContext("position", value)
+	}
+	return domain.NewFloor(floor), nil
+}
+
+// Close closes the connection to the device. It is safe to call more than
+// once.
+func (l *Line) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.closed = true
+	return l.disconnect()
+}
+
+// command sends a command for the car and returns the value of its OK reply
+func (l *Line) command(ctx context.Context, verb string, args ...string) (string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return "", domain.NewConflictError("drive is closed", nil).
+			WithContext("elevator", l.car)
+	}
+	if l.conn == nil {
+		if err := l.connect(ctx); err != nil {
+			return "", err
+		}
+	}
+
+	line := strings.Join(append([]string{verb, l.car}, args...), " ")
+	reply, err := l.exchange(ctx, line)
+	if err != nil {
+		// The connection is out of step with the device now
+		_ = l.disconnect()
+		metrics.IncError("command_error", constants.ComponentDrive)
+		return "", domain.NewExternalError("drive command failed", err).
+			WithContext("elevator", l.car).
+			WithContext("command", line)
+	}
+
+	status, value, _ := strings.Cut(reply, " ")
+	switch status {
+	case replyOK:
+		return value, nil
+	case replyError:
+		metrics.IncError("command_rejected", constants.ComponentDrive)
+		return "", domain.NewExternalError("drive rejected command: "+value, nil).
+			WithContext("elevator", l.car).
+			WithContext("command", line)
+	default:
+		_ = l.disconnect()
+		metrics.IncError("invalid_reply", constants.ComponentDrive)
+		return "", domain.NewExternalError("unexpected reply from drive", nil).
+			WithContext("elevator", l.car).
+			WithContext("command", line).
+			WithContext("reply", reply)
+	}
+}
+
+// exchange writes a line and reads the reply, giving up when ctx is done or
+// the command timeout passes
+func (l *Line) exchange(ctx context.Context, line string) (string, error) {
+	deadline := time.Now().Add(l.timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	if conn, ok := l.conn.(interface{ SetDeadline(time.Time) error }); ok {
+		_ = conn.SetDeadline(deadline)
+		// Cancelling ctx unblocks the exchange by moving the deadline up
+		stop := context.AfterFunc(ctx, func() { _ = conn.SetDeadline(time.Now()) })
+		defer stop()
+	}
+
+	if _, err := io.WriteString(l.conn, line+"\n"); err != nil {
+		return "", err
+	}
+	reply, err := l.reader.ReadString('\n')
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		return "", err
+	}
+	return strings.TrimSpace(reply), nil
+}
+
+// connect opens the connection to the device
+func (l *Line) connect(ctx context.Context) error {
+	var conn io.ReadWriteCloser
+	var err error
+	switch l.address.Scheme {
+	case "tcp":
+		dialer := net.Dialer{Timeout: l.timeout}
+		conn, err = dialer.DialContext(ctx, "tcp", l.address.Host)
+	case "serial":
+		conn, err = os.OpenFile(l.address.Path, os.O_RDWR, 0)
+	}
+	if err != nil {
+		return domain.NewExternalError("failed to connect to drive", err).
+			WithContext("elevator", l.car).
+			WithContext("address", l.address.String())
+	}
+
+	l.conn = conn
+	l.reader = bufio.NewReader(conn)
+	l.logger.Info("connected to drive", slog.String("address", l.address.String()))
+	return nil
+}
+
+// disconnect drops the connection, if any
+func (l *Line) disconnect() error {
+	if l.conn == nil {
+		return nil
+	}
+	err := l.conn.Close()
+	l.conn = nil
+	l.reader = nil
+	if err != nil {
+		return fmt.Errorf("failed to close drive connection: %w", err)
+	}
+	return nil
+}
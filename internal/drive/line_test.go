@@ -0,0 +1,255 @@
+package drive
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+)
+
+// fakeDevice emulates a PLC speaking the line protocol over TCP
+type fakeDevice struct {
+	listener net.Listener
+
+	mu       sync.Mutex
+	floors   map[string]int
+	doors    map[string]bool
+	commands []string
+	reject   string        // Commands starting with it get an ERR reply
+	silent   string        // Commands starting with it get no reply
+	delay    time.Duration // Wait before every reply
+	conns    int
+}
+
+func newFakeDevice(t *testing.T) *fakeDevice {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	d := &fakeDevice{
+		listener: listener,
+		floors:   make(map[string]int),
+		doors:    make(map[string]bool),
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+	go d.serve()
+	return d
+}
+
+func (d *fakeDevice) address() string {
+	return "tcp://" + d.listener.Addr().String()
+}
+
+func (d *fakeDevice) serve() {
+	for {
+		conn, err := d.listener.Accept()
+		if err != nil {
+			return
+		}
+		d.mu.Lock()
+		d.conns++
+		d.mu.Unlock()
+		go d.handle(conn)
+	}
+}
+
+func (d *fakeDevice) handle(conn net.Conn) {
+	defer conn.Close()
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		reply, ok := d.reply(scanner.Text())
+		if !ok {
+			continue
+		}
+		if _, err := fmt.Fprintln(conn, reply); err != nil {
+			return
+		}
+	}
+}
+
+func (d *fakeDevice) reply(line string) (string, bool) {
+	d.mu.Lock()
+	d.commands = append(d.commands, line)
+	delay, silent, reject := d.delay, d.silent, d.reject
+	d.mu.Unlock()
+
+	time.Sleep(delay)
+	if silent != "" && strings.HasPrefix(line, silent) {
+		return "", false
+	}
+	if reject != "" && strings.HasPrefix(line, reject) {
+		return "ERR door obstructed", true
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	fields := strings.Fields(line)
+	if len(fields) < 2 {
+		return "ERR malformed command", true
+	}
+	car := fields[1]
+	switch fields[0] {
+	case commandMove:
+		floor, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return "ERR invalid floor", true
+		}
+		d.floors[car] = floor
+	case commandOpen:
+		d.doors[car] = true
+	case commandClose:
+		d.doors[car] = false
+	case commandPosition:
+		return fmt.Sprintf("OK %d", d.floors[car]), true
+	default:
+		return "ERR unknown command", true
+	}
+	return "OK", true
+}
+
+func (d *fakeDevice) set(update func(d *fakeDevice)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	update(d)
+}
+
+func (d *fakeDevice) received() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.commands...)
+}
+
+func (d *fakeDevice) connections() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.conns
+}
+
+func TestLine_Commands(t *testing.T) {
+	device := newFakeDevice(t)
+	device.set(func(d *fakeDevice) { d.floors["Elevator-1"] = 3 })
+	ctx := context.Background()
+
+	line, err := Dial(ctx, device.address(), "Elevator-1", time.Second)
+	require.NoError(t, err)
+	defer line.Close()
+
+	floor, err := line.Position(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, domain.NewFloor(3), floor)
+
+	require.NoError(t, line.MoveTo(ctx, domain.NewFloor(4)))
+	require.NoError(t, line.OpenDoor(ctx))
+	require.NoError(t, line.CloseDoor(ctx))
+
+	floor, err = line.Position(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, domain.NewFloor(4), floor)
+	assert.Equal(t, []string{
+		"POS Elevator-1",
+		"MOVE Elevator-1 4",
+		"OPEN Elevator-1",
+		"CLOSE Elevator-1",
+		"POS Elevator-1",
+	}, device.received())
+}
+
+func TestLine_RejectedCommand(t *testing.T) {
+	device := newFakeDevice(t)
+	device.set(func(d *fakeDevice) { d.reject = commandOpen })
+	ctx := context.Background()
+
+	line, err := Dial(ctx, device.address(), "Elevator-1", time.Second)
+	require.NoError(t, err)
+	defer line.Close()
+
+	err = line.OpenDoor(ctx)
+	require.Error(t, err)
+	domainErr, ok := err.(*domain.DomainError)
+	require.True(t, ok)
+	assert.Equal(t, domain.ErrTypeExternal, domainErr.Type)
+	assert.Contains(t, domainErr.Message, "door obstructed")
+
+	// A rejected command leaves the connection usable
+	require.NoError(t, line.CloseDoor(ctx))
+	assert.Equal(t, 1, device.connections())
+}
+
+func TestLine_TimeoutReconnects(t *testing.T) {
+	device := newFakeDevice(t)
+	device.set(func(d *fakeDevice) { d.silent = commandMove })
+	ctx := context.Background()
+
+	line, err := Dial(ctx, device.address(), "Elevator-1", 50*time.Millisecond)
+	require.NoError(t, err)
+	defer line.Close()
+
+	start := time.Now()
+	err = line.MoveTo(ctx, domain.NewFloor(1))
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), time.Second)
+
+	// The next command runs on a new connection
+	device.set(func(d *fakeDevice) { d.silent = "" })
+	require.NoError(t, line.MoveTo(ctx, domain.NewFloor(1)))
+	assert.Equal(t, 2, device.connections())
+}
+
+func TestLine_ContextCancelsCommand(t *testing.T) {
+	device := newFakeDevice(t)
+	device.set(func(d *fakeDevice) { d.delay = time.Second })
+
+	line, err := Dial(context.Background(), device.address(), "Elevator-1", 5*time.Second)
+	require.NoError(t, err)
+	defer line.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	start := time.Now()
+	err = line.MoveTo(ctx, domain.NewFloor(1))
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+}
+
+func TestLine_Close(t *testing.T) {
+	device := newFakeDevice(t)
+	ctx := context.Background()
+
+	line, err := Dial(ctx, device.address(), "Elevator-1", time.Second)
+	require.NoError(t, err)
+
+	require.NoError(t, line.Close())
+	require.NoError(t, line.Close())
+	assert.Error(t, line.MoveTo(ctx, domain.NewFloor(1)))
+}
+
+func TestDial_Invalid(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := Dial(ctx, "udp://127.0.0.1:9", "Elevator-1", time.Second)
+	assert.Error(t, err)
+
+	_, err = Dial(ctx, "tcp://127.0.0.1:9", "Car 1", time.Second)
+	assert.Error(t, err)
+
+	// Nothing listens on the port
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := "tcp://" + listener.Addr().String()
+	require.NoError(t, listener.Close())
+	_, err = Dial(ctx, address, "Elevator-1", time.Second)
+	require.Error(t, err)
+	assert.Equal(t, domain.ErrTypeExternal, err.(*domain.DomainError).Type)
+}
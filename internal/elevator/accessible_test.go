@@ -0,0 +1,73 @@
+package elevator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+	"github.com/slavakukuyev/elevator-go/internal/events"
+)
+
+func TestElevator_AccessibleCallExtendsDwellAndAnnounces(t *testing.T) {
+	e, err := New("Kiosk", 0, 9, 10*time.Millisecond, 40*time.Millisecond, time.Second,
+		2, time.Second, 1, 12)
+	require.NoError(t, err)
+	t.Cleanup(e.Shutdown)
+
+	bus := events.NewBus(100)
+	e.SetEventBus(bus)
+	sub := bus.Subscribe(100)
+	defer sub.Close()
+
+	e.RequestCall(domain.DirectionUp, domain.NewFloor(1), domain.NewFloor(3), domain.CallOptions{Accessible: true})
+
+	var announcements []map[string]any
+	var pickup, destination time.Time
+	deadline := time.After(3 * time.Second)
+	for len(announcements) < 4 {
+		select {
+		case event := <-sub.C:
+			switch event.Type {
+			case events.TypeFloorArrived:
+				if pickup.IsZero() {
+					pickup = event.Timestamp
+				} else if destination.IsZero() {
+					destination = event.Timestamp
+				}
+			case events.TypeAnnouncement:
+				announcements = append(announcements, event.Data)
+			}
+		case <-deadline:
+			t.Fatalf("missing announcements, got %v", announcements)
+		}
+	}
+
+	// The doors stayed open three times longer at the pickup floor, so the
+	// car reached the destination two floors up after at least that long
+	assert.GreaterOrEqual(t, destination.Sub(pickup), 120*time.Millisecond)
+
+	assert.Equal(t, AnnouncementArrival, announcements[0]["announcement"])
+	assert.Equal(t, "Floor 1", announcements[0]["message"])
+	assert.Equal(t, AnnouncementDirection, announcements[1]["announcement"])
+	assert.Equal(t, "Going up", announcements[1]["message"])
+	assert.Equal(t, "Floor 3", announcements[2]["message"])
+	assert.Equal(t, 3, announcements[3]["floor"])
+}
+
+func TestElevator_OrdinaryCallIsNotAnnounced(t *testing.T) {
+	e := newFaultTestElevator(t, time.Second)
+	bus := events.NewBus(100)
+	e.SetEventBus(bus)
+	sub := bus.Subscribe(100)
+	defer sub.Close()
+
+	e.Request(domain.DirectionUp, domain.NewFloor(1), domain.NewFloor(2))
+	require.Eventually(t, func() bool { return !e.HasPendingRequests() }, 2*time.Second, 10*time.Millisecond)
+
+	for len(sub.C) > 0 {
+		assert.NotEqual(t, events.TypeAnnouncement, (<-sub.C).Type)
+	}
+}
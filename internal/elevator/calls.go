@@ -0,0 +1,84 @@
+package elevator
+
+import (
+	"log/slog"
+
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+)
+
+// CarCall registers a floor button pressed by a rider inside the car. The
+// stop is added in the direction of the floor as seen from the car's current
+// position, and that direction is returned.
+func (e *Elevator) CarCall(floor domain.Floor) (domain.Direction, error) {
+	direction, err := e.directionTo(floor)
+	if err != nil {
+		return "", err
+	}
+
+	e.wake(direction, floor)
+	e.load.carCall(direction, floor.Value())
+	e.directionsManager.AppendStop(direction, floor)
+	e.notifyChange()
+	e.logger.Info("car call received",
+		slog.Int("floor", floor.Value()),
+		slog.String("direction", string(direction)))
+	e.pushWithContext()
+	return direction, nil
+}
+
+// Reposition sends the car to a floor where demand is expected. The car
+// stops there as for a car call, without counting a rider on board, and the
+// direction of the floor is returned.
+func (e *Elevator) Reposition(floor domain.Floor) (domain.Direction, error) {
+	direction, err := e.directionTo(floor)
+	if err != nil {
+		return "", err
+	}
+
+	e.wake(direction, floor)
+	e.load.reposition(direction, floor.Value())
+	e.directionsManager.AppendStop(direction, floor)
+	e.notifyChange()
+	e.logger.Info("car repositioned",
+		slog.Int("floor", floor.Value()),
+		slog.String("direction", string(direction)))
+	e.pushWithContext()
+	return direction, nil
+}
+
+// directionTo returns the direction of a floor other than the current one
+// within the car's range
+func (e *Elevator) directionTo(floor domain.Floor) (domain.Direction, error) {
+	if !e.state.IsFloorInRange(floor) {
+		return "", domain.NewValidationError("floor out of range for the elevator", nil).
+			WithContext("floor", floor.Value()).
+			WithContext("min_floor", e.state.MinFloor().Value()).
+			WithContext("max_floor", e.state.MaxFloor().Value())
+	}
+
+	currentFloor := e.state.CurrentFloor()
+	if currentFloor.IsEqual(floor) {
+		return "", domain.NewValidationError("car is already at the floor", nil).
+			WithContext("floor", floor.Value())
+	}
+
+	if floor.IsBelow(currentFloor) {
+		return domain.DirectionDown, nil
+	}
+	return domain.DirectionUp, nil
+}
+
+// HallCall registers an up or down button pressed at a floor. The car stops
+// there when travelling in direction; the rider's destination follows as a
+// car call once on board.
+func (e *Elevator) HallCall(direction domain.Direction, floor domain.Floor) {
+	currentDirection := e.wake(direction, floor)
+	e.load.hallCall(direction, floor.Value())
+	e.directionsManager.AppendStop(direction, floor)
+	e.notifyChange()
+	e.logger.Info("hall call received",
+		slog.Int("floor", floor.Value()),
+		slog.String("direction", string(direction)),
+		slog.String("current_direction", string(currentDirection)))
+	e.pushWithContext()
+}
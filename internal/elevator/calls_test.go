@@ -0,0 +1,76 @@
+package elevator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+	"github.com/slavakukuyev/elevator-go/internal/events"
+)
+
+func TestElevator_HallCallThenCarCall(t *testing.T) {
+	e := newFaultTestElevator(t, time.Second)
+
+	// The up button at floor 3 brings the car there without a destination
+	e.HallCall(domain.DirectionUp, domain.NewFloor(3))
+	require.Eventually(t, func() bool {
+		return !e.HasPendingRequests() && e.CurrentFloor().Value() == 3
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// The rider on board then presses floor 7
+	direction, err := e.CarCall(domain.NewFloor(7))
+	require.NoError(t, err)
+	assert.Equal(t, domain.DirectionUp, direction)
+	require.Eventually(t, func() bool {
+		return !e.HasPendingRequests() && e.CurrentFloor().Value() == 7
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, domain.DirectionIdle, e.CurrentDirection())
+
+	direction, err = e.CarCall(domain.NewFloor(1))
+	require.NoError(t, err)
+	assert.Equal(t, domain.DirectionDown, direction)
+}
+
+func TestElevator_CarCallRejectsInvalidFloors(t *testing.T) {
+	e := newFaultTestElevator(t, time.Second)
+
+	for _, floor := range []int{0, 10, -1} {
+		_, err := e.CarCall(domain.NewFloor(floor))
+		require.Error(t, err)
+		assert.Equal(t, domain.ErrTypeValidation, err.(*domain.DomainError).Type)
+	}
+	assert.False(t, e.HasPendingRequests())
+}
+
+func TestElevator_Reposition(t *testing.T) {
+	e := newFaultTestElevator(t, time.Second)
+	bus := events.NewBus(100)
+	e.SetEventBus(bus)
+	sub := bus.Subscribe(100)
+	defer sub.Close()
+
+	direction, err := e.Reposition(domain.NewFloor(8))
+	require.NoError(t, err)
+	assert.Equal(t, domain.DirectionUp, direction)
+
+	// Car calls from riders already in the car are served, and the stop
+	// the car was sent to is expected to be empty and is kept
+	_, err = e.CarCall(domain.NewFloor(5))
+	require.NoError(t, err)
+	_, err = e.CarCall(domain.NewFloor(6))
+	require.NoError(t, err)
+
+	assert.Equal(t, []int{5, 6, 8}, stopsUntilIdle(t, e, sub))
+	assert.Equal(t, 8, e.CurrentFloor().Value())
+	assert.Equal(t, domain.DirectionIdle, e.CurrentDirection())
+
+	for _, floor := range []int{8, 10, -1} {
+		_, err := e.Reposition(domain.NewFloor(floor))
+		require.Error(t, err)
+		assert.Equal(t, domain.ErrTypeValidation, err.(*domain.DomainError).Type)
+	}
+	assert.False(t, e.HasPendingRequests())
+}
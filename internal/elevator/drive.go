@@ -0,0 +1,98 @@
+package elevator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync/atomic"
+
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+)
+
+// Drive moves a car and operates its doors. Run decides where the car goes
+// and when its doors open, pacing each step by the floor duration; the drive
+// carries the decisions out, on the built-in simulator or on hardware.
+type Drive interface {
+	// MoveTo moves the car to an adjacent floor and returns once it has
+	// arrived
+	MoveTo(ctx context.Context, floor domain.Floor) error
+	// OpenDoor opens the doors at the floor the car stands at
+	OpenDoor(ctx context.Context) error
+	// CloseDoor closes the doors
+	CloseDoor(ctx context.Context) error
+	// Position reports the floor the car stands at
+	Position(ctx context.Context) (domain.Floor, error)
+}
+
+// simulatedDrive is the built-in drive. Run already waits out the travel
+// time, so the car and its doors move instantly.
+type simulatedDrive struct {
+	floor atomic.Int64
+}
+
+func newSimulatedDrive(floor domain.Floor) *simulatedDrive {
+	d := &simulatedDrive{}
+	d.floor.Store(int64(floor.Value()))
+	return d
+}
+
+func (d *simulatedDrive) MoveTo(_ context.Context, floor domain.Floor) error {
+	d.floor.Store(int64(floor.Value()))
+	return nil
+}
+
+func (d *simulatedDrive) OpenDoor(context.Context) error {
+	return nil
+}
+
+func (d *simulatedDrive) CloseDoor(context.Context) error {
+	return nil
+}
+
+func (d *simulatedDrive) Position(context.Context) (domain.Floor, error) {
+	return domain.NewFloor(int(d.floor.Load())), nil
+}
+
+// SetDrive replaces the simulated drive, for example with a hardware
+// adapter, and takes the car's position from it. It must be called before
+// the elevator serves requests. A drive that implements io.Closer is closed
+// on Shutdown.
+func (e *Elevator) SetDrive(ctx context.Context, drive Drive) error {
+	floor, err := drive.Position(ctx)
+	if err != nil {
+		return domain.NewExternalError("failed to read car position from drive", err).
+			WithContext("elevator", e.Name())
+	}
+	if !e.state.IsFloorInRange(floor) {
+		return domain.NewValidationError("drive reports a floor outside the elevator's range", nil).
+			WithContext("elevator", e.Name()).
+			WithContext("floor", floor.Value())
+	}
+
+	e.drive = drive
+	e.state.SetCurrentFloor(floor)
+	e.logger.Info("elevator drive attached",
+		slog.String("drive", fmt.Sprintf("%T", drive)),
+		slog.Int("floor", floor.Value()))
+	return nil
+}
+
+// driveError reports a failed drive command. It counts against the circuit
+// breaker like any failed step; an interrupted command is reported as such.
+func (e *Elevator) driveError(ctx context.Context, command string, err error) error {
+	if ctx.Err() != nil {
+		return e.interrupted(ctx)
+	}
+	return fmt.Errorf("elevator %s: drive failed to %s: %w", e.Name(), command, err)
+}
+
+// closeDrive closes a drive that holds a connection
+func (e *Elevator) closeDrive() {
+	if closer, ok := e.drive.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			e.logger.Warn("failed to close elevator drive",
+				slog.String("error", err.Error()))
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package elevator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+)
+
+// recordingDrive records the commands it carries out and fails those listed
+// in failing
+type recordingDrive struct {
+	mu       sync.Mutex
+	floor    domain.Floor
+	commands []string
+	failing  map[string]bool
+	closed   bool
+}
+
+func (d *recordingDrive) record(command string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.commands = append(d.commands, command)
+	if d.failing[command] {
+		return errors.New("device fault")
+	}
+	return nil
+}
+
+func (d *recordingDrive) MoveTo(_ context.Context, floor domain.Floor) error {
+	if err := d.record(fmt.Sprintf("move %d", floor.Value())); err != nil {
+		return err
+	}
+	d.mu.Lock()
+	d.floor = floor
+	d.mu.Unlock()
+	return nil
+}
+
+func (d *recordingDrive) OpenDoor(context.Context) error  { return d.record("open") }
+func (d *recordingDrive) CloseDoor(context.Context) error { return d.record("close") }
+
+func (d *recordingDrive) Position(context.Context) (domain.Floor, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.floor, nil
+}
+
+func (d *recordingDrive) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.closed = true
+	return nil
+}
+
+func (d *recordingDrive) recorded() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.commands...)
+}
+
+func TestElevator_SetDrive(t *testing.T) {
+	e := newFaultTestElevator(t, time.Second)
+	drive := &recordingDrive{floor: domain.NewFloor(2)}
+
+	require.NoError(t, e.SetDrive(context.Background(), drive))
+	assert.Equal(t, 2, e.CurrentFloor().Value())
+
+	// The drive carries out the trip the elevator plans
+	e.Request(domain.DirectionUp, domain.NewFloor(2), domain.NewFloor(4))
+	require.Eventually(t, func() bool {
+		return !e.HasPendingRequests() && e.CurrentFloor().Value() == 4
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"open", "close", "move 3", "move 4", "open", "close"}, drive.recorded())
+
+	e.Shutdown()
+	assert.True(t, drive.closed)
+}
+
+func TestElevator_SetDriveRejectsPositionOutOfRange(t *testing.T) {
+	e := newFaultTestElevator(t, time.Second)
+
+	err := e.SetDrive(context.Background(), &recordingDrive{floor: domain.NewFloor(12)})
+	require.Error(t, err)
+	assert.Equal(t, domain.ErrTypeValidation, err.(*domain.DomainError).Type)
+	assert.Equal(t, 0, e.CurrentFloor().Value())
+}
+
+func TestElevator_DriveFailureFailsStep(t *testing.T) {
+	e := newFaultTestElevator(t, time.Second)
+	drive := &recordingDrive{failing: map[string]bool{"move 1": true}}
+	require.NoError(t, e.SetDrive(context.Background(), drive))
+
+	e.Request(domain.DirectionUp, domain.NewFloor(3), domain.NewFloor(5))
+
+	// Failed moves count against the circuit breaker and the car stays put
+	require.Eventually(t, e.IsFaulted, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 0, e.CurrentFloor().Value())
+	assert.True(t, e.HasPendingRequests())
+}
@@ -2,13 +2,19 @@ package elevator
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/slavakukuyev/elevator-go/internal/circuitbreaker"
 	"github.com/slavakukuyev/elevator-go/internal/constants"
 	"github.com/slavakukuyev/elevator-go/internal/directions"
 	"github.com/slavakukuyev/elevator-go/internal/domain"
+	"github.com/slavakukuyev/elevator-go/internal/events"
+	"github.com/slavakukuyev/elevator-go/metrics"
 )
 
 // Elevator represents an elevator with improved architecture and concurrency support
@@ -17,21 +23,44 @@ type Elevator struct {
 	directionsManager *directions.Manager
 	ctx               context.Context
 	cancel            context.CancelFunc
-	switchOnChan      chan struct{} // Channel for status updates - using struct{} for zero memory
-	eachFloorDuration time.Duration
-	openDoorDuration  time.Duration
-	circuitBreaker    *CircuitBreaker // Circuit breaker for fault tolerance
+	switchOnChan      chan struct{}           // Channel for status updates - using struct{} for zero memory
+	timing            atomic.Pointer[timing]  // Travel and door durations, read once per Run step
+	tuneMu            sync.Mutex              // Serializes Tune calls
+	circuitBreaker    *circuitbreaker.Breaker // Circuit breaker for fault tolerance
 	logger            *slog.Logger
 	operationTimeout  time.Duration // Timeout for elevator operations
-	overloadThreshold int           // Maximum number of requests before considering elevator overloaded
+	overloadThreshold atomic.Int64  // Maximum number of requests before considering elevator overloaded
 	isDeleting        atomic.Bool   // Flag for graceful deletion without interrupting movement
+	eventBus          atomic.Pointer[events.Bus]
+	changeNotifier    atomic.Pointer[func()]
+	faults            *faultInjector // Simulated hardware failures
+	load              *loadSensor    // Simulated load sensor
+	drive             Drive          // Moves the car and operates its doors
+	motion            motion         // Run the car is on when it follows a motion profile
 }
 
-// New creates a new elevator instance with context support
+// New creates a new elevator instance with context support and a circuit
+// breaker that opens after consecutive failures
 func New(name string,
 	minFloor, maxFloor int,
 	eachFloorDuration, openDoorDuration, operationTimeout time.Duration,
 	circuitBreakerMaxFailures int, circuitBreakerResetTimeout time.Duration, circuitBreakerHalfOpenLimit, overloadThreshold int) (*Elevator, error) {
+	return NewWithCircuitBreaker(name,
+		minFloor, maxFloor,
+		eachFloorDuration, openDoorDuration, operationTimeout,
+		circuitbreaker.Settings{
+			MaxFailures:   circuitBreakerMaxFailures,
+			ResetTimeout:  circuitBreakerResetTimeout,
+			HalfOpenLimit: circuitBreakerHalfOpenLimit,
+		}, overloadThreshold)
+}
+
+// NewWithCircuitBreaker creates a new elevator whose operations are guarded by
+// a circuit breaker with the given settings
+func NewWithCircuitBreaker(name string,
+	minFloor, maxFloor int,
+	eachFloorDuration, openDoorDuration, operationTimeout time.Duration,
+	breakerSettings circuitbreaker.Settings, overloadThreshold int) (*Elevator, error) {
 
 	if name == "" {
 		return nil, domain.NewValidationError("elevator name cannot be empty", nil)
@@ -58,14 +87,20 @@ func New(name string,
 		directionsManager: directions.New(),
 		ctx:               ctx,
 		cancel:            cancel,
-		switchOnChan:      make(chan struct{}, 10), // Buffered channel using struct{} for zero memory
-		eachFloorDuration: eachFloorDuration,
-		openDoorDuration:  openDoorDuration,
-		circuitBreaker:    NewCircuitBreaker(circuitBreakerMaxFailures, circuitBreakerResetTimeout, circuitBreakerHalfOpenLimit), // Initialize circuit breaker
+		switchOnChan:      make(chan struct{}, 10),                   // Buffered channel using struct{} for zero memory
+		circuitBreaker:    circuitbreaker.New(name, breakerSettings), // Initialize circuit breaker
 		logger:            logger,
 		operationTimeout:  operationTimeout,
-		overloadThreshold: overloadThreshold,
+		faults:            newFaultInjector(),
+		load:              newLoadSensor(),
+		drive:             newSimulatedDrive(minFloorDomain),
 	}
+	e.timing.Store(&timing{eachFloorDuration: eachFloorDuration, openDoorDuration: openDoorDuration})
+	e.overloadThreshold.Store(int64(overloadThreshold))
+
+	e.state.SetOnChange(e.notifyChange)
+	e.circuitBreaker.OnStateChange(e.onCircuitBreakerStateChange)
+	metrics.SetCircuitBreakerState("", name, circuitbreaker.StateClosed.Value())
 
 	// start read events process with context
 	go e.switchOn()
@@ -88,6 +123,65 @@ func (e *Elevator) SetName(name string) *Elevator {
 	return e
 }
 
+// Building returns the ID of the building the elevator serves, which
+// labels its metrics
+func (e *Elevator) Building() string {
+	return e.state.Building()
+}
+
+// SetBuilding sets the ID of the building the elevator serves. Elevators of
+// different buildings may share a name, so their metrics are told apart by
+// building.
+func (e *Elevator) SetBuilding(building string) *Elevator {
+	previous := e.state.Building()
+	e.state.SetBuilding(building)
+	metrics.DeleteCircuitBreakerState(previous, e.Name())
+	metrics.SetCircuitBreakerState(building, e.Name(), e.circuitBreaker.State().Value())
+	return e
+}
+
+// Type returns the elevator type
+func (e *Elevator) Type() domain.ElevatorType {
+	return e.state.Type()
+}
+
+// SetType sets the elevator type, which decides the calls it may serve
+func (e *Elevator) SetType(elevatorType domain.ElevatorType) *Elevator {
+	e.state.SetType(elevatorType)
+	return e
+}
+
+// Serves reports whether the elevator's type allows it to serve a call
+func (e *Elevator) Serves(call domain.CallOptions) bool {
+	return e.Type().Serves(call)
+}
+
+// SetEventBus attaches the bus on which the elevator publishes its events
+func (e *Elevator) SetEventBus(bus *events.Bus) {
+	e.eventBus.Store(bus)
+}
+
+// publishEvent publishes an event if an event bus is attached
+func (e *Elevator) publishEvent(eventType events.Type, data map[string]any) {
+	if bus := e.eventBus.Load(); bus != nil {
+		bus.Publish(eventType, e.Name(), data)
+	}
+}
+
+// SetChangeNotifier registers a callback invoked whenever the elevator's
+// visible status changes (floor, direction, doors, pending requests or
+// deletion). The callback must not block.
+func (e *Elevator) SetChangeNotifier(fn func()) {
+	e.changeNotifier.Store(&fn)
+}
+
+// notifyChange invokes the registered change notifier, if any
+func (e *Elevator) notifyChange() {
+	if fn := e.changeNotifier.Load(); fn != nil && *fn != nil {
+		(*fn)()
+	}
+}
+
 // switchOn processes elevator events with context support
 func (e *Elevator) switchOn() {
 	for {
@@ -98,6 +192,9 @@ func (e *Elevator) switchOn() {
 		case <-e.switchOnChan:
 			if e.directionsManager.HasUpRequests() || e.directionsManager.HasDownRequests() {
 				e.runWithTimeout()
+			} else {
+				// Nothing is left to travel to
+				e.halt()
 			}
 		}
 	}
@@ -109,46 +206,76 @@ func (e *Elevator) runWithTimeout() {
 	ctx, cancel := context.WithTimeout(e.ctx, e.operationTimeout)
 	defer cancel()
 
-	done := make(chan struct{})
-	var operationErr error
+	// Wrap Run operation with circuit breaker protection
+	operationErr := e.circuitBreaker.Execute(ctx, func() error {
+		return e.run(ctx)
+	})
 
-	go func() {
-		defer close(done)
-		// Wrap Run operation with circuit breaker protection
-		operationErr = e.circuitBreaker.Execute(ctx, func() error {
-			e.Run()
-			return nil // Run doesn't return error, but we can add error handling in future
-		})
-	}()
+	if operationErr == nil {
+		return
+	}
+	e.halt()
 
-	select {
-	case <-ctx.Done():
-		e.logger.Warn("elevator operation timed out",
-			slog.Duration("timeout", e.operationTimeout),
-			slog.Int("current_floor", e.state.CurrentFloor().Value()))
-	case <-done:
-		if operationErr != nil {
-			state, failures, _ := e.circuitBreaker.GetMetrics()
-			e.logger.Warn("elevator operation failed via circuit breaker",
-				slog.String("circuit_breaker_state", e.getCircuitBreakerStateName(state)),
-				slog.Int("failure_count", failures),
-				slog.String("error", operationErr.Error()))
-		}
-		// Operation completed successfully or failed gracefully
+	// The failed step left its requests pending and nothing else wakes the
+	// elevator up, so retry once the breaker lets operations through again
+	retryAfter := max(e.circuitBreaker.RetryAfter(), e.timing.Load().eachFloorDuration)
+	time.AfterFunc(retryAfter, e.pushWithContext)
+
+	if errors.Is(operationErr, circuitbreaker.ErrOpen) {
+		e.logger.Debug("elevator operation rejected by open circuit breaker",
+			slog.Duration("retry_after", retryAfter))
+		return
+	}
+
+	state, failures, _ := e.circuitBreaker.Metrics()
+	stateName := state.String()
+	e.logger.Warn("elevator operation failed via circuit breaker",
+		slog.String("circuit_breaker_state", stateName),
+		slog.Int("failure_count", failures),
+		slog.Int("current_floor", e.state.CurrentFloor().Value()),
+		slog.String("error", operationErr.Error()))
+	metrics.IncCircuitBreakerFailures(e.Building(), e.Name())
+
+	data := map[string]any{
+		"floor":                 e.state.CurrentFloor().Value(),
+		"error":                 operationErr.Error(),
+		"circuit_breaker_state": stateName,
 	}
+	var faultErr *FaultError
+	if errors.As(operationErr, &faultErr) {
+		data["fault"] = string(faultErr.Fault)
+	}
+	e.publishEvent(events.TypeElevatorFault, data)
 }
 
-// getCircuitBreakerStateName returns the string representation of circuit breaker state
-func (e *Elevator) getCircuitBreakerStateName(state CircuitBreakerState) string {
-	switch state {
-	case StateClosed:
-		return "closed"
-	case StateOpen:
-		return "open"
-	case StateHalfOpen:
-		return "half-open"
-	default:
-		return "unknown"
+// interrupted explains why ctx ended during an operation: shutting the
+// elevator down is not a failure, running out of time is
+func (e *Elevator) interrupted(ctx context.Context) error {
+	if e.ctx.Err() != nil {
+		return nil
+	}
+	return fmt.Errorf("elevator operation exceeded %s: %w", e.operationTimeout, ctx.Err())
+}
+
+// onCircuitBreakerStateChange reports circuit breaker transitions. A breaker
+// that opens takes the elevator out of service; one that closes again after
+// failures means the elevator recovered.
+func (e *Elevator) onCircuitBreakerStateChange(_ string, from, to circuitbreaker.State) {
+	metrics.SetCircuitBreakerState(e.Building(), e.Name(), to.Value())
+	level := slog.LevelInfo
+	if to == circuitbreaker.StateOpen {
+		level = slog.LevelWarn
+	}
+	e.logger.Log(e.ctx, level, "circuit breaker state changed",
+		slog.String("from", from.String()),
+		slog.String("to", to.String()))
+	switch to {
+	case circuitbreaker.StateOpen:
+		e.publishEvent(events.TypeCircuitBreakerOpened, map[string]any{
+			"from": from.String(),
+		})
+	case circuitbreaker.StateClosed:
+		e.publishEvent(events.TypeElevatorRecovered, nil)
 	}
 }
 
@@ -163,6 +290,9 @@ func (e *Elevator) getCircuitBreakerStateName(state CircuitBreakerState) string
 // 4. Handle direction changes when needed (LOOK algorithm optimization)
 // 5. Manage idle state when no requests exist (energy efficient)
 //
+// Priority calls are served first: the car heads for the most urgent one and,
+// for emergency calls, skips every other stop on the way.
+//
 // The algorithm handles 7 main scenarios:
 // - Moving up with up requests (normal upward traffic)
 // - Moving down with down requests (normal downward traffic)
@@ -171,7 +301,22 @@ func (e *Elevator) getCircuitBreakerStateName(state CircuitBreakerState) string
 // - Overshot recovery: up direction (prevents stranded passengers)
 // - Overshot recovery: down direction (prevents stranded passengers)
 // - Idle state management (energy efficient when no work)
-func (e *Elevator) Run() {
+//
+// Run returns an error when an injected fault or a timeout interrupts the
+// step; the pending requests stay queued for the next attempt.
+func (e *Elevator) Run() error {
+	return e.run(e.ctx)
+}
+
+// run performs one step of the algorithm described on Run; ctx bounds the
+// floor travel and door waits. The durations are read once, so Tune takes
+// effect from the next step.
+func (e *Elevator) run(ctx context.Context) error {
+	timing := e.timing.Load()
+	if _, ok := e.faults.trigger(domain.FaultPositionLoss); ok {
+		return e.faultError(domain.FaultPositionLoss, "position sensing lost")
+	}
+
 	currentFloor := e.state.CurrentFloor()
 	direction := e.state.Direction()
 
@@ -181,12 +326,25 @@ func (e *Elevator) Run() {
 
 	// Simulate real elevator movement time between floors
 	// This prevents the algorithm from running too fast and allows for
-	// realistic timing in the simulation
-	select {
-	case <-e.ctx.Done():
-		return
-	case <-time.After(e.eachFloorDuration):
-		// Continue with normal operation
+	// realistic timing in the simulation. A car following a motion profile
+	// spends its travel time in moveTo instead.
+	if _, ok := e.MotionProfile(); !ok {
+		travelDuration := timing.eachFloorDuration
+		if fault, ok := e.faults.trigger(domain.FaultSlowTravel); ok {
+			travelDuration = time.Duration(float64(travelDuration) * fault.SlowdownFactor)
+		}
+		select {
+		case <-ctx.Done():
+			return e.interrupted(ctx)
+		case <-time.After(travelDuration):
+			// Continue with normal operation
+		}
+	}
+
+	// SCENARIO 0: Priority calls are served before the SCAN/LOOK sweep
+	// resumes, see runPriority
+	if call, ok := e.directionsManager.NextPriority(); ok {
+		return e.runPriority(ctx, timing, call)
 	}
 
 	// SCENARIO 1: Boundary handling - Top floor with up direction but no up requests
@@ -196,7 +354,7 @@ func (e *Elevator) Run() {
 		if e.directionsManager.HasDownRequests() {
 			e.state.SetDirection(domain.DirectionDown)
 			e.pushWithContext()
-			return
+			return nil
 		}
 		// No requests in either direction, will transition to idle at the end
 	}
@@ -208,9 +366,9 @@ func (e *Elevator) Run() {
 		// Service current floor if passengers requested it
 		// This handles both pickup and dropoff at the current floor
 		if e.directionsManager.HasUpFloor(currentFloor.Value()) {
-			e.openDoor()
-			e.directionsManager.Flush(direction, currentFloor)
-			e.closeDoor()
+			if err := e.stopAt(ctx, timing, direction, currentFloor); err != nil {
+				return err
+			}
 		}
 
 		// Boundary handling: Check if elevator reached the top floor
@@ -220,7 +378,7 @@ func (e *Elevator) Run() {
 			if e.directionsManager.HasDownRequests() {
 				e.state.SetDirection(domain.DirectionDown)
 				e.pushWithContext()
-				return
+				return nil
 			}
 			// Priority 2: Check for up requests below current floor that need pickup
 			// This handles cases where passengers are waiting on lower floors
@@ -231,7 +389,7 @@ func (e *Elevator) Run() {
 					if smallestFloor.IsBelow(currentFloor) {
 						e.state.SetDirection(domain.DirectionDown)
 						e.pushWithContext()
-						return
+						return nil
 					}
 				}
 			}
@@ -241,10 +399,7 @@ func (e *Elevator) Run() {
 		// Continue moving up if there are more up requests above current floor
 		// This implements the core SCAN algorithm - continue in one direction until all requests served
 		if e.shouldMoveUp() {
-			newFloor := domain.NewFloor(currentFloor.Value() + 1)
-			e.state.SetCurrentFloor(newFloor)
-			e.pushWithContext()
-			return
+			return e.moveTo(ctx, domain.NewFloor(currentFloor.Value()+1))
 		}
 	}
 
@@ -256,7 +411,7 @@ func (e *Elevator) Run() {
 		if e.directionsManager.HasUpRequests() {
 			e.state.SetDirection(domain.DirectionUp)
 			e.pushWithContext()
-			return
+			return nil
 		}
 		// No requests in either direction, will transition to idle at the end
 	}
@@ -268,9 +423,9 @@ func (e *Elevator) Run() {
 		// Service current floor if passengers requested it
 		// This handles both pickup and dropoff at the current floor
 		if e.directionsManager.HasDownFloor(currentFloor.Value()) {
-			e.openDoor()
-			e.directionsManager.Flush(direction, currentFloor)
-			e.closeDoor()
+			if err := e.stopAt(ctx, timing, direction, currentFloor); err != nil {
+				return err
+			}
 		}
 
 		// Boundary handling: Check if elevator reached the bottom floor
@@ -280,7 +435,7 @@ func (e *Elevator) Run() {
 			if e.directionsManager.HasUpRequests() {
 				e.state.SetDirection(domain.DirectionUp)
 				e.pushWithContext()
-				return
+				return nil
 			}
 			// Priority 2: Check for down requests above current floor that need pickup
 			// This handles cases where passengers are waiting on upper floors
@@ -291,7 +446,7 @@ func (e *Elevator) Run() {
 					if largestFloor.IsAbove(currentFloor) {
 						e.state.SetDirection(domain.DirectionUp)
 						e.pushWithContext()
-						return
+						return nil
 					}
 				}
 			}
@@ -301,10 +456,7 @@ func (e *Elevator) Run() {
 		// Continue moving down if there are more down requests below current floor
 		// This implements the core SCAN algorithm - continue in one direction until all requests served
 		if e.shouldMoveDown() {
-			newFloor := domain.NewFloor(currentFloor.Value() - 1)
-			e.state.SetCurrentFloor(newFloor)
-			e.pushWithContext()
-			return
+			return e.moveTo(ctx, domain.NewFloor(currentFloor.Value()-1))
 		}
 	}
 
@@ -319,10 +471,7 @@ func (e *Elevator) Run() {
 			// Continue moving down if the smallest up request is below current floor
 			// This ensures we don't change direction prematurely
 			if smallestFloor.IsBelow(currentFloor) {
-				newFloor := domain.NewFloor(currentFloor.Value() - 1)
-				e.state.SetCurrentFloor(newFloor)
-				e.pushWithContext()
-				return
+				return e.moveTo(ctx, domain.NewFloor(currentFloor.Value()-1))
 			}
 
 			// Change direction to up if we're already at the pickup floor
@@ -330,7 +479,7 @@ func (e *Elevator) Run() {
 			if smallestFloor.IsEqual(currentFloor) {
 				e.state.SetDirection(domain.DirectionUp)
 				e.pushWithContext()
-				return
+				return nil
 			}
 
 			// If the smallest up request is above current floor, change direction to up
@@ -338,7 +487,7 @@ func (e *Elevator) Run() {
 			if smallestFloor.IsAbove(currentFloor) {
 				e.state.SetDirection(domain.DirectionUp)
 				e.pushWithContext()
-				return
+				return nil
 			}
 		}
 	}
@@ -354,10 +503,7 @@ func (e *Elevator) Run() {
 			// Continue moving up if the largest down request is above current floor
 			// This ensures we don't change direction prematurely
 			if largestFloor.IsAbove(currentFloor) {
-				newFloor := domain.NewFloor(currentFloor.Value() + 1)
-				e.state.SetCurrentFloor(newFloor)
-				e.pushWithContext()
-				return
+				return e.moveTo(ctx, domain.NewFloor(currentFloor.Value()+1))
 			}
 
 			// Change direction to down if we're already at the pickup floor
@@ -365,7 +511,7 @@ func (e *Elevator) Run() {
 			if largestFloor.IsEqual(currentFloor) {
 				e.state.SetDirection(domain.DirectionDown)
 				e.pushWithContext()
-				return
+				return nil
 			}
 
 			// If the largest down request is below current floor, change direction to down
@@ -373,7 +519,7 @@ func (e *Elevator) Run() {
 			if largestFloor.IsBelow(currentFloor) {
 				e.state.SetDirection(domain.DirectionDown)
 				e.pushWithContext()
-				return
+				return nil
 			}
 		}
 	}
@@ -390,7 +536,7 @@ func (e *Elevator) Run() {
 			if largestFloor.IsBelow(currentFloor) {
 				e.state.SetDirection(domain.DirectionDown)
 				e.pushWithContext()
-				return
+				return nil
 			}
 		}
 	}
@@ -407,7 +553,7 @@ func (e *Elevator) Run() {
 			if smallestFloor.IsAbove(currentFloor) {
 				e.state.SetDirection(domain.DirectionUp)
 				e.pushWithContext()
-				return
+				return nil
 			}
 		}
 	}
@@ -417,9 +563,60 @@ func (e *Elevator) Run() {
 	// the elevator enters an idle state to save energy
 	// This is normal behavior and not a bug - elevators should be idle when no work exists
 	if e.directionsManager.IsIdle() {
+		e.halt()
 		e.state.SetDirection(domain.DirectionIdle)
 		e.logger.Debug("elevator stopped and has empty requests for both directions", slog.Int("floor", e.state.CurrentFloor().Value()))
 	}
+	return nil
+}
+
+// runPriority performs one step towards the most urgent priority call. The
+// car heads straight for the call's pickup floor and then its destination,
+// whatever the sweep direction was. On the way it still stops for travellers
+// going its way, unless the call runs non-stop (emergency), in which case
+// every other stop waits until the call is served.
+func (e *Elevator) runPriority(ctx context.Context, timing *timing, call directions.PriorityCall) error {
+	currentFloor := e.state.CurrentFloor()
+	target := call.Target()
+
+	if currentFloor.IsEqual(target) {
+		// Serve the call in its own direction so trips waiting for it match
+		e.state.SetDirection(call.Direction)
+		if err := e.stopAt(ctx, timing, call.Direction, currentFloor); err != nil {
+			return err
+		}
+
+		if e.directionsManager.IsIdle() {
+			e.state.SetDirection(domain.DirectionIdle)
+			return nil
+		}
+		e.pushWithContext()
+		return nil
+	}
+
+	direction := domain.DirectionUp
+	next := domain.NewFloor(currentFloor.Value() + 1)
+	if target.IsBelow(currentFloor) {
+		direction = domain.DirectionDown
+		next = domain.NewFloor(currentFloor.Value() - 1)
+	}
+	e.state.SetDirection(direction)
+
+	if !call.IsNonStop() && e.HasStop(direction, currentFloor) {
+		if err := e.stopAt(ctx, timing, direction, currentFloor); err != nil {
+			return err
+		}
+	}
+	return e.moveTo(ctx, next)
+}
+
+// HasStop reports whether the car has to stop at a floor while moving in a
+// direction
+func (e *Elevator) HasStop(direction domain.Direction, floor domain.Floor) bool {
+	if direction == domain.DirectionUp {
+		return e.directionsManager.HasUpFloor(floor.Value())
+	}
+	return e.directionsManager.HasDownFloor(floor.Value())
 }
 
 // shouldMoveUp determines if the elevator should continue moving up in the current direction.
@@ -463,48 +660,210 @@ func (e *Elevator) shouldMoveDown() bool {
 	return false
 }
 
-func (e *Elevator) openDoor() {
+// moveTo has the drive advance the car to an adjacent floor, then takes the
+// car's position from the drive. A car following a motion profile first
+// travels for as long as its run takes to reach the floor.
+func (e *Elevator) moveTo(ctx context.Context, floor domain.Floor) error {
+	if _, ok := e.faults.trigger(domain.FaultStuckBetweenFloors); ok {
+		return e.faultError(domain.FaultStuckBetweenFloors,
+			fmt.Sprintf("stuck on the way to floor %d", floor.Value()))
+	}
+
+	if profile, ok := e.MotionProfile(); ok {
+		travelDuration := e.travelTime(profile, floor, e.runDestination(floor))
+		if fault, ok := e.faults.trigger(domain.FaultSlowTravel); ok {
+			travelDuration = time.Duration(float64(travelDuration) * fault.SlowdownFactor)
+		}
+		select {
+		case <-ctx.Done():
+			e.halt()
+			return e.interrupted(ctx)
+		case <-time.After(travelDuration):
+		}
+	}
+
+	if err := e.drive.MoveTo(ctx, floor); err != nil {
+		return e.driveError(ctx, fmt.Sprintf("move to floor %d", floor.Value()), err)
+	}
+
+	position, err := e.drive.Position(ctx)
+	if err != nil {
+		return e.driveError(ctx, "report its position", err)
+	}
+	e.arrive(position)
+	e.state.SetCurrentFloor(position)
+	e.pushWithContext()
+	if !position.IsEqual(floor) {
+		return fmt.Errorf("elevator %s: drive stopped at floor %d instead of %d",
+			e.Name(), position.Value(), floor.Value())
+	}
+	return nil
+}
+
+// stopAt serves the stop at the current floor in a direction: the doors
+// open, riders alight and board, and the load sensor checks the stop for
+// nuisance calls before the doors close. The stop_served event marks the
+// moment the riders got on and off.
+func (e *Elevator) stopAt(ctx context.Context, timing *timing, direction domain.Direction, floor domain.Floor) error {
+	if err := e.openDoor(ctx, timing.openDoorDuration); err != nil {
+		return err
+	}
+	boarded := e.directionsManager.Flush(direction, floor)
+	e.publishEvent(events.TypeStopServed, map[string]any{
+		"floor":     floor.Value(),
+		"direction": string(direction),
+		"boarded":   len(boarded),
+	})
+	e.senseLoad(direction, floor, boarded)
+	e.notifyChange()
+	return e.closeDoor(ctx)
+}
+
+// openDoor opens the doors at the current floor for openDoorDuration. When a
+// rider with mobility needs boards or leaves the car there, the doors stay
+// open constants.AccessibleDwellFactor times longer and the arrival and
+// travel direction are announced.
+func (e *Elevator) openDoor(ctx context.Context, openDoorDuration time.Duration) error {
+	if _, ok := e.faults.trigger(domain.FaultDoorFailure); ok {
+		return e.faultError(domain.FaultDoorFailure, "door cycle failed")
+	}
+
+	e.halt()
+	if err := e.drive.OpenDoor(ctx); err != nil {
+		return e.driveError(ctx, "open the doors", err)
+	}
+
+	floor := e.state.CurrentFloor().Value()
+	direction := e.state.Direction()
+	assisted := e.directionsManager.NeedsAssistance(direction, floor)
+	if assisted {
+		openDoorDuration = time.Duration(float64(openDoorDuration) * constants.AccessibleDwellFactor)
+	}
+
 	e.logger.Info("elevator doors operation",
 		slog.String("action", "open"),
-		slog.Int("floor", e.state.CurrentFloor().Value()))
+		slog.Int("floor", floor),
+		slog.Bool("assisted", assisted))
+	e.state.SetDoorOpen(true)
+	e.publishEvent(events.TypeFloorArrived, map[string]any{
+		"floor":     floor,
+		"direction": string(direction),
+	})
+	if assisted {
+		e.announce(floor, direction)
+	}
 
 	// Use context-aware sleep for door operations
 	select {
-	case <-e.ctx.Done():
-		return
-	case <-time.After(e.openDoorDuration):
+	case <-ctx.Done():
+		if err := e.interrupted(ctx); err != nil {
+			// The doors close even though the step was interrupted
+			_ = e.closeDoor(context.WithoutCancel(ctx))
+			return err
+		}
+	case <-time.After(openDoorDuration):
 		// Continue with normal operation
 	}
+	return nil
+}
+
+// Kinds of announcement events
+const (
+	// AnnouncementArrival announces the floor the car stopped at
+	AnnouncementArrival = "arrival"
+	// AnnouncementDirection announces where the car travels next
+	AnnouncementDirection = "direction"
+)
+
+// announce publishes the arrival and travel direction announcements that
+// kiosk UIs voice for riders with mobility needs
+func (e *Elevator) announce(floor int, direction domain.Direction) {
+	e.publishEvent(events.TypeAnnouncement, map[string]any{
+		"announcement": AnnouncementArrival,
+		"floor":        floor,
+		"direction":    string(direction),
+		"message":      fmt.Sprintf("Floor %d", floor),
+	})
+	e.publishEvent(events.TypeAnnouncement, map[string]any{
+		"announcement": AnnouncementDirection,
+		"floor":        floor,
+		"direction":    string(direction),
+		"message":      fmt.Sprintf("Going %s", direction),
+	})
 }
 
-func (e *Elevator) closeDoor() {
+func (e *Elevator) closeDoor(ctx context.Context) error {
+	if err := e.drive.CloseDoor(ctx); err != nil {
+		return e.driveError(ctx, "close the doors", err)
+	}
 	e.logger.Info("elevator doors operation",
 		slog.String("action", "close"),
 		slog.Int("floor", e.state.CurrentFloor().Value()))
+	e.state.SetDoorOpen(false)
+	return nil
 }
 
 // Request adds a new elevator request
 func (e *Elevator) Request(direction domain.Direction, fromFloor, toFloor domain.Floor) {
+	e.RequestCall(direction, fromFloor, toFloor, domain.CallOptions{})
+}
+
+// RequestCall adds a new elevator request with its call options. High
+// priority calls are served ahead of ordinary traffic, emergency calls run
+// the car non-stop to the caller and destination, and accessible calls get
+// extra door time and announcements at their pickup and destination floors.
+func (e *Elevator) RequestCall(direction domain.Direction, fromFloor, toFloor domain.Floor, call domain.CallOptions) {
+	currentDirection := e.wake(direction, fromFloor)
+	e.directionsManager.AppendCall(direction, fromFloor, toFloor, call)
+	e.notifyChange()
+	e.logger.Info("new elevator request received",
+		slog.String("direction", string(direction)),
+		slog.Int("from_floor", fromFloor.Value()),
+		slog.Int("to_floor", toFloor.Value()),
+		slog.String("priority", call.Priority.String()),
+		slog.Bool("accessible", call.Accessible),
+		slog.String("current_direction", string(currentDirection)))
+	e.pushWithContext()
+}
+
+// wake sets the direction of an idle car towards the floor of a new request
+// going in direction, and returns the direction the car had before
+func (e *Elevator) wake(direction domain.Direction, floor domain.Floor) domain.Direction {
 	currentDirection := e.state.Direction()
 	if currentDirection == domain.DirectionIdle {
 		setDirection := direction
 		currentFloor := e.state.CurrentFloor()
-		if direction == domain.DirectionDown && currentFloor.IsBelow(fromFloor) {
+		if direction == domain.DirectionDown && currentFloor.IsBelow(floor) {
 			setDirection = domain.DirectionUp
-		} else if direction == domain.DirectionUp && currentFloor.IsAbove(fromFloor) {
+		} else if direction == domain.DirectionUp && currentFloor.IsAbove(floor) {
 			setDirection = domain.DirectionDown
 		}
 
 		e.state.SetDirection(setDirection)
 	}
+	return currentDirection
+}
 
-	e.directionsManager.Append(direction, fromFloor, toFloor)
-	e.logger.Info("new elevator request received",
+// CancelRequest withdraws a pickup request that has not been served yet. It
+// returns false if the passengers were already picked up or the request is
+// unknown. keepStop preserves the stop at fromFloor for passengers on board
+// who are headed there.
+func (e *Elevator) CancelRequest(direction domain.Direction, fromFloor, toFloor domain.Floor, keepStop bool) bool {
+	if !e.directionsManager.Remove(direction, fromFloor, toFloor, keepStop) {
+		return false
+	}
+	// switchOn only runs the algorithm while requests remain, so the idle
+	// transition has to happen here when the last request is withdrawn
+	if e.directionsManager.IsIdle() {
+		e.state.SetDirection(domain.DirectionIdle)
+	}
+	e.notifyChange()
+	e.logger.Info("elevator request cancelled",
 		slog.String("direction", string(direction)),
 		slog.Int("from_floor", fromFloor.Value()),
-		slog.Int("to_floor", toFloor.Value()),
-		slog.String("current_direction", string(currentDirection)))
+		slog.Int("to_floor", toFloor.Value()))
 	e.pushWithContext()
+	return true
 }
 
 // CurrentDirection returns the current direction
@@ -543,6 +902,7 @@ func (e *Elevator) Shutdown() {
 	if e.cancel != nil {
 		e.cancel()
 	}
+	e.closeDrive()
 }
 
 // IsRequestInRange checks if the request is within the elevator's range
@@ -568,13 +928,25 @@ func (e *Elevator) MaxFloor() domain.Floor {
 
 // OverloadThreshold returns the elevator's overload threshold
 func (e *Elevator) OverloadThreshold() int {
-	return e.overloadThreshold
+	return int(e.overloadThreshold.Load())
+}
+
+// SetOverloadThreshold changes the overload threshold of a running elevator
+func (e *Elevator) SetOverloadThreshold(threshold int) {
+	e.overloadThreshold.Store(int64(threshold))
+}
+
+// SetCircuitBreakerSettings changes the circuit breaker settings of a running
+// elevator without resetting the breaker state
+func (e *Elevator) SetCircuitBreakerSettings(settings circuitbreaker.Settings) {
+	e.circuitBreaker.Configure(settings)
 }
 
 func (e *Elevator) GetStatus() domain.ElevatorStatus {
 	requestCount := e.directionsManager.DirectionsLength()
 	status := e.state.GetStatus(requestCount)
 	status.IsDeleting = e.isDeleting.Load()
+	status.Position, status.Velocity = e.kinematics(status.CurrentFloor)
 	return status
 }
 
@@ -582,15 +954,28 @@ func (e *Elevator) GetStatus() domain.ElevatorStatus {
 // The elevator continues processing its pending requests until idle, then gets removed.
 func (e *Elevator) MarkForDeletion() {
 	e.isDeleting.Store(true)
+	e.notifyChange()
 	e.logger.Info("elevator marked for deletion",
 		slog.String("elevator", e.Name()))
 }
 
+// IsReserved reports whether the car is reserved for an emergency call and
+// should not be given other requests until it is served
+func (e *Elevator) IsReserved() bool {
+	return e.directionsManager.HasPriority(domain.PriorityEmergency)
+}
+
 // IsMarkedForDeletion returns true if the elevator is marked for deletion
 func (e *Elevator) IsMarkedForDeletion() bool {
 	return e.isDeleting.Load()
 }
 
+// IsFaulted returns true while the circuit breaker rejects the elevator's
+// operations after repeated failures
+func (e *Elevator) IsFaulted() bool {
+	return e.circuitBreaker.State() == circuitbreaker.StateOpen
+}
+
 // CanAcceptRequests returns true if the elevator can accept new requests
 func (e *Elevator) CanAcceptRequests() bool {
 	return !e.isDeleting.Load() && e.CurrentDirection().IsOperational()
@@ -603,19 +988,27 @@ func (e *Elevator) HasPendingRequests() bool {
 
 // GetHealthMetrics returns health metrics including circuit breaker status
 func (e *Elevator) GetHealthMetrics() map[string]any {
-	state, failures, successes := e.circuitBreaker.GetMetrics()
+	state, failures, successes := e.circuitBreaker.Metrics()
+	failureRate, windowRequests := e.circuitBreaker.FailureRate()
 
 	return map[string]any{
-		"name":                      e.Name(),
-		"current_floor":             e.CurrentFloor().Value(),
-		"direction":                 string(e.CurrentDirection()),
-		"pending_requests":          e.directionsManager.DirectionsLength(),
-		"circuit_breaker_state":     e.getCircuitBreakerStateName(state),
-		"circuit_breaker_failures":  failures,
-		"circuit_breaker_successes": successes,
-		"is_healthy":                state != StateOpen && !e.IsMarkedForDeletion(),
-		"is_deleting":               e.IsMarkedForDeletion(),
-		"min_floor":                 e.state.MinFloor().Value(),
-		"max_floor":                 e.state.MaxFloor().Value(),
+		"name":                            e.Name(),
+		"type":                            string(e.Type()),
+		"current_floor":                   e.CurrentFloor().Value(),
+		"direction":                       string(e.CurrentDirection()),
+		"pending_requests":                e.directionsManager.DirectionsLength(),
+		"load":                            e.Load(),
+		"priority_requests":               len(e.directionsManager.PriorityCalls()),
+		"is_reserved":                     e.IsReserved(),
+		"circuit_breaker_state":           state.String(),
+		"circuit_breaker_failures":        failures,
+		"circuit_breaker_successes":       successes,
+		"circuit_breaker_failure_rate":    failureRate,
+		"circuit_breaker_window_requests": windowRequests,
+		"is_healthy":                      state != circuitbreaker.StateOpen && !e.IsMarkedForDeletion(),
+		"is_deleting":                     e.IsMarkedForDeletion(),
+		"faults":                          e.Faults(),
+		"min_floor":                       e.state.MinFloor().Value(),
+		"max_floor":                       e.state.MaxFloor().Value(),
 	}
 }
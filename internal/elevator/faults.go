@@ -0,0 +1,133 @@
+package elevator
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+)
+
+// FaultError is returned by Run when an injected fault interrupts an
+// operation
+type FaultError struct {
+	Elevator string
+	Fault    domain.FaultType
+	Floor    int
+	Reason   string
+}
+
+func (e *FaultError) Error() string {
+	return fmt.Sprintf("elevator %s: %s at floor %d", e.Elevator, e.Reason, e.Floor)
+}
+
+// faultInjector holds the faults injected into an elevator
+type faultInjector struct {
+	mu     sync.Mutex
+	faults map[domain.FaultType]domain.Fault
+}
+
+func newFaultInjector() *faultInjector {
+	return &faultInjector{faults: make(map[domain.FaultType]domain.Fault)}
+}
+
+func (fi *faultInjector) set(fault domain.Fault) {
+	fi.mu.Lock()
+	defer fi.mu.Unlock()
+	fi.faults[fault.Type] = fault
+}
+
+func (fi *faultInjector) clear(faultType domain.FaultType) bool {
+	fi.mu.Lock()
+	defer fi.mu.Unlock()
+	_, ok := fi.faults[faultType]
+	delete(fi.faults, faultType)
+	return ok
+}
+
+func (fi *faultInjector) clearAll() {
+	fi.mu.Lock()
+	defer fi.mu.Unlock()
+	clear(fi.faults)
+}
+
+// list returns the active faults ordered by type
+func (fi *faultInjector) list() []domain.Fault {
+	fi.mu.Lock()
+	defer fi.mu.Unlock()
+	faults := make([]domain.Fault, 0, len(fi.faults))
+	for _, fault := range fi.faults {
+		faults = append(faults, fault)
+	}
+	sort.Slice(faults, func(i, j int) bool { return faults[i].Type < faults[j].Type })
+	return faults
+}
+
+// trigger reports whether a fault of the given type is active and consumes
+// one of its occurrences
+func (fi *faultInjector) trigger(faultType domain.FaultType) (domain.Fault, bool) {
+	fi.mu.Lock()
+	defer fi.mu.Unlock()
+	fault, ok := fi.faults[faultType]
+	if !ok {
+		return fault, false
+	}
+	if fault.Occurrences > 0 {
+		if fault.Occurrences == 1 {
+			delete(fi.faults, faultType)
+		} else {
+			remaining := fault
+			remaining.Occurrences--
+			fi.faults[faultType] = remaining
+		}
+	}
+	return fault, true
+}
+
+// InjectFault activates a simulated hardware failure. Injecting a fault of a
+// type that is already active replaces it.
+func (e *Elevator) InjectFault(fault domain.Fault) (domain.Fault, error) {
+	fault, err := fault.Normalize()
+	if err != nil {
+		return fault, err
+	}
+	e.faults.set(fault)
+	e.logger.Warn("fault injected",
+		slog.String("fault", string(fault.Type)),
+		slog.Float64("slowdown_factor", fault.SlowdownFactor),
+		slog.Int("occurrences", fault.Occurrences))
+	return fault, nil
+}
+
+// ClearFault deactivates a fault and reports whether it was active
+func (e *Elevator) ClearFault(faultType domain.FaultType) bool {
+	cleared := e.faults.clear(faultType)
+	if cleared {
+		e.logger.Info("fault cleared", slog.String("fault", string(faultType)))
+		e.pushWithContext()
+	}
+	return cleared
+}
+
+// ClearFaults deactivates all faults
+func (e *Elevator) ClearFaults() {
+	e.faults.clearAll()
+	e.logger.Info("all faults cleared")
+	e.pushWithContext()
+}
+
+// Faults returns the active faults
+func (e *Elevator) Faults() []domain.Fault {
+	return e.faults.list()
+}
+
+// faultError builds the error for a triggered fault at the current floor
+func (e *Elevator) faultError(faultType domain.FaultType, reason string) error {
+	return &FaultError{
+		Elevator: e.Name(),
+		Fault:    faultType,
+		Floor:    e.state.CurrentFloor().Value(),
+		Reason:   reason,
+	}
+}
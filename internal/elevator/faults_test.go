@@ -0,0 +1,114 @@
+package elevator
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+	"github.com/slavakukuyev/elevator-go/internal/events"
+)
+
+func newFaultTestElevator(t *testing.T, resetTimeout time.Duration) *Elevator {
+	t.Helper()
+	e, err := New("Faulty", 0, 9, 10*time.Millisecond, 10*time.Millisecond, time.Second,
+		2, resetTimeout, 1, 12)
+	require.NoError(t, err)
+	t.Cleanup(e.Shutdown)
+	return e
+}
+
+func TestElevator_InjectFault(t *testing.T) {
+	e := newFaultTestElevator(t, time.Second)
+
+	_, err := e.InjectFault(domain.Fault{Type: "meteor_strike"})
+	assert.Error(t, err)
+
+	fault, err := e.InjectFault(domain.Fault{Type: domain.FaultSlowTravel})
+	require.NoError(t, err)
+	assert.Equal(t, domain.DefaultSlowdownFactor, fault.SlowdownFactor)
+
+	_, err = e.InjectFault(domain.Fault{Type: domain.FaultDoorFailure, Occurrences: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Fault{
+		{Type: domain.FaultDoorFailure, Occurrences: 2},
+		{Type: domain.FaultSlowTravel, SlowdownFactor: domain.DefaultSlowdownFactor},
+	}, e.Faults())
+
+	assert.True(t, e.ClearFault(domain.FaultSlowTravel))
+	assert.False(t, e.ClearFault(domain.FaultSlowTravel))
+	e.ClearFaults()
+	assert.Empty(t, e.Faults())
+}
+
+func TestElevator_RunReturnsFaultErrors(t *testing.T) {
+	e := newFaultTestElevator(t, time.Second)
+	_, err := e.InjectFault(domain.Fault{Type: domain.FaultPositionLoss, Occurrences: 1})
+	require.NoError(t, err)
+
+	err = e.Run()
+	var faultErr *FaultError
+	require.True(t, errors.As(err, &faultErr))
+	assert.Equal(t, domain.FaultPositionLoss, faultErr.Fault)
+	assert.Equal(t, "Faulty", faultErr.Elevator)
+
+	// A single occurrence is consumed by the failed step
+	assert.Empty(t, e.Faults())
+	assert.NoError(t, e.Run())
+}
+
+func TestElevator_StuckFaultOpensCircuitAndRecovers(t *testing.T) {
+	e := newFaultTestElevator(t, 200*time.Millisecond)
+	bus := events.NewBus(100)
+	e.SetEventBus(bus)
+	sub := bus.Subscribe(100)
+	defer sub.Close()
+
+	_, err := e.InjectFault(domain.Fault{Type: domain.FaultStuckBetweenFloors})
+	require.NoError(t, err)
+	e.Request(domain.DirectionUp, domain.NewFloor(3), domain.NewFloor(5))
+
+	// Repeated failures open the breaker while the car stays where it is
+	require.Eventually(t, e.IsFaulted, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 0, e.CurrentFloor().Value())
+	assert.True(t, e.HasPendingRequests())
+
+	fault := <-sub.C
+	assert.Equal(t, events.TypeElevatorFault, fault.Type)
+	assert.Equal(t, string(domain.FaultStuckBetweenFloors), fault.Data["fault"])
+
+	// Once the fault is cleared the breaker closes again and the request is
+	// served without any further push
+	e.ClearFaults()
+	require.Eventually(t, func() bool { return !e.HasPendingRequests() }, 3*time.Second, 10*time.Millisecond)
+	assert.False(t, e.IsFaulted())
+
+	opened, recovered := false, false
+	for !recovered {
+		select {
+		case event := <-sub.C:
+			opened = opened || event.Type == events.TypeCircuitBreakerOpened
+			recovered = event.Type == events.TypeElevatorRecovered
+		case <-time.After(time.Second):
+			t.Fatal("no recovery event")
+		}
+	}
+	assert.True(t, opened, "the breaker reports opening before it recovers")
+}
+
+func TestElevator_DoorFailureRetriesStop(t *testing.T) {
+	e := newFaultTestElevator(t, time.Second)
+	_, err := e.InjectFault(domain.Fault{Type: domain.FaultDoorFailure, Occurrences: 1})
+	require.NoError(t, err)
+
+	e.Request(domain.DirectionUp, domain.NewFloor(0), domain.NewFloor(2))
+
+	// The failed door cycle is retried and the trip still completes
+	require.Eventually(t, func() bool {
+		return !e.HasPendingRequests() && e.CurrentFloor().Value() == 2
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.False(t, e.IsFaulted())
+}
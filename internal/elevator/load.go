@@ -0,0 +1,259 @@
+package elevator
+
+import (
+	"log/slog"
+	"sync"
+
+	"github.com/slavakukuyev/elevator-go/internal/constants"
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+	"github.com/slavakukuyev/elevator-go/metrics"
+)
+
+// Reasons a stop is reported as a nuisance
+const (
+	// NuisanceEmptyStop is a stop where nobody boarded or alighted
+	NuisanceEmptyStop = "empty_stop"
+	// NuisanceExcessCarCalls is a car with more pending destinations than
+	// its load explains
+	NuisanceExcessCarCalls = "excess_car_calls"
+)
+
+// stopKey identifies a stop by floor and the direction it is served in
+type stopKey struct {
+	direction domain.Direction
+	floor     int
+}
+
+// loadSensor simulates the car's load sensor by counting riders as they
+// board and alight. Riders who board a pickup with a destination are
+// counted against the stop they leave at; riders who board at a
+// direction-only hall call count as unassigned until their car call tells
+// where they are headed. A car call pressed while the sensor knows of no
+// boarded rider comes from someone who was already in the car and counts
+// as a rider of its own. Any other car call is unbacked: it is attributed to
+// the riders on board, which is what gives phantom calls away. Stops the
+// car was repositioned to are expected to be empty.
+type loadSensor struct {
+	mu          sync.Mutex
+	riders      map[stopKey]int  // riders on board by the stop where they alight
+	presumed    map[stopKey]int  // riders among them known only by their car call
+	hallCalls   map[stopKey]int  // riders waiting at direction-only hall calls
+	repositions map[stopKey]bool // stops the car was sent to ahead of demand
+	unbacked    []stopKey        // car calls no rider accounts for, in the order pressed
+	unassigned  int              // riders on board without a destination yet
+}
+
+func newLoadSensor() *loadSensor {
+	return &loadSensor{
+		riders:      make(map[stopKey]int),
+		presumed:    make(map[stopKey]int),
+		hallCalls:   make(map[stopKey]int),
+		repositions: make(map[stopKey]bool),
+	}
+}
+
+// reposition records a stop the car was sent to without riders
+func (s *loadSensor) reposition(direction domain.Direction, floor int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.repositions[stopKey{direction, floor}] = true
+}
+
+// isReposition reports whether the car was sent to a stop without riders
+func (s *loadSensor) isReposition(direction domain.Direction, floor int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.repositions[stopKey{direction, floor}]
+}
+
+// hallCall records a rider waiting at a direction-only hall call
+func (s *loadSensor) hallCall(direction domain.Direction, floor int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hallCalls[stopKey{direction, floor}]++
+}
+
+// carCall assigns a car call to a rider on board without a destination. If
+// there is none and no boarded rider is on board either, the call counts as
+// a rider who was already in the car; otherwise it is unbacked.
+func (s *loadSensor) carCall(direction domain.Direction, floor int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := stopKey{direction, floor}
+	switch {
+	case s.unassigned > 0:
+		s.unassigned--
+		s.riders[key]++
+	case s.loadLocked() == s.presumedLocked():
+		s.riders[key]++
+		s.presumed[key]++
+	default:
+		s.unbacked = append(s.unbacked, key)
+	}
+}
+
+// stop counts the riders who alight at a stop and those who board, headed
+// for the given destinations or to be assigned by a car call. It returns
+// the counts and the load once the doors close.
+func (s *loadSensor) stop(direction domain.Direction, floor int, destinations []int) (boarded, alighted, load int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := stopKey{direction, floor}
+	alighted = s.riders[key]
+	delete(s.riders, key)
+	delete(s.presumed, key)
+	delete(s.repositions, key)
+	s.forgetLocked(key)
+
+	waiting := s.hallCalls[key]
+	delete(s.hallCalls, key)
+	s.unassigned += waiting
+	for _, destination := range destinations {
+		s.riders[stopKey{direction, destination}]++
+	}
+	return len(destinations) + waiting, alighted, s.loadLocked()
+}
+
+// phantomCalls returns the unbacked car calls, most recently pressed first
+func (s *loadSensor) phantomCalls() []stopKey {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	calls := make([]stopKey, 0, len(s.unbacked))
+	for i := len(s.unbacked) - 1; i >= 0; i-- {
+		calls = append(calls, s.unbacked[i])
+	}
+	return calls
+}
+
+// forget drops the unbacked car calls to a stop
+func (s *loadSensor) forget(key stopKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.forgetLocked(key)
+}
+
+func (s *loadSensor) forgetLocked(key stopKey) {
+	calls := s.unbacked[:0]
+	for _, call := range s.unbacked {
+		if call != key {
+			calls = append(calls, call)
+		}
+	}
+	s.unbacked = calls
+}
+
+// isBacked reports whether a rider waits at or is headed for a stop
+func (s *loadSensor) isBacked(direction domain.Direction, floor int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := stopKey{direction, floor}
+	return s.riders[key] > 0 || s.hallCalls[key] > 0 || s.repositions[key]
+}
+
+// isHallCall reports whether riders wait at a direction-only hall call
+func (s *loadSensor) isHallCall(direction domain.Direction, floor int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.hallCalls[stopKey{direction, floor}] > 0
+}
+
+// load returns the number of riders on board
+func (s *loadSensor) load() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.loadLocked()
+}
+
+func (s *loadSensor) presumedLocked() int {
+	presumed := 0
+	for _, riders := range s.presumed {
+		presumed += riders
+	}
+	return presumed
+}
+
+func (s *loadSensor) loadLocked() int {
+	load := s.unassigned
+	for _, riders := range s.riders {
+		load += riders
+	}
+	return load
+}
+
+// Load returns the number of riders on board according to the simulated
+// load sensor
+func (e *Elevator) Load() int {
+	return e.load.load()
+}
+
+// senseLoad reads the load sensor for a stop where riders boarded for the
+// given destinations. A stop where nobody boarded or alighted, or more
+// pending destinations than constants.NuisanceDestinationsPerRider per rider
+// on board, is a nuisance: the unbacked car calls the load does not explain
+// are cancelled, and the event is logged and counted. A stop the car was
+// repositioned to may be empty.
+func (e *Elevator) senseLoad(direction domain.Direction, floor domain.Floor, destinations []int) {
+	repositioned := e.load.isReposition(direction, floor.Value())
+	boarded, alighted, load := e.load.stop(direction, floor.Value(), destinations)
+
+	reason := ""
+	if boarded == 0 && alighted == 0 && !repositioned {
+		reason = NuisanceEmptyStop
+	} else if e.pendingDestinations() > load*constants.NuisanceDestinationsPerRider {
+		reason = NuisanceExcessCarCalls
+	}
+	if reason == "" {
+		return
+	}
+
+	cancelled := e.cancelPhantomCalls(load * constants.NuisanceDestinationsPerRider)
+	metrics.IncNuisanceEvents(e.Building(), e.Name(), reason)
+	metrics.AddPhantomCallsCancelled(e.Building(), e.Name(), cancelled)
+	e.logger.Warn("nuisance detected, phantom car calls cancelled",
+		slog.String("reason", reason),
+		slog.Int("floor", floor.Value()),
+		slog.Int("boarded", boarded),
+		slog.Int("alighted", alighted),
+		slog.Int("load", load),
+		slog.Int("cancelled", cancelled))
+}
+
+// pendingDestinations counts the stops the car has to make for riders on
+// board or for car calls, leaving out pickups, hall calls and repositioning
+func (e *Elevator) pendingDestinations() int {
+	count := 0
+	for _, direction := range []domain.Direction{domain.DirectionUp, domain.DirectionDown} {
+		for _, floor := range e.directionsManager.Markers(direction) {
+			if !e.load.isHallCall(direction, floor) && !e.load.isReposition(direction, floor) {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// cancelPhantomCalls withdraws unbacked car calls, most recently pressed
+// first, until no more than limit destinations are pending, and returns how
+// many it withdrew. Stops a rider waits at or is headed for are kept.
+func (e *Elevator) cancelPhantomCalls(limit int) int {
+	pending := e.pendingDestinations()
+	cancelled := 0
+	for _, call := range e.load.phantomCalls() {
+		if pending <= limit {
+			break
+		}
+		if e.load.isBacked(call.direction, call.floor) {
+			continue
+		}
+		e.load.forget(call)
+		if e.directionsManager.RemoveStop(call.direction, domain.NewFloor(call.floor)) {
+			cancelled++
+			pending--
+		}
+	}
+	if cancelled > 0 {
+		e.notifyChange()
+	}
+	return cancelled
+}
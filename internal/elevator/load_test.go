@@ -0,0 +1,95 @@
+package elevator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+	"github.com/slavakukuyev/elevator-go/internal/events"
+)
+
+func TestElevator_EmptyStopCancelsPhantomCarCalls(t *testing.T) {
+	e := newFaultTestElevator(t, time.Second)
+	bus := events.NewBus(100)
+	e.SetEventBus(bus)
+	sub := bus.Subscribe(100)
+	defer sub.Close()
+
+	e.Request(domain.DirectionUp, domain.NewFloor(1), domain.NewFloor(8))
+	require.Eventually(t, func() bool {
+		return e.Load() == 1
+	}, 2*time.Second, 5*time.Millisecond)
+
+	// The rider on board presses three more floors
+	for _, floor := range []int{3, 4, 5} {
+		_, err := e.CarCall(domain.NewFloor(floor))
+		require.NoError(t, err)
+	}
+
+	// Nobody leaves at 3, so the last call pressed is cancelled to bring the
+	// pending stops back to what one rider explains
+	assert.Equal(t, []int{1, 3, 4, 8}, stopsUntilIdle(t, e, sub))
+	assert.Equal(t, 0, e.Load())
+}
+
+func TestElevator_RidersInIdleCarAreServed(t *testing.T) {
+	e := newFaultTestElevator(t, time.Second)
+	bus := events.NewBus(100)
+	e.SetEventBus(bus)
+	sub := bus.Subscribe(100)
+	defer sub.Close()
+
+	// Two riders already in the idle car press different floors
+	for _, floor := range []int{5, 7} {
+		_, err := e.CarCall(domain.NewFloor(floor))
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, e.Load())
+
+	assert.Equal(t, []int{5, 7}, stopsUntilIdle(t, e, sub))
+	assert.Equal(t, 0, e.Load())
+}
+
+func TestElevator_ExcessCarCallsAreCancelled(t *testing.T) {
+	e := newFaultTestElevator(t, time.Second)
+	bus := events.NewBus(100)
+	e.SetEventBus(bus)
+	sub := bus.Subscribe(100)
+	defer sub.Close()
+
+	e.Request(domain.DirectionUp, domain.NewFloor(1), domain.NewFloor(3))
+	require.Eventually(t, func() bool {
+		return e.Load() == 1
+	}, 2*time.Second, 5*time.Millisecond)
+
+	// One rider on board presses four more floors
+	for _, floor := range []int{5, 6, 7, 8} {
+		_, err := e.CarCall(domain.NewFloor(floor))
+		require.NoError(t, err)
+	}
+
+	// The rider leaves at 3, where the car calls outnumber the load
+	assert.Equal(t, []int{1, 3}, stopsUntilIdle(t, e, sub))
+	assert.Equal(t, 0, e.Load())
+}
+
+func TestElevator_HallCallRiderBacksCarCall(t *testing.T) {
+	e := newFaultTestElevator(t, time.Second)
+	bus := events.NewBus(100)
+	e.SetEventBus(bus)
+	sub := bus.Subscribe(100)
+	defer sub.Close()
+
+	e.HallCall(domain.DirectionUp, domain.NewFloor(2))
+	assert.Equal(t, []int{2}, stopsUntilIdle(t, e, sub))
+	assert.Equal(t, 1, e.Load())
+
+	// The rider's car call is served and the rider leaves
+	_, err := e.CarCall(domain.NewFloor(6))
+	require.NoError(t, err)
+	assert.Equal(t, []int{6}, stopsUntilIdle(t, e, sub))
+	assert.Equal(t, 0, e.Load())
+}
@@ -0,0 +1,359 @@
+package elevator
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+)
+
+// MotionProfile describes how a car accelerates and travels. Trips follow a
+// jerk-limited (S-curve) profile from rest to rest: the acceleration ramps up
+// at Jerk to at most Acceleration until the car reaches MaxSpeed, and back
+// down symmetrically before the car stops. Short trips never reach full
+// speed, so travel time depends on trip length rather than floor count.
+type MotionProfile struct {
+	MaxSpeed     float64 // m/s
+	Acceleration float64 // m/s²
+	Jerk         float64 // m/s³
+	FloorHeight  float64 // m
+}
+
+// Validate checks that every quantity of the profile is positive
+func (p MotionProfile) Validate() error {
+	for _, quantity := range []struct {
+		name  string
+		value float64
+	}{
+		{"max_speed", p.MaxSpeed},
+		{"acceleration", p.Acceleration},
+		{"jerk", p.Jerk},
+		{"floor_height", p.FloorHeight},
+	} {
+		if !(quantity.value > 0) || math.IsInf(quantity.value, 0) {
+			return domain.NewValidationError("motion profile values must be positive", nil).
+				WithContext(quantity.name, quantity.value)
+		}
+	}
+	return nil
+}
+
+// TravelTime returns how long the car takes to travel a number of floors
+// from rest to rest
+func (p MotionProfile) TravelTime(floors int) time.Duration {
+	if floors < 0 {
+		floors = -floors
+	}
+	return seconds(p.curve(float64(floors) * p.FloorHeight).total)
+}
+
+// sCurve is the profile of one trip from rest to rest. Accelerating takes
+// 2*rampTime+holdTime: the acceleration ramps up over rampTime, holds at
+// peakAccel for holdTime and ramps down, leaving the car at peakSpeed. The
+// car cruises for cruiseTime, then decelerates in reverse.
+type sCurve struct {
+	distance   float64
+	jerk       float64
+	peakSpeed  float64
+	peakAccel  float64
+	rampTime   float64
+	holdTime   float64
+	cruiseTime float64
+	total      float64
+}
+
+// curve plans a trip over distance meters
+func (p MotionProfile) curve(distance float64) sCurve {
+	c := sCurve{distance: distance, jerk: p.Jerk}
+	if distance <= 0 {
+		return c
+	}
+
+	// A trip too short to reach full speed peaks at the speed whose
+	// acceleration and deceleration together cover the distance
+	c.peakSpeed = p.MaxSpeed
+	if 2*p.rampDistance(p.MaxSpeed) > distance {
+		low, high := 0.0, p.MaxSpeed
+		for range 64 {
+			mid := (low + high) / 2
+			if 2*p.rampDistance(mid) > distance {
+				high = mid
+			} else {
+				low = mid
+			}
+		}
+		c.peakSpeed = low
+	}
+
+	c.rampTime, c.holdTime = p.ramp(c.peakSpeed)
+	c.peakAccel = p.Jerk * c.rampTime
+	accelTime := 2*c.rampTime + c.holdTime
+	c.cruiseTime = max(distance-2*p.rampDistance(c.peakSpeed), 0) / c.peakSpeed
+	c.total = 2*accelTime + c.cruiseTime
+	return c
+}
+
+// ramp returns the ramp and hold times of accelerating from rest to speed
+func (p MotionProfile) ramp(speed float64) (rampTime, holdTime float64) {
+	if speed*p.Jerk >= p.Acceleration*p.Acceleration {
+		return p.Acceleration / p.Jerk, speed/p.Acceleration - p.Acceleration/p.Jerk
+	}
+	return math.Sqrt(speed / p.Jerk), 0
+}
+
+// rampDistance returns the distance covered accelerating from rest to speed
+func (p MotionProfile) rampDistance(speed float64) float64 {
+	rampTime, holdTime := p.ramp(speed)
+	return speed * (2*rampTime + holdTime) / 2
+}
+
+// accelerating returns the distance covered and the speed reached t seconds
+// into the acceleration
+func (c sCurve) accelerating(t float64) (position, speed float64) {
+	j, a := c.jerk, c.peakAccel
+	if t < c.rampTime {
+		return j * t * t * t / 6, j * t * t / 2
+	}
+
+	v1 := j * c.rampTime * c.rampTime / 2
+	p1 := j * c.rampTime * c.rampTime * c.rampTime / 6
+	if t < c.rampTime+c.holdTime {
+		u := t - c.rampTime
+		return p1 + v1*u + a*u*u/2, v1 + a*u
+	}
+
+	u := min(t-c.rampTime-c.holdTime, c.rampTime)
+	v2 := v1 + a*c.holdTime
+	p2 := p1 + v1*c.holdTime + a*c.holdTime*c.holdTime/2
+	return p2 + v2*u + a*u*u/2 - j*u*u*u/6, v2 + a*u - j*u*u/2
+}
+
+// at returns the distance covered and the speed t seconds into the trip
+func (c sCurve) at(t float64) (position, speed float64) {
+	accelTime := 2*c.rampTime + c.holdTime
+	switch {
+	case t <= 0:
+		return 0, 0
+	case t >= c.total:
+		return c.distance, 0
+	case t < accelTime:
+		return c.accelerating(t)
+	case t < accelTime+c.cruiseTime:
+		rampDistance, _ := c.accelerating(accelTime)
+		return rampDistance + c.peakSpeed*(t-accelTime), c.peakSpeed
+	default:
+		position, speed := c.accelerating(c.total - t)
+		return c.distance - position, speed
+	}
+}
+
+// timeAt returns when the trip has covered a distance
+func (c sCurve) timeAt(position float64) float64 {
+	if position <= 0 {
+		return 0
+	}
+	if position >= c.distance {
+		return c.total
+	}
+	low, high := 0.0, c.total
+	for range 64 {
+		mid := (low + high) / 2
+		if covered, _ := c.at(mid); covered < position {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+	return high
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// motionRun is a run of the car from rest at one floor to rest at another
+type motionRun struct {
+	from        domain.Floor
+	destination domain.Floor
+	curve       sCurve
+	started     time.Time
+}
+
+// direction returns +1 for a run up and -1 for a run down
+func (r *motionRun) direction() int {
+	if r.destination.IsBelow(r.from) {
+		return -1
+	}
+	return 1
+}
+
+// arrival returns when the run passes a floor
+func (r *motionRun) arrival(profile MotionProfile, floor domain.Floor) time.Time {
+	floors := math.Abs(float64(floor.Value() - r.from.Value()))
+	return r.started.Add(seconds(r.curve.timeAt(floors * profile.FloorHeight)))
+}
+
+// motion tracks the run the car is on when it follows a motion profile
+type motion struct {
+	mu      sync.Mutex
+	profile *MotionProfile
+	run     *motionRun
+}
+
+// SetMotionProfile has the car travel along the profile instead of taking
+// the floor duration for every floor. It takes effect from the next run.
+func (e *Elevator) SetMotionProfile(profile MotionProfile) error {
+	if err := profile.Validate(); err != nil {
+		return err
+	}
+
+	e.motion.mu.Lock()
+	defer e.motion.mu.Unlock()
+	e.motion.profile = &profile
+	return nil
+}
+
+// MotionProfile returns the profile the car travels along, if it has one
+func (e *Elevator) MotionProfile() (MotionProfile, bool) {
+	e.motion.mu.Lock()
+	defer e.motion.mu.Unlock()
+	if e.motion.profile == nil {
+		return MotionProfile{}, false
+	}
+	return *e.motion.profile, true
+}
+
+// TravelTime estimates how long the car takes to travel a number of floors
+// from rest to rest, leaving out door stops on the way
+func (e *Elevator) TravelTime(floors int) time.Duration {
+	if profile, ok := e.MotionProfile(); ok {
+		return profile.TravelTime(floors)
+	}
+	if floors < 0 {
+		floors = -floors
+	}
+	return time.Duration(floors) * e.timing.Load().eachFloorDuration
+}
+
+// travelTime returns how long the car has to travel before arriving at the
+// adjacent floor next, heading for destination. A car at rest starts a new
+// run; when the destination changes on the way, the run is planned again
+// from its start so that the car keeps its position.
+func (e *Elevator) travelTime(profile MotionProfile, next, destination domain.Floor) time.Duration {
+	e.motion.mu.Lock()
+	defer e.motion.mu.Unlock()
+
+	now := time.Now()
+	current := e.state.CurrentFloor()
+	run := e.motion.run
+	if run == nil || (next.Value()-current.Value()) != run.direction() {
+		run = &motionRun{from: current, started: now}
+	}
+	if run.curve.total == 0 || !run.destination.IsEqual(destination) {
+		floors := math.Abs(float64(destination.Value() - run.from.Value()))
+		run.destination = destination
+		run.curve = profile.curve(floors * profile.FloorHeight)
+		// Keep the car where it is on the new curve
+		run.started = now.Add(-run.arrival(profile, current).Sub(run.started))
+	}
+	e.motion.run = run
+
+	return time.Until(run.arrival(profile, next))
+}
+
+// arrive ends the run once the car has stopped at its destination
+func (e *Elevator) arrive(floor domain.Floor) {
+	e.motion.mu.Lock()
+	defer e.motion.mu.Unlock()
+	if e.motion.run != nil && e.motion.run.destination.IsEqual(floor) {
+		e.motion.run = nil
+	}
+}
+
+// halt ends the run wherever the car is, e.g. when a step fails
+func (e *Elevator) halt() {
+	e.motion.mu.Lock()
+	defer e.motion.mu.Unlock()
+	e.motion.run = nil
+}
+
+// kinematics returns the car's position in floors and its velocity in
+// floors per second, positive when travelling up
+func (e *Elevator) kinematics(floor domain.Floor) (position, velocity float64) {
+	e.motion.mu.Lock()
+	defer e.motion.mu.Unlock()
+
+	run := e.motion.run
+	if e.motion.profile == nil || run == nil {
+		return float64(floor.Value()), 0
+	}
+	covered, speed := run.curve.at(time.Since(run.started).Seconds())
+	height := e.motion.profile.FloorHeight
+	direction := float64(run.direction())
+	position = float64(run.from.Value()) + direction*covered/height
+
+	// The car cannot be further than the floor it is travelling to
+	ahead := float64(floor.Value()) + direction
+	if direction > 0 {
+		position = min(position, ahead)
+	} else {
+		position = max(position, ahead)
+	}
+	return position, direction * speed / height
+}
+
+// runDestination returns the floor the car will stop or turn at when it
+// heads from its current floor to the adjacent floor next
+func (e *Elevator) runDestination(next domain.Floor) domain.Floor {
+	current := e.state.CurrentFloor()
+	direction := domain.DirectionUp
+	step := 1
+	if next.IsBelow(current) {
+		direction = domain.DirectionDown
+		step = -1
+	}
+
+	var target *domain.Floor
+	if call, ok := e.directionsManager.NextPriority(); ok {
+		if call.IsNonStop() {
+			return call.Target()
+		}
+		floor := call.Target()
+		target = &floor
+	}
+
+	// The run ends at the first stop on the way or at the furthest request
+	// in its direction, where the car turns
+	furthest := e.furthestRequest(direction, current)
+	for floor := next; e.state.IsFloorInRange(floor); floor = domain.NewFloor(floor.Value() + step) {
+		reachedFurthest := floor.Value() >= furthest.Value()
+		if step < 0 {
+			reachedFurthest = floor.Value() <= furthest.Value()
+		}
+		if reachedFurthest || e.HasStop(direction, floor) || (target != nil && target.IsEqual(floor)) {
+			return floor
+		}
+	}
+	return next
+}
+
+// furthestRequest returns the requested floor furthest away in a direction,
+// or from when there is none
+func (e *Elevator) furthestRequest(direction domain.Direction, from domain.Floor) domain.Floor {
+	furthest := from.Value()
+	keys := []func() (int, bool){e.directionsManager.GetLargestUpKey, e.directionsManager.GetLargestDownKey}
+	if direction == domain.DirectionDown {
+		keys = []func() (int, bool){e.directionsManager.GetSmallestUpKey, e.directionsManager.GetSmallestDownKey}
+	}
+	for _, key := range keys {
+		if floor, ok := key(); ok {
+			if direction == domain.DirectionUp {
+				furthest = max(furthest, floor)
+			} else {
+				furthest = min(furthest, floor)
+			}
+		}
+	}
+	return domain.NewFloor(furthest)
+}
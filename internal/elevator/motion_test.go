@@ -0,0 +1,128 @@
+package elevator
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+)
+
+var testProfile = MotionProfile{MaxSpeed: 2.5, Acceleration: 1, Jerk: 1.5, FloorHeight: 3.5}
+
+func TestMotionProfile_TravelTimeDependsOnTripLength(t *testing.T) {
+	assert.Zero(t, testProfile.TravelTime(0))
+	assert.Equal(t, testProfile.TravelTime(3), testProfile.TravelTime(-3))
+
+	// A long trip cruises at full speed: 2*3.17s accelerating and braking
+	// over 7.92m, the remaining 27.08m at 2.5 m/s
+	assert.InDelta(t, 17.17, testProfile.TravelTime(10).Seconds(), 0.01)
+
+	// The time per floor drops with trip length, since the car spends a
+	// smaller share of the trip accelerating and braking, towards 1.4s a
+	// floor at full speed
+	average := testProfile.TravelTime(1)
+	for floors := 2; floors <= 20; floors++ {
+		travel := testProfile.TravelTime(floors) / time.Duration(floors)
+		assert.Less(t, travel, average)
+		average = travel
+	}
+	assert.InDelta(t, 1.4, (testProfile.TravelTime(20) - testProfile.TravelTime(19)).Seconds(), 1e-6)
+}
+
+func TestMotionProfile_CurveStaysWithinLimits(t *testing.T) {
+	for _, floors := range []float64{1, 2, 10} {
+		curve := testProfile.curve(floors * testProfile.FloorHeight)
+		require.Greater(t, curve.total, 0.0)
+		if floors == 1 {
+			assert.Less(t, curve.peakSpeed, testProfile.MaxSpeed, "a single floor is too short to reach full speed")
+		}
+
+		const step = 0.001
+		lastPosition, lastSpeed := 0.0, 0.0
+		for t0 := step; t0 <= curve.total; t0 += step {
+			position, speed := curve.at(t0)
+			assert.GreaterOrEqual(t, position, lastPosition-1e-9)
+			assert.LessOrEqual(t, speed, testProfile.MaxSpeed+1e-9)
+			assert.LessOrEqual(t, math.Abs(speed-lastSpeed)/step, testProfile.Acceleration+1e-3)
+			lastPosition, lastSpeed = position, speed
+		}
+
+		position, speed := curve.at(curve.total)
+		assert.Equal(t, curve.distance, position)
+		assert.Zero(t, speed)
+		middle, _ := curve.at(curve.total / 2)
+		assert.InDelta(t, curve.distance/2, middle, 1e-6)
+		assert.InDelta(t, curve.total/2, curve.timeAt(curve.distance/2), 1e-6)
+	}
+}
+
+func TestMotionProfile_Validate(t *testing.T) {
+	assert.NoError(t, testProfile.Validate())
+
+	invalid := testProfile
+	invalid.Jerk = 0
+	err := invalid.Validate()
+	require.Error(t, err)
+	assert.Equal(t, domain.ErrTypeValidation, err.(*domain.DomainError).Type)
+
+	e := newFaultTestElevator(t, time.Second)
+	assert.Error(t, e.SetMotionProfile(invalid))
+	_, ok := e.MotionProfile()
+	assert.False(t, ok)
+}
+
+func TestElevator_MotionProfile(t *testing.T) {
+	e := newFaultTestElevator(t, time.Second)
+	profile := MotionProfile{MaxSpeed: 10, Acceleration: 40, Jerk: 400, FloorHeight: 0.5}
+	require.NoError(t, e.SetMotionProfile(profile))
+	assert.Equal(t, profile.TravelTime(5), e.TravelTime(5))
+
+	status := e.GetStatus()
+	assert.Equal(t, 0.0, status.Position)
+	assert.Zero(t, status.Velocity)
+
+	start := time.Now()
+	e.Request(domain.DirectionUp, domain.NewFloor(0), domain.NewFloor(5))
+
+	// The car reports where it is between floors while it travels
+	var moving domain.ElevatorStatus
+	require.Eventually(t, func() bool {
+		moving = e.GetStatus()
+		return moving.Velocity > 0 && moving.Position != math.Trunc(moving.Position)
+	}, 2*time.Second, time.Millisecond)
+	assert.InDelta(t, float64(moving.CurrentFloor.Value()), moving.Position, 1)
+
+	// The run takes as long as the profile says, rather than a floor
+	// duration per floor
+	require.Eventually(t, func() bool {
+		return !e.HasPendingRequests() && e.CurrentFloor().Value() == 5
+	}, 5*time.Second, time.Millisecond)
+	assert.GreaterOrEqual(t, time.Since(start), profile.TravelTime(5))
+
+	status = e.GetStatus()
+	assert.Equal(t, 5.0, status.Position)
+	assert.Zero(t, status.Velocity)
+}
+
+func TestElevator_MotionProfileStopsOnTheWay(t *testing.T) {
+	e := newFaultTestElevator(t, time.Second)
+	profile := MotionProfile{MaxSpeed: 10, Acceleration: 40, Jerk: 400, FloorHeight: 0.5}
+	require.NoError(t, e.SetMotionProfile(profile))
+
+	e.Request(domain.DirectionUp, domain.NewFloor(0), domain.NewFloor(6))
+	e.Request(domain.DirectionUp, domain.NewFloor(3), domain.NewFloor(6))
+
+	// The first run ends at the pickup on floor 3
+	require.Eventually(t, func() bool { return e.CurrentFloor().Value() == 3 }, 2*time.Second, time.Millisecond)
+	require.Eventually(t, func() bool { return e.GetStatus().DoorOpen }, time.Second, time.Millisecond)
+	assert.Equal(t, 3.0, e.GetStatus().Position)
+	assert.Zero(t, e.GetStatus().Velocity)
+
+	require.Eventually(t, func() bool {
+		return !e.HasPendingRequests() && e.CurrentFloor().Value() == 6
+	}, 5*time.Second, time.Millisecond)
+}
@@ -0,0 +1,88 @@
+package elevator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+	"github.com/slavakukuyev/elevator-go/internal/events"
+)
+
+// stopsUntilIdle collects the floors where the doors opened until the
+// elevator has no requests left
+func stopsUntilIdle(t *testing.T, e *Elevator, sub *events.Subscription) []int {
+	t.Helper()
+	var stops []int
+	deadline := time.After(3 * time.Second)
+	for {
+		select {
+		case event := <-sub.C:
+			if event.Type == events.TypeFloorArrived {
+				stops = append(stops, event.Data["floor"].(int))
+			}
+		case <-time.After(50 * time.Millisecond):
+			if !e.HasPendingRequests() {
+				return stops
+			}
+		case <-deadline:
+			t.Fatalf("elevator did not finish, stopped at %v", stops)
+		}
+	}
+}
+
+func TestElevator_EmergencyCallRunsNonStop(t *testing.T) {
+	e := newFaultTestElevator(t, time.Second)
+	bus := events.NewBus(100)
+	e.SetEventBus(bus)
+	sub := bus.Subscribe(100)
+	defer sub.Close()
+
+	e.Request(domain.DirectionUp, domain.NewFloor(2), domain.NewFloor(3))
+	e.RequestCall(domain.DirectionUp, domain.NewFloor(4), domain.NewFloor(7), domain.CallOptions{Priority: domain.PriorityEmergency})
+	assert.True(t, e.IsReserved())
+
+	// The car passes the waiting passengers at floor 2 on its way to the
+	// emergency call and only serves them afterwards
+	assert.Equal(t, []int{4, 7, 2, 3}, stopsUntilIdle(t, e, sub))
+	assert.False(t, e.IsReserved())
+	assert.Equal(t, domain.DirectionIdle, e.CurrentDirection())
+}
+
+func TestElevator_HighPriorityCallServedFirst(t *testing.T) {
+	e := newFaultTestElevator(t, time.Second)
+	bus := events.NewBus(100)
+	e.SetEventBus(bus)
+	sub := bus.Subscribe(100)
+	defer sub.Close()
+
+	e.Request(domain.DirectionUp, domain.NewFloor(1), domain.NewFloor(9))
+	e.RequestCall(domain.DirectionDown, domain.NewFloor(5), domain.NewFloor(3), domain.CallOptions{Priority: domain.PriorityHigh})
+	assert.False(t, e.IsReserved())
+
+	// The car still picks up travellers going its way, but takes the VIP
+	// guest to floor 3 before finishing the upward trip
+	assert.Equal(t, []int{1, 5, 3, 9}, stopsUntilIdle(t, e, sub))
+}
+
+func TestElevator_ReservedCarDropsOffRidersAfterEmergency(t *testing.T) {
+	e := newFaultTestElevator(t, time.Second)
+	bus := events.NewBus(100)
+	e.SetEventBus(bus)
+	sub := bus.Subscribe(100)
+	defer sub.Close()
+
+	// A rider boards at floor 1 headed for floor 5
+	e.Request(domain.DirectionUp, domain.NewFloor(1), domain.NewFloor(5))
+	require.Eventually(t, func() bool { return e.Load() == 1 }, 3*time.Second, 5*time.Millisecond)
+
+	// The emergency run passes the rider's floor, who is dropped off once
+	// the call is served instead of being forgotten
+	e.RequestCall(domain.DirectionUp, domain.NewFloor(6), domain.NewFloor(9), domain.CallOptions{Priority: domain.PriorityEmergency})
+	assert.True(t, e.IsReserved())
+	assert.Equal(t, []int{1, 6, 9, 5}, stopsUntilIdle(t, e, sub))
+	assert.False(t, e.IsReserved())
+	assert.Equal(t, 0, e.Load())
+}
@@ -10,16 +10,21 @@ import (
 type State struct {
 	mu           sync.RWMutex
 	name         string
+	building     string
+	elevatorType domain.ElevatorType
 	currentFloor domain.Floor
 	direction    domain.Direction
+	doorOpen     bool
 	minFloor     domain.Floor
 	maxFloor     domain.Floor
+	onChange     func() // invoked outside the lock after a visible change
 }
 
 // NewState creates a new elevator state
 func NewState(name string, minFloor, maxFloor domain.Floor) *State {
 	return &State{
 		name:         name,
+		elevatorType: domain.ElevatorTypePassenger,
 		currentFloor: minFloor, // Start at minimum floor
 		direction:    domain.DirectionIdle,
 		minFloor:     minFloor,
@@ -39,6 +44,34 @@ func (s *State) SetName(name string) {
 	s.name = name
 }
 
+// Building returns the ID of the building the elevator serves
+func (s *State) Building() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.building
+}
+
+// SetBuilding sets the ID of the building the elevator serves
+func (s *State) SetBuilding(building string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.building = building
+}
+
+// Type returns the type of the elevator
+func (s *State) Type() domain.ElevatorType {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.elevatorType
+}
+
+// SetType sets the type of the elevator
+func (s *State) SetType(elevatorType domain.ElevatorType) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.elevatorType = elevatorType
+}
+
 // CurrentFloor returns the current floor
 func (s *State) CurrentFloor() domain.Floor {
 	s.mu.RLock()
@@ -46,11 +79,25 @@ func (s *State) CurrentFloor() domain.Floor {
 	return s.currentFloor
 }
 
+// SetOnChange registers a callback invoked whenever floor, direction or door
+// state changes
+func (s *State) SetOnChange(fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onChange = fn
+}
+
 // SetCurrentFloor sets the current floor
 func (s *State) SetCurrentFloor(floor domain.Floor) {
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	changed := s.currentFloor != floor
 	s.currentFloor = floor
+	onChange := s.onChange
+	s.mu.Unlock()
+
+	if changed && onChange != nil {
+		onChange()
+	}
 }
 
 // Direction returns the current direction
@@ -63,8 +110,34 @@ func (s *State) Direction() domain.Direction {
 // SetDirection sets the current direction
 func (s *State) SetDirection(direction domain.Direction) {
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	changed := s.direction != direction
 	s.direction = direction
+	onChange := s.onChange
+	s.mu.Unlock()
+
+	if changed && onChange != nil {
+		onChange()
+	}
+}
+
+// DoorOpen reports whether the car doors are currently open
+func (s *State) DoorOpen() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.doorOpen
+}
+
+// SetDoorOpen records the car door state
+func (s *State) SetDoorOpen(open bool) {
+	s.mu.Lock()
+	changed := s.doorOpen != open
+	s.doorOpen = open
+	onChange := s.onChange
+	s.mu.Unlock()
+
+	if changed && onChange != nil {
+		onChange()
+	}
 }
 
 // MinFloor returns the minimum floor
@@ -101,7 +174,7 @@ func (s *State) GetStatus(requestCount int) domain.ElevatorStatus {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	return domain.NewElevatorStatus(
+	status := domain.NewElevatorStatus(
 		s.name,
 		s.currentFloor,
 		s.direction,
@@ -109,4 +182,7 @@ func (s *State) GetStatus(requestCount int) domain.ElevatorStatus {
 		s.minFloor,
 		s.maxFloor,
 	)
+	status.Type = s.elevatorType
+	status.DoorOpen = s.doorOpen
+	return status
 }
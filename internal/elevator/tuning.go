@@ -0,0 +1,164 @@
+package elevator
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/slavakukuyev/elevator-go/internal/circuitbreaker"
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+)
+
+// timing holds the durations a Run step simulates
+type timing struct {
+	eachFloorDuration time.Duration
+	openDoorDuration  time.Duration
+}
+
+// Tuning holds the settings of an elevator that can change while it runs
+type Tuning struct {
+	EachFloorDuration time.Duration
+	OpenDoorDuration  time.Duration
+	OverloadThreshold int
+	CircuitBreaker    circuitbreaker.Settings
+}
+
+// Validate checks that the tuning can be applied to an elevator
+func (t Tuning) Validate() error {
+	if t.EachFloorDuration <= 0 {
+		return domain.NewValidationError("each floor duration must be positive", nil).
+			WithContext("each_floor_duration", t.EachFloorDuration.String())
+	}
+
+	if t.OpenDoorDuration <= 0 {
+		return domain.NewValidationError("open door duration must be positive", nil).
+			WithContext("open_door_duration", t.OpenDoorDuration.String())
+	}
+
+	if t.OverloadThreshold <= 0 || t.OverloadThreshold > 100 {
+		return domain.NewValidationError("overload threshold must be between 1 and 100", nil).
+			WithContext("overload_threshold", t.OverloadThreshold)
+	}
+
+	cb := t.CircuitBreaker
+	if cb.MaxFailures <= 0 || cb.MaxFailures > 100 {
+		return domain.NewValidationError("circuit breaker max failures must be between 1 and 100", nil).
+			WithContext("max_failures", cb.MaxFailures)
+	}
+
+	if cb.ResetTimeout <= 0 {
+		return domain.NewValidationError("circuit breaker reset timeout must be positive", nil).
+			WithContext("reset_timeout", cb.ResetTimeout.String())
+	}
+
+	if cb.HalfOpenLimit <= 0 || cb.HalfOpenLimit > 50 {
+		return domain.NewValidationError("circuit breaker half open limit must be between 1 and 50", nil).
+			WithContext("half_open_limit", cb.HalfOpenLimit)
+	}
+
+	if cb.FailureThreshold < 0 || cb.FailureThreshold > 1 {
+		return domain.NewValidationError("circuit breaker failure threshold must be between 0 and 1", nil).
+			WithContext("failure_threshold", cb.FailureThreshold)
+	}
+
+	if cb.Window < 0 {
+		return domain.NewValidationError("circuit breaker window cannot be negative", nil).
+			WithContext("window", cb.Window.String())
+	}
+
+	if cb.MinRequests < 0 {
+		return domain.NewValidationError("circuit breaker min requests cannot be negative", nil).
+			WithContext("min_requests", cb.MinRequests)
+	}
+
+	return nil
+}
+
+// TuningPatch changes some settings of a Tuning; nil fields keep their value
+type TuningPatch struct {
+	EachFloorDuration *time.Duration
+	OpenDoorDuration  *time.Duration
+	OverloadThreshold *int
+	CircuitBreaker    CircuitBreakerPatch
+}
+
+// CircuitBreakerPatch changes some circuit breaker settings; nil fields keep
+// their value
+type CircuitBreakerPatch struct {
+	MaxFailures      *int
+	ResetTimeout     *time.Duration
+	HalfOpenLimit    *int
+	FailureThreshold *float64
+	Window           *time.Duration
+	MinRequests      *int
+}
+
+// Apply returns t with the patched settings replaced
+func (p TuningPatch) Apply(t Tuning) Tuning {
+	setIfPresent(&t.EachFloorDuration, p.EachFloorDuration)
+	setIfPresent(&t.OpenDoorDuration, p.OpenDoorDuration)
+	setIfPresent(&t.OverloadThreshold, p.OverloadThreshold)
+
+	cb := p.CircuitBreaker
+	setIfPresent(&t.CircuitBreaker.MaxFailures, cb.MaxFailures)
+	setIfPresent(&t.CircuitBreaker.ResetTimeout, cb.ResetTimeout)
+	setIfPresent(&t.CircuitBreaker.HalfOpenLimit, cb.HalfOpenLimit)
+	setIfPresent(&t.CircuitBreaker.FailureThreshold, cb.FailureThreshold)
+	setIfPresent(&t.CircuitBreaker.Window, cb.Window)
+	setIfPresent(&t.CircuitBreaker.MinRequests, cb.MinRequests)
+	return t
+}
+
+// HasCircuitBreaker reports whether the patch changes circuit breaker settings
+func (p TuningPatch) HasCircuitBreaker() bool {
+	return p.CircuitBreaker != CircuitBreakerPatch{}
+}
+
+func setIfPresent[T any](dst *T, value *T) {
+	if value != nil {
+		*dst = *value
+	}
+}
+
+// Tuning returns the current settings of the elevator
+func (e *Elevator) Tuning() Tuning {
+	timing := e.timing.Load()
+	return Tuning{
+		EachFloorDuration: timing.eachFloorDuration,
+		OpenDoorDuration:  timing.openDoorDuration,
+		OverloadThreshold: e.OverloadThreshold(),
+		CircuitBreaker:    e.circuitBreaker.Settings(),
+	}
+}
+
+// Tune validates and applies a patch to the settings of a running elevator.
+// Travel and door durations apply from the next Run step, so a step in
+// progress finishes with the durations it started with; the overload
+// threshold and circuit breaker settings apply immediately, keeping the
+// breaker state.
+func (e *Elevator) Tune(patch TuningPatch) (Tuning, error) {
+	e.tuneMu.Lock()
+	defer e.tuneMu.Unlock()
+
+	current := e.Tuning()
+	tuned := patch.Apply(current)
+	if err := tuned.Validate(); err != nil {
+		return current, err
+	}
+
+	e.timing.Store(&timing{
+		eachFloorDuration: tuned.EachFloorDuration,
+		openDoorDuration:  tuned.OpenDoorDuration,
+	})
+	e.SetOverloadThreshold(tuned.OverloadThreshold)
+	if patch.HasCircuitBreaker() {
+		e.SetCircuitBreakerSettings(tuned.CircuitBreaker)
+	}
+
+	e.logger.Info("elevator tuned",
+		slog.Duration("floor_duration", tuned.EachFloorDuration),
+		slog.Duration("door_duration", tuned.OpenDoorDuration),
+		slog.Int("overload_threshold", tuned.OverloadThreshold),
+		slog.Int("circuit_breaker_max_failures", tuned.CircuitBreaker.MaxFailures),
+		slog.Duration("circuit_breaker_reset_timeout", tuned.CircuitBreaker.ResetTimeout))
+	return tuned, nil
+}
@@ -0,0 +1,73 @@
+package elevator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slavakukuyev/elevator-go/internal/circuitbreaker"
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+)
+
+func TestElevator_Tune(t *testing.T) {
+	e := newFaultTestElevator(t, time.Second)
+
+	assert.Equal(t, Tuning{
+		EachFloorDuration: 10 * time.Millisecond,
+		OpenDoorDuration:  10 * time.Millisecond,
+		OverloadThreshold: 12,
+		CircuitBreaker: circuitbreaker.Settings{
+			MaxFailures:   2,
+			ResetTimeout:  time.Second,
+			HalfOpenLimit: 1,
+		},
+	}, e.Tuning())
+
+	floor := 50 * time.Millisecond
+	threshold := 20
+	maxFailures := 5
+	tuned, err := e.Tune(TuningPatch{
+		EachFloorDuration: &floor,
+		OverloadThreshold: &threshold,
+		CircuitBreaker:    CircuitBreakerPatch{MaxFailures: &maxFailures},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, floor, tuned.EachFloorDuration)
+	assert.Equal(t, 10*time.Millisecond, tuned.OpenDoorDuration)
+	assert.Equal(t, 20, e.OverloadThreshold())
+	assert.Equal(t, 5, e.Tuning().CircuitBreaker.MaxFailures)
+	assert.Equal(t, time.Second, e.Tuning().CircuitBreaker.ResetTimeout)
+
+	// The next step travels with the new duration
+	start := time.Now()
+	require.NoError(t, e.Run())
+	assert.GreaterOrEqual(t, time.Since(start), floor)
+}
+
+func TestElevator_TuneRejectsInvalidSettings(t *testing.T) {
+	e := newFaultTestElevator(t, time.Second)
+	before := e.Tuning()
+
+	zero := time.Duration(0)
+	threshold := 101
+	failureThreshold := 1.5
+
+	for _, patch := range []TuningPatch{
+		{EachFloorDuration: &zero},
+		{OpenDoorDuration: &zero},
+		{OverloadThreshold: &threshold},
+		{CircuitBreaker: CircuitBreakerPatch{ResetTimeout: &zero}},
+		{CircuitBreaker: CircuitBreakerPatch{FailureThreshold: &failureThreshold}},
+	} {
+		_, err := e.Tune(patch)
+		require.Error(t, err)
+		domainErr, ok := err.(*domain.DomainError)
+		require.True(t, ok)
+		assert.Equal(t, domain.ErrTypeValidation, domainErr.Type)
+	}
+
+	// Nothing is applied when validation fails
+	assert.Equal(t, before, e.Tuning())
+}
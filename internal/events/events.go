@@ -0,0 +1,244 @@
+// Package events provides an in-process publish/subscribe bus for typed
+// elevator system events.
+//
+// Every published event receives a monotonically increasing ID and is kept
+// in a bounded history so that streaming clients can resume after a
+// reconnect (for example via the SSE Last-Event-ID header). Subscribers get
+// a buffered channel; a subscriber that falls behind far enough to fill its
+// buffer is closed rather than allowed to block publishers, and is expected
+// to resubscribe from the last ID it saw.
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// Type identifies the kind of event
+type Type string
+
+const (
+	// TypeRequestAssigned is published when a floor request is assigned to an elevator
+	TypeRequestAssigned Type = "request_assigned"
+	// TypeRequestPickedUp is published when passengers of a request board the car
+	TypeRequestPickedUp Type = "request_picked_up"
+	// TypeRequestCompleted is published when a request reaches its destination
+	TypeRequestCompleted Type = "request_completed"
+	// TypeRequestCancelled is published when a request is withdrawn before pickup
+	TypeRequestCancelled Type = "request_cancelled"
+	// TypeRequestRejected is published when no elevator can take a valid
+	// floor request
+	TypeRequestRejected Type = "request_rejected"
+	// TypeFloorArrived is published when an elevator stops at a floor to serve it
+	TypeFloorArrived Type = "floor_arrived"
+	// TypeStopServed is published when riders have alighted and boarded at a
+	// stop, before the doors close
+	TypeStopServed Type = "stop_served"
+	// TypeElevatorAdded is published when an elevator joins the pool
+	TypeElevatorAdded Type = "elevator_added"
+	// TypeElevatorRemoved is published when an elevator leaves the pool
+	TypeElevatorRemoved Type = "elevator_removed"
+	// TypeElevatorFault is published when an elevator operation fails
+	TypeElevatorFault Type = "elevator_fault"
+	// TypeElevatorRecovered is published when a failing elevator's circuit
+	// breaker closes again
+	TypeElevatorRecovered Type = "elevator_recovered"
+	// TypeCircuitBreakerOpened is published when an elevator's circuit breaker
+	// opens after repeated failures and the car stops taking requests
+	TypeCircuitBreakerOpened Type = "circuit_breaker_opened"
+	// TypeAnnouncement is published for kiosk UIs to voice when a car stops
+	// for a rider with mobility needs
+	TypeAnnouncement Type = "announcement"
+	// TypeSLABreached is published when a service-level indicator exceeds the
+	// objective of an SLA rule
+	TypeSLABreached Type = "sla_breached"
+	// TypeSLAResolved is published when a breached SLA rule is met again
+	TypeSLAResolved Type = "sla_resolved"
+)
+
+// knownTypes lists every type of event the system publishes
+var knownTypes = map[Type]bool{
+	TypeRequestAssigned:      true,
+	TypeRequestPickedUp:      true,
+	TypeRequestCompleted:     true,
+	TypeRequestCancelled:     true,
+	TypeRequestRejected:      true,
+	TypeFloorArrived:         true,
+	TypeStopServed:           true,
+	TypeElevatorAdded:        true,
+	TypeElevatorRemoved:      true,
+	TypeElevatorFault:        true,
+	TypeElevatorRecovered:    true,
+	TypeCircuitBreakerOpened: true,
+	TypeAnnouncement:         true,
+	TypeSLABreached:          true,
+	TypeSLAResolved:          true,
+}
+
+// Known reports whether the system publishes events of type t
+func Known(t Type) bool {
+	return knownTypes[t]
+}
+
+// DefaultHistorySize is used when a bus is created with a non-positive capacity
+const DefaultHistorySize = 1000
+
+// Event is a single typed occurrence in the elevator system
+type Event struct {
+	ID        uint64         `json:"id"`
+	Type      Type           `json:"type"`
+	Timestamp time.Time      `json:"timestamp"`
+	Elevator  string         `json:"elevator,omitempty"`
+	Data      map[string]any `json:"data,omitempty"`
+}
+
+// Subscription delivers events published after it was created. C is closed
+// when the subscription is cancelled or the subscriber falls behind.
+type Subscription struct {
+	C <-chan Event
+
+	bus *Bus
+	id  uint64
+}
+
+// Close cancels the subscription. It is safe to call more than once.
+func (s *Subscription) Close() {
+	s.bus.unsubscribe(s.id)
+}
+
+// Bus fans out events to subscribers and retains a bounded history
+type Bus struct {
+	mu          sync.RWMutex
+	lastID      uint64
+	history     []Event // ring buffer ordered from head
+	head        int
+	size        int
+	subscribers map[uint64]chan Event
+	nextSubID   uint64
+}
+
+// NewBus creates a bus that retains up to historySize events
+func NewBus(historySize int) *Bus {
+	if historySize <= 0 {
+		historySize = DefaultHistorySize
+	}
+	return &Bus{
+		history:     make([]Event, historySize),
+		subscribers: make(map[uint64]chan Event),
+	}
+}
+
+// Publish assigns the next ID to an event, records it in the history and
+// delivers it to all subscribers
+func (b *Bus) Publish(eventType Type, elevator string, data map[string]any) Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastID++
+	event := Event{
+		ID:        b.lastID,
+		Type:      eventType,
+		Timestamp: time.Now(),
+		Elevator:  elevator,
+		Data:      data,
+	}
+
+	capacity := len(b.history)
+	b.history[(b.head+b.size)%capacity] = event
+	if b.size < capacity {
+		b.size++
+	} else {
+		b.head = (b.head + 1) % capacity
+	}
+
+	for id, ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+			// Slow subscriber: disconnect it so it can resume from history
+			delete(b.subscribers, id)
+			close(ch)
+		}
+	}
+
+	return event
+}
+
+// Subscribe registers a subscriber with the given channel buffer size
+func (b *Bus) Subscribe(buffer int) *Subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.register(buffer)
+}
+
+// SubscribeSince atomically returns the retained events with an ID greater
+// than lastID and registers a subscriber for everything after them. The
+// complete flag is false when events after lastID have already been evicted
+// from the history, so the backlog has a gap.
+func (b *Bus) SubscribeSince(lastID uint64, buffer int) (sub *Subscription, backlog []Event, complete bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	backlog, complete = b.since(lastID)
+	return b.register(buffer), backlog, complete
+}
+
+// Since returns the retained events with an ID greater than lastID
+func (b *Bus) Since(lastID uint64) ([]Event, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.since(lastID)
+}
+
+// LastID returns the ID of the most recently published event
+func (b *Bus) LastID() uint64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.lastID
+}
+
+// register adds a subscriber; callers must hold the write lock
+func (b *Bus) register(buffer int) *Subscription {
+	if buffer <= 0 {
+		buffer = 1
+	}
+	b.nextSubID++
+	ch := make(chan Event, buffer)
+	b.subscribers[b.nextSubID] = ch
+	return &Subscription{C: ch, bus: b, id: b.nextSubID}
+}
+
+// since collects retained events after lastID; callers must hold a lock
+func (b *Bus) since(lastID uint64) ([]Event, bool) {
+	if lastID == b.lastID {
+		return nil, true
+	}
+	if lastID > b.lastID {
+		// The ID was issued by an earlier process; nothing can be replayed
+		return nil, false
+	}
+
+	var oldest uint64
+	if b.size > 0 {
+		oldest = b.history[b.head].ID
+	}
+	complete := b.size > 0 && lastID+1 >= oldest
+
+	events := make([]Event, 0, b.size)
+	for i := 0; i < b.size; i++ {
+		event := b.history[(b.head+i)%len(b.history)]
+		if event.ID > lastID {
+			events = append(events, event)
+		}
+	}
+	return events, complete
+}
+
+func (b *Bus) unsubscribe(id uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if ch, ok := b.subscribers[id]; ok {
+		delete(b.subscribers, id)
+		close(ch)
+	}
+}
@@ -0,0 +1,109 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBus_PublishAssignsSequentialIDs(t *testing.T) {
+	bus := NewBus(10)
+
+	first := bus.Publish(TypeElevatorAdded, "A", nil)
+	second := bus.Publish(TypeRequestAssigned, "A", map[string]any{"from_floor": 1, "to_floor": 5})
+
+	assert.Equal(t, uint64(1), first.ID)
+	assert.Equal(t, uint64(2), second.ID)
+	assert.Equal(t, uint64(2), bus.LastID())
+	assert.Equal(t, TypeRequestAssigned, second.Type)
+	assert.False(t, second.Timestamp.IsZero())
+}
+
+func TestBus_SubscribeReceivesNewEvents(t *testing.T) {
+	bus := NewBus(10)
+	bus.Publish(TypeElevatorAdded, "A", nil)
+
+	sub := bus.Subscribe(4)
+	defer sub.Close()
+
+	bus.Publish(TypeFloorArrived, "A", map[string]any{"floor": 3})
+
+	event := <-sub.C
+	assert.Equal(t, uint64(2), event.ID)
+	assert.Equal(t, TypeFloorArrived, event.Type)
+	assert.Empty(t, sub.C)
+}
+
+func TestBus_SubscribeSinceReplaysBacklog(t *testing.T) {
+	bus := NewBus(10)
+	for i := 0; i < 5; i++ {
+		bus.Publish(TypeFloorArrived, "A", map[string]any{"floor": i})
+	}
+
+	sub, backlog, complete := bus.SubscribeSince(2, 4)
+	defer sub.Close()
+
+	require.Len(t, backlog, 3)
+	assert.True(t, complete)
+	assert.Equal(t, uint64(3), backlog[0].ID)
+	assert.Equal(t, uint64(5), backlog[2].ID)
+
+	bus.Publish(TypeElevatorRemoved, "A", nil)
+	assert.Equal(t, uint64(6), (<-sub.C).ID)
+}
+
+func TestBus_HistoryIsBounded(t *testing.T) {
+	bus := NewBus(3)
+	for i := 0; i < 5; i++ {
+		bus.Publish(TypeFloorArrived, "A", nil)
+	}
+
+	events, complete := bus.Since(0)
+	require.Len(t, events, 3)
+	assert.False(t, complete, "events 1 and 2 were evicted")
+	assert.Equal(t, uint64(3), events[0].ID)
+
+	events, complete = bus.Since(2)
+	assert.Len(t, events, 3)
+	assert.True(t, complete)
+
+	events, complete = bus.Since(5)
+	assert.Empty(t, events)
+	assert.True(t, complete)
+}
+
+func TestBus_SinceUnknownFutureID(t *testing.T) {
+	bus := NewBus(3)
+	bus.Publish(TypeFloorArrived, "A", nil)
+
+	events, complete := bus.Since(42)
+	assert.Empty(t, events)
+	assert.False(t, complete)
+}
+
+func TestBus_SlowSubscriberIsDisconnected(t *testing.T) {
+	bus := NewBus(10)
+	sub := bus.Subscribe(1)
+
+	bus.Publish(TypeFloorArrived, "A", nil)
+	bus.Publish(TypeFloorArrived, "A", nil) // buffer full, subscriber dropped
+
+	event, ok := <-sub.C
+	assert.True(t, ok)
+	assert.Equal(t, uint64(1), event.ID)
+
+	_, ok = <-sub.C
+	assert.False(t, ok, "channel should be closed after overflow")
+
+	// Closing an already dropped subscription is a no-op
+	sub.Close()
+}
+
+func TestKnown(t *testing.T) {
+	assert.True(t, Known(TypeRequestCompleted))
+	assert.True(t, Known(TypeCircuitBreakerOpened))
+	assert.True(t, Known(TypeSLABreached))
+	assert.False(t, Known("request_delivered"))
+	assert.False(t, Known(""))
+}
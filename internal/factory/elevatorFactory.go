@@ -1,8 +1,11 @@
 package factory
 
 import (
+	"context"
 	"time"
 
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+	"github.com/slavakukuyev/elevator-go/internal/drive"
 	"github.com/slavakukuyev/elevator-go/internal/elevator"
 	"github.com/slavakukuyev/elevator-go/internal/infra/config"
 )
@@ -11,6 +14,68 @@ type ElevatorFactory interface {
 	CreateElevator(cfg *config.Config, name string,
 		minFloor, maxFloor int,
 		eachFloorDuration, openDoorDuration time.Duration, overloadThreshold int) (*elevator.Elevator, error)
+	CreateTypedElevator(cfg *config.Config, elevatorType domain.ElevatorType, name string,
+		minFloor, maxFloor int,
+		eachFloorDuration, openDoorDuration time.Duration, overloadThreshold int) (*elevator.Elevator, error)
+}
+
+// TypeProfile describes how cars of a type differ from passenger cars
+type TypeProfile struct {
+	// FloorDurationFactor scales the travel time per floor; above 1 is slower
+	FloorDurationFactor float64
+	// DoorDurationFactor scales how long the doors stay open
+	DoorDurationFactor float64
+	// CapacityFactor scales the overload threshold
+	CapacityFactor float64
+}
+
+// typeProfiles holds the profile of every elevator type. Freight cars move
+// slowly and keep their doors open for loading but carry few loads at once;
+// service cars sit in between.
+var typeProfiles = map[domain.ElevatorType]TypeProfile{
+	domain.ElevatorTypePassenger: {FloorDurationFactor: 1, DoorDurationFactor: 1, CapacityFactor: 1},
+	domain.ElevatorTypeFreight:   {FloorDurationFactor: 2, DoorDurationFactor: 3, CapacityFactor: 0.5},
+	domain.ElevatorTypeService:   {FloorDurationFactor: 1, DoorDurationFactor: 1.5, CapacityFactor: 0.75},
+}
+
+// Profile returns the profile of an elevator type; unknown types behave like
+// passenger cars
+func Profile(elevatorType domain.ElevatorType) TypeProfile {
+	if profile, ok := typeProfiles[elevatorType]; ok {
+		return profile
+	}
+	return typeProfiles[domain.ElevatorTypePassenger]
+}
+
+// Apply scales passenger car settings to the profile
+func (p TypeProfile) Apply(settings config.ElevatorSettings) config.ElevatorSettings {
+	settings.EachFloorDuration = time.Duration(float64(settings.EachFloorDuration) * p.FloorDurationFactor)
+	settings.OpenDoorDuration = time.Duration(float64(settings.OpenDoorDuration) * p.DoorDurationFactor)
+	settings.OverloadThreshold = max(int(float64(settings.OverloadThreshold)*p.CapacityFactor), 1)
+	return settings
+}
+
+// DefaultSettings returns the settings of a new elevator: the configured
+// defaults scaled to the type's profile, with the per-elevator overrides from
+// the configuration file applied last
+func DefaultSettings(cfg *config.Config, elevatorType domain.ElevatorType, name string) config.ElevatorSettings {
+	settings := Profile(elevatorType).Apply(config.ElevatorSettings{
+		EachFloorDuration: cfg.EachFloorDuration,
+		OpenDoorDuration:  cfg.OpenDoorDuration,
+		OverloadThreshold: cfg.DefaultOverloadThreshold,
+	})
+	return cfg.Elevators[name].Apply(settings)
+}
+
+// MotionProfile returns the configured motion profile scaled to the type's
+// profile: cars that take longer per floor travel at a lower top speed
+func MotionProfile(cfg *config.Config, elevatorType domain.ElevatorType) elevator.MotionProfile {
+	return elevator.MotionProfile{
+		MaxSpeed:     cfg.MotionMaxSpeed / Profile(elevatorType).FloorDurationFactor,
+		Acceleration: cfg.MotionAcceleration,
+		Jerk:         cfg.MotionJerk,
+		FloorHeight:  cfg.MotionFloorHeight,
+	}
 }
 
 type StandardElevatorFactory struct{}
@@ -19,8 +84,60 @@ func (f StandardElevatorFactory) CreateElevator(cfg *config.Config, name string,
 	minFloor, maxFloor int,
 	eachFloorDuration, openDoorDuration time.Duration, overloadThreshold int) (*elevator.Elevator, error) {
 
-	return elevator.New(name,
+	return f.CreateTypedElevator(cfg, domain.ElevatorTypePassenger, name,
+		minFloor, maxFloor,
+		eachFloorDuration, openDoorDuration, overloadThreshold)
+}
+
+// CreateTypedElevator creates an elevator of the given type. The durations
+// and threshold are used as given; DefaultSettings derives them from the
+// type's profile.
+func (f StandardElevatorFactory) CreateTypedElevator(cfg *config.Config, elevatorType domain.ElevatorType, name string,
+	minFloor, maxFloor int,
+	eachFloorDuration, openDoorDuration time.Duration, overloadThreshold int) (*elevator.Elevator, error) {
+
+	if !elevatorType.IsValid() {
+		return nil, domain.NewValidationError("unknown elevator type", nil).
+			WithContext("type", string(elevatorType))
+	}
+
+	e, err := elevator.NewWithCircuitBreaker(name,
 		minFloor, maxFloor,
 		eachFloorDuration, openDoorDuration, cfg.OperationTimeout,
-		cfg.CircuitBreakerMaxFailures, cfg.CircuitBreakerResetTimeout, cfg.CircuitBreakerHalfOpenLimit, overloadThreshold)
+		cfg.CircuitBreakerSettings(), overloadThreshold)
+	if err != nil {
+		return nil, err
+	}
+	e.SetType(elevatorType).SetBuilding(cfg.BuildingID)
+
+	if cfg.MotionModel == config.MotionModelKinematic {
+		if err := e.SetMotionProfile(MotionProfile(cfg, elevatorType)); err != nil {
+			e.Shutdown()
+			return nil, err
+		}
+	}
+
+	if cfg.DriveBackend == config.DriveBackendLine {
+		if err := attachLineDrive(cfg, e); err != nil {
+			e.Shutdown()
+			return nil, err
+		}
+	}
+	return e, nil
+}
+
+// attachLineDrive connects the elevator to its car on the configured device
+func attachLineDrive(cfg *config.Config, e *elevator.Elevator) error {
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.DriveTimeout)
+	defer cancel()
+
+	line, err := drive.Dial(ctx, cfg.DriveAddress, e.Name(), cfg.DriveTimeout)
+	if err != nil {
+		return err
+	}
+	if err := e.SetDrive(ctx, line); err != nil {
+		_ = line.Close()
+		return err
+	}
+	return nil
 }
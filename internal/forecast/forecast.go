@@ -0,0 +1,212 @@
+// Package forecast learns when and where calls are made and places idle cars
+// ahead of the demand it expects.
+//
+// A Predictor divides the week into slots of a fixed length and keeps, for
+// every slot and floor, an exponentially smoothed count of the calls made
+// from the floor during that slot in past weeks: each time a slot ends, its
+// rate moves towards the count just observed by the smoothing factor. The
+// forecast for a coming period is the sum of the rates of the slots it
+// covers, so a surge at the lobby every weekday at 8:45 shows up in the
+// forecast of every following Monday to Friday at that time.
+//
+// A Planner trains one predictor per building from the analytics history,
+// keeps it learning from the building's events and acts as the building's
+// manager.IdlePolicy: idle cars are sent to the floors expected to get the
+// most calls soon.
+package forecast
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// week is the period the slots of a predictor repeat over
+const week = 7 * 24 * time.Hour
+
+// minRate is the rate below which a floor is forgotten for a slot
+const minRate = 0.001
+
+// SlotResult compares the forecast of a finished slot with the calls that
+// were made during it
+type SlotResult struct {
+	Start    time.Time
+	Forecast map[int]float64 // Calls expected from every floor
+	Actual   map[int]int     // Calls made from every floor
+}
+
+// Error returns how many calls the forecast was off by, summed over floors
+func (r SlotResult) Error() float64 {
+	sum := 0.0
+	for floor, forecast := range r.Forecast {
+		sum += math.Abs(forecast - float64(r.Actual[floor]))
+	}
+	for floor, actual := range r.Actual {
+		if _, ok := r.Forecast[floor]; !ok {
+			sum += float64(actual)
+		}
+	}
+	return sum
+}
+
+// Predictor forecasts the calls of every floor by the time of the week.
+// Calls must be observed in the order they were made; the ones made before
+// the slot being counted are ignored.
+type Predictor struct {
+	slot      time.Duration
+	smoothing float64
+	location  *time.Location
+
+	mu     sync.Mutex
+	rates  []map[int]float64 // Smoothed calls per floor, by slot of the week
+	seen   []bool            // Slots of the week that ended at least once
+	start  time.Time         // Start of the slot being counted; zero until first used
+	counts map[int]int       // Calls per floor in the slot being counted
+	last   *SlotResult       // The slot that ended last
+}
+
+// NewPredictor creates a predictor counting calls in slots of a length that
+// divides an hour, in the time zone the slots of the week follow. smoothing,
+// between 0 and 1, is the weight of the latest week in every rate.
+func NewPredictor(slot time.Duration, smoothing float64, location *time.Location) *Predictor {
+	slots := int(week / slot)
+	return &Predictor{
+		slot:      slot,
+		smoothing: smoothing,
+		location:  location,
+		rates:     make([]map[int]float64, slots),
+		seen:      make([]bool, slots),
+		counts:    make(map[int]int),
+	}
+}
+
+// Observe counts a call made from a floor
+func (p *Predictor) Observe(floor int, at time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.advance(at)
+	if at.Before(p.start) {
+		return
+	}
+	p.counts[floor]++
+}
+
+// Advance ends the slots that ended by now. Slots without calls count as
+// quiet ones.
+func (p *Predictor) Advance(now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.advance(now)
+}
+
+func (p *Predictor) advance(now time.Time) {
+	if p.start.IsZero() {
+		p.start = now.Truncate(p.slot)
+		return
+	}
+	for end := p.start.Add(p.slot); !now.Before(end); end = p.start.Add(p.slot) {
+		p.endSlot()
+		p.start = end
+	}
+}
+
+// endSlot moves the rates of the slot being counted towards its counts;
+// callers must hold p.mu
+func (p *Predictor) endSlot() {
+	index := p.index(p.start)
+	result := &SlotResult{
+		Start:    p.start,
+		Forecast: make(map[int]float64, len(p.rates[index])),
+		Actual:   p.counts,
+	}
+	for floor, rate := range p.rates[index] {
+		result.Forecast[floor] = rate
+	}
+
+	if !p.seen[index] {
+		// The first week sets the rates
+		rates := make(map[int]float64, len(p.counts))
+		for floor, count := range p.counts {
+			rates[floor] = float64(count)
+		}
+		p.rates[index] = rates
+		p.seen[index] = true
+	} else {
+		rates := p.rates[index]
+		if rates == nil {
+			rates = make(map[int]float64, len(p.counts))
+			p.rates[index] = rates
+		}
+		for floor := range p.counts {
+			if _, ok := rates[floor]; !ok {
+				rates[floor] = 0
+			}
+		}
+		for floor, rate := range rates {
+			rate = p.smoothing*float64(p.counts[floor]) + (1-p.smoothing)*rate
+			if rate < minRate {
+				delete(rates, floor)
+				continue
+			}
+			rates[floor] = rate
+		}
+	}
+
+	p.last = result
+	p.counts = make(map[int]int)
+}
+
+// Forecast returns the calls expected from every floor between from and to,
+// counting the part of every slot the period covers. Periods longer than a
+// week are cut to a week.
+func (p *Predictor) Forecast(from, to time.Time) map[int]float64 {
+	if to.Sub(from) > week {
+		to = from.Add(week)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	demand := make(map[int]float64)
+	for start := from.Truncate(p.slot); start.Before(to); start = start.Add(p.slot) {
+		end := start.Add(p.slot)
+		covered := minTime(end, to).Sub(maxTime(start, from))
+		share := float64(covered) / float64(p.slot)
+		for floor, rate := range p.rates[p.index(start)] {
+			demand[floor] += rate * share
+		}
+	}
+	return demand
+}
+
+// LastSlot returns the slot that ended last, if one did
+func (p *Predictor) LastSlot() (SlotResult, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.last == nil {
+		return SlotResult{}, false
+	}
+	return *p.last, true
+}
+
+// index returns the slot of the week a slot start falls in
+func (p *Predictor) index(start time.Time) int {
+	local := start.In(p.location)
+	minutes := int(local.Weekday())*24*60 + local.Hour()*60 + local.Minute()
+	return minutes / int(p.slot/time.Minute) % len(p.rates)
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,87 @@
+package forecast
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// monday is a Monday midnight in UTC
+var monday = time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC)
+
+func TestPredictor_LearnsWeeklyPattern(t *testing.T) {
+	p := NewPredictor(15*time.Minute, 0.5, time.UTC)
+	surge := monday.Add(8*time.Hour + 45*time.Minute)
+
+	// Four calls from the lobby in the first week, two in the second
+	for i := 0; i < 4; i++ {
+		p.Observe(0, surge.Add(time.Duration(i)*time.Minute))
+	}
+	p.Observe(5, surge.Add(20*time.Minute))
+	for i := 0; i < 2; i++ {
+		p.Observe(0, surge.Add(week+time.Duration(i)*time.Minute))
+	}
+	p.Advance(surge.Add(2 * week))
+
+	// The rate is halfway between the weeks; floor 5 called in the next slot
+	assert.Equal(t, map[int]float64{0: 3}, p.Forecast(surge.Add(2*week), surge.Add(2*week+15*time.Minute)))
+	assert.Equal(t, map[int]float64{0: 1.5, 5: 0.25},
+		p.Forecast(surge.Add(2*week+7*time.Minute+30*time.Second), surge.Add(2*week+22*time.Minute+30*time.Second)))
+
+	// Other times of the week are quiet
+	assert.Empty(t, p.Forecast(surge.Add(24*time.Hour), surge.Add(25*time.Hour)))
+}
+
+func TestPredictor_ForgetsFadingDemand(t *testing.T) {
+	p := NewPredictor(time.Hour, 1, time.UTC)
+	p.Observe(3, monday.Add(time.Minute))
+	p.Advance(monday.Add(week + 2*time.Hour))
+
+	// With all weight on the latest week, a quiet week clears the rate
+	assert.Empty(t, p.Forecast(monday, monday.Add(time.Hour)))
+}
+
+func TestPredictor_IgnoresLateCalls(t *testing.T) {
+	p := NewPredictor(15*time.Minute, 0.3, time.UTC)
+	p.Advance(monday.Add(time.Hour))
+	p.Observe(2, monday)
+	p.Observe(2, monday.Add(time.Hour+time.Minute))
+	p.Advance(monday.Add(2 * time.Hour))
+
+	assert.Empty(t, p.Forecast(monday.Add(week), monday.Add(week+15*time.Minute)))
+	assert.Equal(t, map[int]float64{2: 1}, p.Forecast(monday.Add(week+time.Hour), monday.Add(week+75*time.Minute)))
+}
+
+func TestPredictor_LastSlot(t *testing.T) {
+	p := NewPredictor(15*time.Minute, 0.5, time.UTC)
+	_, ok := p.LastSlot()
+	assert.False(t, ok)
+
+	p.Observe(1, monday)
+	p.Observe(1, monday)
+	p.Advance(monday.Add(week))
+	p.Observe(1, monday.Add(week))
+	p.Observe(4, monday.Add(week))
+	p.Advance(monday.Add(week + 15*time.Minute))
+
+	result, ok := p.LastSlot()
+	require.True(t, ok)
+	assert.Equal(t, monday.Add(week), result.Start)
+	assert.Equal(t, map[int]float64{1: 2}, result.Forecast)
+	assert.Equal(t, map[int]int{1: 1, 4: 1}, result.Actual)
+	assert.Equal(t, 2.0, result.Error())
+}
+
+func TestPredictor_SlotsFollowLocalTime(t *testing.T) {
+	location := time.FixedZone("UTC+2", 2*60*60)
+	p := NewPredictor(time.Hour, 1, location)
+
+	// 07:00 UTC is 09:00 in the building
+	p.Observe(0, monday.Add(7*time.Hour))
+	p.Advance(monday.Add(8 * time.Hour))
+
+	local := time.Date(2026, 10, 12, 9, 0, 0, 0, location)
+	assert.Equal(t, map[int]float64{0: 1}, p.Forecast(local, local.Add(time.Hour)))
+}
@@ -0,0 +1,273 @@
+package forecast
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/slavakukuyev/elevator-go/internal/analytics"
+	"github.com/slavakukuyev/elevator-go/internal/constants"
+	"github.com/slavakukuyev/elevator-go/internal/events"
+	"github.com/slavakukuyev/elevator-go/internal/infra/config"
+	"github.com/slavakukuyev/elevator-go/internal/manager"
+	"github.com/slavakukuyev/elevator-go/metrics"
+)
+
+// eventBuffer bounds the events queued for the planner on each bus
+const eventBuffer = 256
+
+// Options tune how demand is learned and idle cars are placed
+type Options struct {
+	Slot      time.Duration  // Length of the time-of-week slots calls are counted in
+	Smoothing float64        // Weight of the latest week in every rate
+	Lookahead time.Duration  // How far ahead demand is forecast
+	MinCalls  float64        // Forecast calls a floor needs to attract an idle car
+	Interval  time.Duration  // How often idle cars are placed
+	Location  *time.Location // Time zone of the slots; the local one when nil
+}
+
+// OptionsFromConfig returns the options a configuration describes
+func OptionsFromConfig(cfg *config.Config) Options {
+	return Options{
+		Slot:      cfg.ForecastSlot,
+		Smoothing: cfg.ForecastSmoothing,
+		Lookahead: cfg.PrepositionLookahead,
+		MinCalls:  cfg.PrepositionMinCalls,
+		Interval:  cfg.PrepositionInterval,
+	}
+}
+
+// Source publishes the calls of one building and places its idle cars;
+// *manager.Manager is a Source
+type Source interface {
+	Events() *events.Bus
+	SetIdlePolicy(policy manager.IdlePolicy, interval time.Duration)
+}
+
+// Planner learns the demand of watched buildings and places their idle cars
+// ahead of it
+type Planner struct {
+	opts   Options
+	logger *slog.Logger
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewPlanner creates a planner
+func NewPlanner(opts Options) *Planner {
+	if opts.Location == nil {
+		opts.Location = time.Local
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Planner{
+		opts:   opts,
+		logger: slog.With(slog.String("component", constants.ComponentForecast)),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Watch learns the calls of a building from its history, ordered by the
+// time they were made, then from its events, and becomes the building's
+// idle policy
+func (p *Planner) Watch(building string, source Source, history []analytics.RequestRecord) {
+	plan := &buildingPlan{
+		planner:   p,
+		building:  building,
+		predictor: NewPredictor(p.opts.Slot, p.opts.Smoothing, p.opts.Location),
+		floors:    make(map[int]bool),
+	}
+	for _, record := range history {
+		plan.predictor.Observe(record.FromFloor, record.RequestedAt)
+	}
+	p.logger.Info("demand forecast trained",
+		slog.String("building", building),
+		slog.Int("calls", len(history)))
+
+	bus := source.Events()
+	lastID := bus.LastID()
+	p.wg.Add(1)
+	go p.watch(plan, bus, lastID)
+
+	source.SetIdlePolicy(plan, p.opts.Interval)
+}
+
+// Stop stops learning and placing idle cars
+func (p *Planner) Stop() {
+	p.cancel()
+	p.wg.Wait()
+}
+
+func (p *Planner) watch(plan *buildingPlan, bus *events.Bus, lastID uint64) {
+	defer p.wg.Done()
+
+	for {
+		sub, backlog, complete := bus.SubscribeSince(lastID, eventBuffer)
+		if !complete {
+			p.logger.Warn("demand forecast fell behind, calls were not counted",
+				slog.String("building", plan.building),
+				slog.Uint64("last_event_id", lastID))
+			metrics.IncError("events_missed", constants.ComponentForecast)
+		}
+		for _, event := range backlog {
+			plan.observe(event)
+			lastID = event.ID
+		}
+
+		// Follow the subscription until the bus drops it
+		for open := true; open; {
+			select {
+			case <-p.ctx.Done():
+				sub.Close()
+				return
+			case event, ok := <-sub.C:
+				if !ok {
+					open = false
+					continue
+				}
+				plan.observe(event)
+				lastID = event.ID
+			}
+		}
+	}
+}
+
+// buildingPlan forecasts the demand of one building and is its idle policy
+type buildingPlan struct {
+	planner   *Planner
+	building  string
+	predictor *Predictor
+
+	// Only used by the building's idle policy goroutine
+	reported time.Time    // Start of the last slot reported in metrics
+	floors   map[int]bool // Floors reported in metrics
+}
+
+// observe counts the call an event announces, if any
+func (b *buildingPlan) observe(event events.Event) {
+	switch event.Type {
+	case events.TypeRequestRejected:
+	case events.TypeRequestAssigned:
+		// Repeated calls and requests moved off a failed car were counted
+		// when they were first made
+		if existing, _ := event.Data["existing"].(bool); existing {
+			return
+		}
+		if _, ok := event.Data["reassigned_from"]; ok {
+			return
+		}
+	default:
+		return
+	}
+
+	if floor, ok := event.Data["from_floor"].(int); ok {
+		b.predictor.Observe(floor, event.Timestamp)
+	}
+}
+
+// Park sends idle cars to the floors expected to get the most calls within
+// the lookahead
+func (b *buildingPlan) Park(now time.Time, cars []manager.IdleCar) map[string]int {
+	if b.planner.ctx.Err() != nil {
+		return nil
+	}
+
+	b.predictor.Advance(now)
+	b.report()
+	demand := b.predictor.Forecast(now, now.Add(b.planner.opts.Lookahead))
+	return place(demand, b.planner.opts.MinCalls, cars)
+}
+
+// report publishes the forecast and actual calls of the slot that ended last
+func (b *buildingPlan) report() {
+	result, ok := b.predictor.LastSlot()
+	if !ok || !result.Start.After(b.reported) {
+		return
+	}
+	b.reported = result.Start
+
+	for floor := range result.Forecast {
+		b.floors[floor] = true
+	}
+	for floor := range result.Actual {
+		b.floors[floor] = true
+	}
+	for floor := range b.floors {
+		metrics.SetForecastCalls(b.building, floor, result.Forecast[floor], result.Actual[floor])
+	}
+	metrics.SetForecastError(b.building, result.Error())
+	b.planner.logger.Debug("demand forecast slot ended",
+		slog.String("building", b.building),
+		slog.Time("start", result.Start),
+		slog.Float64("error_calls", result.Error()))
+}
+
+// place assigns idle cars to the floors expected to get at least minCalls
+// calls, busiest first. A floor where a car waits or is heading keeps it;
+// the others get the nearest idle car left that reaches them, one each. It
+// returns the cars to move and their floors.
+func place(demand map[int]float64, minCalls float64, cars []manager.IdleCar) map[string]int {
+	floors := make([]int, 0, len(demand))
+	for floor, calls := range demand {
+		if calls >= minCalls {
+			floors = append(floors, floor)
+		}
+	}
+	sort.Slice(floors, func(i, j int) bool {
+		if demand[floors[i]] != demand[floors[j]] {
+			return demand[floors[i]] > demand[floors[j]]
+		}
+		return floors[i] < floors[j]
+	})
+
+	taken := make([]bool, len(cars))
+	covered := make(map[int]bool)
+	for _, floor := range floors {
+		for i, car := range cars {
+			if !taken[i] && car.Floor == floor {
+				taken[i] = true
+				covered[floor] = true
+				break
+			}
+		}
+	}
+	// Cars on their way somewhere are not moved again
+	for i, car := range cars {
+		if !car.Idle {
+			taken[i] = true
+		}
+	}
+
+	targets := make(map[string]int)
+	for _, floor := range floors {
+		if covered[floor] {
+			continue
+		}
+		nearest := -1
+		for i, car := range cars {
+			if taken[i] || floor < car.MinFloor || floor > car.MaxFloor {
+				continue
+			}
+			if nearest < 0 || distance(car.Floor, floor) < distance(cars[nearest].Floor, floor) {
+				nearest = i
+			}
+		}
+		if nearest < 0 {
+			continue
+		}
+		taken[nearest] = true
+		targets[cars[nearest].Name] = floor
+	}
+	return targets
+}
+
+func distance(a, b int) int {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
@@ -0,0 +1,125 @@
+package forecast
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slavakukuyev/elevator-go/internal/analytics"
+	"github.com/slavakukuyev/elevator-go/internal/events"
+	"github.com/slavakukuyev/elevator-go/internal/manager"
+)
+
+func TestPlace(t *testing.T) {
+	car := func(name string, floor int, idle bool) manager.IdleCar {
+		return manager.IdleCar{Name: name, Floor: floor, Idle: idle, MinFloor: 0, MaxFloor: 20}
+	}
+
+	tests := []struct {
+		name   string
+		demand map[int]float64
+		cars   []manager.IdleCar
+		want   map[string]int
+	}{
+		{
+			name:   "nearest car goes to the busiest floor",
+			demand: map[int]float64{0: 6, 10: 2},
+			cars:   []manager.IdleCar{car("A", 12, true), car("B", 3, true)},
+			want:   map[string]int{"B": 0, "A": 10},
+		},
+		{
+			name:   "floors below the minimum are left alone",
+			demand: map[int]float64{0: 0.5},
+			cars:   []manager.IdleCar{car("A", 12, true)},
+			want:   map[string]int{},
+		},
+		{
+			name:   "a car waiting at a busy floor stays",
+			demand: map[int]float64{0: 6, 10: 2},
+			cars:   []manager.IdleCar{car("A", 10, true), car("B", 1, true)},
+			want:   map[string]int{"B": 0},
+		},
+		{
+			name:   "a car on its way covers its floor and is not moved",
+			demand: map[int]float64{0: 6, 10: 2},
+			cars:   []manager.IdleCar{car("A", 0, false), car("B", 20, false), car("C", 9, true)},
+			want:   map[string]int{"C": 10},
+		},
+		{
+			name:   "more busy floors than idle cars",
+			demand: map[int]float64{0: 6, 10: 2, 15: 4},
+			cars:   []manager.IdleCar{car("A", 11, true)},
+			want:   map[string]int{"A": 0},
+		},
+		{
+			name:   "floors out of a car's range",
+			demand: map[int]float64{-2: 6},
+			cars:   []manager.IdleCar{car("A", 0, true)},
+			want:   map[string]int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, place(tt.demand, 1, tt.cars))
+		})
+	}
+}
+
+// fakeBuilding is a Source that keeps the idle policy it is given
+type fakeBuilding struct {
+	bus    *events.Bus
+	policy manager.IdlePolicy
+}
+
+func (b *fakeBuilding) Events() *events.Bus { return b.bus }
+
+func (b *fakeBuilding) SetIdlePolicy(policy manager.IdlePolicy, _ time.Duration) {
+	b.policy = policy
+}
+
+func TestPlanner_Watch(t *testing.T) {
+	planner := NewPlanner(Options{
+		Slot: 15 * time.Minute, Smoothing: 0.5, Lookahead: 30 * time.Minute,
+		MinCalls: 1, Interval: time.Second, Location: time.UTC,
+	})
+	defer planner.Stop()
+
+	// Last week the lobby got three calls just after now. Now is the start
+	// of a slot, so the lookahead covers the whole slot they fell in.
+	now := time.Now().UTC().Truncate(15 * time.Minute)
+	var history []analytics.RequestRecord
+	for i := 0; i < 3; i++ {
+		history = append(history, analytics.RequestRecord{FromFloor: 0, RequestedAt: now.Add(-week + time.Duration(i+1)*time.Minute)})
+	}
+
+	building := &fakeBuilding{bus: events.NewBus(100)}
+	planner.Watch("main", building, history)
+	require.NotNil(t, building.policy)
+
+	cars := []manager.IdleCar{{Name: "A", Floor: 7, Idle: true, MinFloor: 0, MaxFloor: 9}}
+	assert.Equal(t, map[string]int{"A": 0}, building.policy.Park(now, cars))
+
+	// New calls are learned from events; repeated and reassigned ones are
+	// counted once
+	plan := building.policy.(*buildingPlan)
+	building.bus.Publish(events.TypeRequestAssigned, "A", map[string]any{"from_floor": 4, "existing": false})
+	building.bus.Publish(events.TypeRequestAssigned, "A", map[string]any{"from_floor": 4, "existing": true})
+	building.bus.Publish(events.TypeRequestAssigned, "B", map[string]any{"from_floor": 4, "reassigned_from": "A"})
+	building.bus.Publish(events.TypeRequestRejected, "", map[string]any{"from_floor": 6})
+	building.bus.Publish(events.TypeFloorArrived, "A", map[string]any{"floor": 4})
+	require.Eventually(t, func() bool {
+		plan.predictor.mu.Lock()
+		defer plan.predictor.mu.Unlock()
+		return plan.predictor.counts[4] == 1 && plan.predictor.counts[6] == 1
+	}, time.Second, time.Millisecond)
+	plan.predictor.mu.Lock()
+	assert.Len(t, plan.predictor.counts, 2)
+	plan.predictor.mu.Unlock()
+
+	// A stopped planner leaves the cars alone
+	planner.Stop()
+	assert.Nil(t, building.policy.Park(now, cars))
+}
@@ -0,0 +1,53 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+	"github.com/slavakukuyev/elevator-go/internal/infra/logging"
+	"github.com/slavakukuyev/elevator-go/internal/sla"
+)
+
+// AlertsResponse lists the SLA alerts of every building
+type AlertsResponse struct {
+	Alerts []sla.Alert `json:"alerts"`
+}
+
+// SetAlerts serves the alerts of an SLA engine under /v1/alerts and makes
+// readiness depend on them: firing warnings degrade it and firing critical
+// rules fail it. It must be called before the server starts.
+func (s *Server) SetAlerts(engine *sla.Engine) {
+	s.alerts = engine
+	s.healthService.Register(engine)
+	s.readiness.AddDependency(engine)
+}
+
+// alertsHandler lists the SLA alerts, optionally only those in a state
+// (GET /v1/alerts?state=firing). The list is empty when no rules are
+// configured.
+func (s *Server) alertsHandler(w http.ResponseWriter, r *http.Request) {
+	rw := NewResponseWriter(w, s.logger, logging.GetRequestID(r.Context()))
+
+	if r.Method != http.MethodGet {
+		rw.WriteError(http.StatusMethodNotAllowed, ErrorCodeMethodNotAllowed,
+			"Method not allowed", "Only GET method is supported")
+		return
+	}
+
+	state := sla.State(r.URL.Query().Get("state"))
+	if state != "" && !state.IsValid() {
+		rw.WriteDomainError(domain.NewValidationError("state must be ok or firing", nil).
+			WithContext("state", string(state)))
+		return
+	}
+
+	alerts := []sla.Alert{}
+	if s.alerts != nil {
+		for _, alert := range s.alerts.Alerts() {
+			if state == "" || alert.State == state {
+				alerts = append(alerts, alert)
+			}
+		}
+	}
+	rw.WriteJSON(http.StatusOK, AlertsResponse{Alerts: alerts})
+}
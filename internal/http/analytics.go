@@ -0,0 +1,93 @@
+package http
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/slavakukuyev/elevator-go/internal/analytics"
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+	"github.com/slavakukuyev/elevator-go/internal/infra/logging"
+)
+
+const (
+	// defaultAnalyticsRange is the range reports cover when no start is given
+	defaultAnalyticsRange = 24 * time.Hour
+	// defaultAnalyticsPeaks is the number of busiest hours reports list by
+	// default
+	defaultAnalyticsPeaks = 5
+)
+
+// SetAnalytics serves reports from an analytics store under /v1/analytics.
+// It must be called before the server starts.
+func (s *Server) SetAnalytics(store *analytics.Store) {
+	s.analytics = store
+}
+
+// analyticsHandler reports the traffic of a time range
+// (GET /v1/analytics?from=...&to=...&building=...&peaks=...). The range is
+// given in RFC 3339 and defaults to the last 24 hours.
+func (s *Server) analyticsHandler(w http.ResponseWriter, r *http.Request) {
+	rw := NewResponseWriter(w, s.logger, logging.GetRequestID(r.Context()))
+
+	if r.Method != http.MethodGet {
+		rw.WriteError(http.StatusMethodNotAllowed, ErrorCodeMethodNotAllowed,
+			"Method not allowed", "Only GET method is supported")
+		return
+	}
+	if s.analytics == nil {
+		rw.WriteDomainError(domain.NewNotFoundError("analytics are not enabled", nil))
+		return
+	}
+
+	query, err := parseAnalyticsQuery(r.URL.Query(), time.Now())
+	if err != nil {
+		rw.WriteDomainError(err)
+		return
+	}
+
+	report, err := s.analytics.Report(query)
+	if err != nil {
+		rw.WriteDomainError(err)
+		return
+	}
+	rw.WriteJSON(http.StatusOK, report)
+}
+
+// parseAnalyticsQuery reads a report query from URL parameters
+func parseAnalyticsQuery(values url.Values, now time.Time) (analytics.Query, error) {
+	query := analytics.Query{
+		Building: values.Get("building"),
+		To:       now,
+		Peaks:    defaultAnalyticsPeaks,
+	}
+
+	if raw := values.Get("to"); raw != "" {
+		to, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return analytics.Query{}, domain.NewValidationError("to must be an RFC 3339 time", err).
+				WithContext("to", raw)
+		}
+		query.To = to
+	}
+	query.From = query.To.Add(-defaultAnalyticsRange)
+	if raw := values.Get("from"); raw != "" {
+		from, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return analytics.Query{}, domain.NewValidationError("from must be an RFC 3339 time", err).
+				WithContext("from", raw)
+		}
+		query.From = from
+	}
+
+	if raw := values.Get("peaks"); raw != "" {
+		peaks, err := strconv.Atoi(raw)
+		if err != nil {
+			return analytics.Query{}, domain.NewValidationError("peaks must be a number", err).
+				WithContext("peaks", raw)
+		}
+		query.Peaks = peaks
+	}
+	return query, nil
+}
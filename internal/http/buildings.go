@@ -0,0 +1,148 @@
+package http
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+	"github.com/slavakukuyev/elevator-go/internal/infra/logging"
+	"github.com/slavakukuyev/elevator-go/internal/manager"
+)
+
+// ErrorCodeBuildingBusy is returned when a building already serves as many
+// requests as its bulkhead allows
+const ErrorCodeBuildingBusy = "BUILDING_BUSY"
+
+// BuildingResponse describes one building of the registry
+type BuildingResponse struct {
+	ID                    string `json:"id"`
+	Default               bool   `json:"default"`
+	MinFloor              int    `json:"min_floor"`
+	MaxFloor              int    `json:"max_floor"`
+	Elevators             int    `json:"elevators"`
+	MaxConcurrentRequests int    `json:"max_concurrent_requests"`
+}
+
+// BuildingsResponse lists the buildings of the registry
+type BuildingsResponse struct {
+	Buildings []BuildingResponse `json:"buildings"`
+}
+
+// SetBuildings serves the buildings of a registry under
+// /v1/buildings/{building}/..., each through its own manager and bulkhead.
+// It must be called before the server starts.
+func (s *Server) SetBuildings(registry *manager.Registry) {
+	s.buildings = registry
+}
+
+// building looks up a building, writing a not found error when the server
+// has no registry or the building does not exist
+func (s *Server) building(rw *ResponseWriter, id string) (*manager.Building, bool) {
+	if s.buildings == nil {
+		rw.WriteDomainError(domain.NewNotFoundError("building not found", nil).
+			WithContext("building", id))
+		return nil, false
+	}
+
+	building, err := s.buildings.Building(id)
+	if err != nil {
+		rw.WriteDomainError(err)
+		return nil, false
+	}
+	return building, true
+}
+
+// buildingHandlers returns the v1 handlers bound to a building's manager.
+// They are built for each request so they see the configuration the
+// manager runs with after a reload.
+func (s *Server) buildingHandlers(building *manager.Building) *V1Handlers {
+	// The default building shares the server's handlers, which route
+	// through the cluster when there is one
+	if building.Manager == s.manager {
+		return s.v1
+	}
+	return NewV1Handlers(building.Manager, building.Manager.Config(),
+		s.logger.With(slog.String("building", building.ID)))
+}
+
+// inBuilding serves a v1 handler for the building named in the path. The
+// request takes a slot in the building's bulkhead for as long as it runs and
+// is turned away with 503 when the building is saturated, leaving the other
+// buildings unaffected.
+func (s *Server) inBuilding(handle func(*V1Handlers, http.ResponseWriter, *http.Request)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestID := logging.GetRequestID(r.Context())
+		rw := NewResponseWriter(w, s.logger, requestID)
+
+		building, ok := s.building(rw, r.PathValue("building"))
+		if !ok {
+			return
+		}
+
+		release, ok := building.Acquire()
+		if !ok {
+			s.logger.WarnContext(r.Context(), "building is saturated, request rejected",
+				slog.String("building", building.ID),
+				slog.String("path", r.URL.Path),
+				slog.String("request_id", requestID))
+			w.Header().Set("Retry-After", "1")
+			rw.WriteError(http.StatusServiceUnavailable, ErrorCodeBuildingBusy,
+				"Building is busy", "The building is serving too many requests, try again shortly")
+			return
+		}
+		defer release()
+
+		handle(s.buildingHandlers(building), w, r)
+	}
+}
+
+// newBuildingResponse describes a building for a response
+func (s *Server) newBuildingResponse(building *manager.Building) BuildingResponse {
+	cfg := building.Manager.Config()
+	return BuildingResponse{
+		ID:                    building.ID,
+		Default:               building.Manager == s.manager,
+		MinFloor:              cfg.MinFloor,
+		MaxFloor:              cfg.MaxFloor,
+		Elevators:             len(building.Manager.GetElevators()),
+		MaxConcurrentRequests: building.MaxConcurrentRequests(),
+	}
+}
+
+// buildingsHandler lists the buildings (GET /v1/buildings)
+func (s *Server) buildingsHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := logging.GetRequestID(r.Context())
+	rw := NewResponseWriter(w, s.logger, requestID)
+
+	if r.Method != http.MethodGet {
+		rw.WriteError(http.StatusMethodNotAllowed, ErrorCodeMethodNotAllowed,
+			"Method not allowed", "Only GET method is supported")
+		return
+	}
+
+	response := BuildingsResponse{Buildings: []BuildingResponse{}}
+	if s.buildings != nil {
+		for _, building := range s.buildings.Buildings() {
+			response.Buildings = append(response.Buildings, s.newBuildingResponse(building))
+		}
+	}
+	rw.WriteJSON(http.StatusOK, response)
+}
+
+// buildingHandler describes one building (GET /v1/buildings/{building})
+func (s *Server) buildingHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := logging.GetRequestID(r.Context())
+	rw := NewResponseWriter(w, s.logger, requestID)
+
+	if r.Method != http.MethodGet {
+		rw.WriteError(http.StatusMethodNotAllowed, ErrorCodeMethodNotAllowed,
+			"Method not allowed", "Only GET method is supported")
+		return
+	}
+
+	building, ok := s.building(rw, r.PathValue("building"))
+	if !ok {
+		return
+	}
+	rw.WriteJSON(http.StatusOK, s.newBuildingResponse(building))
+}
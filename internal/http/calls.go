@@ -0,0 +1,189 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/slavakukuyev/elevator-go/internal/constants"
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+	"github.com/slavakukuyev/elevator-go/internal/infra/logging"
+)
+
+// CarCallRequestBody represents a floor button pressed inside a car
+type CarCallRequestBody struct {
+	Floor *int `json:"floor"`
+}
+
+// CarCallResponse represents the response for a car call
+type CarCallResponse struct {
+	ElevatorName string `json:"elevator_name"`
+	Floor        int    `json:"floor"`
+	Direction    string `json:"direction"`
+	Message      string `json:"message"`
+}
+
+// HallCallRequestBody represents an up or down button pressed at a floor
+type HallCallRequestBody struct {
+	Floor     int    `json:"floor"`
+	Direction string `json:"direction"`
+	Cargo     bool   `json:"cargo,omitempty"` // Optional: served by freight cars only
+}
+
+// HallCallResponse represents the response for a hall call
+type HallCallResponse struct {
+	ElevatorName string `json:"elevator_name"`
+	Floor        int    `json:"floor"`
+	Direction    string `json:"direction"`
+	Message      string `json:"message"`
+}
+
+// CarCallHandler adds a destination pressed by a rider inside an elevator
+// (POST /v1/elevators/{name}/car-calls)
+func (h *V1Handlers) CarCallHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := logging.GetRequestID(r.Context())
+	rw := NewResponseWriter(w, h.logger, requestID)
+	name := r.PathValue("name")
+
+	if r.Method != http.MethodPost {
+		h.logger.WarnContext(r.Context(), "invalid request method for car calls endpoint",
+			slog.String("method", r.Method),
+			slog.String("request_id", requestID))
+		rw.WriteError(http.StatusMethodNotAllowed, ErrorCodeMethodNotAllowed,
+			"Method not allowed", "Only POST method is supported")
+		return
+	}
+
+	var requestBody CarCallRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to decode car call",
+			slog.String("error", err.Error()),
+			slog.String("request_id", requestID))
+		rw.WriteError(http.StatusBadRequest, ErrorCodeInvalidJSON,
+			"Invalid JSON", "Request body contains invalid JSON")
+		return
+	}
+
+	if requestBody.Floor == nil {
+		rw.WriteDomainError(domain.NewValidationError("floor is required", nil))
+		return
+	}
+	floor := *requestBody.Floor
+	if _, err := domain.NewFloorWithValidation(floor); err != nil {
+		rw.WriteDomainError(err)
+		return
+	}
+
+	direction, err := h.carCall(r.Context(), name, floor)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "car call failed",
+			slog.String("elevator_name", name),
+			slog.Int("floor", floor),
+			slog.String("error", err.Error()),
+			slog.String("request_id", requestID))
+		rw.WriteDomainError(err)
+		return
+	}
+
+	h.logger.InfoContext(r.Context(), "car call processed successfully",
+		slog.String("elevator_name", name),
+		slog.Int("floor", floor),
+		slog.String("request_id", requestID),
+		slog.String("component", constants.ComponentHTTPHandler))
+
+	rw.WriteJSON(http.StatusOK, CarCallResponse{
+		ElevatorName: name,
+		Floor:        floor,
+		Direction:    string(direction),
+		Message:      "Car call processed successfully",
+	})
+}
+
+// HallCallHandler assigns an up or down button pressed at a floor to an
+// elevator (POST /v1/floors/hall-calls); the destination follows as a car
+// call once the rider is on board
+func (h *V1Handlers) HallCallHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := logging.GetRequestID(r.Context())
+	rw := NewResponseWriter(w, h.logger, requestID)
+
+	if r.Method != http.MethodPost {
+		h.logger.WarnContext(r.Context(), "invalid request method for hall calls endpoint",
+			slog.String("method", r.Method),
+			slog.String("request_id", requestID))
+		rw.WriteError(http.StatusMethodNotAllowed, ErrorCodeMethodNotAllowed,
+			"Method not allowed", "Only POST method is supported")
+		return
+	}
+
+	var requestBody HallCallRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to decode hall call",
+			slog.String("error", err.Error()),
+			slog.String("request_id", requestID))
+		rw.WriteError(http.StatusBadRequest, ErrorCodeInvalidJSON,
+			"Invalid JSON", "Request body contains invalid JSON")
+		return
+	}
+
+	if _, err := domain.NewFloorWithValidation(requestBody.Floor); err != nil {
+		rw.WriteDomainError(err)
+		return
+	}
+
+	staff, ok := h.authenticateStaff(r, rw)
+	if !ok {
+		return
+	}
+
+	direction := domain.Direction(requestBody.Direction)
+	elevatorName, err := h.hallCall(r.Context(), requestBody.Floor, direction,
+		domain.CallOptions{Cargo: requestBody.Cargo, Staff: staff})
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "hall call failed",
+			slog.Int("floor", requestBody.Floor),
+			slog.String("direction", requestBody.Direction),
+			slog.String("error", err.Error()),
+			slog.String("request_id", requestID))
+		rw.WriteDomainError(err)
+		return
+	}
+
+	h.logger.InfoContext(r.Context(), "hall call processed successfully",
+		slog.String("elevator_name", elevatorName),
+		slog.Int("floor", requestBody.Floor),
+		slog.String("direction", requestBody.Direction),
+		slog.String("request_id", requestID),
+		slog.String("component", constants.ComponentHTTPHandler))
+
+	rw.WriteJSON(http.StatusOK, HallCallResponse{
+		ElevatorName: elevatorName,
+		Floor:        requestBody.Floor,
+		Direction:    requestBody.Direction,
+		Message:      "Hall call processed successfully",
+	})
+}
+
+// carCall adds a car call through the cluster leader when the handlers
+// serve a cluster, or on the manager otherwise
+func (h *V1Handlers) carCall(ctx context.Context, name string, floor int) (domain.Direction, error) {
+	if h.cluster != nil {
+		return h.cluster.CarCall(ctx, name, floor)
+	}
+	return h.manager.CarCall(ctx, name, floor)
+}
+
+// hallCall assigns a hall call through the cluster leader when the handlers
+// serve a cluster, or on the manager otherwise, and returns the name of the
+// elevator assigned
+func (h *V1Handlers) hallCall(ctx context.Context, floor int, direction domain.Direction, call domain.CallOptions) (string, error) {
+	if h.cluster != nil {
+		return h.cluster.HallCall(ctx, floor, direction, call)
+	}
+
+	e, err := h.manager.RequestHallCall(ctx, floor, direction, call)
+	if err != nil {
+		return "", err
+	}
+	return e.Name(), nil
+}
@@ -0,0 +1,29 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/slavakukuyev/elevator-go/internal/cluster"
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+	"github.com/slavakukuyev/elevator-go/internal/infra/logging"
+)
+
+// SetCluster makes the server a node of a cluster: floor requests, hall
+// and car calls, tuning, faults and elevator creation and deletion on the
+// default building go through the cluster leader, and /v1/cluster/ serves
+// the API the nodes use to reach each other. It must be called before the
+// server starts.
+func (s *Server) SetCluster(node *cluster.Node) {
+	s.v1.cluster = node
+	s.clusterAPI = node.Handler()
+}
+
+// clusterHandler serves the cluster API of the node (/v1/cluster/...)
+func (s *Server) clusterHandler(w http.ResponseWriter, r *http.Request) {
+	if s.clusterAPI == nil {
+		rw := NewResponseWriter(w, s.logger, logging.GetRequestID(r.Context()))
+		rw.WriteDomainError(domain.NewNotFoundError("clustering is not enabled", nil))
+		return
+	}
+	s.clusterAPI.ServeHTTP(w, r)
+}
@@ -1,14 +1,20 @@
 package http
 
 import (
+	"context"
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
 	"strings"
 	"time"
 
+	"github.com/slavakukuyev/elevator-go/internal/cluster"
 	"github.com/slavakukuyev/elevator-go/internal/constants"
 	"github.com/slavakukuyev/elevator-go/internal/domain"
+	"github.com/slavakukuyev/elevator-go/internal/elevator"
+	"github.com/slavakukuyev/elevator-go/internal/factory"
 	"github.com/slavakukuyev/elevator-go/internal/infra/config"
 	"github.com/slavakukuyev/elevator-go/internal/infra/logging"
 	"github.com/slavakukuyev/elevator-go/internal/manager"
@@ -19,6 +25,10 @@ type V1Handlers struct {
 	manager *manager.Manager
 	cfg     *config.Config
 	logger  *slog.Logger
+
+	// cluster, when set, routes floor requests and fleet changes to the
+	// cluster leader
+	cluster *cluster.Node
 }
 
 // NewV1Handlers creates a new V1Handlers instance
@@ -36,15 +46,19 @@ type FloorRequestResponse struct {
 	FromFloor    int    `json:"from_floor"`
 	ToFloor      int    `json:"to_floor"`
 	Direction    string `json:"direction"`
+	Priority     string `json:"priority"`
+	Accessible   bool   `json:"accessible"`
 	Message      string `json:"message"`
 }
 
 // ElevatorCreateResponse represents the response for elevator creation
 type ElevatorCreateResponse struct {
-	Name     string `json:"name"`
-	MinFloor int    `json:"min_floor"`
-	MaxFloor int    `json:"max_floor"`
-	Message  string `json:"message"`
+	Name     string         `json:"name"`
+	Type     string         `json:"type"`
+	MinFloor int            `json:"min_floor"`
+	MaxFloor int            `json:"max_floor"`
+	Tuning   ElevatorTuning `json:"tuning"`
+	Message  string         `json:"message"`
 }
 
 // ElevatorDeleteRequest represents the request for elevator deletion
@@ -58,6 +72,12 @@ type ElevatorDeleteResponse struct {
 	Message string `json:"message"`
 }
 
+// ElevatorFaultsResponse lists the active faults of an elevator
+type ElevatorFaultsResponse struct {
+	Name   string         `json:"name"`
+	Faults []domain.Fault `json:"faults"`
+}
+
 // HealthResponse represents the health check response
 type HealthResponse struct {
 	Status    string                 `json:"status"`
@@ -106,26 +126,34 @@ func (h *V1Handlers) FloorRequestHandler(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Validate client input floors before processing
-	if _, err := domain.NewFloorWithValidation(requestBody.From); err != nil {
-		h.logger.ErrorContext(r.Context(), "invalid from floor in client request",
+	if err := validateFloorRequest(requestBody.From, requestBody.To); err != nil {
+		h.logger.ErrorContext(r.Context(), "invalid floor in client request",
 			slog.Int("from_floor", requestBody.From),
+			slog.Int("to_floor", requestBody.To),
 			slog.String("error", err.Error()),
 			slog.String("request_id", requestID))
 		rw.WriteDomainError(err)
 		return
 	}
 
-	if _, err := domain.NewFloorWithValidation(requestBody.To); err != nil {
-		h.logger.ErrorContext(r.Context(), "invalid to floor in client request",
-			slog.Int("to_floor", requestBody.To),
-			slog.String("error", err.Error()),
+	staff, ok := h.authenticateStaff(r, rw)
+	if !ok {
+		return
+	}
+	call, err := newCallOptions(requestBody.Cargo, requestBody.Priority, requestBody.Accessible, staff)
+	if errors.Is(err, errEmergencyNeedsStaff) {
+		h.logger.WarnContext(r.Context(), "rejected emergency call without staff token",
 			slog.String("request_id", requestID))
+		rw.WriteError(http.StatusUnauthorized, ErrorCodeUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+	if err != nil {
 		rw.WriteDomainError(err)
 		return
 	}
 
 	// Request an elevator
-	elevator, err := h.manager.RequestElevator(r.Context(), requestBody.From, requestBody.To)
+	elevatorName, err := h.requestElevator(r.Context(), requestBody.From, requestBody.To, call)
 	if err != nil {
 		h.logger.ErrorContext(r.Context(), "elevator request failed",
 			slog.Int("from_floor", requestBody.From),
@@ -136,16 +164,13 @@ func (h *V1Handlers) FloorRequestHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	var elevatorName string
-	if elevator != nil {
-		elevatorName = elevator.Name()
-	}
-
 	response := FloorRequestResponse{
 		ElevatorName: elevatorName,
 		FromFloor:    requestBody.From,
 		ToFloor:      requestBody.To,
 		Direction:    determineDirection(requestBody.From, requestBody.To),
+		Priority:     call.Priority.String(),
+		Accessible:   call.Accessible,
 		Message:      "Floor request processed successfully",
 	}
 
@@ -160,6 +185,112 @@ func (h *V1Handlers) FloorRequestHandler(w http.ResponseWriter, r *http.Request)
 	rw.WriteJSON(http.StatusOK, response)
 }
 
+// requestElevator requests an elevator through the cluster leader when the
+// handlers serve a cluster, or from the manager otherwise, and returns the
+// name of the elevator assigned
+func (h *V1Handlers) requestElevator(ctx context.Context, fromFloor, toFloor int, call domain.CallOptions) (string, error) {
+	if h.cluster != nil {
+		return h.cluster.RequestElevator(ctx, fromFloor, toFloor, call)
+	}
+
+	e, err := h.manager.RequestElevatorWithOptions(ctx, fromFloor, toFloor, call)
+	if err != nil || e == nil {
+		return "", err
+	}
+	return e.Name(), nil
+}
+
+// StaffTokenHeader carries the token that authenticates a floor request as
+// coming from building staff, which lets it use service cars
+const StaffTokenHeader = "X-Staff-Token"
+
+// authenticateStaff reports whether the request carries a valid staff token.
+// A request with an invalid token, or any token while staff calls are not
+// configured, is rejected with 401 and ok is false.
+func (h *V1Handlers) authenticateStaff(r *http.Request, rw *ResponseWriter) (staff bool, ok bool) {
+	token := r.Header.Get(StaffTokenHeader)
+	if token == "" {
+		return false, true
+	}
+
+	if !validToken(token, h.manager.Config().StaffAPIToken) {
+		h.logger.WarnContext(r.Context(), "rejected floor request with invalid staff token",
+			slog.String("request_id", rw.requestID))
+		rw.WriteError(http.StatusUnauthorized, ErrorCodeUnauthorized,
+			"Unauthorized", "Invalid staff token")
+		return false, false
+	}
+	return true, true
+}
+
+// AdminTokenHeader carries the token that authenticates calls to the admin
+// endpoints: configuration reload and webhook management
+const AdminTokenHeader = "X-Admin-Token"
+
+// authenticateAdmin reports whether the request carries the admin token of
+// the current configuration. Any other request is rejected with 401,
+// including every request while no admin token is configured.
+func (s *Server) authenticateAdmin(r *http.Request, rw *ResponseWriter) bool {
+	if validToken(r.Header.Get(AdminTokenHeader), s.manager.Config().AdminAPIToken) {
+		return true
+	}
+	s.logger.WarnContext(r.Context(), "rejected admin request without a valid admin token",
+		slog.String("path", r.URL.Path),
+		slog.String("request_id", rw.requestID))
+	rw.WriteError(http.StatusUnauthorized, ErrorCodeUnauthorized,
+		"Unauthorized", "A valid admin token is required")
+	return false
+}
+
+// validToken reports whether a token matches the configured one; no token
+// is valid when none is configured
+func validToken(token, expected string) bool {
+	return expected != "" && subtle.ConstantTimeCompare([]byte(token), []byte(expected)) == 1
+}
+
+// errEmergencyNeedsStaff rejects emergency calls without a staff token: an
+// emergency call reserves a car and takes it out of normal service
+var errEmergencyNeedsStaff = errors.New("emergency calls require a staff token")
+
+// newCallOptions builds the call options of a floor request from the fields
+// a client sent. It is shared by every transport that accepts floor requests.
+// Emergency calls that are not staff calls fail with errEmergencyNeedsStaff.
+func newCallOptions(cargo bool, priority string, accessible, staff bool) (domain.CallOptions, error) {
+	parsed, err := domain.ParsePriority(priority)
+	if err != nil {
+		return domain.CallOptions{}, err
+	}
+	if parsed == domain.PriorityEmergency && !staff {
+		return domain.CallOptions{}, errEmergencyNeedsStaff
+	}
+	return domain.CallOptions{Cargo: cargo, Staff: staff, Priority: parsed, Accessible: accessible}, nil
+}
+
+// validateFloorRequest checks client supplied floors before they reach the
+// manager. It is shared by every transport that accepts floor requests.
+func validateFloorRequest(from, to int) error {
+	if _, err := domain.NewFloorWithValidation(from); err != nil {
+		return err
+	}
+	if _, err := domain.NewFloorWithValidation(to); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ElevatorsHandler creates (POST) or deletes (DELETE) elevators
+// (/v1/elevators)
+func (h *V1Handlers) ElevatorsHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.ElevatorCreateHandler(w, r)
+	case http.MethodDelete:
+		h.ElevatorDeleteHandler(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
 // ElevatorCreateHandler handles v1 elevator creation (POST /v1/elevators)
 func (h *V1Handlers) ElevatorCreateHandler(w http.ResponseWriter, r *http.Request) {
 	requestID := logging.GetRequestID(r.Context())
@@ -217,13 +348,43 @@ func (h *V1Handlers) ElevatorCreateHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	// Set default overload threshold if not provided
-	overloadThreshold := h.cfg.DefaultOverloadThreshold // use configured default value
-	if requestBody.OverloadThreshold != nil {
-		overloadThreshold = *requestBody.OverloadThreshold
+	// Settings not given in the request use the configured defaults and
+	// per-elevator overrides
+	patch, err := ElevatorTuningRequest{
+		EachFloorDuration: requestBody.EachFloorDuration,
+		OpenDoorDuration:  requestBody.OpenDoorDuration,
+		OverloadThreshold: requestBody.OverloadThreshold,
+		CircuitBreaker:    requestBody.CircuitBreaker,
+	}.patch()
+	if err != nil {
+		rw.WriteDomainError(err)
+		return
+	}
+
+	elevatorType, err := domain.ParseElevatorType(requestBody.Type)
+	if err != nil {
+		rw.WriteDomainError(err)
+		return
+	}
+
+	cfg := h.manager.Config()
+	settings := factory.DefaultSettings(cfg, elevatorType, requestBody.Name)
+	tuning := patch.Apply(elevator.Tuning{
+		EachFloorDuration: settings.EachFloorDuration,
+		OpenDoorDuration:  settings.OpenDoorDuration,
+		OverloadThreshold: settings.OverloadThreshold,
+		CircuitBreaker:    cfg.CircuitBreakerSettings(),
+	})
+	if err := tuning.Validate(); err != nil {
+		h.logger.ErrorContext(r.Context(), "invalid tuning in elevator creation request",
+			slog.String("elevator_name", requestBody.Name),
+			slog.String("error", err.Error()),
+			slog.String("request_id", requestID))
+		rw.WriteDomainError(err)
+		return
 	}
 
-	err := h.manager.AddElevator(r.Context(), h.cfg, requestBody.Name, requestBody.MinFloor, requestBody.MaxFloor, h.cfg.EachFloorDuration, h.cfg.OpenDoorDuration, overloadThreshold)
+	tuning, err = h.addElevator(r.Context(), cfg, elevatorType, requestBody.Name, requestBody.MinFloor, requestBody.MaxFloor, tuning, patch)
 	if err != nil {
 		h.logger.ErrorContext(r.Context(), "failed to create elevator",
 			slog.String("elevator_name", requestBody.Name),
@@ -237,8 +398,10 @@ func (h *V1Handlers) ElevatorCreateHandler(w http.ResponseWriter, r *http.Reques
 
 	response := ElevatorCreateResponse{
 		Name:     requestBody.Name,
+		Type:     string(elevatorType),
 		MinFloor: requestBody.MinFloor,
 		MaxFloor: requestBody.MaxFloor,
+		Tuning:   newElevatorTuning(tuning),
 		Message:  "Elevator created successfully",
 	}
 
@@ -252,6 +415,34 @@ func (h *V1Handlers) ElevatorCreateHandler(w http.ResponseWriter, r *http.Reques
 	rw.WriteJSON(http.StatusCreated, response)
 }
 
+// addElevator creates an elevator in the cluster leader's fleet when the
+// handlers serve a cluster, or on the manager otherwise, and returns the
+// settings it runs with
+func (h *V1Handlers) addElevator(ctx context.Context, cfg *config.Config, elevatorType domain.ElevatorType, name string,
+	minFloor, maxFloor int, tuning elevator.Tuning, patch elevator.TuningPatch) (elevator.Tuning, error) {
+	if h.cluster != nil {
+		spec := cluster.ElevatorSpec{
+			Name:              name,
+			Type:              elevatorType,
+			MinFloor:          minFloor,
+			MaxFloor:          maxFloor,
+			EachFloorDuration: tuning.EachFloorDuration,
+			OpenDoorDuration:  tuning.OpenDoorDuration,
+			OverloadThreshold: tuning.OverloadThreshold,
+		}
+		if patch.HasCircuitBreaker() {
+			spec.CircuitBreaker = &tuning.CircuitBreaker
+		}
+		return tuning, h.cluster.AddElevator(ctx, spec)
+	}
+
+	err := h.manager.AddTypedElevator(ctx, cfg, elevatorType, name, minFloor, maxFloor, tuning.EachFloorDuration, tuning.OpenDoorDuration, tuning.OverloadThreshold)
+	if err == nil && patch.HasCircuitBreaker() {
+		return h.manager.TuneElevator(ctx, name, elevator.TuningPatch{CircuitBreaker: patch.CircuitBreaker})
+	}
+	return tuning, err
+}
+
 // ElevatorDeleteHandler handles v1 elevator deletion (DELETE /v1/elevators)
 func (h *V1Handlers) ElevatorDeleteHandler(w http.ResponseWriter, r *http.Request) {
 	requestID := logging.GetRequestID(r.Context())
@@ -290,7 +481,12 @@ func (h *V1Handlers) ElevatorDeleteHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	err := h.manager.DeleteElevator(r.Context(), requestBody.Name)
+	var err error
+	if h.cluster != nil {
+		err = h.cluster.DeleteElevator(r.Context(), requestBody.Name)
+	} else {
+		err = h.manager.DeleteElevator(r.Context(), requestBody.Name)
+	}
 	if err != nil {
 		h.logger.ErrorContext(r.Context(), "failed to delete elevator",
 			slog.String("elevator_name", requestBody.Name),
@@ -313,6 +509,93 @@ func (h *V1Handlers) ElevatorDeleteHandler(w http.ResponseWriter, r *http.Reques
 	rw.WriteJSON(http.StatusOK, response)
 }
 
+// ElevatorFaultsHandler inspects, injects and clears simulated hardware faults
+// (GET, POST and DELETE /v1/elevators/{name}/faults). DELETE clears the fault
+// named by the type query parameter, or all faults without it.
+func (h *V1Handlers) ElevatorFaultsHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := logging.GetRequestID(r.Context())
+	rw := NewResponseWriter(w, h.logger, requestID)
+	name := r.PathValue("name")
+
+	status := http.StatusOK
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		var fault domain.Fault
+		if err := json.NewDecoder(r.Body).Decode(&fault); err != nil {
+			h.logger.ErrorContext(r.Context(), "failed to decode fault injection request",
+				slog.String("error", err.Error()),
+				slog.String("request_id", requestID))
+			rw.WriteError(http.StatusBadRequest, ErrorCodeInvalidJSON,
+				"Invalid JSON", "Request body contains invalid JSON")
+			return
+		}
+		if _, err := h.injectFault(r.Context(), name, fault); err != nil {
+			h.logger.ErrorContext(r.Context(), "failed to inject fault",
+				slog.String("elevator_name", name),
+				slog.String("fault", string(fault.Type)),
+				slog.String("error", err.Error()),
+				slog.String("request_id", requestID))
+			rw.WriteDomainError(err)
+			return
+		}
+		status = http.StatusCreated
+	case http.MethodDelete:
+		faultType := domain.FaultType(r.URL.Query().Get("type"))
+		if err := h.clearFault(r.Context(), name, faultType); err != nil {
+			h.logger.ErrorContext(r.Context(), "failed to clear fault",
+				slog.String("elevator_name", name),
+				slog.String("fault", string(faultType)),
+				slog.String("error", err.Error()),
+				slog.String("request_id", requestID))
+			rw.WriteDomainError(err)
+			return
+		}
+	default:
+		h.logger.WarnContext(r.Context(), "invalid request method for elevator faults endpoint",
+			slog.String("method", r.Method),
+			slog.String("request_id", requestID))
+		rw.WriteError(http.StatusMethodNotAllowed, ErrorCodeMethodNotAllowed,
+			"Method not allowed", "Only GET, POST and DELETE methods are supported")
+		return
+	}
+
+	faults, err := h.elevatorFaults(name)
+	if err != nil {
+		rw.WriteDomainError(err)
+		return
+	}
+
+	rw.WriteJSON(status, ElevatorFaultsResponse{Name: name, Faults: faults})
+}
+
+// injectFault injects a fault through the cluster leader when the handlers
+// serve a cluster, or on the manager otherwise
+func (h *V1Handlers) injectFault(ctx context.Context, name string, fault domain.Fault) (domain.Fault, error) {
+	if h.cluster != nil {
+		return h.cluster.InjectFault(ctx, name, fault)
+	}
+	return h.manager.InjectFault(ctx, name, fault)
+}
+
+// clearFault clears a fault through the cluster leader when the handlers
+// serve a cluster, or on the manager otherwise
+func (h *V1Handlers) clearFault(ctx context.Context, name string, faultType domain.FaultType) error {
+	if h.cluster != nil {
+		return h.cluster.ClearFault(ctx, name, faultType)
+	}
+	return h.manager.ClearFault(ctx, name, faultType)
+}
+
+// elevatorFaults returns the active faults of an elevator of the cluster's
+// fleet when the handlers serve a cluster, or of the manager's otherwise
+func (h *V1Handlers) elevatorFaults(name string) ([]domain.Fault, error) {
+	if h.cluster != nil {
+		return h.cluster.ElevatorFaults(name)
+	}
+	return h.manager.ElevatorFaults(name)
+}
+
 // HealthHandler handles v1 health checks (GET /v1/health)
 func (h *V1Handlers) HealthHandler(w http.ResponseWriter, r *http.Request) {
 	requestID := logging.GetRequestID(r.Context())
@@ -395,14 +678,29 @@ func (h *V1Handlers) APIInfoHandler(w http.ResponseWriter, r *http.Request) {
 		Version:     "v1",
 		Description: "RESTful API for managing elevator systems",
 		Endpoints: map[string]string{
-			"POST /v1/floors/request": "Request elevator from one floor to another",
-			"POST /v1/elevators":      "Create a new elevator in the system",
-			"DELETE /v1/elevators":    "Delete an elevator from the system",
-			"GET /v1/health":          "Check system health status",
-			"GET /v1/metrics":         "Get system metrics",
-			"GET /v1":                 "Get API information",
-			"GET /metrics":            "Prometheus metrics endpoint",
-			"WebSocket /ws/status":    "Real-time elevator status updates",
+			"POST /v1/floors/request":             "Request elevator from one floor to another; cargo calls use freight cars, calls with a valid X-Staff-Token header may use service cars, priority (high, emergency) calls are served first and accessible calls get extra door time and announcements",
+			"POST /v1/floors/hall-calls":          "Press the up or down button at a floor; the destination follows as a car call",
+			"POST /v1/elevators/{name}/car-calls": "Press a floor button inside an elevator",
+			"POST /v1/elevators":                  "Create a new passenger, freight or service elevator in the system",
+			"DELETE /v1/elevators":                "Delete an elevator from the system",
+			"GET /v1/health":                      "Check system health status",
+			"GET /v1/metrics":                     "Get system metrics",
+			"GET /v1":                             "Get API information",
+			"GET /v1/events":                      "Server-Sent Events stream of status and system events",
+			"POST /v1/admin/config/reload":        "Reload configuration and apply settings that do not need a restart (admin token)",
+			"/v1/elevators/{name}":                "Inspect (GET) or change (PATCH) the speed, door time, overload threshold and circuit breaker settings of an elevator",
+			"/v1/elevators/{name}/faults":         "Inspect (GET), inject (POST) or clear (DELETE) simulated faults when fault injection is enabled",
+			"GET /v1/buildings":                   "List the buildings served by this process",
+			"GET /v1/buildings/{building}":        "Describe a building: its floors, fleet size and request limit",
+			"/v1/buildings/{building}/...":        "The floors, elevators, health and metrics endpoints above, scoped to one building",
+			"GET /v1/cluster/status":              "Describe this cluster node, its leader and the replicated fleet and pending requests when clustering is enabled",
+			"/v1/webhooks":                        "List (GET) or register (POST) webhooks that receive signed event notifications when webhooks are enabled",
+			"/v1/webhooks/{id}":                   "Inspect (GET) or remove (DELETE) a webhook",
+			"/v1/alerts":                          "SLA alerts of every building (GET, filterable by state=ok|firing)",
+			"/v1/analytics":                       "Traffic report of a time range when analytics are enabled (GET, from/to in RFC 3339, building, peaks)",
+			"/v1/webhooks/{id}/deliveries":        "Delivery log of a webhook (GET, filterable by status); POST to .../{delivery}/redeliver sends a dead-lettered delivery again",
+			"GET /metrics":                        "Prometheus metrics endpoint",
+			"WebSocket /ws/status":                "Real-time elevator status updates",
 		},
 	}
 
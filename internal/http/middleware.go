@@ -185,15 +185,76 @@ func RecoveryMiddleware(logger *slog.Logger) Middleware {
 	}
 }
 
-// CORSMiddleware handles Cross-Origin Resource Sharing
+// CORSMiddleware handles Cross-Origin Resource Sharing for any origin
 func CORSMiddleware() Middleware {
+	return NewCORSPolicy("*", 24*time.Hour).Handler()
+}
+
+// CORSPolicy handles Cross-Origin Resource Sharing for a set of allowed
+// origins that can change while the server runs
+type CORSPolicy struct {
+	mutex   sync.RWMutex
+	origins map[string]bool
+	any     bool
+	maxAge  string
+}
+
+// NewCORSPolicy creates a CORS policy from a comma-separated list of allowed
+// origins, where "*" allows any origin
+func NewCORSPolicy(allowedOrigins string, maxAge time.Duration) *CORSPolicy {
+	p := &CORSPolicy{maxAge: strconv.Itoa(int(maxAge.Seconds()))}
+	p.SetAllowedOrigins(allowedOrigins)
+	return p
+}
+
+// SetAllowedOrigins replaces the allowed origins
+func (p *CORSPolicy) SetAllowedOrigins(allowedOrigins string) {
+	origins := make(map[string]bool)
+	allowAny := false
+	for _, origin := range strings.Split(allowedOrigins, ",") {
+		origin = strings.TrimSpace(origin)
+		if origin == "*" {
+			allowAny = true
+		} else if origin != "" {
+			origins[origin] = true
+		}
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.origins = origins
+	p.any = allowAny
+}
+
+// allowOrigin returns the Access-Control-Allow-Origin value for a request
+// origin, or an empty string when the origin is not allowed
+func (p *CORSPolicy) allowOrigin(origin string) string {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	if p.any {
+		return "*"
+	}
+	if p.origins[origin] {
+		return origin
+	}
+	return ""
+}
+
+// Handler returns the middleware handler function
+func (p *CORSPolicy) Handler() Middleware {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			w.Header().Set("Access-Control-Allow-Origin", "*")
-			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Request-ID")
-			w.Header().Set("Access-Control-Expose-Headers", "X-Request-ID")
-			w.Header().Set("Access-Control-Max-Age", "86400") // 24 hours
+			allowed := p.allowOrigin(r.Header.Get("Origin"))
+			if allowed != "*" {
+				w.Header().Add("Vary", "Origin")
+			}
+			if allowed != "" {
+				w.Header().Set("Access-Control-Allow-Origin", allowed)
+				w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+				w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Request-ID")
+				w.Header().Set("Access-Control-Expose-Headers", "X-Request-ID")
+				w.Header().Set("Access-Control-Max-Age", p.maxAge)
+			}
 
 			if r.Method == http.MethodOptions {
 				w.WriteHeader(http.StatusNoContent)
@@ -224,6 +285,17 @@ func NewRateLimitMiddleware(requestsPerMinute int, logger *slog.Logger) *RateLim
 	}
 }
 
+// Configure changes the number of requests allowed per client within window.
+// Requests already counted stay counted against the new limit.
+func (rl *RateLimitMiddleware) Configure(limit int, window time.Duration) {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+	rl.limit = limit
+	if window > 0 {
+		rl.window = window
+	}
+}
+
 // Handler returns the middleware handler function
 func (rl *RateLimitMiddleware) Handler() Middleware {
 	return func(next http.Handler) http.Handler {
@@ -402,6 +474,11 @@ func (w *responseWriterWrapper) Hijack() (net.Conn, *bufio.ReadWriter, error) {
 	return nil, nil, fmt.Errorf("ResponseWriter does not implement http.Hijacker")
 }
 
+// Unwrap returns the underlying writer for http.ResponseController
+func (w *responseWriterWrapper) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
 // Flush implements http.Flusher interface
 func (w *responseWriterWrapper) Flush() {
 	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
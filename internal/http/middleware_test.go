@@ -196,7 +196,7 @@ func TestCORSMiddleware(t *testing.T) {
 
 		// Check CORS headers
 		assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
-		assert.Equal(t, "GET, POST, PUT, DELETE, OPTIONS", w.Header().Get("Access-Control-Allow-Methods"))
+		assert.Equal(t, "GET, POST, PUT, PATCH, DELETE, OPTIONS", w.Header().Get("Access-Control-Allow-Methods"))
 		assert.Equal(t, "Content-Type, Authorization, X-Request-ID", w.Header().Get("Access-Control-Allow-Headers"))
 		assert.Equal(t, "X-Request-ID", w.Header().Get("Access-Control-Expose-Headers"))
 		assert.Equal(t, "86400", w.Header().Get("Access-Control-Max-Age"))
@@ -474,3 +474,48 @@ func TestMiddlewareIntegration(t *testing.T) {
 	assert.Contains(t, logOutput, "HTTP request started")
 	assert.Contains(t, logOutput, "HTTP request completed")
 }
+
+func TestCORSPolicy_AllowedOrigins(t *testing.T) {
+	policy := NewCORSPolicy("https://a.example.com, https://b.example.com", time.Hour)
+	handler := policy.Handler()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	request := func(origin string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/test", nil)
+		r.Header.Set("Origin", origin)
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	w := request("https://b.example.com")
+	assert.Equal(t, "https://b.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "3600", w.Header().Get("Access-Control-Max-Age"))
+	assert.Equal(t, "Origin", w.Header().Get("Vary"))
+
+	w = request("https://evil.example.com")
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+
+	policy.SetAllowedOrigins("https://evil.example.com")
+	w = request("https://evil.example.com")
+	assert.Equal(t, "https://evil.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	w = request("https://a.example.com")
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestRateLimitMiddleware_Configure(t *testing.T) {
+	rl := NewRateLimitMiddleware(1, slog.Default())
+	assert.True(t, rl.isAllowed("10.0.0.1"))
+	assert.False(t, rl.isAllowed("10.0.0.1"))
+
+	rl.Configure(3, 0)
+	assert.Equal(t, time.Minute, rl.window, "non-positive windows are ignored")
+	assert.True(t, rl.isAllowed("10.0.0.1"))
+	assert.True(t, rl.isAllowed("10.0.0.1"))
+	assert.False(t, rl.isAllowed("10.0.0.1"))
+
+	rl.Configure(3, 10*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	assert.True(t, rl.isAllowed("10.0.0.1"))
+}
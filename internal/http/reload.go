@@ -0,0 +1,103 @@
+package http
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/slavakukuyev/elevator-go/internal/infra/config"
+	"github.com/slavakukuyev/elevator-go/internal/infra/logging"
+)
+
+// ConfigReloadResponse lists the settings changed by a configuration reload
+type ConfigReloadResponse struct {
+	Changes []config.Change `json:"changes"`
+	Message string          `json:"message"`
+}
+
+// ReloadConfig reads the configuration again and applies the settings that
+// can change without a restart to the logger, the middleware, the manager of
+// every building and their elevators. Nothing is applied when the new
+// configuration is invalid or changes a restart-only setting.
+func (s *Server) ReloadConfig(ctx context.Context) ([]config.Change, error) {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	current := s.manager.Config()
+	updated, changes, err := config.Reload(current)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "configuration reload rejected",
+			slog.String("error", err.Error()))
+		return changes, err
+	}
+
+	for _, change := range changes {
+		s.logger.InfoContext(ctx, "configuration value changed",
+			slog.String("key", change.Key),
+			slog.String("old", change.Old),
+			slog.String("new", change.New))
+	}
+
+	logging.SetLevel(updated.LogLevel)
+	s.rateLimiter.Configure(updated.RateLimitRPM, updated.RateLimitWindow)
+	s.cors.SetAllowedOrigins(updated.CORSAllowedOrigins)
+	s.manager.ApplyConfig(ctx, updated)
+	s.applyBuildingConfigs(ctx, updated)
+
+	s.logger.InfoContext(ctx, "configuration reloaded",
+		slog.Int("changes", len(changes)))
+	return changes, nil
+}
+
+// applyBuildingConfigs applies a reloaded configuration, with each
+// building's overrides, to the managers of the other buildings
+func (s *Server) applyBuildingConfigs(ctx context.Context, updated *config.Config) {
+	if s.buildings == nil {
+		return
+	}
+
+	for _, building := range s.buildings.Buildings() {
+		if building.Manager == s.manager {
+			continue
+		}
+		cfg, err := updated.BuildingConfig(building.ID)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "building configuration not reloaded",
+				slog.String("building", building.ID),
+				slog.String("error", err.Error()))
+			continue
+		}
+		building.Manager.ApplyConfig(ctx, cfg)
+	}
+}
+
+// configReloadHandler reloads the configuration on demand for callers with
+// the admin token (POST /v1/admin/config/reload)
+func (s *Server) configReloadHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := logging.GetRequestID(r.Context())
+	rw := NewResponseWriter(w, s.logger, requestID)
+
+	if r.Method != http.MethodPost {
+		rw.WriteError(http.StatusMethodNotAllowed, ErrorCodeMethodNotAllowed,
+			"Method not allowed", "Only POST method is supported")
+		return
+	}
+	if !s.authenticateAdmin(r, rw) {
+		return
+	}
+
+	changes, err := s.ReloadConfig(r.Context())
+	if err != nil {
+		rw.WriteDomainError(err)
+		return
+	}
+
+	message := "Configuration reloaded"
+	if len(changes) == 0 {
+		message = "Configuration unchanged"
+	}
+	rw.WriteJSON(http.StatusOK, ConfigReloadResponse{
+		Changes: changes,
+		Message: message,
+	})
+}
@@ -121,13 +121,7 @@ func (rw *ResponseWriter) WriteJSON(statusCode int, data interface{}) {
 
 // WriteError writes a JSON error response with the standard API format
 func (rw *ResponseWriter) WriteError(statusCode int, errorCode, message, details string) {
-	apiError := &APIError{
-		Code:        errorCode,
-		Message:     message,
-		Details:     details,
-		RequestID:   rw.requestID,
-		UserMessage: getUserFriendlyMessage(errorCode),
-	}
+	apiError := newAPIError(errorCode, message, details, rw.requestID)
 
 	response := APIResponse{
 		Success:   false,
@@ -153,6 +147,23 @@ func (rw *ResponseWriter) WriteError(statusCode int, errorCode, message, details
 
 // WriteDomainError writes a domain error as a JSON response
 func (rw *ResponseWriter) WriteDomainError(err error) {
+	statusCode, apiError := newAPIErrorFromDomain(err, rw.requestID)
+	rw.WriteError(statusCode, apiError.Code, apiError.Message, apiError.Details)
+}
+
+// newAPIError builds an APIError with the user-friendly message for its code
+func newAPIError(errorCode, message, details, requestID string) *APIError {
+	return &APIError{
+		Code:        errorCode,
+		Message:     message,
+		Details:     details,
+		RequestID:   requestID,
+		UserMessage: getUserFriendlyMessage(errorCode),
+	}
+}
+
+// newAPIErrorFromDomain maps an error to its HTTP status code and APIError
+func newAPIErrorFromDomain(err error, requestID string) (int, *APIError) {
 	statusCode := http.StatusInternalServerError
 	errorCode := "INTERNAL_ERROR"
 	message := "Internal server error"
@@ -176,25 +187,31 @@ func (rw *ResponseWriter) WriteDomainError(err error) {
 			statusCode = http.StatusInternalServerError
 			errorCode = "INTERNAL_ERROR"
 			message = "Internal server error"
+		case domain.ErrTypeExternal:
+			statusCode = http.StatusServiceUnavailable
+			errorCode = "SERVICE_UNAVAILABLE"
+			message = "Service unavailable"
 		}
 		details = domainErr.Error()
 	} else {
 		details = err.Error()
 	}
 
-	rw.WriteError(statusCode, errorCode, message, details)
+	return statusCode, newAPIError(errorCode, message, details, requestID)
 }
 
 // getUserFriendlyMessage returns user-friendly messages for error codes
 func getUserFriendlyMessage(errorCode string) string {
 	messages := map[string]string{
-		"VALIDATION_ERROR":   "Please check your input and try again.",
-		"NOT_FOUND":          "The requested resource was not found.",
-		"CONFLICT":           "The requested operation conflicts with existing data.",
-		"INTERNAL_ERROR":     "Something went wrong on our end. Please try again later.",
-		"METHOD_NOT_ALLOWED": "This HTTP method is not supported for this endpoint.",
-		"INVALID_JSON":       "The provided JSON is malformed.",
-		"RATE_LIMITED":       "Too many requests. Please slow down.",
+		"VALIDATION_ERROR":    "Please check your input and try again.",
+		"NOT_FOUND":           "The requested resource was not found.",
+		"CONFLICT":            "The requested operation conflicts with existing data.",
+		"INTERNAL_ERROR":      "Something went wrong on our end. Please try again later.",
+		"SERVICE_UNAVAILABLE": "The service cannot handle the request right now. Please try again shortly.",
+		"METHOD_NOT_ALLOWED":  "This HTTP method is not supported for this endpoint.",
+		"INVALID_JSON":        "The provided JSON is malformed.",
+		"RATE_LIMITED":        "Too many requests. Please slow down.",
+		"UNAUTHORIZED":        "Valid credentials are required for this request.",
 	}
 
 	if msg, exists := messages[errorCode]; exists {
@@ -212,4 +229,5 @@ const (
 	ErrorCodeMethodNotAllowed = "METHOD_NOT_ALLOWED"
 	ErrorCodeInvalidJSON      = "INVALID_JSON"
 	ErrorCodeRateLimit        = "RATE_LIMITED"
+	ErrorCodeUnauthorized     = "UNAUTHORIZED"
 )
@@ -216,6 +216,13 @@ func TestResponseWriter_WriteDomainError(t *testing.T) {
 			expectedCode:   "INTERNAL_ERROR",
 			expectedMsg:    "Internal server error",
 		},
+		{
+			name:           "external domain error",
+			err:            domain.NewExternalError("cluster has no leader", nil),
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedCode:   "SERVICE_UNAVAILABLE",
+			expectedMsg:    "Service unavailable",
+		},
 		{
 			name:           "generic error",
 			err:            assert.AnError,
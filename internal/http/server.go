@@ -7,17 +7,22 @@ import (
 	"log"
 	"log/slog"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 
+	"github.com/slavakukuyev/elevator-go/internal/analytics"
 	"github.com/slavakukuyev/elevator-go/internal/constants"
 	"github.com/slavakukuyev/elevator-go/internal/domain"
+	"github.com/slavakukuyev/elevator-go/internal/elevator"
 	"github.com/slavakukuyev/elevator-go/internal/infra/config"
 	"github.com/slavakukuyev/elevator-go/internal/infra/health"
 	"github.com/slavakukuyev/elevator-go/internal/infra/logging"
 	"github.com/slavakukuyev/elevator-go/internal/manager"
+	"github.com/slavakukuyev/elevator-go/internal/sla"
+	"github.com/slavakukuyev/elevator-go/internal/webhook"
 	"github.com/slavakukuyev/elevator-go/metrics"
 )
 
@@ -28,26 +33,70 @@ type Server struct {
 	cfg           *config.Config
 	logger        *slog.Logger
 	healthService *health.HealthService
+	readiness     *health.ReadinessChecker
+
+	// rateLimiter and cors hold the middleware settings a configuration
+	// reload can change; reloadMu serialises reloads
+	rateLimiter *RateLimitMiddleware
+	cors        *CORSPolicy
+	reloadMu    sync.Mutex
+
+	// buildings serves the namespaced API of every building
+	buildings *manager.Registry
+
+	// v1 serves the default building; clusterAPI, when set, serves the API
+	// cluster nodes use to reach each other
+	v1         *V1Handlers
+	clusterAPI http.Handler
+
+	// webhooks, when set, serves the webhook subscriptions under /v1/webhooks
+	webhooks *webhook.Dispatcher
+
+	// alerts, when set, serves the SLA alerts under /v1/alerts
+	alerts *sla.Engine
+
+	// analytics, when set, serves traffic reports under /v1/analytics
+	analytics *analytics.Store
+
+	// streams is cancelled on shutdown to end long-lived event streams,
+	// which http.Server.Shutdown would otherwise wait on
+	streams       context.Context
+	cancelStreams context.CancelFunc
 }
 
 // FloorRequestBody represents the JSON request body.
 type FloorRequestBody struct {
-	From int `json:"from"`
-	To   int `json:"to"`
+	From       int    `json:"from"`
+	To         int    `json:"to"`
+	Cargo      bool   `json:"cargo,omitempty"`      // Optional: served by freight cars only
+	Priority   string `json:"priority,omitempty"`   // Optional: normal (default), high or emergency
+	Accessible bool   `json:"accessible,omitempty"` // Optional: extra door time, lightly loaded cars and announcements
 }
 
 // ElevatorRequestBody - represents the JSON request body.
 type ElevatorRequestBody struct {
 	Name              string `json:"name"`
+	Type              string `json:"type,omitempty"` // Optional: passenger (default), freight or service
 	MinFloor          int    `json:"min_floor"`
 	MaxFloor          int    `json:"max_floor"`
 	OverloadThreshold *int   `json:"overload_threshold,omitempty"` // Optional: defaults to 12 if not provided
+
+	// Optional tuning; omitted settings use the configured defaults
+	EachFloorDuration *string                      `json:"each_floor_duration,omitempty"`
+	OpenDoorDuration  *string                      `json:"open_door_duration,omitempty"`
+	CircuitBreaker    *CircuitBreakerTuningRequest `json:"circuit_breaker,omitempty"`
 }
 
+// statusSubscriberBuffer bounds the status updates queued for one WebSocket
+// client; older updates are dropped when the client falls behind
+const statusSubscriberBuffer = 16
+
 // upgrader is used to upgrade HTTP connections to WebSocket connections.
 var upgrader = websocket.Upgrader{
 	// Allow all origins for demonstration purposes.
 	CheckOrigin: func(r *http.Request) bool { return true },
+	// Clients opt in to the delta protocol through the subprotocol header
+	Subprotocols: []string{wsProtocolV2},
 	// Set buffer sizes for better performance
 	ReadBufferSize:  1024,
 	WriteBufferSize: 1024,
@@ -83,6 +132,7 @@ func NewServer(cfg *config.Config, port int, manager *manager.Manager) *Server {
 		logger:        slog.With(slog.String("component", constants.ComponentHTTPServer)),
 		healthService: health.NewHealthService(30 * time.Second), // 30 second cache TTL
 	}
+	s.streams, s.cancelStreams = context.WithCancel(context.Background())
 
 	// Initialize health checks
 	s.setupHealthChecks(manager)
@@ -91,19 +141,27 @@ func NewServer(cfg *config.Config, port int, manager *manager.Manager) *Server {
 
 	// Create versioned handlers
 	v1Handlers := NewV1Handlers(manager, cfg, s.logger)
+	s.v1 = v1Handlers
 
-	// Create rate limiter using configuration
-	rateLimiter := NewRateLimitMiddleware(cfg.RateLimitRPM, s.logger)
+	// Create rate limiter and CORS policy using configuration
+	s.rateLimiter = NewRateLimitMiddleware(cfg.RateLimitRPM, s.logger)
+	s.rateLimiter.Configure(cfg.RateLimitRPM, cfg.RateLimitWindow)
+	s.cors = NewCORSPolicy(cfg.CORSAllowedOrigins, cfg.CORSMaxAge)
 
 	// Create middleware chain
-	middlewareChain := ChainMiddleware(
+	middlewares := []Middleware{
 		RequestIDMiddleware(),
 		LoggingMiddleware(s.logger),
 		RecoveryMiddleware(s.logger),
-		CORSMiddleware(),
+	}
+	if cfg.CORSEnabled {
+		middlewares = append(middlewares, s.cors.Handler())
+	}
+	middlewares = append(middlewares,
 		SecurityHeadersMiddleware(),
-		rateLimiter.Handler(),
+		s.rateLimiter.Handler(),
 	)
+	middlewareChain := ChainMiddleware(middlewares...)
 
 	// Create a new ServeMux to handle different routes
 	mux := http.NewServeMux()
@@ -111,19 +169,39 @@ func NewServer(cfg *config.Config, port int, manager *manager.Manager) *Server {
 	// === V1 API ROUTES (New versioned API) ===
 	mux.HandleFunc("/v1", v1Handlers.APIInfoHandler)
 	mux.HandleFunc("/v1/floors/request", v1Handlers.FloorRequestHandler)
-	mux.HandleFunc("/v1/elevators", func(w http.ResponseWriter, r *http.Request) {
-		switch r.Method {
-		case http.MethodPost:
-			v1Handlers.ElevatorCreateHandler(w, r)
-		case http.MethodDelete:
-			v1Handlers.ElevatorDeleteHandler(w, r)
-		default:
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		}
-	})
+	mux.HandleFunc("/v1/floors/hall-calls", v1Handlers.HallCallHandler)
+	mux.HandleFunc("/v1/elevators", v1Handlers.ElevatorsHandler)
+	mux.HandleFunc("/v1/elevators/{name}", v1Handlers.ElevatorTuningHandler)
+	mux.HandleFunc("/v1/elevators/{name}/car-calls", v1Handlers.CarCallHandler)
+	if cfg.FaultInjectionEnabled {
+		mux.HandleFunc("/v1/elevators/{name}/faults", v1Handlers.ElevatorFaultsHandler)
+	}
 	mux.HandleFunc("/v1/health", v1Handlers.HealthHandler)
 	mux.HandleFunc("/v1/metrics", v1Handlers.MetricsHandler)
 
+	// === BUILDING ROUTES (the same API for every building of the registry) ===
+	mux.HandleFunc("/v1/buildings", s.buildingsHandler)
+	mux.HandleFunc("/v1/buildings/{building}", s.buildingHandler)
+	mux.HandleFunc("/v1/buildings/{building}/floors/request", s.inBuilding((*V1Handlers).FloorRequestHandler))
+	mux.HandleFunc("/v1/buildings/{building}/floors/hall-calls", s.inBuilding((*V1Handlers).HallCallHandler))
+	mux.HandleFunc("/v1/buildings/{building}/elevators", s.inBuilding((*V1Handlers).ElevatorsHandler))
+	mux.HandleFunc("/v1/buildings/{building}/elevators/{name}", s.inBuilding((*V1Handlers).ElevatorTuningHandler))
+	mux.HandleFunc("/v1/buildings/{building}/elevators/{name}/car-calls", s.inBuilding((*V1Handlers).CarCallHandler))
+	if cfg.FaultInjectionEnabled {
+		mux.HandleFunc("/v1/buildings/{building}/elevators/{name}/faults", s.inBuilding((*V1Handlers).ElevatorFaultsHandler))
+	}
+	mux.HandleFunc("/v1/buildings/{building}/health", s.inBuilding((*V1Handlers).HealthHandler))
+	mux.HandleFunc("/v1/buildings/{building}/metrics", s.inBuilding((*V1Handlers).MetricsHandler))
+	mux.HandleFunc("/v1/cluster/", s.clusterHandler)
+	mux.HandleFunc("/v1/events", s.eventsHandler)
+	mux.HandleFunc("/v1/webhooks", s.webhooksHandler)
+	mux.HandleFunc("/v1/webhooks/{id}", s.webhookHandler)
+	mux.HandleFunc("/v1/webhooks/{id}/deliveries", s.webhookDeliveriesHandler)
+	mux.HandleFunc("/v1/webhooks/{id}/deliveries/{delivery}/redeliver", s.webhookRedeliverHandler)
+	mux.HandleFunc("/v1/alerts", s.alertsHandler)
+	mux.HandleFunc("/v1/analytics", s.analyticsHandler)
+	mux.HandleFunc("/v1/admin/config/reload", s.configReloadHandler)
+
 	// Enhanced health endpoints
 	mux.HandleFunc("/v1/health/live", s.livenessHandler)
 	mux.HandleFunc("/v1/health/ready", s.readinessHandler)
@@ -150,6 +228,7 @@ func NewServer(cfg *config.Config, port int, manager *manager.Manager) *Server {
 		WriteTimeout: cfg.WriteTimeout,
 		IdleTimeout:  cfg.IdleTimeout,
 	}
+	s.httpServer.RegisterOnShutdown(s.cancelStreams)
 
 	return s
 }
@@ -201,8 +280,8 @@ func (s *Server) setupHealthChecks(manager *manager.Manager) {
 	s.healthService.Register(managerHealthChecker)
 
 	// Readiness checker (depends on manager)
-	readinessChecker := health.NewReadinessChecker(managerHealthChecker)
-	s.healthService.Register(readinessChecker)
+	s.readiness = health.NewReadinessChecker(managerHealthChecker)
+	s.healthService.Register(s.readiness)
 
 	s.logger.Info("health checks initialized",
 		slog.Int("registered_checkers", 4))
@@ -222,7 +301,8 @@ func (s *Server) livenessHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	if result.Status == health.StatusHealthy {
+	// A degraded application still serves traffic
+	if result.Status == health.StatusHealthy || result.Status == health.StatusDegraded {
 		w.WriteHeader(http.StatusOK)
 	} else {
 		w.WriteHeader(http.StatusServiceUnavailable)
@@ -337,7 +417,7 @@ func (s *Server) floorHandler(w http.ResponseWriter, r *http.Request) {
 	startTime := time.Now()
 	var elevatorName string
 	defer func() {
-		requestDuration(elevatorName, startTime)
+		requestDuration(s.manager.Config().BuildingID, elevatorName, startTime)
 	}()
 
 	if r.Method != http.MethodPost {
@@ -379,7 +459,7 @@ func (s *Server) floorHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Request an elevator going from floor requestBody.From to floor requestBody.To
-	elevator, err := s.manager.RequestElevator(ctx, requestBody.From, requestBody.To)
+	elevatorName, err = s.v1.requestElevator(ctx, requestBody.From, requestBody.To, domain.CallOptions{})
 	if err != nil {
 		s.logger.ErrorContext(ctx, "elevator request failed",
 			slog.Int("from_floor", requestBody.From),
@@ -403,10 +483,6 @@ func (s *Server) floorHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if elevator != nil {
-		elevatorName = elevator.Name()
-	}
-
 	response := fmt.Sprintf("elevator %s received request: from %d to %d", elevatorName, requestBody.From, requestBody.To)
 	w.Header().Set("Content-Type", constants.ContentTypeTextPlain)
 	w.WriteHeader(http.StatusOK)
@@ -496,7 +572,15 @@ func (s *Server) elevatorHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = s.manager.AddElevator(ctx, s.cfg, requestBody.Name, requestBody.MinFloor, requestBody.MaxFloor, s.cfg.EachFloorDuration, s.cfg.OpenDoorDuration, s.cfg.DefaultOverloadThreshold)
+	cfg := s.manager.Config()
+	settings := cfg.ElevatorSettings(requestBody.Name)
+	_, err = s.v1.addElevator(ctx, cfg, domain.ElevatorTypePassenger, requestBody.Name, requestBody.MinFloor, requestBody.MaxFloor,
+		elevator.Tuning{
+			EachFloorDuration: settings.EachFloorDuration,
+			OpenDoorDuration:  settings.OpenDoorDuration,
+			OverloadThreshold: settings.OverloadThreshold,
+			CircuitBreaker:    cfg.CircuitBreakerSettings(),
+		}, elevator.TuningPatch{})
 	if err != nil {
 		s.logger.ErrorContext(ctx, "failed to create elevator",
 			slog.String("elevator_name", requestBody.Name),
@@ -551,15 +635,17 @@ func (s *Server) Shutdown() error {
 	return s.httpServer.Shutdown(ctx)
 }
 
-func requestDuration(elevatorName string, start time.Time) {
+func requestDuration(building, elevatorName string, start time.Time) {
 	end := time.Now()
 	durationIMilliseconds := end.Sub(start).Milliseconds()
 	durationInSeconds := float64(durationIMilliseconds) / 1000.0
-	metrics.RequestDurationHistogram(elevatorName, durationInSeconds)
+	metrics.RequestDurationHistogram(building, elevatorName, durationInSeconds)
 }
 
 // statusWebSocketHandler handles WebSocket connections for elevator status updates.
-// It periodically sends the current status (retrieved from the manager) to the connected client.
+// It subscribes to the manager's status broadcaster and forwards every change to
+// the connected client, starting with the current status. Clients that negotiate
+// protocol v2 get subscriptions, snapshots and deltas instead (see ws_protocol.go).
 func (s *Server) statusWebSocketHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := logging.NewContextWithCorrelation(r.Context())
 
@@ -580,23 +666,21 @@ func (s *Server) statusWebSocketHandler(w http.ResponseWriter, r *http.Request)
 
 	s.logger.InfoContext(ctx, "WebSocket connection established")
 
-	// Send an initial status immediately upon connection.
-	status, err := s.manager.GetStatus()
-	if err != nil {
-		s.logger.ErrorContext(ctx, "failed to get initial elevator status",
-			slog.String("error", err.Error()))
-		return
-	}
-	if err := ws.WriteJSON(status); err != nil {
-		s.logger.ErrorContext(ctx, "failed to send initial status via WebSocket",
-			slog.String("error", err.Error()))
+	if wantsStatusProtocolV2(ws, r) {
+		newStatusSession(ws, s.manager, s.v1.cluster, s.logger, logging.GetRequestID(r.Context()), statusSessionTimeouts{
+			write: s.cfg.WebSocketWriteTimeout,
+			read:  s.cfg.WebSocketReadTimeout,
+			ping:  s.cfg.WebSocketPingInterval,
+		}).run(ctx)
 		return
 	}
 
-	// Create tickers for status updates and ping/pong keep-alive
-	statusTicker := time.NewTicker(s.cfg.StatusUpdateInterval)
-	defer statusTicker.Stop()
+	// The latest status is queued on subscription, so the client receives it
+	// immediately upon connection
+	sub := s.manager.Broadcaster().Subscribe(statusSubscriberBuffer)
+	defer sub.Close()
 
+	// Create ticker for ping/pong keep-alive
 	pingTicker := time.NewTicker(s.cfg.WebSocketPingInterval)
 	defer pingTicker.Stop()
 
@@ -661,35 +745,10 @@ func (s *Server) statusWebSocketHandler(w http.ResponseWriter, r *http.Request)
 				return
 			}
 
-		case <-statusTicker.C:
-			// Create a timeout context for this status update using configuration
-			updateCtx, updateCancel := context.WithTimeout(wsCtx, s.cfg.StatusUpdateTimeout)
-
-			// Retrieve the latest elevator status with timeout
-			statusCh := make(chan statusResult, 1)
-			go func() {
-				st, errS := s.manager.GetStatus()
-				statusCh <- statusResult{status: st, err: errS}
-			}()
-
-			var st map[string]interface{}
-			var errS error
-
-			select {
-			case <-updateCtx.Done():
-				s.logger.WarnContext(ctx, "status update timed out")
-				updateCancel()
-				continue
-			case result := <-statusCh:
-				st = result.status
-				errS = result.err
-			}
-			updateCancel()
-
-			if errS != nil {
-				s.logger.ErrorContext(ctx, "failed to get status update",
-					slog.String("error", errS.Error()))
-				continue
+		case update, ok := <-sub.C:
+			if !ok {
+				s.logger.InfoContext(ctx, "status broadcaster stopped, closing WebSocket connection")
+				return
 			}
 
 			// Set write deadline and send the updated status to the client
@@ -698,8 +757,7 @@ func (s *Server) statusWebSocketHandler(w http.ResponseWriter, r *http.Request)
 					slog.String("error", err.Error()))
 				return
 			}
-			err = ws.WriteJSON(st)
-			if err != nil {
+			if err := ws.WriteMessage(websocket.TextMessage, update.JSON); err != nil {
 				s.logger.ErrorContext(ctx, "failed to send status update via WebSocket",
 					slog.String("error", err.Error()))
 				return
@@ -708,12 +766,6 @@ func (s *Server) statusWebSocketHandler(w http.ResponseWriter, r *http.Request)
 	}
 }
 
-// statusResult is a helper struct for handling status updates with timeouts
-type statusResult struct {
-	status map[string]interface{}
-	err    error
-}
-
 // healthHandler handles health check requests
 func (s *Server) healthHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := logging.NewContextWithCorrelation(r.Context())
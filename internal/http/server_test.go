@@ -5,17 +5,27 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"net/netip"
+	"net/url"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 
+	"github.com/slavakukuyev/elevator-go/internal/analytics"
+	"github.com/slavakukuyev/elevator-go/internal/domain"
 	"github.com/slavakukuyev/elevator-go/internal/factory"
 	"github.com/slavakukuyev/elevator-go/internal/infra/config"
+	"github.com/slavakukuyev/elevator-go/internal/infra/observability"
 	"github.com/slavakukuyev/elevator-go/internal/manager"
+	"github.com/slavakukuyev/elevator-go/internal/sla"
+	"github.com/slavakukuyev/elevator-go/internal/webhook"
 )
 
 func buildServerTestConfig() *config.Config {
@@ -34,6 +44,7 @@ func buildServerTestConfig() *config.Config {
 		CircuitBreakerEnabled:          true,
 		CircuitBreakerMaxFailures:      5,
 		CircuitBreakerResetTimeout:     time.Second * 30,
+		CircuitBreakerHalfOpenLimit:    3,
 		CircuitBreakerFailureThreshold: 0.6,
 	}
 }
@@ -522,3 +533,672 @@ func TestServer_EdgeCases(t *testing.T) {
 		}
 	})
 }
+
+func TestV1Handlers_ElevatorFaultsHandler(t *testing.T) {
+	cfg := buildServerTestConfig()
+	m := manager.New(cfg, &factory.StandardElevatorFactory{})
+	defer m.Shutdown()
+	require.NoError(t, m.AddElevator(context.Background(), cfg, "A", 0, 9,
+		cfg.EachFloorDuration, cfg.OpenDoorDuration, 12))
+
+	handlers := NewV1Handlers(m, cfg, slog.Default())
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/elevators/{name}/faults", handlers.ElevatorFaultsHandler)
+
+	serve := func(method, target, body string) (*httptest.ResponseRecorder, APIResponse) {
+		req := httptest.NewRequest(method, target, bytes.NewBufferString(body))
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+		var response APIResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		return rr, response
+	}
+
+	rr, response := serve(http.MethodPost, "/v1/elevators/A/faults", `{"type":"slow_travel","slowdown_factor":2}`)
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	assert.Equal(t, map[string]any{
+		"name":   "A",
+		"faults": []any{map[string]any{"type": "slow_travel", "slowdown_factor": 2.0}},
+	}, response.Data)
+
+	rr, response = serve(http.MethodPost, "/v1/elevators/A/faults", `{"type":"meteor_strike"}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, ErrorCodeValidation, response.Error.Code)
+
+	rr, _ = serve(http.MethodPost, "/v1/elevators/A/faults", `{"type":`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr, response = serve(http.MethodGet, "/v1/elevators/missing/faults", "")
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Equal(t, ErrorCodeNotFound, response.Error.Code)
+
+	rr, _ = serve(http.MethodDelete, "/v1/elevators/A/faults?type=door_failure", "")
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	rr, response = serve(http.MethodDelete, "/v1/elevators/A/faults?type=slow_travel", "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, map[string]any{"name": "A", "faults": []any{}}, response.Data)
+
+	rr, _ = serve(http.MethodPut, "/v1/elevators/A/faults", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+}
+
+func TestV1Handlers_ElevatorTuning(t *testing.T) {
+	cfg := buildServerTestConfig()
+	m := manager.New(cfg, &factory.StandardElevatorFactory{})
+	defer m.Shutdown()
+
+	handlers := NewV1Handlers(m, cfg, slog.Default())
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/elevators", handlers.ElevatorCreateHandler)
+	mux.HandleFunc("/v1/elevators/{name}", handlers.ElevatorTuningHandler)
+
+	serve := func(method, target, body string) (*httptest.ResponseRecorder, APIResponse) {
+		req := httptest.NewRequest(method, target, bytes.NewBufferString(body))
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+		var response APIResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		return rr, response
+	}
+	tuningOf := func(response APIResponse) map[string]any {
+		data, ok := response.Data.(map[string]any)
+		require.True(t, ok)
+		tuning, ok := data["tuning"].(map[string]any)
+		require.True(t, ok)
+		return tuning
+	}
+
+	// Create a fast express car with its own breaker settings
+	rr, response := serve(http.MethodPost, "/v1/elevators", `{"name":"Express","min_floor":0,"max_floor":20,
+		"each_floor_duration":"100ms","open_door_duration":"1s","overload_threshold":20,
+		"circuit_breaker":{"max_failures":7}}`)
+	require.Equal(t, http.StatusCreated, rr.Code)
+	tuning := tuningOf(response)
+	assert.Equal(t, "100ms", tuning["each_floor_duration"])
+	assert.Equal(t, "1s", tuning["open_door_duration"])
+	assert.Equal(t, 20.0, tuning["overload_threshold"])
+	assert.Equal(t, 7.0, tuning["circuit_breaker"].(map[string]any)["max_failures"])
+
+	rr, response = serve(http.MethodPost, "/v1/elevators", `{"name":"Bad","min_floor":0,"max_floor":9,"each_floor_duration":"fast"}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, ErrorCodeValidation, response.Error.Code)
+	assert.Nil(t, m.GetElevator("Bad"))
+
+	// Slow it down while it runs; other settings are kept
+	rr, response = serve(http.MethodPatch, "/v1/elevators/Express", `{"each_floor_duration":"2s","circuit_breaker":{"reset_timeout":"45s"}}`)
+	require.Equal(t, http.StatusOK, rr.Code)
+	tuning = tuningOf(response)
+	assert.Equal(t, "2s", tuning["each_floor_duration"])
+	assert.Equal(t, "1s", tuning["open_door_duration"])
+	breaker := tuning["circuit_breaker"].(map[string]any)
+	assert.Equal(t, 7.0, breaker["max_failures"])
+	assert.Equal(t, "45s", breaker["reset_timeout"])
+	assert.Equal(t, 2*time.Second, m.GetElevator("Express").Tuning().EachFloorDuration)
+
+	rr, response = serve(http.MethodGet, "/v1/elevators/Express", "")
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "2s", tuningOf(response)["each_floor_duration"])
+
+	rr, response = serve(http.MethodPatch, "/v1/elevators/Express", `{"overload_threshold":0}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, ErrorCodeValidation, response.Error.Code)
+
+	rr, _ = serve(http.MethodPatch, "/v1/elevators/Express", `{"overload_threshold":`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr, response = serve(http.MethodPatch, "/v1/elevators/missing", `{}`)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Equal(t, ErrorCodeNotFound, response.Error.Code)
+
+	rr, _ = serve(http.MethodPut, "/v1/elevators/Express", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+}
+
+func TestV1Handlers_ElevatorTypes(t *testing.T) {
+	cfg := buildServerTestConfig()
+	cfg.DefaultOverloadThreshold = 12
+	cfg.StaffAPIToken = "staff-secret"
+	m := manager.New(cfg, &factory.StandardElevatorFactory{})
+	defer m.Shutdown()
+
+	handlers := NewV1Handlers(m, cfg, slog.Default())
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/elevators", handlers.ElevatorCreateHandler)
+	mux.HandleFunc("/v1/floors/request", handlers.FloorRequestHandler)
+
+	serve := func(target, body, staffToken string) (*httptest.ResponseRecorder, map[string]any) {
+		req := httptest.NewRequest(http.MethodPost, target, bytes.NewBufferString(body))
+		if staffToken != "" {
+			req.Header.Set(StaffTokenHeader, staffToken)
+		}
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+		var response APIResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		data, _ := response.Data.(map[string]any)
+		return rr, data
+	}
+
+	// Freight cars default to the freight profile: slower, longer door time
+	// and a smaller capacity
+	rr, data := serve("/v1/elevators", `{"name":"Freight","type":"freight","min_floor":0,"max_floor":9}`, "")
+	require.Equal(t, http.StatusCreated, rr.Code)
+	assert.Equal(t, "freight", data["type"])
+	tuning := data["tuning"].(map[string]any)
+	assert.Equal(t, "100ms", tuning["each_floor_duration"])
+	assert.Equal(t, "150ms", tuning["open_door_duration"])
+	assert.Equal(t, 6.0, tuning["overload_threshold"])
+
+	rr, _ = serve("/v1/elevators", `{"name":"Lift","type":"dumbwaiter","min_floor":0,"max_floor":9}`, "")
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr, _ = serve("/v1/elevators", `{"name":"Service","type":"service","min_floor":0,"max_floor":9}`, "")
+	require.Equal(t, http.StatusCreated, rr.Code)
+
+	// Only cargo and staff calls reach the reserved cars
+	rr, _ = serve("/v1/floors/request", `{"from":1,"to":5}`, "")
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	rr, data = serve("/v1/floors/request", `{"from":1,"to":5,"cargo":true}`, "")
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "Freight", data["elevator_name"])
+
+	rr, data = serve("/v1/floors/request", `{"from":2,"to":6}`, "staff-secret")
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "Service", data["elevator_name"])
+
+	rr, _ = serve("/v1/floors/request", `{"from":2,"to":6}`, "guess")
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+}
+
+func TestV1Handlers_FloorRequestOptions(t *testing.T) {
+	cfg := buildServerTestConfig()
+	cfg.DefaultOverloadThreshold = 12
+	cfg.StaffAPIToken = "staff-secret"
+	m := manager.New(cfg, &factory.StandardElevatorFactory{})
+	defer m.Shutdown()
+	require.NoError(t, m.AddElevator(context.Background(), cfg, "A", 0, 9,
+		cfg.EachFloorDuration, cfg.OpenDoorDuration, cfg.DefaultOverloadThreshold))
+
+	handlers := NewV1Handlers(m, cfg, slog.Default())
+	serve := func(body string, staffToken ...string) (*httptest.ResponseRecorder, map[string]any) {
+		req := httptest.NewRequest(http.MethodPost, "/v1/floors/request", bytes.NewBufferString(body))
+		if len(staffToken) > 0 {
+			req.Header.Set(StaffTokenHeader, staffToken[0])
+		}
+		rr := httptest.NewRecorder()
+		handlers.FloorRequestHandler(rr, req)
+		var response APIResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		data, _ := response.Data.(map[string]any)
+		return rr, data
+	}
+
+	rr, data := serve(`{"from":1,"to":5}`)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "normal", data["priority"])
+
+	// Emergency calls take a car out of service, so only staff may place them
+	rr, _ = serve(`{"from":2,"to":7,"priority":"emergency"}`)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	assert.False(t, m.GetElevator("A").IsReserved())
+
+	rr, data = serve(`{"from":2,"to":7,"priority":"emergency"}`, "staff-secret")
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "emergency", data["priority"])
+	assert.True(t, m.GetElevator("A").IsReserved())
+
+	rr, _ = serve(`{"from":1,"to":5,"priority":"urgent"}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr, data = serve(`{"from":8,"to":3,"accessible":true}`)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, true, data["accessible"])
+	assert.True(t, m.GetElevator("A").Directions().NeedsAssistance(domain.DirectionDown, 8))
+}
+
+func TestV1Handlers_CarAndHallCalls(t *testing.T) {
+	cfg := buildServerTestConfig()
+	cfg.DefaultOverloadThreshold = 12
+	m := manager.New(cfg, &factory.StandardElevatorFactory{})
+	defer m.Shutdown()
+	require.NoError(t, m.AddElevator(context.Background(), cfg, "A", 0, 9,
+		cfg.EachFloorDuration, cfg.OpenDoorDuration, cfg.DefaultOverloadThreshold))
+
+	handlers := NewV1Handlers(m, cfg, slog.Default())
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/floors/hall-calls", handlers.HallCallHandler)
+	mux.HandleFunc("/v1/elevators/{name}/car-calls", handlers.CarCallHandler)
+
+	serve := func(method, target, body string) (*httptest.ResponseRecorder, map[string]any) {
+		req := httptest.NewRequest(method, target, bytes.NewBufferString(body))
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+		var response APIResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		data, _ := response.Data.(map[string]any)
+		return rr, data
+	}
+
+	rr, data := serve(http.MethodPost, "/v1/floors/hall-calls", `{"floor":5,"direction":"down"}`)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "A", data["elevator_name"])
+	assert.Equal(t, "down", data["direction"])
+
+	rr, _ = serve(http.MethodPost, "/v1/floors/hall-calls", `{"floor":5,"direction":"sideways"}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr, data = serve(http.MethodPost, "/v1/elevators/A/car-calls", `{"floor":7}`)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "up", data["direction"])
+	assert.True(t, m.GetElevator("A").HasStop(domain.DirectionUp, domain.NewFloor(7)))
+
+	rr, _ = serve(http.MethodPost, "/v1/elevators/A/car-calls", `{}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr, _ = serve(http.MethodPost, "/v1/elevators/A/car-calls", `{"floor":42}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr, _ = serve(http.MethodPost, "/v1/elevators/missing/car-calls", `{"floor":3}`)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	rr, _ = serve(http.MethodGet, "/v1/elevators/A/car-calls", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+}
+
+func TestServer_ConfigReloadHandler(t *testing.T) {
+	t.Setenv("ADMIN_API_TOKEN", "admin-secret")
+	cfg, err := config.InitConfig()
+	require.NoError(t, err)
+	elevatorManager := manager.New(cfg, &factory.StandardElevatorFactory{})
+	defer elevatorManager.Shutdown()
+	server := NewServer(cfg, 8080, elevatorManager)
+
+	reload := func(method string, adminToken ...string) (*httptest.ResponseRecorder, APIResponse) {
+		req := httptest.NewRequest(method, "/v1/admin/config/reload", nil)
+		if len(adminToken) > 0 {
+			req.Header.Set(AdminTokenHeader, adminToken[0])
+		}
+		rr := httptest.NewRecorder()
+		server.configReloadHandler(rr, req)
+		var response APIResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		return rr, response
+	}
+
+	t.Setenv("RATE_LIMIT_RPM", "500")
+	t.Setenv("CORS_ALLOWED_ORIGINS", "https://ops.example.com")
+
+	// Only callers with the admin token may reload
+	rr, _ := reload(http.MethodPost)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	rr, _ = reload(http.MethodPost, "guess")
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	assert.Equal(t, cfg.RateLimitRPM, elevatorManager.Config().RateLimitRPM)
+
+	rr, response := reload(http.MethodPost, "admin-secret")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	data := response.Data.(map[string]any)
+	assert.Len(t, data["changes"], 2)
+	assert.Equal(t, 500, elevatorManager.Config().RateLimitRPM)
+	assert.Equal(t, 500, server.rateLimiter.limit)
+	assert.Equal(t, "https://ops.example.com", server.cors.allowOrigin("https://ops.example.com"))
+	assert.Empty(t, server.cors.allowOrigin("https://other.example.com"))
+
+	// Restart-only settings reject the whole reload
+	t.Setenv("RATE_LIMIT_RPM", "600")
+	t.Setenv("PORT", "7001")
+	rr, response = reload(http.MethodPost, "admin-secret")
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, response.Error.Details, "require a restart: PORT")
+	assert.Equal(t, 500, server.rateLimiter.limit)
+
+	rr, _ = reload(http.MethodGet)
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+}
+
+func TestServer_BuildingRoutes(t *testing.T) {
+	cfg := buildServerTestConfig()
+	cfg.RateLimitRPM = 1000
+	cfg.DefaultOverloadThreshold = 12
+	elevatorFactory := &factory.StandardElevatorFactory{}
+
+	registry := manager.NewRegistry(elevatorFactory)
+	defer registry.Shutdown()
+	mainManager := manager.New(cfg, elevatorFactory)
+	_, err := registry.Register("main", mainManager)
+	require.NoError(t, err)
+
+	towerCfg := *cfg
+	towerCfg.MinFloor, towerCfg.MaxFloor = 0, 40
+	towerCfg.DefaultElevatorCount = 1
+	towerCfg.NamePrefix = "Tower"
+	towerCfg.BuildingMaxConcurrentRequests = 1
+	tower, err := registry.AddBuilding(context.Background(), "tower", &towerCfg)
+	require.NoError(t, err)
+
+	server := NewServer(cfg, 8080, mainManager)
+	server.SetBuildings(registry)
+
+	serve := func(method, target, body string) (*httptest.ResponseRecorder, APIResponse) {
+		req := httptest.NewRequest(method, target, bytes.NewBufferString(body))
+		rr := httptest.NewRecorder()
+		server.httpServer.Handler.ServeHTTP(rr, req)
+		var response APIResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response), rr.Body.String())
+		return rr, response
+	}
+
+	rr, response := serve(http.MethodGet, "/v1/buildings", "")
+	require.Equal(t, http.StatusOK, rr.Code)
+	buildings := response.Data.(map[string]any)["buildings"].([]any)
+	require.Len(t, buildings, 2)
+	assert.Equal(t, "main", buildings[0].(map[string]any)["id"])
+	assert.Equal(t, true, buildings[0].(map[string]any)["default"])
+	assert.Equal(t, float64(40), buildings[1].(map[string]any)["max_floor"])
+
+	// Requests are served by the building's own fleet
+	rr, response = serve(http.MethodPost, "/v1/buildings/tower/floors/request", `{"from":30,"to":35}`)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Equal(t, "Tower-1", response.Data.(map[string]any)["elevator_name"])
+
+	rr, _ = serve(http.MethodPost, "/v1/buildings/main/elevators", `{"name":"A","min_floor":0,"max_floor":9}`)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	assert.NotNil(t, mainManager.GetElevator("A"))
+	assert.Nil(t, tower.Manager.GetElevator("A"))
+
+	rr, _ = serve(http.MethodPost, "/v1/buildings/annex/floors/request", `{"from":1,"to":2}`)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	// A saturated building is turned away while the others keep serving
+	release, ok := tower.Acquire()
+	require.True(t, ok)
+	rr, response = serve(http.MethodPost, "/v1/buildings/tower/floors/request", `{"from":1,"to":2}`)
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.Equal(t, ErrorCodeBuildingBusy, response.Error.Code)
+	assert.Equal(t, "1", rr.Header().Get("Retry-After"))
+
+	rr, _ = serve(http.MethodPost, "/v1/buildings/main/floors/request", `{"from":1,"to":2}`)
+	assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	release()
+
+	rr, _ = serve(http.MethodGet, "/v1/buildings/tower/health", "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	// Handlers follow the configuration a building runs with after a reload
+	reloaded := towerCfg
+	reloaded.BuildingID = "tower"
+	reloaded.DefaultOverloadThreshold = 20
+	tower.Manager.ApplyConfig(context.Background(), &reloaded)
+	rr, response = serve(http.MethodPost, "/v1/buildings/tower/elevators", `{"name":"B","min_floor":0,"max_floor":40}`)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	tuning := response.Data.(map[string]any)["tuning"].(map[string]any)
+	assert.Equal(t, float64(20), tuning["overload_threshold"])
+}
+
+func TestServer_WebhookRoutes(t *testing.T) {
+	cfg := buildServerTestConfig()
+	cfg.RateLimitRPM = 1000
+	cfg.DefaultOverloadThreshold = 12
+	cfg.AdminAPIToken = "admin-secret"
+	m := manager.New(cfg, &factory.StandardElevatorFactory{})
+	defer m.Shutdown()
+	server := NewServer(cfg, 8080, m)
+
+	adminToken := cfg.AdminAPIToken
+	serve := func(method, target, body string) (*httptest.ResponseRecorder, APIResponse) {
+		req := httptest.NewRequest(method, target, bytes.NewBufferString(body))
+		req.Header.Set(AdminTokenHeader, adminToken)
+		rr := httptest.NewRecorder()
+		server.httpServer.Handler.ServeHTTP(rr, req)
+		var response APIResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response), rr.Body.String())
+		return rr, response
+	}
+
+	rr, _ := serve(http.MethodGet, "/v1/webhooks", "")
+	assert.Equal(t, http.StatusNotFound, rr.Code, "webhooks are not enabled")
+
+	dispatcher := webhook.NewDispatcher(webhook.Options{
+		MaxAttempts:     2,
+		RetryBackoff:    10 * time.Millisecond,
+		MaxRetryBackoff: 10 * time.Millisecond,
+		Timeout:         time.Second,
+		DeliveryLogSize: 10,
+		AllowedNetworks: []netip.Prefix{netip.MustParsePrefix("127.0.0.0/8")},
+	})
+	defer dispatcher.Stop()
+	dispatcher.Watch("main", m.Events())
+	server.SetWebhooks(dispatcher)
+
+	received := make(chan *http.Request, 10)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- r
+	}))
+	defer receiver.Close()
+
+	body := fmt.Sprintf(`{"url":%q,"events":["elevator_added"],"secret":"0123456789abcdef"}`, receiver.URL)
+
+	// Managing webhooks takes the admin token
+	for _, token := range []string{"", "guess"} {
+		adminToken = token
+		rr, _ = serve(http.MethodPost, "/v1/webhooks", body)
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		rr, _ = serve(http.MethodGet, "/v1/webhooks", "")
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	}
+	adminToken = cfg.AdminAPIToken
+	assert.Empty(t, dispatcher.Subscriptions())
+
+	// Receivers on internal addresses outside the allowed networks are refused
+	rr, _ = serve(http.MethodPost, "/v1/webhooks", `{"url":"http://169.254.169.254/latest","secret":"0123456789abcdef"}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr, response := serve(http.MethodPost, "/v1/webhooks", body)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	created := response.Data.(map[string]any)
+	id := created["id"].(string)
+	assert.Equal(t, []any{"elevator_added"}, created["events"])
+	assert.NotContains(t, created, "secret")
+
+	rr, _ = serve(http.MethodPost, "/v1/webhooks", `{"url":"ftp://ops.example.com","secret":"0123456789abcdef"}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	// Creating an elevator notifies the webhook
+	rr, _ = serve(http.MethodPost, "/v1/elevators", `{"name":"A","min_floor":0,"max_floor":9}`)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	select {
+	case r := <-received:
+		assert.Equal(t, "elevator_added", r.Header.Get(webhook.HeaderEvent))
+	case <-time.After(2 * time.Second):
+		t.Fatal("webhook not delivered")
+	}
+
+	require.Eventually(t, func() bool {
+		rr, response = serve(http.MethodGet, "/v1/webhooks/"+id+"/deliveries?status=delivered", "")
+		return rr.Code == http.StatusOK && len(response.Data.(map[string]any)["deliveries"].([]any)) == 1
+	}, time.Second, 5*time.Millisecond)
+
+	rr, _ = serve(http.MethodGet, "/v1/webhooks/"+id+"/deliveries?status=lost", "")
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr, response = serve(http.MethodGet, "/v1/webhooks", "")
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Len(t, response.Data.(map[string]any)["webhooks"], 1)
+
+	rr, _ = serve(http.MethodDelete, "/v1/webhooks/"+id, "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	rr, _ = serve(http.MethodGet, "/v1/webhooks/"+id, "")
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	rr, _ = serve(http.MethodPost, "/v1/webhooks/"+id+"/deliveries/dlv_missing/redeliver", "")
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestServer_AlertRoutes(t *testing.T) {
+	cfg := buildServerTestConfig()
+	cfg.RateLimitRPM = 1000
+	m := manager.New(cfg, &factory.StandardElevatorFactory{})
+	defer m.Shutdown()
+	server := NewServer(cfg, 8080, m)
+
+	serve := func(method, target string) (*httptest.ResponseRecorder, APIResponse) {
+		req := httptest.NewRequest(method, target, nil)
+		rr := httptest.NewRecorder()
+		server.httpServer.Handler.ServeHTTP(rr, req)
+		var response APIResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response), rr.Body.String())
+		return rr, response
+	}
+	alerts := func(response APIResponse) []any {
+		return response.Data.(map[string]any)["alerts"].([]any)
+	}
+
+	rr, response := serve(http.MethodGet, "/v1/alerts")
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Empty(t, alerts(response), "no rules are configured")
+
+	rules, err := config.ParseSLARules("rejections:rejected_requests:<=5%:5m:critical")
+	require.NoError(t, err)
+	engine := sla.NewEngine(rules, 5*time.Millisecond)
+	engine.Monitor("main", m)
+	server.SetAlerts(engine)
+	engine.Start()
+	defer engine.Stop()
+
+	// Without elevators every request is rejected
+	_, err = m.RequestElevator(context.Background(), 1, 3)
+	require.Error(t, err)
+
+	require.Eventually(t, func() bool {
+		_, response = serve(http.MethodGet, "/v1/alerts?state=firing")
+		return len(alerts(response)) == 1
+	}, time.Second, 5*time.Millisecond)
+	alert := alerts(response)[0].(map[string]any)
+	assert.Equal(t, "rejections", alert["rule"])
+	assert.Equal(t, "main", alert["building"])
+	assert.Equal(t, 1.0, alert["value"])
+
+	rr, response = serve(http.MethodGet, "/v1/alerts?state=ok")
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Empty(t, alerts(response))
+	rr, _ = serve(http.MethodGet, "/v1/alerts?state=paging")
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	rr, _ = serve(http.MethodPost, "/v1/alerts")
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+
+	// A breached critical rule fails readiness
+	req := httptest.NewRequest(http.MethodGet, "/v1/health/ready", nil)
+	ready := httptest.NewRecorder()
+	server.httpServer.Handler.ServeHTTP(ready, req)
+	assert.Equal(t, http.StatusServiceUnavailable, ready.Code)
+	assert.Contains(t, ready.Body.String(), "sla")
+}
+
+func TestServer_AnalyticsRoutes(t *testing.T) {
+	cfg := buildServerTestConfig()
+	cfg.RateLimitRPM = 1000
+	m := manager.New(cfg, &factory.StandardElevatorFactory{})
+	defer m.Shutdown()
+	server := NewServer(cfg, 8080, m)
+
+	serve := func(method, target string) (*httptest.ResponseRecorder, APIResponse) {
+		req := httptest.NewRequest(method, target, nil)
+		rr := httptest.NewRecorder()
+		server.httpServer.Handler.ServeHTTP(rr, req)
+		var response APIResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response), rr.Body.String())
+		return rr, response
+	}
+
+	rr, _ := serve(http.MethodGet, "/v1/analytics")
+	assert.Equal(t, http.StatusNotFound, rr.Code, "analytics are not enabled")
+
+	store, err := analytics.OpenStore(t.TempDir(), 24*time.Hour)
+	require.NoError(t, err)
+	defer store.Close()
+	server.SetAnalytics(store)
+
+	now := time.Now().UTC()
+	pickedUpAt := now.Add(-50 * time.Second)
+	require.NoError(t, store.AppendRequest(analytics.RequestRecord{
+		Building: "main", Elevator: "A", Outcome: analytics.OutcomeCompleted,
+		FromFloor: 2, ToFloor: 6, Direction: "up",
+		RequestedAt: now.Add(-time.Minute), PickedUpAt: &pickedUpAt, FinishedAt: now.Add(-30 * time.Second),
+	}))
+	require.NoError(t, store.AppendRequest(analytics.RequestRecord{
+		Building: "main", Outcome: analytics.OutcomeRejected, FromFloor: 2, ToFloor: 0, Direction: "down",
+		RequestedAt: now.Add(-time.Minute), FinishedAt: now.Add(-time.Minute),
+	}))
+
+	rr, response := serve(http.MethodGet, "/v1/analytics?building=main")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	report := response.Data.(map[string]any)
+	requests := report["requests"].(map[string]any)
+	assert.Equal(t, 2.0, requests["total"])
+	assert.Equal(t, 1.0, requests["rejected"])
+	assert.Equal(t, 10.0, report["wait_time"].(map[string]any)["p95"])
+	assert.Len(t, report["peaks"], 1)
+
+	from := url.QueryEscape(now.Add(-time.Hour).Format(time.RFC3339))
+	to := url.QueryEscape(now.Add(-30 * time.Minute).Format(time.RFC3339))
+	rr, response = serve(http.MethodGet, "/v1/analytics?from="+from+"&to="+to)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, 0.0, response.Data.(map[string]any)["requests"].(map[string]any)["total"])
+
+	for _, target := range []string{
+		"/v1/analytics?from=yesterday",
+		"/v1/analytics?to=" + from + "&from=" + to,
+		"/v1/analytics?peaks=many",
+		"/v1/analytics?peaks=1000",
+	} {
+		rr, _ = serve(http.MethodGet, target)
+		assert.Equal(t, http.StatusBadRequest, rr.Code, target)
+	}
+	rr, _ = serve(http.MethodPost, "/v1/analytics")
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+}
+
+func TestServer_SetTelemetry(t *testing.T) {
+	cfg := buildServerTestConfig()
+	cfg.RateLimitRPM = 1000
+	m := manager.New(cfg, &factory.StandardElevatorFactory{})
+	defer m.Shutdown()
+	require.NoError(t, m.AddElevator(context.Background(), cfg, "A", 0, 9,
+		cfg.EachFloorDuration, cfg.OpenDoorDuration, cfg.DefaultOverloadThreshold))
+	server := NewServer(cfg, 8080, m)
+
+	exporter := tracetest.NewInMemoryExporter()
+	previous := otel.GetTracerProvider()
+	telemetry, err := observability.NewTelemetryProviderWithExporter(&observability.ObservabilityConfig{
+		Enabled:     true,
+		ServiceName: "elevator-test",
+		Tracing:     observability.TracingConfig{Enabled: true, SamplingRatio: 1.0, Timeout: time.Second},
+	}, slog.Default(), exporter)
+	require.NoError(t, err)
+	defer func() {
+		otel.SetTracerProvider(previous)
+		_ = telemetry.Shutdown(context.Background())
+	}()
+	server.SetTelemetry(telemetry)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/floors/request", bytes.NewBufferString(`{"from":1,"to":5}`))
+	rr := httptest.NewRecorder()
+	server.httpServer.Handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	require.NoError(t, telemetry.ForceFlush(context.Background()))
+	spans := map[string]tracetest.SpanStub{}
+	for _, span := range exporter.GetSpans() {
+		spans[span.Name] = span
+	}
+	request, ok := spans["http_request"]
+	require.True(t, ok)
+	assert.False(t, request.Parent.IsValid())
+
+	// The manager's span of the floor request is a child of the HTTP span
+	assign, ok := spans["manager.request_elevator"]
+	require.True(t, ok)
+	assert.Equal(t, request.SpanContext.TraceID(), assign.SpanContext.TraceID())
+	assert.Equal(t, request.SpanContext.SpanID(), assign.Parent.SpanID())
+}
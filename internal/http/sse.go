@@ -0,0 +1,183 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/slavakukuyev/elevator-go/internal/constants"
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+	"github.com/slavakukuyev/elevator-go/internal/events"
+	"github.com/slavakukuyev/elevator-go/internal/infra/logging"
+	"github.com/slavakukuyev/elevator-go/metrics"
+)
+
+const (
+	// sseEventStatus names the periodic status snapshot event
+	sseEventStatus = "status"
+	// sseEventResync tells a resuming client that events were lost
+	sseEventResync = "resync"
+	// sseSubscriberBuffer bounds the events queued for one SSE client
+	sseSubscriberBuffer = 256
+	// sseRetryInterval is the reconnect delay suggested to clients
+	sseRetryInterval = 2 * time.Second
+)
+
+// eventsHandler streams elevator status and system events using Server-Sent
+// Events, for clients behind proxies that do not support WebSockets.
+//
+// Status snapshots carry the same payload as statusWebSocketHandler and are
+// sent without an id whenever the status broadcaster reports a change. Typed events
+// (request_assigned, floor_arrived, elevator_added, elevator_removed,
+// announcement, ...) carry
+// the event bus ID, so a reconnecting client that sends Last-Event-ID (or the
+// last_event_id query parameter) receives everything it missed that is still
+// in the bounded history. If the history no longer covers the gap a resync
+// event is sent first.
+func (s *Server) eventsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := logging.NewContextWithCorrelation(r.Context())
+	requestID := logging.GetRequestID(r.Context())
+	rw := NewResponseWriter(w, s.logger, requestID)
+
+	if r.Method != http.MethodGet {
+		rw.WriteError(http.StatusMethodNotAllowed, ErrorCodeMethodNotAllowed,
+			"Method not allowed", "Only GET method is supported")
+		return
+	}
+
+	lastID, err := parseLastEventID(r)
+	if err != nil {
+		rw.WriteDomainError(err)
+		return
+	}
+
+	// Streams outlive the server write timeout, so lift the deadline
+	controller := http.NewResponseController(w)
+	if err := controller.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		s.logger.WarnContext(ctx, "failed to clear write deadline for event stream",
+			slog.String("error", err.Error()))
+	}
+
+	sub, backlog, complete := s.manager.Events().SubscribeSince(lastID, sseSubscriberBuffer)
+	defer sub.Close()
+
+	// The current status is queued on subscription and sent after the backlog
+	statusSub := s.manager.Broadcaster().Subscribe(statusSubscriberBuffer)
+	defer statusSub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	s.logger.InfoContext(ctx, "event stream established",
+		slog.Uint64("last_event_id", lastID),
+		slog.Int("backlog", len(backlog)))
+
+	if _, err := fmt.Fprintf(w, "retry: %d\n\n", sseRetryInterval.Milliseconds()); err != nil {
+		return
+	}
+
+	if lastID > 0 && !complete {
+		if err := writeSSE(w, "", sseEventResync, map[string]any{"last_event_id": lastID}); err != nil {
+			return
+		}
+	}
+	for _, event := range backlog {
+		if err := writeSSEEvent(w, event); err != nil {
+			return
+		}
+	}
+	if err := controller.Flush(); err != nil {
+		return
+	}
+
+	for {
+		var err error
+		select {
+		case <-ctx.Done():
+			s.logger.InfoContext(ctx, "event stream closed by client")
+			return
+
+		case <-s.streams.Done():
+			s.logger.InfoContext(ctx, "event stream closed for server shutdown")
+			return
+
+		case event, ok := <-sub.C:
+			if !ok {
+				// Dropped for falling behind; the client resumes via Last-Event-ID
+				s.logger.WarnContext(ctx, "event stream subscriber fell behind, closing stream")
+				metrics.IncError("sse_slow_consumer", constants.ComponentHTTPServer)
+				return
+			}
+			err = writeSSEEvent(w, event)
+
+		case update, ok := <-statusSub.C:
+			if !ok {
+				s.logger.InfoContext(ctx, "status broadcaster stopped, closing event stream")
+				return
+			}
+			err = writeSSERaw(w, "", sseEventStatus, update.JSON)
+		}
+
+		if err == nil {
+			err = controller.Flush()
+		}
+		if err != nil {
+			s.logger.InfoContext(ctx, "event stream write failed, closing",
+				slog.String("error", err.Error()))
+			return
+		}
+	}
+}
+
+// writeSSEEvent writes a bus event, using its ID as the SSE event id
+func writeSSEEvent(w io.Writer, event events.Event) error {
+	return writeSSE(w, strconv.FormatUint(event.ID, 10), string(event.Type), event)
+}
+
+// writeSSE writes a single SSE message with a JSON data line
+func writeSSE(w io.Writer, id, eventType string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return writeSSERaw(w, id, eventType, payload)
+}
+
+// writeSSERaw writes a single SSE message with an already encoded JSON payload
+func writeSSERaw(w io.Writer, id, eventType string, payload []byte) error {
+	if id != "" {
+		if _, err := fmt.Fprintf(w, "id: %s\n", id); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", eventType, payload)
+	return err
+}
+
+// parseLastEventID reads the resume position from the Last-Event-ID header,
+// falling back to the last_event_id query parameter for clients that cannot
+// set headers on the initial connection
+func parseLastEventID(r *http.Request) (uint64, error) {
+	raw := r.Header.Get("Last-Event-ID")
+	if raw == "" {
+		raw = r.URL.Query().Get("last_event_id")
+	}
+	if raw == "" {
+		return 0, nil
+	}
+
+	id, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		return 0, domain.NewValidationError("last event id must be a non-negative integer", err).
+			WithContext("last_event_id", raw)
+	}
+	return id, nil
+}
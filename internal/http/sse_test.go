@@ -0,0 +1,242 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slavakukuyev/elevator-go/internal/events"
+)
+
+// sseMessage is a single parsed Server-Sent Events message
+type sseMessage struct {
+	id    string
+	event string
+	data  string
+}
+
+// readSSE parses SSE messages from a stream onto a channel until it ends
+func readSSE(t *testing.T, resp *http.Response) <-chan sseMessage {
+	t.Helper()
+
+	out := make(chan sseMessage, 64)
+	go func() {
+		defer close(out)
+		scanner := bufio.NewScanner(resp.Body)
+		var msg sseMessage
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case line == "":
+				if msg.event != "" {
+					out <- msg
+				}
+				msg = sseMessage{}
+			case strings.HasPrefix(line, "id: "):
+				msg.id = strings.TrimPrefix(line, "id: ")
+			case strings.HasPrefix(line, "event: "):
+				msg.event = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				msg.data = strings.TrimPrefix(line, "data: ")
+			}
+		}
+	}()
+	return out
+}
+
+// nextSSE returns the next message of the given type, skipping others
+func nextSSE(t *testing.T, messages <-chan sseMessage, eventType string) sseMessage {
+	t.Helper()
+
+	timeout := time.After(3 * time.Second)
+	for {
+		select {
+		case msg, ok := <-messages:
+			require.True(t, ok, "stream ended before %q event", eventType)
+			if msg.event == eventType {
+				return msg
+			}
+		case <-timeout:
+			t.Fatalf("timed out waiting for %q event", eventType)
+		}
+	}
+}
+
+// newEventStreamServer serves the events handler; streams are ended before
+// the test server closes since Close waits for active requests
+func newEventStreamServer(t *testing.T, server *Server) *httptest.Server {
+	t.Helper()
+
+	ts := httptest.NewServer(http.HandlerFunc(server.eventsHandler))
+	t.Cleanup(func() {
+		server.cancelStreams()
+		ts.Close()
+	})
+	return ts
+}
+
+func openEventStream(t *testing.T, ts *httptest.Server, lastEventID string) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, ts.URL+"/v1/events", nil)
+	require.NoError(t, err)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+
+	resp, err := ts.Client().Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = resp.Body.Close() })
+	return resp
+}
+
+func TestEventsHandler_StreamsStatusAndTypedEvents(t *testing.T) {
+	server, manager := setupTestServer()
+	defer manager.Shutdown()
+
+	ts := newEventStreamServer(t, server)
+
+	resp := openEventStream(t, ts, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "no-cache", resp.Header.Get("Cache-Control"))
+
+	messages := readSSE(t, resp)
+
+	status := nextSSE(t, messages, "status")
+	assert.Empty(t, status.id, "status snapshots are not resumable")
+	assert.JSONEq(t, "{}", status.data)
+
+	cfg := buildServerTestConfig()
+	require.NoError(t, manager.AddElevator(context.Background(), cfg, "SSE-1", 0, 10,
+		cfg.EachFloorDuration, cfg.OpenDoorDuration, 12))
+
+	added := nextSSE(t, messages, string(events.TypeElevatorAdded))
+	assert.NotEmpty(t, added.id)
+	var addedEvent events.Event
+	require.NoError(t, json.Unmarshal([]byte(added.data), &addedEvent))
+	assert.Equal(t, "SSE-1", addedEvent.Elevator)
+	assert.EqualValues(t, 10, addedEvent.Data["max_floor"])
+
+	_, err := manager.RequestElevator(context.Background(), 0, 2)
+	require.NoError(t, err)
+
+	assigned := nextSSE(t, messages, string(events.TypeRequestAssigned))
+	var assignedEvent events.Event
+	require.NoError(t, json.Unmarshal([]byte(assigned.data), &assignedEvent))
+	assert.Equal(t, "SSE-1", assignedEvent.Elevator)
+	assert.EqualValues(t, 2, assignedEvent.Data["to_floor"])
+
+	arrived := nextSSE(t, messages, string(events.TypeFloorArrived))
+	var arrivedEvent events.Event
+	require.NoError(t, json.Unmarshal([]byte(arrived.data), &arrivedEvent))
+	assert.Equal(t, "SSE-1", arrivedEvent.Elevator)
+
+	// Subsequent status snapshots include the new elevator
+	status = nextSSE(t, messages, "status")
+	assert.Contains(t, status.data, `"SSE-1"`)
+}
+
+func TestEventsHandler_ResumesFromLastEventID(t *testing.T) {
+	server, manager := setupTestServer()
+	defer manager.Shutdown()
+
+	cfg := buildServerTestConfig()
+	for _, name := range []string{"R-1", "R-2", "R-3"} {
+		require.NoError(t, manager.AddElevator(context.Background(), cfg, name, 0, 10,
+			cfg.EachFloorDuration, cfg.OpenDoorDuration, 12))
+	}
+
+	ts := newEventStreamServer(t, server)
+
+	resp := openEventStream(t, ts, "1")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	messages := readSSE(t, resp)
+
+	// Events 2 and 3 are replayed before the first status snapshot
+	first := <-messages
+	second := <-messages
+	third := <-messages
+	assert.Equal(t, "2", first.id)
+	assert.Contains(t, first.data, `"R-2"`)
+	assert.Equal(t, "3", second.id)
+	assert.Contains(t, second.data, `"R-3"`)
+	assert.Equal(t, "status", third.event)
+}
+
+func TestEventsHandler_ResyncWhenHistoryExhausted(t *testing.T) {
+	server, manager := setupTestServer()
+	defer manager.Shutdown()
+
+	ts := newEventStreamServer(t, server)
+
+	// The client resumes with an ID this process never issued
+	resp := openEventStream(t, ts, "500")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	messages := readSSE(t, resp)
+
+	first := <-messages
+	assert.Equal(t, "resync", first.event)
+	assert.JSONEq(t, `{"last_event_id": 500}`, first.data)
+}
+
+func TestEventsHandler_RejectsInvalidRequests(t *testing.T) {
+	server, manager := setupTestServer()
+	defer manager.Shutdown()
+
+	tests := []struct {
+		name           string
+		method         string
+		lastEventID    string
+		expectedStatus int
+	}{
+		{name: "wrong method", method: http.MethodPost, expectedStatus: http.StatusMethodNotAllowed},
+		{name: "malformed last event id", method: http.MethodGet, lastEventID: "abc", expectedStatus: http.StatusBadRequest},
+		{name: "negative last event id", method: http.MethodGet, lastEventID: "-1", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/v1/events", nil)
+			if tt.lastEventID != "" {
+				req.Header.Set("Last-Event-ID", tt.lastEventID)
+			}
+			w := httptest.NewRecorder()
+
+			server.eventsHandler(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+		})
+	}
+}
+
+func TestEventsHandler_ShutdownEndsStream(t *testing.T) {
+	server, manager := setupTestServer()
+	defer manager.Shutdown()
+
+	ts := newEventStreamServer(t, server)
+
+	resp := openEventStream(t, ts, "")
+	messages := readSSE(t, resp)
+	nextSSE(t, messages, "status")
+
+	server.cancelStreams()
+
+	select {
+	case _, ok := <-messages:
+		for ok {
+			_, ok = <-messages
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("event stream was not closed on shutdown")
+	}
+}
@@ -0,0 +1,13 @@
+package http
+
+import (
+	"github.com/slavakukuyev/elevator-go/internal/infra/observability"
+)
+
+// SetTelemetry traces every request the server handles. The request span
+// wraps the whole middleware chain, continues a trace the caller propagated
+// and is the parent of the manager's spans for floor requests. It must be
+// called before the server starts.
+func (s *Server) SetTelemetry(provider *observability.TelemetryProvider) {
+	s.httpServer.Handler = provider.TelemetryMiddleware()(s.httpServer.Handler)
+}
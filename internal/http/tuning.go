@@ -0,0 +1,202 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/slavakukuyev/elevator-go/internal/constants"
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+	"github.com/slavakukuyev/elevator-go/internal/elevator"
+	"github.com/slavakukuyev/elevator-go/internal/infra/logging"
+)
+
+// ElevatorTuningRequest represents the request for tuning an elevator
+// (PATCH /v1/elevators/{name}); omitted fields keep their value. Durations
+// use Go syntax such as "750ms" or "2s".
+type ElevatorTuningRequest struct {
+	EachFloorDuration *string                      `json:"each_floor_duration,omitempty"`
+	OpenDoorDuration  *string                      `json:"open_door_duration,omitempty"`
+	OverloadThreshold *int                         `json:"overload_threshold,omitempty"`
+	CircuitBreaker    *CircuitBreakerTuningRequest `json:"circuit_breaker,omitempty"`
+}
+
+// CircuitBreakerTuningRequest represents circuit breaker settings in a
+// tuning request; omitted fields keep their value
+type CircuitBreakerTuningRequest struct {
+	MaxFailures      *int     `json:"max_failures,omitempty"`
+	ResetTimeout     *string  `json:"reset_timeout,omitempty"`
+	HalfOpenLimit    *int     `json:"half_open_limit,omitempty"`
+	FailureThreshold *float64 `json:"failure_threshold,omitempty"`
+	Window           *string  `json:"window,omitempty"`
+	MinRequests      *int     `json:"min_requests,omitempty"`
+}
+
+// ElevatorTuning represents the current settings of an elevator
+type ElevatorTuning struct {
+	EachFloorDuration string               `json:"each_floor_duration"`
+	OpenDoorDuration  string               `json:"open_door_duration"`
+	OverloadThreshold int                  `json:"overload_threshold"`
+	CircuitBreaker    CircuitBreakerTuning `json:"circuit_breaker"`
+}
+
+// CircuitBreakerTuning represents the circuit breaker settings of an elevator
+type CircuitBreakerTuning struct {
+	MaxFailures      int     `json:"max_failures"`
+	ResetTimeout     string  `json:"reset_timeout"`
+	HalfOpenLimit    int     `json:"half_open_limit"`
+	FailureThreshold float64 `json:"failure_threshold"`
+	Window           string  `json:"window"`
+	MinRequests      int     `json:"min_requests"`
+}
+
+// ElevatorTuningResponse represents the response for tuning an elevator
+type ElevatorTuningResponse struct {
+	Name    string         `json:"name"`
+	Tuning  ElevatorTuning `json:"tuning"`
+	Message string         `json:"message"`
+}
+
+// patch converts the request into a tuning patch, rejecting malformed
+// durations
+func (req ElevatorTuningRequest) patch() (elevator.TuningPatch, error) {
+	var patch elevator.TuningPatch
+	var err error
+	if patch.EachFloorDuration, err = parseTuningDuration("each_floor_duration", req.EachFloorDuration); err != nil {
+		return patch, err
+	}
+	if patch.OpenDoorDuration, err = parseTuningDuration("open_door_duration", req.OpenDoorDuration); err != nil {
+		return patch, err
+	}
+	patch.OverloadThreshold = req.OverloadThreshold
+
+	if cb := req.CircuitBreaker; cb != nil {
+		patch.CircuitBreaker.MaxFailures = cb.MaxFailures
+		patch.CircuitBreaker.HalfOpenLimit = cb.HalfOpenLimit
+		patch.CircuitBreaker.FailureThreshold = cb.FailureThreshold
+		patch.CircuitBreaker.MinRequests = cb.MinRequests
+		if patch.CircuitBreaker.ResetTimeout, err = parseTuningDuration("circuit_breaker.reset_timeout", cb.ResetTimeout); err != nil {
+			return patch, err
+		}
+		if patch.CircuitBreaker.Window, err = parseTuningDuration("circuit_breaker.window", cb.Window); err != nil {
+			return patch, err
+		}
+	}
+	return patch, nil
+}
+
+func parseTuningDuration(field string, value *string) (*time.Duration, error) {
+	if value == nil {
+		return nil, nil
+	}
+	duration, err := time.ParseDuration(*value)
+	if err != nil {
+		return nil, domain.NewValidationError("invalid duration", err).
+			WithContext("field", field).
+			WithContext("value", *value)
+	}
+	return &duration, nil
+}
+
+// newElevatorTuning converts elevator settings for a response
+func newElevatorTuning(t elevator.Tuning) ElevatorTuning {
+	return ElevatorTuning{
+		EachFloorDuration: t.EachFloorDuration.String(),
+		OpenDoorDuration:  t.OpenDoorDuration.String(),
+		OverloadThreshold: t.OverloadThreshold,
+		CircuitBreaker: CircuitBreakerTuning{
+			MaxFailures:      t.CircuitBreaker.MaxFailures,
+			ResetTimeout:     t.CircuitBreaker.ResetTimeout.String(),
+			HalfOpenLimit:    t.CircuitBreaker.HalfOpenLimit,
+			FailureThreshold: t.CircuitBreaker.FailureThreshold,
+			Window:           t.CircuitBreaker.Window.String(),
+			MinRequests:      t.CircuitBreaker.MinRequests,
+		},
+	}
+}
+
+// ElevatorTuningHandler inspects (GET) and changes (PATCH) the speed, door
+// time, overload threshold and circuit breaker settings of a running
+// elevator (/v1/elevators/{name})
+func (h *V1Handlers) ElevatorTuningHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := logging.GetRequestID(r.Context())
+	rw := NewResponseWriter(w, h.logger, requestID)
+	name := r.PathValue("name")
+
+	switch r.Method {
+	case http.MethodGet:
+		tuning, err := h.elevatorTuning(name)
+		if err != nil {
+			rw.WriteDomainError(err)
+			return
+		}
+		rw.WriteJSON(http.StatusOK, ElevatorTuningResponse{
+			Name:    name,
+			Tuning:  newElevatorTuning(tuning),
+			Message: "Elevator tuning retrieved",
+		})
+	case http.MethodPatch:
+		var requestBody ElevatorTuningRequest
+		if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
+			h.logger.ErrorContext(r.Context(), "failed to decode elevator tuning request",
+				slog.String("error", err.Error()),
+				slog.String("request_id", requestID))
+			rw.WriteError(http.StatusBadRequest, ErrorCodeInvalidJSON,
+				"Invalid JSON", "Request body contains invalid JSON")
+			return
+		}
+
+		patch, err := requestBody.patch()
+		if err != nil {
+			rw.WriteDomainError(err)
+			return
+		}
+
+		tuning, err := h.tuneElevator(r.Context(), name, patch)
+		if err != nil {
+			h.logger.ErrorContext(r.Context(), "failed to tune elevator",
+				slog.String("elevator_name", name),
+				slog.String("error", err.Error()),
+				slog.String("request_id", requestID))
+			rw.WriteDomainError(err)
+			return
+		}
+
+		h.logger.InfoContext(r.Context(), "elevator tuned successfully",
+			slog.String("elevator_name", name),
+			slog.String("request_id", requestID),
+			slog.String("component", constants.ComponentHTTPHandler))
+
+		rw.WriteJSON(http.StatusOK, ElevatorTuningResponse{
+			Name:    name,
+			Tuning:  newElevatorTuning(tuning),
+			Message: "Elevator tuned successfully",
+		})
+	default:
+		h.logger.WarnContext(r.Context(), "invalid request method for elevator tuning endpoint",
+			slog.String("method", r.Method),
+			slog.String("request_id", requestID))
+		rw.WriteError(http.StatusMethodNotAllowed, ErrorCodeMethodNotAllowed,
+			"Method not allowed", "Only GET and PATCH methods are supported")
+	}
+}
+
+// elevatorTuning returns the settings of an elevator of the cluster's fleet
+// when the handlers serve a cluster, or of the manager's otherwise
+func (h *V1Handlers) elevatorTuning(name string) (elevator.Tuning, error) {
+	if h.cluster != nil {
+		return h.cluster.ElevatorTuning(name)
+	}
+	return h.manager.ElevatorTuning(name)
+}
+
+// tuneElevator tunes an elevator through the cluster leader when the
+// handlers serve a cluster, or on the manager otherwise
+func (h *V1Handlers) tuneElevator(ctx context.Context, name string, patch elevator.TuningPatch) (elevator.Tuning, error) {
+	if h.cluster != nil {
+		return h.cluster.TuneElevator(ctx, name, patch)
+	}
+	return h.manager.TuneElevator(ctx, name, patch)
+}
@@ -0,0 +1,179 @@
+package http
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+	"github.com/slavakukuyev/elevator-go/internal/infra/logging"
+	"github.com/slavakukuyev/elevator-go/internal/webhook"
+)
+
+// WebhookRequestBody registers a webhook
+type WebhookRequestBody struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events,omitempty"` // Optional: every event type when empty or ["*"]
+	Secret string   `json:"secret"`           // Signs every delivery; never returned
+}
+
+// WebhooksResponse lists the registered webhooks
+type WebhooksResponse struct {
+	Webhooks []webhook.Subscription `json:"webhooks"`
+}
+
+// WebhookDeletedResponse confirms that a webhook was removed
+type WebhookDeletedResponse struct {
+	ID      string `json:"id"`
+	Message string `json:"message"`
+}
+
+// WebhookDeliveriesResponse lists the delivery log of a webhook, newest first
+type WebhookDeliveriesResponse struct {
+	Deliveries []webhook.Delivery `json:"deliveries"`
+}
+
+// SetWebhooks serves the subscriptions of a webhook dispatcher under
+// /v1/webhooks. It must be called before the server starts.
+func (s *Server) SetWebhooks(dispatcher *webhook.Dispatcher) {
+	s.webhooks = dispatcher
+}
+
+// webhookDispatcher returns the dispatcher, writing a not found error when
+// webhooks are not enabled and an unauthorized one when the request does not
+// carry the admin token
+func (s *Server) webhookDispatcher(r *http.Request, rw *ResponseWriter) (*webhook.Dispatcher, bool) {
+	if s.webhooks == nil {
+		rw.WriteDomainError(domain.NewNotFoundError("webhooks are not enabled", nil))
+		return nil, false
+	}
+	if !s.authenticateAdmin(r, rw) {
+		return nil, false
+	}
+	return s.webhooks, true
+}
+
+// webhooksHandler lists (GET) or registers (POST) webhooks (/v1/webhooks)
+func (s *Server) webhooksHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := logging.GetRequestID(r.Context())
+	rw := NewResponseWriter(w, s.logger, requestID)
+
+	dispatcher, ok := s.webhookDispatcher(r, rw)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		rw.WriteJSON(http.StatusOK, WebhooksResponse{Webhooks: dispatcher.Subscriptions()})
+	case http.MethodPost:
+		var requestBody WebhookRequestBody
+		if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
+			s.logger.ErrorContext(r.Context(), "failed to decode webhook request",
+				slog.String("error", err.Error()),
+				slog.String("request_id", requestID))
+			rw.WriteError(http.StatusBadRequest, ErrorCodeInvalidJSON,
+				"Invalid JSON", "Request body contains invalid JSON")
+			return
+		}
+
+		subscription, err := dispatcher.Subscribe(requestBody.URL, requestBody.Events, requestBody.Secret)
+		if err != nil {
+			rw.WriteDomainError(err)
+			return
+		}
+		rw.WriteJSON(http.StatusCreated, subscription)
+	default:
+		rw.WriteError(http.StatusMethodNotAllowed, ErrorCodeMethodNotAllowed,
+			"Method not allowed", "Only GET and POST methods are supported")
+	}
+}
+
+// webhookHandler describes (GET) or removes (DELETE) a webhook
+// (/v1/webhooks/{id})
+func (s *Server) webhookHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := logging.GetRequestID(r.Context())
+	rw := NewResponseWriter(w, s.logger, requestID)
+	id := r.PathValue("id")
+
+	dispatcher, ok := s.webhookDispatcher(r, rw)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		subscription, err := dispatcher.Subscription(id)
+		if err != nil {
+			rw.WriteDomainError(err)
+			return
+		}
+		rw.WriteJSON(http.StatusOK, subscription)
+	case http.MethodDelete:
+		if err := dispatcher.Unsubscribe(id); err != nil {
+			rw.WriteDomainError(err)
+			return
+		}
+		rw.WriteJSON(http.StatusOK, WebhookDeletedResponse{ID: id, Message: "Webhook deleted"})
+	default:
+		rw.WriteError(http.StatusMethodNotAllowed, ErrorCodeMethodNotAllowed,
+			"Method not allowed", "Only GET and DELETE methods are supported")
+	}
+}
+
+// webhookDeliveriesHandler returns the delivery log of a webhook, optionally
+// only the deliveries with a status (GET /v1/webhooks/{id}/deliveries?status=dead_letter)
+func (s *Server) webhookDeliveriesHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := logging.GetRequestID(r.Context())
+	rw := NewResponseWriter(w, s.logger, requestID)
+
+	if r.Method != http.MethodGet {
+		rw.WriteError(http.StatusMethodNotAllowed, ErrorCodeMethodNotAllowed,
+			"Method not allowed", "Only GET method is supported")
+		return
+	}
+
+	dispatcher, ok := s.webhookDispatcher(r, rw)
+	if !ok {
+		return
+	}
+
+	status := webhook.DeliveryStatus(r.URL.Query().Get("status"))
+	if status != "" && !status.IsValid() {
+		rw.WriteDomainError(domain.NewValidationError("status must be pending, retrying, delivered or dead_letter", nil).
+			WithContext("status", string(status)))
+		return
+	}
+
+	deliveries, err := dispatcher.Deliveries(r.PathValue("id"), status)
+	if err != nil {
+		rw.WriteDomainError(err)
+		return
+	}
+	rw.WriteJSON(http.StatusOK, WebhookDeliveriesResponse{Deliveries: deliveries})
+}
+
+// webhookRedeliverHandler sends a dead-lettered delivery again
+// (POST /v1/webhooks/{id}/deliveries/{delivery}/redeliver)
+func (s *Server) webhookRedeliverHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := logging.GetRequestID(r.Context())
+	rw := NewResponseWriter(w, s.logger, requestID)
+
+	if r.Method != http.MethodPost {
+		rw.WriteError(http.StatusMethodNotAllowed, ErrorCodeMethodNotAllowed,
+			"Method not allowed", "Only POST method is supported")
+		return
+	}
+
+	dispatcher, ok := s.webhookDispatcher(r, rw)
+	if !ok {
+		return
+	}
+
+	delivery, err := dispatcher.Redeliver(r.PathValue("id"), r.PathValue("delivery"))
+	if err != nil {
+		rw.WriteDomainError(err)
+		return
+	}
+	rw.WriteJSON(http.StatusAccepted, delivery)
+}
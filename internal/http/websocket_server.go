@@ -10,12 +10,14 @@ import (
 
 	"github.com/gorilla/websocket"
 
+	"github.com/slavakukuyev/elevator-go/internal/cluster"
 	"github.com/slavakukuyev/elevator-go/internal/manager"
 )
 
 // WebSocketServer is a separate server just for WebSocket connections
 type WebSocketServer struct {
 	manager     *manager.Manager
+	cluster     *cluster.Node
 	server      *http.Server
 	logger      *slog.Logger
 	ctx         context.Context
@@ -27,6 +29,8 @@ type WebSocketServer struct {
 // Simple upgrader without any special configuration
 var wsUpgrader = websocket.Upgrader{
 	CheckOrigin: func(r *http.Request) bool { return true },
+	// Clients opt in to the delta protocol through the subprotocol header
+	Subprotocols: []string{wsProtocolV2},
 	// Set buffer sizes for better performance
 	ReadBufferSize:  1024,
 	WriteBufferSize: 1024,
@@ -52,7 +56,7 @@ func NewWebSocketServer(port int, manager *manager.Manager, logger *slog.Logger)
 		// Add CORS headers
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Access-Control-Allow-Methods", "GET")
-		w.Header().Set("Access-Control-Allow-Headers", "Upgrade, Connection, Sec-WebSocket-Key, Sec-WebSocket-Version")
+		w.Header().Set("Access-Control-Allow-Headers", "Upgrade, Connection, Sec-WebSocket-Key, Sec-WebSocket-Version, Sec-WebSocket-Protocol")
 
 		ws.statusHandler(w, r)
 	})
@@ -66,6 +70,12 @@ func NewWebSocketServer(port int, manager *manager.Manager, logger *slog.Logger)
 }
 
 // addConnection adds a connection to the tracking map
+// SetCluster routes the trip commands of WebSocket sessions through the
+// cluster node, whose leader runs the fleet
+func (ws *WebSocketServer) SetCluster(node *cluster.Node) {
+	ws.cluster = node
+}
+
 func (ws *WebSocketServer) addConnection(conn *websocket.Conn, cancel context.CancelFunc) {
 	ws.connMutex.Lock()
 	defer ws.connMutex.Unlock()
@@ -126,12 +136,20 @@ func (ws *WebSocketServer) statusHandler(w http.ResponseWriter, r *http.Request)
 
 	// Set up connection timeouts
 	const (
-		writeWait      = 10 * time.Second
-		pongWait       = 60 * time.Second
-		pingPeriod     = (pongWait * 9) / 10
-		statusInterval = 100 * time.Millisecond // Update every 100ms for real-time movement
+		writeWait  = 10 * time.Second
+		pongWait   = 60 * time.Second
+		pingPeriod = (pongWait * 9) / 10
 	)
 
+	if wantsStatusProtocolV2(conn, r) {
+		newStatusSession(conn, ws.manager, ws.cluster, ws.logger, "", statusSessionTimeouts{
+			write: writeWait,
+			read:  pongWait,
+			ping:  pingPeriod,
+		}).run(ctx)
+		return
+	}
+
 	// Set read deadline and pong handler for keep-alive
 	if err := conn.SetReadDeadline(time.Now().Add(pongWait)); err != nil {
 		ws.logger.Error("failed to set read deadline", slog.String("error", err.Error()))
@@ -144,26 +162,12 @@ func (ws *WebSocketServer) statusHandler(w http.ResponseWriter, r *http.Request)
 		return nil
 	})
 
-	// Send initial status
-	status, err := ws.manager.GetStatus()
-	if err != nil {
-		ws.logger.Error("Failed to get initial status", slog.String("component", "websocket-server"), slog.String("error", err.Error()))
-		return
-	}
-
-	if err := conn.SetWriteDeadline(time.Now().Add(writeWait)); err != nil {
-		ws.logger.Error("failed to set write deadline for initial status", slog.String("error", err.Error()))
-		return
-	}
-	if err := conn.WriteJSON(status); err != nil {
-		ws.logger.Error("Failed to send initial status", slog.String("component", "websocket-server"), slog.String("error", err.Error()))
-		return
-	}
-
-	// Create tickers for status updates and ping messages
-	statusTicker := time.NewTicker(statusInterval)
-	defer statusTicker.Stop()
+	// Status changes are pushed by the broadcaster; the current status is
+	// queued on subscription and sent first
+	sub := ws.manager.Broadcaster().Subscribe(statusSubscriberBuffer)
+	defer sub.Close()
 
+	// Create ticker for ping messages
 	pingTicker := time.NewTicker(pingPeriod)
 	defer pingTicker.Stop()
 
@@ -209,33 +213,10 @@ func (ws *WebSocketServer) statusHandler(w http.ResponseWriter, r *http.Request)
 				return
 			}
 
-		case <-statusTicker.C:
-			// Get status with timeout
-			type wsStatusResult struct {
-				status map[string]interface{}
-				err    error
-			}
-			statusCh := make(chan wsStatusResult, 1)
-			go func() {
-				st, errS := ws.manager.GetStatus()
-				statusCh <- wsStatusResult{status: st, err: errS}
-			}()
-
-			var st map[string]interface{}
-			var errS error
-
-			select {
-			case <-time.After(5 * time.Second):
-				ws.logger.Error("Failed to get status", slog.String("component", "websocket-server"), slog.String("error", "internal: status collection timed out: context canceled"))
-				continue
-			case result := <-statusCh:
-				st = result.status
-				errS = result.err
-			}
-
-			if errS != nil {
-				ws.logger.Error("Failed to get status", slog.String("component", "websocket-server"), slog.String("error", errS.Error()))
-				continue
+		case update, ok := <-sub.C:
+			if !ok {
+				ws.logger.Info("Status broadcaster stopped, closing WebSocket connection", slog.String("component", "websocket-server"))
+				return
 			}
 
 			// Send status update with timeout
@@ -243,7 +224,7 @@ func (ws *WebSocketServer) statusHandler(w http.ResponseWriter, r *http.Request)
 				ws.logger.Error("failed to set write deadline for status update", slog.String("error", err.Error()))
 				return
 			}
-			if err := conn.WriteJSON(st); err != nil {
+			if err := conn.WriteMessage(websocket.TextMessage, update.JSON); err != nil {
 				ws.logger.Error("Failed to send status", slog.String("component", "websocket-server"), slog.String("error", err.Error()))
 				return
 			}
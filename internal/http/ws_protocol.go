@@ -0,0 +1,258 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+	"github.com/slavakukuyev/elevator-go/internal/events"
+	"github.com/slavakukuyev/elevator-go/internal/manager"
+)
+
+// Status WebSocket protocol v2
+//
+// Clients opt in with the "elevator.v2" subprotocol or the protocol=v2 query
+// parameter; everyone else keeps receiving full status maps (v1).
+//
+// Client messages:
+//
+//	{"type":"subscribe","elevators":["A","B"],"events":["floor_arrived"]}
+//	{"type":"resync"}
+//	{"type":"request","id":"c1","from_floor":1,"to_floor":5}
+//	{"type":"cancel","id":"c2","trip_id":"trip-7"}
+//	{"type":"track","id":"c3","trip_id":"trip-7"}
+//
+// An empty elevators list subscribes to all cars; events is optional. Every
+// subscribe is acknowledged and followed by a fresh snapshot.
+//
+// request, cancel and track are commands: the optional id is echoed on the
+// ack or error that answers them. request and cancel are acknowledged with
+// the trip; track is acknowledged with the trip's current state and followed
+// by a trip message on every lifecycle change until it completes or is
+// cancelled.
+//
+// Server messages:
+//
+//	{"type":"snapshot","seq":1,"elevators":{"A":{...}}}
+//	{"type":"delta","seq":2,"ops":[{"op":"replace","path":"/A/current_floor","value":3}]}
+//	{"type":"event","event":{...}}
+//	{"type":"subscribed","elevators":["A"],"events":["floor_arrived"]}
+//	{"type":"ack","id":"c1","data":{"trip_id":"trip-7","state":"assigned",...}}
+//	{"type":"trip","id":"c3","data":{"trip_id":"trip-7","state":"picked_up",...}}
+//	{"type":"error","id":"c1","error":{"code":"VALIDATION_ERROR","message":"...",...}}
+//
+// Errors use the APIError shape of the REST API.
+//
+// Status messages are numbered consecutively per connection. A delta applies
+// to the state produced by the message with the previous seq; a client that
+// sees a gap sends resync and receives a new snapshot.
+const (
+	wsProtocolV2 = "elevator.v2"
+
+	wsMessageSubscribe  = "subscribe"
+	wsMessageResync     = "resync"
+	wsMessageRequest    = "request"
+	wsMessageCancel     = "cancel"
+	wsMessageTrack      = "track"
+	wsMessageAck        = "ack"
+	wsMessageTrip       = "trip"
+	wsMessageSnapshot   = "snapshot"
+	wsMessageDelta      = "delta"
+	wsMessageEvent      = "event"
+	wsMessageSubscribed = "subscribed"
+	wsMessageError      = "error"
+
+	patchOpAdd     = "add"
+	patchOpReplace = "replace"
+	patchOpRemove  = "remove"
+)
+
+// wsSubscribableEvents lists the event types a v2 client may subscribe to
+var wsSubscribableEvents = map[events.Type]bool{
+	events.TypeRequestAssigned:      true,
+	events.TypeRequestPickedUp:      true,
+	events.TypeRequestCompleted:     true,
+	events.TypeRequestCancelled:     true,
+	events.TypeRequestRejected:      true,
+	events.TypeFloorArrived:         true,
+	events.TypeStopServed:           true,
+	events.TypeElevatorAdded:        true,
+	events.TypeElevatorRemoved:      true,
+	events.TypeElevatorFault:        true,
+	events.TypeElevatorRecovered:    true,
+	events.TypeCircuitBreakerOpened: true,
+	events.TypeAnnouncement:         true,
+	events.TypeSLABreached:          true,
+	events.TypeSLAResolved:          true,
+}
+
+// wsClientMessage is a command sent by a v2 client
+type wsClientMessage struct {
+	Type      string   `json:"type"`
+	ID        string   `json:"id,omitempty"`
+	Elevators []string `json:"elevators,omitempty"`
+	Events    []string `json:"events,omitempty"`
+	FromFloor *int     `json:"from_floor,omitempty"`
+	ToFloor   *int     `json:"to_floor,omitempty"`
+	TripID    string   `json:"trip_id,omitempty"`
+
+	// Call options of a request command, as in the REST floor request;
+	// StaffToken plays the part of the X-Staff-Token header
+	Cargo      bool   `json:"cargo,omitempty"`
+	Priority   string `json:"priority,omitempty"`
+	Accessible bool   `json:"accessible,omitempty"`
+	StaffToken string `json:"staff_token,omitempty"`
+}
+
+type wsSnapshotMessage struct {
+	Type      string                           `json:"type"`
+	Seq       uint64                           `json:"seq"`
+	Elevators map[string]domain.ElevatorStatus `json:"elevators"`
+}
+
+type wsDeltaMessage struct {
+	Type string    `json:"type"`
+	Seq  uint64    `json:"seq"`
+	Ops  []patchOp `json:"ops"`
+}
+
+type wsEventMessage struct {
+	Type  string       `json:"type"`
+	Event events.Event `json:"event"`
+}
+
+type wsSubscribedMessage struct {
+	Type      string   `json:"type"`
+	Elevators []string `json:"elevators"`
+	Events    []string `json:"events"`
+}
+
+// wsReplyMessage answers a command (ack) or reports a trip change; ID echoes
+// the command's correlation id
+type wsReplyMessage struct {
+	Type string       `json:"type"`
+	ID   string       `json:"id,omitempty"`
+	Data manager.Trip `json:"data"`
+}
+
+type wsErrorMessage struct {
+	Type  string    `json:"type"`
+	ID    string    `json:"id,omitempty"`
+	Error *APIError `json:"error"`
+}
+
+// patchOp is a JSON Patch (RFC 6902) style operation on the status document,
+// which is an object keyed by elevator name
+type patchOp struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// wantsStatusProtocolV2 reports whether the client negotiated the v2 protocol
+func wantsStatusProtocolV2(conn *websocket.Conn, r *http.Request) bool {
+	return conn.Subprotocol() == wsProtocolV2 || r.URL.Query().Get("protocol") == "v2"
+}
+
+// statusPatch returns the operations that turn previous into current. Added
+// and removed cars are whole-object operations; changed cars get one replace
+// per changed field.
+func statusPatch(previous, current map[string]domain.ElevatorStatus) ([]patchOp, error) {
+	var ops []patchOp
+
+	names := make([]string, 0, len(current))
+	for name := range current {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		status := current[name]
+		old, existed := previous[name]
+		if existed && old == status {
+			continue
+		}
+
+		if !existed {
+			value, err := json.Marshal(status)
+			if err != nil {
+				return nil, err
+			}
+			ops = append(ops, patchOp{Op: patchOpAdd, Path: patchPath(name), Value: value})
+			continue
+		}
+
+		fieldOps, err := statusFieldPatch(name, old, status)
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, fieldOps...)
+	}
+
+	removed := make([]string, 0)
+	for name := range previous {
+		if _, ok := current[name]; !ok {
+			removed = append(removed, name)
+		}
+	}
+	sort.Strings(removed)
+	for _, name := range removed {
+		ops = append(ops, patchOp{Op: patchOpRemove, Path: patchPath(name)})
+	}
+
+	return ops, nil
+}
+
+// statusFieldPatch compares two statuses field by field using their JSON
+// representation, so new ElevatorStatus fields are picked up automatically
+func statusFieldPatch(name string, old, current domain.ElevatorStatus) ([]patchOp, error) {
+	oldFields, err := statusFields(old)
+	if err != nil {
+		return nil, err
+	}
+	newFields, err := statusFields(current)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(newFields))
+	for key := range newFields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var ops []patchOp
+	for _, key := range keys {
+		if string(oldFields[key]) != string(newFields[key]) {
+			ops = append(ops, patchOp{Op: patchOpReplace, Path: patchPath(name, key), Value: newFields[key]})
+		}
+	}
+	return ops, nil
+}
+
+func statusFields(status domain.ElevatorStatus) (map[string]json.RawMessage, error) {
+	raw, err := json.Marshal(status)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// patchPath builds a JSON Pointer (RFC 6901) from unescaped tokens
+func patchPath(tokens ...string) string {
+	var b strings.Builder
+	escaper := strings.NewReplacer("~", "~0", "/", "~1")
+	for _, token := range tokens {
+		b.WriteByte('/')
+		b.WriteString(escaper.Replace(token))
+	}
+	return b.String()
+}
@@ -0,0 +1,427 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+	"github.com/slavakukuyev/elevator-go/internal/events"
+	"github.com/slavakukuyev/elevator-go/internal/factory"
+	"github.com/slavakukuyev/elevator-go/internal/manager"
+)
+
+func TestStatusPatch(t *testing.T) {
+	a := domain.ElevatorStatus{Name: "A", CurrentFloor: domain.NewFloor(1), MaxFloor: domain.NewFloor(9)}
+	moved := a
+	moved.CurrentFloor = domain.NewFloor(2)
+	moved.Direction = domain.DirectionUp
+	b := domain.ElevatorStatus{Name: "B/1", MaxFloor: domain.NewFloor(5)}
+
+	tests := []struct {
+		name     string
+		previous map[string]domain.ElevatorStatus
+		current  map[string]domain.ElevatorStatus
+		want     []patchOp
+	}{
+		{
+			name:     "unchanged",
+			previous: map[string]domain.ElevatorStatus{"A": a},
+			current:  map[string]domain.ElevatorStatus{"A": a},
+		},
+		{
+			name:     "field changes",
+			previous: map[string]domain.ElevatorStatus{"A": a},
+			current:  map[string]domain.ElevatorStatus{"A": moved},
+			want: []patchOp{
+				{Op: patchOpReplace, Path: "/A/current_floor", Value: json.RawMessage(`2`)},
+				{Op: patchOpReplace, Path: "/A/direction", Value: json.RawMessage(`"up"`)},
+			},
+		},
+		{
+			name:     "added and removed cars",
+			previous: map[string]domain.ElevatorStatus{"A": a},
+			current:  map[string]domain.ElevatorStatus{"B/1": b},
+			want: []patchOp{
+				{Op: patchOpAdd, Path: "/B~11", Value: mustMarshal(t, b)},
+				{Op: patchOpRemove, Path: "/A"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ops, err := statusPatch(tt.previous, tt.current)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, ops)
+		})
+	}
+}
+
+func TestStatusPatch_ValueSerialization(t *testing.T) {
+	previous := map[string]domain.ElevatorStatus{"A": {Name: "A", DoorOpen: true}}
+	current := map[string]domain.ElevatorStatus{"A": {Name: "A"}}
+
+	ops, err := statusPatch(previous, current)
+	require.NoError(t, err)
+
+	// A replace with a zero value must still carry the value
+	raw, err := json.Marshal(ops)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"op":"replace","path":"/A/door_open","value":false}]`, string(raw))
+}
+
+func TestPatchPath_EscapesTokens(t *testing.T) {
+	assert.Equal(t, "/a~1b/c~0d", patchPath("a/b", "c~d"))
+}
+
+func mustMarshal(t *testing.T, v any) json.RawMessage {
+	t.Helper()
+	raw, err := json.Marshal(v)
+	require.NoError(t, err)
+	return raw
+}
+
+// wsTestMessage is a decoded server message of any v2 type
+type wsTestMessage struct {
+	Type      string          `json:"type"`
+	ID        string          `json:"id"`
+	Seq       uint64          `json:"seq"`
+	Elevators json.RawMessage `json:"elevators"`
+	Ops       []patchOp       `json:"ops"`
+	Event     events.Event    `json:"event"`
+	Events    []string        `json:"events"`
+	Error     *APIError       `json:"error"`
+	Data      manager.Trip    `json:"data"`
+	statuses  map[string]domain.ElevatorStatus
+}
+
+func setupStatusProtocolServer(t *testing.T) (*manager.Manager, *httptest.Server) {
+	t.Helper()
+
+	cfg := buildServerTestConfig()
+	cfg.WebSocketWriteTimeout = 2 * time.Second
+	cfg.WebSocketReadTimeout = 10 * time.Second
+	cfg.WebSocketPingInterval = 5 * time.Second
+	m := manager.New(cfg, &factory.StandardElevatorFactory{})
+	t.Cleanup(m.Shutdown)
+
+	server := NewServer(cfg, 8080, m)
+	ts := httptest.NewServer(http.HandlerFunc(server.statusWebSocketHandler))
+	t.Cleanup(ts.Close)
+	return m, ts
+}
+
+func dialStatusV2(t *testing.T, ts *httptest.Server) *websocket.Conn {
+	t.Helper()
+
+	dialer := websocket.Dialer{Subprotocols: []string{wsProtocolV2}}
+	conn, resp, err := dialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http"), nil)
+	require.NoError(t, err)
+	require.Equal(t, wsProtocolV2, resp.Header.Get("Sec-WebSocket-Protocol"))
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func readWSMessage(t *testing.T, conn *websocket.Conn) wsTestMessage {
+	t.Helper()
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(3*time.Second)))
+	var msg wsTestMessage
+	require.NoError(t, conn.ReadJSON(&msg))
+	if msg.Type == wsMessageSnapshot {
+		require.NoError(t, json.Unmarshal(msg.Elevators, &msg.statuses))
+	}
+	return msg
+}
+
+// readUntil skips messages until one of the given type satisfies match
+func readUntil(t *testing.T, conn *websocket.Conn, msgType string, match func(wsTestMessage) bool) wsTestMessage {
+	t.Helper()
+
+	for {
+		msg := readWSMessage(t, conn)
+		if msg.Type == msgType && (match == nil || match(msg)) {
+			return msg
+		}
+	}
+}
+
+func TestStatusWebSocketV2_SnapshotThenDeltas(t *testing.T) {
+	m, ts := setupStatusProtocolServer(t)
+	cfg := buildServerTestConfig()
+	require.NoError(t, m.AddElevator(context.Background(), cfg, "A", 0, 9,
+		cfg.EachFloorDuration, cfg.OpenDoorDuration, 12))
+
+	conn := dialStatusV2(t, ts)
+
+	snapshot := readUntil(t, conn, wsMessageSnapshot, nil)
+	assert.Equal(t, uint64(1), snapshot.Seq)
+	require.Contains(t, snapshot.statuses, "A")
+
+	_, err := m.RequestElevator(context.Background(), 0, 2)
+	require.NoError(t, err)
+
+	// Deltas are consecutive and eventually move the car to floor 2
+	lastSeq := snapshot.Seq
+	arrived := false
+	for !arrived {
+		delta := readWSMessage(t, conn)
+		require.Equal(t, wsMessageDelta, delta.Type)
+		require.Equal(t, lastSeq+1, delta.Seq)
+		lastSeq = delta.Seq
+		for _, op := range delta.Ops {
+			assert.True(t, strings.HasPrefix(op.Path, "/A/"), op.Path)
+			if op.Path == "/A/current_floor" && string(op.Value) == "2" {
+				arrived = true
+			}
+		}
+	}
+
+	// Resync returns a fresh snapshot with the next sequence number
+	require.NoError(t, conn.WriteJSON(wsClientMessage{Type: wsMessageResync}))
+	resync := readUntil(t, conn, wsMessageSnapshot, nil)
+	assert.Greater(t, resync.Seq, lastSeq)
+	assert.Equal(t, 2, resync.statuses["A"].CurrentFloor.Value())
+}
+
+func TestStatusWebSocketV2_SubscribeFiltersCarsAndEvents(t *testing.T) {
+	m, ts := setupStatusProtocolServer(t)
+	cfg := buildServerTestConfig()
+	for _, name := range []string{"A", "B"} {
+		require.NoError(t, m.AddElevator(context.Background(), cfg, name, 0, 9,
+			cfg.EachFloorDuration, cfg.OpenDoorDuration, 12))
+	}
+
+	conn := dialStatusV2(t, ts)
+	readUntil(t, conn, wsMessageSnapshot, nil)
+
+	require.NoError(t, conn.WriteJSON(wsClientMessage{
+		Type:      wsMessageSubscribe,
+		Elevators: []string{"B"},
+		Events:    []string{string(events.TypeRequestAssigned)},
+	}))
+
+	ack := readUntil(t, conn, wsMessageSubscribed, nil)
+	assert.Equal(t, []string{string(events.TypeRequestAssigned)}, ack.Events)
+
+	snapshot := readUntil(t, conn, wsMessageSnapshot, nil)
+	assert.Len(t, snapshot.statuses, 1)
+	assert.Contains(t, snapshot.statuses, "B")
+
+	// Route a request to whichever car is chosen; only B's events and deltas
+	// may arrive
+	el, err := m.RequestElevator(context.Background(), 0, 3)
+	require.NoError(t, err)
+	if el.Name() != "B" {
+		el, err = m.RequestElevator(context.Background(), 9, 4)
+		require.NoError(t, err)
+	}
+	require.Equal(t, "B", el.Name(), "expected one of the requests to be assigned to B")
+
+	event := readUntil(t, conn, wsMessageEvent, nil)
+	assert.Equal(t, events.TypeRequestAssigned, event.Event.Type)
+	assert.Equal(t, "B", event.Event.Elevator)
+
+	delta := readUntil(t, conn, wsMessageDelta, nil)
+	for _, op := range delta.Ops {
+		assert.True(t, strings.HasPrefix(op.Path, "/B/"), op.Path)
+	}
+}
+
+func TestStatusWebSocketV2_RejectsInvalidMessages(t *testing.T) {
+	_, ts := setupStatusProtocolServer(t)
+	conn := dialStatusV2(t, ts)
+	readUntil(t, conn, wsMessageSnapshot, nil)
+
+	tests := []struct {
+		name    string
+		message string
+		code    string
+		want    string
+	}{
+		{name: "malformed", message: `{"type":`, code: ErrorCodeInvalidJSON, want: "malformed message"},
+		{name: "unknown type", message: `{"type":"dance","id":"c1"}`, code: ErrorCodeValidation, want: `unknown message type "dance"`},
+		{name: "unknown event", message: `{"type":"subscribe","events":["door_jammed"]}`, code: ErrorCodeValidation, want: `unknown event type "door_jammed"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(tt.message)))
+			msg := readUntil(t, conn, wsMessageError, nil)
+			require.NotNil(t, msg.Error)
+			assert.Equal(t, tt.code, msg.Error.Code)
+			assert.Contains(t, msg.Error.Details, tt.want)
+		})
+	}
+
+	// The connection stays usable after errors
+	require.NoError(t, conn.WriteJSON(wsClientMessage{Type: wsMessageResync}))
+	readUntil(t, conn, wsMessageSnapshot, nil)
+}
+
+func TestStatusWebSocket_DefaultsToFullSnapshots(t *testing.T) {
+	_, ts := setupStatusProtocolServer(t)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http"), nil)
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(3*time.Second)))
+	_, data, err := conn.ReadMessage()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{}`, string(data), "v1 clients receive the plain status map")
+}
+
+func intPtr(v int) *int { return &v }
+
+func TestStatusWebSocketV2_RequestCommand(t *testing.T) {
+	m, ts := setupStatusProtocolServer(t)
+	cfg := buildServerTestConfig()
+	require.NoError(t, m.AddElevator(context.Background(), cfg, "A", 0, 9,
+		cfg.EachFloorDuration, cfg.OpenDoorDuration, 12))
+
+	conn := dialStatusV2(t, ts)
+	readUntil(t, conn, wsMessageSnapshot, nil)
+
+	tests := []struct {
+		name    string
+		message wsClientMessage
+		code    string
+	}{
+		{name: "missing floors", message: wsClientMessage{Type: wsMessageRequest, ID: "r1", FromFloor: intPtr(1)}, code: ErrorCodeValidation},
+		{name: "same floor", message: wsClientMessage{Type: wsMessageRequest, ID: "r2", FromFloor: intPtr(3), ToFloor: intPtr(3)}, code: ErrorCodeValidation},
+		{name: "out of range", message: wsClientMessage{Type: wsMessageRequest, ID: "r3", FromFloor: intPtr(0), ToFloor: intPtr(50)}, code: ErrorCodeValidation},
+		{name: "unknown trip", message: wsClientMessage{Type: wsMessageCancel, ID: "r4", TripID: "trip-404"}, code: ErrorCodeNotFound},
+		{name: "missing trip id", message: wsClientMessage{Type: wsMessageTrack, ID: "r5"}, code: ErrorCodeValidation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, conn.WriteJSON(tt.message))
+			msg := readUntil(t, conn, wsMessageError, nil)
+			assert.Equal(t, tt.message.ID, msg.ID, "errors carry the correlation id")
+			require.NotNil(t, msg.Error)
+			assert.Equal(t, tt.code, msg.Error.Code)
+		})
+	}
+
+	require.NoError(t, conn.WriteJSON(wsClientMessage{Type: wsMessageRequest, ID: "ok", FromFloor: intPtr(5), ToFloor: intPtr(8)}))
+	ack := readUntil(t, conn, wsMessageAck, nil)
+	assert.Equal(t, "ok", ack.ID)
+	assert.Equal(t, "A", ack.Data.Elevator)
+	assert.Equal(t, manager.TripAssigned, ack.Data.State)
+	assert.Equal(t, 5, ack.Data.FromFloor)
+	assert.Equal(t, 8, ack.Data.ToFloor)
+	assert.NotEmpty(t, ack.Data.ID)
+
+	// Cancel the request before the car reaches the pickup floor
+	require.NoError(t, conn.WriteJSON(wsClientMessage{Type: wsMessageCancel, ID: "c1", TripID: ack.Data.ID}))
+	msg := readUntil(t, conn, wsMessageAck, nil)
+	assert.Equal(t, "c1", msg.ID)
+	assert.Equal(t, manager.TripCancelled, msg.Data.State)
+
+	// A cancelled request cannot be cancelled again
+	require.NoError(t, conn.WriteJSON(wsClientMessage{Type: wsMessageCancel, ID: "c2", TripID: ack.Data.ID}))
+	conflict := readUntil(t, conn, wsMessageError, nil)
+	assert.Equal(t, "c2", conflict.ID)
+	assert.Equal(t, ErrorCodeConflict, conflict.Error.Code)
+}
+
+func TestStatusWebSocketV2_RequestCommandCallOptions(t *testing.T) {
+	cfg := buildServerTestConfig()
+	cfg.StaffAPIToken = "staff-secret"
+	cfg.WebSocketWriteTimeout = 2 * time.Second
+	cfg.WebSocketReadTimeout = 10 * time.Second
+	cfg.WebSocketPingInterval = 5 * time.Second
+	m := manager.New(cfg, &factory.StandardElevatorFactory{})
+	t.Cleanup(m.Shutdown)
+	server := NewServer(cfg, 8080, m)
+	ts := httptest.NewServer(http.HandlerFunc(server.statusWebSocketHandler))
+	t.Cleanup(ts.Close)
+
+	require.NoError(t, m.AddTypedElevator(context.Background(), cfg, domain.ElevatorTypeService, "Service", 0, 9,
+		cfg.EachFloorDuration, cfg.OpenDoorDuration, 12))
+
+	conn := dialStatusV2(t, ts)
+	readUntil(t, conn, wsMessageSnapshot, nil)
+
+	tests := []struct {
+		name    string
+		message wsClientMessage
+		code    string
+	}{
+		{name: "invalid priority", message: wsClientMessage{Type: wsMessageRequest, ID: "p1", FromFloor: intPtr(1), ToFloor: intPtr(5), Priority: "urgent"}, code: ErrorCodeValidation},
+		{name: "invalid staff token", message: wsClientMessage{Type: wsMessageRequest, ID: "p2", FromFloor: intPtr(1), ToFloor: intPtr(5), StaffToken: "guess"}, code: ErrorCodeUnauthorized},
+		{name: "emergency without staff token", message: wsClientMessage{Type: wsMessageRequest, ID: "p4", FromFloor: intPtr(1), ToFloor: intPtr(5), Priority: "emergency"}, code: ErrorCodeUnauthorized},
+		{name: "service car without staff token", message: wsClientMessage{Type: wsMessageRequest, ID: "p3", FromFloor: intPtr(1), ToFloor: intPtr(5)}, code: ErrorCodeNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, conn.WriteJSON(tt.message))
+			msg := readUntil(t, conn, wsMessageError, nil)
+			assert.Equal(t, tt.message.ID, msg.ID)
+			require.NotNil(t, msg.Error)
+			assert.Equal(t, tt.code, msg.Error.Code)
+		})
+	}
+
+	// A staff call reaches the service car and keeps its options
+	require.NoError(t, conn.WriteJSON(wsClientMessage{Type: wsMessageRequest, ID: "ok", FromFloor: intPtr(1), ToFloor: intPtr(5),
+		Priority: "high", Accessible: true, StaffToken: "staff-secret"}))
+	ack := readUntil(t, conn, wsMessageAck, nil)
+	assert.Equal(t, "ok", ack.ID)
+	assert.Equal(t, "Service", ack.Data.Elevator)
+	assert.True(t, ack.Data.Staff)
+	assert.True(t, ack.Data.Accessible)
+	assert.Equal(t, domain.PriorityHigh, ack.Data.Priority)
+}
+
+func TestStatusWebSocketV2_TrackCommand(t *testing.T) {
+	m, ts := setupStatusProtocolServer(t)
+	cfg := buildServerTestConfig()
+	require.NoError(t, m.AddElevator(context.Background(), cfg, "A", 0, 9,
+		cfg.EachFloorDuration, cfg.OpenDoorDuration, 12))
+
+	conn := dialStatusV2(t, ts)
+	readUntil(t, conn, wsMessageSnapshot, nil)
+
+	require.NoError(t, conn.WriteJSON(wsClientMessage{Type: wsMessageRequest, ID: "r1", FromFloor: intPtr(1), ToFloor: intPtr(2)}))
+	ack := readUntil(t, conn, wsMessageAck, nil)
+	tripID := ack.Data.ID
+
+	require.NoError(t, conn.WriteJSON(wsClientMessage{Type: wsMessageTrack, ID: "t1", TripID: tripID}))
+	tracked := readUntil(t, conn, wsMessageAck, nil)
+	assert.Equal(t, "t1", tracked.ID)
+	assert.Equal(t, tripID, tracked.Data.ID)
+
+	// The trip is reported through every remaining stage under the track id
+	var states []manager.TripState
+	if tracked.Data.State != manager.TripAssigned {
+		states = append(states, tracked.Data.State)
+	}
+	for len(states) == 0 || states[len(states)-1] != manager.TripCompleted {
+		msg := readUntil(t, conn, wsMessageTrip, nil)
+		assert.Equal(t, "t1", msg.ID)
+		assert.Equal(t, tripID, msg.Data.ID)
+		states = append(states, msg.Data.State)
+	}
+	assert.Contains(t, []string{"picked_up,completed", "completed"}, joinStates(states))
+}
+
+func joinStates(states []manager.TripState) string {
+	parts := make([]string, len(states))
+	for i, state := range states {
+		parts[i] = string(state)
+	}
+	return strings.Join(parts, ",")
+}
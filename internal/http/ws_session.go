@@ -0,0 +1,486 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/slavakukuyev/elevator-go/internal/broadcast"
+	"github.com/slavakukuyev/elevator-go/internal/cluster"
+	"github.com/slavakukuyev/elevator-go/internal/constants"
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+	"github.com/slavakukuyev/elevator-go/internal/events"
+	"github.com/slavakukuyev/elevator-go/internal/manager"
+	"github.com/slavakukuyev/elevator-go/metrics"
+)
+
+const (
+	// wsMaxClientMessageSize bounds the size of a single v2 client command
+	wsMaxClientMessageSize = 4096
+	// wsEventBuffer bounds the events queued for one v2 client
+	wsEventBuffer = 64
+)
+
+// statusSessionTimeouts holds the keep-alive settings of a WebSocket session
+type statusSessionTimeouts struct {
+	write time.Duration
+	read  time.Duration
+	ping  time.Duration
+}
+
+// statusSession serves one WebSocket client speaking protocol v2. It is
+// shared by the API server and the standalone WebSocket server.
+type statusSession struct {
+	conn      *websocket.Conn
+	manager   *manager.Manager
+	cluster   *cluster.Node // routes trip commands to the leader when set
+	logger    *slog.Logger
+	requestID string
+	timeouts  statusSessionTimeouts
+
+	// subscription state, owned by the run loop
+	elevators map[string]bool // nil means all cars
+	events    map[events.Type]bool
+	tracked   map[string]string // trip ID -> correlation id of the track command
+	eventSub  *events.Subscription
+
+	// delta state, owned by the run loop
+	latest *broadcast.Update
+	sent   map[string]domain.ElevatorStatus
+	seq    uint64
+}
+
+func newStatusSession(conn *websocket.Conn, manager *manager.Manager, node *cluster.Node, logger *slog.Logger, requestID string, timeouts statusSessionTimeouts) *statusSession {
+	return &statusSession{
+		conn:      conn,
+		manager:   manager,
+		cluster:   node,
+		logger:    logger,
+		requestID: requestID,
+		timeouts:  timeouts,
+		events:    make(map[events.Type]bool),
+		tracked:   make(map[string]string),
+	}
+}
+
+// run serves the session until the client disconnects or ctx is cancelled
+func (s *statusSession) run(ctx context.Context) {
+	statusSub := s.manager.Broadcaster().Subscribe(statusSubscriberBuffer)
+	defer statusSub.Close()
+	defer s.closeEvents()
+
+	s.conn.SetReadLimit(wsMaxClientMessageSize)
+	if err := s.conn.SetReadDeadline(time.Now().Add(s.timeouts.read)); err != nil {
+		s.logger.ErrorContext(ctx, "failed to set read deadline",
+			slog.String("error", err.Error()))
+		return
+	}
+	s.conn.SetPongHandler(func(string) error {
+		if err := s.conn.SetReadDeadline(time.Now().Add(s.timeouts.read)); err != nil {
+			s.logger.ErrorContext(ctx, "failed to set read deadline in pong handler",
+				slog.String("error", err.Error()))
+		}
+		return nil
+	})
+
+	commands := make(chan wsClientMessage)
+	invalid := make(chan string)
+	done := make(chan struct{})
+	stop := make(chan struct{})
+	defer close(stop)
+	go s.readLoop(ctx, commands, invalid, done, stop)
+
+	pingTicker := time.NewTicker(s.timeouts.ping)
+	defer pingTicker.Stop()
+
+	for {
+		var err error
+		select {
+		case <-done:
+			s.logger.InfoContext(ctx, "WebSocket connection closed by client")
+			return
+
+		case <-ctx.Done():
+			s.logger.InfoContext(ctx, "WebSocket connection context cancelled")
+			if err := s.conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseNormalClosure, "Server shutdown"),
+				time.Now().Add(s.timeouts.write)); err != nil {
+				s.logger.ErrorContext(ctx, "failed to send close message",
+					slog.String("error", err.Error()))
+			}
+			return
+
+		case <-pingTicker.C:
+			if err = s.conn.SetWriteDeadline(time.Now().Add(s.timeouts.write)); err == nil {
+				err = s.conn.WriteMessage(websocket.PingMessage, nil)
+			}
+
+		case update, ok := <-statusSub.C:
+			if !ok {
+				s.logger.InfoContext(ctx, "status broadcaster stopped, closing WebSocket connection")
+				return
+			}
+			s.latest = update
+			err = s.sendStatus()
+
+		case event, ok := <-s.nextEvent():
+			if !ok {
+				// Dropped by the bus for falling behind; resubscribe and tell the client
+				s.eventSub = nil
+				metrics.IncError("ws_event_overflow", constants.ComponentHTTPServer)
+				s.syncEventSubscription()
+				err = s.writeError("", ErrorCodeInternal, "Event stream overflowed", "some events were lost")
+				break
+			}
+			err = s.handleEvent(event)
+
+		case cmd := <-commands:
+			err = s.handleCommand(ctx, cmd)
+
+		case reason := <-invalid:
+			err = s.writeError("", ErrorCodeInvalidJSON, "Invalid JSON", reason)
+		}
+
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed to write to WebSocket client",
+				slog.String("error", err.Error()))
+			return
+		}
+	}
+}
+
+// readLoop parses client commands and hands them to the run loop until the
+// connection fails or the run loop stops
+func (s *statusSession) readLoop(ctx context.Context, commands chan<- wsClientMessage, invalid chan<- string, done, stop chan struct{}) {
+	defer close(done)
+	for {
+		_, data, err := s.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure, websocket.CloseNormalClosure) {
+				s.logger.WarnContext(ctx, "WebSocket connection closed unexpectedly",
+					slog.String("error", err.Error()))
+			}
+			return
+		}
+
+		var cmd wsClientMessage
+		if err := json.Unmarshal(data, &cmd); err != nil {
+			select {
+			case invalid <- "malformed message: " + err.Error():
+			case <-stop:
+				return
+			}
+			continue
+		}
+
+		select {
+		case commands <- cmd:
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (s *statusSession) handleCommand(ctx context.Context, cmd wsClientMessage) error {
+	switch cmd.Type {
+	case wsMessageSubscribe:
+		return s.subscribe(cmd)
+	case wsMessageResync:
+		return s.sendSnapshot()
+	case wsMessageRequest:
+		return s.placeRequest(ctx, cmd)
+	case wsMessageCancel:
+		return s.cancelRequest(ctx, cmd)
+	case wsMessageTrack:
+		return s.trackRequest(cmd)
+	default:
+		return s.writeError(cmd.ID, ErrorCodeValidation, "Unknown message type",
+			fmt.Sprintf("unknown message type %q", cmd.Type))
+	}
+}
+
+// placeRequest validates a floor request like FloorRequestHandler and
+// assigns it to an elevator
+func (s *statusSession) placeRequest(ctx context.Context, cmd wsClientMessage) error {
+	if cmd.FromFloor == nil || cmd.ToFloor == nil {
+		return s.writeError(cmd.ID, ErrorCodeValidation, "Invalid input provided",
+			"from_floor and to_floor are required")
+	}
+	if err := validateFloorRequest(*cmd.FromFloor, *cmd.ToFloor); err != nil {
+		return s.writeDomainError(cmd.ID, err)
+	}
+
+	staff := cmd.StaffToken != ""
+	if staff && !validToken(cmd.StaffToken, s.manager.Config().StaffAPIToken) {
+		s.logger.WarnContext(ctx, "rejected WebSocket request with invalid staff token")
+		return s.writeError(cmd.ID, ErrorCodeUnauthorized, "Unauthorized", "Invalid staff token")
+	}
+	call, err := newCallOptions(cmd.Cargo, cmd.Priority, cmd.Accessible, staff)
+	if errors.Is(err, errEmergencyNeedsStaff) {
+		s.logger.WarnContext(ctx, "rejected WebSocket emergency call without staff token")
+		return s.writeError(cmd.ID, ErrorCodeUnauthorized, "Unauthorized", err.Error())
+	}
+	if err != nil {
+		return s.writeDomainError(cmd.ID, err)
+	}
+
+	trip, err := s.requestTrip(ctx, *cmd.FromFloor, *cmd.ToFloor, call)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "elevator request over WebSocket failed",
+			slog.Int("from_floor", *cmd.FromFloor),
+			slog.Int("to_floor", *cmd.ToFloor),
+			slog.String("error", err.Error()))
+		return s.writeDomainError(cmd.ID, err)
+	}
+	return s.write(wsReplyMessage{Type: wsMessageAck, ID: cmd.ID, Data: trip})
+}
+
+// requestTrip assigns a floor request through the cluster, whose leader
+// tracks the trip, or on the manager otherwise
+func (s *statusSession) requestTrip(ctx context.Context, fromFloor, toFloor int, call domain.CallOptions) (manager.Trip, error) {
+	if s.cluster != nil {
+		return s.cluster.RequestTrip(ctx, fromFloor, toFloor, call)
+	}
+	return s.manager.RequestTripWithOptions(ctx, fromFloor, toFloor, call)
+}
+
+// leaderRequired rejects trip commands on a cluster node that does not run
+// the fleet, naming the leader to send them to instead
+func (s *statusSession) leaderRequired() error {
+	if s.cluster == nil || s.cluster.IsLeader() {
+		return nil
+	}
+	return s.cluster.LeaderRequiredError()
+}
+
+func (s *statusSession) cancelRequest(ctx context.Context, cmd wsClientMessage) error {
+	if cmd.TripID == "" {
+		return s.writeError(cmd.ID, ErrorCodeValidation, "Invalid input provided", "trip_id is required")
+	}
+
+	if err := s.leaderRequired(); err != nil {
+		return s.writeDomainError(cmd.ID, err)
+	}
+
+	trip, err := s.manager.CancelRequest(ctx, cmd.TripID)
+	if err != nil {
+		return s.writeDomainError(cmd.ID, err)
+	}
+	return s.write(wsReplyMessage{Type: wsMessageAck, ID: cmd.ID, Data: trip})
+}
+
+// trackRequest acknowledges with the trip's current state and reports every
+// later change until the trip finishes
+func (s *statusSession) trackRequest(cmd wsClientMessage) error {
+	if cmd.TripID == "" {
+		return s.writeError(cmd.ID, ErrorCodeValidation, "Invalid input provided", "trip_id is required")
+	}
+	if err := s.leaderRequired(); err != nil {
+		return s.writeDomainError(cmd.ID, err)
+	}
+
+	// Subscribe before reading the state so no transition falls in between
+	s.tracked[cmd.TripID] = cmd.ID
+	s.syncEventSubscription()
+
+	trip, ok := s.manager.GetTrip(cmd.TripID)
+	if !ok {
+		delete(s.tracked, cmd.TripID)
+		s.syncEventSubscription()
+		return s.writeDomainError(cmd.ID, domain.NewNotFoundError("request not found", nil).
+			WithContext("trip_id", cmd.TripID))
+	}
+	if trip.IsFinished() {
+		delete(s.tracked, cmd.TripID)
+		s.syncEventSubscription()
+	}
+	return s.write(wsReplyMessage{Type: wsMessageAck, ID: cmd.ID, Data: trip})
+}
+
+// handleEvent forwards subscribed events and trip changes of tracked trips
+func (s *statusSession) handleEvent(event events.Event) error {
+	if s.wantsEvent(event) {
+		if err := s.write(wsEventMessage{Type: wsMessageEvent, Event: event}); err != nil {
+			return err
+		}
+	}
+
+	tripID, _ := event.Data["trip_id"].(string)
+	correlationID, tracked := s.tracked[tripID]
+	if !tracked {
+		return nil
+	}
+
+	trip, ok := s.manager.GetTrip(tripID)
+	if !ok {
+		delete(s.tracked, tripID)
+		s.syncEventSubscription()
+		return nil
+	}
+	// Report the state of this event even if the trip has moved on since
+	if state, ok := event.Data["state"].(string); ok {
+		trip.State = manager.TripState(state)
+	}
+	if trip.IsFinished() {
+		delete(s.tracked, tripID)
+		s.syncEventSubscription()
+	}
+	return s.write(wsReplyMessage{Type: wsMessageTrip, ID: correlationID, Data: trip})
+}
+
+// subscribe replaces the client's subscription, acknowledges it and sends a
+// snapshot of the newly selected cars
+func (s *statusSession) subscribe(cmd wsClientMessage) error {
+	eventTypes := make(map[events.Type]bool, len(cmd.Events))
+	for _, name := range cmd.Events {
+		eventType := events.Type(name)
+		if !wsSubscribableEvents[eventType] {
+			return s.writeError(cmd.ID, ErrorCodeValidation, "Unknown event type",
+				fmt.Sprintf("unknown event type %q", name))
+		}
+		eventTypes[eventType] = true
+	}
+
+	var elevators map[string]bool
+	if len(cmd.Elevators) > 0 {
+		elevators = make(map[string]bool, len(cmd.Elevators))
+		for _, name := range cmd.Elevators {
+			elevators[name] = true
+		}
+	}
+
+	s.elevators = elevators
+	s.events = eventTypes
+	s.syncEventSubscription()
+
+	ack := wsSubscribedMessage{
+		Type:      wsMessageSubscribed,
+		Elevators: sortedKeys(elevators),
+		Events:    make([]string, 0, len(eventTypes)),
+	}
+	for eventType := range eventTypes {
+		ack.Events = append(ack.Events, string(eventType))
+	}
+	sort.Strings(ack.Events)
+	if err := s.write(ack); err != nil {
+		return err
+	}
+
+	return s.sendSnapshot()
+}
+
+// sendStatus sends a snapshot first and deltas afterwards
+func (s *statusSession) sendStatus() error {
+	if s.sent == nil {
+		return s.sendSnapshot()
+	}
+
+	current := s.filtered()
+	ops, err := statusPatch(s.sent, current)
+	if err != nil {
+		return err
+	}
+	if len(ops) == 0 {
+		return nil
+	}
+
+	s.seq++
+	s.sent = current
+	return s.write(wsDeltaMessage{Type: wsMessageDelta, Seq: s.seq, Ops: ops})
+}
+
+func (s *statusSession) sendSnapshot() error {
+	if s.latest == nil {
+		s.latest = s.manager.Broadcaster().Latest()
+	}
+	if s.latest == nil {
+		// Nothing computed yet; the first update will be sent as a snapshot
+		return nil
+	}
+
+	s.seq++
+	s.sent = s.filtered()
+	return s.write(wsSnapshotMessage{Type: wsMessageSnapshot, Seq: s.seq, Elevators: s.sent})
+}
+
+// filtered returns the latest status restricted to the subscribed cars
+func (s *statusSession) filtered() map[string]domain.ElevatorStatus {
+	out := make(map[string]domain.ElevatorStatus)
+	if s.latest == nil {
+		return out
+	}
+	for name, status := range s.latest.Status {
+		if s.elevators == nil || s.elevators[name] {
+			out[name] = status
+		}
+	}
+	return out
+}
+
+func (s *statusSession) wantsEvent(event events.Event) bool {
+	if !s.events[event.Type] {
+		return false
+	}
+	return s.elevators == nil || event.Elevator == "" || s.elevators[event.Elevator]
+}
+
+// nextEvent returns the event channel, or nil when no events are subscribed
+func (s *statusSession) nextEvent() <-chan events.Event {
+	if s.eventSub == nil {
+		return nil
+	}
+	return s.eventSub.C
+}
+
+// syncEventSubscription holds an event subscription exactly while the client
+// subscribed to event types or tracks trips
+func (s *statusSession) syncEventSubscription() {
+	needed := len(s.events) > 0 || len(s.tracked) > 0
+	if needed && s.eventSub == nil {
+		s.eventSub = s.manager.Events().Subscribe(wsEventBuffer)
+	} else if !needed {
+		s.closeEvents()
+	}
+}
+
+func (s *statusSession) closeEvents() {
+	if s.eventSub != nil {
+		s.eventSub.Close()
+		s.eventSub = nil
+	}
+}
+
+func (s *statusSession) write(message any) error {
+	if err := s.conn.SetWriteDeadline(time.Now().Add(s.timeouts.write)); err != nil {
+		return err
+	}
+	return s.conn.WriteJSON(message)
+}
+
+func (s *statusSession) writeError(id, errorCode, message, details string) error {
+	return s.write(wsErrorMessage{
+		Type:  wsMessageError,
+		ID:    id,
+		Error: newAPIError(errorCode, message, details, s.requestID),
+	})
+}
+
+func (s *statusSession) writeDomainError(id string, err error) error {
+	_, apiError := newAPIErrorFromDomain(err, s.requestID)
+	return s.write(wsErrorMessage{Type: wsMessageError, ID: id, Error: apiError})
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,23 @@
+package config
+
+import (
+	"strings"
+	"time"
+
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+)
+
+// validateAnalyticsConfiguration validates where analytics records are kept
+// and for how long
+func validateAnalyticsConfiguration(cfg *Config) error {
+	if strings.TrimSpace(cfg.AnalyticsDir) == "" {
+		return domain.NewValidationError("analytics directory is required when analytics are enabled", nil)
+	}
+
+	if cfg.AnalyticsRetention < 24*time.Hour || cfg.AnalyticsRetention > 366*24*time.Hour {
+		return domain.NewValidationError("analytics retention must be between 24h and 8784h", nil).
+			WithContext("retention", cfg.AnalyticsRetention)
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_AnalyticsDefaults(t *testing.T) {
+	cleanup := clearEnvVars()
+	defer cleanup()
+
+	cfg, err := InitConfig()
+	require.NoError(t, err)
+	assert.False(t, cfg.AnalyticsEnabled)
+	assert.Equal(t, "data/analytics", cfg.AnalyticsDir)
+	assert.Equal(t, 30*24*time.Hour, cfg.AnalyticsRetention)
+}
+
+func TestConfig_AnalyticsValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		contains string
+	}{
+		{"blank directory", map[string]string{"ANALYTICS_DIR": " "}, "analytics directory is required"},
+		{"short retention", map[string]string{"ANALYTICS_RETENTION": "1h"}, "analytics retention must be between 24h and 8784h"},
+		{"long retention", map[string]string{"ANALYTICS_RETENTION": "9000h"}, "analytics retention must be between 24h and 8784h"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cleanup := clearEnvVars()
+			defer cleanup()
+
+			t.Setenv("ANALYTICS_ENABLED", "true")
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			_, err := InitConfig()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.contains)
+		})
+	}
+
+	// The settings are only checked when analytics are enabled
+	cleanup := clearEnvVars()
+	defer cleanup()
+	t.Setenv("ANALYTICS_RETENTION", "1h")
+	_, err := InitConfig()
+	assert.NoError(t, err)
+}
@@ -0,0 +1,155 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+)
+
+// buildingsKey is the file section holding per-building overrides
+const buildingsKey = "buildings"
+
+// buildingKeys lists the settings a building may override: its topology,
+// its fleet and how its elevators and dispatch behave. Server, transport and
+// logging settings are shared by the whole process.
+var buildingKeys = map[string]bool{
+	"DEFAULT_MIN_FLOOR":                 true,
+	"DEFAULT_MAX_FLOOR":                 true,
+	"DEFAULT_OVERLOAD_THRESHOLD":        true,
+	"EACH_FLOOR_DURATION":               true,
+	"OPEN_DOOR_DURATION":                true,
+	"MAX_ELEVATORS":                     true,
+	"DEFAULT_ELEVATOR_COUNT":            true,
+	"ELEVATOR_NAME_PREFIX":              true,
+	"BUILDING_MAX_CONCURRENT_REQUESTS":  true,
+	"CIRCUIT_BREAKER_ENABLED":           true,
+	"CIRCUIT_BREAKER_MAX_FAILURES":      true,
+	"CIRCUIT_BREAKER_RESET_TIMEOUT":     true,
+	"CIRCUIT_BREAKER_HALF_OPEN_LIMIT":   true,
+	"CIRCUIT_BREAKER_FAILURE_THRESHOLD": true,
+	"CIRCUIT_BREAKER_WINDOW":            true,
+	"CIRCUIT_BREAKER_MIN_REQUESTS":      true,
+	"STAFF_API_TOKEN":                   true,
+}
+
+func parseBuildingOverrides(value any) (map[string]map[string]string, error) {
+	buildings, ok := value.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%s must map building IDs to their settings", buildingsKey)
+	}
+
+	overrides := make(map[string]map[string]string, len(buildings))
+	for id, settings := range buildings {
+		fields, ok := settings.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("settings of building %q must be a mapping", id)
+		}
+
+		values := make(map[string]string, len(fields))
+		for key, raw := range fields {
+			setting := normalizeKey(key)
+			if !buildingKeys[setting] {
+				return nil, fmt.Errorf("unknown setting %q for building %q", key, id)
+			}
+			if !isScalar(raw) {
+				return nil, fmt.Errorf("setting %q for building %q must be a single value", key, id)
+			}
+			values[setting] = fmt.Sprint(raw)
+		}
+		overrides[id] = values
+	}
+
+	return overrides, nil
+}
+
+// BuildingIDs returns the IDs of the buildings configured in addition to
+// the default one, in order
+func (c *Config) BuildingIDs() []string {
+	ids := make([]string, 0, len(c.Buildings))
+	for id := range c.Buildings {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// BuildingConfig returns the configuration of a building: a copy of c with
+// the building's overrides applied and BuildingID set to id. The default
+// building and unknown IDs get c's own settings.
+func (c *Config) BuildingConfig(id string) (*Config, error) {
+	building := *c
+	building.Buildings = nil
+	if _, exists := c.Buildings[id]; exists {
+		building.BuildingID = id
+	}
+
+	value := reflect.ValueOf(&building).Elem()
+	fields := make(map[string]reflect.Value)
+	for i := 0; i < value.NumField(); i++ {
+		if key := value.Type().Field(i).Tag.Get("env"); key != "" {
+			fields[key] = value.Field(i)
+		}
+	}
+
+	for key, raw := range c.Buildings[id] {
+		field, ok := fields[key]
+		if !ok || !buildingKeys[key] {
+			return nil, domain.NewValidationError("setting cannot be overridden per building", nil).
+				WithContext("building", id).
+				WithContext("setting", key)
+		}
+		if err := setField(field, raw); err != nil {
+			return nil, domain.NewValidationError("invalid building setting", err).
+				WithContext("building", id).
+				WithContext("setting", key).
+				WithContext("value", raw)
+		}
+	}
+
+	return &building, nil
+}
+
+// validateBuildings validates the building IDs and the configuration each
+// building ends up with
+func validateBuildings(cfg *Config) error {
+	if !isValidBuildingID(cfg.BuildingID) {
+		return domain.NewValidationError("building id must be a non-empty path segment", nil).
+			WithContext("building_id", cfg.BuildingID)
+	}
+
+	if cfg.BuildingMaxConcurrentRequests <= 0 || cfg.BuildingMaxConcurrentRequests > 10000 {
+		return domain.NewValidationError("building max concurrent requests must be between 1 and 10000", nil).
+			WithContext("building_max_concurrent_requests", cfg.BuildingMaxConcurrentRequests)
+	}
+
+	for _, id := range cfg.BuildingIDs() {
+		if !isValidBuildingID(id) {
+			return domain.NewValidationError("building id must be a non-empty path segment", nil).
+				WithContext("building_id", id)
+		}
+		if id == cfg.BuildingID {
+			return domain.NewValidationError("building overrides cannot use the default building id", nil).
+				WithContext("building_id", id)
+		}
+
+		building, err := cfg.BuildingConfig(id)
+		if err != nil {
+			return err
+		}
+		if err := validateConfiguration(building); err != nil {
+			return domain.NewValidationError("invalid building configuration", err).
+				WithContext("building", id)
+		}
+	}
+
+	return nil
+}
+
+// isValidBuildingID reports whether id can be used as a single URL path
+// segment and metrics label
+func isValidBuildingID(id string) bool {
+	return id != "" && !strings.ContainsAny(id, "/?#{} \t\n")
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_BuildingConfig(t *testing.T) {
+	cleanup := clearEnvVars()
+	defer cleanup()
+
+	path := filepath.Join(t.TempDir(), "elevator.yaml")
+	writeEnvFile(t, path, `each_floor_duration: 300ms
+buildings:
+  tower:
+    default_min_floor: -3
+    default_max_floor: 40
+    default_elevator_count: 4
+    elevator_name_prefix: Tower
+    building_max_concurrent_requests: 10
+  annex:
+    each-floor-duration: 1s
+`)
+	t.Setenv(ConfigFileVar, path)
+
+	cfg, settings, err := Effective()
+	require.NoError(t, err)
+	assert.Equal(t, "main", cfg.BuildingID)
+	assert.Equal(t, []string{"annex", "tower"}, cfg.BuildingIDs())
+
+	tower, err := cfg.BuildingConfig("tower")
+	require.NoError(t, err)
+	assert.Equal(t, -3, tower.MinFloor)
+	assert.Equal(t, 40, tower.MaxFloor)
+	assert.Equal(t, 4, tower.DefaultElevatorCount)
+	assert.Equal(t, "Tower", tower.NamePrefix)
+	assert.Equal(t, 10, tower.BuildingMaxConcurrentRequests)
+	assert.Equal(t, 300*time.Millisecond, tower.EachFloorDuration)
+	assert.Equal(t, "tower", tower.BuildingID)
+	assert.Nil(t, tower.Buildings)
+
+	annex, err := cfg.BuildingConfig("annex")
+	require.NoError(t, err)
+	assert.Equal(t, time.Second, annex.EachFloorDuration)
+	assert.Equal(t, cfg.MaxFloor, annex.MaxFloor)
+
+	// The process-wide configuration is left alone
+	assert.Equal(t, 0, cfg.MinFloor)
+	assert.Equal(t, 300*time.Millisecond, cfg.EachFloorDuration)
+
+	assert.Equal(t, Setting{Key: "buildings.tower.default_max_floor", Value: "40", Source: SourceFile},
+		findSetting(settings, "buildings.tower.default_max_floor"))
+}
+
+func TestConfig_BuildingValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		contains string
+	}{
+		{"server setting", "buildings:\n  tower:\n    port: 7000\n", `unknown setting "port" for building "tower"`},
+		{"nested setting", "buildings:\n  tower:\n    default_max_floor:\n      value: 3\n", "must be a single value"},
+		{"invalid value", "buildings:\n  tower:\n    default_max_floor: tall\n", "invalid building setting"},
+		{"invalid topology", "buildings:\n  tower:\n    default_min_floor: 12\n", "invalid building configuration"},
+		{"invalid id", "buildings:\n  \"north/tower\":\n    default_max_floor: 12\n", "building id must be a non-empty path segment"},
+		{"default id", "buildings:\n  main:\n    default_max_floor: 12\n", "cannot use the default building id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cleanup := clearEnvVars()
+			defer cleanup()
+
+			path := filepath.Join(t.TempDir(), "elevator.yaml")
+			writeEnvFile(t, path, tt.content)
+			t.Setenv(ConfigFileVar, path)
+
+			_, err := InitConfig()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.contains)
+		})
+	}
+}
+
+func TestDiff_BuildingsRequireRestart(t *testing.T) {
+	old := &Config{}
+	updated := &Config{Buildings: map[string]map[string]string{"tower": {"DEFAULT_MAX_FLOOR": "40"}}}
+
+	changes := Diff(old, updated)
+	require.Len(t, changes, 1)
+	assert.Equal(t, Change{Key: "buildings", Old: "[]", New: "[tower]", Reloadable: false}, changes[0])
+}
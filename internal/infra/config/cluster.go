@@ -0,0 +1,91 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+)
+
+// ClusterPeer is one node of a cluster: the address its Raft transport
+// listens on and the base URL of its HTTP API, which followers forward
+// calls to
+type ClusterPeer struct {
+	ID       string
+	RaftAddr string
+	APIURL   string
+}
+
+// ParseClusterPeers parses a peer list of the form
+// "node1=10.0.0.1:7000@http://10.0.0.1:6660,node2=...".
+func ParseClusterPeers(spec string) ([]ClusterPeer, error) {
+	var peers []ClusterPeer
+	seen := make(map[string]bool)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		id, addresses, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("peer %q must have the form id=raft_addr@api_url", entry)
+		}
+		raftAddr, apiURL, ok := strings.Cut(addresses, "@")
+		if !ok {
+			return nil, fmt.Errorf("peer %q must have the form id=raft_addr@api_url", entry)
+		}
+
+		id, raftAddr, apiURL = strings.TrimSpace(id), strings.TrimSpace(raftAddr), strings.TrimSpace(apiURL)
+		if id == "" || raftAddr == "" {
+			return nil, fmt.Errorf("peer %q needs an id and a raft address", entry)
+		}
+		if parsed, err := url.Parse(apiURL); err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			return nil, fmt.Errorf("peer %q has an invalid api url %q", id, apiURL)
+		}
+		if seen[id] {
+			return nil, fmt.Errorf("peer %q is listed twice", id)
+		}
+		seen[id] = true
+
+		peers = append(peers, ClusterPeer{ID: id, RaftAddr: raftAddr, APIURL: strings.TrimRight(apiURL, "/")})
+	}
+	return peers, nil
+}
+
+// validateClusterConfiguration validates the cluster settings
+func validateClusterConfiguration(cfg *Config) error {
+	if cfg.ClusterNodeID == "" {
+		return domain.NewValidationError("cluster node id is required when clustering is enabled", nil)
+	}
+
+	peers, err := ParseClusterPeers(cfg.ClusterPeers)
+	if err != nil {
+		return domain.NewValidationError("invalid cluster peers", err).
+			WithContext("cluster_peers", cfg.ClusterPeers)
+	}
+
+	found := false
+	for _, peer := range peers {
+		found = found || peer.ID == cfg.ClusterNodeID
+	}
+	if !found {
+		return domain.NewValidationError("cluster peers must include this node", nil).
+			WithContext("cluster_node_id", cfg.ClusterNodeID)
+	}
+
+	if cfg.ClusterElectionTimeout <= 0 {
+		return domain.NewValidationError("cluster election timeout must be positive", nil).
+			WithContext("cluster_election_timeout", cfg.ClusterElectionTimeout)
+	}
+
+	// Only the default building's fleet is replicated, so a node must not
+	// run other buildings outside the cluster log
+	if len(cfg.Buildings) > 0 {
+		return domain.NewValidationError("buildings cannot be configured when clustering is enabled", nil).
+			WithContext("buildings", strings.Join(cfg.BuildingIDs(), ","))
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseClusterPeers(t *testing.T) {
+	peers, err := ParseClusterPeers(" node1=10.0.0.1:7000@http://10.0.0.1:6660/, node2=10.0.0.2:7000@https://node2.example.com ")
+	require.NoError(t, err)
+	assert.Equal(t, []ClusterPeer{
+		{ID: "node1", RaftAddr: "10.0.0.1:7000", APIURL: "http://10.0.0.1:6660"},
+		{ID: "node2", RaftAddr: "10.0.0.2:7000", APIURL: "https://node2.example.com"},
+	}, peers)
+
+	invalid := map[string]string{
+		"missing addresses": "node1",
+		"missing api url":   "node1=10.0.0.1:7000",
+		"missing id":        "=10.0.0.1:7000@http://10.0.0.1:6660",
+		"relative api url":  "node1=10.0.0.1:7000@10.0.0.1:6660",
+		"duplicate id":      "node1=a:1@http://a,node1=b:1@http://b",
+	}
+	for name, spec := range invalid {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseClusterPeers(spec)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestConfig_ClusterValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		contains string
+	}{
+		{"missing node id", map[string]string{"CLUSTER_PEERS": "node1=a:1@http://a"}, "cluster node id is required"},
+		{"invalid peers", map[string]string{"CLUSTER_NODE_ID": "node1", "CLUSTER_PEERS": "node1"}, "invalid cluster peers"},
+		{"node not a peer", map[string]string{"CLUSTER_NODE_ID": "node3", "CLUSTER_PEERS": "node1=a:1@http://a"}, "must include this node"},
+		{"invalid election timeout", map[string]string{"CLUSTER_NODE_ID": "node1", "CLUSTER_PEERS": "node1=a:1@http://a", "CLUSTER_ELECTION_TIMEOUT": "0s"}, "election timeout must be positive"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cleanup := clearEnvVars()
+			defer cleanup()
+
+			t.Setenv("CLUSTER_ENABLED", "true")
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			_, err := InitConfig()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.contains)
+		})
+	}
+
+	t.Run("buildings", func(t *testing.T) {
+		cleanup := clearEnvVars()
+		defer cleanup()
+
+		path := filepath.Join(t.TempDir(), "elevator.yaml")
+		writeEnvFile(t, path, `buildings:
+  tower:
+    default_max_floor: 40
+`)
+		t.Setenv(ConfigFileVar, path)
+		t.Setenv("CLUSTER_ENABLED", "true")
+		t.Setenv("CLUSTER_NODE_ID", "node1")
+		t.Setenv("CLUSTER_PEERS", "node1=a:7000@http://a:6660")
+
+		_, err := InitConfig()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "buildings cannot be configured")
+	})
+
+	t.Run("valid cluster", func(t *testing.T) {
+		cleanup := clearEnvVars()
+		defer cleanup()
+
+		t.Setenv("CLUSTER_ENABLED", "true")
+		t.Setenv("CLUSTER_NODE_ID", "node2")
+		t.Setenv("CLUSTER_PEERS", "node1=a:7000@http://a:6660,node2=b:7000@http://b:6660")
+
+		cfg, err := InitConfig()
+		require.NoError(t, err)
+		assert.True(t, cfg.ClusterEnabled)
+		assert.Equal(t, "node2", cfg.ClusterNodeID)
+	})
+}
@@ -2,9 +2,10 @@ package config
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
-	"github.com/caarlos0/env"
+	"github.com/slavakukuyev/elevator-go/internal/circuitbreaker"
 	"github.com/slavakukuyev/elevator-go/internal/constants"
 	"github.com/slavakukuyev/elevator-go/internal/domain"
 )
@@ -39,6 +40,10 @@ type Config struct {
 	NamePrefix               string        `env:"ELEVATOR_NAME_PREFIX" envDefault:"Elevator"`
 	SwitchOnChannelBuffer    int           `env:"SWITCH_ON_CHANNEL_BUFFER" envDefault:"10"`
 
+	// Building configuration
+	BuildingID                    string `env:"BUILDING_ID" envDefault:"main"`                    // ID of the building served by the un-namespaced API
+	BuildingMaxConcurrentRequests int    `env:"BUILDING_MAX_CONCURRENT_REQUESTS" envDefault:"50"` // Requests one building serves at once
+
 	// HTTP Configuration
 	RateLimitRPM       int           `env:"RATE_LIMIT_RPM" envDefault:"100"`
 	RateLimitWindow    time.Duration `env:"RATE_LIMIT_WINDOW" envDefault:"1m"`
@@ -48,6 +53,8 @@ type Config struct {
 	CORSEnabled        bool          `env:"CORS_ENABLED" envDefault:"true"`
 	CORSMaxAge         time.Duration `env:"CORS_MAX_AGE" envDefault:"12h"`
 	CORSAllowedOrigins string        `env:"CORS_ALLOWED_ORIGINS" envDefault:"*"`
+	StaffAPIToken      string        `env:"STAFF_API_TOKEN"` // Authenticates staff calls for service cars; empty disables them
+	AdminAPIToken      string        `env:"ADMIN_API_TOKEN"` // Authenticates admin endpoints (config reload, webhooks); empty disables them
 
 	// Monitoring
 	MetricsEnabled       bool          `env:"METRICS_ENABLED" envDefault:"true"`
@@ -58,6 +65,7 @@ type Config struct {
 	StructuredLogging    bool          `env:"STRUCTURED_LOGGING" envDefault:"true"`
 	LogRequestDetails    bool          `env:"LOG_REQUEST_DETAILS" envDefault:"false"`
 	CorrelationIDHeader  string        `env:"CORRELATION_ID_HEADER" envDefault:"X-Request-ID"`
+	EventHistorySize     int           `env:"EVENT_HISTORY_SIZE" envDefault:"1000"`
 
 	// Circuit Breaker
 	CircuitBreakerEnabled          bool          `env:"CIRCUIT_BREAKER_ENABLED" envDefault:"true"`
@@ -65,6 +73,8 @@ type Config struct {
 	CircuitBreakerResetTimeout     time.Duration `env:"CIRCUIT_BREAKER_RESET_TIMEOUT" envDefault:"30s"`
 	CircuitBreakerHalfOpenLimit    int           `env:"CIRCUIT_BREAKER_HALF_OPEN_LIMIT" envDefault:"3"`
 	CircuitBreakerFailureThreshold float64       `env:"CIRCUIT_BREAKER_FAILURE_THRESHOLD" envDefault:"0.6"`
+	CircuitBreakerWindow           time.Duration `env:"CIRCUIT_BREAKER_WINDOW" envDefault:"1m"`
+	CircuitBreakerMinRequests      int           `env:"CIRCUIT_BREAKER_MIN_REQUESTS" envDefault:"10"`
 
 	// WebSocket
 	WebSocketEnabled           bool          `env:"WEBSOCKET_ENABLED" envDefault:"true"`
@@ -75,6 +85,73 @@ type Config struct {
 	WebSocketPingInterval      time.Duration `env:"WEBSOCKET_PING_INTERVAL" envDefault:"30s"`
 	WebSocketMaxConnections    int           `env:"WEBSOCKET_MAX_CONNECTIONS" envDefault:"1000"`
 	WebSocketBufferSize        int           `env:"WEBSOCKET_BUFFER_SIZE" envDefault:"1024"`
+
+	// MQTT Bridge
+	MQTTEnabled        bool          `env:"MQTT_ENABLED" envDefault:"false"`
+	MQTTBrokerURL      string        `env:"MQTT_BROKER_URL" envDefault:"tcp://localhost:1883"`
+	MQTTClientID       string        `env:"MQTT_CLIENT_ID" envDefault:"elevator-bridge"`
+	MQTTUsername       string        `env:"MQTT_USERNAME"`
+	MQTTPassword       string        `env:"MQTT_PASSWORD"`
+	MQTTTopicPrefix    string        `env:"MQTT_TOPIC_PREFIX" envDefault:"building"`
+	MQTTBuildingID     string        `env:"MQTT_BUILDING_ID" envDefault:"main"`
+	MQTTQoS            int           `env:"MQTT_QOS" envDefault:"1"`
+	MQTTConnectTimeout time.Duration `env:"MQTT_CONNECT_TIMEOUT" envDefault:"10s"`
+
+	// Fault Injection
+	FaultInjectionEnabled bool   `env:"FAULT_INJECTION_ENABLED" envDefault:"false"`
+	FaultInjections       string `env:"FAULT_INJECTIONS"`
+
+	// Cluster configuration
+	ClusterEnabled         bool          `env:"CLUSTER_ENABLED" envDefault:"false"`
+	ClusterNodeID          string        `env:"CLUSTER_NODE_ID"`
+	ClusterPeers           string        `env:"CLUSTER_PEERS"` // id=raft_addr@api_url entries separated by commas, including this node
+	ClusterToken           string        `env:"CLUSTER_TOKEN"` // Authenticates calls forwarded between nodes; empty disables the check
+	ClusterElectionTimeout time.Duration `env:"CLUSTER_ELECTION_TIMEOUT" envDefault:"1s"`
+
+	// Motion model
+	MotionModel        string  `env:"MOTION_MODEL" envDefault:"fixed"`      // fixed or kinematic
+	MotionMaxSpeed     float64 `env:"MOTION_MAX_SPEED" envDefault:"2.5"`    // m/s
+	MotionAcceleration float64 `env:"MOTION_ACCELERATION" envDefault:"1"`   // m/s²
+	MotionJerk         float64 `env:"MOTION_JERK" envDefault:"1.5"`         // m/s³
+	MotionFloorHeight  float64 `env:"MOTION_FLOOR_HEIGHT" envDefault:"3.5"` // m
+
+	// Elevator drive
+	DriveBackend string        `env:"DRIVE_BACKEND" envDefault:"simulated"` // simulated or line
+	DriveAddress string        `env:"DRIVE_ADDRESS"`                        // tcp://host:port or serial:///dev/ttyUSB0 for the line backend
+	DriveTimeout time.Duration `env:"DRIVE_TIMEOUT" envDefault:"30s"`       // Longest wait for a reply to a drive command
+
+	// Webhooks
+	WebhookEnabled         bool          `env:"WEBHOOK_ENABLED" envDefault:"false"`
+	WebhookMaxAttempts     int           `env:"WEBHOOK_MAX_ATTEMPTS" envDefault:"5"`        // Attempts before a delivery is dead-lettered
+	WebhookRetryBackoff    time.Duration `env:"WEBHOOK_RETRY_BACKOFF" envDefault:"1s"`      // Wait before the first retry, doubled for every further one
+	WebhookMaxRetryBackoff time.Duration `env:"WEBHOOK_MAX_RETRY_BACKOFF" envDefault:"5m"`  // Longest wait between retries
+	WebhookTimeout         time.Duration `env:"WEBHOOK_TIMEOUT" envDefault:"5s"`            // Longest wait for a receiver to answer
+	WebhookDeliveryLogSize int           `env:"WEBHOOK_DELIVERY_LOG_SIZE" envDefault:"100"` // Deliveries kept per subscription
+	WebhookAllowedNetworks string        `env:"WEBHOOK_ALLOWED_NETWORKS"`                   // CIDRs separated by commas where loopback, link-local and private receivers are allowed
+
+	// Service-level objectives
+	SLARules              string        `env:"SLA_RULES"`                                // name:indicator:objective[:window[:severity]] entries separated by semicolons
+	SLAEvaluationInterval time.Duration `env:"SLA_EVALUATION_INTERVAL" envDefault:"15s"` // How often the rules are evaluated
+
+	// Analytics store
+	AnalyticsEnabled   bool          `env:"ANALYTICS_ENABLED" envDefault:"false"`
+	AnalyticsDir       string        `env:"ANALYTICS_DIR" envDefault:"data/analytics"` // One file of records per UTC day
+	AnalyticsRetention time.Duration `env:"ANALYTICS_RETENTION" envDefault:"720h"`     // How long records are kept
+
+	// Predictive pre-positioning of idle cars
+	PrepositionEnabled   bool          `env:"PREPOSITION_ENABLED" envDefault:"false"`
+	PrepositionInterval  time.Duration `env:"PREPOSITION_INTERVAL" envDefault:"30s"`  // How often idle cars are placed
+	PrepositionLookahead time.Duration `env:"PREPOSITION_LOOKAHEAD" envDefault:"15m"` // How far ahead demand is forecast
+	PrepositionMinCalls  float64       `env:"PREPOSITION_MIN_CALLS" envDefault:"1"`   // Forecast calls a floor needs to attract an idle car
+	ForecastSlot         time.Duration `env:"FORECAST_SLOT" envDefault:"15m"`         // Length of the time-of-week buckets calls are counted in
+	ForecastSmoothing    float64       `env:"FORECAST_SMOOTHING" envDefault:"0.3"`    // Weight of the latest week in the rate of every bucket
+
+	// Per-elevator overrides, only available in configuration files
+	Elevators map[string]ElevatorOverride
+
+	// Per-building setting overrides keyed by building ID, only available in
+	// configuration files
+	Buildings map[string]map[string]string
 }
 
 // ServerConfig contains HTTP server specific configuration
@@ -157,6 +234,8 @@ type CircuitBreakerConfig struct {
 	ResetTimeout     time.Duration `env:"CIRCUIT_BREAKER_RESET_TIMEOUT" envDefault:"30s"`
 	HalfOpenLimit    int           `env:"CIRCUIT_BREAKER_HALF_OPEN_LIMIT" envDefault:"3"`
 	FailureThreshold float64       `env:"CIRCUIT_BREAKER_FAILURE_THRESHOLD" envDefault:"0.6"`
+	Window           time.Duration `env:"CIRCUIT_BREAKER_WINDOW" envDefault:"1m"`
+	MinRequests      int           `env:"CIRCUIT_BREAKER_MIN_REQUESTS" envDefault:"10"`
 }
 
 // WebSocketConfig contains WebSocket specific configuration
@@ -171,24 +250,22 @@ type WebSocketConfig struct {
 	BufferSize        int           `env:"WEBSOCKET_BUFFER_SIZE" envDefault:"1024"`
 }
 
-// InitConfig initializes the configuration from environment variables with comprehensive validation
+// InitConfig initializes the configuration with comprehensive validation.
+// Values come from the built-in defaults, the YAML or TOML file named by
+// --config or CONFIG_FILE, environment variables (including CONFIG_ENV_FILE)
+// and command-line flags, each layer overriding the previous one.
 func InitConfig() (*Config, error) {
-	cfg := Config{}
-	if err := env.Parse(&cfg); err != nil {
-		return nil, fmt.Errorf("failed to parse environment variables: %w", err)
-	}
-
-	// Apply environment-specific defaults
-	if err := applyEnvironmentDefaults(&cfg); err != nil {
-		return nil, fmt.Errorf("failed to apply environment defaults: %w", err)
+	cfg, _, err := load()
+	if err != nil {
+		return nil, err
 	}
 
 	// Comprehensive validation
-	if err := validateConfiguration(&cfg); err != nil {
+	if err := validateConfiguration(cfg); err != nil {
 		return nil, fmt.Errorf("configuration validation failed: %w", err)
 	}
 
-	return &cfg, nil
+	return cfg, nil
 }
 
 // applyEnvironmentDefaults applies environment-specific default values
@@ -350,6 +427,77 @@ func validateConfiguration(cfg *Config) error {
 			WithContext("default_overload_threshold", cfg.DefaultOverloadThreshold)
 	}
 
+	if cfg.EventHistorySize <= 0 || cfg.EventHistorySize > 100000 {
+		return domain.NewValidationError("event history size must be between 1 and 100000", nil).
+			WithContext("event_history_size", cfg.EventHistorySize)
+	}
+
+	if cfg.MQTTEnabled {
+		if err := validateMQTTConfiguration(cfg); err != nil {
+			return err
+		}
+	}
+
+	if err := validateCircuitBreakerConfiguration(CircuitBreakerConfig{
+		Enabled:          cfg.CircuitBreakerEnabled,
+		MaxFailures:      cfg.CircuitBreakerMaxFailures,
+		ResetTimeout:     cfg.CircuitBreakerResetTimeout,
+		HalfOpenLimit:    cfg.CircuitBreakerHalfOpenLimit,
+		FailureThreshold: cfg.CircuitBreakerFailureThreshold,
+		Window:           cfg.CircuitBreakerWindow,
+		MinRequests:      cfg.CircuitBreakerMinRequests,
+	}); err != nil {
+		return err
+	}
+
+	if err := validateFaultInjectionConfiguration(cfg); err != nil {
+		return err
+	}
+
+	if cfg.ClusterEnabled {
+		if err := validateClusterConfiguration(cfg); err != nil {
+			return err
+		}
+	}
+
+	if err := validateMotionConfiguration(cfg); err != nil {
+		return err
+	}
+
+	if err := validateDriveConfiguration(cfg); err != nil {
+		return err
+	}
+
+	if cfg.WebhookEnabled {
+		if err := validateWebhookConfiguration(cfg); err != nil {
+			return err
+		}
+	}
+
+	if err := validateSLAConfiguration(cfg); err != nil {
+		return err
+	}
+
+	if cfg.AnalyticsEnabled {
+		if err := validateAnalyticsConfiguration(cfg); err != nil {
+			return err
+		}
+	}
+
+	if cfg.PrepositionEnabled {
+		if err := validatePrepositionConfiguration(cfg); err != nil {
+			return err
+		}
+	}
+
+	if err := validateElevatorOverrides(cfg.Elevators); err != nil {
+		return err
+	}
+
+	if err := validateBuildings(cfg); err != nil {
+		return err
+	}
+
 	// Environment-specific validations
 	if err := validateEnvironmentSpecificConfig(cfg); err != nil {
 		return err
@@ -373,6 +521,11 @@ func validateEnvironmentSpecificConfig(cfg *Config) error {
 				WithContext("environment", cfg.Environment)
 		}
 
+		if cfg.FaultInjectionEnabled {
+			return domain.NewValidationError("fault injection not allowed in production", nil).
+				WithContext("environment", cfg.Environment)
+		}
+
 		if cfg.RateLimitRPM > 100 {
 			return domain.NewValidationError("rate limit too high for production", nil).
 				WithContext("environment", cfg.Environment).
@@ -527,6 +680,16 @@ func validateCircuitBreakerConfiguration(config CircuitBreakerConfig) error {
 			WithContext("threshold", config.FailureThreshold)
 	}
 
+	if config.Window < 0 {
+		return domain.NewValidationError("failure rate window cannot be negative", nil).
+			WithContext("window", config.Window)
+	}
+
+	if config.MinRequests < 0 {
+		return domain.NewValidationError("min requests cannot be negative", nil).
+			WithContext("min_requests", config.MinRequests)
+	}
+
 	return nil
 }
 
@@ -550,6 +713,123 @@ func validateWebSocketConfiguration(config WebSocketConfig) error {
 	return nil
 }
 
+// validateMQTTConfiguration validates MQTT bridge configuration
+func validateMQTTConfiguration(cfg *Config) error {
+	if cfg.MQTTBrokerURL == "" {
+		return domain.NewValidationError("mqtt broker url is required when mqtt is enabled", nil)
+	}
+
+	if cfg.MQTTQoS < 0 || cfg.MQTTQoS > 2 {
+		return domain.NewValidationError("mqtt qos must be 0, 1 or 2", nil).
+			WithContext("qos", cfg.MQTTQoS)
+	}
+
+	if !isValidTopicSegment(cfg.MQTTTopicPrefix) {
+		return domain.NewValidationError("mqtt topic prefix must be non-empty and free of wildcards", nil).
+			WithContext("topic_prefix", cfg.MQTTTopicPrefix)
+	}
+
+	if !isValidTopicSegment(cfg.MQTTBuildingID) || strings.Contains(cfg.MQTTBuildingID, "/") {
+		return domain.NewValidationError("mqtt building id must be a single topic level without wildcards", nil).
+			WithContext("building_id", cfg.MQTTBuildingID)
+	}
+
+	if cfg.MQTTConnectTimeout <= 0 {
+		return domain.NewValidationError("mqtt connect timeout must be positive", nil).
+			WithContext("timeout", cfg.MQTTConnectTimeout)
+	}
+
+	return nil
+}
+
+// validateFaultInjectionConfiguration validates faults injected at startup
+func validateFaultInjectionConfiguration(cfg *Config) error {
+	if cfg.FaultInjections == "" {
+		return nil
+	}
+
+	if !cfg.FaultInjectionEnabled {
+		return domain.NewValidationError("fault injections require fault injection to be enabled", nil).
+			WithContext("fault_injections", cfg.FaultInjections)
+	}
+
+	_, err := domain.ParseFaultInjections(cfg.FaultInjections)
+	return err
+}
+
+// isValidTopicSegment reports whether s can be embedded in an MQTT topic name
+func isValidTopicSegment(s string) bool {
+	return s != "" && !strings.ContainsAny(s, "+#")
+}
+
+// validateElevatorOverrides validates the per-elevator overrides
+func validateElevatorOverrides(overrides map[string]ElevatorOverride) error {
+	for name, override := range overrides {
+		if strings.TrimSpace(name) == "" {
+			return domain.NewValidationError("elevator override name cannot be empty", nil)
+		}
+
+		if override.EachFloorDuration < 0 || override.OpenDoorDuration < 0 {
+			return domain.NewValidationError("elevator override durations must be positive", nil).
+				WithContext("elevator", name)
+		}
+
+		if override.OverloadThreshold < 0 || override.OverloadThreshold > 100 {
+			return domain.NewValidationError("elevator override overload threshold must be between 1 and 100", nil).
+				WithContext("elevator", name).
+				WithContext("overload_threshold", override.OverloadThreshold)
+		}
+	}
+
+	return nil
+}
+
+// ElevatorSettings are the timing and capacity settings of one elevator
+type ElevatorSettings struct {
+	EachFloorDuration time.Duration
+	OpenDoorDuration  time.Duration
+	OverloadThreshold int
+}
+
+// ElevatorSettings returns the settings for the named elevator: the global
+// defaults with its overrides from the configuration file applied
+func (c *Config) ElevatorSettings(name string) ElevatorSettings {
+	settings := ElevatorSettings{
+		EachFloorDuration: c.EachFloorDuration,
+		OpenDoorDuration:  c.OpenDoorDuration,
+		OverloadThreshold: c.DefaultOverloadThreshold,
+	}
+
+	return c.Elevators[name].Apply(settings)
+}
+
+// Apply returns settings with the overridden values replaced
+func (o ElevatorOverride) Apply(settings ElevatorSettings) ElevatorSettings {
+	if o.EachFloorDuration > 0 {
+		settings.EachFloorDuration = o.EachFloorDuration
+	}
+	if o.OpenDoorDuration > 0 {
+		settings.OpenDoorDuration = o.OpenDoorDuration
+	}
+	if o.OverloadThreshold > 0 {
+		settings.OverloadThreshold = o.OverloadThreshold
+	}
+	return settings
+}
+
+// CircuitBreakerSettings returns the settings for the circuit breakers that
+// guard elevator operations and dispatch
+func (c *Config) CircuitBreakerSettings() circuitbreaker.Settings {
+	return circuitbreaker.Settings{
+		MaxFailures:      c.CircuitBreakerMaxFailures,
+		ResetTimeout:     c.CircuitBreakerResetTimeout,
+		HalfOpenLimit:    c.CircuitBreakerHalfOpenLimit,
+		FailureThreshold: c.CircuitBreakerFailureThreshold,
+		Window:           c.CircuitBreakerWindow,
+		MinRequests:      c.CircuitBreakerMinRequests,
+	}
+}
+
 // IsProduction returns true if running in production environment
 func (c *Config) IsProduction() bool {
 	return c.Environment == "production" || c.Environment == "prod"
@@ -53,7 +53,7 @@ func TestInitConfig_EnvironmentVariables(t *testing.T) {
 		"OPEN_DOOR_DURATION":      "3s",
 		"MAX_ELEVATORS":           "50",
 		"ELEVATOR_NAME_PREFIX":    "Lift",
-		"RATE_LIMIT_RPM":          "200",
+		"RATE_LIMIT_RPM":          "60",
 		"WEBSOCKET_ENABLED":       "false",
 		"CIRCUIT_BREAKER_ENABLED": "false",
 	}
@@ -70,15 +70,16 @@ func TestInitConfig_EnvironmentVariables(t *testing.T) {
 
 	// Verify environment variables are parsed correctly
 	assert.Equal(t, "production", cfg.Environment)
-	assert.Equal(t, "WARN", cfg.LogLevel) // Should be overridden to WARN in production defaults
+	assert.Equal(t, "ERROR", cfg.LogLevel) // Explicit values win over production defaults
 	assert.Equal(t, 8080, cfg.Port)
 	assert.Equal(t, 20, cfg.MaxFloor)
 	assert.Equal(t, -5, cfg.MinFloor)
-	assert.Equal(t, 200*time.Millisecond, cfg.EachFloorDuration) // Overridden by production defaults
-	assert.Equal(t, 1*time.Second, cfg.OpenDoorDuration)         // Overridden by production defaults
-	assert.Equal(t, 200, cfg.MaxElevators)                       // Overridden by production defaults
+	assert.Equal(t, 1*time.Second, cfg.EachFloorDuration)
+	assert.Equal(t, 3*time.Second, cfg.OpenDoorDuration)
+	assert.Equal(t, 50, cfg.MaxElevators)
 	assert.Equal(t, "Lift", cfg.NamePrefix)
-	assert.Equal(t, 30, cfg.RateLimitRPM) // Should be overridden to 30 in production defaults
+	assert.Equal(t, 60, cfg.RateLimitRPM)
+	assert.Equal(t, 15*time.Second, cfg.ReadTimeout) // Unset, so taken from production defaults
 	assert.False(t, cfg.WebSocketEnabled)
 	assert.False(t, cfg.CircuitBreakerEnabled)
 }
@@ -366,6 +367,122 @@ func TestConfigValidation_InvalidMaxElevators(t *testing.T) {
 	}
 }
 
+func TestConfigValidation_MQTTConfiguration(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr string
+	}{
+		{
+			name: "disabled bridge skips validation",
+			env:  map[string]string{"MQTT_ENABLED": "false", "MQTT_QOS": "7"},
+		},
+		{
+			name: "valid enabled bridge",
+			env:  map[string]string{"MQTT_ENABLED": "true", "MQTT_BUILDING_ID": "tower-a", "MQTT_QOS": "2"},
+		},
+		{
+			name:    "invalid qos",
+			env:     map[string]string{"MQTT_ENABLED": "true", "MQTT_QOS": "3"},
+			wantErr: "mqtt qos must be 0, 1 or 2",
+		},
+		{
+			name:    "wildcard in topic prefix",
+			env:     map[string]string{"MQTT_ENABLED": "true", "MQTT_TOPIC_PREFIX": "building/#"},
+			wantErr: "mqtt topic prefix must be non-empty and free of wildcards",
+		},
+		{
+			name:    "multi-level building id",
+			env:     map[string]string{"MQTT_ENABLED": "true", "MQTT_BUILDING_ID": "campus/tower"},
+			wantErr: "mqtt building id must be a single topic level without wildcards",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cleanupEnv := clearEnvVars()
+			defer cleanupEnv()
+
+			for key, value := range tt.env {
+				if err := os.Setenv(key, value); err != nil {
+					t.Fatalf("Failed to set environment variable %s: %v", key, err)
+				}
+			}
+
+			cfg, err := InitConfig()
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				require.NotNil(t, cfg)
+				return
+			}
+
+			require.Error(t, err)
+			assert.Nil(t, cfg)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestConfigValidation_FaultInjection(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr string
+	}{
+		{
+			name: "faults configured for elevators",
+			env: map[string]string{
+				"FAULT_INJECTION_ENABLED": "true",
+				"FAULT_INJECTIONS":        "A=stuck_between_floors;B=slow_travel:2.5,door_failure",
+			},
+		},
+		{
+			name:    "faults without enabling injection",
+			env:     map[string]string{"FAULT_INJECTIONS": "A=door_failure"},
+			wantErr: "fault injections require fault injection to be enabled",
+		},
+		{
+			name:    "unknown fault type",
+			env:     map[string]string{"FAULT_INJECTION_ENABLED": "true", "FAULT_INJECTIONS": "A=meteor_strike"},
+			wantErr: "unknown fault type",
+		},
+		{
+			name:    "slowdown factor on door failure",
+			env:     map[string]string{"FAULT_INJECTION_ENABLED": "true", "FAULT_INJECTIONS": "A=door_failure:2"},
+			wantErr: "slowdown factor only applies to slow travel faults",
+		},
+		{
+			name:    "production",
+			env:     map[string]string{"ENV": "production", "CORS_ALLOWED_ORIGINS": "https://example.com", "FAULT_INJECTION_ENABLED": "true"},
+			wantErr: "fault injection not allowed in production",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cleanupEnv := clearEnvVars()
+			defer cleanupEnv()
+
+			for key, value := range tt.env {
+				if err := os.Setenv(key, value); err != nil {
+					t.Fatalf("Failed to set environment variable %s: %v", key, err)
+				}
+			}
+
+			cfg, err := InitConfig()
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				require.NotNil(t, cfg)
+				return
+			}
+
+			require.Error(t, err)
+			assert.Nil(t, cfg)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
 func TestValidateElevatorConfiguration(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -591,6 +708,31 @@ func TestValidateCircuitBreakerConfiguration(t *testing.T) {
 			wantErr: true,
 			errMsg:  "max failures must be between 1 and 100",
 		},
+		{
+			name: "negative failure rate window",
+			config: CircuitBreakerConfig{
+				MaxFailures:      5,
+				ResetTimeout:     30 * time.Second,
+				HalfOpenLimit:    3,
+				FailureThreshold: 0.6,
+				Window:           -time.Second,
+			},
+			wantErr: true,
+			errMsg:  "failure rate window cannot be negative",
+		},
+		{
+			name: "negative min requests",
+			config: CircuitBreakerConfig{
+				MaxFailures:      5,
+				ResetTimeout:     30 * time.Second,
+				HalfOpenLimit:    3,
+				FailureThreshold: 0.6,
+				Window:           time.Minute,
+				MinRequests:      -1,
+			},
+			wantErr: true,
+			errMsg:  "min requests cannot be negative",
+		},
 	}
 
 	for _, tt := range tests {
@@ -836,12 +978,25 @@ func clearEnvVars() func() {
 		"CORS_ENABLED", "CORS_MAX_AGE", "CORS_ALLOWED_ORIGINS", "METRICS_ENABLED",
 		"METRICS_PATH", "STATUS_UPDATE_INTERVAL", "HEALTH_ENABLED", "HEALTH_PATH",
 		"STRUCTURED_LOGGING", "LOG_REQUEST_DETAILS", "CORRELATION_ID_HEADER",
+		"EVENT_HISTORY_SIZE",
 		"CIRCUIT_BREAKER_ENABLED", "CIRCUIT_BREAKER_MAX_FAILURES",
 		"CIRCUIT_BREAKER_RESET_TIMEOUT", "CIRCUIT_BREAKER_HALF_OPEN_LIMIT",
-		"CIRCUIT_BREAKER_FAILURE_THRESHOLD", "WEBSOCKET_ENABLED", "WEBSOCKET_PATH",
+		"CIRCUIT_BREAKER_FAILURE_THRESHOLD", "CIRCUIT_BREAKER_WINDOW",
+		"CIRCUIT_BREAKER_MIN_REQUESTS", "WEBSOCKET_ENABLED", "WEBSOCKET_PATH",
 		"WEBSOCKET_CONNECTION_TIMEOUT", "WEBSOCKET_WRITE_TIMEOUT",
 		"WEBSOCKET_READ_TIMEOUT", "WEBSOCKET_PING_INTERVAL",
 		"WEBSOCKET_MAX_CONNECTIONS", "WEBSOCKET_BUFFER_SIZE",
+		"MQTT_ENABLED", "MQTT_BROKER_URL", "MQTT_CLIENT_ID", "MQTT_USERNAME",
+		"MQTT_PASSWORD", "MQTT_TOPIC_PREFIX", "MQTT_BUILDING_ID", "MQTT_QOS",
+		"MQTT_CONNECT_TIMEOUT", "FAULT_INJECTION_ENABLED", "FAULT_INJECTIONS",
+		"STAFF_API_TOKEN", "ADMIN_API_TOKEN", "BUILDING_ID", "BUILDING_MAX_CONCURRENT_REQUESTS",
+		"CLUSTER_ENABLED", "CLUSTER_NODE_ID", "CLUSTER_PEERS", "CLUSTER_TOKEN",
+		"CLUSTER_ELECTION_TIMEOUT", "DRIVE_BACKEND", "DRIVE_ADDRESS", "DRIVE_TIMEOUT",
+		"MOTION_MODEL", "MOTION_MAX_SPEED", "MOTION_ACCELERATION", "MOTION_JERK", "MOTION_FLOOR_HEIGHT",
+		"WEBHOOK_ENABLED", "WEBHOOK_MAX_ATTEMPTS", "WEBHOOK_RETRY_BACKOFF", "WEBHOOK_MAX_RETRY_BACKOFF", "WEBHOOK_TIMEOUT",
+		"WEBHOOK_DELIVERY_LOG_SIZE", "WEBHOOK_ALLOWED_NETWORKS", "SLA_RULES", "SLA_EVALUATION_INTERVAL",
+		"ANALYTICS_ENABLED", "ANALYTICS_DIR", "ANALYTICS_RETENTION", "PREPOSITION_ENABLED", "PREPOSITION_INTERVAL",
+		"PREPOSITION_LOOKAHEAD", "PREPOSITION_MIN_CALLS", "FORECAST_SLOT", "FORECAST_SMOOTHING", EnvFileVar, ConfigFileVar,
 	}
 
 	// Store original values
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"net/url"
+
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+)
+
+// Drive backends
+const (
+	// DriveBackendSimulated moves cars in the built-in simulator
+	DriveBackendSimulated = "simulated"
+	// DriveBackendLine drives cars through a PLC or emulator speaking the
+	// line protocol over TCP or a serial port
+	DriveBackendLine = "line"
+)
+
+// validateDriveConfiguration validates the drive settings
+func validateDriveConfiguration(cfg *Config) error {
+	switch cfg.DriveBackend {
+	case DriveBackendSimulated:
+		return nil
+	case DriveBackendLine:
+	default:
+		return domain.NewValidationError("drive backend must be simulated or line", nil).
+			WithContext("drive_backend", cfg.DriveBackend)
+	}
+
+	address, err := url.Parse(cfg.DriveAddress)
+	if err != nil || !isValidDriveAddress(address) {
+		return domain.NewValidationError("drive address must be tcp://host:port or serial:///path/to/device", err).
+			WithContext("drive_address", cfg.DriveAddress)
+	}
+
+	if cfg.DriveTimeout <= 0 {
+		return domain.NewValidationError("drive timeout must be positive", nil).
+			WithContext("drive_timeout", cfg.DriveTimeout)
+	}
+
+	return nil
+}
+
+func isValidDriveAddress(address *url.URL) bool {
+	switch address.Scheme {
+	case "tcp":
+		return address.Host != "" && address.Port() != ""
+	case "serial":
+		return address.Host == "" && address.Path != ""
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_DriveDefaults(t *testing.T) {
+	cleanup := clearEnvVars()
+	defer cleanup()
+
+	cfg, err := InitConfig()
+	require.NoError(t, err)
+	assert.Equal(t, DriveBackendSimulated, cfg.DriveBackend)
+	assert.Equal(t, 30*time.Second, cfg.DriveTimeout)
+}
+
+func TestConfig_DriveValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		contains string
+	}{
+		{"unknown backend", map[string]string{"DRIVE_BACKEND": "plc"}, "drive backend must be simulated or line"},
+		{"missing address", map[string]string{"DRIVE_BACKEND": "line"}, "drive address must be"},
+		{"address without port", map[string]string{"DRIVE_BACKEND": "line", "DRIVE_ADDRESS": "tcp://plc.local"}, "drive address must be"},
+		{"unknown scheme", map[string]string{"DRIVE_BACKEND": "line", "DRIVE_ADDRESS": "udp://plc.local:502"}, "drive address must be"},
+		{"serial without device", map[string]string{"DRIVE_BACKEND": "line", "DRIVE_ADDRESS": "serial://"}, "drive address must be"},
+		{"invalid timeout", map[string]string{"DRIVE_BACKEND": "line", "DRIVE_ADDRESS": "tcp://plc.local:502", "DRIVE_TIMEOUT": "0s"}, "drive timeout must be positive"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cleanup := clearEnvVars()
+			defer cleanup()
+
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			_, err := InitConfig()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.contains)
+		})
+	}
+
+	for _, address := range []string{"tcp://plc.local:502", "serial:///dev/ttyUSB0"} {
+		cleanup := clearEnvVars()
+		t.Setenv("DRIVE_BACKEND", "line")
+		t.Setenv("DRIVE_ADDRESS", address)
+		_, err := InitConfig()
+		assert.NoError(t, err, address)
+		cleanup()
+	}
+}
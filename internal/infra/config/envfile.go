@@ -0,0 +1,102 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// EnvFileVar names the environment variable that points at an optional file
+// of KEY=VALUE lines, such as configs/production.env. The file is read every
+// time the configuration is loaded, so edits take effect on reload. Variables
+// set in the process environment take precedence over the file.
+const EnvFileVar = "CONFIG_ENV_FILE"
+
+// envFile remembers the values applied from the env file so a reload can
+// update and remove them without touching variables set by the process
+// environment
+var envFile struct {
+	sync.Mutex
+	applied map[string]string
+}
+
+// loadEnvFile applies the env file named by EnvFileVar to the process
+// environment
+func loadEnvFile() error {
+	envFile.Lock()
+	defer envFile.Unlock()
+
+	values := map[string]string{}
+	if path := os.Getenv(EnvFileVar); path != "" {
+		var err error
+		if values, err = parseEnvFile(path); err != nil {
+			return err
+		}
+	}
+
+	// Drop values from a previous load that the file no longer sets
+	for key, previous := range envFile.applied {
+		if _, ok := values[key]; ok {
+			continue
+		}
+		if current, set := os.LookupEnv(key); set && current == previous {
+			if err := os.Unsetenv(key); err != nil {
+				return fmt.Errorf("failed to unset %s: %w", key, err)
+			}
+		}
+	}
+
+	applied := make(map[string]string, len(values))
+	for key, value := range values {
+		current, set := os.LookupEnv(key)
+		if previous, ours := envFile.applied[key]; set && (!ours || current != previous) {
+			// Set by the process environment, which wins over the file
+			continue
+		}
+		if err := os.Setenv(key, value); err != nil {
+			return fmt.Errorf("failed to set %s: %w", key, err)
+		}
+		applied[key] = value
+	}
+	envFile.applied = applied
+
+	return nil
+}
+
+// parseEnvFile reads KEY=VALUE lines, ignoring blank lines, comments and an
+// optional "export " prefix
+func parseEnvFile(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open env file: %w", err)
+	}
+	defer file.Close()
+
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid line %d in env file %s: expected KEY=VALUE", lineNumber, path)
+		}
+
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		values[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read env file: %w", err)
+	}
+
+	return values, nil
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeEnvFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
+
+func TestParseEnvFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.env")
+	writeEnvFile(t, path, `# comment
+
+LOG_LEVEL=WARN
+export RATE_LIMIT_RPM = 50
+CORS_ALLOWED_ORIGINS="https://a.example.com,https://b.example.com"
+MQTT_PASSWORD='p=ss'
+`)
+
+	values, err := parseEnvFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"LOG_LEVEL":            "WARN",
+		"RATE_LIMIT_RPM":       "50",
+		"CORS_ALLOWED_ORIGINS": "https://a.example.com,https://b.example.com",
+		"MQTT_PASSWORD":        "p=ss",
+	}, values)
+
+	writeEnvFile(t, path, "LOG_LEVEL=WARN\nnot a setting\n")
+	_, err = parseEnvFile(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid line 2")
+}
+
+func TestInitConfig_EnvFile(t *testing.T) {
+	cleanupEnv := clearEnvVars()
+	defer cleanupEnv()
+
+	path := filepath.Join(t.TempDir(), "app.env")
+	writeEnvFile(t, path, "LOG_LEVEL=ERROR\nRATE_LIMIT_RPM=40\nSTATUS_UPDATE_INTERVAL=3s\n")
+	require.NoError(t, os.Setenv(EnvFileVar, path))
+	require.NoError(t, os.Setenv("RATE_LIMIT_RPM", "70"))
+
+	cfg, err := InitConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "ERROR", cfg.LogLevel)
+	assert.Equal(t, 70, cfg.RateLimitRPM, "process environment wins over the file")
+	assert.Equal(t, "3s", cfg.StatusUpdateInterval.String())
+
+	// Edits to the file are picked up on the next load and removed keys fall
+	// back to their defaults
+	writeEnvFile(t, path, "LOG_LEVEL=WARN\nRATE_LIMIT_RPM=40\n")
+	cfg, err = InitConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "WARN", cfg.LogLevel)
+	assert.Equal(t, 70, cfg.RateLimitRPM)
+	assert.Equal(t, "1s", cfg.StatusUpdateInterval.String())
+
+	require.NoError(t, os.Setenv(EnvFileVar, filepath.Join(t.TempDir(), "missing.env")))
+	_, err = InitConfig()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to load env file")
+
+	require.NoError(t, os.Unsetenv(EnvFileVar))
+	cfg, err = InitConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "DEBUG", cfg.LogLevel, "values from a dropped env file are removed")
+}
@@ -0,0 +1,200 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileVar names the environment variable that points at a YAML or TOML
+// configuration file when no --config flag is given
+const ConfigFileVar = "CONFIG_FILE"
+
+// elevatorsKey is the file section holding per-elevator overrides
+const elevatorsKey = "elevators"
+
+// ElevatorOverride holds settings of one elevator that differ from the
+// global defaults; zero values keep the default
+type ElevatorOverride struct {
+	EachFloorDuration time.Duration `json:"each_floor_duration,omitempty"`
+	OpenDoorDuration  time.Duration `json:"open_door_duration,omitempty"`
+	OverloadThreshold int           `json:"overload_threshold,omitempty"`
+}
+
+// configFile is the content of a configuration file
+type configFile struct {
+	values    map[string]string
+	elevators map[string]ElevatorOverride
+	buildings map[string]map[string]string
+}
+
+// readConfigFile reads a YAML (.yaml, .yml) or TOML (.toml) configuration
+// file. Top-level keys are setting names in any case, with underscores or
+// dashes, such as log_level or each-floor-duration; the elevators section
+// maps elevator names to their overrides and the buildings section maps
+// building IDs to the settings they override.
+func readConfigFile(path string) (*configFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := map[string]any{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &raw)
+	case ".toml":
+		err = toml.Unmarshal(data, &raw)
+	default:
+		return nil, fmt.Errorf("unsupported config file format %q: use .yaml, .yml or .toml", filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	known := make(map[string]bool)
+	for _, key := range settingKeys() {
+		known[key] = true
+	}
+
+	file := &configFile{values: make(map[string]string)}
+	for key, value := range raw {
+		if strings.EqualFold(key, elevatorsKey) {
+			if file.elevators, err = parseElevatorOverrides(value); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if strings.EqualFold(key, buildingsKey) {
+			if file.buildings, err = parseBuildingOverrides(value); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		setting := normalizeKey(key)
+		if !known[setting] {
+			return nil, fmt.Errorf("unknown setting %q in %s", key, path)
+		}
+		if !isScalar(value) {
+			return nil, fmt.Errorf("setting %q in %s must be a single value", key, path)
+		}
+		file.values[setting] = fmt.Sprint(value)
+	}
+
+	return file, nil
+}
+
+func parseElevatorOverrides(value any) (map[string]ElevatorOverride, error) {
+	cars, ok := value.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%s must map elevator names to their settings", elevatorsKey)
+	}
+
+	overrides := make(map[string]ElevatorOverride, len(cars))
+	for name, settings := range cars {
+		fields, ok := settings.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("settings of elevator %q must be a mapping", name)
+		}
+
+		var override ElevatorOverride
+		for key, raw := range fields {
+			var err error
+			switch normalizeKey(key) {
+			case "EACH_FLOOR_DURATION":
+				override.EachFloorDuration, err = time.ParseDuration(fmt.Sprint(raw))
+			case "OPEN_DOOR_DURATION":
+				override.OpenDoorDuration, err = time.ParseDuration(fmt.Sprint(raw))
+			case "OVERLOAD_THRESHOLD":
+				threshold, isInt := raw.(int)
+				if wide, isInt64 := raw.(int64); isInt64 {
+					threshold, isInt = int(wide), true
+				}
+				if !isInt {
+					err = fmt.Errorf("expected a whole number")
+				}
+				override.OverloadThreshold = threshold
+			default:
+				return nil, fmt.Errorf("unknown setting %q for elevator %q", key, name)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s for elevator %q: %w", key, name, err)
+			}
+		}
+		overrides[name] = override
+	}
+
+	return overrides, nil
+}
+
+func isScalar(value any) bool {
+	switch value.(type) {
+	case map[string]any, []any:
+		return false
+	default:
+		return true
+	}
+}
+
+// settings lists the per-elevator overrides ordered by elevator name, then
+// the per-building overrides ordered by building ID and setting
+func (f *configFile) settings() []Setting {
+	names := make([]string, 0, len(f.elevators))
+	for name := range f.elevators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var settings []Setting
+	add := func(name, key, value string) {
+		settings = append(settings, Setting{
+			Key:    fmt.Sprintf("%s.%s.%s", elevatorsKey, name, key),
+			Value:  value,
+			Source: SourceFile,
+		})
+	}
+	for _, name := range names {
+		override := f.elevators[name]
+		if override.EachFloorDuration != 0 {
+			add(name, "each_floor_duration", override.EachFloorDuration.String())
+		}
+		if override.OpenDoorDuration != 0 {
+			add(name, "open_door_duration", override.OpenDoorDuration.String())
+		}
+		if override.OverloadThreshold != 0 {
+			add(name, "overload_threshold", fmt.Sprint(override.OverloadThreshold))
+		}
+	}
+
+	ids := make([]string, 0, len(f.buildings))
+	for id := range f.buildings {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		keys := make([]string, 0, len(f.buildings[id]))
+		for key := range f.buildings[id] {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			value := f.buildings[id][key]
+			if isSecretKey(key) {
+				value = maskSecret(value)
+			}
+			settings = append(settings, Setting{
+				Key:    fmt.Sprintf("%s.%s.%s", buildingsKey, id, strings.ToLower(key)),
+				Value:  value,
+				Source: SourceFile,
+			})
+		}
+	}
+	return settings
+}
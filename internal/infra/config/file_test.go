@@ -0,0 +1,203 @@
+package config
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func findSetting(settings []Setting, key string) Setting {
+	for _, setting := range settings {
+		if setting.Key == key {
+			return setting
+		}
+	}
+	return Setting{}
+}
+
+func TestReadConfigFile(t *testing.T) {
+	dir := t.TempDir()
+
+	yamlPath := filepath.Join(dir, "elevator.yaml")
+	writeEnvFile(t, yamlPath, `log_level: WARN
+each-floor-duration: 300ms
+RATE_LIMIT_RPM: 40
+elevators:
+  Car-1:
+    each_floor_duration: 1s
+    overload_threshold: 8
+`)
+	tomlPath := filepath.Join(dir, "elevator.toml")
+	writeEnvFile(t, tomlPath, `log_level = "WARN"
+each-floor-duration = "300ms"
+RATE_LIMIT_RPM = 40
+
+[elevators.Car-1]
+each_floor_duration = "1s"
+overload_threshold = 8
+`)
+
+	for _, path := range []string{yamlPath, tomlPath} {
+		t.Run(filepath.Ext(path), func(t *testing.T) {
+			file, err := readConfigFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, map[string]string{
+				"LOG_LEVEL":           "WARN",
+				"EACH_FLOOR_DURATION": "300ms",
+				"RATE_LIMIT_RPM":      "40",
+			}, file.values)
+			assert.Equal(t, map[string]ElevatorOverride{
+				"Car-1": {EachFloorDuration: time.Second, OverloadThreshold: 8},
+			}, file.elevators)
+		})
+	}
+}
+
+func TestReadConfigFile_Errors(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		contains string
+	}{
+		{"unknown setting", "a.yaml", "log_levle: WARN\n", `unknown setting "log_levle"`},
+		{"nested setting", "b.yaml", "log_level:\n  value: WARN\n", "must be a single value"},
+		{"unknown elevator setting", "c.yaml", "elevators:\n  A:\n    speed: 2\n", `unknown setting "speed"`},
+		{"invalid elevator duration", "d.toml", "[elevators.A]\nopen_door_duration = \"soon\"\n", "invalid open_door_duration"},
+		{"unsupported format", "e.json", "{}", "unsupported config file format"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.file)
+			writeEnvFile(t, path, tt.content)
+			_, err := readConfigFile(path)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.contains)
+		})
+	}
+}
+
+func TestInitConfig_LayeredPrecedence(t *testing.T) {
+	cleanup := clearEnvVars()
+	defer cleanup()
+	defer UseFlags(Flags{})
+
+	path := filepath.Join(t.TempDir(), "elevator.yaml")
+	writeEnvFile(t, path, `log_level: WARN
+rate_limit_rpm: 40
+max_elevators: 20
+each_floor_duration: 300ms
+`)
+	t.Setenv(ConfigFileVar, path)
+	t.Setenv("RATE_LIMIT_RPM", "50")
+	t.Setenv("MAX_ELEVATORS", "30")
+
+	flags, err := ParseFlags("elevator", []string{"--max-elevators=40"}, &bytes.Buffer{})
+	require.NoError(t, err)
+	UseFlags(flags)
+
+	cfg, settings, err := Effective()
+	require.NoError(t, err)
+
+	assert.Equal(t, "WARN", cfg.LogLevel)
+	assert.Equal(t, 50, cfg.RateLimitRPM)
+	assert.Equal(t, 40, cfg.MaxElevators)
+	assert.Equal(t, 300*time.Millisecond, cfg.EachFloorDuration)
+	assert.Equal(t, 2*time.Second, cfg.OpenDoorDuration)
+
+	assert.Equal(t, Setting{Key: "LOG_LEVEL", Value: "WARN", Source: SourceFile}, findSetting(settings, "LOG_LEVEL"))
+	assert.Equal(t, Setting{Key: "RATE_LIMIT_RPM", Value: "50", Source: SourceEnv}, findSetting(settings, "RATE_LIMIT_RPM"))
+	assert.Equal(t, Setting{Key: "MAX_ELEVATORS", Value: "40", Source: SourceFlag}, findSetting(settings, "MAX_ELEVATORS"))
+	assert.Equal(t, Setting{Key: "OPEN_DOOR_DURATION", Value: "2s", Source: SourceDefault}, findSetting(settings, "OPEN_DOOR_DURATION"))
+}
+
+func TestInitConfig_PresetSource(t *testing.T) {
+	cleanup := clearEnvVars()
+	defer cleanup()
+
+	t.Setenv("ENV", "production")
+	t.Setenv("RATE_LIMIT_RPM", "60")
+
+	cfg, settings, err := Effective()
+	require.NoError(t, err)
+
+	assert.Equal(t, "WARN", cfg.LogLevel)
+	assert.Equal(t, 60, cfg.RateLimitRPM)
+	assert.Equal(t, Setting{Key: "LOG_LEVEL", Value: "WARN", Source: SourcePreset}, findSetting(settings, "LOG_LEVEL"))
+	assert.Equal(t, Setting{Key: "RATE_LIMIT_RPM", Value: "60", Source: SourceEnv}, findSetting(settings, "RATE_LIMIT_RPM"))
+	assert.Equal(t, SourceEnv, findSetting(settings, "ENV").Source)
+}
+
+func TestInitConfig_FlagOverridesPreset(t *testing.T) {
+	cleanup := clearEnvVars()
+	defer cleanup()
+	defer UseFlags(Flags{})
+
+	path := filepath.Join(t.TempDir(), "elevator.yaml")
+	writeEnvFile(t, path, "open_door_duration: 3s\n")
+	t.Setenv(ConfigFileVar, path)
+	t.Setenv("ENV", "production")
+
+	flags, err := ParseFlags("elevator", []string{"--log-level=DEBUG"}, &bytes.Buffer{})
+	require.NoError(t, err)
+	UseFlags(flags)
+
+	cfg, settings, err := Effective()
+	require.NoError(t, err)
+
+	assert.Equal(t, "DEBUG", cfg.LogLevel)
+	assert.Equal(t, 3*time.Second, cfg.OpenDoorDuration)
+	assert.Equal(t, Setting{Key: "LOG_LEVEL", Value: "DEBUG", Source: SourceFlag}, findSetting(settings, "LOG_LEVEL"))
+	assert.Equal(t, Setting{Key: "OPEN_DOOR_DURATION", Value: "3s", Source: SourceFile}, findSetting(settings, "OPEN_DOOR_DURATION"))
+	assert.Equal(t, Setting{Key: "EACH_FLOOR_DURATION", Value: "200ms", Source: SourcePreset}, findSetting(settings, "EACH_FLOOR_DURATION"))
+}
+
+func TestConfig_ElevatorSettings(t *testing.T) {
+	cleanup := clearEnvVars()
+	defer cleanup()
+
+	path := filepath.Join(t.TempDir(), "elevator.toml")
+	writeEnvFile(t, path, `
+[elevators.Express]
+each_floor_duration = "200ms"
+overload_threshold = 20
+
+[elevators.Freight]
+open_door_duration = "5s"
+`)
+	t.Setenv(ConfigFileVar, path)
+
+	cfg, settings, err := Effective()
+	require.NoError(t, err)
+
+	assert.Equal(t, ElevatorSettings{
+		EachFloorDuration: 200 * time.Millisecond,
+		OpenDoorDuration:  2 * time.Second,
+		OverloadThreshold: 20,
+	}, cfg.ElevatorSettings("Express"))
+	assert.Equal(t, ElevatorSettings{
+		EachFloorDuration: 500 * time.Millisecond,
+		OpenDoorDuration:  5 * time.Second,
+		OverloadThreshold: 12,
+	}, cfg.ElevatorSettings("Freight"))
+	assert.Equal(t, ElevatorSettings{
+		EachFloorDuration: 500 * time.Millisecond,
+		OpenDoorDuration:  2 * time.Second,
+		OverloadThreshold: 12,
+	}, cfg.ElevatorSettings("Local"))
+
+	assert.Equal(t, Setting{Key: "elevators.Express.overload_threshold", Value: "20", Source: SourceFile},
+		findSetting(settings, "elevators.Express.overload_threshold"))
+
+	writeEnvFile(t, path, "[elevators.Express]\noverload_threshold = 150\n")
+	_, err = InitConfig()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "overload threshold must be between 1 and 100")
+}
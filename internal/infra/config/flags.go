@@ -0,0 +1,101 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"text/tabwriter"
+)
+
+// Flags holds the command-line options that affect configuration loading
+type Flags struct {
+	// File is the YAML or TOML configuration file to load
+	File string
+	// PrintConfig asks for the effective configuration to be printed
+	PrintConfig bool
+	// Values are settings given as flags, keyed by setting name
+	Values map[string]string
+}
+
+// flags are the command-line options used by every load, including reloads
+var flags struct {
+	sync.RWMutex
+	current Flags
+}
+
+// ParseFlags parses command-line arguments. Every setting has a flag named
+// after it in lower case with dashes, such as --log-level or
+// --each-floor-duration.
+func ParseFlags(name string, args []string, output io.Writer) (Flags, error) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(output)
+
+	var parsed Flags
+	fs.StringVar(&parsed.File, "config", "", "YAML or TOML configuration file (overrides "+ConfigFileVar+")")
+	fs.BoolVar(&parsed.PrintConfig, "print-config", false, "print the effective configuration and where each value came from, then exit")
+
+	flagKeys := make(map[string]string)
+	for _, key := range settingKeys() {
+		flagName := strings.ToLower(strings.ReplaceAll(key, "_", "-"))
+		flagKeys[flagName] = key
+		fs.String(flagName, "", "overrides "+key)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return Flags{}, err
+	}
+	if fs.NArg() > 0 {
+		return Flags{}, fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+
+	parsed.Values = make(map[string]string)
+	fs.Visit(func(f *flag.Flag) {
+		if key, ok := flagKeys[f.Name]; ok {
+			parsed.Values[key] = f.Value.String()
+		}
+	})
+
+	return parsed, nil
+}
+
+// UseFlags makes later loads, including reloads, apply the given flags
+func UseFlags(f Flags) {
+	flags.Lock()
+	defer flags.Unlock()
+	flags.current = f
+}
+
+func currentFlags() Flags {
+	flags.RLock()
+	defer flags.RUnlock()
+	return flags.current
+}
+
+// Effective loads the configuration like InitConfig and also returns every
+// setting with the layer its value came from
+func Effective() (*Config, []Setting, error) {
+	cfg, settings, err := load()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := validateConfiguration(cfg); err != nil {
+		return nil, nil, fmt.Errorf("configuration validation failed: %w", err)
+	}
+	return cfg, settings, nil
+}
+
+// PrintSettings writes settings as an aligned table; secret values are masked
+func PrintSettings(w io.Writer, settings []Setting) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY\tVALUE\tSOURCE")
+	for _, setting := range settings {
+		value := setting.Value
+		if isSecretKey(setting.Key) {
+			value = maskSecret(value)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", setting.Key, value, setting.Source)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFlags(t *testing.T) {
+	flags, err := ParseFlags("elevator", []string{
+		"--config", "elevator.yaml",
+		"--print-config",
+		"--log-level=DEBUG",
+		"--each-floor-duration", "1s",
+	}, &bytes.Buffer{})
+	require.NoError(t, err)
+
+	assert.Equal(t, Flags{
+		File:        "elevator.yaml",
+		PrintConfig: true,
+		Values: map[string]string{
+			"LOG_LEVEL":           "DEBUG",
+			"EACH_FLOOR_DURATION": "1s",
+		},
+	}, flags)
+
+	_, err = ParseFlags("elevator", []string{"--no-such-setting=1"}, &bytes.Buffer{})
+	require.Error(t, err)
+
+	_, err = ParseFlags("elevator", []string{"serve"}, &bytes.Buffer{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unexpected arguments: serve")
+}
+
+func TestPrintSettings(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, PrintSettings(&out, []Setting{
+		{Key: "LOG_LEVEL", Value: "WARN", Source: SourceFile},
+		{Key: "MQTT_PASSWORD", Value: "hunter2", Source: SourceEnv},
+	}))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, []string{"KEY", "VALUE", "SOURCE"}, strings.Fields(lines[0]))
+	assert.Equal(t, []string{"LOG_LEVEL", "WARN", "file"}, strings.Fields(lines[1]))
+	assert.NotContains(t, lines[2], "hunter2")
+	assert.Equal(t, "env", strings.Fields(lines[2])[2])
+}
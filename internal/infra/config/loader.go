@@ -0,0 +1,187 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Source names the layer an effective configuration value came from
+type Source string
+
+const (
+	// SourceDefault is the built-in default of a setting
+	SourceDefault Source = "default"
+	// SourceFile is the YAML or TOML configuration file
+	SourceFile Source = "file"
+	// SourceEnv is the process environment, including CONFIG_ENV_FILE
+	SourceEnv Source = "env"
+	// SourceFlag is a command-line flag
+	SourceFlag Source = "flag"
+	// SourcePreset is the preset of the selected environment (ENV), which
+	// replaces built-in defaults but not values set by any other layer
+	SourcePreset Source = "preset"
+)
+
+// Setting is an effective configuration value and the layer it came from
+type Setting struct {
+	Key    string
+	Value  string
+	Source Source
+}
+
+// load resolves the configuration from its layers, with later layers taking
+// precedence: defaults < preset < file < env < flags. The preset is chosen by
+// the resolved ENV, so it is applied after the layers are merged, and only to
+// settings that no layer has set.
+func load() (*Config, []Setting, error) {
+	if err := loadEnvFile(); err != nil {
+		return nil, nil, fmt.Errorf("failed to load env file: %w", err)
+	}
+
+	flags := currentFlags()
+	path := flags.File
+	if path == "" {
+		path = os.Getenv(ConfigFileVar)
+	}
+
+	file := &configFile{}
+	if path != "" {
+		var err error
+		if file, err = readConfigFile(path); err != nil {
+			return nil, nil, fmt.Errorf("failed to load config file: %w", err)
+		}
+	}
+
+	cfg := Config{}
+	defaults := Config{}
+	value := reflect.ValueOf(&cfg).Elem()
+	defaultValue := reflect.ValueOf(&defaults).Elem()
+	var settings []Setting
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		key := field.Tag.Get("env")
+		if key == "" {
+			continue
+		}
+
+		raw, source := field.Tag.Get("envDefault"), SourceDefault
+		if err := setField(defaultValue.Field(i), raw); err != nil {
+			return nil, nil, fmt.Errorf("invalid default %q for %s: %w", raw, key, err)
+		}
+		if fileValue, ok := file.values[key]; ok {
+			raw, source = fileValue, SourceFile
+		}
+		if envValue := os.Getenv(key); envValue != "" {
+			raw, source = envValue, SourceEnv
+		}
+		if flagValue, ok := flags.Values[key]; ok {
+			raw, source = flagValue, SourceFlag
+		}
+
+		if err := setField(value.Field(i), raw); err != nil {
+			return nil, nil, fmt.Errorf("invalid value %q for %s from %s: %w", raw, key, source, err)
+		}
+		settings = append(settings, Setting{Key: key, Source: source})
+	}
+	cfg.Elevators = file.elevators
+	cfg.Buildings = file.buildings
+
+	// Apply environment-specific defaults to the built-in defaults, then
+	// carry them over to the settings no other layer has set
+	preset := defaults
+	preset.Environment = cfg.Environment
+	if err := applyEnvironmentDefaults(&preset); err != nil {
+		return nil, nil, fmt.Errorf("failed to apply environment defaults: %w", err)
+	}
+
+	presetValue := reflect.ValueOf(preset)
+	for i, j := 0, 0; i < value.NumField(); i++ {
+		if value.Type().Field(i).Tag.Get("env") == "" {
+			continue
+		}
+		if settings[j].Source == SourceDefault && presetValue.Field(i).Interface() != defaultValue.Field(i).Interface() {
+			value.Field(i).Set(presetValue.Field(i))
+			settings[j].Source = SourcePreset
+		}
+		settings[j].Value = fmt.Sprint(value.Field(i).Interface())
+		j++
+	}
+	settings = append(settings, file.settings()...)
+
+	return &cfg, settings, nil
+}
+
+// setField parses raw into a configuration field
+func setField(field reflect.Value, raw string) error {
+	if field.Type() == reflect.TypeOf(time.Duration(0)) {
+		if raw == "" {
+			field.SetInt(0)
+			return nil
+		}
+		duration, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(duration))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Bool:
+		if raw == "" {
+			field.SetBool(false)
+			return nil
+		}
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		if raw == "" {
+			field.SetInt(0)
+			return nil
+		}
+		n, err := strconv.ParseInt(raw, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Float64:
+		if raw == "" {
+			field.SetFloat(0)
+			return nil
+		}
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+	return nil
+}
+
+// settingKeys returns the keys of all settings in declaration order
+func settingKeys() []string {
+	configType := reflect.TypeOf(Config{})
+	keys := make([]string, 0, configType.NumField())
+	for i := 0; i < configType.NumField(); i++ {
+		if key := configType.Field(i).Tag.Get("env"); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// normalizeKey maps file keys such as log_level or log-level to setting keys
+func normalizeKey(key string) string {
+	return strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+}
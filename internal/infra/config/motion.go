@@ -0,0 +1,44 @@
+package config
+
+import (
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+)
+
+// Motion models
+const (
+	// MotionModelFixed takes the floor duration for every floor travelled
+	MotionModelFixed = "fixed"
+	// MotionModelKinematic accelerates cars along a jerk-limited profile
+	MotionModelKinematic = "kinematic"
+)
+
+// validateMotionConfiguration validates the motion model settings
+func validateMotionConfiguration(cfg *Config) error {
+	switch cfg.MotionModel {
+	case MotionModelFixed:
+		return nil
+	case MotionModelKinematic:
+	default:
+		return domain.NewValidationError("motion model must be fixed or kinematic", nil).
+			WithContext("motion_model", cfg.MotionModel)
+	}
+
+	for _, setting := range []struct {
+		name  string
+		value float64
+		limit float64
+	}{
+		{"motion_max_speed", cfg.MotionMaxSpeed, 20},
+		{"motion_acceleration", cfg.MotionAcceleration, 10},
+		{"motion_jerk", cfg.MotionJerk, 20},
+		{"motion_floor_height", cfg.MotionFloorHeight, 20},
+	} {
+		if !(setting.value > 0 && setting.value <= setting.limit) {
+			return domain.NewValidationError("motion settings must be positive and realistic", nil).
+				WithContext(setting.name, setting.value).
+				WithContext("limit", setting.limit)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_MotionDefaults(t *testing.T) {
+	cleanup := clearEnvVars()
+	defer cleanup()
+
+	cfg, err := InitConfig()
+	require.NoError(t, err)
+	assert.Equal(t, MotionModelFixed, cfg.MotionModel)
+	assert.Equal(t, 2.5, cfg.MotionMaxSpeed)
+	assert.Equal(t, 1.0, cfg.MotionAcceleration)
+	assert.Equal(t, 1.5, cfg.MotionJerk)
+	assert.Equal(t, 3.5, cfg.MotionFloorHeight)
+}
+
+func TestConfig_MotionValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		contains string
+	}{
+		{"unknown model", map[string]string{"MOTION_MODEL": "teleport"}, "motion model must be fixed or kinematic"},
+		{"zero speed", map[string]string{"MOTION_MODEL": "kinematic", "MOTION_MAX_SPEED": "0"}, "motion settings must be positive"},
+		{"negative jerk", map[string]string{"MOTION_MODEL": "kinematic", "MOTION_JERK": "-1"}, "motion settings must be positive"},
+		{"unrealistic acceleration", map[string]string{"MOTION_MODEL": "kinematic", "MOTION_ACCELERATION": "50"}, "motion settings must be positive"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cleanup := clearEnvVars()
+			defer cleanup()
+
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			_, err := InitConfig()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.contains)
+		})
+	}
+
+	cleanup := clearEnvVars()
+	defer cleanup()
+	t.Setenv("MOTION_MODEL", "kinematic")
+	_, err := InitConfig()
+	assert.NoError(t, err)
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"time"
+
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+)
+
+// validatePrepositionConfiguration validates how demand is forecast and how
+// idle cars are placed ahead of it
+func validatePrepositionConfiguration(cfg *Config) error {
+	if cfg.PrepositionInterval < time.Second || cfg.PrepositionInterval > time.Hour {
+		return domain.NewValidationError("preposition interval must be between 1s and 1h", nil).
+			WithContext("interval", cfg.PrepositionInterval)
+	}
+
+	if cfg.PrepositionLookahead < time.Minute || cfg.PrepositionLookahead > 24*time.Hour {
+		return domain.NewValidationError("preposition lookahead must be between 1m and 24h", nil).
+			WithContext("lookahead", cfg.PrepositionLookahead)
+	}
+
+	if cfg.PrepositionMinCalls <= 0 || cfg.PrepositionMinCalls > 1000 {
+		return domain.NewValidationError("preposition minimum calls must be greater than 0 and at most 1000", nil).
+			WithContext("min_calls", cfg.PrepositionMinCalls)
+	}
+
+	// Slots are whole minutes that tile every hour, so each one falls on the
+	// same time of every week
+	if cfg.ForecastSlot < time.Minute || cfg.ForecastSlot > time.Hour ||
+		cfg.ForecastSlot%time.Minute != 0 || time.Hour%cfg.ForecastSlot != 0 {
+		return domain.NewValidationError("forecast slot must be a whole number of minutes dividing an hour", nil).
+			WithContext("slot", cfg.ForecastSlot)
+	}
+
+	if cfg.ForecastSmoothing <= 0 || cfg.ForecastSmoothing > 1 {
+		return domain.NewValidationError("forecast smoothing must be greater than 0 and at most 1", nil).
+			WithContext("smoothing", cfg.ForecastSmoothing)
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_PrepositionDefaults(t *testing.T) {
+	cleanup := clearEnvVars()
+	defer cleanup()
+
+	cfg, err := InitConfig()
+	require.NoError(t, err)
+	assert.False(t, cfg.PrepositionEnabled)
+	assert.Equal(t, 30*time.Second, cfg.PrepositionInterval)
+	assert.Equal(t, 15*time.Minute, cfg.PrepositionLookahead)
+	assert.Equal(t, 1.0, cfg.PrepositionMinCalls)
+	assert.Equal(t, 15*time.Minute, cfg.ForecastSlot)
+	assert.Equal(t, 0.3, cfg.ForecastSmoothing)
+}
+
+func TestConfig_PrepositionValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		contains string
+	}{
+		{"short interval", map[string]string{"PREPOSITION_INTERVAL": "100ms"}, "preposition interval must be between 1s and 1h"},
+		{"long lookahead", map[string]string{"PREPOSITION_LOOKAHEAD": "48h"}, "preposition lookahead must be between 1m and 24h"},
+		{"no minimum calls", map[string]string{"PREPOSITION_MIN_CALLS": "0"}, "preposition minimum calls must be greater than 0"},
+		{"partial minute slot", map[string]string{"FORECAST_SLOT": "90s"}, "forecast slot must be a whole number of minutes dividing an hour"},
+		{"slot not dividing an hour", map[string]string{"FORECAST_SLOT": "7m"}, "forecast slot must be a whole number of minutes dividing an hour"},
+		{"long slot", map[string]string{"FORECAST_SLOT": "2h"}, "forecast slot must be a whole number of minutes dividing an hour"},
+		{"no smoothing", map[string]string{"FORECAST_SMOOTHING": "0"}, "forecast smoothing must be greater than 0 and at most 1"},
+		{"excess smoothing", map[string]string{"FORECAST_SMOOTHING": "1.5"}, "forecast smoothing must be greater than 0 and at most 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cleanup := clearEnvVars()
+			defer cleanup()
+
+			t.Setenv("PREPOSITION_ENABLED", "true")
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			_, err := InitConfig()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.contains)
+		})
+	}
+
+	// The settings are only checked when pre-positioning is enabled
+	cleanup := clearEnvVars()
+	defer cleanup()
+	t.Setenv("FORECAST_SLOT", "7m")
+	_, err := InitConfig()
+	assert.NoError(t, err)
+}
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+)
+
+// reloadableKeys lists the settings a running system applies without a
+// restart. Everything else is wired into servers, connections or elevators
+// when they are created.
+var reloadableKeys = map[string]bool{
+	"LOG_LEVEL":                         true,
+	"RATE_LIMIT_RPM":                    true,
+	"RATE_LIMIT_WINDOW":                 true,
+	"STATUS_UPDATE_INTERVAL":            true,
+	"DEFAULT_OVERLOAD_THRESHOLD":        true,
+	"CIRCUIT_BREAKER_MAX_FAILURES":      true,
+	"CIRCUIT_BREAKER_RESET_TIMEOUT":     true,
+	"CIRCUIT_BREAKER_HALF_OPEN_LIMIT":   true,
+	"CIRCUIT_BREAKER_FAILURE_THRESHOLD": true,
+	"CIRCUIT_BREAKER_WINDOW":            true,
+	"CIRCUIT_BREAKER_MIN_REQUESTS":      true,
+	"CORS_ALLOWED_ORIGINS":              true,
+	"STAFF_API_TOKEN":                   true,
+	"ADMIN_API_TOKEN":                   true,
+}
+
+// Change describes a setting that differs between two configurations
+type Change struct {
+	Key        string `json:"key"`
+	Old        string `json:"old"`
+	New        string `json:"new"`
+	Reloadable bool   `json:"reloadable"`
+}
+
+// ReloadableKeys returns the settings that can change without a restart
+func ReloadableKeys() []string {
+	keys := make([]string, 0, len(reloadableKeys))
+	for key := range reloadableKeys {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Diff returns the settings that differ between two configurations in the
+// order they are declared in Config. Secret values are masked.
+func Diff(old, updated *Config) []Change {
+	oldValue := reflect.ValueOf(old).Elem()
+	newValue := reflect.ValueOf(updated).Elem()
+	configType := oldValue.Type()
+
+	var changes []Change
+	for i := 0; i < configType.NumField(); i++ {
+		key := configType.Field(i).Tag.Get("env")
+		if key == "" {
+			continue
+		}
+
+		before := fmt.Sprint(oldValue.Field(i).Interface())
+		after := fmt.Sprint(newValue.Field(i).Interface())
+		if before == after {
+			continue
+		}
+
+		if isSecretKey(key) {
+			before, after = maskSecret(before), maskSecret(after)
+		}
+		changes = append(changes, Change{
+			Key:        key,
+			Old:        before,
+			New:        after,
+			Reloadable: reloadableKeys[key],
+		})
+	}
+
+	// Overrides only affect elevators created after the reload
+	if !reflect.DeepEqual(old.Elevators, updated.Elevators) {
+		changes = append(changes, Change{
+			Key:        elevatorsKey,
+			Old:        fmt.Sprint(old.Elevators),
+			New:        fmt.Sprint(updated.Elevators),
+			Reloadable: true,
+		})
+	}
+
+	// Buildings get their own managers and fleets at startup
+	if !reflect.DeepEqual(old.Buildings, updated.Buildings) {
+		changes = append(changes, Change{
+			Key:        buildingsKey,
+			Old:        fmt.Sprint(old.BuildingIDs()),
+			New:        fmt.Sprint(updated.BuildingIDs()),
+			Reloadable: false,
+		})
+	}
+
+	return changes
+}
+
+// Reload loads and validates the configuration again and compares it with
+// current. The new configuration is only returned when every change can be
+// applied to the running system; otherwise the error names the settings that
+// need a restart.
+func Reload(current *Config) (*Config, []Change, error) {
+	updated, err := InitConfig()
+	if err != nil {
+		return nil, nil, domain.NewValidationError("reloaded configuration is invalid", err)
+	}
+
+	changes := Diff(current, updated)
+
+	var restartRequired []string
+	for _, change := range changes {
+		if !change.Reloadable {
+			restartRequired = append(restartRequired, change.Key)
+		}
+	}
+	if len(restartRequired) > 0 {
+		return nil, changes, domain.NewValidationError(
+			fmt.Sprintf("configuration changes require a restart: %s", strings.Join(restartRequired, ", ")), nil).
+			WithContext("restart_required", restartRequired)
+	}
+
+	return updated, changes, nil
+}
+
+func isSecretKey(key string) bool {
+	return strings.Contains(key, "PASSWORD") || strings.Contains(key, "SECRET") || strings.Contains(key, "TOKEN")
+}
+
+func maskSecret(value string) string {
+	if value == "" {
+		return ""
+	}
+	return "***"
+}
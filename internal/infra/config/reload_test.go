@@ -0,0 +1,74 @@
+package config
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	old := &Config{LogLevel: "INFO", Port: 6660, RateLimitRPM: 100, MQTTPassword: "secret"}
+	updated := &Config{LogLevel: "DEBUG", Port: 7000, RateLimitRPM: 100, MQTTPassword: "other", StatusUpdateInterval: time.Second}
+
+	changes := Diff(old, updated)
+	assert.Equal(t, []Change{
+		{Key: "LOG_LEVEL", Old: "INFO", New: "DEBUG", Reloadable: true},
+		{Key: "PORT", Old: "6660", New: "7000", Reloadable: false},
+		{Key: "STATUS_UPDATE_INTERVAL", Old: "0s", New: "1s", Reloadable: true},
+		{Key: "MQTT_PASSWORD", Old: "***", New: "***", Reloadable: false},
+	}, changes)
+
+	assert.Empty(t, Diff(old, old))
+}
+
+func TestReload(t *testing.T) {
+	cleanupEnv := clearEnvVars()
+	defer cleanupEnv()
+
+	current, err := InitConfig()
+	require.NoError(t, err)
+
+	t.Run("applies reloadable changes", func(t *testing.T) {
+		require.NoError(t, os.Setenv("RATE_LIMIT_RPM", "250"))
+		require.NoError(t, os.Setenv("CIRCUIT_BREAKER_MAX_FAILURES", "8"))
+		defer os.Unsetenv("RATE_LIMIT_RPM")
+		defer os.Unsetenv("CIRCUIT_BREAKER_MAX_FAILURES")
+
+		updated, changes, err := Reload(current)
+		require.NoError(t, err)
+		assert.Equal(t, 250, updated.RateLimitRPM)
+		assert.Equal(t, 8, updated.CircuitBreakerMaxFailures)
+		assert.Len(t, changes, 2)
+	})
+
+	t.Run("rejects restart-only changes", func(t *testing.T) {
+		require.NoError(t, os.Setenv("RATE_LIMIT_RPM", "250"))
+		require.NoError(t, os.Setenv("PORT", "7000"))
+		defer os.Unsetenv("RATE_LIMIT_RPM")
+		defer os.Unsetenv("PORT")
+
+		updated, changes, err := Reload(current)
+		assert.Nil(t, updated)
+		assert.Len(t, changes, 2)
+
+		var domainErr *domain.DomainError
+		require.ErrorAs(t, err, &domainErr)
+		assert.Equal(t, domain.ErrTypeValidation, domainErr.Type)
+		assert.Contains(t, err.Error(), "configuration changes require a restart: PORT")
+	})
+
+	t.Run("rejects invalid values", func(t *testing.T) {
+		require.NoError(t, os.Setenv("DEFAULT_OVERLOAD_THRESHOLD", "0"))
+		defer os.Unsetenv("DEFAULT_OVERLOAD_THRESHOLD")
+
+		updated, _, err := Reload(current)
+		assert.Nil(t, updated)
+		var domainErr *domain.DomainError
+		require.ErrorAs(t, err, &domainErr)
+		assert.Contains(t, err.Error(), "reloaded configuration is invalid")
+	})
+}
@@ -0,0 +1,213 @@
+package config
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+)
+
+// Service-level indicators the manager computes
+const (
+	// SLIWaitTime is a percentile of the seconds riders waited for pickup
+	SLIWaitTime = "wait_time"
+	// SLIRideTime is a percentile of the seconds from pickup to drop-off
+	SLIRideTime = "ride_time"
+	// SLIRejectedRequests is the share of floor requests no elevator took
+	SLIRejectedRequests = "rejected_requests"
+	// SLIUnavailableCars is the number of cars out of service
+	SLIUnavailableCars = "unavailable_cars"
+)
+
+// SLA rule severities
+const (
+	// SLASeverityWarning breaches degrade readiness
+	SLASeverityWarning = "warning"
+	// SLASeverityCritical breaches fail readiness
+	SLASeverityCritical = "critical"
+)
+
+const (
+	// DefaultSLAWindow is the window of a rule that names none
+	DefaultSLAWindow = 5 * time.Minute
+	// MaxSLAWindow is the longest window a rule may look back over
+	MaxSLAWindow = 24 * time.Hour
+)
+
+var slaRuleName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// SLARule is an objective on a service-level indicator: the indicator over
+// the trailing window must stay at or below the threshold
+type SLARule struct {
+	Name       string        `json:"name"`
+	Indicator  string        `json:"indicator"`
+	Percentile float64       `json:"percentile,omitempty"` // For wait and ride times
+	Threshold  float64       `json:"threshold"`            // Seconds, a share of requests or a number of cars
+	Window     time.Duration `json:"window"`
+	Severity   string        `json:"severity"`
+}
+
+// IsTime reports whether the rule's indicator is a duration in seconds
+func (r SLARule) IsTime() bool {
+	return r.Indicator == SLIWaitTime || r.Indicator == SLIRideTime
+}
+
+// ParseSLARules parses rules of the form
+// "name:indicator:objective[:window[:severity]]" separated by semicolons,
+// e.g. "answered-30s:wait_time:p95<=30s:5m:critical;rejections:rejected_requests:<=5%".
+// Wait and ride time objectives name a percentile and a duration, rejected
+// requests a percentage or fraction and unavailable cars a count. The window
+// defaults to 5m and the severity to warning.
+func ParseSLARules(spec string) ([]SLARule, error) {
+	var rules []SLARule
+	names := make(map[string]bool)
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		rule, err := parseSLARule(entry)
+		if err != nil {
+			return nil, err
+		}
+		if names[rule.Name] {
+			return nil, domain.NewValidationError("duplicate SLA rule name", nil).
+				WithContext("rule", rule.Name)
+		}
+		names[rule.Name] = true
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func parseSLARule(entry string) (SLARule, error) {
+	fields := strings.Split(entry, ":")
+	for i := range fields {
+		fields[i] = strings.TrimSpace(fields[i])
+	}
+	if len(fields) < 3 || len(fields) > 5 {
+		return SLARule{}, domain.NewValidationError("SLA rule must have the form name:indicator:objective[:window[:severity]]", nil).
+			WithContext("rule", entry)
+	}
+
+	rule := SLARule{
+		Name:      fields[0],
+		Indicator: fields[1],
+		Window:    DefaultSLAWindow,
+		Severity:  SLASeverityWarning,
+	}
+	if !slaRuleName.MatchString(rule.Name) {
+		return SLARule{}, domain.NewValidationError("SLA rule name must be lowercase letters, digits, dashes or underscores", nil).
+			WithContext("rule", entry)
+	}
+	switch rule.Indicator {
+	case SLIWaitTime, SLIRideTime, SLIRejectedRequests, SLIUnavailableCars:
+	default:
+		return SLARule{}, domain.NewValidationError("SLA indicator must be wait_time, ride_time, rejected_requests or unavailable_cars", nil).
+			WithContext("rule", rule.Name).
+			WithContext("indicator", rule.Indicator)
+	}
+
+	if err := rule.parseObjective(fields[2]); err != nil {
+		return SLARule{}, err
+	}
+
+	if len(fields) > 3 && fields[3] != "" {
+		window, err := time.ParseDuration(fields[3])
+		if err != nil || window <= 0 || window > MaxSLAWindow {
+			return SLARule{}, domain.NewValidationError("SLA window must be a positive duration of at most 24h", err).
+				WithContext("rule", rule.Name).
+				WithContext("window", fields[3])
+		}
+		rule.Window = window
+	}
+
+	if len(fields) > 4 {
+		rule.Severity = fields[4]
+		if rule.Severity != SLASeverityWarning && rule.Severity != SLASeverityCritical {
+			return SLARule{}, domain.NewValidationError("SLA severity must be warning or critical", nil).
+				WithContext("rule", rule.Name).
+				WithContext("severity", rule.Severity)
+		}
+	}
+
+	return rule, nil
+}
+
+// parseObjective reads "[pNN]<=threshold" into the rule
+func (r *SLARule) parseObjective(objective string) error {
+	percentile, threshold, ok := strings.Cut(objective, "<=")
+	if !ok {
+		return domain.NewValidationError("SLA objective must have the form [pNN]<=threshold", nil).
+			WithContext("rule", r.Name).
+			WithContext("objective", objective)
+	}
+	percentile, threshold = strings.TrimSpace(percentile), strings.TrimSpace(threshold)
+
+	if r.IsTime() {
+		value, err := strconv.ParseFloat(strings.TrimPrefix(percentile, "p"), 64)
+		if !strings.HasPrefix(percentile, "p") || err != nil || value <= 0 || value > 100 {
+			return domain.NewValidationError("wait and ride time objectives need a percentile between p0 and p100, e.g. p95<=30s", err).
+				WithContext("rule", r.Name).
+				WithContext("objective", objective)
+		}
+		r.Percentile = value
+
+		duration, err := time.ParseDuration(threshold)
+		if err != nil || duration <= 0 {
+			return domain.NewValidationError("wait and ride time thresholds must be positive durations", err).
+				WithContext("rule", r.Name).
+				WithContext("objective", objective)
+		}
+		r.Threshold = duration.Seconds()
+		return nil
+	}
+
+	if percentile != "" {
+		return domain.NewValidationError("only wait and ride time objectives take a percentile", nil).
+			WithContext("rule", r.Name).
+			WithContext("objective", objective)
+	}
+
+	switch r.Indicator {
+	case SLIRejectedRequests:
+		value, isPercent := strings.CutSuffix(threshold, "%")
+		share, err := strconv.ParseFloat(value, 64)
+		if isPercent {
+			share /= 100
+		}
+		if err != nil || share < 0 || share > 1 {
+			return domain.NewValidationError("rejected request thresholds must be a percentage or a fraction between 0 and 1", err).
+				WithContext("rule", r.Name).
+				WithContext("objective", objective)
+		}
+		r.Threshold = share
+	case SLIUnavailableCars:
+		count, err := strconv.Atoi(threshold)
+		if err != nil || count < 0 {
+			return domain.NewValidationError("unavailable car thresholds must be a whole number of cars", err).
+				WithContext("rule", r.Name).
+				WithContext("objective", objective)
+		}
+		r.Threshold = float64(count)
+	}
+	return nil
+}
+
+// validateSLAConfiguration validates the SLA rules and how often they are
+// evaluated
+func validateSLAConfiguration(cfg *Config) error {
+	if _, err := ParseSLARules(cfg.SLARules); err != nil {
+		return err
+	}
+
+	if cfg.SLAEvaluationInterval <= 0 {
+		return domain.NewValidationError("SLA evaluation interval must be positive", nil).
+			WithContext("sla_evaluation_interval", cfg.SLAEvaluationInterval)
+	}
+
+	return nil
+}
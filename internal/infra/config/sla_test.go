@@ -0,0 +1,93 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSLARules(t *testing.T) {
+	rules, err := ParseSLARules("answered-30s:wait_time:p95<=30s:10m:critical; rejections:rejected_requests:<=5% ;fleet:unavailable_cars:<=1;;rides:ride_time:p99.9<=2m::warning;share:rejected_requests:<=0.1")
+	require.NoError(t, err)
+	require.Len(t, rules, 5)
+
+	assert.Equal(t, SLARule{
+		Name:       "answered-30s",
+		Indicator:  SLIWaitTime,
+		Percentile: 95,
+		Threshold:  30,
+		Window:     10 * time.Minute,
+		Severity:   SLASeverityCritical,
+	}, rules[0])
+	assert.True(t, rules[0].IsTime())
+
+	assert.Equal(t, SLIRejectedRequests, rules[1].Indicator)
+	assert.InDelta(t, 0.05, rules[1].Threshold, 1e-9)
+	assert.Equal(t, DefaultSLAWindow, rules[1].Window)
+	assert.Equal(t, SLASeverityWarning, rules[1].Severity)
+	assert.False(t, rules[1].IsTime())
+
+	assert.Equal(t, 1.0, rules[2].Threshold)
+	assert.Equal(t, 99.9, rules[3].Percentile)
+	assert.Equal(t, 120.0, rules[3].Threshold)
+	assert.Equal(t, DefaultSLAWindow, rules[3].Window)
+	assert.InDelta(t, 0.1, rules[4].Threshold, 1e-9)
+
+	rules, err = ParseSLARules("")
+	require.NoError(t, err)
+	assert.Empty(t, rules)
+}
+
+func TestParseSLARules_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     string
+		contains string
+	}{
+		{"missing objective", "slow:wait_time", "SLA rule must have the form"},
+		{"too many fields", "slow:wait_time:p95<=30s:5m:critical:extra", "SLA rule must have the form"},
+		{"invalid name", "Slow Calls:wait_time:p95<=30s", "SLA rule name must be"},
+		{"unknown indicator", "slow:queue_length:<=3", "SLA indicator must be"},
+		{"missing comparison", "slow:wait_time:p95>30s", "SLA objective must have the form"},
+		{"missing percentile", "slow:wait_time:<=30s", "need a percentile"},
+		{"percentile out of range", "slow:wait_time:p120<=30s", "need a percentile"},
+		{"invalid duration", "slow:ride_time:p95<=30", "thresholds must be positive durations"},
+		{"percentile on ratio", "rejections:rejected_requests:p95<=5%", "only wait and ride time objectives take a percentile"},
+		{"ratio above one", "rejections:rejected_requests:<=150%", "percentage or a fraction"},
+		{"fractional cars", "fleet:unavailable_cars:<=1.5", "whole number of cars"},
+		{"window too long", "slow:wait_time:p95<=30s:48h", "SLA window must be"},
+		{"invalid severity", "slow:wait_time:p95<=30s:5m:page", "SLA severity must be"},
+		{"duplicate name", "slow:wait_time:p95<=30s;slow:ride_time:p95<=1m", "duplicate SLA rule name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseSLARules(tt.spec)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.contains)
+		})
+	}
+}
+
+func TestConfig_SLAValidation(t *testing.T) {
+	cleanup := clearEnvVars()
+	defer cleanup()
+
+	cfg, err := InitConfig()
+	require.NoError(t, err)
+	assert.Empty(t, cfg.SLARules)
+	assert.Equal(t, 15*time.Second, cfg.SLAEvaluationInterval)
+
+	t.Setenv("SLA_RULES", "slow:wait_time:p95")
+	_, err = InitConfig()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "SLA objective must have the form")
+
+	t.Setenv("SLA_RULES", "slow:wait_time:p95<=30s")
+	t.Setenv("SLA_EVALUATION_INTERVAL", "0s")
+	_, err = InitConfig()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "SLA evaluation interval must be positive")
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"net/netip"
+	"strings"
+
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+)
+
+// ParseWebhookNetworks parses WEBHOOK_ALLOWED_NETWORKS: CIDRs separated by
+// commas, such as "10.0.0.0/8,127.0.0.1/32"
+func ParseWebhookNetworks(spec string) ([]netip.Prefix, error) {
+	var networks []netip.Prefix
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		network, err := netip.ParsePrefix(entry)
+		if err != nil {
+			return nil, domain.NewValidationError("webhook allowed networks must be CIDRs separated by commas", err).
+				WithContext("network", entry)
+		}
+		networks = append(networks, network.Masked())
+	}
+	return networks, nil
+}
+
+// validateWebhookConfiguration validates the webhook delivery settings
+func validateWebhookConfiguration(cfg *Config) error {
+	if cfg.WebhookMaxAttempts <= 0 || cfg.WebhookMaxAttempts > 20 {
+		return domain.NewValidationError("webhook max attempts must be between 1 and 20", nil).
+			WithContext("max_attempts", cfg.WebhookMaxAttempts)
+	}
+
+	if cfg.WebhookRetryBackoff <= 0 {
+		return domain.NewValidationError("webhook retry backoff must be positive", nil).
+			WithContext("retry_backoff", cfg.WebhookRetryBackoff)
+	}
+
+	if cfg.WebhookMaxRetryBackoff < cfg.WebhookRetryBackoff {
+		return domain.NewValidationError("webhook max retry backoff cannot be shorter than the retry backoff", nil).
+			WithContext("retry_backoff", cfg.WebhookRetryBackoff).
+			WithContext("max_retry_backoff", cfg.WebhookMaxRetryBackoff)
+	}
+
+	if cfg.WebhookTimeout <= 0 {
+		return domain.NewValidationError("webhook timeout must be positive", nil).
+			WithContext("timeout", cfg.WebhookTimeout)
+	}
+
+	if cfg.WebhookDeliveryLogSize <= 0 || cfg.WebhookDeliveryLogSize > 10000 {
+		return domain.NewValidationError("webhook delivery log size must be between 1 and 10000", nil).
+			WithContext("delivery_log_size", cfg.WebhookDeliveryLogSize)
+	}
+
+	if _, err := ParseWebhookNetworks(cfg.WebhookAllowedNetworks); err != nil {
+		return err
+	}
+
+	return nil
+}
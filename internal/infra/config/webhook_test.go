@@ -0,0 +1,74 @@
+package config
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_WebhookDefaults(t *testing.T) {
+	cleanup := clearEnvVars()
+	defer cleanup()
+
+	cfg, err := InitConfig()
+	require.NoError(t, err)
+	assert.False(t, cfg.WebhookEnabled)
+	assert.Equal(t, 5, cfg.WebhookMaxAttempts)
+	assert.Equal(t, time.Second, cfg.WebhookRetryBackoff)
+	assert.Equal(t, 5*time.Minute, cfg.WebhookMaxRetryBackoff)
+	assert.Equal(t, 5*time.Second, cfg.WebhookTimeout)
+	assert.Equal(t, 100, cfg.WebhookDeliveryLogSize)
+	assert.Empty(t, cfg.WebhookAllowedNetworks)
+}
+
+func TestConfig_WebhookValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		contains string
+	}{
+		{"no attempts", map[string]string{"WEBHOOK_MAX_ATTEMPTS": "0"}, "webhook max attempts must be between 1 and 20"},
+		{"too many attempts", map[string]string{"WEBHOOK_MAX_ATTEMPTS": "50"}, "webhook max attempts must be between 1 and 20"},
+		{"invalid backoff", map[string]string{"WEBHOOK_RETRY_BACKOFF": "0s"}, "webhook retry backoff must be positive"},
+		{"max backoff below backoff", map[string]string{"WEBHOOK_RETRY_BACKOFF": "10s", "WEBHOOK_MAX_RETRY_BACKOFF": "5s"}, "webhook max retry backoff cannot be shorter"},
+		{"invalid timeout", map[string]string{"WEBHOOK_TIMEOUT": "0s"}, "webhook timeout must be positive"},
+		{"invalid log size", map[string]string{"WEBHOOK_DELIVERY_LOG_SIZE": "0"}, "webhook delivery log size must be between 1 and 10000"},
+		{"invalid allowed network", map[string]string{"WEBHOOK_ALLOWED_NETWORKS": "10.0.0.0/8,intranet"}, "webhook allowed networks must be CIDRs"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cleanup := clearEnvVars()
+			defer cleanup()
+
+			t.Setenv("WEBHOOK_ENABLED", "true")
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			_, err := InitConfig()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.contains)
+		})
+	}
+
+	// The settings are only checked when webhooks are enabled
+	cleanup := clearEnvVars()
+	defer cleanup()
+	t.Setenv("WEBHOOK_MAX_ATTEMPTS", "0")
+	_, err := InitConfig()
+	assert.NoError(t, err)
+}
+
+func TestParseWebhookNetworks(t *testing.T) {
+	networks, err := ParseWebhookNetworks(" 10.1.2.3/8, ::1/128 ,")
+	require.NoError(t, err)
+	assert.Equal(t, []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("::1/128")}, networks)
+
+	networks, err = ParseWebhookNetworks("")
+	require.NoError(t, err)
+	assert.Empty(t, networks)
+}
@@ -305,8 +305,10 @@ func (chc *ComponentHealthChecker) Check(ctx context.Context) CheckResult {
 	}
 }
 
-// ReadinessChecker checks if the application is ready to serve traffic
+// ReadinessChecker checks if the application is ready to serve traffic.
+// Unhealthy dependencies make it unhealthy, degraded ones degraded.
 type ReadinessChecker struct {
+	mu           sync.RWMutex
 	dependencies []HealthChecker
 }
 
@@ -316,6 +318,13 @@ func NewReadinessChecker(dependencies ...HealthChecker) *ReadinessChecker {
 	}
 }
 
+// AddDependency adds a component the application's readiness depends on
+func (rc *ReadinessChecker) AddDependency(dependency HealthChecker) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.dependencies = append(rc.dependencies, dependency)
+}
+
 func (rc *ReadinessChecker) Name() string {
 	return "readiness"
 }
@@ -327,22 +336,32 @@ func (rc *ReadinessChecker) Check(ctx context.Context) CheckResult {
 	message := "Application is ready"
 	details := make(map[string]interface{})
 
-	unhealthyCount := 0
-	for _, dep := range rc.dependencies {
+	rc.mu.RLock()
+	dependencies := append([]HealthChecker(nil), rc.dependencies...)
+	rc.mu.RUnlock()
+
+	unhealthyCount, degradedCount := 0, 0
+	for _, dep := range dependencies {
 		result := dep.Check(ctx)
 		details[dep.Name()] = map[string]interface{}{
 			"status":  result.Status,
 			"message": result.Message,
 		}
 
-		if result.Status == StatusUnhealthy {
+		switch result.Status {
+		case StatusUnhealthy:
 			unhealthyCount++
+		case StatusDegraded:
+			degradedCount++
 		}
 	}
 
 	if unhealthyCount > 0 {
 		status = StatusUnhealthy
 		message = fmt.Sprintf("Application not ready: %d unhealthy dependencies", unhealthyCount)
+	} else if degradedCount > 0 {
+		status = StatusDegraded
+		message = fmt.Sprintf("Application ready but degraded: %d degraded dependencies", degradedCount)
 	}
 
 	return CheckResult{
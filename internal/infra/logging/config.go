@@ -6,9 +6,13 @@ import (
 	"strings"
 )
 
+// level is shared by the loggers InitLogger installs so SetLevel can change
+// verbosity while the service runs
+var level = new(slog.LevelVar)
+
 // InitLogger configures the global slog logger with JSON handler
 func InitLogger(logLevel string) {
-	level := parseLogLevel(logLevel)
+	level.Set(parseLogLevel(logLevel))
 
 	handler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
 		Level: level,
@@ -31,6 +35,16 @@ func InitLogger(logLevel string) {
 	slog.SetDefault(logger)
 }
 
+// SetLevel changes the level of the logger installed by InitLogger
+func SetLevel(logLevel string) {
+	level.Set(parseLogLevel(logLevel))
+}
+
+// Level returns the current level of the logger installed by InitLogger
+func Level() slog.Level {
+	return level.Level()
+}
+
 // parseLogLevel converts string log level to slog.Level
 // Defaults to INFO for production safety
 func parseLogLevel(logLevel string) slog.Level {
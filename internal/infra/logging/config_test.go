@@ -1,6 +1,7 @@
 package logging
 
 import (
+	"context"
 	"log/slog"
 	"testing"
 )
@@ -124,3 +125,21 @@ func TestInitLogger(t *testing.T) {
 		})
 	}
 }
+
+func TestSetLevel(t *testing.T) {
+	InitLogger("INFO")
+	defer InitLogger("INFO")
+
+	SetLevel("DEBUG")
+	if Level() != slog.LevelDebug {
+		t.Errorf("Level() = %v, want %v", Level(), slog.LevelDebug)
+	}
+	if !slog.Default().Enabled(context.Background(), slog.LevelDebug) {
+		t.Error("default logger should log debug messages after SetLevel(DEBUG)")
+	}
+
+	SetLevel("ERROR")
+	if slog.Default().Enabled(context.Background(), slog.LevelWarn) {
+		t.Error("default logger should drop warnings after SetLevel(ERROR)")
+	}
+}
@@ -15,8 +15,10 @@ import (
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/trace"
 	"go.opentelemetry.io/otel/trace/noop"
 )
@@ -27,6 +29,7 @@ type TelemetryProvider struct {
 	logger        *slog.Logger
 	tracer        trace.Tracer
 	meter         metric.Meter
+	spans         *sdktrace.TracerProvider // Exports spans; nil without an exporter
 	shutdownFuncs []func(context.Context) error
 
 	// External integrations
@@ -36,8 +39,30 @@ type TelemetryProvider struct {
 	otlpClient       *OTLPClient
 }
 
-// NewTelemetryProvider creates a new telemetry provider with the given configuration
+// instrumentationName names the tracer and meter of the service
+const instrumentationName = "elevator-control-system"
+
+// NewTelemetryProvider creates a new telemetry provider with the given
+// configuration. Spans are exported to the OTLP collector when tracing and
+// OTLP are enabled.
 func NewTelemetryProvider(config *ObservabilityConfig, logger *slog.Logger) (*TelemetryProvider, error) {
+	var exporter sdktrace.SpanExporter
+	if config.Enabled && config.Tracing.Enabled && config.OTLP.Enabled {
+		var err error
+		exporter, err = newOTLPTraceExporter(context.Background(), &config.OTLP)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTLP trace exporter: %w", err)
+		}
+	}
+	return NewTelemetryProviderWithExporter(config, logger, exporter)
+}
+
+// NewTelemetryProviderWithExporter creates a telemetry provider whose spans
+// go to an exporter, such as an in-memory one in tests. With a nil exporter
+// spans are only recorded by a tracer provider installed elsewhere. The
+// provider becomes the global OpenTelemetry tracer provider, so components
+// tracing through otel.Tracer export through it as well.
+func NewTelemetryProviderWithExporter(config *ObservabilityConfig, logger *slog.Logger, exporter sdktrace.SpanExporter) (*TelemetryProvider, error) {
 	if !config.Enabled {
 		return &TelemetryProvider{
 			config: config,
@@ -51,8 +76,13 @@ func NewTelemetryProvider(config *ObservabilityConfig, logger *slog.Logger) (*Te
 	}
 
 	// Initialize basic OpenTelemetry components
-	provider.tracer = otel.Tracer("elevator-control-system")
-	provider.meter = otel.Meter("elevator-control-system")
+	if exporter != nil && config.Tracing.Enabled {
+		provider.spans = newTracerProvider(config, exporter)
+		otel.SetTracerProvider(provider.spans)
+		provider.shutdownFuncs = append(provider.shutdownFuncs, provider.spans.Shutdown)
+	}
+	provider.tracer = otel.Tracer(instrumentationName)
+	provider.meter = otel.Meter(instrumentationName)
 
 	// Set global propagator for distributed tracing
 	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
@@ -146,6 +176,14 @@ func (tp *TelemetryProvider) CreateSpan(ctx context.Context, name string, opts .
 	return tp.tracer.Start(ctx, name, opts...)
 }
 
+// ForceFlush exports the spans still waiting for their batch
+func (tp *TelemetryProvider) ForceFlush(ctx context.Context) error {
+	if tp.spans == nil {
+		return nil
+	}
+	return tp.spans.ForceFlush(ctx)
+}
+
 // RecordMetric records a metric value and sends to all configured backends
 func (tp *TelemetryProvider) RecordMetric(ctx context.Context, name string, value float64, labels map[string]string) {
 	// Send to DataDog if enabled
@@ -216,12 +254,15 @@ func (tp *TelemetryProvider) SendLog(level string, message string, fields map[st
 	}
 }
 
-// TelemetryMiddleware provides HTTP middleware for automatic instrumentation
+// TelemetryMiddleware provides HTTP middleware for automatic instrumentation.
+// The request span continues a trace the caller propagated in its headers.
 func (tp *TelemetryProvider) TelemetryMiddleware() func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Create span for request
-			ctx, span := tp.CreateSpan(r.Context(), "http_request",
+			ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+			ctx, span := tp.CreateSpan(ctx, "http_request",
+				trace.WithSpanKind(trace.SpanKindServer),
 				trace.WithAttributes(
 					attribute.String("http.method", r.Method),
 					attribute.String("http.url", r.URL.String()),
@@ -260,6 +301,9 @@ func (tp *TelemetryProvider) TelemetryMiddleware() func(http.Handler) http.Handl
 				attribute.Int("http.status_code", wrapped.statusCode),
 				attribute.Float64("http.duration_seconds", duration),
 			)
+			if wrapped.statusCode >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(wrapped.statusCode))
+			}
 
 			// Log request completion
 			logFields := map[string]interface{}{
@@ -348,6 +392,19 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.ResponseWriter.WriteHeader(code)
 }
 
+// Flush implements http.Flusher for streamed responses such as Server-Sent
+// Events
+func (rw *responseWriter) Flush() {
+	if flusher, ok := rw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the wrapped writer for http.ResponseController
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 // Hijack implements http.Hijacker interface for WebSocket support
 func (rw *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
 	if hijacker, ok := rw.ResponseWriter.(http.Hijacker); ok {
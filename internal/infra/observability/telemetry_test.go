@@ -11,7 +11,10 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -658,3 +661,77 @@ func TestTelemetryProvider_ContextualLogging(t *testing.T) {
 		assert.NotNil(t, span)
 	})
 }
+
+func TestTelemetryProvider_ExportsSpans(t *testing.T) {
+	config := &ObservabilityConfig{
+		Enabled:     true,
+		ServiceName: "test-service",
+		Tracing:     TracingConfig{Enabled: true, SamplingRatio: 1.0, Timeout: time.Second},
+	}
+	exporter := tracetest.NewInMemoryExporter()
+	previous := otel.GetTracerProvider()
+	provider, err := NewTelemetryProviderWithExporter(config, slog.Default(), exporter)
+	require.NoError(t, err)
+	defer func() {
+		otel.SetTracerProvider(previous)
+		require.NoError(t, provider.Shutdown(context.Background()))
+	}()
+
+	// Components tracing through the global provider export as well
+	var handlerSpan trace.SpanContext
+	handler := provider.TelemetryMiddleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, span := otel.Tracer("test").Start(r.Context(), "handler")
+		handlerSpan = span.SpanContext()
+		span.End()
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+
+	// The request span continues the caller's trace
+	parent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	req := httptest.NewRequest(http.MethodPost, "/v1/floors/request", nil)
+	req.Header.Set("traceparent", parent)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	require.NoError(t, provider.ForceFlush(context.Background()))
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+
+	request := spans[1]
+	assert.Equal(t, "http_request", request.Name)
+	assert.Equal(t, trace.SpanKindServer, request.SpanKind)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", request.SpanContext.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", request.Parent.SpanID().String())
+	assert.True(t, request.Parent.IsRemote())
+	assert.Equal(t, codes.Error, request.Status.Code)
+	assert.Equal(t, "test-service", resourceValue(request, "service.name"))
+
+	assert.Equal(t, "handler", spans[0].Name)
+	assert.Equal(t, handlerSpan, spans[0].SpanContext)
+	assert.Equal(t, request.SpanContext.SpanID(), spans[0].Parent.SpanID())
+}
+
+func resourceValue(span tracetest.SpanStub, key string) string {
+	for _, attr := range span.Resource.Attributes() {
+		if string(attr.Key) == key {
+			return attr.Value.AsString()
+		}
+	}
+	return ""
+}
+
+func TestNewOTLPTraceExporter(t *testing.T) {
+	ctx := context.Background()
+
+	exporter, err := newOTLPTraceExporter(ctx, &OTLPConfig{Endpoint: "http://localhost:4317", Timeout: time.Second})
+	require.NoError(t, err)
+	require.NoError(t, exporter.Shutdown(ctx))
+
+	exporter, err = newOTLPTraceExporter(ctx, &OTLPConfig{HTTPEndpoint: "collector:4318", Insecure: true, Compression: "gzip", Headers: "authorization=token", Timeout: time.Second})
+	require.NoError(t, err)
+	require.NoError(t, exporter.Shutdown(ctx))
+
+	_, err = newOTLPTraceExporter(ctx, &OTLPConfig{Endpoint: "http://", Timeout: time.Second})
+	assert.Error(t, err)
+
+	assert.Equal(t, map[string]string{"a": "1", "b": "x=y"}, parseHeaders("a=1, b=x=y,broken,=empty"))
+}
@@ -0,0 +1,122 @@
+package observability
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// defaultTracesPath is where OTLP/HTTP collectors accept spans
+const defaultTracesPath = "/v1/traces"
+
+// newTracerProvider creates the SDK tracer provider that samples traces at
+// the configured ratio and hands the spans to an exporter in batches. The
+// sampler follows the decision of a remote parent, so a trace started by a
+// caller is kept or dropped as a whole.
+func newTracerProvider(config *ObservabilityConfig, exporter sdktrace.SpanExporter) *sdktrace.TracerProvider {
+	attrs := make([]attribute.KeyValue, 0, len(config.GetResourceAttributes()))
+	for key, value := range config.GetResourceAttributes() {
+		attrs = append(attrs, attribute.String(key, value))
+	}
+
+	batchOptions := []sdktrace.BatchSpanProcessorOption{
+		sdktrace.WithExportTimeout(config.Tracing.Timeout),
+	}
+	if config.Tracing.BatchSize > 0 {
+		batchOptions = append(batchOptions, sdktrace.WithMaxExportBatchSize(config.Tracing.BatchSize))
+	}
+	if config.OTel.BatchTimeout > 0 {
+		batchOptions = append(batchOptions, sdktrace.WithBatchTimeout(config.OTel.BatchTimeout))
+	}
+
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithResource(resource.NewSchemaless(attrs...)),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.Tracing.SamplingRatio))),
+		sdktrace.WithBatcher(exporter, batchOptions...),
+	)
+}
+
+// newOTLPTraceExporter creates an exporter sending spans to the OTLP
+// collector: over HTTP when an HTTP endpoint is configured and over gRPC
+// otherwise. Endpoints without a scheme use TLS unless the connection is
+// configured as insecure.
+func newOTLPTraceExporter(ctx context.Context, config *OTLPConfig) (sdktrace.SpanExporter, error) {
+	headers := parseHeaders(config.Headers)
+
+	if config.HTTPEndpoint != "" {
+		endpoint, err := endpointURL(config.HTTPEndpoint, config.Insecure && !config.TLS)
+		if err != nil {
+			return nil, err
+		}
+		path := endpoint.Path
+		if path == "" || path == "/" {
+			path = defaultTracesPath
+		}
+		options := []otlptracehttp.Option{
+			otlptracehttp.WithEndpoint(endpoint.Host),
+			otlptracehttp.WithURLPath(path),
+			otlptracehttp.WithTimeout(config.Timeout),
+			otlptracehttp.WithHeaders(headers),
+		}
+		if endpoint.Scheme != "https" {
+			options = append(options, otlptracehttp.WithInsecure())
+		}
+		if config.Compression == "gzip" {
+			options = append(options, otlptracehttp.WithCompression(otlptracehttp.GzipCompression))
+		}
+		return otlptracehttp.New(ctx, options...)
+	}
+
+	endpoint, err := endpointURL(config.Endpoint, config.Insecure && !config.TLS)
+	if err != nil {
+		return nil, err
+	}
+	options := []otlptracegrpc.Option{
+		otlptracegrpc.WithEndpoint(endpoint.Host),
+		otlptracegrpc.WithTimeout(config.Timeout),
+		otlptracegrpc.WithHeaders(headers),
+	}
+	if endpoint.Scheme != "https" {
+		options = append(options, otlptracegrpc.WithInsecure())
+	}
+	if config.Compression == "gzip" {
+		options = append(options, otlptracegrpc.WithCompressor("gzip"))
+	}
+	return otlptracegrpc.New(ctx, options...)
+}
+
+// endpointURL parses a collector endpoint, defaulting the scheme of a bare
+// host and port
+func endpointURL(endpoint string, insecure bool) (*url.URL, error) {
+	if !strings.Contains(endpoint, "://") {
+		scheme := "https"
+		if insecure {
+			scheme = "http"
+		}
+		endpoint = scheme + "://" + endpoint
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid OTLP endpoint %q", endpoint)
+	}
+	return u, nil
+}
+
+// parseHeaders parses headers given as key1=value1,key2=value2
+func parseHeaders(headers string) map[string]string {
+	parsed := make(map[string]string)
+	for _, pair := range strings.Split(headers, ",") {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) == 2 && strings.TrimSpace(parts[0]) != "" {
+			parsed[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+		}
+	}
+	return parsed
+}
@@ -0,0 +1,35 @@
+package manager
+
+import (
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+	"github.com/slavakukuyev/elevator-go/internal/elevator"
+)
+
+// isLightlyLoaded reports whether a car has used at most half of its
+// overload threshold
+func isLightlyLoaded(e *elevator.Elevator) bool {
+	return e.Directions().DirectionsLength()*2 <= e.OverloadThreshold()
+}
+
+// lightlyLoadedElevator picks the car for a rider with mobility needs: the
+// lightly loaded car with the fewest pending requests, nearest to the pickup
+// floor on a tie. It returns nil when every suitable car is busier than
+// that, leaving the choice to the regular dispatch.
+func lightlyLoadedElevator(elevators []*elevator.Elevator, fromFloor, toFloor domain.Floor, call domain.CallOptions) *elevator.Elevator {
+	var best *elevator.Elevator
+	bestLoad, bestDistance := 0, 0
+
+	for _, e := range elevators {
+		if !e.Serves(call) || !e.IsRequestInRange(fromFloor, toFloor) ||
+			e.IsMarkedForDeletion() || e.IsFaulted() || !isLightlyLoaded(e) {
+			continue
+		}
+
+		load := e.Directions().DirectionsLength()
+		distance := e.CurrentFloor().Distance(fromFloor)
+		if best == nil || load < bestLoad || (load == bestLoad && distance < bestDistance) {
+			best, bestLoad, bestDistance = e, load, distance
+		}
+	}
+	return best
+}
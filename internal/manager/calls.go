@@ -0,0 +1,124 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/slavakukuyev/elevator-go/internal/circuitbreaker"
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+	"github.com/slavakukuyev/elevator-go/internal/elevator"
+	"github.com/slavakukuyev/elevator-go/internal/events"
+	"github.com/slavakukuyev/elevator-go/metrics"
+)
+
+// CarCall adds a destination pressed by a rider inside an elevator. Cars
+// that are being deleted or out of service still take it, since the rider
+// is already on board.
+func (m *Manager) CarCall(ctx context.Context, name string, floor int) (domain.Direction, error) {
+	el := m.GetElevator(name)
+	if el == nil {
+		return "", domain.NewNotFoundError("elevator not found", nil).
+			WithContext("name", name)
+	}
+
+	direction, err := el.CarCall(domain.NewFloor(floor))
+	if err != nil {
+		return "", err
+	}
+
+	metrics.IncRequestsTotal(m.building(), name, string(direction), "car_call")
+	m.logger.InfoContext(ctx, "car call accepted",
+		slog.String("elevator", name),
+		slog.Int("floor", floor),
+		slog.String("direction", string(direction)))
+	return direction, nil
+}
+
+// RequestHallCall assigns an up or down button press at a floor to an
+// elevator. The destination is unknown until the rider boards and makes a
+// car call, so the call is not tracked as a trip, and priority and
+// accessible calls, which depend on the destination, are rejected.
+func (m *Manager) RequestHallCall(ctx context.Context, floor int, direction domain.Direction, call domain.CallOptions) (*elevator.Elevator, error) {
+	if direction != domain.DirectionUp && direction != domain.DirectionDown {
+		return nil, domain.NewValidationError("hall call direction must be up or down", nil).
+			WithContext("direction", string(direction))
+	}
+
+	if call.IsPriority() || call.Accessible {
+		return nil, domain.NewValidationError("priority and accessible calls need a destination", nil).
+			WithContext("floor", floor)
+	}
+
+	requestCtx, cancel := context.WithTimeout(ctx, m.config().RequestTimeout)
+	defer cancel()
+
+	// The floor next to the pickup in the call's direction must exist for
+	// the car, which the range checks of the dispatcher verify
+	floorDomain := domain.NewFloor(floor)
+	next := domain.NewFloor(floor + 1)
+	if direction == domain.DirectionDown {
+		next = domain.NewFloor(floor - 1)
+	}
+
+	elevators := m.GetElevators()
+	if len(elevators) == 0 {
+		return nil, domain.NewInternalError("no elevators created yet", nil)
+	}
+
+	if !anyServes(elevators, call) {
+		return nil, domain.NewNotFoundError("no elevator of a type that serves this call", nil).
+			WithContext("cargo", call.Cargo).
+			WithContext("staff", call.Staff)
+	}
+
+	existing := true
+	el := hallCallElevator(elevators, direction, floorDomain, call)
+	if el == nil {
+		existing = false
+		var err error
+		el, err = m.dispatch(requestCtx, elevators, direction, floorDomain, next, call)
+		if err != nil {
+			m.logger.ErrorContext(requestCtx, "failed to choose elevator for hall call",
+				slog.Int("floor", floor),
+				slog.String("direction", string(direction)),
+				slog.String("error", err.Error()))
+			metrics.IncError("elevator_selection_failed", "manager")
+			if errors.Is(err, circuitbreaker.ErrOpen) {
+				return nil, err
+			}
+			return nil, domain.NewNotFoundError("no suitable elevator found", err).
+				WithContext("floor", floor).
+				WithContext("direction", string(direction))
+		}
+		el.HallCall(direction, floorDomain)
+		metrics.IncRequestsTotal(m.building(), el.Name(), string(direction), "success")
+	}
+
+	m.events.Publish(events.TypeRequestAssigned, el.Name(), map[string]any{
+		"from_floor": floor,
+		"direction":  string(direction),
+		"hall_call":  true,
+		"existing":   existing,
+	})
+	m.logger.InfoContext(requestCtx, "hall call has been approved",
+		slog.String("elevator", el.Name()),
+		slog.Int("floor", floor),
+		slog.String("direction", string(direction)),
+		slog.Bool("existing", existing))
+	return el, nil
+}
+
+// hallCallElevator returns a car that already stops at the floor in the
+// call's direction
+func hallCallElevator(elevators []*elevator.Elevator, direction domain.Direction, floor domain.Floor, call domain.CallOptions) *elevator.Elevator {
+	for _, e := range elevators {
+		if e.IsMarkedForDeletion() || e.IsFaulted() || e.IsReserved() || !e.Serves(call) {
+			continue
+		}
+		if e.HasStop(direction, floor) {
+			return e
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/slavakukuyev/elevator-go/internal/circuitbreaker"
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+	"github.com/slavakukuyev/elevator-go/internal/elevator"
+	"github.com/slavakukuyev/elevator-go/internal/infra/config"
+	"github.com/slavakukuyev/elevator-go/metrics"
+)
+
+// dispatchBreakerName labels the dispatch circuit breaker in metrics and logs
+const dispatchBreakerName = "dispatch"
+
+// newDispatchBreaker creates the circuit breaker that guards elevator
+// selection. Only internal errors such as selection timeouts count against
+// it; requests that no elevator can serve are a normal outcome.
+func (m *Manager) newDispatchBreaker(cfg *config.Config) *circuitbreaker.Breaker {
+	settings := cfg.CircuitBreakerSettings()
+	settings.IsFailure = isDispatchFailure
+
+	breaker := circuitbreaker.New(dispatchBreakerName, settings)
+	breaker.OnStateChange(m.onDispatchStateChange)
+	metrics.SetCircuitBreakerState(cfg.BuildingID, dispatchBreakerName, circuitbreaker.StateClosed.Value())
+	return breaker
+}
+
+func isDispatchFailure(err error) bool {
+	var domainErr *domain.DomainError
+	if errors.As(err, &domainErr) {
+		return domainErr.Type == domain.ErrTypeInternal
+	}
+	return true
+}
+
+func (m *Manager) onDispatchStateChange(name string, from, to circuitbreaker.State) {
+	metrics.SetCircuitBreakerState(m.building(), name, to.Value())
+	level := slog.LevelInfo
+	if to == circuitbreaker.StateOpen {
+		level = slog.LevelWarn
+	}
+	m.logger.Log(m.ctx, level, "dispatch circuit breaker state changed",
+		slog.String("from", from.String()),
+		slog.String("to", to.String()))
+}
+
+// dispatch chooses an elevator for a new request through the dispatch
+// circuit breaker
+func (m *Manager) dispatch(ctx context.Context, elevators []*elevator.Elevator, direction domain.Direction, fromFloor, toFloor domain.Floor, call domain.CallOptions) (*elevator.Elevator, error) {
+	var el *elevator.Elevator
+	err := m.dispatchBreaker.Execute(ctx, func() error {
+		var err error
+		el, err = m.chooseElevatorWithTimeout(ctx, elevators, direction, fromFloor, toFloor, call)
+		return err
+	})
+	if errors.Is(err, circuitbreaker.ErrOpen) {
+		return nil, domain.NewInternalError("elevator dispatch is temporarily unavailable", err).
+			WithContext("retry_after", m.dispatchBreaker.RetryAfter().String())
+	}
+	return el, err
+}
+
+// dispatchHealth describes the dispatch circuit breaker for health reports
+func (m *Manager) dispatchHealth() map[string]any {
+	state, failures, _ := m.dispatchBreaker.Metrics()
+	rate, requests := m.dispatchBreaker.FailureRate()
+	return map[string]any{
+		"circuit_breaker_state":           state.String(),
+		"circuit_breaker_failures":        failures,
+		"circuit_breaker_failure_rate":    rate,
+		"circuit_breaker_window_requests": requests,
+	}
+}
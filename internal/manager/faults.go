@@ -0,0 +1,84 @@
+package manager
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+	"github.com/slavakukuyev/elevator-go/internal/elevator"
+)
+
+// InjectFault activates a simulated hardware failure on an elevator
+func (m *Manager) InjectFault(ctx context.Context, name string, fault domain.Fault) (domain.Fault, error) {
+	el := m.GetElevator(name)
+	if el == nil {
+		return fault, domain.NewNotFoundError("elevator not found", nil).
+			WithContext("name", name)
+	}
+
+	injected, err := el.InjectFault(fault)
+	if err != nil {
+		return injected, err
+	}
+
+	m.logger.WarnContext(ctx, "fault injected into elevator",
+		slog.String("elevator", name),
+		slog.String("fault", string(injected.Type)))
+	return injected, nil
+}
+
+// ClearFault deactivates a fault of an elevator; an empty fault type clears
+// all of them
+func (m *Manager) ClearFault(ctx context.Context, name string, faultType domain.FaultType) error {
+	el := m.GetElevator(name)
+	if el == nil {
+		return domain.NewNotFoundError("elevator not found", nil).
+			WithContext("name", name)
+	}
+
+	if faultType == "" {
+		el.ClearFaults()
+	} else if !el.ClearFault(faultType) {
+		return domain.NewNotFoundError("fault is not active", nil).
+			WithContext("name", name).
+			WithContext("fault", string(faultType))
+	}
+
+	m.logger.InfoContext(ctx, "fault cleared on elevator",
+		slog.String("elevator", name),
+		slog.String("fault", string(faultType)))
+	return nil
+}
+
+// ElevatorFaults returns the active faults of an elevator
+func (m *Manager) ElevatorFaults(name string) ([]domain.Fault, error) {
+	el := m.GetElevator(name)
+	if el == nil {
+		return nil, domain.NewNotFoundError("elevator not found", nil).
+			WithContext("name", name)
+	}
+	return el.Faults(), nil
+}
+
+// applyConfiguredFaults injects the faults configured for a new elevator
+func (m *Manager) applyConfiguredFaults(el *elevator.Elevator) {
+	if !m.config().FaultInjectionEnabled || m.config().FaultInjections == "" {
+		return
+	}
+
+	configured, err := domain.ParseFaultInjections(m.config().FaultInjections)
+	if err != nil {
+		m.logger.Error("invalid fault injection configuration",
+			slog.String("error", err.Error()))
+		return
+	}
+
+	for _, fault := range configured[el.Name()] {
+		if _, err := el.InjectFault(fault); err != nil {
+			m.logger.Error("failed to inject configured fault",
+				slog.String("elevator", el.Name()),
+				slog.String("fault", string(fault.Type)),
+				slog.String("error", err.Error()))
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package manager
+
+import (
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+	"github.com/slavakukuyev/elevator-go/internal/elevator"
+	"github.com/slavakukuyev/elevator-go/metrics"
+)
+
+// IdlePolicy decides where cars without requests wait for the next one
+type IdlePolicy interface {
+	// Park returns the floor each idle car should move to, keyed by car
+	// name. Cars left out stay where they are; cars that are not idle are
+	// already on their way to a floor the policy chose and must be left out.
+	Park(now time.Time, cars []IdleCar) map[string]int
+}
+
+// IdleCar is a car the idle policy may place
+type IdleCar struct {
+	Name     string
+	Floor    int  // Where the car waits, or the floor it is being moved to
+	Idle     bool // False while the car is being moved to Floor
+	MinFloor int
+	MaxFloor int
+}
+
+// SetIdlePolicy places the idle cars where a policy says every interval
+// until the manager shuts down. It must be called at most once.
+func (m *Manager) SetIdlePolicy(policy IdlePolicy, interval time.Duration) {
+	go m.runIdlePolicy(policy, interval)
+}
+
+func (m *Manager) runIdlePolicy(policy IdlePolicy, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// moving holds the floor each car was sent to until it gets there
+	moving := make(map[string]int)
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case now := <-ticker.C:
+			m.applyIdlePolicy(policy, now, moving)
+		}
+	}
+}
+
+// applyIdlePolicy asks the policy where the idle cars should be and sends
+// them there
+func (m *Manager) applyIdlePolicy(policy IdlePolicy, now time.Time, moving map[string]int) {
+	elevators := make(map[string]*elevator.Elevator)
+	var cars []IdleCar
+	for _, el := range m.GetElevators() {
+		// Cars out of service or not serving ordinary calls are never placed
+		if el.IsMarkedForDeletion() || el.IsFaulted() || !el.Serves(domain.CallOptions{}) {
+			delete(moving, el.Name())
+			continue
+		}
+
+		car := IdleCar{
+			Name:     el.Name(),
+			Floor:    el.CurrentFloor().Value(),
+			Idle:     !el.HasPendingRequests(),
+			MinFloor: el.MinFloor().Value(),
+			MaxFloor: el.MaxFloor().Value(),
+		}
+		if target, ok := moving[car.Name]; ok {
+			if car.Idle {
+				delete(moving, car.Name)
+			} else {
+				car.Floor = target
+			}
+		} else if !car.Idle {
+			continue
+		}
+		elevators[car.Name] = el
+		cars = append(cars, car)
+	}
+	for name := range moving {
+		if _, ok := elevators[name]; !ok {
+			delete(moving, name)
+		}
+	}
+	if len(cars) == 0 {
+		return
+	}
+
+	targets := policy.Park(now, cars)
+	names := make([]string, 0, len(targets))
+	for name := range targets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		el, ok := elevators[name]
+		if !ok || el.HasPendingRequests() {
+			continue
+		}
+		floor := targets[name]
+		if _, err := el.Reposition(domain.NewFloor(floor)); err != nil {
+			m.logger.Warn("failed to reposition idle car",
+				slog.String("elevator", name),
+				slog.Int("floor", floor),
+				slog.String("error", err.Error()))
+			continue
+		}
+		moving[name] = floor
+		metrics.IncRepositionedCars(m.building(), name)
+		m.logger.Info("idle car sent ahead of demand",
+			slog.String("elevator", name),
+			slog.Int("floor", floor))
+	}
+}
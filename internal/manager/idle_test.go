@@ -0,0 +1,96 @@
+package manager
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slavakukuyev/elevator-go/internal/factory"
+)
+
+// floorPolicy parks every idle car at one floor and remembers the cars it
+// was shown
+type floorPolicy struct {
+	floor int
+
+	mu   sync.Mutex
+	seen [][]IdleCar
+}
+
+func (p *floorPolicy) Park(_ time.Time, cars []IdleCar) map[string]int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.seen = append(p.seen, cars)
+
+	targets := make(map[string]int)
+	for _, car := range cars {
+		if car.Idle && car.Floor != p.floor {
+			targets[car.Name] = p.floor
+		}
+	}
+	return targets
+}
+
+func (p *floorPolicy) last() []IdleCar {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.seen[len(p.seen)-1]
+}
+
+func TestManager_ApplyIdlePolicy(t *testing.T) {
+	t.Parallel()
+
+	cfg := buildManagerTestConfig()
+	manager := New(cfg, &factory.StandardElevatorFactory{})
+	defer manager.Shutdown()
+
+	ctx := context.Background()
+	require.NoError(t, manager.AddElevator(ctx, cfg, "Idle", 0, 9,
+		cfg.EachFloorDuration, cfg.OpenDoorDuration, cfg.DefaultOverloadThreshold))
+	require.NoError(t, manager.AddElevator(ctx, cfg, "Busy", 0, 9,
+		cfg.EachFloorDuration, cfg.OpenDoorDuration, cfg.DefaultOverloadThreshold))
+	busy := manager.GetElevator("Busy")
+	_, err := busy.CarCall(busy.MaxFloor())
+	require.NoError(t, err)
+
+	policy := &floorPolicy{floor: 4}
+	moving := make(map[string]int)
+	manager.applyIdlePolicy(policy, time.Now(), moving)
+
+	// Cars serving requests are not offered to the policy
+	assert.Equal(t, []IdleCar{{Name: "Idle", Floor: 0, Idle: true, MinFloor: 0, MaxFloor: 9}}, policy.last())
+	assert.Equal(t, map[string]int{"Idle": 4}, moving)
+	assert.True(t, manager.GetElevator("Idle").HasPendingRequests())
+
+	// A car on its way counts as being at its target
+	manager.applyIdlePolicy(policy, time.Now(), moving)
+	assert.Equal(t, []IdleCar{{Name: "Idle", Floor: 4, Idle: false, MinFloor: 0, MaxFloor: 9}}, policy.last())
+
+	require.Eventually(t, func() bool {
+		el := manager.GetElevator("Idle")
+		return !el.HasPendingRequests() && el.CurrentFloor().Value() == 4
+	}, 3*time.Second, 10*time.Millisecond)
+	manager.applyIdlePolicy(policy, time.Now(), moving)
+	assert.Empty(t, moving)
+}
+
+func TestManager_SetIdlePolicy(t *testing.T) {
+	t.Parallel()
+
+	cfg := buildManagerTestConfig()
+	manager := New(cfg, &factory.StandardElevatorFactory{})
+	defer manager.Shutdown()
+
+	require.NoError(t, manager.AddElevator(context.Background(), cfg, "Parked", 0, 9,
+		cfg.EachFloorDuration, cfg.OpenDoorDuration, cfg.DefaultOverloadThreshold))
+	manager.SetIdlePolicy(&floorPolicy{floor: 2}, 10*time.Millisecond)
+
+	require.Eventually(t, func() bool {
+		el := manager.GetElevator("Parked")
+		return !el.HasPendingRequests() && el.CurrentFloor().Value() == 2
+	}, 3*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,58 @@
+package manager
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+	"github.com/slavakukuyev/elevator-go/internal/elevator"
+	"github.com/slavakukuyev/elevator-go/metrics"
+)
+
+// newTrip describes a request assigned to an elevator at requestedAt, with
+// its estimates and the span of the request
+func newTrip(ctx context.Context, el *elevator.Elevator, direction domain.Direction, fromFloor, toFloor int, call domain.CallOptions, requestedAt time.Time) Trip {
+	wait, ride := estimateTrip(el, fromFloor, toFloor)
+	return Trip{
+		Elevator:             el.Name(),
+		Direction:            direction,
+		FromFloor:            fromFloor,
+		ToFloor:              toFloor,
+		CallOptions:          call,
+		CreatedAt:            requestedAt,
+		EstimatedWaitSeconds: wait.Seconds(),
+		EstimatedRideSeconds: ride.Seconds(),
+		requestSpan:          trace.SpanContextFromContext(ctx),
+	}
+}
+
+// estimateTrip estimates how long a request waits for a car and how long
+// its ride takes from the car's motion, leaving out the stops it makes on
+// the way
+func estimateTrip(el *elevator.Elevator, fromFloor, toFloor int) (wait, ride time.Duration) {
+	wait = el.TravelTime(fromFloor - el.CurrentFloor().Value())
+	ride = el.TravelTime(toFloor - fromFloor)
+	return wait, ride
+}
+
+// recordJourney observes the measured wait of a trip that was picked up and
+// the ride of one that was completed, along with how far each was from its
+// estimate
+func recordJourney(building string, trip Trip) {
+	switch trip.State {
+	case TripPickedUp:
+		wait := trip.PickedUpAt.Sub(trip.CreatedAt).Seconds()
+		metrics.RecordWaitTime(building, trip.Elevator, wait)
+		metrics.RecordETAError(building, trip.Elevator, "wait", wait-trip.EstimatedWaitSeconds)
+	case TripCompleted:
+		if trip.PickedUpAt == nil {
+			return
+		}
+		ride := trip.DroppedOffAt.Sub(*trip.PickedUpAt).Seconds()
+		metrics.RecordTravelTime(building, trip.Elevator, strconv.Itoa(abs(trip.ToFloor-trip.FromFloor)), ride)
+		metrics.RecordETAError(building, trip.Elevator, "ride", ride-trip.EstimatedRideSeconds)
+	}
+}
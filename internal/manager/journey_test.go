@@ -0,0 +1,69 @@
+package manager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+)
+
+func TestTripLedger_ServedRecordsStopTimes(t *testing.T) {
+	ledger := newTripLedger("")
+	requestedAt := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	trip := ledger.add(Trip{
+		Elevator:             "A",
+		Direction:            domain.DirectionUp,
+		FromFloor:            1,
+		ToFloor:              4,
+		CreatedAt:            requestedAt,
+		EstimatedWaitSeconds: 5,
+		EstimatedRideSeconds: 10,
+	})
+	assert.Equal(t, "trip-1", trip.ID)
+	assert.Equal(t, TripAssigned, trip.State)
+	assert.Equal(t, requestedAt, trip.CreatedAt)
+	assert.Equal(t, requestedAt, trip.UpdatedAt)
+	assert.Equal(t, 5.0, trip.EstimatedWaitSeconds)
+	assert.Equal(t, 10.0, trip.EstimatedRideSeconds)
+
+	// Stops of another car or direction leave the trip alone
+	assert.Empty(t, ledger.served("B", 1, domain.DirectionUp, requestedAt.Add(time.Second)))
+	assert.Empty(t, ledger.served("A", 1, domain.DirectionDown, requestedAt.Add(time.Second)))
+
+	pickedUpAt := requestedAt.Add(7 * time.Second)
+	changed := ledger.served("A", 1, domain.DirectionUp, pickedUpAt)
+	require.Len(t, changed, 1)
+	assert.Equal(t, TripPickedUp, changed[0].State)
+	assert.Equal(t, pickedUpAt, *changed[0].PickedUpAt)
+	assert.Nil(t, changed[0].DroppedOffAt)
+
+	droppedOffAt := pickedUpAt.Add(12 * time.Second)
+	changed = ledger.served("A", 4, domain.DirectionUp, droppedOffAt)
+	require.Len(t, changed, 1)
+	assert.Equal(t, TripCompleted, changed[0].State)
+	assert.Equal(t, pickedUpAt, *changed[0].PickedUpAt)
+	assert.Equal(t, droppedOffAt, *changed[0].DroppedOffAt)
+	assert.Equal(t, droppedOffAt, changed[0].UpdatedAt)
+}
+
+func TestTripLedger_Efficiency(t *testing.T) {
+	ledger := newTripLedger("")
+	assert.Equal(t, 0.0, ledger.efficiency())
+
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		ledger.add(Trip{Elevator: "A", Direction: domain.DirectionUp, FromFloor: 0, ToFloor: 2, CreatedAt: now})
+	}
+	ledger.add(Trip{Elevator: "B", Direction: domain.DirectionUp, FromFloor: 0, ToFloor: 2, CreatedAt: now})
+
+	ledger.served("A", 0, domain.DirectionUp, now)
+	ledger.served("A", 2, domain.DirectionUp, now)
+	// B's trip is still under way and does not count
+	assert.Equal(t, 1.0, ledger.efficiency())
+
+	ledger.elevatorRemoved("B")
+	assert.Equal(t, 0.75, ledger.efficiency())
+}
@@ -2,14 +2,21 @@ package manager
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"log/slog"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/slavakukuyev/elevator-go/internal/broadcast"
+	"github.com/slavakukuyev/elevator-go/internal/circuitbreaker"
 	"github.com/slavakukuyev/elevator-go/internal/constants"
 	"github.com/slavakukuyev/elevator-go/internal/domain"
 	"github.com/slavakukuyev/elevator-go/internal/elevator"
+	"github.com/slavakukuyev/elevator-go/internal/events"
 	"github.com/slavakukuyev/elevator-go/internal/factory"
 	"github.com/slavakukuyev/elevator-go/internal/infra/config"
 	"github.com/slavakukuyev/elevator-go/metrics"
@@ -22,27 +29,72 @@ type Manager struct {
 	logger    *slog.Logger
 	ctx       context.Context
 	cancel    context.CancelFunc
-	cfg       *config.Config
+	cfg       atomic.Pointer[config.Config]
+	events    *events.Bus
+	status    *broadcast.Broadcaster
+	trips     *tripLedger
+
+	// serviceLevels samples waits, rides and request outcomes for SLA rules
+	serviceLevels *serviceLevels
+
+	// dispatchBreaker stops elevator selection while it keeps failing
+	dispatchBreaker *circuitbreaker.Breaker
+
+	// tracer traces requests and trips, with the global tracer provider
+	// unless SetTracerProvider says otherwise
+	tracer trace.Tracer
 }
 
 func New(cfg *config.Config, factory factory.ElevatorFactory) *Manager {
 	ctx, cancel := context.WithCancel(context.Background())
-	return &Manager{
+	m := &Manager{
 		elevators: make([]*elevator.Elevator, 0),
 		factory:   factory,
 		logger:    slog.With(slog.String("component", constants.ComponentManager)),
 		ctx:       ctx,
 		cancel:    cancel,
-		cfg:       cfg,
+		events:    events.NewBus(cfg.EventHistorySize),
+		trips:     newTripLedger(cfg.BuildingID),
+
+		serviceLevels: newServiceLevels(),
+		tracer:        otel.Tracer(tracerName),
 	}
+	m.cfg.Store(cfg)
+	m.dispatchBreaker = m.newDispatchBreaker(cfg)
+	m.status = broadcast.New(m, cfg.StatusUpdateInterval, m.logger)
+	go m.status.Run(ctx)
+	go m.trackTrips(m.events.Subscribe(tripEventBuffer))
+	return m
+}
+
+// Broadcaster returns the broadcaster that pushes status updates to
+// connected clients whenever an elevator changes
+func (m *Manager) Broadcaster() *broadcast.Broadcaster {
+	return m.status
+}
+
+// Events returns the bus on which the manager and its elevators publish
+// system events
+func (m *Manager) Events() *events.Bus {
+	return m.events
 }
 
 func (m *Manager) AddElevator(ctx context.Context, cfg *config.Config, name string,
 	minFloor, maxFloor int,
 	eachFloorDuration, openDoorDuration time.Duration, overloadThreshold int) error {
+	return m.AddTypedElevator(ctx, cfg, domain.ElevatorTypePassenger, name,
+		minFloor, maxFloor,
+		eachFloorDuration, openDoorDuration, overloadThreshold)
+}
+
+// AddTypedElevator adds an elevator of the given type, which decides the
+// calls it may serve
+func (m *Manager) AddTypedElevator(ctx context.Context, cfg *config.Config, elevatorType domain.ElevatorType, name string,
+	minFloor, maxFloor int,
+	eachFloorDuration, openDoorDuration time.Duration, overloadThreshold int) error {
 
 	// Create a timeout context for elevator creation using configuration
-	createCtx, cancel := context.WithTimeout(ctx, m.cfg.CreateElevatorTimeout)
+	createCtx, cancel := context.WithTimeout(ctx, m.config().CreateElevatorTimeout)
 	defer cancel()
 
 	// Check if elevator with the same name already exists - optimized lock scope
@@ -55,7 +107,7 @@ func (m *Manager) AddElevator(ctx context.Context, cfg *config.Config, name stri
 		return err
 	}
 
-	e, err := m.factory.CreateElevator(cfg, name,
+	e, err := m.factory.CreateTypedElevator(cfg, elevatorType, name,
 		minFloor, maxFloor,
 		eachFloorDuration, openDoorDuration, overloadThreshold)
 	if err != nil {
@@ -76,13 +128,25 @@ func (m *Manager) AddElevator(ctx context.Context, cfg *config.Config, name stri
 			WithContext("maxFloor", maxFloor)
 	}
 
+	e.SetEventBus(m.events)
+	e.SetChangeNotifier(m.status.Notify)
+	m.applyConfiguredFaults(e)
+
 	// Add to the collection with minimal lock time
 	m.mu.Lock()
 	m.elevators = append(m.elevators, e)
 	m.mu.Unlock()
+	m.status.Notify()
+
+	m.events.Publish(events.TypeElevatorAdded, e.Name(), map[string]any{
+		"type":      string(e.Type()),
+		"min_floor": e.MinFloor().Value(),
+		"max_floor": e.MaxFloor().Value(),
+	})
 
 	m.logger.InfoContext(createCtx, "new elevator added to the management pool",
 		slog.String("elevator", e.Name()),
+		slog.String("type", string(e.Type())),
 		slog.Int("minFloor", e.MinFloor().Value()),
 		slog.Int("maxFloor", e.MaxFloor().Value()),
 	)
@@ -124,7 +188,7 @@ func (m *Manager) GetElevators() []*elevator.Elevator {
 // It marks the elevator for deletion, waits for current requests to finish, then removes it
 func (m *Manager) DeleteElevator(ctx context.Context, name string) error {
 	// Create a timeout context for elevator deletion using configuration
-	deleteCtx, cancel := context.WithTimeout(ctx, m.cfg.CreateElevatorTimeout) // Reuse elevator creation timeout for deletion
+	deleteCtx, cancel := context.WithTimeout(ctx, m.config().CreateElevatorTimeout) // Reuse elevator creation timeout for deletion
 	defer cancel()
 
 	// Find and mark the elevator for deletion atomically to prevent TOCTOU race
@@ -185,6 +249,11 @@ func (m *Manager) DeleteElevator(ctx context.Context, name string) error {
 
 	// Shutdown the elevator gracefully
 	elevator.Shutdown()
+	m.status.Notify()
+
+	m.events.Publish(events.TypeElevatorRemoved, name, map[string]any{
+		"forced": waitErr != nil,
+	})
 
 	if waitErr != nil {
 		m.logger.InfoContext(deleteCtx, "elevator forcefully deleted after timeout",
@@ -201,6 +270,27 @@ func (m *Manager) DeleteElevator(ctx context.Context, name string) error {
 	return nil
 }
 
+// DropElevators stops and removes every elevator at once, without waiting
+// for pending requests; their trips are cancelled. A cluster node uses it
+// when it hands the fleet over to another leader.
+func (m *Manager) DropElevators(ctx context.Context) {
+	m.mu.Lock()
+	dropped := m.elevators
+	m.elevators = make([]*elevator.Elevator, 0)
+	m.mu.Unlock()
+
+	for _, e := range dropped {
+		e.Shutdown()
+		m.events.Publish(events.TypeElevatorRemoved, e.Name(), map[string]any{
+			"forced": true,
+		})
+	}
+	m.status.Notify()
+
+	m.logger.InfoContext(ctx, "elevators dropped from the management pool",
+		slog.Int("elevators", len(dropped)))
+}
+
 // waitForElevatorToFinish waits for an elevator to complete all pending requests
 func (m *Manager) waitForElevatorToFinish(ctx context.Context, elevator *elevator.Elevator) error {
 	// Check every 100ms if the elevator has finished all requests
@@ -242,10 +332,53 @@ func (m *Manager) removeElevatorFromList(name string) error {
 }
 
 func (m *Manager) RequestElevator(ctx context.Context, fromFloor, toFloor int) (*elevator.Elevator, error) {
+	return m.RequestElevatorWithOptions(ctx, fromFloor, toFloor, domain.CallOptions{})
+}
+
+// RequestElevatorWithOptions requests an elevator for a call with special
+// needs; only cars whose type serves the call are considered
+func (m *Manager) RequestElevatorWithOptions(ctx context.Context, fromFloor, toFloor int, call domain.CallOptions) (*elevator.Elevator, error) {
+	el, _, err := m.requestElevator(ctx, fromFloor, toFloor, call)
+	return el, err
+}
+
+// requestElevator assigns a floor request to an elevator, records it as a
+// trip and counts whether it was taken towards the service levels
+func (m *Manager) requestElevator(ctx context.Context, fromFloor, toFloor int, call domain.CallOptions) (*elevator.Elevator, Trip, error) {
+	ctx, span := m.startRequestSpan(ctx, fromFloor, toFloor, call)
+	el, trip, err := m.assignRequest(ctx, fromFloor, toFloor, call)
+	endRequestSpan(span, trip, err)
+	m.serviceLevels.recordRequest(time.Now(), err)
+	if err != nil && !isValidationError(err) {
+		m.publishRequestRejected(fromFloor, toFloor, call, err)
+	}
+	return el, trip, err
+}
+
+// publishRequestRejected announces a valid floor request that no elevator
+// took
+func (m *Manager) publishRequestRejected(fromFloor, toFloor int, call domain.CallOptions, err error) {
+	direction := domain.DirectionUp
+	if toFloor < fromFloor {
+		direction = domain.DirectionDown
+	}
+	m.events.Publish(events.TypeRequestRejected, "", map[string]any{
+		"from_floor": fromFloor,
+		"to_floor":   toFloor,
+		"direction":  string(direction),
+		"priority":   call.Priority.String(),
+		"accessible": call.Accessible,
+		"reason":     err.Error(),
+	})
+}
+
+// assignRequest assigns a floor request to an elevator and records it as a
+// trip
+func (m *Manager) assignRequest(ctx context.Context, fromFloor, toFloor int, call domain.CallOptions) (*elevator.Elevator, Trip, error) {
 	start := time.Now()
 
 	// Create a timeout context for elevator request processing using configuration
-	requestCtx, cancel := context.WithTimeout(ctx, m.cfg.RequestTimeout)
+	requestCtx, cancel := context.WithTimeout(ctx, m.config().RequestTimeout)
 	defer cancel()
 
 	if toFloor == fromFloor {
@@ -259,7 +392,7 @@ func (m *Manager) RequestElevator(ctx context.Context, fromFloor, toFloor int) (
 
 		// Record validation error
 		metrics.IncError("validation_error", "manager")
-		return nil, err
+		return nil, Trip{}, err
 	}
 
 	direction := domain.DirectionUp
@@ -279,7 +412,19 @@ func (m *Manager) RequestElevator(ctx context.Context, fromFloor, toFloor int) (
 			slog.Int("fromFloor", fromFloor),
 			slog.Int("toFloor", toFloor),
 			slog.String("error", err.Error()))
-		return nil, err
+		return nil, Trip{}, err
+	}
+
+	if !anyServes(elevators, call) {
+		err := domain.NewNotFoundError("no elevator of a type that serves this call", nil).
+			WithContext("cargo", call.Cargo).
+			WithContext("staff", call.Staff)
+		m.logger.ErrorContext(requestCtx, "no compatible elevator type",
+			slog.Int("fromFloor", fromFloor),
+			slog.Int("toFloor", toFloor),
+			slog.Bool("cargo", call.Cargo),
+			slog.Bool("staff", call.Staff))
+		return nil, Trip{}, err
 	}
 
 	var el *elevator.Elevator
@@ -287,21 +432,23 @@ func (m *Manager) RequestElevator(ctx context.Context, fromFloor, toFloor int) (
 	if len(elevators) == 1 {
 		el = elevators[0]
 		if !el.IsRequestInRange(fromFloorDomain, toFloorDomain) {
-			return nil, domain.NewValidationError("requested floors out of range for the elevator", nil).
+			return nil, Trip{}, domain.NewValidationError("requested floors out of range for the elevator", nil).
 				WithContext("fromFloor", fromFloor).
 				WithContext("toFloor", toFloor)
 		}
 		// Skip elevator if it's marked for deletion
 		if el.IsMarkedForDeletion() {
-			return nil, domain.NewValidationError("elevator is being deleted and cannot accept new requests", nil).
+			return nil, Trip{}, domain.NewValidationError("elevator is being deleted and cannot accept new requests", nil).
 				WithContext("fromFloor", fromFloor).
 				WithContext("toFloor", toFloor)
 		}
 	}
 
-	if el == nil {
+	// Priority and accessible calls are not merged into an existing ordinary
+	// request, which would be served in sweep order without assistance
+	if el == nil && !call.IsPriority() && !call.Accessible {
 		// validate existing requests
-		if el = requestedElevator(elevators, direction, fromFloorDomain, toFloorDomain); el != nil {
+		if el = requestedElevator(elevators, direction, fromFloorDomain, toFloorDomain, call); el != nil {
 			m.logger.InfoContext(requestCtx, "found existing elevator request",
 				slog.String("elevator", el.Name()),
 				slog.Int("fromFloor", fromFloor),
@@ -309,14 +456,16 @@ func (m *Manager) RequestElevator(ctx context.Context, fromFloor, toFloor int) (
 
 			// Record existing request metrics
 			duration := time.Since(start)
-			metrics.RecordRequestDuration(el.Name(), "existing", duration.Seconds())
-			return el, nil
+			metrics.RecordRequestDuration(m.building(), el.Name(), "existing", duration.Seconds())
+			trip := m.trips.add(newTrip(requestCtx, el, direction, fromFloor, toFloor, call, start))
+			m.publishRequestAssigned(el, trip, true)
+			return el, trip, nil
 		}
 	}
 
 	if el == nil {
 		var err error
-		el, err = m.chooseElevatorWithTimeout(requestCtx, elevators, direction, fromFloorDomain, toFloorDomain)
+		el, err = m.traceDispatch(requestCtx, elevators, direction, fromFloorDomain, toFloorDomain, call)
 		if err != nil {
 			m.logger.ErrorContext(requestCtx, "failed to choose elevator",
 				slog.Int("fromFloor", fromFloor),
@@ -325,7 +474,10 @@ func (m *Manager) RequestElevator(ctx context.Context, fromFloor, toFloor int) (
 
 			// Record elevator selection failure
 			metrics.IncError("elevator_selection_failed", "manager")
-			return nil, domain.NewNotFoundError("no suitable elevator found", err).
+			if errors.Is(err, circuitbreaker.ErrOpen) {
+				return nil, Trip{}, err
+			}
+			return nil, Trip{}, domain.NewNotFoundError("no suitable elevator found", err).
 				WithContext("fromFloor", fromFloor).
 				WithContext("toFloor", toFloor)
 		}
@@ -339,41 +491,49 @@ func (m *Manager) RequestElevator(ctx context.Context, fromFloor, toFloor int) (
 			slog.Int("toFloor", toFloor))
 
 		metrics.IncError("nil_elevator_selection", "manager")
-		return nil, err
+		return nil, Trip{}, err
 	}
 
-	el.Request(direction, fromFloorDomain, toFloorDomain)
+	// Record the trip before the elevator can serve it. The measured wait
+	// starts when the request was registered.
+	trip := m.trips.add(newTrip(requestCtx, el, direction, fromFloor, toFloor, call, start))
+	el.RequestCall(direction, fromFloorDomain, toFloorDomain, call)
 
 	// Record successful request metrics
 	duration := time.Since(start)
 	directionStr := string(direction)
 
-	metrics.RecordRequestDuration(el.Name(), "success", duration.Seconds())
-	metrics.IncRequestsTotal(el.Name(), directionStr, "success")
-
-	// Calculate estimated wait time (simplified estimation)
-	currentFloor := el.CurrentFloor().Value()
-	waitTimeEstimate := float64(abs(fromFloor-currentFloor)) * 2.0 // 2 seconds per floor estimate
-	metrics.RecordWaitTime(el.Name(), waitTimeEstimate)
+	metrics.RecordRequestDuration(m.building(), el.Name(), "success", duration.Seconds())
+	metrics.IncRequestsTotal(m.building(), el.Name(), directionStr, "success")
+	if call.IsPriority() {
+		metrics.IncPriorityRequests(m.building(), el.Name(), call.Priority.String())
+	}
 
-	// Calculate travel time estimate
-	travelDistance := abs(toFloor - fromFloor)
-	travelTimeEstimate := float64(travelDistance) * 2.0 // 2 seconds per floor
-	metrics.RecordTravelTime(el.Name(), fmt.Sprintf("%d", travelDistance), travelTimeEstimate)
+	m.publishRequestAssigned(el, trip, false)
 
 	m.logger.InfoContext(requestCtx, "request has been approved",
 		slog.String("elevator", el.Name()),
 		slog.Int("fromFloor", fromFloor),
 		slog.Int("toFloor", toFloor),
+		slog.String("priority", call.Priority.String()),
+		slog.Bool("accessible", call.Accessible),
 		slog.Float64("processing_time_seconds", duration.Seconds()),
-		slog.Float64("estimated_wait_time", waitTimeEstimate))
-	return el, nil
+		slog.Float64("estimated_wait_time", trip.EstimatedWaitSeconds))
+	return el, trip, nil
 }
 
-func requestedElevator(elevators []*elevator.Elevator, direction domain.Direction, fromFloor, toFloor domain.Floor) *elevator.Elevator {
+// publishRequestAssigned announces which elevator serves a floor request
+func (m *Manager) publishRequestAssigned(el *elevator.Elevator, trip Trip, existing bool) {
+	data := tripEventData(trip)
+	data["existing"] = existing
+	m.events.Publish(events.TypeRequestAssigned, el.Name(), data)
+}
+
+func requestedElevator(elevators []*elevator.Elevator, direction domain.Direction, fromFloor, toFloor domain.Floor, call domain.CallOptions) *elevator.Elevator {
 	for _, e := range elevators {
-		// Skip elevators marked for deletion
-		if e.IsMarkedForDeletion() {
+		// Skip elevators marked for deletion, out of service after failures
+		// or of a type that does not serve the call
+		if e.IsMarkedForDeletion() || e.IsFaulted() || !e.Serves(call) {
 			continue
 		}
 
@@ -386,7 +546,7 @@ func requestedElevator(elevators []*elevator.Elevator, direction domain.Directio
 }
 
 // chooseElevatorWithTimeout wraps chooseElevator with timeout support
-func (m *Manager) chooseElevatorWithTimeout(ctx context.Context, elevators []*elevator.Elevator, requestedDirection domain.Direction, fromFloor, toFloor domain.Floor) (*elevator.Elevator, error) {
+func (m *Manager) chooseElevatorWithTimeout(ctx context.Context, elevators []*elevator.Elevator, requestedDirection domain.Direction, fromFloor, toFloor domain.Floor, call domain.CallOptions) (*elevator.Elevator, error) {
 	type result struct {
 		elevator *elevator.Elevator
 		err      error
@@ -395,7 +555,7 @@ func (m *Manager) chooseElevatorWithTimeout(ctx context.Context, elevators []*el
 	resultCh := make(chan result, 1)
 
 	go func() {
-		e, err := m.chooseElevator(elevators, requestedDirection, fromFloor, toFloor)
+		e, err := m.chooseElevator(elevators, requestedDirection, fromFloor, toFloor, call)
 		resultCh <- result{elevator: e, err: err}
 	}()
 
@@ -411,12 +571,32 @@ func (m *Manager) chooseElevatorWithTimeout(ctx context.Context, elevators []*el
 	}
 }
 
-func (m *Manager) chooseElevator(elevators []*elevator.Elevator, requestedDirection domain.Direction, fromFloor, toFloor domain.Floor) (*elevator.Elevator, error) {
+// chooseElevator picks the best elevator for a request among the cars whose
+// type serves the call. Emergency calls reserve a car of their own, and cars
+// reserved that way are left out for every other call. Accessible calls
+// prefer lightly loaded cars.
+func (m *Manager) chooseElevator(elevators []*elevator.Elevator, requestedDirection domain.Direction, fromFloor, toFloor domain.Floor, call domain.CallOptions) (*elevator.Elevator, error) {
+	if call.Priority == domain.PriorityEmergency {
+		return m.reserveElevator(elevators, fromFloor, toFloor, call)
+	}
+	elevators = unreserved(elevators)
+
+	if call.Accessible {
+		if e := lightlyLoadedElevator(elevators, fromFloor, toFloor, call); e != nil {
+			return e, nil
+		}
+	}
+
 	elevatorsWaiting := make(map[*elevator.Elevator]domain.Floor)
 	elevatorsByDirection := make(map[*elevator.Elevator]domain.Direction)
 
 	// case when elevator is waiting to start
 	for _, e := range elevators {
+		// Freight and service cars are reserved for their calls
+		if !e.Serves(call) {
+			continue
+		}
+
 		if !e.IsRequestInRange(fromFloor, toFloor) {
 			continue
 		}
@@ -426,6 +606,11 @@ func (m *Manager) chooseElevator(elevators []*elevator.Elevator, requestedDirect
 			continue
 		}
 
+		// Skip elevators whose circuit breaker opened after repeated failures
+		if e.IsFaulted() {
+			continue
+		}
+
 		d := e.CurrentDirection()
 		if d == domain.DirectionIdle {
 			elevatorsWaiting[e] = e.CurrentFloor()
@@ -538,6 +723,17 @@ func (m *Manager) chooseElevator(elevators []*elevator.Elevator, requestedDirect
 		WithContext("toFloor", toFloor.Value())
 }
 
+// anyServes reports whether any of the elevators has a type that serves the
+// call
+func anyServes(elevators []*elevator.Elevator, call domain.CallOptions) bool {
+	for _, e := range elevators {
+		if e.Serves(call) {
+			return true
+		}
+	}
+	return false
+}
+
 // isElevatorOverloaded checks if an elevator has too many requests to serve efficiently
 // Uses the elevator's configured overload threshold (defaults to 12 if not specified)
 func isElevatorOverloaded(e *elevator.Elevator) bool {
@@ -634,9 +830,21 @@ func elevatorWithMinRequestsByDirection(elevators []*elevator.Elevator, directio
 	return el
 }
 
+// StatusSnapshot returns the current status of every elevator keyed by name,
+// including elevators that are being deleted
+func (m *Manager) StatusSnapshot() map[string]domain.ElevatorStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	status := make(map[string]domain.ElevatorStatus, len(m.elevators))
+	for _, e := range m.elevators {
+		status[e.Name()] = e.GetStatus()
+	}
+	return status
+}
+
 func (m *Manager) GetStatus() (map[string]any, error) {
 	// Use a timeout for status collection to prevent hanging
-	ctx, cancel := context.WithTimeout(m.ctx, m.cfg.HealthCheckTimeout)
+	ctx, cancel := context.WithTimeout(m.ctx, m.config().HealthCheckTimeout)
 	defer cancel()
 
 	type statusResult struct {
@@ -669,7 +877,7 @@ func (m *Manager) GetStatus() (map[string]any, error) {
 // GetHealthStatus returns health status for all elevators including circuit breaker metrics
 func (m *Manager) GetHealthStatus() (map[string]any, error) {
 	// Use a timeout for health status collection
-	ctx, cancel := context.WithTimeout(m.ctx, m.cfg.HealthCheckTimeout)
+	ctx, cancel := context.WithTimeout(m.ctx, m.config().HealthCheckTimeout)
 	defer cancel()
 
 	type healthResult struct {
@@ -708,6 +916,7 @@ func (m *Manager) GetHealthStatus() (map[string]any, error) {
 		}
 
 		health["elevators"] = elevatorHealth
+		health["dispatch"] = m.dispatchHealth()
 		health["total_elevators"] = totalElevators
 		health["elevators_count"] = totalElevators // OpenAPI spec compatibility
 		health["healthy_elevators"] = healthyElevators
@@ -730,6 +939,11 @@ func (m *Manager) GetHealthStatus() (map[string]any, error) {
 
 // GetMetrics returns operational metrics for monitoring
 func (m *Manager) GetMetrics() map[string]any {
+	// The ledger is read before m.mu is taken. The lock order is trips.mu
+	// before m.mu: reconcile (through elevatorOf) and reassignTrips call
+	// into the manager while holding trips.mu.
+	efficiency := m.trips.efficiency()
+
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -737,8 +951,6 @@ func (m *Manager) GetMetrics() map[string]any {
 	totalUpRequests := 0
 	totalDownRequests := 0
 	healthyElevators := 0
-	totalSuccessfulRequests := 0
-	totalFailedRequests := 0
 
 	for _, e := range m.elevators {
 		// Skip elevators that are being deleted
@@ -754,40 +966,18 @@ func (m *Manager) GetMetrics() map[string]any {
 		totalDownRequests += downRequests
 
 		// Update Prometheus metrics for each elevator
-		metrics.SetCurrentFloor(e.Name(), float64(e.CurrentFloor().Value()))
-		metrics.SetPendingRequests(e.Name(), "up", float64(upRequests))
-		metrics.SetPendingRequests(e.Name(), "down", float64(downRequests))
+		metrics.SetCurrentFloor(m.building(), e.Name(), float64(e.CurrentFloor().Value()))
+		metrics.SetPendingRequests(m.building(), e.Name(), "up", float64(upRequests))
+		metrics.SetPendingRequests(m.building(), e.Name(), "down", float64(downRequests))
 
 		// Check elevator health
 		healthMetrics := e.GetHealthMetrics()
 		if isHealthy, ok := healthMetrics["is_healthy"].(bool); ok && isHealthy {
 			healthyElevators++
 		}
-
-		// Update circuit breaker metrics
-		if cbState, ok := healthMetrics["circuit_breaker_state"].(string); ok {
-			stateValue := 0.0 // closed
-			switch cbState {
-			case "half-open":
-				stateValue = 1.0
-			case "open":
-				stateValue = 2.0
-			default:
-				stateValue = 0.0
-			}
-			metrics.SetCircuitBreakerState(e.Name(), stateValue)
-		}
 	}
 	totalRequests = totalUpRequests + totalDownRequests
 
-	// Calculate efficiency metrics
-	if totalRequests > 0 {
-		efficiency := float64(totalSuccessfulRequests) / float64(totalRequests+totalSuccessfulRequests+totalFailedRequests)
-		for _, e := range m.elevators {
-			metrics.SetElevatorEfficiency(e.Name(), efficiency)
-		}
-	}
-
 	// Update system health metrics
 	// System is healthy if there are no elevators (initial state) or if at least one elevator is working
 	systemHealthy := len(m.elevators) == 0 || healthyElevators > 0
@@ -803,7 +993,7 @@ func (m *Manager) GetMetrics() map[string]any {
 		"total_up_requests":   totalUpRequests,
 		"total_down_requests": totalDownRequests,
 		"average_load":        avgLoad,
-		"system_efficiency":   float64(totalSuccessfulRequests) / float64(max(totalRequests+totalSuccessfulRequests+totalFailedRequests, 1)),
+		"system_efficiency":   efficiency,
 		"performance_score":   m.calculatePerformanceScore(avgLoad, float64(healthyElevators)/float64(max(len(m.elevators), 1))),
 		"timestamp":           time.Now().Format(time.RFC3339), // OpenAPI spec expects date-time format
 	}
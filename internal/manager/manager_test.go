@@ -11,8 +11,11 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/slavakukuyev/elevator-go/internal/broadcast"
+	"github.com/slavakukuyev/elevator-go/internal/circuitbreaker"
 	"github.com/slavakukuyev/elevator-go/internal/domain"
 	"github.com/slavakukuyev/elevator-go/internal/elevator"
+	"github.com/slavakukuyev/elevator-go/internal/events"
 	"github.com/slavakukuyev/elevator-go/internal/factory"
 	"github.com/slavakukuyev/elevator-go/internal/infra/config"
 )
@@ -702,3 +705,653 @@ func TestManager_GetMetrics_ZeroElevators(t *testing.T) {
 		assert.NotNil(t, metrics["system_efficiency"], "System efficiency should be present")
 	})
 }
+
+func TestManager_PublishesEvents(t *testing.T) {
+	t.Parallel()
+
+	cfg := buildManagerTestConfig()
+	manager := New(cfg, &factory.StandardElevatorFactory{})
+	defer manager.Shutdown()
+
+	sub := manager.Events().Subscribe(16)
+	defer sub.Close()
+
+	nextEvent := func(eventType events.Type) events.Event {
+		t.Helper()
+		timeout := time.After(2 * time.Second)
+		for {
+			select {
+			case event := <-sub.C:
+				if event.Type == eventType {
+					return event
+				}
+			case <-timeout:
+				t.Fatalf("timed out waiting for %s event", eventType)
+			}
+		}
+	}
+
+	ctx := context.Background()
+	require.NoError(t, manager.AddElevator(ctx, cfg, "Events", 0, 5,
+		cfg.EachFloorDuration, cfg.OpenDoorDuration, cfg.DefaultOverloadThreshold))
+
+	added := nextEvent(events.TypeElevatorAdded)
+	assert.Equal(t, "Events", added.Elevator)
+	assert.Equal(t, 5, added.Data["max_floor"])
+
+	_, err := manager.RequestElevator(ctx, 1, 3)
+	require.NoError(t, err)
+
+	assigned := nextEvent(events.TypeRequestAssigned)
+	assert.Equal(t, "Events", assigned.Elevator)
+	assert.Equal(t, 1, assigned.Data["from_floor"])
+	assert.Equal(t, 3, assigned.Data["to_floor"])
+	assert.Equal(t, false, assigned.Data["existing"])
+
+	arrived := nextEvent(events.TypeFloorArrived)
+	assert.Equal(t, 1, arrived.Data["floor"])
+	arrived = nextEvent(events.TypeFloorArrived)
+	assert.Equal(t, 3, arrived.Data["floor"])
+
+	require.NoError(t, manager.DeleteElevator(ctx, "Events"))
+	removed := nextEvent(events.TypeElevatorRemoved)
+	assert.Equal(t, "Events", removed.Elevator)
+	assert.Equal(t, false, removed.Data["forced"])
+}
+
+func TestManager_BroadcastsStatusChanges(t *testing.T) {
+	t.Parallel()
+
+	cfg := buildManagerTestConfig()
+	cfg.StatusUpdateInterval = time.Hour // only notifications may trigger updates
+	manager := New(cfg, &factory.StandardElevatorFactory{})
+	defer manager.Shutdown()
+
+	sub := manager.Broadcaster().Subscribe(64)
+	defer sub.Close()
+
+	nextUpdate := func(match func(*broadcast.Update) bool) *broadcast.Update {
+		t.Helper()
+		timeout := time.After(3 * time.Second)
+		for {
+			select {
+			case update := <-sub.C:
+				if match(update) {
+					return update
+				}
+			case <-timeout:
+				t.Fatal("timed out waiting for status update")
+			}
+		}
+	}
+
+	ctx := context.Background()
+	require.NoError(t, manager.AddElevator(ctx, cfg, "Push", 0, 5,
+		cfg.EachFloorDuration, cfg.OpenDoorDuration, cfg.DefaultOverloadThreshold))
+
+	added := nextUpdate(func(u *broadcast.Update) bool { return len(u.Changed) > 0 })
+	assert.Equal(t, []string{"Push"}, added.Changed)
+
+	_, err := manager.RequestElevator(ctx, 0, 3)
+	require.NoError(t, err)
+
+	arrived := nextUpdate(func(u *broadcast.Update) bool {
+		return u.Status["Push"].CurrentFloor.Value() == 3
+	})
+	assert.Greater(t, arrived.Seq, added.Seq)
+
+	require.NoError(t, manager.DeleteElevator(ctx, "Push"))
+	removed := nextUpdate(func(u *broadcast.Update) bool { return len(u.Removed) > 0 })
+	assert.Equal(t, []string{"Push"}, removed.Removed)
+	assert.Empty(t, removed.Status)
+}
+
+func TestManager_TripLifecycle(t *testing.T) {
+	t.Parallel()
+
+	cfg := buildManagerTestConfig()
+	manager := New(cfg, &factory.StandardElevatorFactory{})
+	defer manager.Shutdown()
+
+	sub := manager.Events().Subscribe(64)
+	defer sub.Close()
+
+	ctx := context.Background()
+	require.NoError(t, manager.AddElevator(ctx, cfg, "Trips", 0, 5,
+		cfg.EachFloorDuration, cfg.OpenDoorDuration, cfg.DefaultOverloadThreshold))
+
+	trip, err := manager.RequestTrip(ctx, 1, 3)
+	require.NoError(t, err)
+	assert.NotEmpty(t, trip.ID)
+	assert.Equal(t, "Trips", trip.Elevator)
+	assert.Equal(t, TripAssigned, trip.State)
+	assert.Equal(t, domain.DirectionUp, trip.Direction)
+
+	var states []string
+	timeout := time.After(3 * time.Second)
+	for len(states) < 3 {
+		select {
+		case event := <-sub.C:
+			if event.Data["trip_id"] == trip.ID {
+				states = append(states, event.Data["state"].(string))
+			}
+		case <-timeout:
+			t.Fatalf("timed out waiting for trip events, got %v", states)
+		}
+	}
+	assert.Equal(t, []string{"assigned", "picked_up", "completed"}, states)
+
+	finished, ok := manager.GetTrip(trip.ID)
+	require.True(t, ok)
+	assert.Equal(t, TripCompleted, finished.State)
+	assert.True(t, finished.IsFinished())
+	require.NotNil(t, finished.PickedUpAt)
+	assert.False(t, finished.PickedUpAt.Before(finished.CreatedAt))
+	require.NotNil(t, finished.DroppedOffAt)
+	assert.False(t, finished.DroppedOffAt.Before(*finished.PickedUpAt))
+	assert.Equal(t, *finished.DroppedOffAt, finished.UpdatedAt)
+	assert.Equal(t, manager.GetElevator("Trips").TravelTime(2).Seconds(), finished.EstimatedRideSeconds)
+	assert.Equal(t, 1.0, manager.GetMetrics()["system_efficiency"])
+
+	// Finished trips cannot be cancelled
+	_, err = manager.CancelRequest(ctx, trip.ID)
+	var domainErr *domain.DomainError
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, domain.ErrTypeConflict, domainErr.Type)
+}
+
+func TestManager_CancelRequest(t *testing.T) {
+	t.Parallel()
+
+	cfg := buildManagerTestConfig()
+	manager := New(cfg, &factory.StandardElevatorFactory{})
+	defer manager.Shutdown()
+
+	ctx := context.Background()
+	// Slow floors give the test time to cancel before pickup
+	require.NoError(t, manager.AddElevator(ctx, cfg, "Cancel", 0, 9,
+		200*time.Millisecond, cfg.OpenDoorDuration, cfg.DefaultOverloadThreshold))
+
+	trip, err := manager.RequestTrip(ctx, 8, 9)
+	require.NoError(t, err)
+
+	cancelled, err := manager.CancelRequest(ctx, trip.ID)
+	require.NoError(t, err)
+	assert.Equal(t, TripCancelled, cancelled.State)
+
+	// The elevator drops the pickup and goes idle instead of travelling to 8
+	el := manager.GetElevator("Cancel")
+	assert.Eventually(t, func() bool {
+		return el.CurrentDirection() == domain.DirectionIdle
+	}, 2*time.Second, 20*time.Millisecond)
+	assert.Less(t, el.CurrentFloor().Value(), 8)
+	assert.False(t, el.HasPendingRequests())
+
+	_, err = manager.CancelRequest(ctx, "trip-unknown")
+	var domainErr *domain.DomainError
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, domain.ErrTypeNotFound, domainErr.Type)
+}
+
+func TestManager_ReconcilesTripsAfterLedgerOverflow(t *testing.T) {
+	t.Parallel()
+
+	cfg := buildManagerTestConfig()
+	manager := New(cfg, &factory.StandardElevatorFactory{})
+	defer manager.Shutdown()
+
+	ctx := context.Background()
+	require.NoError(t, manager.AddElevator(ctx, cfg, "Overflow", 0, 9,
+		cfg.EachFloorDuration, cfg.OpenDoorDuration, cfg.DefaultOverloadThreshold))
+	trip, err := manager.RequestTrip(ctx, 2, 5)
+	require.NoError(t, err)
+
+	// Stall the ledger and flood the bus so its subscription overflows and
+	// the events of the trip are lost
+	manager.trips.mu.Lock()
+	for i := 0; i < tripEventBuffer+2; i++ {
+		manager.events.Publish(events.TypeStopServed, "Elsewhere", map[string]any{"floor": 0, "direction": "up"})
+	}
+	el := manager.GetElevator("Overflow")
+	require.Eventually(t, func() bool {
+		return !el.HasPendingRequests() && el.CurrentFloor().Value() == 5
+	}, 3*time.Second, 10*time.Millisecond)
+	manager.trips.mu.Unlock()
+
+	// The ledger rebuilds the trip from the stops the car has left
+	require.Eventually(t, func() bool {
+		reconciled, ok := manager.GetTrip(trip.ID)
+		return ok && reconciled.State == TripCompleted
+	}, 2*time.Second, 10*time.Millisecond)
+	reconciled, _ := manager.GetTrip(trip.ID)
+	assert.NotNil(t, reconciled.PickedUpAt)
+	assert.NotNil(t, reconciled.DroppedOffAt)
+}
+
+func TestManager_ReconcileGivesUpOnStuckTrips(t *testing.T) {
+	t.Parallel()
+
+	cfg := buildManagerTestConfig()
+	manager := New(cfg, &factory.StandardElevatorFactory{})
+	defer manager.Shutdown()
+
+	ctx := context.Background()
+	require.NoError(t, manager.AddElevator(ctx, cfg, "Stuck", 0, 9,
+		cfg.EachFloorDuration, cfg.OpenDoorDuration, cfg.DefaultOverloadThreshold))
+
+	// The car holds the pickup of a request but never moves for it
+	el := manager.GetElevator("Stuck")
+	el.Directions().Append(domain.DirectionUp, domain.NewFloor(3), domain.NewFloor(6))
+
+	now := time.Now()
+	longAgo := now.Add(-2 * unfinishedTripTTL)
+	stuck := manager.trips.add(Trip{Elevator: "Stuck", Direction: domain.DirectionUp, FromFloor: 3, ToFloor: 6, CreatedAt: longAgo})
+	recent := manager.trips.add(Trip{Elevator: "Stuck", Direction: domain.DirectionUp, FromFloor: 3, ToFloor: 6, CreatedAt: now.Add(-time.Minute)})
+	served := manager.trips.add(Trip{Elevator: "Stuck", Direction: domain.DirectionUp, FromFloor: 1, ToFloor: 2, CreatedAt: now.Add(-time.Minute)})
+	gone := manager.trips.add(Trip{Elevator: "Gone", Direction: domain.DirectionDown, FromFloor: 5, ToFloor: 0, CreatedAt: now.Add(-time.Minute)})
+
+	manager.reconcileTrips(now, now)
+
+	states := map[string]TripState{}
+	for _, trip := range []Trip{stuck, recent, served, gone} {
+		reconciled, ok := manager.GetTrip(trip.ID)
+		require.True(t, ok)
+		states[trip.ID] = reconciled.State
+	}
+	assert.Equal(t, TripUnknown, states[stuck.ID])
+	assert.Equal(t, TripAssigned, states[recent.ID])
+	assert.Equal(t, TripCompleted, states[served.ID])
+	assert.Equal(t, TripCancelled, states[gone.ID])
+
+	// Trips given up on count as finished but not towards efficiency
+	assert.Equal(t, 0.5, manager.trips.efficiency())
+}
+
+func TestManager_ReassignsTripsFromFaultedElevator(t *testing.T) {
+	t.Parallel()
+
+	cfg := buildManagerTestConfig()
+	cfg.CircuitBreakerMaxFailures = 1
+	cfg.CircuitBreakerResetTimeout = time.Minute
+	manager := New(cfg, &factory.StandardElevatorFactory{})
+	defer manager.Shutdown()
+
+	ctx := context.Background()
+	for _, name := range []string{"A", "B"} {
+		require.NoError(t, manager.AddElevator(ctx, cfg, name, 0, 9,
+			50*time.Millisecond, cfg.OpenDoorDuration, cfg.DefaultOverloadThreshold))
+	}
+
+	sub := manager.Events().Subscribe(100)
+	defer sub.Close()
+
+	trip, err := manager.RequestTrip(ctx, 6, 9)
+	require.NoError(t, err)
+	failed := trip.Elevator
+	_, err = manager.InjectFault(ctx, failed, domain.Fault{Type: domain.FaultStuckBetweenFloors})
+	require.NoError(t, err)
+
+	// The stuck car trips its breaker and hands the waiting trip over
+	var reassigned events.Event
+	require.Eventually(t, func() bool {
+		select {
+		case event := <-sub.C:
+			if event.Type == events.TypeRequestAssigned && event.Data["reassigned_from"] == failed {
+				reassigned = event
+				return true
+			}
+		default:
+		}
+		return false
+	}, 3*time.Second, 5*time.Millisecond)
+	assert.Equal(t, trip.ID, reassigned.Data["trip_id"])
+	assert.NotEqual(t, failed, reassigned.Elevator)
+	assert.True(t, manager.GetElevator(failed).IsFaulted())
+
+	// New requests avoid the faulted car as well
+	el, err := manager.RequestElevator(ctx, 2, 4)
+	require.NoError(t, err)
+	assert.Equal(t, reassigned.Elevator, el.Name())
+
+	assert.Eventually(t, func() bool {
+		current, ok := manager.GetTrip(trip.ID)
+		return ok && current.State == TripCompleted && current.Elevator == reassigned.Elevator
+	}, 5*time.Second, 20*time.Millisecond)
+
+	faults, err := manager.ElevatorFaults(failed)
+	require.NoError(t, err)
+	assert.Len(t, faults, 1)
+	require.NoError(t, manager.ClearFault(ctx, failed, ""))
+	assert.Error(t, manager.ClearFault(ctx, failed, domain.FaultDoorFailure))
+	_, err = manager.InjectFault(ctx, "missing", domain.Fault{Type: domain.FaultDoorFailure})
+	assert.Error(t, err)
+}
+
+func TestManager_DispatchCircuitBreaker(t *testing.T) {
+	t.Parallel()
+
+	cfg := buildManagerTestConfig()
+	cfg.CircuitBreakerMaxFailures = 1
+	cfg.CircuitBreakerResetTimeout = time.Minute
+	manager := New(cfg, &factory.StandardElevatorFactory{})
+	defer manager.Shutdown()
+
+	ctx := context.Background()
+	for _, name := range []string{"A", "B"} {
+		require.NoError(t, manager.AddElevator(ctx, cfg, name, 0, 5,
+			cfg.EachFloorDuration, cfg.OpenDoorDuration, cfg.DefaultOverloadThreshold))
+	}
+
+	// Requests no elevator can serve do not count against the breaker
+	for i := 0; i < 3; i++ {
+		_, err := manager.RequestElevator(ctx, 7, 9)
+		require.Error(t, err)
+	}
+	assert.Equal(t, circuitbreaker.StateClosed, manager.dispatchBreaker.State())
+
+	// Internal selection failures do
+	err := manager.dispatchBreaker.Execute(ctx, func() error {
+		return domain.NewInternalError("elevator selection timed out", context.DeadlineExceeded)
+	})
+	require.Error(t, err)
+	assert.Equal(t, circuitbreaker.StateOpen, manager.dispatchBreaker.State())
+
+	_, err = manager.RequestElevator(ctx, 1, 3)
+	var domainErr *domain.DomainError
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, domain.ErrTypeInternal, domainErr.Type)
+	assert.ErrorIs(t, err, circuitbreaker.ErrOpen)
+
+	health, err := manager.GetHealthStatus()
+	require.NoError(t, err)
+	dispatch, ok := health["dispatch"].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, "open", dispatch["circuit_breaker_state"])
+}
+
+func TestManager_ApplyConfig(t *testing.T) {
+	t.Parallel()
+
+	cfg := buildManagerTestConfig()
+	manager := New(cfg, &factory.StandardElevatorFactory{})
+	defer manager.Shutdown()
+
+	ctx := context.Background()
+	require.NoError(t, manager.AddElevator(ctx, cfg, "Default", 0, 9,
+		cfg.EachFloorDuration, cfg.OpenDoorDuration, cfg.DefaultOverloadThreshold))
+	require.NoError(t, manager.AddElevator(ctx, cfg, "Custom", 0, 9,
+		cfg.EachFloorDuration, cfg.OpenDoorDuration, cfg.DefaultOverloadThreshold+5))
+
+	updated := *cfg
+	updated.DefaultOverloadThreshold = cfg.DefaultOverloadThreshold + 1
+	updated.StatusUpdateInterval = 250 * time.Millisecond
+	updated.CircuitBreakerMaxFailures = 1
+	manager.ApplyConfig(ctx, &updated)
+
+	assert.Same(t, &updated, manager.Config())
+	assert.Equal(t, 250*time.Millisecond, manager.Broadcaster().Interval())
+	assert.Equal(t, updated.DefaultOverloadThreshold, manager.GetElevator("Default").OverloadThreshold())
+	assert.Equal(t, cfg.DefaultOverloadThreshold+5, manager.GetElevator("Custom").OverloadThreshold(),
+		"cars with their own threshold keep it")
+
+	// The dispatch breaker now opens after a single internal failure
+	err := manager.dispatchBreaker.Execute(ctx, func() error {
+		return domain.NewInternalError("elevator selection timed out", context.DeadlineExceeded)
+	})
+	require.Error(t, err)
+	assert.Equal(t, circuitbreaker.StateOpen, manager.dispatchBreaker.State())
+}
+
+func TestManager_TuneElevator(t *testing.T) {
+	t.Parallel()
+
+	cfg := buildManagerTestConfig()
+	manager := New(cfg, &factory.StandardElevatorFactory{})
+	defer manager.Shutdown()
+
+	ctx := context.Background()
+	require.NoError(t, manager.AddElevator(ctx, cfg, "Express", 0, 9,
+		cfg.EachFloorDuration, cfg.OpenDoorDuration, cfg.DefaultOverloadThreshold))
+	require.NoError(t, manager.AddElevator(ctx, cfg, "Local", 0, 9,
+		cfg.EachFloorDuration, cfg.OpenDoorDuration, cfg.DefaultOverloadThreshold))
+
+	_, err := manager.TuneElevator(ctx, "Missing", elevator.TuningPatch{})
+	require.Error(t, err)
+	assert.Equal(t, domain.ErrTypeNotFound, err.(*domain.DomainError).Type)
+
+	floor := cfg.EachFloorDuration / 2
+	maxFailures := cfg.CircuitBreakerMaxFailures + 3
+	tuning, err := manager.TuneElevator(ctx, "Express", elevator.TuningPatch{
+		EachFloorDuration: &floor,
+		CircuitBreaker:    elevator.CircuitBreakerPatch{MaxFailures: &maxFailures},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, floor, tuning.EachFloorDuration)
+
+	current, err := manager.ElevatorTuning("Express")
+	require.NoError(t, err)
+	assert.Equal(t, tuning, current)
+
+	// A reload moves cars on the global breaker settings but keeps tuned ones
+	updated := *cfg
+	updated.CircuitBreakerMaxFailures = 1
+	manager.ApplyConfig(ctx, &updated)
+
+	express, err := manager.ElevatorTuning("Express")
+	require.NoError(t, err)
+	assert.Equal(t, maxFailures, express.CircuitBreaker.MaxFailures)
+	local, err := manager.ElevatorTuning("Local")
+	require.NoError(t, err)
+	assert.Equal(t, 1, local.CircuitBreaker.MaxFailures)
+}
+
+func TestManager_ElevatorTypes(t *testing.T) {
+	t.Parallel()
+
+	cfg := buildManagerTestConfig()
+	manager := New(cfg, &factory.StandardElevatorFactory{})
+	defer manager.Shutdown()
+
+	ctx := context.Background()
+	add := func(elevatorType domain.ElevatorType, name string) {
+		require.NoError(t, manager.AddTypedElevator(ctx, cfg, elevatorType, name, 0, 9,
+			cfg.EachFloorDuration, cfg.OpenDoorDuration, cfg.DefaultOverloadThreshold))
+	}
+
+	// A single passenger car does not take cargo
+	add(domain.ElevatorTypePassenger, "Passenger")
+	_, err := manager.RequestElevatorWithOptions(ctx, 1, 5, domain.CallOptions{Cargo: true})
+	require.Error(t, err)
+	assert.Equal(t, domain.ErrTypeNotFound, err.(*domain.DomainError).Type)
+
+	// The freight and service cars wait nearest to the pickup floor but are
+	// only used for their calls
+	add(domain.ElevatorTypeFreight, "Freight")
+	add(domain.ElevatorTypeService, "Service")
+	assert.Equal(t, domain.ElevatorTypeFreight, manager.GetElevator("Freight").GetStatus().Type)
+
+	el, err := manager.RequestElevatorWithOptions(ctx, 0, 5, domain.CallOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "Passenger", el.Name())
+
+	el, err = manager.RequestElevatorWithOptions(ctx, 0, 6, domain.CallOptions{Cargo: true})
+	require.NoError(t, err)
+	assert.Equal(t, "Freight", el.Name())
+
+	trip, err := manager.RequestTrip(ctx, 0, 7)
+	require.NoError(t, err)
+	assert.Equal(t, "Passenger", trip.Elevator)
+
+	_, trip, err = manager.requestElevator(ctx, 0, 8, domain.CallOptions{Staff: true})
+	require.NoError(t, err)
+	assert.Contains(t, []string{"Passenger", "Service"}, trip.Elevator)
+	assert.True(t, trip.Staff)
+
+	err = manager.AddTypedElevator(ctx, cfg, "dumbwaiter", "Tiny", 0, 3,
+		cfg.EachFloorDuration, cfg.OpenDoorDuration, cfg.DefaultOverloadThreshold)
+	require.Error(t, err)
+	assert.Equal(t, domain.ErrTypeValidation, err.(*domain.DomainError).Type)
+}
+
+func TestManager_PriorityCalls(t *testing.T) {
+	t.Parallel()
+
+	cfg := buildManagerTestConfig()
+	manager := New(cfg, &factory.StandardElevatorFactory{})
+	defer manager.Shutdown()
+
+	// Slow cars keep the reservations in place for the whole test
+	ctx := context.Background()
+	require.NoError(t, manager.AddElevator(ctx, cfg, "Low", 0, 9,
+		200*time.Millisecond, 200*time.Millisecond, cfg.DefaultOverloadThreshold))
+	require.NoError(t, manager.AddElevator(ctx, cfg, "High", 5, 9,
+		200*time.Millisecond, 200*time.Millisecond, cfg.DefaultOverloadThreshold))
+
+	// An emergency call reserves the nearest free car
+	_, trip, err := manager.requestElevator(ctx, 6, 9, domain.CallOptions{Priority: domain.PriorityEmergency})
+	require.NoError(t, err)
+	assert.Equal(t, "High", trip.Elevator)
+	assert.Equal(t, domain.PriorityEmergency, trip.Priority)
+	assert.True(t, manager.GetElevator("High").IsReserved())
+
+	// Other calls avoid the reserved car, even though it is heading their way
+	el, err := manager.RequestElevator(ctx, 7, 8)
+	require.NoError(t, err)
+	assert.Equal(t, "Low", el.Name())
+
+	// Without a free car the nearest busy one is reserved
+	el, err = manager.RequestElevatorWithOptions(ctx, 5, 9, domain.CallOptions{Priority: domain.PriorityEmergency})
+	require.NoError(t, err)
+	assert.Equal(t, "Low", el.Name())
+	assert.True(t, el.IsReserved())
+
+	_, err = manager.RequestElevatorWithOptions(ctx, 6, 8, domain.CallOptions{Priority: domain.PriorityEmergency})
+	require.Error(t, err)
+	assert.Equal(t, domain.ErrTypeNotFound, err.(*domain.DomainError).Type)
+
+	// High priority calls are not merged into an identical ordinary request
+	_, trip, err = manager.requestElevator(ctx, 7, 8, domain.CallOptions{Priority: domain.PriorityHigh})
+	require.NoError(t, err)
+	assert.Len(t, manager.GetElevator(trip.Elevator).Directions().PriorityCalls(), 2)
+}
+
+func TestManager_EmergencyCallsSpareRidersAboard(t *testing.T) {
+	t.Parallel()
+
+	cfg := buildManagerTestConfig()
+	manager := New(cfg, &factory.StandardElevatorFactory{})
+	defer manager.Shutdown()
+
+	ctx := context.Background()
+	require.NoError(t, manager.AddElevator(ctx, cfg, "Loaded", 0, 9,
+		200*time.Millisecond, 200*time.Millisecond, cfg.DefaultOverloadThreshold))
+	require.NoError(t, manager.AddElevator(ctx, cfg, "Empty", 0, 9,
+		200*time.Millisecond, 200*time.Millisecond, cfg.DefaultOverloadThreshold))
+
+	// One busy car carries a rider to floor 9, the other is still on its
+	// way to pick someone up
+	loaded := manager.GetElevator("Loaded")
+	loaded.Request(domain.DirectionUp, domain.NewFloor(0), domain.NewFloor(9))
+	require.Eventually(t, func() bool { return loaded.Load() == 1 }, 3*time.Second, 10*time.Millisecond)
+	manager.GetElevator("Empty").Request(domain.DirectionUp, domain.NewFloor(8), domain.NewFloor(9))
+
+	// The car without riders is reserved
+	el, err := manager.RequestElevatorWithOptions(ctx, 2, 4, domain.CallOptions{Priority: domain.PriorityEmergency})
+	require.NoError(t, err)
+	assert.Equal(t, "Empty", el.Name())
+
+	// With no other car left the loaded one is reserved, and its rider's
+	// drop-off is kept for after the emergency run
+	el, err = manager.RequestElevatorWithOptions(ctx, 3, 6, domain.CallOptions{Priority: domain.PriorityEmergency})
+	require.NoError(t, err)
+	assert.Equal(t, "Loaded", el.Name())
+	assert.True(t, el.IsReserved())
+	assert.Equal(t, 1, el.Load())
+	assert.True(t, el.HasStop(domain.DirectionUp, domain.NewFloor(9)))
+}
+
+func TestManager_AccessibleCallsPreferLightlyLoadedCars(t *testing.T) {
+	t.Parallel()
+
+	cfg := buildManagerTestConfig()
+	manager := New(cfg, &factory.StandardElevatorFactory{})
+	defer manager.Shutdown()
+
+	ctx := context.Background()
+	require.NoError(t, manager.AddElevator(ctx, cfg, "Busy", 0, 9,
+		time.Second, time.Second, 4))
+	require.NoError(t, manager.AddElevator(ctx, cfg, "Quiet", 0, 9,
+		time.Second, time.Second, 4))
+
+	// Load one car past half of its threshold while it is still on its way
+	busy := manager.GetElevator("Busy")
+	busy.Request(domain.DirectionUp, domain.NewFloor(5), domain.NewFloor(7))
+	busy.Request(domain.DirectionUp, domain.NewFloor(6), domain.NewFloor(8))
+	quiet := manager.GetElevator("Quiet")
+	quiet.Request(domain.DirectionUp, domain.NewFloor(1), domain.NewFloor(9))
+
+	// The busy car already waits for an identical ordinary request, but the
+	// accessible call is not merged into it and goes to the lightly loaded car
+	_, trip, err := manager.requestElevator(ctx, 5, 7, domain.CallOptions{Accessible: true})
+	require.NoError(t, err)
+	assert.Equal(t, "Quiet", trip.Elevator)
+	assert.True(t, trip.Accessible)
+	assert.True(t, quiet.Directions().NeedsAssistance(domain.DirectionUp, 5))
+}
+
+func TestManager_CarAndHallCalls(t *testing.T) {
+	t.Parallel()
+
+	cfg := buildManagerTestConfig()
+	manager := New(cfg, &factory.StandardElevatorFactory{})
+	defer manager.Shutdown()
+
+	ctx := context.Background()
+	require.NoError(t, manager.AddElevator(ctx, cfg, "A", 0, 9,
+		200*time.Millisecond, 200*time.Millisecond, cfg.DefaultOverloadThreshold))
+
+	sub := manager.Events().Subscribe(16)
+	defer sub.Close()
+
+	el, err := manager.RequestHallCall(ctx, 4, domain.DirectionDown, domain.CallOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "A", el.Name())
+	assert.True(t, el.HasStop(domain.DirectionDown, domain.NewFloor(4)))
+
+	event := <-sub.C
+	assert.Equal(t, events.TypeRequestAssigned, event.Type)
+	assert.Equal(t, true, event.Data["hall_call"])
+	assert.Equal(t, false, event.Data["existing"])
+
+	// A second press of the same button joins the pending stop
+	el, err = manager.RequestHallCall(ctx, 4, domain.DirectionDown, domain.CallOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "A", el.Name())
+	assert.Equal(t, true, (<-sub.C).Data["existing"])
+
+	// There is no floor above the top floor to go up to
+	_, err = manager.RequestHallCall(ctx, 9, domain.DirectionUp, domain.CallOptions{})
+	require.Error(t, err)
+
+	for _, invalid := range []struct {
+		direction domain.Direction
+		call      domain.CallOptions
+	}{
+		{domain.DirectionIdle, domain.CallOptions{}},
+		{domain.DirectionUp, domain.CallOptions{Priority: domain.PriorityHigh}},
+		{domain.DirectionUp, domain.CallOptions{Accessible: true}},
+	} {
+		_, err = manager.RequestHallCall(ctx, 2, invalid.direction, invalid.call)
+		require.Error(t, err)
+		assert.Equal(t, domain.ErrTypeValidation, err.(*domain.DomainError).Type)
+	}
+
+	direction, err := manager.CarCall(ctx, "A", 8)
+	require.NoError(t, err)
+	assert.Equal(t, domain.DirectionUp, direction)
+	assert.True(t, el.HasStop(domain.DirectionUp, domain.NewFloor(8)))
+
+	_, err = manager.CarCall(ctx, "missing", 8)
+	require.Error(t, err)
+	assert.Equal(t, domain.ErrTypeNotFound, err.(*domain.DomainError).Type)
+}
@@ -0,0 +1,88 @@
+package manager
+
+import (
+	"log/slog"
+
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+	"github.com/slavakukuyev/elevator-go/internal/elevator"
+	"github.com/slavakukuyev/elevator-go/metrics"
+)
+
+// reserveElevator picks the car for an emergency call: the nearest free
+// (idle) car, then the nearest busy one with no riders aboard, and only when
+// neither is left the nearest car carrying riders. Cars already reserved for
+// another emergency are never picked. The chosen car serves the call
+// non-stop and takes no other requests until it is done; riders already
+// aboard stay on and are dropped off after the emergency run.
+func (m *Manager) reserveElevator(elevators []*elevator.Elevator, fromFloor, toFloor domain.Floor, call domain.CallOptions) (*elevator.Elevator, error) {
+	const (
+		free = iota
+		empty
+		occupied
+	)
+	var el *elevator.Elevator
+	rank, distance := 0, 0
+
+	for _, e := range elevators {
+		if !e.Serves(call) || !e.IsRequestInRange(fromFloor, toFloor) ||
+			e.IsMarkedForDeletion() || e.IsFaulted() || e.IsReserved() {
+			continue
+		}
+
+		r := free
+		if e.CurrentDirection() != domain.DirectionIdle {
+			r = empty
+			if e.Load() > 0 {
+				r = occupied
+			}
+		}
+		d := e.CurrentFloor().Distance(fromFloor)
+		if el == nil || r < rank || (r == rank && d < distance) {
+			el, rank, distance = e, r, d
+		}
+	}
+
+	if el == nil {
+		return nil, domain.NewValidationError("no elevator can be reserved for this emergency call", nil).
+			WithContext("fromFloor", fromFloor.Value()).
+			WithContext("toFloor", toFloor.Value())
+	}
+
+	m.logger.Info("elevator reserved for emergency call",
+		slog.String("elevator", el.Name()),
+		slog.Bool("was_idle", rank == free),
+		slog.Int("riders_aboard", el.Load()),
+		slog.Int("fromFloor", fromFloor.Value()),
+		slog.Int("toFloor", toFloor.Value()))
+	return el, nil
+}
+
+// unreserved leaves out the cars reserved for an emergency call, unless
+// every car is reserved
+func unreserved(elevators []*elevator.Elevator) []*elevator.Elevator {
+	available := make([]*elevator.Elevator, 0, len(elevators))
+	for _, e := range elevators {
+		if !e.IsReserved() {
+			available = append(available, e)
+		}
+	}
+	if len(available) == 0 {
+		return elevators
+	}
+	return available
+}
+
+// recordPriorityTrip observes how long a priority call waited for its car
+// and how long it took to reach its destination
+func recordPriorityTrip(trip Trip) {
+	if !trip.IsPriority() {
+		return
+	}
+	elapsed := trip.UpdatedAt.Sub(trip.CreatedAt).Seconds()
+	switch trip.State {
+	case TripPickedUp:
+		metrics.RecordPriorityWaitTime(trip.Priority.String(), elapsed)
+	case TripCompleted:
+		metrics.RecordPriorityJourneyTime(trip.Priority.String(), elapsed)
+	}
+}
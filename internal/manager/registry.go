@@ -0,0 +1,188 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+
+	"github.com/slavakukuyev/elevator-go/internal/constants"
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+	"github.com/slavakukuyev/elevator-go/internal/factory"
+	"github.com/slavakukuyev/elevator-go/internal/infra/config"
+	"github.com/slavakukuyev/elevator-go/metrics"
+)
+
+// Building is one building served by the process: a Manager with its own
+// fleet, configuration, event bus and circuit breakers, and a bulkhead that
+// bounds how many requests it serves at once so a busy or failing building
+// cannot starve the others.
+type Building struct {
+	ID      string
+	Manager *Manager
+
+	inFlight chan struct{}
+}
+
+// Acquire takes a slot in the building's bulkhead. It returns false when the
+// building already serves as many requests as its configuration allows;
+// otherwise release must be called once the request is done.
+func (b *Building) Acquire() (release func(), ok bool) {
+	select {
+	case b.inFlight <- struct{}{}:
+		metrics.IncBuildingRequests(b.ID, "accepted")
+		metrics.SetBuildingRequestsInFlight(b.ID, len(b.inFlight))
+		return func() {
+			<-b.inFlight
+			metrics.SetBuildingRequestsInFlight(b.ID, len(b.inFlight))
+		}, true
+	default:
+		metrics.IncBuildingRequests(b.ID, "rejected")
+		return nil, false
+	}
+}
+
+// MaxConcurrentRequests returns how many requests the building serves at
+// once
+func (b *Building) MaxConcurrentRequests() int {
+	return cap(b.inFlight)
+}
+
+// Registry holds the buildings served by the process, keyed by ID
+type Registry struct {
+	mu        sync.RWMutex
+	buildings map[string]*Building
+	factory   factory.ElevatorFactory
+	logger    *slog.Logger
+}
+
+// NewRegistry creates an empty registry whose buildings create their
+// elevators with factory
+func NewRegistry(factory factory.ElevatorFactory) *Registry {
+	return &Registry{
+		buildings: make(map[string]*Building),
+		factory:   factory,
+		logger:    slog.With(slog.String("component", constants.ComponentRegistry)),
+	}
+}
+
+// Register adds a running manager as a building. Its bulkhead is sized by
+// the manager's configuration.
+func (r *Registry) Register(id string, m *Manager) (*Building, error) {
+	if id == "" {
+		return nil, domain.NewValidationError("building id cannot be empty", nil)
+	}
+
+	limit := m.Config().BuildingMaxConcurrentRequests
+	if limit <= 0 {
+		limit = constants.DefaultBuildingMaxConcurrentRequests
+	}
+	building := &Building{
+		ID:       id,
+		Manager:  m,
+		inFlight: make(chan struct{}, limit),
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.buildings[id]; exists {
+		return nil, domain.NewConflictError("building already exists", nil).
+			WithContext("building", id)
+	}
+	r.buildings[id] = building
+
+	r.logger.Info("building registered",
+		slog.String("building", id),
+		slog.Int("max_concurrent_requests", limit))
+	return building, nil
+}
+
+// AddBuilding creates a manager for a building from its configuration,
+// starts its default elevators and registers it. The manager runs with
+// BuildingID set to id, which labels the metrics of its elevators.
+func (r *Registry) AddBuilding(ctx context.Context, id string, cfg *config.Config) (*Building, error) {
+	if _, err := r.Building(id); err == nil {
+		return nil, domain.NewConflictError("building already exists", nil).
+			WithContext("building", id)
+	}
+	if cfg.BuildingID != id {
+		building := *cfg
+		building.BuildingID = id
+		cfg = &building
+	}
+
+	m := New(cfg, r.factory)
+	m.AddDefaultElevators(ctx, cfg)
+
+	building, err := r.Register(id, m)
+	if err != nil {
+		m.Shutdown()
+		return nil, err
+	}
+	return building, nil
+}
+
+// Building returns the building with the given ID
+func (r *Registry) Building(id string) (*Building, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	building, exists := r.buildings[id]
+	if !exists {
+		return nil, domain.NewNotFoundError("building not found", nil).
+			WithContext("building", id)
+	}
+	return building, nil
+}
+
+// Buildings returns the registered buildings ordered by ID
+func (r *Registry) Buildings() []*Building {
+	r.mu.RLock()
+	buildings := make([]*Building, 0, len(r.buildings))
+	for _, building := range r.buildings {
+		buildings = append(buildings, building)
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(buildings, func(i, j int) bool {
+		return buildings[i].ID < buildings[j].ID
+	})
+	return buildings
+}
+
+// Shutdown shuts down the manager of every building
+func (r *Registry) Shutdown() {
+	for _, building := range r.Buildings() {
+		building.Manager.Shutdown()
+	}
+}
+
+// AddDefaultElevators creates the number of passenger elevators the
+// configuration asks for, named after its prefix. Elevators that fail to be
+// created are logged and skipped.
+func (m *Manager) AddDefaultElevators(ctx context.Context, cfg *config.Config) {
+	if cfg.DefaultElevatorCount <= 0 {
+		return
+	}
+
+	m.logger.InfoContext(ctx, "creating default elevators",
+		slog.Int("count", cfg.DefaultElevatorCount),
+		slog.String("prefix", cfg.NamePrefix))
+
+	for i := 0; i < cfg.DefaultElevatorCount; i++ {
+		elevatorName := fmt.Sprintf("%s-%d", cfg.NamePrefix, i+1)
+		settings := cfg.ElevatorSettings(elevatorName)
+		err := m.AddElevator(ctx, cfg, elevatorName,
+			cfg.MinFloor, cfg.MaxFloor,
+			settings.EachFloorDuration, settings.OpenDoorDuration, settings.OverloadThreshold)
+		if err != nil {
+			m.logger.ErrorContext(ctx, "failed to create default elevator",
+				slog.String("name", elevatorName),
+				slog.String("error", err.Error()))
+			continue
+		}
+		m.logger.InfoContext(ctx, "default elevator created",
+			slog.String("name", elevatorName))
+	}
+}
@@ -0,0 +1,125 @@
+package manager
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slavakukuyev/elevator-go/internal/constants"
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+	"github.com/slavakukuyev/elevator-go/internal/factory"
+)
+
+func TestRegistry_Buildings(t *testing.T) {
+	registry := NewRegistry(&factory.StandardElevatorFactory{})
+	defer registry.Shutdown()
+
+	cfg := buildManagerTestConfig()
+	defaultManager := New(cfg, &factory.StandardElevatorFactory{})
+	_, err := registry.Register("main", defaultManager)
+	require.NoError(t, err)
+
+	towerCfg := *cfg
+	towerCfg.MinFloor, towerCfg.MaxFloor = -2, 30
+	towerCfg.DefaultElevatorCount = 2
+	towerCfg.NamePrefix = "Tower"
+	tower, err := registry.AddBuilding(context.Background(), "tower", &towerCfg)
+	require.NoError(t, err)
+
+	// Each building has its own fleet
+	require.Len(t, tower.Manager.GetElevators(), 2)
+	assert.Equal(t, 30, tower.Manager.GetElevator("Tower-1").MaxFloor().Value())
+	assert.Empty(t, defaultManager.GetElevators())
+
+	found, err := registry.Building("tower")
+	require.NoError(t, err)
+	assert.Same(t, tower, found)
+	assert.Equal(t, []string{"main", "tower"}, []string{registry.Buildings()[0].ID, registry.Buildings()[1].ID})
+
+	_, err = registry.Building("annex")
+	require.Error(t, err)
+	assert.Equal(t, domain.ErrTypeNotFound, err.(*domain.DomainError).Type)
+
+	_, err = registry.AddBuilding(context.Background(), "tower", &towerCfg)
+	require.Error(t, err)
+	assert.Equal(t, domain.ErrTypeConflict, err.(*domain.DomainError).Type)
+}
+
+func TestBuilding_AcquireBoundsConcurrentRequests(t *testing.T) {
+	registry := NewRegistry(&factory.StandardElevatorFactory{})
+	defer registry.Shutdown()
+
+	cfg := buildManagerTestConfig()
+	cfg.BuildingMaxConcurrentRequests = 2
+	busy, err := registry.AddBuilding(context.Background(), "busy", cfg)
+	require.NoError(t, err)
+	quiet, err := registry.AddBuilding(context.Background(), "quiet", cfg)
+	require.NoError(t, err)
+	assert.Equal(t, 2, busy.MaxConcurrentRequests())
+
+	first, ok := busy.Acquire()
+	require.True(t, ok)
+	second, ok := busy.Acquire()
+	require.True(t, ok)
+
+	// A saturated building turns requests away without affecting the others
+	_, ok = busy.Acquire()
+	assert.False(t, ok)
+	release, ok := quiet.Acquire()
+	require.True(t, ok)
+	release()
+
+	first()
+	third, ok := busy.Acquire()
+	assert.True(t, ok)
+	second()
+	third()
+}
+
+func TestRegistry_MetricsAreLabelledByBuilding(t *testing.T) {
+	registry := NewRegistry(&factory.StandardElevatorFactory{})
+	defer registry.Shutdown()
+
+	// Both buildings name their default car Elevator-1
+	cfg := buildManagerTestConfig()
+	cfg.DefaultElevatorCount = 1
+	north, err := registry.AddBuilding(context.Background(), "north", cfg)
+	require.NoError(t, err)
+	south, err := registry.AddBuilding(context.Background(), "south", cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "north", north.Manager.Config().BuildingID)
+	assert.Equal(t, "north", north.Manager.GetElevator("Elevator-1").Building())
+
+	_, err = north.Manager.RequestElevator(context.Background(), 0, 3)
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		_, err = south.Manager.RequestElevator(context.Background(), 0, 4+i)
+		require.NoError(t, err)
+	}
+
+	requests := func(building string) float64 {
+		families, err := prometheus.DefaultGatherer.Gather()
+		require.NoError(t, err)
+		total := 0.0
+		for _, family := range families {
+			if family.GetName() != constants.MetricsNamespace+"_requests_total" {
+				continue
+			}
+			for _, metric := range family.GetMetric() {
+				labels := map[string]string{}
+				for _, label := range metric.GetLabel() {
+					labels[label.GetName()] = label.GetValue()
+				}
+				if labels[constants.BuildingLabel] == building && labels[constants.ElevatorNameLabel] == "Elevator-1" {
+					total += metric.GetCounter().GetValue()
+				}
+			}
+		}
+		return total
+	}
+	assert.Equal(t, 1.0, requests("north"))
+	assert.Equal(t, 2.0, requests("south"))
+}
@@ -0,0 +1,54 @@
+package manager
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/slavakukuyev/elevator-go/internal/infra/config"
+)
+
+// config returns the configuration the manager currently runs with
+func (m *Manager) config() *config.Config {
+	return m.cfg.Load()
+}
+
+// building returns the ID of the building the manager serves, which labels
+// the metrics of its elevators
+func (m *Manager) building() string {
+	return m.config().BuildingID
+}
+
+// Config returns the configuration the manager currently runs with. It
+// changes when a reloaded configuration is applied and must not be modified.
+func (m *Manager) Config() *config.Config {
+	return m.config()
+}
+
+// ApplyConfig applies the live-reloadable settings of cfg to the manager and
+// its elevators. Elevators still running with the previous default overload
+// threshold or circuit breaker settings move to the new defaults; cars
+// created or tuned with their own values keep them.
+func (m *Manager) ApplyConfig(ctx context.Context, cfg *config.Config) {
+	previous := m.cfg.Swap(cfg)
+
+	m.status.SetInterval(cfg.StatusUpdateInterval)
+	m.dispatchBreaker.Configure(cfg.CircuitBreakerSettings())
+
+	previousSettings := previous.CircuitBreakerSettings()
+	settings := cfg.CircuitBreakerSettings()
+	elevators := m.GetElevators()
+	for _, e := range elevators {
+		if e.Tuning().CircuitBreaker.Equal(previousSettings) {
+			e.SetCircuitBreakerSettings(settings)
+		}
+		if previous.DefaultOverloadThreshold != cfg.DefaultOverloadThreshold &&
+			e.OverloadThreshold() == previous.DefaultOverloadThreshold {
+			e.SetOverloadThreshold(cfg.DefaultOverloadThreshold)
+		}
+	}
+
+	m.logger.InfoContext(ctx, "configuration applied to elevators",
+		slog.Int("elevators", len(elevators)),
+		slog.Duration("status_update_interval", cfg.StatusUpdateInterval),
+		slog.Int("default_overload_threshold", cfg.DefaultOverloadThreshold))
+}
@@ -0,0 +1,169 @@
+package manager
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+	"github.com/slavakukuyev/elevator-go/internal/infra/config"
+)
+
+// maxSLISamples bounds the samples kept per indicator; the oldest are dropped
+// first
+const maxSLISamples = 100000
+
+// sliSample is one observation of a service-level indicator
+type sliSample struct {
+	at    time.Time
+	value float64
+}
+
+// sliSeries is a time-ordered series of samples no older than
+// config.MaxSLAWindow
+type sliSeries struct {
+	samples []sliSample
+}
+
+func (s *sliSeries) add(at time.Time, value float64) {
+	s.samples = append(s.samples, sliSample{at: at, value: value})
+	s.prune(at)
+}
+
+// prune drops samples that no rule can look back to
+func (s *sliSeries) prune(now time.Time) {
+	cutoff := now.Add(-config.MaxSLAWindow)
+	drop := sort.Search(len(s.samples), func(i int) bool { return !s.samples[i].at.Before(cutoff) })
+	drop = max(drop, len(s.samples)-maxSLISamples)
+	if drop > 0 {
+		s.samples = append(s.samples[:0], s.samples[drop:]...)
+	}
+}
+
+// since returns the values of the samples taken after a point in time
+func (s *sliSeries) since(from time.Time) []float64 {
+	start := sort.Search(len(s.samples), func(i int) bool { return s.samples[i].at.After(from) })
+	values := make([]float64, 0, len(s.samples)-start)
+	for _, sample := range s.samples[start:] {
+		values = append(values, sample.value)
+	}
+	return values
+}
+
+// serviceLevels collects the samples behind the service-level indicators:
+// how long riders waited and rode, and whether floor requests were taken
+type serviceLevels struct {
+	mu       sync.Mutex
+	waits    sliSeries // Seconds from request to pickup
+	rides    sliSeries // Seconds from pickup to drop-off
+	requests sliSeries // 1 for a rejected request, 0 for an assigned one
+}
+
+func newServiceLevels() *serviceLevels {
+	return &serviceLevels{}
+}
+
+// recordTrip records the wait of a trip that was picked up and the ride of
+// one that was completed
+func (s *serviceLevels) recordTrip(trip Trip) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch trip.State {
+	case TripPickedUp:
+		s.waits.add(trip.UpdatedAt, trip.UpdatedAt.Sub(trip.CreatedAt).Seconds())
+	case TripCompleted:
+		if trip.PickedUpAt != nil {
+			s.rides.add(trip.UpdatedAt, trip.UpdatedAt.Sub(*trip.PickedUpAt).Seconds())
+		}
+	}
+}
+
+// recordRequest records whether a floor request was assigned. Invalid
+// requests are the caller's mistake and do not count against the service.
+func (s *serviceLevels) recordRequest(at time.Time, err error) {
+	if isValidationError(err) {
+		return
+	}
+
+	rejected := 0.0
+	if err != nil {
+		rejected = 1
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests.add(at, rejected)
+}
+
+// isValidationError reports whether a request failed because it was invalid
+func isValidationError(err error) bool {
+	domainErr, ok := err.(*domain.DomainError)
+	return ok && domainErr.Type == domain.ErrTypeValidation
+}
+
+// window returns the samples of an indicator taken within the trailing window
+func (s *serviceLevels) window(indicator string, now time.Time, window time.Duration) []float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var series *sliSeries
+	switch indicator {
+	case config.SLIWaitTime:
+		series = &s.waits
+	case config.SLIRideTime:
+		series = &s.rides
+	case config.SLIRejectedRequests:
+		series = &s.requests
+	default:
+		return nil
+	}
+	series.prune(now)
+	return series.since(now.Add(-window))
+}
+
+// ServiceLevel returns a service-level indicator over the trailing window
+// and the number of samples it is based on:
+//
+//   - wait_time and ride_time: the percentile of the seconds riders waited
+//     for pickup or rode to their floor
+//   - rejected_requests: the share of floor requests no elevator took
+//   - unavailable_cars: the number of cars that are faulted or being removed
+//     right now, out of a fleet of samples cars
+//
+// The value is 0 when there are no samples.
+func (m *Manager) ServiceLevel(indicator string, percentile float64, window time.Duration) (float64, int) {
+	if indicator == config.SLIUnavailableCars {
+		elevators := m.GetElevators()
+		unavailable := 0
+		for _, e := range elevators {
+			if e.IsFaulted() || e.IsMarkedForDeletion() {
+				unavailable++
+			}
+		}
+		return float64(unavailable), len(elevators)
+	}
+
+	values := m.serviceLevels.window(indicator, time.Now(), window)
+	if len(values) == 0 {
+		return 0, 0
+	}
+
+	if indicator == config.SLIRejectedRequests {
+		rejected := 0.0
+		for _, value := range values {
+			rejected += value
+		}
+		return rejected / float64(len(values)), len(values)
+	}
+	return nearestRank(values, percentile), len(values)
+}
+
+// nearestRank returns the nearest-rank percentile of values, which it sorts
+func nearestRank(values []float64, percentile float64) float64 {
+	sort.Float64s(values)
+	rank := int(math.Ceil(percentile / 100 * float64(len(values))))
+	rank = min(max(rank, 1), len(values))
+	return values[rank-1]
+}
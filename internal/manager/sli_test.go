@@ -0,0 +1,105 @@
+package manager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+	"github.com/slavakukuyev/elevator-go/internal/events"
+	"github.com/slavakukuyev/elevator-go/internal/factory"
+	"github.com/slavakukuyev/elevator-go/internal/infra/config"
+)
+
+func TestManager_ServiceLevel(t *testing.T) {
+	t.Parallel()
+
+	cfg := buildManagerTestConfig()
+	manager := New(cfg, &factory.StandardElevatorFactory{})
+	defer manager.Shutdown()
+
+	sub := manager.Events().Subscribe(64)
+	defer sub.Close()
+
+	ctx := context.Background()
+	require.NoError(t, manager.AddElevator(ctx, cfg, "SLI", 0, 5,
+		cfg.EachFloorDuration, cfg.OpenDoorDuration, cfg.DefaultOverloadThreshold))
+
+	value, samples := manager.ServiceLevel(config.SLIWaitTime, 95, time.Minute)
+	assert.Zero(t, value)
+	assert.Zero(t, samples)
+
+	trip, err := manager.RequestTrip(ctx, 1, 3)
+	require.NoError(t, err)
+
+	// Invalid requests do not count, requests no car can take do
+	_, err = manager.RequestTrip(ctx, 2, 2)
+	require.Error(t, err)
+	_, err = manager.RequestElevatorWithOptions(ctx, 1, 3, domain.CallOptions{Cargo: true})
+	require.Error(t, err)
+
+	value, samples = manager.ServiceLevel(config.SLIRejectedRequests, 0, time.Minute)
+	assert.Equal(t, 0.5, value)
+	assert.Equal(t, 2, samples)
+
+	timeout := time.After(3 * time.Second)
+	for completed := false; !completed; {
+		select {
+		case event := <-sub.C:
+			completed = event.Type == events.TypeRequestCompleted && event.Data["trip_id"] == trip.ID
+		case <-timeout:
+			t.Fatal("timed out waiting for the trip to complete")
+		}
+	}
+
+	finished, ok := manager.GetTrip(trip.ID)
+	require.True(t, ok)
+	value, samples = manager.ServiceLevel(config.SLIWaitTime, 95, time.Minute)
+	assert.Equal(t, 1, samples)
+	assert.InDelta(t, finished.PickedUpAt.Sub(finished.CreatedAt).Seconds(), value, 1e-9)
+	value, samples = manager.ServiceLevel(config.SLIRideTime, 50, time.Minute)
+	assert.Equal(t, 1, samples)
+	assert.InDelta(t, finished.UpdatedAt.Sub(*finished.PickedUpAt).Seconds(), value, 1e-9)
+
+	value, samples = manager.ServiceLevel(config.SLIUnavailableCars, 0, time.Minute)
+	assert.Zero(t, value)
+	assert.Equal(t, 1, samples)
+}
+
+func TestServiceLevels_Window(t *testing.T) {
+	levels := newServiceLevels()
+	now := time.Now()
+
+	// A sample older than any window is dropped
+	levels.recordTrip(Trip{State: TripPickedUp, CreatedAt: now.Add(-config.MaxSLAWindow - time.Hour), UpdatedAt: now.Add(-config.MaxSLAWindow - time.Minute)})
+	for i := 1; i <= 10; i++ {
+		at := now.Add(-time.Duration(10-i) * time.Minute)
+		levels.recordTrip(Trip{State: TripPickedUp, CreatedAt: at.Add(-time.Duration(i) * time.Second), UpdatedAt: at})
+	}
+
+	assert.Len(t, levels.window(config.SLIWaitTime, now, config.MaxSLAWindow), 10)
+	assert.Equal(t, []float64{8, 9, 10}, levels.window(config.SLIWaitTime, now, 150*time.Second))
+	assert.Empty(t, levels.window(config.SLIRideTime, now, time.Hour))
+
+	// Completed trips without a pickup time have no ride
+	levels.recordTrip(Trip{State: TripCompleted, CreatedAt: now, UpdatedAt: now})
+	assert.Empty(t, levels.window(config.SLIRideTime, now, time.Hour))
+
+	levels.recordRequest(now, nil)
+	levels.recordRequest(now, domain.NewValidationError("invalid", nil))
+	levels.recordRequest(now, domain.NewNotFoundError("no car", nil))
+	assert.Equal(t, []float64{0, 1}, levels.window(config.SLIRejectedRequests, now, time.Minute))
+}
+
+func TestNearestRank(t *testing.T) {
+	values := []float64{5, 1, 4, 2, 3, 10, 9, 8, 7, 6}
+	assert.Equal(t, 10.0, nearestRank(values, 100))
+	assert.Equal(t, 10.0, nearestRank(values, 95))
+	assert.Equal(t, 9.0, nearestRank(values, 90))
+	assert.Equal(t, 5.0, nearestRank(values, 50))
+	assert.Equal(t, 1.0, nearestRank(values, 1))
+	assert.Equal(t, 7.0, nearestRank([]float64{7}, 99))
+}
@@ -0,0 +1,138 @@
+package manager
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+	"github.com/slavakukuyev/elevator-go/internal/elevator"
+)
+
+// tracerName names the tracer of the manager's spans
+const tracerName = "github.com/slavakukuyev/elevator-go/internal/manager"
+
+// Spans of a floor request. The request and dispatch spans are part of the
+// caller's trace; the elevator serves the call later on its own loop, so the
+// pickup and delivery spans start traces of their own that link back to the
+// request. Trip IDs are only unique within a building, so every span carries
+// the building.id attribute as well.
+const (
+	spanRequest  = "manager.request_elevator"
+	spanDispatch = "manager.dispatch"
+	spanPickup   = "trip.pickup"
+	spanDelivery = "trip.delivery"
+)
+
+// SetTracerProvider makes the manager trace its requests and trips with a
+// provider other than the global one. It must be called before the manager
+// takes requests.
+func (m *Manager) SetTracerProvider(provider trace.TracerProvider) {
+	m.tracer = provider.Tracer(tracerName)
+}
+
+// startRequestSpan starts the span of a floor request
+func (m *Manager) startRequestSpan(ctx context.Context, fromFloor, toFloor int, call domain.CallOptions) (context.Context, trace.Span) {
+	return m.tracer.Start(ctx, spanRequest, trace.WithAttributes(
+		attribute.String("building.id", m.building()),
+		attribute.Int("elevator.from_floor", fromFloor),
+		attribute.Int("elevator.to_floor", toFloor),
+		attribute.String("elevator.priority", call.Priority.String()),
+		attribute.Bool("elevator.accessible", call.Accessible),
+	))
+}
+
+// endRequestSpan records the outcome of a floor request on its span
+func endRequestSpan(span trace.Span, trip Trip, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	} else {
+		span.SetAttributes(
+			attribute.String("trip.id", trip.ID),
+			attribute.String("elevator.name", trip.Elevator),
+			attribute.Float64("trip.estimated_wait_seconds", trip.EstimatedWaitSeconds),
+		)
+	}
+	span.End()
+}
+
+// traceDispatch chooses an elevator like dispatch within a span that
+// describes every car as a candidate event. The chooser is rule based, so
+// the score recorded for a car is its estimated wait for the caller.
+func (m *Manager) traceDispatch(ctx context.Context, elevators []*elevator.Elevator, direction domain.Direction, fromFloor, toFloor domain.Floor, call domain.CallOptions) (*elevator.Elevator, error) {
+	ctx, span := m.tracer.Start(ctx, spanDispatch, trace.WithAttributes(
+		attribute.String("building.id", m.building()),
+		attribute.String("elevator.direction", string(direction)),
+		attribute.Int("dispatch.candidates", len(elevators)),
+	))
+	defer span.End()
+
+	if span.IsRecording() {
+		for _, e := range elevators {
+			eligible := e.Serves(call) && e.IsRequestInRange(fromFloor, toFloor) &&
+				!e.IsMarkedForDeletion() && !e.IsFaulted()
+			wait, _ := estimateTrip(e, fromFloor.Value(), toFloor.Value())
+			span.AddEvent("candidate", trace.WithAttributes(
+				attribute.String("elevator.name", e.Name()),
+				attribute.Bool("dispatch.eligible", eligible),
+				attribute.Float64("dispatch.score", wait.Seconds()),
+				attribute.Int("elevator.floor", e.CurrentFloor().Value()),
+				attribute.String("elevator.direction", string(e.CurrentDirection())),
+				attribute.Int("elevator.pending_requests", e.Directions().DirectionsLength()),
+				attribute.Bool("elevator.overloaded", isElevatorOverloaded(e)),
+			))
+		}
+	}
+
+	el, err := m.dispatch(ctx, elevators, direction, fromFloor, toFloor, call)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	span.SetAttributes(attribute.String("dispatch.chosen", el.Name()))
+	return el, nil
+}
+
+// traceTrip records the wait of a trip that was picked up and the ride of
+// one that was completed as spans over the measured times, linked to the
+// span of the request
+func (m *Manager) traceTrip(trip Trip) {
+	var name string
+	var start, end time.Time
+	attrs := []attribute.KeyValue{
+		attribute.String("building.id", m.building()),
+		attribute.String("trip.id", trip.ID),
+		attribute.String("elevator.name", trip.Elevator),
+		attribute.Int("elevator.from_floor", trip.FromFloor),
+		attribute.Int("elevator.to_floor", trip.ToFloor),
+	}
+	switch trip.State {
+	case TripPickedUp:
+		name, start, end = spanPickup, trip.CreatedAt, *trip.PickedUpAt
+		attrs = append(attrs, attribute.Float64("trip.estimated_wait_seconds", trip.EstimatedWaitSeconds))
+	case TripCompleted:
+		if trip.PickedUpAt == nil {
+			return
+		}
+		name, start, end = spanDelivery, *trip.PickedUpAt, *trip.DroppedOffAt
+		attrs = append(attrs, attribute.Float64("trip.estimated_ride_seconds", trip.EstimatedRideSeconds))
+	default:
+		return
+	}
+
+	options := []trace.SpanStartOption{
+		trace.WithNewRoot(),
+		trace.WithTimestamp(start),
+		trace.WithAttributes(attrs...),
+	}
+	if trip.requestSpan.IsValid() {
+		options = append(options, trace.WithLinks(trace.Link{SpanContext: trip.requestSpan}))
+	}
+	_, span := m.tracer.Start(m.ctx, name, options...)
+	span.End(trace.WithTimestamp(end))
+}
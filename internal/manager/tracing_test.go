@@ -0,0 +1,110 @@
+package manager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/slavakukuyev/elevator-go/internal/factory"
+)
+
+// useInMemoryTracing returns a tracer provider recording spans in memory
+// for the rest of the test
+func useInMemoryTracing(t *testing.T) (*sdktrace.TracerProvider, *tracetest.InMemoryExporter) {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() {
+		_ = provider.Shutdown(context.Background())
+	})
+	return provider, exporter
+}
+
+func findSpan(spans tracetest.SpanStubs, name, tripID string) (tracetest.SpanStub, bool) {
+	for _, span := range spans {
+		if span.Name != name {
+			continue
+		}
+		if tripID == "" {
+			return span, true
+		}
+		for _, attr := range span.Attributes {
+			if attr.Key == "trip.id" && attr.Value.AsString() == tripID {
+				return span, true
+			}
+		}
+	}
+	return tracetest.SpanStub{}, false
+}
+
+func TestManager_TracesRequestPath(t *testing.T) {
+	provider, exporter := useInMemoryTracing(t)
+
+	cfg := buildManagerTestConfig()
+	cfg.BuildingID = "tower"
+	manager := New(cfg, &factory.StandardElevatorFactory{})
+	defer manager.Shutdown()
+	manager.SetTracerProvider(provider)
+
+	ctx := context.Background()
+	require.NoError(t, manager.AddElevator(ctx, cfg, "Near", 0, 9,
+		cfg.EachFloorDuration, cfg.OpenDoorDuration, cfg.DefaultOverloadThreshold))
+	require.NoError(t, manager.AddElevator(ctx, cfg, "Far", 0, 9,
+		cfg.EachFloorDuration, cfg.OpenDoorDuration, cfg.DefaultOverloadThreshold))
+
+	// The caller's span stands in for the HTTP request
+	callerCtx, caller := provider.Tracer("test").Start(ctx, "http_request")
+	trip, err := manager.RequestTrip(callerCtx, 1, 3)
+	caller.End()
+	require.NoError(t, err)
+
+	var spans tracetest.SpanStubs
+	require.Eventually(t, func() bool {
+		spans = exporter.GetSpans()
+		_, ok := findSpan(spans, spanDelivery, trip.ID)
+		return ok
+	}, 5*time.Second, 20*time.Millisecond)
+
+	// Stop the manager so no more spans arrive while they are inspected
+	finished, ok := manager.GetTrip(trip.ID)
+	require.True(t, ok)
+	manager.Shutdown()
+	spans = exporter.GetSpans()
+
+	request, ok := findSpan(spans, spanRequest, trip.ID)
+	require.True(t, ok)
+	assert.Equal(t, caller.SpanContext().SpanID(), request.Parent.SpanID())
+
+	dispatch, ok := findSpan(spans, spanDispatch, "")
+	require.True(t, ok)
+	assert.Equal(t, request.SpanContext.SpanID(), dispatch.Parent.SpanID())
+	require.Len(t, dispatch.Events, 2)
+	for _, event := range dispatch.Events {
+		assert.Equal(t, "candidate", event.Name)
+	}
+
+	pickup, ok := findSpan(spans, spanPickup, trip.ID)
+	require.True(t, ok)
+	assert.False(t, pickup.Parent.IsValid())
+	require.Len(t, pickup.Links, 1)
+	assert.Equal(t, request.SpanContext, pickup.Links[0].SpanContext)
+	assert.Equal(t, finished.CreatedAt, pickup.StartTime)
+	assert.Equal(t, *finished.PickedUpAt, pickup.EndTime)
+
+	delivery, _ := findSpan(spans, spanDelivery, trip.ID)
+	require.Len(t, delivery.Links, 1)
+	assert.Equal(t, request.SpanContext, delivery.Links[0].SpanContext)
+	assert.Equal(t, *finished.PickedUpAt, delivery.StartTime)
+	assert.Equal(t, *finished.DroppedOffAt, delivery.EndTime)
+
+	// Trip IDs repeat across buildings, so every span names its building
+	for _, span := range []tracetest.SpanStub{request, dispatch, pickup, delivery} {
+		assert.Contains(t, span.Attributes, attribute.String("building.id", "tower"), span.Name)
+	}
+}
@@ -0,0 +1,528 @@
+package manager
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/slavakukuyev/elevator-go/internal/constants"
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+	"github.com/slavakukuyev/elevator-go/internal/elevator"
+	"github.com/slavakukuyev/elevator-go/internal/events"
+	"github.com/slavakukuyev/elevator-go/metrics"
+)
+
+// TripState is the lifecycle stage of a floor request
+type TripState string
+
+const (
+	// TripAssigned means the request waits for its elevator at the pickup floor
+	TripAssigned TripState = "assigned"
+	// TripPickedUp means the passengers boarded at the pickup floor
+	TripPickedUp TripState = "picked_up"
+	// TripCompleted means the passengers got off at the destination floor
+	TripCompleted TripState = "completed"
+	// TripCancelled means the request was withdrawn before pickup or its
+	// elevator left the pool
+	TripCancelled TripState = "cancelled"
+	// TripUnknown means the ledger gave up on a request that made no
+	// progress for unfinishedTripTTL; its car may still serve it
+	TripUnknown TripState = "unknown"
+)
+
+const (
+	// finishedTripRetention bounds how many completed or cancelled trips stay
+	// queryable
+	finishedTripRetention = 1000
+	// tripEventBuffer bounds the events queued for the trip ledger
+	tripEventBuffer = 1024
+	// tripSweepInterval is how often trips that made no progress are checked
+	// against the stops of their elevators
+	tripSweepInterval = time.Minute
+	// unfinishedTripTTL bounds how long an unfinished trip is tracked
+	// without progress
+	unfinishedTripTTL = time.Hour
+)
+
+// Trip is a floor request tracked from assignment to completion. CreatedAt
+// is when the request was registered, PickedUpAt when its car flushed the
+// pickup and DroppedOffAt when the car removed the destination; the estimates
+// are the times the dispatcher expected the wait and the ride to take.
+type Trip struct {
+	ID        string           `json:"trip_id"`
+	Elevator  string           `json:"elevator_name"`
+	Direction domain.Direction `json:"direction"`
+	FromFloor int              `json:"from_floor"`
+	ToFloor   int              `json:"to_floor"`
+	domain.CallOptions
+	State                TripState  `json:"state"`
+	CreatedAt            time.Time  `json:"created_at"`
+	PickedUpAt           *time.Time `json:"picked_up_at,omitempty"`
+	DroppedOffAt         *time.Time `json:"dropped_off_at,omitempty"`
+	UpdatedAt            time.Time  `json:"updated_at"`
+	EstimatedWaitSeconds float64    `json:"estimated_wait_seconds"`
+	EstimatedRideSeconds float64    `json:"estimated_ride_seconds"`
+
+	// requestSpan is the span of the request, which the pickup and delivery
+	// spans link to
+	requestSpan trace.SpanContext
+}
+
+// IsFinished reports whether the trip reached a terminal state
+func (t Trip) IsFinished() bool {
+	return t.State == TripCompleted || t.State == TripCancelled || t.State == TripUnknown
+}
+
+// tripLedger records floor requests and advances them as elevators serve
+// their stops. Finished trips are retained up to finishedTripRetention; the
+// outcomes of all completed and cancelled trips are counted per elevator.
+type tripLedger struct {
+	building string // labels the efficiency metrics of the elevators
+	mu       sync.Mutex
+	nextID   uint64
+	trips    map[string]*Trip
+	finished []string
+	outcomes map[string]*tripOutcomes
+}
+
+// tripOutcomes counts how an elevator's trips finished
+type tripOutcomes struct {
+	completed int
+	cancelled int
+}
+
+func newTripLedger(building string) *tripLedger {
+	return &tripLedger{
+		building: building,
+		trips:    make(map[string]*Trip),
+		outcomes: make(map[string]*tripOutcomes),
+	}
+}
+
+// add records a request from its elevator, floors, call options, the time
+// it was registered at (CreatedAt) and its estimates. The ledger assigns the
+// ID and state.
+func (l *tripLedger) add(request Trip) Trip {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.nextID++
+	trip := &request
+	trip.ID = "trip-" + strconv.FormatUint(l.nextID, 10)
+	trip.State = TripAssigned
+	trip.UpdatedAt = trip.CreatedAt
+	l.trips[trip.ID] = trip
+	return *trip
+}
+
+func (l *tripLedger) get(id string) (Trip, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	trip, ok := l.trips[id]
+	if !ok {
+		return Trip{}, false
+	}
+	return *trip, true
+}
+
+// served advances the trips of a stop served at a time: passengers headed to
+// the floor get off first, then waiting passengers board
+func (l *tripLedger) served(elevatorName string, floor int, direction domain.Direction, at time.Time) []Trip {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var changed []Trip
+	for _, trip := range l.trips {
+		if trip.Elevator == elevatorName && trip.Direction == direction &&
+			trip.State == TripPickedUp && trip.ToFloor == floor {
+			changed = append(changed, l.transition(trip, TripCompleted, at))
+		}
+	}
+	for _, trip := range l.trips {
+		if trip.Elevator == elevatorName && trip.Direction == direction &&
+			trip.State == TripAssigned && trip.FromFloor == floor {
+			changed = append(changed, l.transition(trip, TripPickedUp, at))
+		}
+	}
+	return changed
+}
+
+// elevatorRemoved cancels every unfinished trip of a removed elevator
+func (l *tripLedger) elevatorRemoved(elevatorName string) []Trip {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var changed []Trip
+	for _, trip := range l.trips {
+		if trip.Elevator == elevatorName && !trip.IsFinished() {
+			changed = append(changed, l.transition(trip, TripCancelled, time.Now()))
+		}
+	}
+	return changed
+}
+
+// transition moves a trip to a new state at a time; callers must hold l.mu
+func (l *tripLedger) transition(trip *Trip, state TripState, at time.Time) Trip {
+	trip.State = state
+	trip.UpdatedAt = at
+	switch state {
+	case TripPickedUp:
+		trip.PickedUpAt = &at
+	case TripCompleted:
+		trip.DroppedOffAt = &at
+	}
+	if trip.IsFinished() {
+		if state != TripUnknown {
+			outcomes, ok := l.outcomes[trip.Elevator]
+			if !ok {
+				outcomes = &tripOutcomes{}
+				l.outcomes[trip.Elevator] = outcomes
+			}
+			if state == TripCompleted {
+				outcomes.completed++
+			} else {
+				outcomes.cancelled++
+			}
+			metrics.SetElevatorEfficiency(l.building, trip.Elevator, outcomes.efficiency())
+		}
+
+		l.finished = append(l.finished, trip.ID)
+		for len(l.finished) > finishedTripRetention {
+			delete(l.trips, l.finished[0])
+			l.finished = l.finished[1:]
+		}
+	}
+	return *trip
+}
+
+// efficiency returns the share of all finished trips that were completed,
+// or 0 before any trip finished
+func (l *tripLedger) efficiency() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var total tripOutcomes
+	for _, outcomes := range l.outcomes {
+		total.completed += outcomes.completed
+		total.cancelled += outcomes.cancelled
+	}
+	return total.efficiency()
+}
+
+// efficiency returns the share of finished trips that were completed, or 0
+// before any trip finished
+func (o *tripOutcomes) efficiency() float64 {
+	finished := o.completed + o.cancelled
+	if finished == 0 {
+		return 0
+	}
+	return float64(o.completed) / float64(finished)
+}
+
+// reconcile advances the unfinished trips that made no progress since
+// staleBefore from the stops their elevators still have, for when
+// stop_served events were missed: a pickup the car no longer holds was
+// picked up, and a drop-off it no longer holds was completed. Trips of
+// elevators that left the pool are cancelled, and trips still unfinished
+// after unfinishedTripTTL are given up on.
+func (l *tripLedger) reconcile(elevatorOf func(string) *elevator.Elevator, staleBefore, now time.Time) []Trip {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var changed []Trip
+	for _, trip := range l.trips {
+		if trip.IsFinished() || trip.UpdatedAt.After(staleBefore) {
+			continue
+		}
+
+		el := elevatorOf(trip.Elevator)
+		if el == nil {
+			changed = append(changed, l.transition(trip, TripCancelled, now))
+			continue
+		}
+		fromFloor, toFloor := domain.NewFloor(trip.FromFloor), domain.NewFloor(trip.ToFloor)
+		if trip.State == TripAssigned && !el.Directions().IsRequestExisting(trip.Direction, fromFloor, toFloor) {
+			changed = append(changed, l.transition(trip, TripPickedUp, now))
+		}
+		if trip.State == TripPickedUp && !el.HasStop(trip.Direction, toFloor) {
+			changed = append(changed, l.transition(trip, TripCompleted, now))
+		}
+		if !trip.IsFinished() && now.Sub(trip.UpdatedAt) > unfinishedTripTTL {
+			changed = append(changed, l.transition(trip, TripUnknown, now))
+		}
+	}
+	return changed
+}
+
+// sharesPickup reports whether another waiting trip uses the same pickup
+// entry; callers must hold l.mu
+func (l *tripLedger) sharesPickup(trip *Trip) bool {
+	for _, other := range l.trips {
+		if other.ID != trip.ID && other.State == TripAssigned && other.Elevator == trip.Elevator &&
+			other.Direction == trip.Direction && other.FromFloor == trip.FromFloor && other.ToFloor == trip.ToFloor {
+			return true
+		}
+	}
+	return false
+}
+
+// hasDropOffAt reports whether passengers on board are headed to the floor;
+// callers must hold l.mu
+func (l *tripLedger) hasDropOffAt(elevatorName string, direction domain.Direction, floor int) bool {
+	for _, trip := range l.trips {
+		if trip.Elevator == elevatorName && trip.Direction == direction &&
+			trip.State == TripPickedUp && trip.ToFloor == floor {
+			return true
+		}
+	}
+	return false
+}
+
+// GetTrip returns a tracked floor request by ID
+func (m *Manager) GetTrip(id string) (Trip, bool) {
+	return m.trips.get(id)
+}
+
+// RequestTrip requests an elevator like RequestElevator and returns the
+// tracked trip, whose lifecycle is published on the event bus
+func (m *Manager) RequestTrip(ctx context.Context, fromFloor, toFloor int) (Trip, error) {
+	_, trip, err := m.requestElevator(ctx, fromFloor, toFloor, domain.CallOptions{})
+	return trip, err
+}
+
+// RequestTripWithOptions requests an elevator like RequestElevatorWithOptions
+// and returns the tracked trip
+func (m *Manager) RequestTripWithOptions(ctx context.Context, fromFloor, toFloor int, call domain.CallOptions) (Trip, error) {
+	_, trip, err := m.requestElevator(ctx, fromFloor, toFloor, call)
+	return trip, err
+}
+
+// CancelRequest withdraws a floor request that has not been picked up yet
+func (m *Manager) CancelRequest(ctx context.Context, id string) (Trip, error) {
+	m.trips.mu.Lock()
+	trip, ok := m.trips.trips[id]
+	if !ok {
+		m.trips.mu.Unlock()
+		return Trip{}, domain.NewNotFoundError("request not found", nil).
+			WithContext("trip_id", id)
+	}
+	if trip.State != TripAssigned {
+		state := trip.State
+		m.trips.mu.Unlock()
+		return Trip{}, domain.NewConflictError("request can no longer be cancelled", nil).
+			WithContext("trip_id", id).
+			WithContext("state", string(state))
+	}
+
+	if !m.withdrawPickup(trip) {
+		// The elevator already flushed the pickup; its stop_served event
+		// will move the trip along
+		m.trips.mu.Unlock()
+		return Trip{}, domain.NewConflictError("request can no longer be cancelled", nil).
+			WithContext("trip_id", id).
+			WithContext("state", string(TripPickedUp))
+	}
+	cancelled := m.trips.transition(trip, TripCancelled, time.Now())
+	m.trips.mu.Unlock()
+
+	m.publishTrip(events.TypeRequestCancelled, cancelled)
+	m.logger.InfoContext(ctx, "request cancelled",
+		slog.String("trip_id", id),
+		slog.String("elevator", cancelled.Elevator),
+		slog.Int("fromFloor", cancelled.FromFloor),
+		slog.Int("toFloor", cancelled.ToFloor))
+	return cancelled, nil
+}
+
+// withdrawPickup removes a waiting trip's pickup from its elevator and
+// reports false if the elevator already served it. A pickup entry shared with
+// another waiting trip stays for that trip. Callers must hold m.trips.mu.
+func (m *Manager) withdrawPickup(trip *Trip) bool {
+	el := m.GetElevator(trip.Elevator)
+	if el == nil || m.trips.sharesPickup(trip) {
+		return true
+	}
+	keepStop := m.trips.hasDropOffAt(trip.Elevator, trip.Direction, trip.FromFloor)
+	return el.CancelRequest(trip.Direction, domain.NewFloor(trip.FromFloor), domain.NewFloor(trip.ToFloor), keepStop)
+}
+
+// reassignTrips moves the waiting trips of a failed elevator to healthy
+// elevators. Passengers already on board stay with their car.
+func (m *Manager) reassignTrips(name string) {
+	var candidates []*elevator.Elevator
+	for _, e := range m.GetElevators() {
+		if e.Name() != name && !e.IsFaulted() && !e.IsMarkedForDeletion() {
+			candidates = append(candidates, e)
+		}
+	}
+	if len(candidates) == 0 {
+		m.logger.Warn("no healthy elevator to take over requests",
+			slog.String("elevator", name))
+		return
+	}
+
+	m.trips.mu.Lock()
+	var waiting []*Trip
+	for _, trip := range m.trips.trips {
+		if trip.Elevator == name && trip.State == TripAssigned {
+			waiting = append(waiting, trip)
+		}
+	}
+	// Priority calls get the first pick of the remaining cars
+	sort.Slice(waiting, func(i, j int) bool {
+		if ri, rj := waiting[i].Priority.Rank(), waiting[j].Priority.Rank(); ri != rj {
+			return ri > rj
+		}
+		return waiting[i].CreatedAt.Before(waiting[j].CreatedAt)
+	})
+
+	var moved []Trip
+	for _, trip := range waiting {
+		fromFloor, toFloor := domain.NewFloor(trip.FromFloor), domain.NewFloor(trip.ToFloor)
+		target, err := m.chooseElevator(candidates, trip.Direction, fromFloor, toFloor, trip.CallOptions)
+		if err != nil {
+			m.logger.Warn("failed to reassign request",
+				slog.String("trip_id", trip.ID),
+				slog.String("elevator", name),
+				slog.String("error", err.Error()))
+			continue
+		}
+		if !m.withdrawPickup(trip) {
+			continue
+		}
+		// The new car's wait is expected to start now, on top of the time
+		// the passengers already waited
+		now := time.Now()
+		wait, ride := estimateTrip(target, trip.FromFloor, trip.ToFloor)
+		trip.Elevator = target.Name()
+		trip.UpdatedAt = now
+		trip.EstimatedWaitSeconds = (now.Sub(trip.CreatedAt) + wait).Seconds()
+		trip.EstimatedRideSeconds = ride.Seconds()
+		target.RequestCall(trip.Direction, fromFloor, toFloor, trip.CallOptions)
+		moved = append(moved, *trip)
+	}
+	m.trips.mu.Unlock()
+
+	for _, trip := range moved {
+		data := tripEventData(trip)
+		data["reassigned_from"] = name
+		m.events.Publish(events.TypeRequestAssigned, trip.Elevator, data)
+		m.logger.Info("request reassigned from failed elevator",
+			slog.String("trip_id", trip.ID),
+			slog.String("from_elevator", name),
+			slog.String("to_elevator", trip.Elevator))
+	}
+}
+
+// trackTrips advances trips from elevator events until the manager stops.
+// Trips that made no progress are checked against their elevators every
+// tripSweepInterval, and all of them are when the ledger falls behind the
+// event bus and misses events.
+func (m *Manager) trackTrips(sub *events.Subscription) {
+	sweep := time.NewTicker(tripSweepInterval)
+	defer sweep.Stop()
+
+	for {
+		select {
+		case <-m.ctx.Done():
+			sub.Close()
+			return
+		case now := <-sweep.C:
+			m.reconcileTrips(now.Add(-tripSweepInterval), now)
+		case event, ok := <-sub.C:
+			if !ok {
+				m.logger.Warn("trip ledger fell behind the event bus, resubscribing and reconciling trips")
+				metrics.IncError("trip_ledger_overflow", constants.ComponentManager)
+				sub = m.events.Subscribe(tripEventBuffer)
+				now := time.Now()
+				m.reconcileTrips(now, now)
+				continue
+			}
+			m.applyTripEvent(event)
+		}
+	}
+}
+
+// reconcileTrips advances the trips that made no progress since staleBefore
+// from the stops of their elevators and publishes the changes. The times of
+// the missed transitions are unknown, so they are not measured.
+func (m *Manager) reconcileTrips(staleBefore, now time.Time) {
+	changed := m.trips.reconcile(m.GetElevator, staleBefore, now)
+	for _, trip := range changed {
+		m.publishTripChange(trip)
+	}
+	if len(changed) > 0 {
+		m.logger.Info("trips reconciled with their elevators",
+			slog.Int("changed", len(changed)))
+	}
+}
+
+func (m *Manager) applyTripEvent(event events.Event) {
+	var changed []Trip
+	switch event.Type {
+	case events.TypeStopServed:
+		floor, _ := event.Data["floor"].(int)
+		direction, _ := event.Data["direction"].(string)
+		changed = m.trips.served(event.Elevator, floor, domain.Direction(direction), event.Timestamp)
+	case events.TypeElevatorRemoved:
+		changed = m.trips.elevatorRemoved(event.Elevator)
+	case events.TypeElevatorFault:
+		// Reassignment publishes events itself, so keep it off this loop
+		if state, _ := event.Data["circuit_breaker_state"].(string); state == "open" {
+			go m.reassignTrips(event.Elevator)
+		}
+		return
+	default:
+		return
+	}
+
+	for _, trip := range changed {
+		recordJourney(m.building(), trip)
+		m.traceTrip(trip)
+		recordPriorityTrip(trip)
+		m.serviceLevels.recordTrip(trip)
+		m.publishTripChange(trip)
+	}
+}
+
+// publishTripChange publishes the event of the state a trip moved to. Trips
+// the ledger gave up on are reported as cancelled with the unknown state.
+func (m *Manager) publishTripChange(trip Trip) {
+	switch trip.State {
+	case TripPickedUp:
+		m.publishTrip(events.TypeRequestPickedUp, trip)
+	case TripCompleted:
+		m.publishTrip(events.TypeRequestCompleted, trip)
+	case TripCancelled, TripUnknown:
+		m.publishTrip(events.TypeRequestCancelled, trip)
+	}
+}
+
+func (m *Manager) publishTrip(eventType events.Type, trip Trip) {
+	m.events.Publish(eventType, trip.Elevator, tripEventData(trip))
+}
+
+func tripEventData(trip Trip) map[string]any {
+	data := map[string]any{
+		"trip_id":      trip.ID,
+		"state":        string(trip.State),
+		"from_floor":   trip.FromFloor,
+		"to_floor":     trip.ToFloor,
+		"direction":    string(trip.Direction),
+		"priority":     trip.Priority.String(),
+		"accessible":   trip.Accessible,
+		"requested_at": trip.CreatedAt,
+	}
+	if trip.PickedUpAt != nil {
+		data["picked_up_at"] = *trip.PickedUpAt
+	}
+	if trip.DroppedOffAt != nil {
+		data["dropped_off_at"] = *trip.DroppedOffAt
+	}
+	return data
+}
@@ -0,0 +1,41 @@
+package manager
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+	"github.com/slavakukuyev/elevator-go/internal/elevator"
+)
+
+// TuneElevator changes the speed, door time, overload threshold or circuit
+// breaker settings of a running elevator
+func (m *Manager) TuneElevator(ctx context.Context, name string, patch elevator.TuningPatch) (elevator.Tuning, error) {
+	el := m.GetElevator(name)
+	if el == nil {
+		return elevator.Tuning{}, domain.NewNotFoundError("elevator not found", nil).
+			WithContext("name", name)
+	}
+
+	tuning, err := el.Tune(patch)
+	if err != nil {
+		return tuning, err
+	}
+
+	m.logger.InfoContext(ctx, "elevator tuning updated",
+		slog.String("elevator", name),
+		slog.Duration("floor_duration", tuning.EachFloorDuration),
+		slog.Duration("door_duration", tuning.OpenDoorDuration),
+		slog.Int("overload_threshold", tuning.OverloadThreshold))
+	return tuning, nil
+}
+
+// ElevatorTuning returns the current settings of an elevator
+func (m *Manager) ElevatorTuning(name string) (elevator.Tuning, error) {
+	el := m.GetElevator(name)
+	if el == nil {
+		return elevator.Tuning{}, domain.NewNotFoundError("elevator not found", nil).
+			WithContext("name", name)
+	}
+	return el.Tuning(), nil
+}
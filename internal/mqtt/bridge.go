@@ -0,0 +1,425 @@
+// Package mqtt bridges the elevator manager to MQTT-speaking field hardware.
+//
+// Hall call panels publish calls to per-floor topics and the bridge feeds
+// them into Manager.RequestElevator, answering on a per-floor assignment
+// topic. Car position indicators subscribe to retained per-elevator topics
+// that the bridge keeps up to date with each car's floor, direction and door
+// state.
+//
+// Topic layout, relative to "{prefix}/{building}":
+//
+//	floor/{n}/call               <- hall call, payload {"to_floor": N} or a bare integer
+//	floor/{n}/assignment         -> assignment result for the call (not retained)
+//	elevator/{name}/floor        -> current floor (retained)
+//	elevator/{name}/direction    -> "up", "down" or "idle" (retained)
+//	elevator/{name}/door         -> "open" or "closed" (retained)
+//	bridge/status                -> "online"/"offline" (retained, last will)
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	paho "github.com/eclipse/paho.mqtt.golang"
+
+	"github.com/slavakukuyev/elevator-go/internal/constants"
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+	"github.com/slavakukuyev/elevator-go/internal/infra/config"
+	"github.com/slavakukuyev/elevator-go/internal/manager"
+	"github.com/slavakukuyev/elevator-go/metrics"
+)
+
+const (
+	bridgeOnline  = "online"
+	bridgeOffline = "offline"
+	doorOpen      = "open"
+	doorClosed    = "closed"
+	directionIdle = "idle"
+)
+
+// CallPayload is the JSON body accepted on a floor call topic
+type CallPayload struct {
+	ToFloor *int `json:"to_floor"`
+}
+
+// AssignmentPayload is published in response to every hall call
+type AssignmentPayload struct {
+	FromFloor    int       `json:"from_floor"`
+	ToFloor      int       `json:"to_floor,omitempty"`
+	ElevatorName string    `json:"elevator_name,omitempty"`
+	Error        string    `json:"error,omitempty"`
+	ErrorType    string    `json:"error_type,omitempty"`
+	Timestamp    time.Time `json:"timestamp"`
+}
+
+// carState is the last display state published for an elevator
+type carState struct {
+	floor     string
+	direction string
+	door      string
+}
+
+// Bridge connects the elevator manager to an MQTT broker
+type Bridge struct {
+	cfg     *config.Config
+	manager *manager.Manager
+	client  paho.Client
+	topics  Topics
+	logger  *slog.Logger
+
+	mu        sync.Mutex
+	published map[string]carState
+	resync    chan struct{}
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// New creates a bridge for the given manager. The broker connection is
+// established by Start.
+func New(cfg *config.Config, manager *manager.Manager) *Bridge {
+	ctx, cancel := context.WithCancel(context.Background())
+	b := &Bridge{
+		cfg:       cfg,
+		manager:   manager,
+		topics:    NewTopics(cfg.MQTTTopicPrefix, cfg.MQTTBuildingID),
+		logger:    slog.With(slog.String("component", constants.ComponentMQTTBridge)),
+		published: make(map[string]carState),
+		resync:    make(chan struct{}, 1),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+
+	opts := paho.NewClientOptions().
+		AddBroker(cfg.MQTTBrokerURL).
+		SetClientID(cfg.MQTTClientID).
+		SetUsername(cfg.MQTTUsername).
+		SetPassword(cfg.MQTTPassword).
+		SetConnectTimeout(cfg.MQTTConnectTimeout).
+		SetAutoReconnect(true).
+		SetCleanSession(true).
+		SetOrderMatters(false).
+		SetWill(b.topics.BridgeStatus(), bridgeOffline, b.qos(), true).
+		SetOnConnectHandler(b.onConnect).
+		SetConnectionLostHandler(func(_ paho.Client, err error) {
+			b.logger.Warn("mqtt connection lost", slog.String("error", err.Error()))
+			metrics.IncError("connection_lost", constants.ComponentMQTTBridge)
+		})
+	b.client = paho.NewClient(opts)
+
+	return b
+}
+
+// Start connects to the broker and begins publishing car state. It returns
+// an error if the initial connection cannot be established in time.
+func (b *Bridge) Start() error {
+	token := b.client.Connect()
+	if !token.WaitTimeout(b.cfg.MQTTConnectTimeout) {
+		b.client.Disconnect(0)
+		return domain.NewInternalError("mqtt connect timed out", nil).
+			WithContext("broker", b.cfg.MQTTBrokerURL)
+	}
+	if err := token.Error(); err != nil {
+		return domain.NewInternalError("failed to connect to mqtt broker", err).
+			WithContext("broker", b.cfg.MQTTBrokerURL)
+	}
+
+	b.logger.Info("mqtt bridge connected",
+		slog.String("broker", b.cfg.MQTTBrokerURL),
+		slog.String("building", b.cfg.MQTTBuildingID))
+
+	b.wg.Add(1)
+	go b.publishLoop()
+
+	return nil
+}
+
+// Stop announces the bridge as offline and disconnects from the broker
+func (b *Bridge) Stop() {
+	b.cancel()
+	b.wg.Wait()
+
+	if b.client.IsConnected() {
+		b.publish(b.topics.BridgeStatus(), true, bridgeOffline)
+		b.client.Disconnect(250)
+	}
+
+	b.logger.Info("mqtt bridge stopped")
+}
+
+// onConnect (re)subscribes to call topics and announces the bridge. It runs
+// after every successful connect, including automatic reconnects.
+func (b *Bridge) onConnect(client paho.Client) {
+	token := client.Subscribe(b.topics.CallFilter(), b.qos(), b.handleCall)
+	if token.WaitTimeout(b.cfg.MQTTConnectTimeout) && token.Error() != nil {
+		b.logger.Error("failed to subscribe to call topics",
+			slog.String("topic", b.topics.CallFilter()),
+			slog.String("error", token.Error().Error()))
+		metrics.IncError("subscribe_error", constants.ComponentMQTTBridge)
+	}
+
+	// Retained messages may have been lost if the broker restarted, so force a
+	// full republish of the latest status.
+	b.mu.Lock()
+	b.published = make(map[string]carState)
+	b.mu.Unlock()
+
+	b.publish(b.topics.BridgeStatus(), true, bridgeOnline)
+
+	select {
+	case b.resync <- struct{}{}:
+	default:
+	}
+}
+
+// handleCall turns a hall call message into an elevator request
+func (b *Bridge) handleCall(_ paho.Client, msg paho.Message) {
+	fromFloor, err := b.topics.ParseCallTopic(msg.Topic())
+	if err != nil {
+		b.logger.Warn("ignoring call on malformed topic",
+			slog.String("topic", msg.Topic()),
+			slog.String("error", err.Error()))
+		metrics.IncError("validation_error", constants.ComponentMQTTBridge)
+		return
+	}
+
+	result := AssignmentPayload{FromFloor: fromFloor, Timestamp: time.Now()}
+
+	toFloor, err := parseCallPayload(msg.Payload())
+	if err == nil {
+		result.ToFloor = toFloor
+		err = b.requestElevator(fromFloor, toFloor, &result)
+	}
+
+	if err != nil {
+		result.Error = err.Error()
+		var domainErr *domain.DomainError
+		if errors.As(err, &domainErr) {
+			result.Error = domainErr.Message
+			result.ErrorType = string(domainErr.Type)
+		}
+		b.logger.Warn("hall call rejected",
+			slog.Int("from_floor", fromFloor),
+			slog.String("error", err.Error()))
+	}
+
+	body, err := json.Marshal(result)
+	if err != nil {
+		b.logger.Error("failed to encode assignment", slog.String("error", err.Error()))
+		return
+	}
+	b.publish(b.topics.Assignment(fromFloor), false, body)
+}
+
+func (b *Bridge) requestElevator(fromFloor, toFloor int, result *AssignmentPayload) error {
+	if _, err := domain.NewFloorWithValidation(fromFloor); err != nil {
+		return err
+	}
+	if _, err := domain.NewFloorWithValidation(toFloor); err != nil {
+		return err
+	}
+
+	el, err := b.manager.RequestElevator(b.ctx, fromFloor, toFloor)
+	if err != nil {
+		return err
+	}
+	if el == nil {
+		return domain.NewInternalError("no elevator assigned", nil)
+	}
+
+	result.ElevatorName = el.Name()
+	b.logger.Info("hall call assigned",
+		slog.Int("from_floor", fromFloor),
+		slog.Int("to_floor", toFloor),
+		slog.String("elevator", el.Name()))
+	return nil
+}
+
+// publishLoop publishes car display state whenever the status broadcaster
+// reports a change, and republishes everything after a reconnect
+func (b *Bridge) publishLoop() {
+	defer b.wg.Done()
+
+	broadcaster := b.manager.Broadcaster()
+	sub := broadcaster.Subscribe(0)
+	defer sub.Close()
+
+	for {
+		select {
+		case <-b.ctx.Done():
+			return
+		case update, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			b.publishStatus(update.Status)
+		case <-b.resync:
+			if latest := broadcaster.Latest(); latest != nil {
+				b.publishStatus(latest.Status)
+			}
+		}
+	}
+}
+
+// publishStatus publishes retained state for every car that changed since
+// the last call and clears the topics of cars that no longer exist
+func (b *Bridge) publishStatus(statuses map[string]domain.ElevatorStatus) {
+	if !b.client.IsConnected() {
+		return
+	}
+
+	current := make(map[string]carState, len(statuses))
+	for name, status := range statuses {
+		current[name] = stateFromStatus(status)
+	}
+
+	b.mu.Lock()
+	previous := b.published
+	b.published = current
+	b.mu.Unlock()
+
+	for name, state := range current {
+		last, seen := previous[name]
+		if !seen || last.floor != state.floor {
+			b.publish(b.topics.Elevator(name, "floor"), true, state.floor)
+		}
+		if !seen || last.direction != state.direction {
+			b.publish(b.topics.Elevator(name, "direction"), true, state.direction)
+		}
+		if !seen || last.door != state.door {
+			b.publish(b.topics.Elevator(name, "door"), true, state.door)
+		}
+	}
+
+	// An empty retained payload deletes the retained message on the broker.
+	for name := range previous {
+		if _, ok := current[name]; ok {
+			continue
+		}
+		for _, field := range []string{"floor", "direction", "door"} {
+			b.publish(b.topics.Elevator(name, field), true, "")
+		}
+	}
+}
+
+func (b *Bridge) publish(topic string, retained bool, payload interface{}) {
+	token := b.client.Publish(topic, b.qos(), retained, payload)
+	if !token.WaitTimeout(b.cfg.MQTTConnectTimeout) {
+		b.logger.Warn("mqtt publish timed out", slog.String("topic", topic))
+		metrics.IncError("publish_timeout", constants.ComponentMQTTBridge)
+		return
+	}
+	if err := token.Error(); err != nil {
+		b.logger.Warn("mqtt publish failed",
+			slog.String("topic", topic),
+			slog.String("error", err.Error()))
+		metrics.IncError("publish_error", constants.ComponentMQTTBridge)
+	}
+}
+
+func (b *Bridge) qos() byte {
+	return byte(b.cfg.MQTTQoS)
+}
+
+func stateFromStatus(status domain.ElevatorStatus) carState {
+	door := doorClosed
+	if status.DoorOpen {
+		door = doorOpen
+	}
+	// DirectionIdle is the empty string, which would clear a retained topic
+	direction := status.Direction.String()
+	if status.Direction == domain.DirectionIdle {
+		direction = directionIdle
+	}
+	return carState{
+		floor:     status.CurrentFloor.String(),
+		direction: direction,
+		door:      door,
+	}
+}
+
+// parseCallPayload accepts either {"to_floor": N} or a bare integer
+func parseCallPayload(payload []byte) (int, error) {
+	trimmed := strings.TrimSpace(string(payload))
+	if n, err := strconv.Atoi(trimmed); err == nil {
+		return n, nil
+	}
+
+	var call CallPayload
+	if err := json.Unmarshal([]byte(trimmed), &call); err != nil {
+		return 0, domain.NewValidationError("call payload must be an integer or {\"to_floor\": N}", err)
+	}
+	if call.ToFloor == nil {
+		return 0, domain.NewValidationError("call payload is missing to_floor", nil)
+	}
+	return *call.ToFloor, nil
+}
+
+// Topics builds and parses the bridge's topic names
+type Topics struct {
+	base string
+}
+
+// NewTopics returns the topic layout rooted at "{prefix}/{buildingID}"
+func NewTopics(prefix, buildingID string) Topics {
+	return Topics{base: strings.TrimSuffix(prefix, "/") + "/" + buildingID}
+}
+
+// CallFilter is the subscription filter matching every floor's call topic
+func (t Topics) CallFilter() string {
+	return t.base + "/floor/+/call"
+}
+
+// Call returns the call topic for a floor
+func (t Topics) Call(floor int) string {
+	return fmt.Sprintf("%s/floor/%d/call", t.base, floor)
+}
+
+// Assignment returns the topic on which call results for a floor are published
+func (t Topics) Assignment(floor int) string {
+	return fmt.Sprintf("%s/floor/%d/assignment", t.base, floor)
+}
+
+// Elevator returns the retained state topic for one field of a car
+func (t Topics) Elevator(name, field string) string {
+	return fmt.Sprintf("%s/elevator/%s/%s", t.base, sanitizeLevel(name), field)
+}
+
+// BridgeStatus returns the bridge's online/offline topic
+func (t Topics) BridgeStatus() string {
+	return t.base + "/bridge/status"
+}
+
+// ParseCallTopic extracts the floor number from a call topic
+func (t Topics) ParseCallTopic(topic string) (int, error) {
+	rest, ok := strings.CutPrefix(topic, t.base+"/floor/")
+	if !ok {
+		return 0, domain.NewValidationError("topic is not a call topic", nil).
+			WithContext("topic", topic)
+	}
+	floorPart, ok := strings.CutSuffix(rest, "/call")
+	if !ok || strings.Contains(floorPart, "/") {
+		return 0, domain.NewValidationError("topic is not a call topic", nil).
+			WithContext("topic", topic)
+	}
+	floor, err := strconv.Atoi(floorPart)
+	if err != nil {
+		return 0, domain.NewValidationError("call topic floor must be an integer", err).
+			WithContext("topic", topic)
+	}
+	return floor, nil
+}
+
+// sanitizeLevel makes an arbitrary elevator name safe to use as a single
+// topic level by replacing separators and wildcards
+func sanitizeLevel(name string) string {
+	return strings.NewReplacer("/", "_", "+", "_", "#", "_").Replace(name)
+}
@@ -0,0 +1,290 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	paho "github.com/eclipse/paho.mqtt.golang"
+	mochi "github.com/mochi-mqtt/server/v2"
+	"github.com/mochi-mqtt/server/v2/hooks/auth"
+	"github.com/mochi-mqtt/server/v2/listeners"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slavakukuyev/elevator-go/internal/factory"
+	"github.com/slavakukuyev/elevator-go/internal/infra/config"
+	"github.com/slavakukuyev/elevator-go/internal/manager"
+)
+
+// startTestBroker runs an embedded MQTT broker on a random local port and
+// returns its URL
+func startTestBroker(t *testing.T) string {
+	t.Helper()
+
+	server := mochi.New(&mochi.Options{
+		Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+	require.NoError(t, server.AddHook(new(auth.AllowHook), nil))
+
+	tcp := listeners.NewTCP(listeners.Config{ID: "test", Address: "127.0.0.1:0"})
+	require.NoError(t, server.AddListener(tcp))
+
+	go func() {
+		_ = server.Serve()
+	}()
+	t.Cleanup(func() { _ = server.Close() })
+
+	return "tcp://" + tcp.Address()
+}
+
+func buildBridgeTestConfig(t *testing.T, brokerURL string) *config.Config {
+	t.Helper()
+
+	require.NoError(t, os.Setenv("ENV", "testing"))
+	defer func() { _ = os.Unsetenv("ENV") }()
+
+	cfg, err := config.InitConfig()
+	require.NoError(t, err)
+
+	cfg.MQTTEnabled = true
+	cfg.MQTTBrokerURL = brokerURL
+	cfg.MQTTClientID = "bridge-" + t.Name()
+	cfg.MQTTBuildingID = "test"
+	cfg.MQTTConnectTimeout = 2 * time.Second
+	return cfg
+}
+
+// setupBridge starts a manager with one elevator and a bridge connected to
+// an embedded broker, and returns an observer client on the same broker
+func setupBridge(t *testing.T) (*Bridge, *manager.Manager, paho.Client) {
+	t.Helper()
+
+	brokerURL := startTestBroker(t)
+	cfg := buildBridgeTestConfig(t, brokerURL)
+
+	m := manager.New(cfg, &factory.StandardElevatorFactory{})
+	require.NoError(t, m.AddElevator(context.Background(), cfg, "A", 0, 9,
+		cfg.EachFloorDuration, cfg.OpenDoorDuration, cfg.DefaultOverloadThreshold))
+	t.Cleanup(m.Shutdown)
+
+	bridge := New(cfg, m)
+	require.NoError(t, bridge.Start())
+	t.Cleanup(bridge.Stop)
+
+	observer := paho.NewClient(paho.NewClientOptions().
+		AddBroker(brokerURL).
+		SetClientID("observer-" + t.Name()))
+	token := observer.Connect()
+	require.True(t, token.WaitTimeout(2*time.Second))
+	require.NoError(t, token.Error())
+	t.Cleanup(func() { observer.Disconnect(0) })
+
+	return bridge, m, observer
+}
+
+// collector records the latest payload per topic
+type collector struct {
+	mu     sync.Mutex
+	latest map[string]string
+	seen   map[string][]string
+}
+
+func subscribe(t *testing.T, client paho.Client, filter string) *collector {
+	t.Helper()
+
+	c := &collector{latest: make(map[string]string), seen: make(map[string][]string)}
+	token := client.Subscribe(filter, 1, func(_ paho.Client, msg paho.Message) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.latest[msg.Topic()] = string(msg.Payload())
+		c.seen[msg.Topic()] = append(c.seen[msg.Topic()], string(msg.Payload()))
+	})
+	require.True(t, token.WaitTimeout(2*time.Second))
+	require.NoError(t, token.Error())
+	return c
+}
+
+func (c *collector) get(topic string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	v, ok := c.latest[topic]
+	return v, ok
+}
+
+func (c *collector) sawValue(topic, value string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, v := range c.seen[topic] {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func TestBridge_PublishesRetainedCarState(t *testing.T) {
+	bridge, m, observer := setupBridge(t)
+	topics := bridge.topics
+
+	// Subscribing after the bridge started must still deliver retained state
+	time.Sleep(50 * time.Millisecond)
+	c := subscribe(t, observer, "building/test/#")
+
+	assert.Eventually(t, func() bool {
+		floor, _ := c.get(topics.Elevator("A", "floor"))
+		direction, _ := c.get(topics.Elevator("A", "direction"))
+		door, _ := c.get(topics.Elevator("A", "door"))
+		status, _ := c.get(topics.BridgeStatus())
+		return floor == "0" && direction == "idle" && door == "closed" && status == "online"
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// Removing an elevator clears its retained topics
+	require.NoError(t, m.DeleteElevator(context.Background(), "A"))
+	assert.Eventually(t, func() bool {
+		floor, ok := c.get(topics.Elevator("A", "floor"))
+		return ok && floor == ""
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestBridge_HallCallDispatchesElevator(t *testing.T) {
+	bridge, _, observer := setupBridge(t)
+	topics := bridge.topics
+
+	c := subscribe(t, observer, "building/test/#")
+
+	token := observer.Publish(topics.Call(2), 1, false, `{"to_floor": 5}`)
+	require.True(t, token.WaitTimeout(2*time.Second))
+	require.NoError(t, token.Error())
+
+	var assignment AssignmentPayload
+	require.Eventually(t, func() bool {
+		raw, ok := c.get(topics.Assignment(2))
+		return ok && json.Unmarshal([]byte(raw), &assignment) == nil
+	}, 2*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, "A", assignment.ElevatorName)
+	assert.Equal(t, 2, assignment.FromFloor)
+	assert.Equal(t, 5, assignment.ToFloor)
+	assert.Empty(t, assignment.Error)
+
+	// The car display follows the elevator to the destination
+	assert.Eventually(t, func() bool {
+		floor, _ := c.get(topics.Elevator("A", "floor"))
+		return floor == "5"
+	}, 3*time.Second, 10*time.Millisecond)
+	assert.True(t, c.sawValue(topics.Elevator("A", "direction"), "up"))
+}
+
+func TestBridge_RejectsInvalidCalls(t *testing.T) {
+	tests := []struct {
+		name      string
+		floor     int
+		payload   string
+		errorType string
+	}{
+		{name: "malformed payload", floor: 1, payload: "up please", errorType: "validation"},
+		{name: "missing destination", floor: 1, payload: `{}`, errorType: "validation"},
+		{name: "same floor", floor: 3, payload: "3", errorType: "validation"},
+		{name: "out of range", floor: 1, payload: "500", errorType: "validation"},
+	}
+
+	bridge, _, observer := setupBridge(t)
+	topics := bridge.topics
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := subscribe(t, observer, topics.Assignment(tt.floor))
+			defer observer.Unsubscribe(topics.Assignment(tt.floor))
+
+			token := observer.Publish(topics.Call(tt.floor), 1, false, tt.payload)
+			require.True(t, token.WaitTimeout(2*time.Second))
+
+			var assignment AssignmentPayload
+			require.Eventually(t, func() bool {
+				raw, ok := c.get(topics.Assignment(tt.floor))
+				return ok && json.Unmarshal([]byte(raw), &assignment) == nil
+			}, 2*time.Second, 10*time.Millisecond)
+
+			assert.Empty(t, assignment.ElevatorName)
+			assert.NotEmpty(t, assignment.Error)
+			assert.Equal(t, tt.errorType, assignment.ErrorType)
+		})
+	}
+}
+
+func TestBridge_StartFailsWithoutBroker(t *testing.T) {
+	cfg := buildBridgeTestConfig(t, "tcp://127.0.0.1:1")
+	cfg.MQTTConnectTimeout = 500 * time.Millisecond
+
+	bridge := New(cfg, manager.New(cfg, &factory.StandardElevatorFactory{}))
+	assert.Error(t, bridge.Start())
+}
+
+func TestTopics_ParseCallTopic(t *testing.T) {
+	topics := NewTopics("building", "hq")
+
+	tests := []struct {
+		topic   string
+		floor   int
+		wantErr bool
+	}{
+		{topic: "building/hq/floor/4/call", floor: 4},
+		{topic: "building/hq/floor/-2/call", floor: -2},
+		{topic: "building/hq/floor/x/call", wantErr: true},
+		{topic: "building/other/floor/4/call", wantErr: true},
+		{topic: "building/hq/floor/4/assignment", wantErr: true},
+		{topic: "building/hq/floor/4/5/call", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.topic, func(t *testing.T) {
+			floor, err := topics.ParseCallTopic(tt.topic)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.floor, floor)
+			assert.Equal(t, tt.topic, topics.Call(floor))
+		})
+	}
+}
+
+func TestParseCallPayload(t *testing.T) {
+	tests := []struct {
+		payload string
+		want    int
+		wantErr bool
+	}{
+		{payload: "7", want: 7},
+		{payload: " -1\n", want: -1},
+		{payload: `{"to_floor": 0}`, want: 0},
+		{payload: `{"to_floor": "3"}`, wantErr: true},
+		{payload: `{"floor": 3}`, wantErr: true},
+		{payload: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%q", tt.payload), func(t *testing.T) {
+			got, err := parseCallPayload([]byte(tt.payload))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestTopics_ElevatorSanitizesName(t *testing.T) {
+	topics := NewTopics("building/", "hq")
+	assert.Equal(t, "building/hq/elevator/east_1__/floor", topics.Elevator("east/1#+", "floor"))
+}
@@ -0,0 +1,283 @@
+// Package sla evaluates service-level objectives against the indicators each
+// building's manager computes and raises alerts when they are breached.
+//
+// Every evaluation interval each rule is checked against every monitored
+// building. When an indicator over the rule's window rises above the
+// threshold the rule starts firing: a warning is logged and an sla_breached
+// event is published on the building's bus, which reaches WebSocket clients
+// and webhooks. Once the indicator is back within the threshold an
+// sla_resolved event follows. The engine is also a health checker: firing
+// warnings degrade readiness and firing critical rules fail it.
+package sla
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/slavakukuyev/elevator-go/internal/constants"
+	"github.com/slavakukuyev/elevator-go/internal/events"
+	"github.com/slavakukuyev/elevator-go/internal/infra/config"
+	"github.com/slavakukuyev/elevator-go/internal/infra/health"
+	"github.com/slavakukuyev/elevator-go/metrics"
+)
+
+// Source computes the indicators of one building and publishes its events;
+// *manager.Manager is a Source
+type Source interface {
+	ServiceLevel(indicator string, percentile float64, window time.Duration) (float64, int)
+	Events() *events.Bus
+}
+
+// State is whether an alert's rule is met
+type State string
+
+const (
+	// StateOK alerts have their indicator within the threshold
+	StateOK State = "ok"
+	// StateFiring alerts have their indicator above the threshold
+	StateFiring State = "firing"
+)
+
+// IsValid reports whether s is a known alert state
+func (s State) IsValid() bool {
+	return s == StateOK || s == StateFiring
+}
+
+// Alert is the standing of one rule in one building
+type Alert struct {
+	Rule        string     `json:"rule"`
+	Building    string     `json:"building"`
+	Indicator   string     `json:"indicator"`
+	Percentile  float64    `json:"percentile,omitempty"`
+	Threshold   float64    `json:"threshold"`
+	Window      string     `json:"window"`
+	Severity    string     `json:"severity"`
+	State       State      `json:"state"`
+	Value       float64    `json:"value"`                  // Indicator at the last evaluation
+	Samples     int        `json:"samples"`                // Observations behind the value
+	Since       *time.Time `json:"since,omitempty"`        // When the rule started firing
+	EvaluatedAt *time.Time `json:"evaluated_at,omitempty"` // Unset until the first evaluation
+}
+
+// monitor holds the alerts of one building
+type monitor struct {
+	building string
+	source   Source
+	alerts   []Alert // One per rule, in rule order
+}
+
+// Engine evaluates SLA rules against monitored buildings
+type Engine struct {
+	rules    []config.SLARule
+	interval time.Duration
+	logger   *slog.Logger
+
+	mu       sync.Mutex
+	monitors []*monitor
+	started  bool
+
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewEngine creates an engine that evaluates rules every interval once
+// started
+func NewEngine(rules []config.SLARule, interval time.Duration) *Engine {
+	return &Engine{
+		rules:    rules,
+		interval: interval,
+		logger:   slog.With(slog.String("component", constants.ComponentSLA)),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Monitor evaluates every rule against a building's indicators
+func (e *Engine) Monitor(building string, source Source) {
+	m := &monitor{building: building, source: source}
+	for _, rule := range e.rules {
+		m.alerts = append(m.alerts, Alert{
+			Rule:       rule.Name,
+			Building:   building,
+			Indicator:  rule.Indicator,
+			Percentile: rule.Percentile,
+			Threshold:  rule.Threshold,
+			Window:     rule.Window.String(),
+			Severity:   rule.Severity,
+			State:      StateOK,
+		})
+		metrics.SetSLAAlertFiring(building, rule.Name, rule.Severity, false)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.monitors = append(e.monitors, m)
+}
+
+// Start evaluates the rules every interval until Stop is called
+func (e *Engine) Start() {
+	e.mu.Lock()
+	e.started = true
+	e.mu.Unlock()
+
+	go func() {
+		defer close(e.done)
+		ticker := time.NewTicker(e.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-e.stop:
+				return
+			case now := <-ticker.C:
+				e.evaluate(now)
+			}
+		}
+	}()
+}
+
+// Stop ends the evaluation loop started by Start
+func (e *Engine) Stop() {
+	e.stopOnce.Do(func() {
+		close(e.stop)
+	})
+
+	e.mu.Lock()
+	started := e.started
+	e.mu.Unlock()
+	if started {
+		<-e.done
+	}
+}
+
+// Alerts returns the alerts of every monitored building, in the order the
+// buildings were monitored and the rules declared
+func (e *Engine) Alerts() []Alert {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var alerts []Alert
+	for _, m := range e.monitors {
+		alerts = append(alerts, m.alerts...)
+	}
+	return alerts
+}
+
+// evaluate checks every rule against every monitored building
+func (e *Engine) evaluate(now time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, m := range e.monitors {
+		for i, rule := range e.rules {
+			value, samples := m.source.ServiceLevel(rule.Indicator, rule.Percentile, rule.Window)
+			e.update(m, &m.alerts[i], value, samples, now)
+		}
+	}
+}
+
+// update records an evaluation of an alert and announces state changes;
+// callers must hold e.mu
+func (e *Engine) update(m *monitor, alert *Alert, value float64, samples int, now time.Time) {
+	evaluatedAt := now
+	alert.Value = value
+	alert.Samples = samples
+	alert.EvaluatedAt = &evaluatedAt
+	metrics.SetSLAIndicator(alert.Building, alert.Rule, value)
+
+	firing := value > alert.Threshold
+	if firing == (alert.State == StateFiring) {
+		return
+	}
+	metrics.SetSLAAlertFiring(alert.Building, alert.Rule, alert.Severity, firing)
+
+	attrs := []any{
+		slog.String("rule", alert.Rule),
+		slog.String("building", alert.Building),
+		slog.String("indicator", alert.Indicator),
+		slog.String("severity", alert.Severity),
+		slog.Float64("value", value),
+		slog.Float64("threshold", alert.Threshold),
+		slog.Int("samples", samples),
+	}
+	if firing {
+		alert.State = StateFiring
+		alert.Since = &evaluatedAt
+		e.logger.Warn("SLA rule breached", attrs...)
+		m.source.Events().Publish(events.TypeSLABreached, "", eventData(*alert))
+		return
+	}
+
+	alert.State = StateOK
+	alert.Since = nil
+	e.logger.Info("SLA rule resolved", attrs...)
+	m.source.Events().Publish(events.TypeSLAResolved, "", eventData(*alert))
+}
+
+func eventData(alert Alert) map[string]any {
+	data := map[string]any{
+		"rule":      alert.Rule,
+		"indicator": alert.Indicator,
+		"severity":  alert.Severity,
+		"value":     alert.Value,
+		"threshold": alert.Threshold,
+		"window":    alert.Window,
+		"samples":   alert.Samples,
+	}
+	if alert.Percentile > 0 {
+		data["percentile"] = alert.Percentile
+	}
+	return data
+}
+
+// Name implements health.HealthChecker
+func (e *Engine) Name() string {
+	return "sla"
+}
+
+// Check implements health.HealthChecker: the engine is unhealthy while a
+// critical rule fires and degraded while a warning fires
+func (e *Engine) Check(ctx context.Context) health.CheckResult {
+	start := time.Now()
+
+	var critical, warning []string
+	for _, alert := range e.Alerts() {
+		if alert.State != StateFiring {
+			continue
+		}
+		name := alert.Rule + "@" + alert.Building
+		if alert.Severity == config.SLASeverityCritical {
+			critical = append(critical, name)
+		} else {
+			warning = append(warning, name)
+		}
+	}
+
+	status := health.StatusHealthy
+	message := "All SLA rules are met"
+	switch {
+	case len(critical) > 0:
+		status = health.StatusUnhealthy
+		message = fmt.Sprintf("Critical SLA rules breached: %s", strings.Join(critical, ", "))
+	case len(warning) > 0:
+		status = health.StatusDegraded
+		message = fmt.Sprintf("SLA rules breached: %s", strings.Join(warning, ", "))
+	}
+
+	return health.CheckResult{
+		Name:    e.Name(),
+		Status:  status,
+		Message: message,
+		Details: map[string]interface{}{
+			"rules":           len(e.rules),
+			"critical_firing": len(critical),
+			"warning_firing":  len(warning),
+		},
+		Duration:  time.Since(start),
+		Timestamp: time.Now(),
+	}
+}
@@ -0,0 +1,149 @@
+package sla
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slavakukuyev/elevator-go/internal/events"
+	"github.com/slavakukuyev/elevator-go/internal/infra/config"
+	"github.com/slavakukuyev/elevator-go/internal/infra/health"
+)
+
+// fakeSource reports the indicator values set on it
+type fakeSource struct {
+	bus *events.Bus
+
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+func newFakeSource() *fakeSource {
+	return &fakeSource{bus: events.NewBus(100), values: make(map[string]float64)}
+}
+
+func (s *fakeSource) set(indicator string, value float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[indicator] = value
+}
+
+func (s *fakeSource) ServiceLevel(indicator string, _ float64, _ time.Duration) (float64, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.values[indicator], 10
+}
+
+func (s *fakeSource) Events() *events.Bus {
+	return s.bus
+}
+
+func testRules(t *testing.T) []config.SLARule {
+	t.Helper()
+	rules, err := config.ParseSLARules("answered-30s:wait_time:p95<=30s:5m:critical;rejections:rejected_requests:<=5%")
+	require.NoError(t, err)
+	return rules
+}
+
+func TestEngine_FiresAndResolves(t *testing.T) {
+	engine := NewEngine(testRules(t), time.Minute)
+	source := newFakeSource()
+	engine.Monitor("main", source)
+	sub := source.bus.Subscribe(10)
+	defer sub.Close()
+
+	alerts := engine.Alerts()
+	require.Len(t, alerts, 2)
+	assert.Equal(t, StateOK, alerts[0].State)
+	assert.Nil(t, alerts[0].EvaluatedAt)
+	assert.Equal(t, "5m0s", alerts[0].Window)
+
+	// A value at the threshold meets the rule
+	source.set(config.SLIWaitTime, 30)
+	engine.evaluate(time.Now())
+	assert.Equal(t, StateOK, engine.Alerts()[0].State)
+	assert.Equal(t, 30.0, engine.Alerts()[0].Value)
+	assert.Equal(t, 10, engine.Alerts()[0].Samples)
+
+	source.set(config.SLIWaitTime, 42)
+	engine.evaluate(time.Now())
+	alert := engine.Alerts()[0]
+	assert.Equal(t, StateFiring, alert.State)
+	require.NotNil(t, alert.Since)
+	assert.Equal(t, StateOK, engine.Alerts()[1].State)
+
+	event := <-sub.C
+	assert.Equal(t, events.TypeSLABreached, event.Type)
+	assert.Equal(t, "answered-30s", event.Data["rule"])
+	assert.Equal(t, config.SLASeverityCritical, event.Data["severity"])
+	assert.Equal(t, 42.0, event.Data["value"])
+	assert.Equal(t, 95.0, event.Data["percentile"])
+
+	// A rule that keeps firing is announced once and keeps its start time
+	since := *alert.Since
+	engine.evaluate(time.Now().Add(time.Minute))
+	assert.Equal(t, since, *engine.Alerts()[0].Since)
+
+	source.set(config.SLIWaitTime, 12)
+	engine.evaluate(time.Now())
+	assert.Equal(t, StateOK, engine.Alerts()[0].State)
+	assert.Nil(t, engine.Alerts()[0].Since)
+
+	event = <-sub.C
+	assert.Equal(t, events.TypeSLAResolved, event.Type)
+	assert.Equal(t, 12.0, event.Data["value"])
+	assert.Empty(t, sub.C)
+}
+
+func TestEngine_Check(t *testing.T) {
+	engine := NewEngine(testRules(t), time.Minute)
+	main, annex := newFakeSource(), newFakeSource()
+	engine.Monitor("main", main)
+	engine.Monitor("annex", annex)
+	require.Len(t, engine.Alerts(), 4)
+
+	ctx := context.Background()
+	assert.Equal(t, health.StatusHealthy, engine.Check(ctx).Status)
+
+	annex.set(config.SLIRejectedRequests, 0.2)
+	engine.evaluate(time.Now())
+	result := engine.Check(ctx)
+	assert.Equal(t, health.StatusDegraded, result.Status)
+	assert.Contains(t, result.Message, "rejections@annex")
+
+	main.set(config.SLIWaitTime, 60)
+	engine.evaluate(time.Now())
+	result = engine.Check(ctx)
+	assert.Equal(t, health.StatusUnhealthy, result.Status)
+	assert.Contains(t, result.Message, "answered-30s@main")
+
+	// A degraded dependency degrades readiness, a failing one fails it
+	readiness := health.NewReadinessChecker()
+	readiness.AddDependency(engine)
+	assert.Equal(t, health.StatusUnhealthy, readiness.Check(ctx).Status)
+
+	main.set(config.SLIWaitTime, 0)
+	engine.evaluate(time.Now())
+	assert.Equal(t, health.StatusDegraded, readiness.Check(ctx).Status)
+}
+
+func TestEngine_StartStop(t *testing.T) {
+	engine := NewEngine(testRules(t), 5*time.Millisecond)
+	source := newFakeSource()
+	engine.Monitor("main", source)
+	source.set(config.SLIRejectedRequests, 1)
+
+	engine.Start()
+	require.Eventually(t, func() bool {
+		return engine.Alerts()[1].State == StateFiring
+	}, time.Second, 5*time.Millisecond)
+	engine.Stop()
+	engine.Stop()
+
+	// An engine that never started stops at once
+	NewEngine(nil, time.Second).Stop()
+}
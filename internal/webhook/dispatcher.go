@@ -0,0 +1,590 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/slavakukuyev/elevator-go/internal/constants"
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+	"github.com/slavakukuyev/elevator-go/internal/events"
+	"github.com/slavakukuyev/elevator-go/internal/infra/config"
+	"github.com/slavakukuyev/elevator-go/metrics"
+)
+
+const (
+	// maxSubscriptions bounds the subscriptions a dispatcher holds
+	maxSubscriptions = 100
+	// workers is the number of deliveries attempted at the same time
+	workers = 4
+	// queueSize bounds the attempts waiting for a worker; a delivery that
+	// finds the queue full is dead-lettered
+	queueSize = 1024
+	// eventBuffer bounds the events queued for the dispatcher on each bus
+	eventBuffer = 256
+	// maxResponseBody is how much of a response is read before the
+	// connection is reused
+	maxResponseBody = 64 << 10
+)
+
+// Options tune the delivery of webhooks
+type Options struct {
+	MaxAttempts     int           // Attempts before a delivery is dead-lettered
+	RetryBackoff    time.Duration // Wait before the first retry, doubled for every further one
+	MaxRetryBackoff time.Duration // Longest wait between retries
+	Timeout         time.Duration // Longest wait for a receiver to answer
+	DeliveryLogSize int           // Deliveries kept per subscription
+
+	// AllowedNetworks may receive deliveries even though they are loopback,
+	// link-local or private addresses
+	AllowedNetworks []netip.Prefix
+}
+
+// OptionsFromConfig returns the delivery options a configuration describes
+func OptionsFromConfig(cfg *config.Config) Options {
+	opts := Options{
+		MaxAttempts:     cfg.WebhookMaxAttempts,
+		RetryBackoff:    cfg.WebhookRetryBackoff,
+		MaxRetryBackoff: cfg.WebhookMaxRetryBackoff,
+		Timeout:         cfg.WebhookTimeout,
+		DeliveryLogSize: cfg.WebhookDeliveryLogSize,
+	}
+	// The networks were validated with the rest of the configuration
+	opts.AllowedNetworks, _ = config.ParseWebhookNetworks(cfg.WebhookAllowedNetworks)
+	return opts
+}
+
+// subscription is a registered webhook with its secret and delivery log
+type subscription struct {
+	Subscription
+	secret string
+
+	// mu guards the deliveries, including the fields of every delivery
+	mu         sync.Mutex
+	deliveries []*Delivery // oldest first
+	removed    bool
+}
+
+// job is a delivery waiting for its next attempt
+type job struct {
+	sub      *subscription
+	delivery *Delivery
+}
+
+// Dispatcher delivers the events of watched buses to the subscriptions they
+// match
+type Dispatcher struct {
+	opts   Options
+	client *http.Client
+	logger *slog.Logger
+	queue  chan job
+
+	mu            sync.RWMutex
+	subscriptions map[string]*subscription
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewDispatcher creates a dispatcher and starts its delivery workers
+func NewDispatcher(opts Options) *Dispatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	d := &Dispatcher{
+		opts:          opts,
+		logger:        slog.With(slog.String("component", constants.ComponentWebhook)),
+		queue:         make(chan job, queueSize),
+		subscriptions: make(map[string]*subscription),
+		ctx:           ctx,
+		cancel:        cancel,
+	}
+	d.client = d.newClient()
+
+	for range workers {
+		d.wg.Add(1)
+		go d.work()
+	}
+	return d
+}
+
+// Watch delivers the events published on a building's bus from now on
+func (d *Dispatcher) Watch(building string, bus *events.Bus) {
+	d.wg.Add(1)
+	go d.watch(building, bus, bus.LastID())
+}
+
+// Stop stops watching buses and abandons the deliveries not yet attempted
+func (d *Dispatcher) Stop() {
+	d.cancel()
+	d.wg.Wait()
+	d.logger.Info("webhook dispatcher stopped")
+}
+
+// Subscribe registers a webhook for the given event types, every type when
+// none or "*" is given
+func (d *Dispatcher) Subscribe(rawURL string, eventTypes []string, secret string) (Subscription, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return Subscription{}, domain.NewValidationError("webhook url must be an absolute http or https url", err).
+			WithContext("url", rawURL)
+	}
+	if !d.hostAllowed(parsed.Hostname()) {
+		return Subscription{}, domain.NewValidationError(
+			"webhook url must not point to a loopback, link-local or private address", nil).
+			WithContext("url", rawURL)
+	}
+	if len(secret) < MinSecretLength {
+		return Subscription{}, domain.NewValidationError(
+			fmt.Sprintf("webhook secret must be at least %d characters", MinSecretLength), nil)
+	}
+
+	filter := make([]events.Type, 0, len(eventTypes))
+	for _, name := range eventTypes {
+		eventType := events.Type(name)
+		if eventType != AllEvents && !events.Known(eventType) {
+			return Subscription{}, domain.NewValidationError("unknown event type", nil).
+				WithContext("event", name)
+		}
+		if !slices.Contains(filter, eventType) {
+			filter = append(filter, eventType)
+		}
+	}
+	if len(filter) == 0 || slices.Contains(filter, AllEvents) {
+		filter = []events.Type{AllEvents}
+	}
+
+	sub := &subscription{
+		Subscription: Subscription{
+			ID:        newID("wh"),
+			URL:       parsed.String(),
+			Events:    filter,
+			CreatedAt: time.Now(),
+		},
+		secret: secret,
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.subscriptions) >= maxSubscriptions {
+		return Subscription{}, domain.NewConflictError("too many webhook subscriptions", nil).
+			WithContext("max_subscriptions", maxSubscriptions)
+	}
+	d.subscriptions[sub.ID] = sub
+
+	d.logger.Info("webhook subscribed",
+		slog.String("subscription", sub.ID),
+		slog.String("url", sub.URL),
+		slog.Any("events", sub.Events))
+	return sub.view(), nil
+}
+
+// Unsubscribe removes a webhook; deliveries still pending are dropped
+func (d *Dispatcher) Unsubscribe(id string) error {
+	d.mu.Lock()
+	sub, exists := d.subscriptions[id]
+	delete(d.subscriptions, id)
+	d.mu.Unlock()
+
+	if !exists {
+		return notFound(id)
+	}
+	sub.mu.Lock()
+	sub.removed = true
+	sub.mu.Unlock()
+
+	d.logger.Info("webhook unsubscribed", slog.String("subscription", id))
+	return nil
+}
+
+// Subscriptions lists the webhooks in the order they were registered
+func (d *Dispatcher) Subscriptions() []Subscription {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	subscriptions := make([]Subscription, 0, len(d.subscriptions))
+	for _, sub := range d.subscriptions {
+		subscriptions = append(subscriptions, sub.view())
+	}
+	slices.SortFunc(subscriptions, func(a, b Subscription) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return subscriptions
+}
+
+// Subscription returns a webhook
+func (d *Dispatcher) Subscription(id string) (Subscription, error) {
+	sub, err := d.lookup(id)
+	if err != nil {
+		return Subscription{}, err
+	}
+	return sub.view(), nil
+}
+
+// Deliveries returns the delivery log of a webhook, newest first, keeping
+// only deliveries with the given status unless it is empty
+func (d *Dispatcher) Deliveries(id string, status DeliveryStatus) ([]Delivery, error) {
+	sub, err := d.lookup(id)
+	if err != nil {
+		return nil, err
+	}
+
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	deliveries := make([]Delivery, 0, len(sub.deliveries))
+	for i := len(sub.deliveries) - 1; i >= 0; i-- {
+		if status == "" || sub.deliveries[i].Status == status {
+			deliveries = append(deliveries, *sub.deliveries[i])
+		}
+	}
+	return deliveries, nil
+}
+
+// Redeliver sends a dead-lettered delivery again, with a fresh set of
+// attempts
+func (d *Dispatcher) Redeliver(id, deliveryID string) (Delivery, error) {
+	sub, err := d.lookup(id)
+	if err != nil {
+		return Delivery{}, err
+	}
+
+	sub.mu.Lock()
+	index := slices.IndexFunc(sub.deliveries, func(delivery *Delivery) bool { return delivery.ID == deliveryID })
+	if index < 0 {
+		sub.mu.Unlock()
+		return Delivery{}, domain.NewNotFoundError("webhook delivery not found", nil).
+			WithContext("subscription", id).
+			WithContext("delivery", deliveryID)
+	}
+	delivery := sub.deliveries[index]
+	if delivery.Status != StatusDeadLetter {
+		sub.mu.Unlock()
+		return Delivery{}, domain.NewConflictError("only dead-lettered deliveries can be redelivered", nil).
+			WithContext("delivery", deliveryID).
+			WithContext("status", string(delivery.Status))
+	}
+	delivery.Status = StatusPending
+	delivery.Attempts = 0
+	delivery.Error = ""
+	delivery.NextAttemptAt = nil
+	redelivered := *delivery
+	sub.mu.Unlock()
+
+	d.enqueue(job{sub: sub, delivery: delivery})
+	return redelivered, nil
+}
+
+func (d *Dispatcher) lookup(id string) (*subscription, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	sub, exists := d.subscriptions[id]
+	if !exists {
+		return nil, notFound(id)
+	}
+	return sub, nil
+}
+
+func notFound(id string) error {
+	return domain.NewNotFoundError("webhook not found", nil).
+		WithContext("subscription", id)
+}
+
+// view returns a copy of the subscription safe to hand out
+func (s *subscription) view() Subscription {
+	view := s.Subscription
+	view.Events = slices.Clone(s.Events)
+	return view
+}
+
+// watch dispatches the events of a bus published after lastID until the
+// dispatcher stops. A dispatcher that falls behind is dropped by the bus and
+// resumes from the bus history.
+func (d *Dispatcher) watch(building string, bus *events.Bus, lastID uint64) {
+	defer d.wg.Done()
+
+	for {
+		sub, backlog, complete := bus.SubscribeSince(lastID, eventBuffer)
+		if !complete {
+			d.logger.Warn("webhook dispatcher fell behind, events were not delivered",
+				slog.String("building", building),
+				slog.Uint64("last_event_id", lastID))
+			metrics.IncError("events_missed", constants.ComponentWebhook)
+		}
+		for _, event := range backlog {
+			d.dispatch(building, event)
+			lastID = event.ID
+		}
+		if !d.follow(building, sub, &lastID) {
+			return
+		}
+	}
+}
+
+// follow dispatches events from a subscription until it is closed. It
+// returns false once the dispatcher stops.
+func (d *Dispatcher) follow(building string, sub *events.Subscription, lastID *uint64) bool {
+	for {
+		select {
+		case <-d.ctx.Done():
+			sub.Close()
+			return false
+		case event, ok := <-sub.C:
+			if !ok {
+				return true
+			}
+			d.dispatch(building, event)
+			*lastID = event.ID
+		}
+	}
+}
+
+// dispatch records a delivery of an event for every subscription it matches
+// and queues its first attempt
+func (d *Dispatcher) dispatch(building string, event events.Event) {
+	d.mu.RLock()
+	var matching []*subscription
+	for _, sub := range d.subscriptions {
+		if sub.Matches(event.Type) {
+			matching = append(matching, sub)
+		}
+	}
+	d.mu.RUnlock()
+
+	for _, sub := range matching {
+		delivery := &Delivery{
+			ID:             newID("dlv"),
+			SubscriptionID: sub.ID,
+			Building:       building,
+			EventID:        event.ID,
+			EventType:      event.Type,
+			Status:         StatusPending,
+			CreatedAt:      time.Now(),
+		}
+		body, err := json.Marshal(Payload{
+			DeliveryID:     delivery.ID,
+			SubscriptionID: sub.ID,
+			Building:       building,
+			Event:          event,
+		})
+		if err != nil {
+			d.logger.Error("failed to encode webhook payload",
+				slog.String("subscription", sub.ID),
+				slog.Uint64("event_id", event.ID),
+				slog.String("error", err.Error()))
+			metrics.IncError("encode_error", constants.ComponentWebhook)
+			continue
+		}
+		delivery.body = body
+
+		sub.mu.Lock()
+		sub.deliveries = append(sub.deliveries, delivery)
+		if excess := len(sub.deliveries) - d.opts.DeliveryLogSize; excess > 0 {
+			sub.deliveries = slices.Delete(sub.deliveries, 0, excess)
+		}
+		sub.mu.Unlock()
+
+		d.enqueue(job{sub: sub, delivery: delivery})
+	}
+}
+
+// enqueue queues an attempt, dead-lettering the delivery when the queue is
+// full so that it can still be redelivered
+func (d *Dispatcher) enqueue(j job) {
+	select {
+	case d.queue <- j:
+	default:
+		j.sub.mu.Lock()
+		j.delivery.Status = StatusDeadLetter
+		j.delivery.Error = "delivery queue is full"
+		j.delivery.NextAttemptAt = nil
+		j.sub.mu.Unlock()
+
+		d.logger.Warn("webhook delivery queue is full, delivery dead-lettered",
+			slog.String("subscription", j.sub.ID),
+			slog.String("delivery", j.delivery.ID))
+		metrics.IncWebhookDeliveries(string(j.delivery.EventType), string(StatusDeadLetter))
+	}
+}
+
+// work attempts queued deliveries until the dispatcher stops
+func (d *Dispatcher) work() {
+	defer d.wg.Done()
+	for {
+		select {
+		case <-d.ctx.Done():
+			return
+		case j := <-d.queue:
+			d.attempt(j)
+		}
+	}
+}
+
+// attempt sends a delivery once and schedules its retry if it fails
+func (d *Dispatcher) attempt(j job) {
+	sub, delivery := j.sub, j.delivery
+
+	sub.mu.Lock()
+	if sub.removed {
+		sub.mu.Unlock()
+		return
+	}
+	now := time.Now()
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.NextAttemptAt = nil
+	attempts := delivery.Attempts
+	sub.mu.Unlock()
+
+	statusCode, err := d.post(sub, delivery, now)
+
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	delivery.ResponseStatus = statusCode
+	if err == nil {
+		delivery.Status = StatusDelivered
+		delivery.Error = ""
+		metrics.IncWebhookDeliveries(string(delivery.EventType), string(StatusDelivered))
+		return
+	}
+
+	delivery.Error = err.Error()
+	if attempts >= d.opts.MaxAttempts {
+		delivery.Status = StatusDeadLetter
+		d.logger.Warn("webhook delivery dead-lettered",
+			slog.String("subscription", sub.ID),
+			slog.String("delivery", delivery.ID),
+			slog.Int("attempts", attempts),
+			slog.String("error", err.Error()))
+		metrics.IncWebhookDeliveries(string(delivery.EventType), string(StatusDeadLetter))
+		return
+	}
+
+	backoff := d.backoff(attempts)
+	next := time.Now().Add(backoff)
+	delivery.Status = StatusRetrying
+	delivery.NextAttemptAt = &next
+	d.logger.Debug("webhook delivery failed, retrying",
+		slog.String("subscription", sub.ID),
+		slog.String("delivery", delivery.ID),
+		slog.Int("attempts", attempts),
+		slog.Duration("backoff", backoff),
+		slog.String("error", err.Error()))
+	metrics.IncWebhookDeliveries(string(delivery.EventType), "failed")
+
+	time.AfterFunc(backoff, func() {
+		if d.ctx.Err() == nil {
+			d.enqueue(j)
+		}
+	})
+}
+
+// post sends the delivery's body signed for the time of the attempt and
+// returns the status code of the response
+func (d *Dispatcher) post(sub *subscription, delivery *Delivery, at time.Time) (int, error) {
+	ctx, cancel := context.WithTimeout(d.ctx, d.opts.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(delivery.body))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := at.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderDelivery, delivery.ID)
+	req.Header.Set(HeaderEvent, string(delivery.EventType))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(sub.secret, timestamp, delivery.body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBody))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("receiver responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff returns the wait before the retry that follows an attempt
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	backoff := d.opts.RetryBackoff
+	for i := 1; i < attempts && backoff < d.opts.MaxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, d.opts.MaxRetryBackoff)
+}
+
+// newClient returns the client deliveries are sent with. It connects only
+// to allowed addresses, whatever a receiver's name resolves to at the time,
+// and does not follow redirects: a redirect response fails the attempt.
+// Proxies are not used, since they would connect on the client's behalf.
+func (d *Dispatcher) newClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: d.opts.Timeout,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !d.addrAllowed(addrPort.Addr()) {
+				return fmt.Errorf("webhook destination %s is not allowed", addrPort.Addr())
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   d.opts.Timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// hostAllowed reports whether a subscription may name a host. Names other
+// than localhost are checked once they resolve, when a delivery connects.
+func (d *Dispatcher) hostAllowed(host string) bool {
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return d.addrAllowed(addr)
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return d.addrAllowed(netip.AddrFrom4([4]byte{127, 0, 0, 1})) || d.addrAllowed(netip.IPv6Loopback())
+	}
+	return true
+}
+
+// addrAllowed reports whether deliveries may be sent to an address: public
+// addresses always, loopback, link-local, private and unspecified ones only
+// within the allowed networks
+func (d *Dispatcher) addrAllowed(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsLoopback() && !addr.IsLinkLocalUnicast() && !addr.IsLinkLocalMulticast() &&
+		!addr.IsPrivate() && !addr.IsUnspecified() {
+		return true
+	}
+	for _, network := range d.opts.AllowedNetworks {
+		if network.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,343 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slavakukuyev/elevator-go/internal/domain"
+	"github.com/slavakukuyev/elevator-go/internal/events"
+)
+
+const testSecret = "0123456789abcdef"
+
+var testOptions = Options{
+	MaxAttempts:     3,
+	RetryBackoff:    10 * time.Millisecond,
+	MaxRetryBackoff: 40 * time.Millisecond,
+	Timeout:         time.Second,
+	DeliveryLogSize: 10,
+	// The test receivers listen on loopback
+	AllowedNetworks: []netip.Prefix{netip.MustParsePrefix("127.0.0.0/8")},
+}
+
+// receivedRequest is a delivery as the receiver saw it
+type receivedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// receiver is an httptest server answering deliveries with the status codes
+// queued in replies, then with status
+type receiver struct {
+	server *httptest.Server
+
+	mu       sync.Mutex
+	replies  []int
+	status   int
+	requests []receivedRequest
+}
+
+func newReceiver(t *testing.T, replies ...int) *receiver {
+	t.Helper()
+	r := &receiver{replies: replies, status: http.StatusOK}
+	r.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		r.mu.Lock()
+		r.requests = append(r.requests, receivedRequest{header: req.Header.Clone(), body: body})
+		status := r.status
+		if len(r.replies) > 0 {
+			status, r.replies = r.replies[0], r.replies[1:]
+		}
+		r.mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(r.server.Close)
+	return r
+}
+
+func (r *receiver) received() []receivedRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]receivedRequest(nil), r.requests...)
+}
+
+func (r *receiver) setStatus(status int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.status = status
+}
+
+func newTestDispatcher(t *testing.T, opts Options) (*Dispatcher, *events.Bus) {
+	t.Helper()
+	d := NewDispatcher(opts)
+	t.Cleanup(d.Stop)
+	bus := events.NewBus(100)
+	d.Watch("main", bus)
+	return d, bus
+}
+
+// waitForStatus waits until the only delivery of a subscription has a status
+func waitForStatus(t *testing.T, d *Dispatcher, id string, status DeliveryStatus) Delivery {
+	t.Helper()
+	var deliveries []Delivery
+	require.Eventually(t, func() bool {
+		var err error
+		deliveries, err = d.Deliveries(id, "")
+		require.NoError(t, err)
+		return len(deliveries) == 1 && deliveries[0].Status == status
+	}, 2*time.Second, 5*time.Millisecond)
+	return deliveries[0]
+}
+
+func TestDispatcher_DeliversSignedEvents(t *testing.T) {
+	d, bus := newTestDispatcher(t, testOptions)
+	recv := newReceiver(t)
+
+	sub, err := d.Subscribe(recv.server.URL, []string{string(events.TypeRequestCompleted)}, testSecret)
+	require.NoError(t, err)
+
+	// Only the subscribed event type is delivered
+	bus.Publish(events.TypeElevatorAdded, "A", nil)
+	published := bus.Publish(events.TypeRequestCompleted, "A", map[string]any{"trip_id": "trip-1"})
+
+	delivery := waitForStatus(t, d, sub.ID, StatusDelivered)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, http.StatusOK, delivery.ResponseStatus)
+	assert.Equal(t, published.ID, delivery.EventID)
+	assert.Equal(t, "main", delivery.Building)
+
+	requests := recv.received()
+	require.Len(t, requests, 1)
+	request := requests[0]
+	assert.Equal(t, delivery.ID, request.header.Get(HeaderDelivery))
+	assert.Equal(t, string(events.TypeRequestCompleted), request.header.Get(HeaderEvent))
+	assert.Equal(t, "application/json", request.header.Get("Content-Type"))
+
+	timestamp, err := strconv.ParseInt(request.header.Get(HeaderTimestamp), 10, 64)
+	require.NoError(t, err)
+	assert.True(t, Verify(testSecret, timestamp, request.body, request.header.Get(HeaderSignature)))
+	assert.False(t, Verify("another-secret-value", timestamp, request.body, request.header.Get(HeaderSignature)))
+	assert.False(t, Verify(testSecret, timestamp+1, request.body, request.header.Get(HeaderSignature)))
+
+	var payload Payload
+	require.NoError(t, json.Unmarshal(request.body, &payload))
+	assert.Equal(t, delivery.ID, payload.DeliveryID)
+	assert.Equal(t, sub.ID, payload.SubscriptionID)
+	assert.Equal(t, "main", payload.Building)
+	assert.Equal(t, published.ID, payload.Event.ID)
+	assert.Equal(t, "A", payload.Event.Elevator)
+	assert.Equal(t, "trip-1", payload.Event.Data["trip_id"])
+}
+
+func TestDispatcher_RetriesWithBackoff(t *testing.T) {
+	d, bus := newTestDispatcher(t, testOptions)
+	recv := newReceiver(t, http.StatusInternalServerError, http.StatusBadGateway)
+
+	sub, err := d.Subscribe(recv.server.URL, nil, testSecret)
+	require.NoError(t, err)
+	bus.Publish(events.TypeCircuitBreakerOpened, "A", nil)
+
+	delivery := waitForStatus(t, d, sub.ID, StatusDelivered)
+	assert.Equal(t, 3, delivery.Attempts)
+	assert.Empty(t, delivery.Error)
+
+	// Every attempt carries the same delivery and body
+	requests := recv.received()
+	require.Len(t, requests, 3)
+	for _, request := range requests[1:] {
+		assert.Equal(t, requests[0].header.Get(HeaderDelivery), request.header.Get(HeaderDelivery))
+		assert.Equal(t, requests[0].body, request.body)
+	}
+}
+
+func TestDispatcher_DeadLettersAndRedelivers(t *testing.T) {
+	d, bus := newTestDispatcher(t, testOptions)
+	recv := newReceiver(t)
+	recv.setStatus(http.StatusServiceUnavailable)
+
+	sub, err := d.Subscribe(recv.server.URL, []string{"*"}, testSecret)
+	require.NoError(t, err)
+	bus.Publish(events.TypeElevatorRemoved, "A", nil)
+
+	delivery := waitForStatus(t, d, sub.ID, StatusDeadLetter)
+	assert.Equal(t, testOptions.MaxAttempts, delivery.Attempts)
+	assert.Equal(t, http.StatusServiceUnavailable, delivery.ResponseStatus)
+	assert.Contains(t, delivery.Error, "503")
+	assert.Len(t, recv.received(), testOptions.MaxAttempts)
+
+	deadLetters, err := d.Deliveries(sub.ID, StatusDeadLetter)
+	require.NoError(t, err)
+	assert.Len(t, deadLetters, 1)
+	delivered, err := d.Deliveries(sub.ID, StatusDelivered)
+	require.NoError(t, err)
+	assert.Empty(t, delivered)
+
+	// A dead letter can be sent again once the receiver is back
+	recv.setStatus(http.StatusNoContent)
+	redelivered, err := d.Redeliver(sub.ID, delivery.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, redelivered.Status)
+
+	delivery = waitForStatus(t, d, sub.ID, StatusDelivered)
+	assert.Equal(t, 1, delivery.Attempts)
+
+	_, err = d.Redeliver(sub.ID, delivery.ID)
+	require.Error(t, err)
+	assert.Equal(t, domain.ErrTypeConflict, err.(*domain.DomainError).Type)
+
+	_, err = d.Redeliver(sub.ID, "dlv_missing")
+	require.Error(t, err)
+	assert.Equal(t, domain.ErrTypeNotFound, err.(*domain.DomainError).Type)
+}
+
+func TestDispatcher_DeliveryLogIsBounded(t *testing.T) {
+	opts := testOptions
+	opts.DeliveryLogSize = 3
+	d, bus := newTestDispatcher(t, opts)
+	recv := newReceiver(t)
+
+	sub, err := d.Subscribe(recv.server.URL, nil, testSecret)
+	require.NoError(t, err)
+	for floor := range 5 {
+		bus.Publish(events.TypeFloorArrived, "A", map[string]any{"floor": floor})
+	}
+
+	require.Eventually(t, func() bool { return len(recv.received()) == 5 }, 2*time.Second, 5*time.Millisecond)
+	deliveries, err := d.Deliveries(sub.ID, "")
+	require.NoError(t, err)
+	require.Len(t, deliveries, 3)
+	assert.Equal(t, uint64(5), deliveries[0].EventID, "newest first")
+	assert.Equal(t, uint64(3), deliveries[2].EventID)
+}
+
+func TestDispatcher_Unsubscribe(t *testing.T) {
+	d, bus := newTestDispatcher(t, testOptions)
+	recv := newReceiver(t)
+
+	sub, err := d.Subscribe(recv.server.URL, nil, testSecret)
+	require.NoError(t, err)
+	assert.Len(t, d.Subscriptions(), 1)
+
+	require.NoError(t, d.Unsubscribe(sub.ID))
+	assert.Empty(t, d.Subscriptions())
+	bus.Publish(events.TypeElevatorAdded, "A", nil)
+
+	_, err = d.Subscription(sub.ID)
+	require.Error(t, err)
+	assert.Equal(t, domain.ErrTypeNotFound, err.(*domain.DomainError).Type)
+	assert.Error(t, d.Unsubscribe(sub.ID))
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, recv.received())
+}
+
+func TestDispatcher_SubscribeValidation(t *testing.T) {
+	d := NewDispatcher(testOptions)
+	defer d.Stop()
+
+	tests := []struct {
+		name   string
+		url    string
+		events []string
+		secret string
+	}{
+		{"relative url", "/hooks", nil, testSecret},
+		{"unsupported scheme", "ftp://ops.example.com/hooks", nil, testSecret},
+		{"missing host", "https:///hooks", nil, testSecret},
+		{"short secret", "https://ops.example.com/hooks", nil, "secret"},
+		{"unknown event", "https://ops.example.com/hooks", []string{"request_delivered"}, testSecret},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := d.Subscribe(tt.url, tt.events, tt.secret)
+			require.Error(t, err)
+			assert.Equal(t, domain.ErrTypeValidation, err.(*domain.DomainError).Type)
+		})
+	}
+
+	sub, err := d.Subscribe("https://ops.example.com/hooks", []string{"elevator_added", "elevator_added"}, testSecret)
+	require.NoError(t, err)
+	assert.Equal(t, []events.Type{events.TypeElevatorAdded}, sub.Events)
+	assert.True(t, sub.Matches(events.TypeElevatorAdded))
+	assert.False(t, sub.Matches(events.TypeElevatorRemoved))
+
+	sub, err = d.Subscribe("https://ops.example.com/hooks", []string{"elevator_added", "*"}, testSecret)
+	require.NoError(t, err)
+	assert.Equal(t, []events.Type{AllEvents}, sub.Events)
+	assert.True(t, sub.Matches(events.TypeElevatorRemoved))
+}
+
+func TestDispatcher_RefusesInternalReceivers(t *testing.T) {
+	opts := testOptions
+	opts.AllowedNetworks = nil
+	d := NewDispatcher(opts)
+	defer d.Stop()
+
+	for _, url := range []string{
+		"http://127.0.0.1:8080/hooks",
+		"http://localhost/hooks",
+		"http://[::1]/hooks",
+		"http://169.254.169.254/latest/meta-data",
+		"http://10.1.2.3/hooks",
+		"http://192.168.0.10/hooks",
+		"http://[fd00::1]/hooks",
+		"http://0.0.0.0/hooks",
+	} {
+		t.Run(url, func(t *testing.T) {
+			_, err := d.Subscribe(url, nil, testSecret)
+			require.Error(t, err)
+			assert.Equal(t, domain.ErrTypeValidation, err.(*domain.DomainError).Type)
+		})
+	}
+
+	// Names are checked when a delivery connects, whatever they resolve to
+	receiver := newReceiver(t)
+	_, err := d.client.Post(receiver.server.URL, "application/json", nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not allowed")
+	assert.Empty(t, receiver.received())
+
+	// Allowed networks may receive deliveries
+	opts.AllowedNetworks = []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+	allowed := NewDispatcher(opts)
+	defer allowed.Stop()
+	_, err = allowed.Subscribe("http://10.1.2.3/hooks", nil, testSecret)
+	require.NoError(t, err)
+	_, err = allowed.Subscribe("http://192.168.0.10/hooks", nil, testSecret)
+	require.Error(t, err)
+}
+
+func TestDispatcher_DoesNotFollowRedirects(t *testing.T) {
+	target := newReceiver(t)
+	redirect := httptest.NewServer(http.RedirectHandler(target.server.URL, http.StatusFound))
+	defer redirect.Close()
+
+	d, bus := newTestDispatcher(t, testOptions)
+	sub, err := d.Subscribe(redirect.URL, nil, testSecret)
+	require.NoError(t, err)
+	bus.Publish(events.TypeElevatorAdded, "A", nil)
+
+	delivery := waitForStatus(t, d, sub.ID, StatusDeadLetter)
+	assert.Equal(t, http.StatusFound, delivery.ResponseStatus)
+	assert.Empty(t, target.received())
+}
+
+func TestDispatcher_Backoff(t *testing.T) {
+	d := NewDispatcher(testOptions)
+	defer d.Stop()
+
+	assert.Equal(t, 10*time.Millisecond, d.backoff(1))
+	assert.Equal(t, 20*time.Millisecond, d.backoff(2))
+	assert.Equal(t, 40*time.Millisecond, d.backoff(3))
+	assert.Equal(t, 40*time.Millisecond, d.backoff(10))
+}
@@ -0,0 +1,135 @@
+// Package webhook notifies external systems of elevator and request events.
+//
+// A subscription names a URL, the event types it wants and a shared secret.
+// Every matching event published on a watched building's bus is POSTed to
+// the URL as JSON:
+//
+//	{"delivery_id": "...", "subscription_id": "...", "building": "main", "event": {...}}
+//
+// with the headers
+//
+//	X-Webhook-Delivery   delivery ID, the same for every attempt
+//	X-Webhook-Event      event type
+//	X-Webhook-Timestamp  Unix time of the attempt
+//	X-Webhook-Signature  sha256=<hex HMAC-SHA256 of "<timestamp>.<body>" keyed with the secret>
+//
+// A 2xx response acknowledges the delivery. Other responses and network
+// errors are retried with exponential backoff; a delivery that fails every
+// attempt is dead-lettered and kept in the subscription's delivery log, from
+// where it can be redelivered. Subscriptions and delivery logs are held in
+// memory and do not survive a restart.
+//
+// Receivers on loopback, link-local and private addresses are refused unless
+// Options.AllowedNetworks contains them, both when a subscription names an
+// address and when a delivery connects, and redirects are not followed, so
+// subscriptions cannot reach the internal services of the host.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/slavakukuyev/elevator-go/internal/events"
+)
+
+// Headers sent with every delivery
+const (
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// AllEvents subscribes to every event type
+const AllEvents events.Type = "*"
+
+// MinSecretLength is the shortest secret a subscription accepts
+const MinSecretLength = 16
+
+// DeliveryStatus is where a delivery stands
+type DeliveryStatus string
+
+const (
+	// StatusPending deliveries wait for their first attempt
+	StatusPending DeliveryStatus = "pending"
+	// StatusRetrying deliveries failed and wait for their next attempt
+	StatusRetrying DeliveryStatus = "retrying"
+	// StatusDelivered deliveries were acknowledged by the receiver
+	StatusDelivered DeliveryStatus = "delivered"
+	// StatusDeadLetter deliveries failed every attempt
+	StatusDeadLetter DeliveryStatus = "dead_letter"
+)
+
+// IsValid reports whether s is a known delivery status
+func (s DeliveryStatus) IsValid() bool {
+	switch s {
+	case StatusPending, StatusRetrying, StatusDelivered, StatusDeadLetter:
+		return true
+	}
+	return false
+}
+
+// Subscription is a registered webhook. Its secret is never returned.
+type Subscription struct {
+	ID        string        `json:"id"`
+	URL       string        `json:"url"`
+	Events    []events.Type `json:"events"` // ["*"] for every event type
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+// Matches reports whether the subscription receives events of a type
+func (s Subscription) Matches(eventType events.Type) bool {
+	return slices.Contains(s.Events, AllEvents) || slices.Contains(s.Events, eventType)
+}
+
+// Delivery is one event sent, or to be sent, to a subscription
+type Delivery struct {
+	ID             string         `json:"id"`
+	SubscriptionID string         `json:"subscription_id"`
+	Building       string         `json:"building,omitempty"`
+	EventID        uint64         `json:"event_id"`
+	EventType      events.Type    `json:"event_type"`
+	Status         DeliveryStatus `json:"status"`
+	Attempts       int            `json:"attempts"`
+	ResponseStatus int            `json:"response_status,omitempty"` // Status code of the last response
+	Error          string         `json:"error,omitempty"`           // Why the last attempt failed
+	CreatedAt      time.Time      `json:"created_at"`
+	LastAttemptAt  *time.Time     `json:"last_attempt_at,omitempty"`
+	NextAttemptAt  *time.Time     `json:"next_attempt_at,omitempty"`
+
+	body []byte
+}
+
+// Payload is the body of a delivery
+type Payload struct {
+	DeliveryID     string       `json:"delivery_id"`
+	SubscriptionID string       `json:"subscription_id"`
+	Building       string       `json:"building,omitempty"`
+	Event          events.Event `json:"event"`
+}
+
+// Sign returns the signature header of a body sent at a Unix timestamp
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = fmt.Fprintf(mac, "%d.", timestamp)
+	_, _ = mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is the signature of a body sent at a
+// Unix timestamp. Receivers should also reject stale timestamps.
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+// newID returns a random identifier with a prefix
+func newID(prefix string) string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return prefix + "_" + hex.EncodeToString(b)
+}
@@ -1,6 +1,8 @@
 package metrics
 
 import (
+	"strconv"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/slavakukuyev/elevator-go/internal/constants"
 )
@@ -13,7 +15,7 @@ var (
 			Help:    "Duration of elevator request processing",
 			Buckets: []float64{0.1, 0.5, 1, 2, 5, 10, 30},
 		},
-		[]string{constants.ElevatorNameLabel, "status"},
+		[]string{constants.BuildingLabel, constants.ElevatorNameLabel, "status"},
 	)
 
 	// Request counters
@@ -22,36 +24,178 @@ var (
 			Name: constants.MetricsNamespace + "_requests_total",
 			Help: "Total number of elevator requests",
 		},
-		[]string{constants.ElevatorNameLabel, "direction", "status"},
+		[]string{constants.BuildingLabel, constants.ElevatorNameLabel, "direction", "status"},
 	)
 
 	// Elevator efficiency metrics
 	elevatorEfficiency = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Name: constants.MetricsNamespace + "_efficiency_ratio",
-			Help: "Elevator efficiency ratio (successful requests / total requests)",
+			Help: "Share of an elevator's finished requests that reached their destination",
 		},
-		[]string{constants.ElevatorNameLabel},
+		[]string{constants.BuildingLabel, constants.ElevatorNameLabel},
 	)
 
 	// Wait time tracking
 	waitTime = prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
 			Name:    constants.MetricsNamespace + "_wait_time_seconds",
-			Help:    "Time passengers wait for elevator arrival",
+			Help:    "Time passengers wait from request to pickup",
 			Buckets: []float64{1, 5, 10, 30, 60, 120, 300},
 		},
-		[]string{constants.ElevatorNameLabel},
+		[]string{constants.BuildingLabel, constants.ElevatorNameLabel},
 	)
 
 	// Elevator travel metrics
 	travelTime = prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
 			Name:    constants.MetricsNamespace + "_travel_time_seconds",
-			Help:    "Time for elevator to complete a journey",
+			Help:    "Time passengers ride from pickup to drop-off",
+			Buckets: []float64{1, 5, 10, 30, 60, 120, 300},
+		},
+		[]string{constants.BuildingLabel, constants.ElevatorNameLabel, "floors_traveled"},
+	)
+
+	etaError = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    constants.MetricsNamespace + "_eta_error_seconds",
+			Help:    "Measured minus estimated wait or ride time; positive when slower than estimated",
+			Buckets: []float64{-120, -60, -30, -10, -5, -1, 0, 1, 5, 10, 30, 60, 120},
+		},
+		[]string{constants.BuildingLabel, constants.ElevatorNameLabel, "estimate"},
+	)
+
+	// Priority call metrics
+	priorityRequestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: constants.MetricsNamespace + "_priority_requests_total",
+			Help: "Total number of priority floor requests assigned to an elevator",
+		},
+		[]string{constants.BuildingLabel, constants.ElevatorNameLabel, "priority"},
+	)
+
+	priorityWaitTime = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    constants.MetricsNamespace + "_priority_wait_time_seconds",
+			Help:    "Time priority callers wait from request to pickup",
+			Buckets: []float64{1, 5, 10, 30, 60, 120, 300},
+		},
+		[]string{"priority"},
+	)
+
+	priorityJourneyTime = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    constants.MetricsNamespace + "_priority_journey_time_seconds",
+			Help:    "Time priority callers take from request to arrival at their destination",
 			Buckets: []float64{1, 5, 10, 30, 60, 120, 300},
 		},
-		[]string{constants.ElevatorNameLabel, "floors_traveled"},
+		[]string{"priority"},
+	)
+
+	// Anti-nuisance metrics
+	nuisanceEvents = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: constants.MetricsNamespace + "_nuisance_events_total",
+			Help: "Total number of stops detected as nuisance by the load sensor",
+		},
+		[]string{constants.BuildingLabel, constants.ElevatorNameLabel, "reason"},
+	)
+
+	phantomCallsCancelled = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: constants.MetricsNamespace + "_phantom_calls_cancelled_total",
+			Help: "Total number of car calls cancelled because no rider was headed for them",
+		},
+		[]string{constants.BuildingLabel, constants.ElevatorNameLabel},
+	)
+
+	// Building metrics
+	buildingRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: constants.MetricsNamespace + "_building_requests_total",
+			Help: "Total number of API requests to a building, accepted or rejected by its bulkhead",
+		},
+		[]string{constants.BuildingLabel, "status"},
+	)
+
+	buildingRequestsInFlight = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: constants.MetricsNamespace + "_building_requests_in_flight",
+			Help: "Number of API requests a building is currently serving",
+		},
+		[]string{constants.BuildingLabel},
+	)
+
+	// Cluster metrics
+	clusterLeader = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: constants.MetricsNamespace + "_cluster_leader",
+			Help: "Whether this cluster node leads the cluster and runs the fleet (1 = leader, 0 = follower)",
+		},
+		[]string{constants.ClusterNodeLabel},
+	)
+
+	clusterForwardedCalls = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: constants.MetricsNamespace + "_cluster_forwarded_calls_total",
+			Help: "Total number of calls a follower forwarded to the cluster leader",
+		},
+		[]string{constants.ClusterNodeLabel, "operation", "status"},
+	)
+
+	// Webhook metrics
+	webhookDeliveries = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: constants.MetricsNamespace + "_webhook_deliveries_total",
+			Help: "Total number of webhook delivery attempts by event type and outcome",
+		},
+		[]string{"event", "status"},
+	)
+
+	// SLA metrics
+	slaIndicatorValue = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: constants.MetricsNamespace + "_sla_indicator_value",
+			Help: "Last evaluated service-level indicator of an SLA rule, in seconds, as a share of requests or in cars",
+		},
+		[]string{"building", "rule"},
+	)
+	slaAlertFiring = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: constants.MetricsNamespace + "_sla_alert_firing",
+			Help: "Whether an SLA rule is breached (1) or met (0)",
+		},
+		[]string{"building", "rule", "severity"},
+	)
+
+	// Forecast metrics
+	forecastCalls = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: constants.MetricsNamespace + "_forecast_calls",
+			Help: "Calls forecast from a floor for the last finished forecast slot",
+		},
+		[]string{"building", "floor"},
+	)
+	forecastActualCalls = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: constants.MetricsNamespace + "_forecast_actual_calls",
+			Help: "Calls made from a floor during the last finished forecast slot",
+		},
+		[]string{"building", "floor"},
+	)
+	forecastError = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: constants.MetricsNamespace + "_forecast_error_calls",
+			Help: "Calls the forecast of the last finished slot was off by, summed over floors",
+		},
+		[]string{"building"},
+	)
+	repositionedCars = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: constants.MetricsNamespace + "_repositioned_cars_total",
+			Help: "Idle cars sent to a floor ahead of forecast demand",
+		},
+		[]string{constants.BuildingLabel, constants.ElevatorNameLabel},
 	)
 
 	// System health metrics
@@ -69,7 +213,7 @@ var (
 			Name: constants.MetricsNamespace + "_current_floor",
 			Help: "Current floor of each elevator",
 		},
-		[]string{constants.ElevatorNameLabel},
+		[]string{constants.BuildingLabel, constants.ElevatorNameLabel},
 	)
 
 	pendingRequests = prometheus.NewGaugeVec(
@@ -77,7 +221,7 @@ var (
 			Name: constants.MetricsNamespace + "_pending_requests",
 			Help: "Number of pending requests per elevator",
 		},
-		[]string{constants.ElevatorNameLabel, "direction"},
+		[]string{constants.BuildingLabel, constants.ElevatorNameLabel, "direction"},
 	)
 
 	// Circuit breaker metrics
@@ -86,7 +230,7 @@ var (
 			Name: constants.MetricsNamespace + "_circuit_breaker_state",
 			Help: "Circuit breaker state (0 = closed, 1 = half-open, 2 = open)",
 		},
-		[]string{constants.ElevatorNameLabel},
+		[]string{constants.BuildingLabel, constants.ElevatorNameLabel},
 	)
 
 	circuitBreakerFailures = prometheus.NewCounterVec(
@@ -94,7 +238,7 @@ var (
 			Name: constants.MetricsNamespace + "_circuit_breaker_failures_total",
 			Help: "Total circuit breaker failures",
 		},
-		[]string{constants.ElevatorNameLabel},
+		[]string{constants.BuildingLabel, constants.ElevatorNameLabel},
 	)
 
 	// HTTP metrics
@@ -148,6 +292,21 @@ var (
 			Help: "Number of active WebSocket connections",
 		},
 	)
+
+	// Status broadcast metrics
+	statusSubscribers = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: constants.MetricsNamespace + "_status_subscribers",
+			Help: "Number of clients subscribed to status broadcasts",
+		},
+	)
+
+	statusUpdatesDropped = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: constants.MetricsNamespace + "_status_updates_dropped_total",
+			Help: "Total number of status updates dropped for slow subscribers",
+		},
+	)
 )
 
 func init() {
@@ -157,6 +316,23 @@ func init() {
 		elevatorEfficiency,
 		waitTime,
 		travelTime,
+		etaError,
+		priorityRequestsTotal,
+		priorityWaitTime,
+		priorityJourneyTime,
+		nuisanceEvents,
+		phantomCallsCancelled,
+		buildingRequests,
+		buildingRequestsInFlight,
+		clusterLeader,
+		clusterForwardedCalls,
+		webhookDeliveries,
+		slaIndicatorValue,
+		slaAlertFiring,
+		forecastCalls,
+		forecastActualCalls,
+		forecastError,
+		repositionedCars,
 		systemHealth,
 		currentFloor,
 		pendingRequests,
@@ -168,29 +344,114 @@ func init() {
 		avgResponseTime,
 		memoryUsage,
 		activeConnections,
+		statusSubscribers,
+		statusUpdatesDropped,
 	)
 }
 
 // Request processing metrics
-func RecordRequestDuration(elevatorName, status string, seconds float64) {
-	requestDuration.WithLabelValues(elevatorName, status).Observe(seconds)
+func RecordRequestDuration(building, elevatorName, status string, seconds float64) {
+	requestDuration.WithLabelValues(building, elevatorName, status).Observe(seconds)
 }
 
-func IncRequestsTotal(elevatorName, direction, status string) {
-	requestsTotal.WithLabelValues(elevatorName, direction, status).Inc()
+func IncRequestsTotal(building, elevatorName, direction, status string) {
+	requestsTotal.WithLabelValues(building, elevatorName, direction, status).Inc()
 }
 
 // Elevator efficiency metrics
-func SetElevatorEfficiency(elevatorName string, ratio float64) {
-	elevatorEfficiency.WithLabelValues(elevatorName).Set(ratio)
+func SetElevatorEfficiency(building, elevatorName string, ratio float64) {
+	elevatorEfficiency.WithLabelValues(building, elevatorName).Set(ratio)
 }
 
-func RecordWaitTime(elevatorName string, seconds float64) {
-	waitTime.WithLabelValues(elevatorName).Observe(seconds)
+func RecordWaitTime(building, elevatorName string, seconds float64) {
+	waitTime.WithLabelValues(building, elevatorName).Observe(seconds)
 }
 
-func RecordTravelTime(elevatorName, floorsTraveled string, seconds float64) {
-	travelTime.WithLabelValues(elevatorName, floorsTraveled).Observe(seconds)
+func RecordTravelTime(building, elevatorName, floorsTraveled string, seconds float64) {
+	travelTime.WithLabelValues(building, elevatorName, floorsTraveled).Observe(seconds)
+}
+
+// RecordETAError observes how far a measured wait or ride time was from its
+// estimate; estimate is "wait" or "ride"
+func RecordETAError(building, elevatorName, estimate string, seconds float64) {
+	etaError.WithLabelValues(building, elevatorName, estimate).Observe(seconds)
+}
+
+// Priority call metrics
+func IncPriorityRequests(building, elevatorName, priority string) {
+	priorityRequestsTotal.WithLabelValues(building, elevatorName, priority).Inc()
+}
+
+func RecordPriorityWaitTime(priority string, seconds float64) {
+	priorityWaitTime.WithLabelValues(priority).Observe(seconds)
+}
+
+func RecordPriorityJourneyTime(priority string, seconds float64) {
+	priorityJourneyTime.WithLabelValues(priority).Observe(seconds)
+}
+
+// Anti-nuisance metrics
+func IncNuisanceEvents(building, elevatorName, reason string) {
+	nuisanceEvents.WithLabelValues(building, elevatorName, reason).Inc()
+}
+
+func AddPhantomCallsCancelled(building, elevatorName string, count int) {
+	phantomCallsCancelled.WithLabelValues(building, elevatorName).Add(float64(count))
+}
+
+// Building metrics
+func IncBuildingRequests(building, status string) {
+	buildingRequests.WithLabelValues(building, status).Inc()
+}
+
+func SetBuildingRequestsInFlight(building string, count int) {
+	buildingRequestsInFlight.WithLabelValues(building).Set(float64(count))
+}
+
+// Cluster metrics
+func SetClusterLeader(node string, leader bool) {
+	value := 0.0
+	if leader {
+		value = 1.0
+	}
+	clusterLeader.WithLabelValues(node).Set(value)
+}
+
+func IncClusterForwardedCalls(node, operation, status string) {
+	clusterForwardedCalls.WithLabelValues(node, operation, status).Inc()
+}
+
+// Webhook metrics
+func IncWebhookDeliveries(eventType, status string) {
+	webhookDeliveries.WithLabelValues(eventType, status).Inc()
+}
+
+// SLA metrics
+func SetSLAIndicator(building, rule string, value float64) {
+	slaIndicatorValue.WithLabelValues(building, rule).Set(value)
+}
+
+func SetSLAAlertFiring(building, rule, severity string, firing bool) {
+	value := 0.0
+	if firing {
+		value = 1.0
+	}
+	slaAlertFiring.WithLabelValues(building, rule, severity).Set(value)
+}
+
+// Forecast metrics
+func SetForecastCalls(building string, floor int, forecast float64, actual int) {
+	label := strconv.Itoa(floor)
+	forecastCalls.WithLabelValues(building, label).Set(forecast)
+	forecastActualCalls.WithLabelValues(building, label).Set(float64(actual))
+}
+
+func SetForecastError(building string, calls float64) {
+	forecastError.WithLabelValues(building).Set(calls)
+}
+
+func IncRepositionedCars(building, elevatorName string) {
+	repositionedCars.WithLabelValues(building, elevatorName).Inc()
 }
 
 // System health metrics
@@ -203,21 +464,25 @@ func SetSystemHealth(component string, healthy bool) {
 }
 
 // Current state metrics
-func SetCurrentFloor(elevatorName string, floor float64) {
-	currentFloor.WithLabelValues(elevatorName).Set(floor)
+func SetCurrentFloor(building, elevatorName string, floor float64) {
+	currentFloor.WithLabelValues(building, elevatorName).Set(floor)
 }
 
-func SetPendingRequests(elevatorName, direction string, count float64) {
-	pendingRequests.WithLabelValues(elevatorName, direction).Set(count)
+func SetPendingRequests(building, elevatorName, direction string, count float64) {
+	pendingRequests.WithLabelValues(building, elevatorName, direction).Set(count)
 }
 
 // Circuit breaker metrics
-func SetCircuitBreakerState(elevatorName string, state float64) {
-	circuitBreakerState.WithLabelValues(elevatorName).Set(state)
+func SetCircuitBreakerState(building, elevatorName string, state float64) {
+	circuitBreakerState.WithLabelValues(building, elevatorName).Set(state)
+}
+
+func DeleteCircuitBreakerState(building, elevatorName string) {
+	circuitBreakerState.DeleteLabelValues(building, elevatorName)
 }
 
-func IncCircuitBreakerFailures(elevatorName string) {
-	circuitBreakerFailures.WithLabelValues(elevatorName).Inc()
+func IncCircuitBreakerFailures(building, elevatorName string) {
+	circuitBreakerFailures.WithLabelValues(building, elevatorName).Inc()
 }
 
 // HTTP metrics
@@ -245,7 +510,16 @@ func SetActiveConnections(count float64) {
 	activeConnections.Set(count)
 }
 
+// Status broadcast metrics
+func SetStatusSubscribers(count float64) {
+	statusSubscribers.Set(count)
+}
+
+func IncStatusUpdatesDropped() {
+	statusUpdatesDropped.Inc()
+}
+
 // Legacy function for backward compatibility
-func RequestDurationHistogram(elevatorName string, seconds float64) {
-	RecordRequestDuration(elevatorName, "success", seconds)
+func RequestDurationHistogram(building, elevatorName string, seconds float64) {
+	RecordRequestDuration(building, elevatorName, "success", seconds)
 }
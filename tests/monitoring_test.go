@@ -130,11 +130,11 @@ func testMetricsCollection(t *testing.T, server *httpPkg.Server, elevatorManager
 
 	t.Run("Request Metrics Collection", func(t *testing.T) {
 		// Test metrics recording
-		metrics.RecordRequestDuration("TestElevator-1", "success", 1.5)
-		metrics.IncRequestsTotal("TestElevator-1", "up", "success")
-		metrics.SetElevatorEfficiency("TestElevator-1", 0.95)
-		metrics.RecordWaitTime("TestElevator-1", 10.0)
-		metrics.RecordTravelTime("TestElevator-1", "5", 15.0)
+		metrics.RecordRequestDuration("main", "TestElevator-1", "success", 1.5)
+		metrics.IncRequestsTotal("main", "TestElevator-1", "up", "success")
+		metrics.SetElevatorEfficiency("main", "TestElevator-1", 0.95)
+		metrics.RecordWaitTime("main", "TestElevator-1", 10.0)
+		metrics.RecordTravelTime("main", "TestElevator-1", "5", 15.0)
 
 		// Verify metrics can be gathered
 		metricFamilies, err := prometheus.DefaultGatherer.Gather()
@@ -165,9 +165,9 @@ func testMetricsCollection(t *testing.T, server *httpPkg.Server, elevatorManager
 	t.Run("System Health Metrics", func(t *testing.T) {
 		metrics.SetSystemHealth("elevators", true)
 		metrics.SetSystemHealth("manager", true)
-		metrics.SetCurrentFloor("TestElevator-1", 5.0)
-		metrics.SetPendingRequests("TestElevator-1", "up", 2.0)
-		metrics.SetCircuitBreakerState("TestElevator-1", 0.0) // closed
+		metrics.SetCurrentFloor("main", "TestElevator-1", 5.0)
+		metrics.SetPendingRequests("main", "TestElevator-1", "up", 2.0)
+		metrics.SetCircuitBreakerState("main", "TestElevator-1", 0.0) // closed
 
 		// Get system metrics through manager
 		systemMetrics := elevatorManager.GetMetrics()
@@ -325,10 +325,10 @@ func TestHealthServiceStandalone(t *testing.T) {
 func TestMetricsCollection(t *testing.T) {
 	t.Run("Prometheus Metrics", func(t *testing.T) {
 		// Test various metric types
-		metrics.RecordRequestDuration("test-elevator", "success", 2.5)
-		metrics.IncRequestsTotal("test-elevator", "up", "success")
-		metrics.SetElevatorEfficiency("test-elevator", 0.85)
-		metrics.RecordWaitTime("test-elevator", 30.0)
+		metrics.RecordRequestDuration("main", "test-elevator", "success", 2.5)
+		metrics.IncRequestsTotal("main", "test-elevator", "up", "success")
+		metrics.SetElevatorEfficiency("main", "test-elevator", 0.85)
+		metrics.RecordWaitTime("main", "test-elevator", 30.0)
 		metrics.SetSystemHealth("test-component", true)
 		metrics.IncError("validation_error", "test-component")
 